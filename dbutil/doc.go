@@ -0,0 +1,11 @@
+// Package dbutil provides the building blocks shared by the database modules in the catalog, e.g.
+// postgres and mysql, including the generated passwords, the Secret of the database credentials
+// injected into the workload, the resolution of the cloud provider region, and the override of
+// the provider configs, e.g. the assumed role and the custom endpoints.
+//
+// Each module imports the package by a local replace directive in its go.mod:
+//
+//	require dbutil v0.0.0
+//
+//	replace dbutil => ../../../dbutil
+package dbutil
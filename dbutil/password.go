@@ -0,0 +1,56 @@
+package dbutil
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// RandomPasswordType is the type of the Terraform resource generating the random passwords.
+const RandomPasswordType = "random_password"
+
+// localPasswordLength is the length of the passwords of the locally deployed databases.
+const localPasswordLength = 16
+
+// RandomProviderConfig is the provider config of the Terraform random provider.
+var RandomProviderConfig = module.ProviderConfig{
+	Source:  "hashicorp/random",
+	Version: "3.6.0",
+}
+
+// RandomPassword generates the Terraform random_password resource of the name, and returns it with
+// its ID, whose result attribute is the password of the cloud provided database. The non-empty
+// version is kept in the keepers of the resource, so bumping it replaces the resource and rotates
+// the password.
+func RandomPassword(name, version string) (*kusionapiv1.Resource, string, error) {
+	resAttrs := map[string]any{
+		"length":           16,
+		"special":          true,
+		"override_special": "_",
+	}
+	if version != "" {
+		resAttrs["keepers"] = map[string]any{"passwordVersion": version}
+	}
+
+	id, err := module.TerraformResourceID(RandomProviderConfig, RandomPasswordType, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resource, err := module.WrapTFResourceToKusionResource(RandomProviderConfig, RandomPasswordType, id, resAttrs, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resource, id, nil
+}
+
+// LocalPassword generates the fixed password of the name in the App of the request for the
+// locally deployed database, which stays the same across the generations without any state.
+func LocalPassword(request *module.GeneratorRequest, name string) string {
+	hash := md5.Sum([]byte(request.Project + request.Stack + request.App + name))
+
+	return hex.EncodeToString(hash[:])[:localPasswordLength]
+}
@@ -0,0 +1,46 @@
+package dbutil
+
+import (
+	"testing"
+
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestRandomPassword(t *testing.T) {
+	resource, id, err := RandomPassword("foo-postgres", "")
+	if err != nil {
+		t.Fatalf("RandomPassword() error = %v", err)
+	}
+	if id != "hashicorp:random:random_password:foo-postgres" {
+		t.Errorf("RandomPassword() id = %q", id)
+	}
+	if resource.ID != id || resource.Attributes["length"] != 16 {
+		t.Errorf("RandomPassword() resource = %v", resource)
+	}
+}
+
+func TestLocalPassword(t *testing.T) {
+	request := &module.GeneratorRequest{Project: "foo", Stack: "dev", App: "bar"}
+
+	password := LocalPassword(request, "foo-db")
+	if len(password) != localPasswordLength {
+		t.Errorf("LocalPassword() = %q, want %d characters", password, localPasswordLength)
+	}
+	if LocalPassword(request, "foo-db") != password {
+		t.Error("LocalPassword() is not stable across the calls")
+	}
+	if LocalPassword(request, "foo-db-cdc") == password {
+		t.Error("LocalPassword() is the same for the different names")
+	}
+}
+
+func TestRandomPassword_Version(t *testing.T) {
+	resource, _, err := RandomPassword("foo-postgres", "2")
+	if err != nil {
+		t.Fatalf("RandomPassword() error = %v", err)
+	}
+	keepers, ok := resource.Attributes["keepers"].(map[string]any)
+	if !ok || keepers["passwordVersion"] != "2" {
+		t.Errorf("RandomPassword() keepers = %v, want the password version", resource.Attributes["keepers"])
+	}
+}
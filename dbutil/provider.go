@@ -0,0 +1,115 @@
+package dbutil
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrEmptyAssumeRoleARN   = errors.New("empty assumeRoleARN for the sessionName")
+	ErrInvalidAssumeRoleARN = errors.New("invalid assumeRoleARN, must be an IAM or RAM role ARN")
+	ErrInvalidEndpoints     = errors.New("invalid endpoints, must be a map of the service codes to the endpoints")
+)
+
+// ProviderOverride overrides the provider configs of the cloud resources with the credentials
+// and the endpoints in the platform config, which are set as the provider meta of the Terraform
+// resources.
+type ProviderOverride struct {
+	// The ARN of the IAM or RAM role assumed by the cloud provider.
+	AssumeRoleARN string
+	// The session name of the assumed role.
+	SessionName string
+	// The named profile in the shared credentials of the AWS provider.
+	Profile string
+	// The custom endpoints of the Alicloud services by the service codes, e.g. rds.
+	Endpoints map[string]string
+	// The STS security token of the temporary credentials of the Alicloud provider.
+	SecurityToken string
+}
+
+// Validate validates the assumed role of the override.
+func (o ProviderOverride) Validate() error {
+	if o.SessionName != "" && o.AssumeRoleARN == "" {
+		return ErrEmptyAssumeRoleARN
+	}
+	if o.AssumeRoleARN != "" && !strings.HasPrefix(o.AssumeRoleARN, "arn:") &&
+		!strings.HasPrefix(o.AssumeRoleARN, "acs:ram:") {
+		return ErrInvalidAssumeRoleARN
+	}
+	return nil
+}
+
+// AWSProviderMeta returns the provider meta of the AWS provider in the region, which assumes the
+// IAM role and uses the named profile if set.
+func (o ProviderOverride) AWSProviderMeta(region string) map[string]any {
+	providerMeta := map[string]any{"region": region}
+	if o.Profile != "" {
+		providerMeta["profile"] = o.Profile
+	}
+	if assumeRole := o.assumeRole(); assumeRole != nil {
+		providerMeta["assume_role"] = assumeRole
+	}
+
+	return providerMeta
+}
+
+// AlicloudProviderMeta returns the provider meta of the Alicloud provider in the region, which
+// assumes the RAM role, uses the custom endpoints and the security token if set.
+func (o ProviderOverride) AlicloudProviderMeta(region string) map[string]any {
+	providerMeta := map[string]any{"region": region}
+	if assumeRole := o.assumeRole(); assumeRole != nil {
+		providerMeta["assume_role"] = assumeRole
+	}
+	if len(o.Endpoints) != 0 {
+		endpoints := make(map[string]any, len(o.Endpoints))
+		for service, endpoint := range o.Endpoints {
+			endpoints[service] = endpoint
+		}
+		providerMeta["endpoints"] = endpoints
+	}
+	if o.SecurityToken != "" {
+		providerMeta["security_token"] = o.SecurityToken
+	}
+
+	return providerMeta
+}
+
+// assumeRole returns the assume_role block of the provider meta, or nil if no role is assumed.
+func (o ProviderOverride) assumeRole() map[string]any {
+	if o.AssumeRoleARN == "" {
+		return nil
+	}
+	assumeRole := map[string]any{"role_arn": o.AssumeRoleARN}
+	if o.SessionName != "" {
+		assumeRole["session_name"] = o.SessionName
+	}
+	return assumeRole
+}
+
+// ParseEndpoints parses the custom endpoints of the Alicloud services in the platform config.
+func ParseEndpoints(value interface{}) (map[string]string, error) {
+	endpoints := map[string]string{}
+	switch value := value.(type) {
+	case map[string]string:
+		for service, endpoint := range value {
+			endpoints[service] = endpoint
+		}
+	case map[string]interface{}:
+		for service, endpoint := range value {
+			endpointStr, ok := endpoint.(string)
+			if !ok {
+				return nil, ErrInvalidEndpoints
+			}
+			endpoints[service] = endpointStr
+		}
+	default:
+		return nil, ErrInvalidEndpoints
+	}
+	for service, endpoint := range endpoints {
+		if service == "" || endpoint == "" {
+			return nil, ErrInvalidEndpoints
+		}
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,104 @@
+package dbutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProviderOverride_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		override ProviderOverride
+		expected error
+	}{
+		{
+			name: "empty override",
+		},
+		{
+			name:     "session name without assumeRoleARN",
+			override: ProviderOverride{SessionName: "kusion"},
+			expected: ErrEmptyAssumeRoleARN,
+		},
+		{
+			name:     "invalid assumeRoleARN",
+			override: ProviderOverride{AssumeRoleARN: "kusion"},
+			expected: ErrInvalidAssumeRoleARN,
+		},
+		{
+			name:     "RAM role",
+			override: ProviderOverride{AssumeRoleARN: "acs:ram::123456789012:role/kusion", SessionName: "kusion"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.override.Validate(); !errors.Is(err, tt.expected) {
+				t.Errorf("Validate() = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProviderOverride_AWSProviderMeta(t *testing.T) {
+	override := ProviderOverride{
+		AssumeRoleARN: "arn:aws:iam::123456789012:role/kusion",
+		SessionName:   "kusion",
+		Profile:       "prod",
+		Endpoints:     map[string]string{"rds": "rds.example.com"},
+	}
+
+	expected := map[string]any{
+		"region":  "us-east-1",
+		"profile": "prod",
+		"assume_role": map[string]any{
+			"role_arn":     "arn:aws:iam::123456789012:role/kusion",
+			"session_name": "kusion",
+		},
+	}
+	if got := override.AWSProviderMeta("us-east-1"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("AWSProviderMeta() = %v, want %v", got, expected)
+	}
+	if got := (ProviderOverride{}).AWSProviderMeta("us-east-1"); !reflect.DeepEqual(got, map[string]any{"region": "us-east-1"}) {
+		t.Errorf("AWSProviderMeta() = %v, want the region only", got)
+	}
+}
+
+func TestProviderOverride_AlicloudProviderMeta(t *testing.T) {
+	override := ProviderOverride{
+		AssumeRoleARN: "acs:ram::123456789012:role/kusion",
+		Profile:       "prod",
+		Endpoints:     map[string]string{"rds": "rds-vpc.cn-beijing.aliyuncs.com"},
+		SecurityToken: "test-token",
+	}
+
+	expected := map[string]any{
+		"region":         "cn-beijing",
+		"assume_role":    map[string]any{"role_arn": "acs:ram::123456789012:role/kusion"},
+		"endpoints":      map[string]any{"rds": "rds-vpc.cn-beijing.aliyuncs.com"},
+		"security_token": "test-token",
+	}
+	if got := override.AlicloudProviderMeta("cn-beijing"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("AlicloudProviderMeta() = %v, want %v", got, expected)
+	}
+}
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints(map[string]interface{}{"rds": "rds.example.com"})
+	if err != nil {
+		t.Fatalf("ParseEndpoints() error = %v", err)
+	}
+	if !reflect.DeepEqual(endpoints, map[string]string{"rds": "rds.example.com"}) {
+		t.Errorf("ParseEndpoints() = %v", endpoints)
+	}
+
+	for _, value := range []interface{}{
+		map[string]interface{}{"rds": 1},
+		map[string]string{"rds": ""},
+		[]interface{}{"rds.example.com"},
+	} {
+		if _, err = ParseEndpoints(value); !errors.Is(err, ErrInvalidEndpoints) {
+			t.Errorf("ParseEndpoints(%v) = %v, want %v", value, err, ErrInvalidEndpoints)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package dbutil
+
+import (
+	"testing"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestResolveRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expected       string
+	}{
+		{
+			name: "empty configs",
+		},
+		{
+			name:      "dev config",
+			devConfig: kusionapiv1.Accessory{"region": "us-east-1"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"region":    "us-west-1",
+				TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expected: "us-east-1",
+		},
+		{
+			name:      "platform config",
+			devConfig: kusionapiv1.Accessory{"region": ""},
+			platformConfig: kusionapiv1.GenericConfig{
+				"region":    "us-west-1",
+				TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expected: "us-west-1",
+		},
+		{
+			name: "topology",
+			platformConfig: kusionapiv1.GenericConfig{
+				TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expected: "us-west-2",
+		},
+		{
+			name: "topology without region",
+			platformConfig: kusionapiv1.GenericConfig{
+				TopologyKey: map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveRegion(tt.devConfig, tt.platformConfig); got != tt.expected {
+				t.Errorf("ResolveRegion() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProviderRegion(t *testing.T) {
+	t.Setenv("DBUTIL_TEST_REGION", "env-region")
+	providerCfg := module.ProviderConfig{Source: "hashicorp/aws", Version: "5.0.1"}
+
+	if got := ProviderRegion("us-east-1", providerCfg, "DBUTIL_TEST_REGION"); got != "us-east-1" {
+		t.Errorf("ProviderRegion() = %q, want the resolved region", got)
+	}
+	if got := ProviderRegion("", providerCfg, "DBUTIL_TEST_REGION"); got != "env-region" {
+		t.Errorf("ProviderRegion() = %q, want the region of the env", got)
+	}
+}
@@ -0,0 +1,128 @@
+package dbutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// The prefixes of the environment variables injecting the database credentials into the workload,
+// which are followed by the upper-cased database name, e.g. KUSION_DB_HOST_FOO_DB.
+const (
+	HostAddressEnv = "KUSION_DB_HOST"
+	UsernameEnv    = "KUSION_DB_USERNAME"
+	PasswordEnv    = "KUSION_DB_PASSWORD"
+)
+
+// DBSecret describes the Kubernetes Secret storing the credentials of the database, which are
+// injected into the workload as the environment variables.
+type DBSecret struct {
+	// The name of the Secret.
+	Name string
+	// The namespace of the Secret, i.e. the project of the App.
+	Namespace string
+	// The name of the database, which suffixes the names of the environment variables.
+	DatabaseName string
+	// The host address of the database.
+	HostAddress string
+	// The port of the database.
+	Port int
+	// The username of the database account.
+	Username string
+	// The password of the database account.
+	Password string
+	// The version of the password, which is bumped to rotate the password generated by Terraform.
+	PasswordVersion string
+	// The other keys of the Secret, e.g. the CA certificates.
+	Extra map[string]string
+	// The key of the pod annotation holding the checksum of the Secret, which restarts the pods of
+	// the workload to pick up the new credentials once the Secret is regenerated.
+	ChecksumAnnotation string
+}
+
+// Generate generates the Secret resource, and the patcher injecting the host address, username
+// and password into the workload.
+func (s DBSecret) Generate() (*kusionapiv1.Resource, *kusionapiv1.Patcher, error) {
+	data := map[string]string{
+		"hostAddress": s.HostAddress,
+		"username":    s.Username,
+		"password":    s.Password,
+		"port":        strconv.Itoa(s.Port),
+	}
+	for k, v := range s.Extra {
+		data[k] = v
+	}
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: s.Namespace,
+		},
+		StringData: data,
+	}
+
+	resourceID := module.KubernetesResourceID(secret.TypeMeta, secret.ObjectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, secret)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	suffix := "_" + strings.ToUpper(strings.ReplaceAll(s.DatabaseName, "-", "_"))
+	var envVars []v1.EnvVar
+	for _, env := range []struct{ name, key string }{
+		{HostAddressEnv + suffix, "hostAddress"},
+		{UsernameEnv + suffix, "username"},
+		{PasswordEnv + suffix, "password"},
+	} {
+		envVars = append(envVars, v1.EnvVar{
+			Name: env.name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{
+						Name: secret.Name,
+					},
+					Key: env.key,
+				},
+			},
+		})
+	}
+
+	patcher := &kusionapiv1.Patcher{Environments: envVars}
+	if s.ChecksumAnnotation != "" {
+		checksum, err := SecretChecksum(secret, s.PasswordVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		patcher.PodAnnotations = map[string]string{s.ChecksumAnnotation: checksum}
+	}
+
+	return resource, patcher, nil
+}
+
+// SecretChecksum calculates the sha256 checksum of the data of the Secret and the version of the
+// password. The values referencing the other resources, e.g. the Terraform generated passwords, are
+// checksummed as the references, which stay the same across the rotations, so the non-empty
+// password version is checksummed as well to restart the pods once the password is rotated.
+func SecretChecksum(secret *v1.Secret, passwordVersion string) (string, error) {
+	fields := []any{secret.Name, secret.Data, secret.StringData}
+	if passwordVersion != "" {
+		fields = append(fields, passwordVersion)
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
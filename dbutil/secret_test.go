@@ -0,0 +1,94 @@
+package dbutil
+
+import (
+	"testing"
+)
+
+func TestDBSecret_Generate(t *testing.T) {
+	secret := DBSecret{
+		Name:               "foo-db-mysql",
+		Namespace:          "foo",
+		DatabaseName:       "foo-db",
+		HostAddress:        "foo-db-host",
+		Port:               3306,
+		Username:           "root",
+		Password:           "test-password",
+		Extra:              map[string]string{"ca": "test-ca"},
+		ChecksumAnnotation: "checksum.kusionstack.io/foo-db-mysql",
+	}
+
+	resource, patcher, err := secret.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resource.ID != "v1:Secret:foo:foo-db-mysql" {
+		t.Errorf("Generate() resource id = %q", resource.ID)
+	}
+	data := resource.Attributes["stringData"].(map[string]interface{})
+	for key, expected := range map[string]string{
+		"hostAddress": "foo-db-host",
+		"port":        "3306",
+		"username":    "root",
+		"password":    "test-password",
+		"ca":          "test-ca",
+	} {
+		if data[key] != expected {
+			t.Errorf("Generate() stringData[%s] = %v, want %s", key, data[key], expected)
+		}
+	}
+
+	var names []string
+	for _, env := range patcher.Environments {
+		names = append(names, env.Name)
+	}
+	expectedNames := []string{"KUSION_DB_HOST_FOO_DB", "KUSION_DB_USERNAME_FOO_DB", "KUSION_DB_PASSWORD_FOO_DB"}
+	if len(names) != len(expectedNames) {
+		t.Fatalf("Generate() environments = %v, want %v", names, expectedNames)
+	}
+	for i := range names {
+		if names[i] != expectedNames[i] {
+			t.Errorf("Generate() environments = %v, want %v", names, expectedNames)
+		}
+	}
+
+	// The checksum changes with the credentials, which restarts the pods of the workload.
+	checksum := patcher.PodAnnotations[secret.ChecksumAnnotation]
+	secret.Password = "rotated-password"
+	_, rotated, err := secret.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if checksum == "" || rotated.PodAnnotations[secret.ChecksumAnnotation] == checksum {
+		t.Errorf("Generate() checksum = %q, want a different one after the rotation", checksum)
+	}
+}
+
+func TestDBSecret_GeneratePasswordVersion(t *testing.T) {
+	secret := DBSecret{
+		Name:               "foo-db-mysql",
+		Namespace:          "foo",
+		DatabaseName:       "foo-db",
+		HostAddress:        "$kusion_path.hashicorp:aws:aws_db_instance:foo-db.address",
+		Port:               3306,
+		Username:           "root",
+		Password:           "$kusion_path.hashicorp:random:random_password:foo-db-mysql.result",
+		ChecksumAnnotation: "checksum.kusionstack.io/foo-db-mysql",
+	}
+
+	_, patcher, err := secret.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	// The password reference stays the same across the rotations, so the checksum changes with
+	// the password version instead.
+	checksum := patcher.PodAnnotations[secret.ChecksumAnnotation]
+	secret.PasswordVersion = "2"
+	_, rotated, err := secret.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if checksum == "" || rotated.PodAnnotations[secret.ChecksumAnnotation] == checksum {
+		t.Errorf("Generate() checksum = %q, want a different one after the rotation", checksum)
+	}
+}
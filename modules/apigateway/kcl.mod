@@ -0,0 +1,3 @@
+[package]
+name = "apigateway"
+version = "0.1.0"
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	alicloudGroup            = "alicloud_api_gateway_group"
+	alicloudVPCAccess        = "alicloud_api_gateway_vpc_access"
+	alicloudAPI              = "alicloud_api_gateway_api"
+	alicloudApp              = "alicloud_api_gateway_app"
+	alicloudAppAttachment    = "alicloud_api_gateway_app_attachment"
+	alicloudPlugin           = "alicloud_api_gateway_plugin"
+	alicloudPluginAttachment = "alicloud_api_gateway_plugin_attachment"
+
+	// alicloudBackendTimeout is the timeout of the backend in milliseconds.
+	alicloudBackendTimeout = 10000
+	// alicloudNameMaxLength is the max length of the names of the API gateway resources, which
+	// consist of the letters, the digits and the underscores, and start with a letter.
+	alicloudNameMaxLength = 50
+)
+
+var defaultAlicloudProviderCfg = module.ProviderConfig{
+	Source:  "aliyun/alicloud",
+	Version: "1.209.1",
+}
+
+// generateAlicloudResources generates the API group of Alicloud API Gateway with an API of each
+// route published to the stage, and returns the resources along with the endpoint of the
+// gateway. The API key and the JWT auth are enforced by the app and the jwtAuth plugin attached
+// to the APIs, and the throttling by the trafficControl plugin.
+func (apigateway *APIGateway) generateAlicloudResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, string, error) {
+	providerCfg := defaultAlicloudProviderCfg
+	providerCfg.ProviderMeta = map[string]any{"region": apigateway.platform.Region}
+	name := alicloudName(moduleutil.AppName(request), "")
+
+	group, err := moduleutil.WrapTFResource(providerCfg, alicloudGroup, name, map[string]interface{}{
+		"name":        name,
+		"description": fmt.Sprintf("The API group of %s managed by Kusion", moduleutil.AppName(request)),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	resources := []kusionapiv1.Resource{*group}
+	groupID := module.KusionPathDependency(group.ID, "id")
+
+	// Authorize the VPC access to the internal load balancer of the private integration, which
+	// is referred to by its name in the APIs.
+	backend := apigateway.platform.Backend
+	var vpcAccess *kusionapiv1.Resource
+	if backend.VPCID != "" {
+		vpcAccess, err = moduleutil.WrapTFResource(providerCfg, alicloudVPCAccess, name, map[string]interface{}{
+			"name":        name,
+			"vpc_id":      backend.VPCID,
+			"instance_id": backend.InstanceID,
+			"port":        apigateway.Port,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, *vpcAccess)
+	}
+
+	authType := "ANONYMOUS"
+	if apigateway.Auth == AuthAPIKey {
+		authType = "APP"
+	}
+	var apiIDs []string
+	for _, route := range apigateway.Routes {
+		attrs := map[string]interface{}{
+			"group_id":    groupID,
+			"name":        alicloudName(route.name(), ""),
+			"description": fmt.Sprintf("%s %s", route.Method, route.Path),
+			"auth_type":   authType,
+			"request_config": []map[string]interface{}{{
+				"protocol": "HTTP,HTTPS",
+				"method":   route.Method,
+				"path":     alicloudPath(route.Path),
+				"mode":     "PASSTHROUGH",
+			}},
+			"stage_names": []string{apigateway.Stage},
+		}
+		var dependsOn []string
+		if vpcAccess != nil {
+			attrs["service_type"] = "HTTP-VPC"
+			attrs["http_vpc_service_config"] = []map[string]interface{}{{
+				"name":    name,
+				"method":  route.Method,
+				"path":    alicloudPath(route.Path),
+				"timeout": alicloudBackendTimeout,
+			}}
+			dependsOn = append(dependsOn, vpcAccess.ID)
+		} else {
+			address, basePath, err := splitBackendURL(apigateway.backendURL(request))
+			if err != nil {
+				return nil, "", err
+			}
+			attrs["service_type"] = "HTTP"
+			attrs["http_service_config"] = []map[string]interface{}{{
+				"address": address,
+				"method":  route.Method,
+				"path":    basePath + alicloudPath(route.Path),
+				"timeout": alicloudBackendTimeout,
+			}}
+		}
+
+		api, err := moduleutil.WrapTFResource(providerCfg, alicloudAPI, name+"_"+route.name(), attrs, dependsOn...)
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, *api)
+		apiIDs = append(apiIDs, module.KusionPathDependency(api.ID, "api_id"))
+	}
+
+	// Authorize the app to call the APIs with its API key.
+	if apigateway.Auth == AuthAPIKey {
+		app, err := moduleutil.WrapTFResource(providerCfg, alicloudApp, name, map[string]interface{}{
+			"name":        name,
+			"description": fmt.Sprintf("The app calling the APIs of %s", moduleutil.AppName(request)),
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, *app)
+		attachments, err := apigateway.alicloudAttachments(providerCfg, alicloudAppAttachment, "app_id", app, groupID, apiIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, attachments...)
+	}
+
+	plugins, err := apigateway.alicloudPlugins()
+	if err != nil {
+		return nil, "", err
+	}
+	for _, pluginType := range []string{"jwtAuth", "trafficControl"} {
+		data, ok := plugins[pluginType]
+		if !ok {
+			continue
+		}
+		plugin, err := moduleutil.WrapTFResource(providerCfg, alicloudPlugin, name+"_"+pluginType, map[string]interface{}{
+			"plugin_name": alicloudName(name, "_"+pluginType),
+			"plugin_type": pluginType,
+			"plugin_data": data,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, *plugin)
+		attachments, err := apigateway.alicloudAttachments(providerCfg, alicloudPluginAttachment, "plugin_id", plugin, groupID, apiIDs)
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, attachments...)
+	}
+
+	return resources, module.KusionPathDependency(group.ID, "sub_domain"), nil
+}
+
+// alicloudPlugins returns the plugin data of the jwtAuth and the trafficControl plugins by the
+// plugin types.
+func (apigateway *APIGateway) alicloudPlugins() (map[string]string, error) {
+	plugins := map[string]string{}
+	if apigateway.Auth == AuthJWT {
+		jwk := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(apigateway.platform.JWT.JWK), &jwk); err != nil {
+			return nil, err
+		}
+		data, err := yaml.Marshal(map[string]interface{}{
+			"parameter":         "Authorization",
+			"parameterLocation": "header",
+			"jwk":               jwk,
+		})
+		if err != nil {
+			return nil, err
+		}
+		plugins["jwtAuth"] = string(data)
+	}
+	if t := apigateway.Throttling; t != nil && t.RateLimit > 0 {
+		data, err := yaml.Marshal(map[string]interface{}{
+			"unit":       "SECOND",
+			"apiDefault": t.RateLimit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		plugins["trafficControl"] = string(data)
+	}
+	return plugins, nil
+}
+
+// alicloudAttachments attaches the app or the plugin to each API in the stage.
+func (apigateway *APIGateway) alicloudAttachments(providerCfg module.ProviderConfig, resourceType, idKey string,
+	target *kusionapiv1.Resource, groupID string, apiIDs []string,
+) ([]kusionapiv1.Resource, error) {
+	var resources []kusionapiv1.Resource
+	for i, route := range apigateway.Routes {
+		attachment, err := moduleutil.WrapTFResource(providerCfg, resourceType, resourceName(*target)+"_"+route.name(), map[string]interface{}{
+			"api_id":     apiIDs[i],
+			"group_id":   groupID,
+			idKey:        module.KusionPathDependency(target.ID, "id"),
+			"stage_name": apigateway.Stage,
+		})
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *attachment)
+	}
+	return resources, nil
+}
+
+// resourceName returns the name of the Terraform resource in its ID.
+func resourceName(res kusionapiv1.Resource) string {
+	return res.ID[strings.LastIndex(res.ID, ":")+1:]
+}
+
+// alicloudPath returns the path in the syntax of alicloud, where the parameters are enclosed in
+// the square brackets, e.g. /orders/[id].
+func alicloudPath(path string) string {
+	return strings.NewReplacer("{", "[", "}", "]").Replace(path)
+}
+
+// splitBackendURL splits the url of the backend into the address and the base path.
+func splitBackendURL(backendURL string) (string, string, error) {
+	u, err := url.Parse(backendURL)
+	if err != nil {
+		return "", "", err
+	}
+	return u.Scheme + "://" + u.Host, strings.TrimSuffix(u.Path, "/"), nil
+}
+
+// alicloudName returns the name following the naming rule of the API gateway resources, where
+// the name is truncated to keep the suffix.
+func alicloudName(name, suffix string) string {
+	name = strings.ReplaceAll(name, "-", "_")
+	if name == "" || !(name[0] >= 'a' && name[0] <= 'z' || name[0] >= 'A' && name[0] <= 'Z') {
+		name = "kusion_" + name
+	}
+	if maxLength := alicloudNameMaxLength - len(suffix); len(name) > maxLength {
+		name = strings.TrimRight(name[:maxLength], "_")
+	}
+	return name + suffix
+}
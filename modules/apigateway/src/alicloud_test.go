@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestAPIGateway_GenerateAlicloudResources(t *testing.T) {
+	request := testutil.NewRequest().WithServiceWorkload("Deployment").Build()
+	apigateway := &APIGateway{
+		Routes: []Route{{Path: "/orders/{id}", Method: "GET"}},
+		Auth:   AuthJWT,
+		Stage:  defaultAlicloudStage,
+		platform: PlatformConfig{
+			Cloud:   CloudAlicloud,
+			Region:  "cn-hangzhou",
+			Backend: &Backend{URL: "https://orders.internal:8443/api"},
+			JWT:     &JWTConfig{JWK: `{"kty": "RSA", "e": "AQAB", "kid": "orders", "n": "abc"}`},
+		},
+	}
+
+	resources, endpoint, err := apigateway.generateAlicloudResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 4) {
+		return
+	}
+	assert.Equal(t, module.KusionPathDependency(resources[0].ID, "sub_domain"), endpoint)
+
+	api := resources[1]
+	assert.Equal(t, "default_dev_foo_get_orders_id", resourceName(api))
+	assert.Equal(t, "get_orders_id", api.Attributes["name"])
+	assert.Equal(t, "ANONYMOUS", api.Attributes["auth_type"])
+	assert.Equal(t, []string{"RELEASE"}, api.Attributes["stage_names"])
+	assert.Equal(t, "/orders/[id]", api.Attributes["request_config"].([]map[string]interface{})[0]["path"])
+	assert.Equal(t, []map[string]interface{}{{
+		"address": "https://orders.internal:8443",
+		"method":  "GET",
+		"path":    "/api/orders/[id]",
+		"timeout": alicloudBackendTimeout,
+	}}, api.Attributes["http_service_config"])
+
+	plugin := resources[2].Attributes
+	assert.Equal(t, "default_dev_foo_jwtAuth", plugin["plugin_name"])
+	assert.Contains(t, plugin["plugin_data"], "parameterLocation: header")
+	assert.Contains(t, plugin["plugin_data"], "kid: orders")
+
+	attachment := resources[3].Attributes
+	assert.Equal(t, module.KusionPathDependency(api.ID, "api_id"), attachment["api_id"])
+	assert.Equal(t, module.KusionPathDependency(resources[2].ID, "id"), attachment["plugin_id"])
+	assert.Equal(t, "RELEASE", attachment["stage_name"])
+}
+
+func TestAlicloudName(t *testing.T) {
+	assert.Equal(t, "default_dev_foo", alicloudName("default-dev-foo", ""))
+	assert.Equal(t, "kusion_1st_app", alicloudName("1st-app", ""))
+	assert.Equal(t, "a_bcdefghij_bcdefghij_bcdefghij_bcd_trafficControl", alicloudName("a-bcdefghij-bcdefghij-bcdefghij-bcdefghij", "_trafficControl"))
+}
@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+// The cloud vendors providing the API gateway.
+const (
+	CloudAWS      = "aws"
+	CloudAlicloud = "alicloud"
+)
+
+// The auth types of the routes.
+const (
+	// AuthNone allows the anonymous requests.
+	AuthNone = "none"
+	// AuthAPIKey requires the requests signed by the API key of an authorized app, which is only
+	// supported by alicloud, since the HTTP APIs of AWS have no API keys.
+	AuthAPIKey = "apiKey"
+	// AuthJWT requires the requests carrying the JWT issued by the identity provider configured
+	// in workspace.
+	AuthJWT = "jwt"
+)
+
+const (
+	defaultMethod = "ANY"
+	// defaultAlicloudStage is the stage of alicloud the APIs are published to, which must be
+	// RELEASE, PRE or TEST.
+	defaultAlicloudStage = "RELEASE"
+)
+
+var (
+	// routeMethods are the methods of the routes, where ANY matches all of them.
+	routeMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS", "ANY"}
+	// alicloudStages are the stages of the APIs of alicloud.
+	alicloudStages = []string{"RELEASE", "PRE", "TEST"}
+
+	pathSegmentPattern  = regexp.MustCompile(`^([A-Za-z0-9._~-]+|\{[A-Za-z_][A-Za-z0-9_]*\+?\})$`)
+	awsStagePattern     = regexp.MustCompile(`^[A-Za-z0-9_-]{1,128}$`)
+	domainPattern       = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+	basePathPattern     = regexp.MustCompile(`^[A-Za-z0-9._~-]+(/[A-Za-z0-9._~-]+)*$`)
+	awsARNPrefixPattern = regexp.MustCompile(`^arn:aws[a-z-]*:`)
+)
+
+var (
+	ErrEmptyCloud              = errors.New("empty cloud in the platform config, which must be aws or alicloud")
+	ErrUnsupportedCloud        = errors.New("cloud must be aws or alicloud")
+	ErrEmptyRegion             = errors.New("region must be configured in the platform config, or by AWS_REGION or ALICLOUD_REGION")
+	ErrEmptyRoutes             = errors.New("routes must not be empty")
+	ErrInvalidRoutePath        = errors.New("path of the route must start with / and consist of the literal segments or the {param} segments")
+	ErrInvalidRouteMethod      = errors.New("method of the route must be GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS or ANY")
+	ErrDuplicateRoute          = errors.New("duplicate route")
+	ErrGreedyPathUnsupported   = errors.New("the greedy path parameter, e.g. {proxy+}, is only supported by aws")
+	ErrInvalidAuth             = errors.New("auth must be none, apiKey or jwt")
+	ErrAPIKeyUnsupported       = errors.New("apiKey auth is only supported by alicloud, the HTTP APIs of aws have no API keys")
+	ErrEmptyJWTConfig          = errors.New("jwt auth requires the jwt config in the platform config")
+	ErrInvalidJWTConfig        = errors.New("invalid jwt config")
+	ErrInvalidThrottling       = errors.New("rateLimit and burstLimit of throttling must be positive if exist")
+	ErrInvalidStage            = errors.New("invalid stage")
+	ErrInvalidDomain           = errors.New("invalid domain")
+	ErrCustomDomainUnsupported = errors.New("domain is only supported by aws")
+	ErrEmptyCertificateARN     = errors.New("domain requires the certificateARN of the ACM certificate in the platform config")
+	ErrEmptyBackend            = errors.New("empty backend in the platform config, which requires either the url or the private integration")
+	ErrConflictingBackend      = errors.New("url and the private integration of the backend are mutually exclusive")
+	ErrInvalidBackendURL       = errors.New("url of the backend must be an absolute http or https url")
+	ErrInvalidBackend          = errors.New("invalid private integration of the backend")
+	ErrInvalidPort             = errors.New("port must be between 1 and 65535")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, APIGateway{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&APIGateway{})
+}
+
+// APIGateway describes the cloud API gateway in front of the internal endpoint of the workload,
+// which exposes the routes of the workload with the auth, the throttling and the custom domain.
+type APIGateway struct {
+	// Routes are the routes exposed by the gateway, which are forwarded to the same paths of the
+	// backend.
+	Routes []Route `json:"routes,omitempty" yaml:"routes,omitempty"`
+	// Auth is the auth type of the routes, which is none, apiKey or jwt, defaults to none.
+	Auth string `json:"auth,omitempty" yaml:"auth,omitempty"`
+	// Throttling is the rate limit of the requests to the routes.
+	Throttling *Throttling `json:"throttling,omitempty" yaml:"throttling,omitempty"`
+	// Stage is the stage the routes are deployed to, which defaults to the stack name on aws and
+	// RELEASE on alicloud.
+	Stage string `json:"stage,omitempty" yaml:"stage,omitempty"`
+	// Domain is the custom domain of the gateway, which is only supported by aws.
+	Domain *Domain `json:"domain,omitempty" yaml:"domain,omitempty"`
+	// Port is the port of the internal load balancer of the workload, which is required by the
+	// private integration of alicloud.
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+
+	// The platform config of the apigateway module.
+	platform PlatformConfig
+}
+
+// Route describes a route of the gateway.
+type Route struct {
+	// Path is the path of the route, e.g. /orders/{id}, where the greedy parameter, e.g.
+	// /{proxy+}, is only supported by aws as the last segment.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Method is the HTTP method of the route, which defaults to ANY.
+	Method string `json:"method,omitempty" yaml:"method,omitempty"`
+}
+
+// Throttling describes the rate limit of the requests.
+type Throttling struct {
+	// RateLimit is the steady-state requests per second.
+	RateLimit int `json:"rateLimit,omitempty" yaml:"rateLimit,omitempty"`
+	// BurstLimit is the requests allowed in a burst, which is only supported by aws.
+	BurstLimit int `json:"burstLimit,omitempty" yaml:"burstLimit,omitempty"`
+}
+
+// Domain describes the custom domain of the gateway.
+type Domain struct {
+	// Name is the domain name, e.g. api.example.com.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// BasePath is the path of the domain the stage is mapped to, which defaults to the root.
+	BasePath string `json:"basePath,omitempty" yaml:"basePath,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the apigateway module in workspace.
+type PlatformConfig struct {
+	// Cloud is the cloud vendor providing the API gateway, aws or alicloud.
+	Cloud string `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	// Region is the region of the cloud provider, which falls back to AWS_REGION or
+	// ALICLOUD_REGION.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// Backend is the internal endpoint of the workload the routes are forwarded to.
+	Backend *Backend `json:"backend,omitempty" yaml:"backend,omitempty"`
+	// JWT is the identity provider issuing the JWT of the jwt auth.
+	JWT *JWTConfig `json:"jwt,omitempty" yaml:"jwt,omitempty"`
+	// CertificateARN is the ARN of the ACM certificate of the custom domains on aws.
+	CertificateARN string `json:"certificateARN,omitempty" yaml:"certificateARN,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *APIGateway `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// Backend describes the internal endpoint of the workload, which is either reached by the url, or
+// by the private integration through the VPC of the cloud vendor.
+type Backend struct {
+	// URL is the url of the endpoint, e.g. http://$app.internal.example.com, where "$project",
+	// "$stack" and "$app" are replaced by the project name, the stack name and the app name.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// VPCLinkID is the ID of the VPC link of the private integration on aws.
+	VPCLinkID string `json:"vpcLinkID,omitempty" yaml:"vpcLinkID,omitempty"`
+	// ListenerARN is the ARN of the listener of the internal load balancer on aws.
+	ListenerARN string `json:"listenerARN,omitempty" yaml:"listenerARN,omitempty"`
+	// VPCID is the ID of the VPC of the private integration on alicloud.
+	VPCID string `json:"vpcID,omitempty" yaml:"vpcID,omitempty"`
+	// InstanceID is the ID of the internal load balancer on alicloud.
+	InstanceID string `json:"instanceID,omitempty" yaml:"instanceID,omitempty"`
+}
+
+// JWTConfig describes the identity provider issuing the JWT.
+type JWTConfig struct {
+	// Issuer is the issuer of the JWT, which is required by aws.
+	Issuer string `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// Audiences are the audiences accepted by aws, at least one is required.
+	Audiences []string `json:"audiences,omitempty" yaml:"audiences,omitempty"`
+	// JWK is the JSON Web Key of the public key verifying the JWT, which is required by alicloud.
+	JWK string `json:"jwk,omitempty" yaml:"jwk,omitempty"`
+}
+
+// Generate implements the generation logic of the apigateway module.
+func (apigateway *APIGateway) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "apigateway", &response, &err)
+
+	// Attach the connection info of the gateway, and finalize the generated resources with the
+	// standard metadata, the policy and Pod Security Standards checks, and the preview summary if
+	// enabled in the workspace context.
+	var endpoint string
+	defer func() {
+		if err == nil {
+			name := moduleutil.AppName(request) + "-apigateway-connection-info"
+			if err = moduleutil.AttachConnectionInfo("apigateway", name, request, response, map[string]string{"endpoint": endpoint}); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("apigateway", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// APIGateway does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("APIGateway does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the apigateway module.
+	if err := apigateway.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("apigateway", moduleutil.PhaseComplete, err)
+	}
+	if apigateway.Stage == "" {
+		apigateway.Stage = request.Stack
+	}
+
+	var resources []kusionapiv1.Resource
+	switch apigateway.platform.Cloud {
+	case CloudAWS:
+		resources, endpoint, err = apigateway.generateAWSResources(request)
+	case CloudAlicloud:
+		resources, endpoint, err = apigateway.generateAlicloudResources(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the apigateway module.
+func (apigateway *APIGateway) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, APIGateway{}); err != nil {
+		return moduleutil.NewModuleError("apigateway", moduleutil.PhaseValidate, fmt.Errorf("validate apigateway dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("apigateway", moduleutil.PhaseValidate, fmt.Errorf("validate apigateway platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, apigateway); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &apigateway.platform); err != nil {
+			return err
+		}
+	}
+
+	apigateway.platform.Cloud = strings.ToLower(apigateway.platform.Cloud)
+	if apigateway.platform.Region == "" {
+		switch apigateway.platform.Cloud {
+		case CloudAWS:
+			apigateway.platform.Region = os.Getenv("AWS_REGION")
+		case CloudAlicloud:
+			apigateway.platform.Region = os.Getenv("ALICLOUD_REGION")
+		}
+	}
+	if apigateway.Auth == "" {
+		apigateway.Auth = AuthNone
+	}
+	if apigateway.Stage == "" && apigateway.platform.Cloud == CloudAlicloud {
+		apigateway.Stage = defaultAlicloudStage
+	}
+	for i := range apigateway.Routes {
+		apigateway.Routes[i].Method = strings.ToUpper(apigateway.Routes[i].Method)
+		if apigateway.Routes[i].Method == "" {
+			apigateway.Routes[i].Method = defaultMethod
+		}
+	}
+
+	return apigateway.Validate()
+}
+
+// Validate validates whether the configs of the apigateway module are valid.
+func (apigateway *APIGateway) Validate() error {
+	cloud := apigateway.platform.Cloud
+	switch cloud {
+	case "":
+		return ErrEmptyCloud
+	case CloudAWS, CloudAlicloud:
+	default:
+		return fmt.Errorf("%w, got %s", ErrUnsupportedCloud, cloud)
+	}
+	if apigateway.platform.Region == "" {
+		return ErrEmptyRegion
+	}
+
+	if err := apigateway.validateRoutes(); err != nil {
+		return err
+	}
+	if err := apigateway.validateBackend(); err != nil {
+		return err
+	}
+	if err := apigateway.validateAuth(); err != nil {
+		return err
+	}
+
+	if t := apigateway.Throttling; t != nil && (t.RateLimit < 0 || t.BurstLimit < 0 || t.RateLimit == 0 && t.BurstLimit == 0) {
+		return ErrInvalidThrottling
+	}
+
+	if stage := apigateway.Stage; stage != "" {
+		if cloud == CloudAlicloud && !slices.Contains(alicloudStages, stage) {
+			return fmt.Errorf("%w, the stage of alicloud must be RELEASE, PRE or TEST, got %s", ErrInvalidStage, stage)
+		}
+		if cloud == CloudAWS && !awsStagePattern.MatchString(stage) {
+			return fmt.Errorf("%w, got %s", ErrInvalidStage, stage)
+		}
+	}
+
+	if domain := apigateway.Domain; domain != nil {
+		if cloud != CloudAWS {
+			return ErrCustomDomainUnsupported
+		}
+		if !domainPattern.MatchString(domain.Name) {
+			return fmt.Errorf("%w, got name %q", ErrInvalidDomain, domain.Name)
+		}
+		if domain.BasePath != "" && !basePathPattern.MatchString(domain.BasePath) {
+			return fmt.Errorf("%w, got basePath %q", ErrInvalidDomain, domain.BasePath)
+		}
+		if apigateway.platform.CertificateARN == "" {
+			return ErrEmptyCertificateARN
+		}
+	}
+
+	return nil
+}
+
+// validateRoutes validates the paths and the methods of the routes, which must be unique.
+func (apigateway *APIGateway) validateRoutes() error {
+	if len(apigateway.Routes) == 0 {
+		return ErrEmptyRoutes
+	}
+	routes := map[string]bool{}
+	names := map[string]bool{}
+	for _, route := range apigateway.Routes {
+		if !slices.Contains(routeMethods, route.Method) {
+			return fmt.Errorf("%w, got %s", ErrInvalidRouteMethod, route.Method)
+		}
+		if err := validatePath(route.Path); err != nil {
+			return err
+		}
+		if strings.HasSuffix(route.Path, "+}") && apigateway.platform.Cloud != CloudAWS {
+			return fmt.Errorf("%w, got %s", ErrGreedyPathUnsupported, route.Path)
+		}
+		// The routes are also told apart by their names on alicloud.
+		key := route.Method + " " + route.Path
+		if routes[key] || names[route.name()] {
+			return fmt.Errorf("%w, got %s", ErrDuplicateRoute, key)
+		}
+		routes[key] = true
+		names[route.name()] = true
+	}
+	return nil
+}
+
+// validatePath validates the path of the route, where the greedy parameter is only allowed as the
+// last segment.
+func validatePath(path string) error {
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("%w, got %q", ErrInvalidRoutePath, path)
+	}
+	if path == "/" {
+		return nil
+	}
+	segments := strings.Split(path[1:], "/")
+	for i, segment := range segments {
+		if !pathSegmentPattern.MatchString(segment) || (strings.HasSuffix(segment, "+}") && i != len(segments)-1) {
+			return fmt.Errorf("%w, got %q", ErrInvalidRoutePath, path)
+		}
+	}
+	return nil
+}
+
+// validateBackend validates the backend, which is either the url or the private integration of
+// the cloud vendor.
+func (apigateway *APIGateway) validateBackend() error {
+	backend := apigateway.platform.Backend
+	if backend == nil {
+		return ErrEmptyBackend
+	}
+	private := backend.VPCLinkID != "" || backend.ListenerARN != "" || backend.VPCID != "" || backend.InstanceID != ""
+	switch {
+	case backend.URL == "" && !private:
+		return ErrEmptyBackend
+	case backend.URL != "" && private:
+		return ErrConflictingBackend
+	case backend.URL != "":
+		u, err := url.Parse(backend.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" {
+			return fmt.Errorf("%w, got %s", ErrInvalidBackendURL, backend.URL)
+		}
+		return nil
+	}
+
+	if apigateway.platform.Cloud == CloudAWS {
+		if backend.VPCLinkID == "" || !awsARNPrefixPattern.MatchString(backend.ListenerARN) || backend.VPCID != "" || backend.InstanceID != "" {
+			return fmt.Errorf("%w, aws requires the vpcLinkID and the listenerARN", ErrInvalidBackend)
+		}
+		return nil
+	}
+	if backend.VPCID == "" || backend.InstanceID == "" || backend.VPCLinkID != "" || backend.ListenerARN != "" {
+		return fmt.Errorf("%w, alicloud requires the vpcID and the instanceID", ErrInvalidBackend)
+	}
+	if apigateway.Port < 1 || apigateway.Port > 65535 {
+		return fmt.Errorf("%w, got %d", ErrInvalidPort, apigateway.Port)
+	}
+	return nil
+}
+
+// validateAuth validates the auth type against the cloud vendor and the jwt config.
+func (apigateway *APIGateway) validateAuth() error {
+	switch apigateway.Auth {
+	case AuthNone:
+		return nil
+	case AuthAPIKey:
+		if apigateway.platform.Cloud == CloudAWS {
+			return ErrAPIKeyUnsupported
+		}
+		return nil
+	case AuthJWT:
+	default:
+		return fmt.Errorf("%w, got %s", ErrInvalidAuth, apigateway.Auth)
+	}
+
+	jwt := apigateway.platform.JWT
+	if jwt == nil {
+		return ErrEmptyJWTConfig
+	}
+	if apigateway.platform.Cloud == CloudAWS {
+		if u, err := url.Parse(jwt.Issuer); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("%w, the issuer must be an https url, got %q", ErrInvalidJWTConfig, jwt.Issuer)
+		}
+		if len(jwt.Audiences) == 0 {
+			return fmt.Errorf("%w, aws requires at least one audience", ErrInvalidJWTConfig)
+		}
+		return nil
+	}
+	jwk := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(jwt.JWK), &jwk); err != nil || jwk["kty"] == nil {
+		return fmt.Errorf("%w, alicloud requires the jwk of the public key in JSON", ErrInvalidJWTConfig)
+	}
+	return nil
+}
+
+// backendURL returns the url of the backend with the placeholders replaced.
+func (apigateway *APIGateway) backendURL(request *module.GeneratorRequest) string {
+	return strings.NewReplacer(
+		"$project", request.Project,
+		"$stack", request.Stack,
+		"$app", request.App,
+	).Replace(apigateway.platform.Backend.URL)
+}
+
+// name returns the name of the route derived from the method and the path, e.g. get_orders_id of
+// GET /orders/{id}.
+func (r Route) name() string {
+	name := strings.ToLower(r.Method)
+	for _, segment := range strings.Split(r.Path, "/") {
+		segment = strings.Trim(segment, "{}+")
+		if segment != "" {
+			name += "_" + segment
+		}
+	}
+	return strings.NewReplacer("-", "_", ".", "_", "~", "_").Replace(name)
+}
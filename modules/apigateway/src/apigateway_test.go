@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestAPIGateway_Generate(t *testing.T) {
+	awsConfig := kusionapiv1.GenericConfig{
+		"cloud":          "aws",
+		"region":         "us-west-2",
+		"backend":        map[string]interface{}{"url": "http://$app.internal.example.com"},
+		"certificateARN": "arn:aws:acm:us-west-2:123456789012:certificate/abc",
+		"jwt": map[string]interface{}{
+			"issuer":    "https://auth.example.com",
+			"audiences": []interface{}{"orders"},
+		},
+	}
+	alicloudConfig := kusionapiv1.GenericConfig{
+		"cloud":   "alicloud",
+		"region":  "cn-hangzhou",
+		"backend": map[string]interface{}{"vpcID": "vpc-123", "instanceID": "lb-123"},
+	}
+	routes := []interface{}{
+		map[string]interface{}{"path": "/orders", "method": "post"},
+		map[string]interface{}{"path": "/orders/{id}", "method": "GET"},
+	}
+
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+		expectedErr    error
+		expectedTypes  []string
+	}{
+		{
+			name: "aws with jwt and domain",
+			devConfig: kusionapiv1.Accessory{
+				"routes":     routes,
+				"auth":       "jwt",
+				"throttling": map[string]interface{}{"rateLimit": 100, "burstLimit": 200},
+				"domain":     map[string]interface{}{"name": "api.example.com", "basePath": "orders"},
+			},
+			platformConfig: awsConfig,
+			expectedTypes:  []string{awsAPI, awsStage, awsDomainName, awsAPIMapping},
+		},
+		{
+			name: "alicloud with api key and throttling",
+			devConfig: kusionapiv1.Accessory{
+				"routes":     routes,
+				"auth":       "apiKey",
+				"throttling": map[string]interface{}{"rateLimit": 100},
+				"port":       8080,
+			},
+			platformConfig: alicloudConfig,
+			expectedTypes: []string{
+				alicloudGroup, alicloudVPCAccess, alicloudAPI, alicloudAPI,
+				alicloudApp, alicloudAppAttachment, alicloudAppAttachment,
+				alicloudPlugin, alicloudPluginAttachment, alicloudPluginAttachment,
+			},
+		},
+		{
+			name:           "api key on aws",
+			devConfig:      kusionapiv1.Accessory{"routes": routes, "auth": "apiKey"},
+			platformConfig: awsConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrAPIKeyUnsupported,
+		},
+		{
+			name: "domain on alicloud",
+			devConfig: kusionapiv1.Accessory{
+				"routes": routes,
+				"port":   8080,
+				"domain": map[string]interface{}{"name": "api.example.com"},
+			},
+			platformConfig: alicloudConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrCustomDomainUnsupported,
+		},
+		{
+			name:           "empty routes",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: awsConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrEmptyRoutes,
+		},
+		{
+			name:          "empty cloud",
+			devConfig:     kusionapiv1.Accessory{"routes": routes},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrEmptyCloud,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&APIGateway{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedTypes)) {
+				return
+			}
+			for i, resourceType := range tt.expectedTypes {
+				assert.Equal(t, resourceType, response.Resources[i].Extensions["resourceType"])
+			}
+		})
+	}
+}
+
+func TestAPIGateway_Validate(t *testing.T) {
+	aws := PlatformConfig{Cloud: CloudAWS, Region: "us-west-2", Backend: &Backend{URL: "http://orders.internal"}}
+	alicloud := PlatformConfig{Cloud: CloudAlicloud, Region: "cn-hangzhou", Backend: &Backend{URL: "http://orders.internal"}}
+	routes := []Route{{Path: "/orders", Method: "GET"}}
+
+	tests := []struct {
+		name        string
+		apigateway  APIGateway
+		expectedErr error
+	}{
+		{
+			name:       "valid",
+			apigateway: APIGateway{Routes: []Route{{Path: "/{proxy+}", Method: "ANY"}}, Auth: AuthNone, Stage: "dev", platform: aws},
+		},
+		{
+			name:        "unsupported cloud",
+			apigateway:  APIGateway{platform: PlatformConfig{Cloud: "gcp", Region: "us-west1"}},
+			expectedErr: ErrUnsupportedCloud,
+		},
+		{
+			name:        "invalid path",
+			apigateway:  APIGateway{Routes: []Route{{Path: "orders", Method: "GET"}}, platform: aws},
+			expectedErr: ErrInvalidRoutePath,
+		},
+		{
+			name:        "greedy parameter in the middle",
+			apigateway:  APIGateway{Routes: []Route{{Path: "/{proxy+}/items", Method: "GET"}}, platform: aws},
+			expectedErr: ErrInvalidRoutePath,
+		},
+		{
+			name:        "greedy parameter on alicloud",
+			apigateway:  APIGateway{Routes: []Route{{Path: "/{proxy+}", Method: "GET"}}, platform: alicloud},
+			expectedErr: ErrGreedyPathUnsupported,
+		},
+		{
+			name:        "invalid method",
+			apigateway:  APIGateway{Routes: []Route{{Path: "/orders", Method: "FETCH"}}, platform: aws},
+			expectedErr: ErrInvalidRouteMethod,
+		},
+		{
+			name:        "duplicate route",
+			apigateway:  APIGateway{Routes: []Route{{Path: "/orders", Method: "GET"}, {Path: "/orders", Method: "GET"}}, platform: aws},
+			expectedErr: ErrDuplicateRoute,
+		},
+		{
+			name: "conflicting backend",
+			apigateway: APIGateway{Routes: routes, platform: PlatformConfig{
+				Cloud: CloudAWS, Region: "us-west-2", Backend: &Backend{URL: "http://orders.internal", VPCLinkID: "abc"},
+			}},
+			expectedErr: ErrConflictingBackend,
+		},
+		{
+			name: "aws private integration without listener",
+			apigateway: APIGateway{Routes: routes, platform: PlatformConfig{
+				Cloud: CloudAWS, Region: "us-west-2", Backend: &Backend{VPCLinkID: "abc"},
+			}},
+			expectedErr: ErrInvalidBackend,
+		},
+		{
+			name: "alicloud private integration without port",
+			apigateway: APIGateway{Routes: routes, Auth: AuthNone, platform: PlatformConfig{
+				Cloud: CloudAlicloud, Region: "cn-hangzhou", Backend: &Backend{VPCID: "vpc-123", InstanceID: "lb-123"},
+			}},
+			expectedErr: ErrInvalidPort,
+		},
+		{
+			name:        "invalid backend url",
+			apigateway:  APIGateway{Routes: routes, platform: PlatformConfig{Cloud: CloudAWS, Region: "us-west-2", Backend: &Backend{URL: "orders.internal"}}},
+			expectedErr: ErrInvalidBackendURL,
+		},
+		{
+			name:        "jwt without config",
+			apigateway:  APIGateway{Routes: routes, Auth: AuthJWT, platform: aws},
+			expectedErr: ErrEmptyJWTConfig,
+		},
+		{
+			name: "alicloud jwt without jwk",
+			apigateway: APIGateway{Routes: routes, Auth: AuthJWT, platform: PlatformConfig{
+				Cloud: CloudAlicloud, Region: "cn-hangzhou", Backend: alicloud.Backend, JWT: &JWTConfig{Issuer: "https://auth.example.com"},
+			}},
+			expectedErr: ErrInvalidJWTConfig,
+		},
+		{
+			name:        "invalid throttling",
+			apigateway:  APIGateway{Routes: routes, Auth: AuthNone, Throttling: &Throttling{RateLimit: -1}, platform: aws},
+			expectedErr: ErrInvalidThrottling,
+		},
+		{
+			name:        "invalid alicloud stage",
+			apigateway:  APIGateway{Routes: routes, Auth: AuthNone, Stage: "dev", platform: alicloud},
+			expectedErr: ErrInvalidStage,
+		},
+		{
+			name:        "invalid domain",
+			apigateway:  APIGateway{Routes: routes, Auth: AuthNone, Domain: &Domain{Name: "api_example"}, platform: aws},
+			expectedErr: ErrInvalidDomain,
+		},
+		{
+			name:        "domain without certificate",
+			apigateway:  APIGateway{Routes: routes, Auth: AuthNone, Domain: &Domain{Name: "api.example.com"}, platform: aws},
+			expectedErr: ErrEmptyCertificateARN,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.apigateway.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRoute_Name(t *testing.T) {
+	assert.Equal(t, "get_orders_id", Route{Path: "/orders/{id}", Method: "GET"}.name())
+	assert.Equal(t, "any_proxy", Route{Path: "/{proxy+}", Method: "ANY"}.name())
+	assert.Equal(t, "post_v1_order_items", Route{Path: "/v1/order-items", Method: "POST"}.name())
+	assert.Equal(t, "any", Route{Path: "/", Method: "ANY"}.name())
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	awsAPI        = "aws_apigatewayv2_api"
+	awsStage      = "aws_apigatewayv2_stage"
+	awsDomainName = "aws_apigatewayv2_domain_name"
+	awsAPIMapping = "aws_apigatewayv2_api_mapping"
+
+	// jwtSecurityScheme is the name of the security scheme of the JWT authorizer in the OpenAPI
+	// definition.
+	jwtSecurityScheme = "jwt"
+)
+
+var defaultAWSProviderCfg = module.ProviderConfig{
+	Source:  "hashicorp/aws",
+	Version: "5.0.1",
+}
+
+// generateAWSResources generates the HTTP API of AWS API Gateway with the stage and the custom
+// domain, and returns the resources along with the endpoint of the gateway. The routes, the
+// integrations and the authorizer are defined by the OpenAPI definition of the API, so that they
+// are replaced as a whole when the routes change.
+func (apigateway *APIGateway) generateAWSResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, string, error) {
+	providerCfg := defaultAWSProviderCfg
+	providerCfg.ProviderMeta = map[string]any{"region": apigateway.platform.Region}
+	name := moduleutil.AppName(request)
+
+	body, err := json.Marshal(apigateway.openAPIDefinition(request, name))
+	if err != nil {
+		return nil, "", err
+	}
+	api, err := moduleutil.WrapTFResource(providerCfg, awsAPI, name, map[string]interface{}{
+		"name":             name,
+		"protocol_type":    "HTTP",
+		"body":             string(body),
+		"fail_on_warnings": true,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Deploy the API to the stage automatically, whose default route settings throttle the
+	// requests to all the routes.
+	stageAttrs := map[string]interface{}{
+		"api_id":      module.KusionPathDependency(api.ID, "id"),
+		"name":        apigateway.Stage,
+		"auto_deploy": true,
+	}
+	if t := apigateway.Throttling; t != nil {
+		settings := map[string]interface{}{}
+		if t.RateLimit > 0 {
+			settings["throttling_rate_limit"] = t.RateLimit
+		}
+		if t.BurstLimit > 0 {
+			settings["throttling_burst_limit"] = t.BurstLimit
+		}
+		stageAttrs["default_route_settings"] = []map[string]interface{}{settings}
+	}
+	stage, err := moduleutil.WrapTFResource(providerCfg, awsStage, name, stageAttrs)
+	if err != nil {
+		return nil, "", err
+	}
+	resources := []kusionapiv1.Resource{*api, *stage}
+	endpoint := module.KusionPathDependency(stage.ID, "invoke_url")
+
+	// Map the stage to the base path of the custom domain, which is pointed to the regional
+	// endpoint of the domain by the DNS of the platform.
+	if domain := apigateway.Domain; domain != nil {
+		domainName, err := moduleutil.WrapTFResource(providerCfg, awsDomainName, name, map[string]interface{}{
+			"domain_name": domain.Name,
+			"domain_name_configuration": []map[string]interface{}{{
+				"certificate_arn": apigateway.platform.CertificateARN,
+				"endpoint_type":   "REGIONAL",
+				"security_policy": "TLS_1_2",
+			}},
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		mappingAttrs := map[string]interface{}{
+			"api_id":      module.KusionPathDependency(api.ID, "id"),
+			"domain_name": module.KusionPathDependency(domainName.ID, "id"),
+			"stage":       module.KusionPathDependency(stage.ID, "id"),
+		}
+		if domain.BasePath != "" {
+			mappingAttrs["api_mapping_key"] = domain.BasePath
+		}
+		mapping, err := moduleutil.WrapTFResource(providerCfg, awsAPIMapping, name, mappingAttrs)
+		if err != nil {
+			return nil, "", err
+		}
+		resources = append(resources, *domainName, *mapping)
+		endpoint = "https://" + domain.Name + "/" + domain.BasePath
+	}
+
+	return resources, endpoint, nil
+}
+
+// openAPIDefinition returns the OpenAPI definition of the HTTP API, where each route is proxied
+// to the backend by the integration extension of AWS.
+func (apigateway *APIGateway) openAPIDefinition(request *module.GeneratorRequest, name string) map[string]interface{} {
+	backend := apigateway.platform.Backend
+	paths := map[string]interface{}{}
+	for _, route := range apigateway.Routes {
+		integration := map[string]interface{}{
+			"type":                 "http_proxy",
+			"httpMethod":           route.Method,
+			"payloadFormatVersion": "1.0",
+		}
+		if backend.VPCLinkID != "" {
+			// The private integration forwards the path of the request to the listener as is.
+			integration["connectionType"] = "VPC_LINK"
+			integration["connectionId"] = backend.VPCLinkID
+			integration["uri"] = backend.ListenerARN
+		} else {
+			// The greedy parameter of the route is referenced by its name in the uri.
+			integration["uri"] = strings.TrimSuffix(apigateway.backendURL(request), "/") +
+				strings.ReplaceAll(route.Path, "+}", "}")
+		}
+
+		operation := map[string]interface{}{"x-amazon-apigateway-integration": integration}
+		if apigateway.Auth == AuthJWT {
+			operation["security"] = []interface{}{map[string]interface{}{jwtSecurityScheme: []interface{}{}}}
+		}
+
+		method := strings.ToLower(route.Method)
+		if route.Method == "ANY" {
+			method = "x-amazon-apigateway-any-method"
+		}
+		operations, ok := paths[route.Path].(map[string]interface{})
+		if !ok {
+			operations = map[string]interface{}{}
+			paths[route.Path] = operations
+		}
+		operations[method] = operation
+	}
+
+	definition := map[string]interface{}{
+		"openapi": "3.0.1",
+		"info":    map[string]interface{}{"title": name, "version": "1.0"},
+		"paths":   paths,
+	}
+	if apigateway.Auth == AuthJWT {
+		definition["components"] = map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				jwtSecurityScheme: map[string]interface{}{
+					"type":  "oauth2",
+					"flows": map[string]interface{}{},
+					"x-amazon-apigateway-authorizer": map[string]interface{}{
+						"type":           "jwt",
+						"identitySource": "$request.header.Authorization",
+						"jwtConfiguration": map[string]interface{}{
+							"issuer":   apigateway.platform.JWT.Issuer,
+							"audience": apigateway.platform.JWT.Audiences,
+						},
+					},
+				},
+			},
+		}
+	}
+	return definition
+}
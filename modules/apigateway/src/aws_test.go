@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestAPIGateway_GenerateAWSResources(t *testing.T) {
+	request := testutil.NewRequest().WithServiceWorkload("Deployment").Build()
+	apigateway := &APIGateway{
+		Routes: []Route{
+			{Path: "/orders", Method: "POST"},
+			{Path: "/orders", Method: "GET"},
+			{Path: "/{proxy+}", Method: "ANY"},
+		},
+		Auth:       AuthJWT,
+		Throttling: &Throttling{RateLimit: 100},
+		Stage:      "dev",
+		Domain:     &Domain{Name: "api.example.com"},
+		platform: PlatformConfig{
+			Cloud:          CloudAWS,
+			Region:         "us-west-2",
+			Backend:        &Backend{URL: "http://$app.$project.internal/api/"},
+			JWT:            &JWTConfig{Issuer: "https://auth.example.com", Audiences: []string{"orders"}},
+			CertificateARN: "arn:aws:acm:us-west-2:123456789012:certificate/abc",
+		},
+	}
+
+	resources, endpoint, err := apigateway.generateAWSResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 4) {
+		return
+	}
+	assert.Equal(t, "https://api.example.com/", endpoint)
+	assert.Equal(t, "default-dev-foo", resourceName(resources[0]))
+	assert.Equal(t, map[string]any{"region": "us-west-2"}, resources[0].Extensions["providerMeta"])
+
+	stage := resources[1].Attributes
+	assert.Equal(t, module.KusionPathDependency(resources[0].ID, "id"), stage["api_id"])
+	assert.Equal(t, []map[string]interface{}{{"throttling_rate_limit": 100}}, stage["default_route_settings"])
+	mapping := resources[3].Attributes
+	assert.Equal(t, module.KusionPathDependency(resources[1].ID, "id"), mapping["stage"])
+	assert.NotContains(t, mapping, "api_mapping_key")
+
+	definition := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(resources[0].Attributes["body"].(string)), &definition))
+	paths := definition["paths"].(map[string]interface{})
+	orders := paths["/orders"].(map[string]interface{})
+	assert.Contains(t, orders, "get")
+	assert.Equal(t, map[string]interface{}{
+		"x-amazon-apigateway-integration": map[string]interface{}{
+			"type":                 "http_proxy",
+			"httpMethod":           "POST",
+			"payloadFormatVersion": "1.0",
+			"uri":                  "http://foo.default.internal/api/orders",
+		},
+		"security": []interface{}{map[string]interface{}{"jwt": []interface{}{}}},
+	}, orders["post"])
+	proxy := paths["/{proxy+}"].(map[string]interface{})["x-amazon-apigateway-any-method"].(map[string]interface{})
+	assert.Equal(t, "http://foo.default.internal/api/{proxy}", proxy["x-amazon-apigateway-integration"].(map[string]interface{})["uri"])
+	authorizer := definition["components"].(map[string]interface{})["securitySchemes"].(map[string]interface{})["jwt"].(map[string]interface{})["x-amazon-apigateway-authorizer"]
+	assert.Equal(t, map[string]interface{}{
+		"type":             "jwt",
+		"identitySource":   "$request.header.Authorization",
+		"jwtConfiguration": map[string]interface{}{"issuer": "https://auth.example.com", "audience": []interface{}{"orders"}},
+	}, authorizer)
+}
+
+func TestAPIGateway_OpenAPIDefinitionOfVPCLink(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	apigateway := &APIGateway{
+		Routes: []Route{{Path: "/orders/{id}", Method: "GET"}},
+		Auth:   AuthNone,
+		platform: PlatformConfig{Backend: &Backend{
+			VPCLinkID:   "abc123",
+			ListenerARN: "arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/orders/1/2",
+		}},
+	}
+
+	definition := apigateway.openAPIDefinition(request, "default-dev-foo")
+	assert.NotContains(t, definition, "components")
+	operation := definition["paths"].(map[string]interface{})["/orders/{id}"].(map[string]interface{})["get"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"x-amazon-apigateway-integration": map[string]interface{}{
+			"type":                 "http_proxy",
+			"httpMethod":           "GET",
+			"payloadFormatVersion": "1.0",
+			"connectionType":       "VPC_LINK",
+			"connectionId":         "abc123",
+			"uri":                  "arn:aws:elasticloadbalancing:us-west-2:123456789012:listener/app/orders/1/2",
+		},
+	}, operation)
+}
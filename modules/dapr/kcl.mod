@@ -0,0 +1,3 @@
+[package]
+name = "dapr"
+version = "0.1.0"
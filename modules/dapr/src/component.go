@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	componentAPIVersion = "dapr.io/v1alpha1"
+	componentKind       = "Component"
+)
+
+// generateComponent generates the Dapr Component scoped to the App. The metadata referencing the
+// outputs of the other modules are resolved to the secretKeyRef of the Secrets storing them, or the
+// names of the Secrets, and the Component depends on the Secrets.
+func generateComponent(request *module.GeneratorRequest, appID, name string, component Component) (*kusionapiv1.Resource, error) {
+	keys := make([]string, 0, len(component.Metadata))
+	for key := range component.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var dependsOn []string
+	metadata := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		item, id, err := resolveMetadata(request, name, key, component.Metadata[key])
+		if err != nil {
+			return nil, err
+		}
+		metadata = append(metadata, item)
+		if id != "" && !slices.Contains(dependsOn, id) {
+			dependsOn = append(dependsOn, id)
+		}
+	}
+
+	objectMeta := metav1.ObjectMeta{Name: name, Namespace: request.Project}
+	// Round trip the object through JSON, so that it only holds the JSON values accepted by the
+	// unstructured object.
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": componentAPIVersion,
+		"kind":       componentKind,
+		"metadata":   map[string]interface{}{"name": objectMeta.Name, "namespace": objectMeta.Namespace},
+		"spec": map[string]interface{}{
+			"type":     component.Type,
+			"version":  component.Version,
+			"metadata": metadata,
+		},
+		// The Component is only loaded by the sidecars of the App.
+		"scopes": []string{appID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err = json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, err
+	}
+
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: componentAPIVersion, Kind: componentKind}, objectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, obj)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = dependsOn
+	return resource, nil
+}
+
+// resolveMetadata returns the metadata item of the Component, and the ID of the Secret if the
+// value references the output of the other module.
+func resolveMetadata(request *module.GeneratorRequest, component, key, value string) (map[string]interface{}, string, error) {
+	ref, err := moduleutil.ParseOutputRef(request, value)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w, metadata %s of component %s", err, key, component)
+	}
+	if ref == nil {
+		return map[string]interface{}{"name": key, "value": value}, "", nil
+	}
+	if ref.Key == "" {
+		return map[string]interface{}{"name": key, "value": ref.SecretName}, ref.SecretID, nil
+	}
+	return map[string]interface{}{
+		"name":         key,
+		"secretKeyRef": map[string]interface{}{"name": ref.SecretName, "key": ref.Key},
+	}, ref.SecretID, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+	"testutil"
+)
+
+func TestGenerateComponent(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	secretID := "v1:Secret:default:default-dev-foo-postgres-postgres"
+	secretName := module.KusionPathDependency(secretID, "metadata.name")
+
+	resource, err := generateComponent(request, "orders", "statestore", Component{
+		Type:    "state.postgresql",
+		Version: "v1",
+		Metadata: map[string]string{
+			"host":            "${postgres.host}",
+			"user":            "${postgres.username}",
+			"password":        "${postgres.password}",
+			"tableName":       "state",
+			"credentialStore": "${postgres.secretName}",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{secretID}, resource.DependsOn)
+	assert.Equal(t, []interface{}{"orders"}, resource.Attributes["scopes"])
+
+	spec := resource.Attributes["spec"].(map[string]interface{})
+	assert.Equal(t, "state.postgresql", spec["type"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "credentialStore", "value": secretName},
+		map[string]interface{}{"name": "host", "secretKeyRef": map[string]interface{}{"name": secretName, "key": "hostAddress"}},
+		map[string]interface{}{"name": "password", "secretKeyRef": map[string]interface{}{"name": secretName, "key": "password"}},
+		map[string]interface{}{"name": "tableName", "value": "state"},
+		map[string]interface{}{"name": "user", "secretKeyRef": map[string]interface{}{"name": secretName, "key": "username"}},
+	}, spec["metadata"])
+}
+
+func TestGenerateComponent_InvalidOutputs(t *testing.T) {
+	request := testutil.NewRequest().Build()
+
+	_, err := generateComponent(request, "orders", "statestore", Component{
+		Type:     "state.postgresql",
+		Metadata: map[string]string{"connectionString": "host=${postgres.host}"},
+	})
+	assert.ErrorIs(t, err, moduleutil.ErrEmbeddedOutput)
+
+	_, err = generateComponent(request, "orders", "statestore", Component{
+		Type:     "state.mysql",
+		Metadata: map[string]string{"database": "${mysql.database}"},
+	})
+	assert.ErrorIs(t, err, moduleutil.ErrUnknownOutput)
+
+	// The references to the unknown modules are left to the component.
+	resource, err := generateComponent(request, "orders", "pubsub", Component{
+		Type:     "pubsub.kafka",
+		Metadata: map[string]string{"brokers": "${kafka.brokers}"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, resource.DependsOn)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+// The annotations of the pod template read by the Dapr sidecar injector.
+const (
+	enabledAnnotation     = "dapr.io/enabled"
+	appIDAnnotation       = "dapr.io/app-id"
+	appPortAnnotation     = "dapr.io/app-port"
+	appProtocolAnnotation = "dapr.io/app-protocol"
+	logLevelAnnotation    = "dapr.io/log-level"
+	configAnnotation      = "dapr.io/config"
+)
+
+const defaultComponentVersion = "v1"
+
+// componentCategories are the categories of the Dapr components supported by the module, which
+// prefix the component types, e.g. state.redis.
+var componentCategories = []string{"state", "pubsub", "bindings"}
+
+var (
+	appProtocols = []string{"http", "grpc", "https", "grpcs", "h2c"}
+	logLevels    = []string{"debug", "info", "warn", "error"}
+)
+
+var (
+	// dnsLabelPattern matches the names of the Dapr app IDs and components.
+	dnsLabelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	// componentTypePattern matches the types of the Dapr components, e.g. state.redis.
+	componentTypePattern = regexp.MustCompile(`^([a-z]+)\.[a-z0-9.-]+$`)
+)
+
+var (
+	ErrInvalidAppID          = errors.New("appID must be a DNS label of at most 63 lowercase letters, digits and hyphens")
+	ErrInvalidAppPort        = errors.New("appPort must be between 1 and 65535")
+	ErrInvalidAppProtocol    = errors.New("appProtocol must be one of http, grpc, https, grpcs and h2c")
+	ErrInvalidLogLevel       = errors.New("logLevel must be one of debug, info, warn and error")
+	ErrInvalidComponentName  = errors.New("name of Dapr component must be a DNS label of at most 63 lowercase letters, digits and hyphens")
+	ErrInvalidComponentType  = errors.New("type of Dapr component must be a state, pubsub or bindings component, e.g. state.redis")
+	ErrEmptyComponentVersion = errors.New("version of Dapr component must not be empty")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Dapr{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Dapr{})
+}
+
+// Dapr describes the Dapr sidecar injected into the workload and the Dapr components the workload
+// uses, e.g. the state stores, the pub/sub brokers and the bindings. The metadata of the
+// components may reference the outputs of the other modules of the App, e.g. "${postgres.host}",
+// which are resolved to the Secrets generated by the modules.
+type Dapr struct {
+	// AppID is the ID of the App in Dapr, which defaults to the name of the App.
+	AppID string `json:"appID,omitempty" yaml:"appID,omitempty"`
+	// AppPort is the port the workload listens on for the calls from the sidecar.
+	AppPort int `json:"appPort,omitempty" yaml:"appPort,omitempty"`
+	// AppProtocol is the protocol of the AppPort, which defaults to http.
+	AppProtocol string `json:"appProtocol,omitempty" yaml:"appProtocol,omitempty"`
+	// LogLevel is the log level of the sidecar, which defaults to info.
+	LogLevel string `json:"logLevel,omitempty" yaml:"logLevel,omitempty"`
+	// Components are the Dapr components keyed by their names, which the workload references by
+	// the names, e.g. statestore.
+	Components map[string]Component `json:"components,omitempty" yaml:"components,omitempty"`
+
+	// The platform config of the dapr module.
+	platform PlatformConfig
+}
+
+// Component describes a Dapr component.
+type Component struct {
+	// Type is the type of the component, e.g. state.redis, pubsub.kafka or bindings.aws.s3.
+	Type string `json:"type" yaml:"type"`
+	// Version is the version of the component, which defaults to v1.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// Metadata is the metadata of the component, whose values may be the references to the
+	// outputs of the other modules of the App, e.g. "${postgres.password}".
+	Metadata map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the dapr module in workspace.
+type PlatformConfig struct {
+	// Config is the name of the Dapr Configuration applied to the sidecars, e.g. the tracing and
+	// the mTLS settings managed by the platform.
+	Config string `json:"config,omitempty" yaml:"config,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Dapr `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// Generate implements the generation logic of the dapr module.
+func (dapr *Dapr) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "dapr", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("dapr", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Dapr does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Dapr does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the dapr module.
+	if err := dapr.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("dapr", moduleutil.PhaseComplete, err)
+	}
+
+	appID := dapr.appID(request)
+	names := make([]string, 0, len(dapr.Components))
+	for name := range dapr.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var resources []kusionapiv1.Resource
+	for _, name := range names {
+		resource, err := generateComponent(request, appID, name, dapr.Components[name])
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *resource)
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   &kusionapiv1.Patcher{PodAnnotations: dapr.sidecarAnnotations(appID)},
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the dapr module.
+func (dapr *Dapr) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Dapr{}); err != nil {
+		return moduleutil.NewModuleError("dapr", moduleutil.PhaseValidate, fmt.Errorf("validate dapr dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("dapr", moduleutil.PhaseValidate, fmt.Errorf("validate dapr platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, dapr); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &dapr.platform); err != nil {
+			return err
+		}
+	}
+
+	for name, component := range dapr.Components {
+		if component.Version == "" {
+			component.Version = defaultComponentVersion
+			dapr.Components[name] = component
+		}
+	}
+
+	return dapr.Validate()
+}
+
+// Validate validates whether the configs of the dapr module are valid.
+func (dapr *Dapr) Validate() error {
+	if dapr.AppID != "" && !dnsLabelPattern.MatchString(dapr.AppID) {
+		return fmt.Errorf("%w, got %q", ErrInvalidAppID, dapr.AppID)
+	}
+	if dapr.AppPort < 0 || dapr.AppPort > 65535 {
+		return fmt.Errorf("%w, got %d", ErrInvalidAppPort, dapr.AppPort)
+	}
+	if dapr.AppProtocol != "" && !slices.Contains(appProtocols, dapr.AppProtocol) {
+		return fmt.Errorf("%w, got %q", ErrInvalidAppProtocol, dapr.AppProtocol)
+	}
+	if dapr.LogLevel != "" && !slices.Contains(logLevels, dapr.LogLevel) {
+		return fmt.Errorf("%w, got %q", ErrInvalidLogLevel, dapr.LogLevel)
+	}
+
+	for name, component := range dapr.Components {
+		if !dnsLabelPattern.MatchString(name) {
+			return fmt.Errorf("%w, got %q", ErrInvalidComponentName, name)
+		}
+		match := componentTypePattern.FindStringSubmatch(component.Type)
+		if match == nil || !slices.Contains(componentCategories, match[1]) {
+			return fmt.Errorf("%w, got %q of %s", ErrInvalidComponentType, component.Type, name)
+		}
+		if component.Version == "" {
+			return fmt.Errorf("%w, %s", ErrEmptyComponentVersion, name)
+		}
+	}
+
+	return nil
+}
+
+// appID returns the ID of the App in Dapr, which defaults to the name of the App.
+func (dapr *Dapr) appID(request *module.GeneratorRequest) string {
+	if dapr.AppID != "" {
+		return dapr.AppID
+	}
+	return moduleutil.AppName(request)
+}
+
+// sidecarAnnotations returns the annotations of the pod template injecting the Dapr sidecar.
+func (dapr *Dapr) sidecarAnnotations(appID string) map[string]string {
+	annotations := map[string]string{
+		enabledAnnotation: "true",
+		appIDAnnotation:   appID,
+	}
+	if dapr.AppPort != 0 {
+		annotations[appPortAnnotation] = strconv.Itoa(dapr.AppPort)
+	}
+	if dapr.AppProtocol != "" {
+		annotations[appProtocolAnnotation] = dapr.AppProtocol
+	}
+	if dapr.LogLevel != "" {
+		annotations[logLevelAnnotation] = dapr.LogLevel
+	}
+	if dapr.platform.Config != "" {
+		annotations[configAnnotation] = dapr.platform.Config
+	}
+	return annotations
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestDapr_Generate(t *testing.T) {
+	tests := []struct {
+		name                string
+		devConfig           kusionapiv1.Accessory
+		platformConfig      kusionapiv1.GenericConfig
+		expectedPhase       moduleutil.Phase
+		expectedErr         error
+		expectedComponents  []string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:      "sidecar only",
+			devConfig: kusionapiv1.Accessory{},
+			expectedAnnotations: map[string]string{
+				enabledAnnotation: "true",
+				appIDAnnotation:   "default-dev-foo",
+			},
+		},
+		{
+			name: "components",
+			devConfig: kusionapiv1.Accessory{
+				"appID":   "orders",
+				"appPort": 8080,
+				"components": map[string]interface{}{
+					"statestore": map[string]interface{}{
+						"type":     "state.postgresql",
+						"metadata": map[string]interface{}{"host": "${postgres.host}", "password": "${postgres.password}"},
+					},
+					"pubsub": map[string]interface{}{
+						"type":     "pubsub.kafka",
+						"metadata": map[string]interface{}{"brokers": "kafka:9092"},
+					},
+				},
+			},
+			platformConfig:     kusionapiv1.GenericConfig{"config": "tracing"},
+			expectedComponents: []string{"pubsub", "statestore"},
+			expectedAnnotations: map[string]string{
+				enabledAnnotation: "true",
+				appIDAnnotation:   "orders",
+				appPortAnnotation: "8080",
+				configAnnotation:  "tracing",
+			},
+		},
+		{
+			name: "secret store component",
+			devConfig: kusionapiv1.Accessory{
+				"components": map[string]interface{}{
+					"vault": map[string]interface{}{"type": "secretstores.hashicorp.vault"},
+				},
+			},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrInvalidComponentType,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Dapr{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedComponents)) {
+				return
+			}
+			for i, name := range tt.expectedComponents {
+				assert.Equal(t, componentKind, response.Resources[i].Attributes["kind"])
+				assert.Equal(t, name, response.Resources[i].Attributes["metadata"].(map[string]interface{})["name"])
+			}
+			assert.Equal(t, tt.expectedAnnotations, response.Patcher.PodAnnotations)
+		})
+	}
+}
+
+func TestDapr_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		dapr        Dapr
+		expectedErr error
+	}{
+		{
+			name: "valid",
+			dapr: Dapr{
+				AppID:       "orders",
+				AppPort:     50051,
+				AppProtocol: "grpc",
+				LogLevel:    "debug",
+				Components:  map[string]Component{"bucket": {Type: "bindings.aws.s3", Version: "v1"}},
+			},
+		},
+		{
+			name:        "invalid app id",
+			dapr:        Dapr{AppID: "Orders"},
+			expectedErr: ErrInvalidAppID,
+		},
+		{
+			name:        "invalid app port",
+			dapr:        Dapr{AppPort: 70000},
+			expectedErr: ErrInvalidAppPort,
+		},
+		{
+			name:        "invalid app protocol",
+			dapr:        Dapr{AppProtocol: "tcp"},
+			expectedErr: ErrInvalidAppProtocol,
+		},
+		{
+			name:        "invalid log level",
+			dapr:        Dapr{LogLevel: "trace"},
+			expectedErr: ErrInvalidLogLevel,
+		},
+		{
+			name:        "invalid component name",
+			dapr:        Dapr{Components: map[string]Component{"state_store": {Type: "state.redis", Version: "v1"}}},
+			expectedErr: ErrInvalidComponentName,
+		},
+		{
+			name:        "empty component version",
+			dapr:        Dapr{Components: map[string]Component{"statestore": {Type: "state.redis"}}},
+			expectedErr: ErrEmptyComponentVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dapr.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
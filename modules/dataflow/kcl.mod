@@ -0,0 +1,3 @@
+[package]
+name = "dataflow"
+version = "0.1.0"
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+// The engines of the data workloads, which run with the Kubernetes operators of the engines
+// installed in the cluster.
+const (
+	EngineSpark = "spark"
+	EngineFlink = "flink"
+)
+
+const (
+	defaultSparkVersion       = "3.5.1"
+	defaultFlinkVersion       = "v1_18"
+	defaultSparkAccount       = "spark"
+	defaultFlinkAccount       = "flink"
+	defaultCores              = 1
+	defaultMemory             = "1024m"
+	defaultReplicas           = 1
+	defaultCheckpointInterval = "60s"
+)
+
+// DataflowNamingRule is the rule of the names of the data workloads, which prefix the names of the
+// pods and the Services created by the operators, e.g. <name>-driver-svc of Spark.
+var DataflowNamingRule = moduleutil.NamingRule{MaxLength: 45}
+
+// memoryPattern matches the memory of the JVM processes, e.g. 512m or 2g.
+var memoryPattern = regexp.MustCompile(`^[1-9][0-9]*[kmgKMG]?$`)
+
+var (
+	ErrUnsupportedEngine             = errors.New("engine of dataflow must be spark or flink")
+	ErrEmptyImage                    = errors.New("empty image of dataflow")
+	ErrEmptyApplication              = errors.New("empty application of dataflow")
+	ErrInvalidCores                  = errors.New("cores of dataflow driver and executors must be greater than 0")
+	ErrInvalidMemory                 = errors.New("memory of dataflow driver and executors must be a number with an optional unit k, m or g, e.g. 2048m")
+	ErrInvalidReplicas               = errors.New("replicas of dataflow executors must be greater than 0")
+	ErrUnsupportedParallelism        = errors.New("parallelism of dataflow is only supported by flink")
+	ErrInvalidCheckpointPath         = errors.New("invalid path of dataflow checkpoint, must be s3://<bucket>[/<prefix>]")
+	ErrInvalidCheckpointInterval     = errors.New("interval of dataflow checkpoint must be a duration, e.g. 60s")
+	ErrUnsupportedCheckpointInterval = errors.New("interval of dataflow checkpoint is only supported by flink")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Dataflow{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Dataflow{})
+}
+
+// Dataflow describes the data workload of the App running on Spark or Flink, which is generated
+// as the SparkApplication of the Spark operator or the FlinkDeployment of the Flink Kubernetes
+// operator. The checkpoints are stored in the bucket of the S3 compatible object storage.
+type Dataflow struct {
+	// Engine is the engine running the workload, spark or flink.
+	Engine string `json:"engine" yaml:"engine"`
+	// Image is the image of the workload, which ships the engine and the application.
+	Image string `json:"image" yaml:"image"`
+	// Application is the URI of the jar or the Python file of the application, e.g.
+	// local:///opt/app/app.jar.
+	Application string `json:"application" yaml:"application"`
+	// MainClass is the entry class of the jar, which defaults to the one in the manifest of it.
+	MainClass string `json:"mainClass,omitempty" yaml:"mainClass,omitempty"`
+	// Args are the arguments of the application.
+	Args []string `json:"args,omitempty" yaml:"args,omitempty"`
+	// Driver is the resources of the Spark driver or the Flink JobManager.
+	Driver *Resources `json:"driver,omitempty" yaml:"driver,omitempty"`
+	// Executors is the resources of the Spark executors or the Flink TaskManagers.
+	Executors *Executors `json:"executors,omitempty" yaml:"executors,omitempty"`
+	// Parallelism is the parallelism of the Flink job, which defaults to the task slots of the
+	// TaskManagers.
+	Parallelism int `json:"parallelism,omitempty" yaml:"parallelism,omitempty"`
+	// Conf is the extra configuration of the engine, i.e. the sparkConf of Spark or the
+	// flinkConfiguration of Flink.
+	Conf map[string]string `json:"conf,omitempty" yaml:"conf,omitempty"`
+	// Checkpoint is the storage of the checkpoints of the streaming workload.
+	Checkpoint *Checkpoint `json:"checkpoint,omitempty" yaml:"checkpoint,omitempty"`
+
+	// The platform config of the dataflow module.
+	platform PlatformConfig
+}
+
+// Resources describes the resources of a process of the workload.
+type Resources struct {
+	// Cores is the number of the CPU cores, which defaults to 1.
+	Cores int `json:"cores,omitempty" yaml:"cores,omitempty"`
+	// Memory is the memory of the process, which defaults to 1024m.
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// Executors describes the resources and the number of the executors of the workload.
+type Executors struct {
+	Resources `json:",inline" yaml:",inline"`
+	// Replicas is the number of the executors, which defaults to 1.
+	Replicas int `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+}
+
+// Checkpoint describes the storage of the checkpoints in the bucket of the S3 compatible object
+// storage, where the checkpoints of the workload are stored under the path named after it.
+type Checkpoint struct {
+	// The bucket and the path prefix of the checkpoints, e.g. s3://checkpoints/dataflow.
+	Path string `json:"path" yaml:"path"`
+	// The endpoint of the S3 compatible object storage, e.g. https://oss-cn-hangzhou.aliyuncs.com,
+	// which defaults to AWS S3.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// The name of the Secret storing the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the
+	// bucket, and the credentials of the service account are used if empty.
+	CredentialsSecret string `json:"credentialsSecret,omitempty" yaml:"credentialsSecret,omitempty"`
+	// The interval of the checkpoints of Flink, which defaults to 60s.
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the dataflow module in workspace.
+type PlatformConfig struct {
+	// SparkVersion is the version of Spark in the images, which defaults to 3.5.1.
+	SparkVersion string `json:"sparkVersion,omitempty" yaml:"sparkVersion,omitempty"`
+	// FlinkVersion is the version of Flink in the images in the format of the Flink operator,
+	// which defaults to v1_18.
+	FlinkVersion string `json:"flinkVersion,omitempty" yaml:"flinkVersion,omitempty"`
+	// ServiceAccount is the service account of the driver managing the executors, which is
+	// granted by the operator and defaults to spark or flink.
+	ServiceAccount string `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Dataflow `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+}
+
+// Generate implements the generation logic of the dataflow module.
+func (dataflow *Dataflow) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "dataflow", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("dataflow", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Dataflow does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Dataflow does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the dataflow module.
+	if err := dataflow.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("dataflow", moduleutil.PhaseComplete, err)
+	}
+
+	var resource *kusionapiv1.Resource
+	switch dataflow.Engine {
+	case EngineSpark:
+		resource, err = dataflow.generateSparkApplication(request)
+	case EngineFlink:
+		resource, err = dataflow.generateFlinkDeployment(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &module.GeneratorResponse{
+		Resources: []kusionapiv1.Resource{*resource},
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the dataflow module.
+func (dataflow *Dataflow) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Dataflow{}); err != nil {
+		return moduleutil.NewModuleError("dataflow", moduleutil.PhaseValidate, fmt.Errorf("validate dataflow dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("dataflow", moduleutil.PhaseValidate, fmt.Errorf("validate dataflow platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, dataflow); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &dataflow.platform); err != nil {
+			return err
+		}
+	}
+
+	if dataflow.platform.SparkVersion == "" {
+		dataflow.platform.SparkVersion = defaultSparkVersion
+	}
+	if dataflow.platform.FlinkVersion == "" {
+		dataflow.platform.FlinkVersion = defaultFlinkVersion
+	}
+	if dataflow.platform.ServiceAccount == "" {
+		dataflow.platform.ServiceAccount = defaultSparkAccount
+		if dataflow.Engine == EngineFlink {
+			dataflow.platform.ServiceAccount = defaultFlinkAccount
+		}
+	}
+	if dataflow.Driver == nil {
+		dataflow.Driver = &Resources{}
+	}
+	if dataflow.Executors == nil {
+		dataflow.Executors = &Executors{}
+	}
+	completeResources(dataflow.Driver)
+	completeResources(&dataflow.Executors.Resources)
+	if dataflow.Executors.Replicas == 0 {
+		dataflow.Executors.Replicas = defaultReplicas
+	}
+	if checkpoint := dataflow.Checkpoint; checkpoint != nil {
+		checkpoint.Path = strings.TrimSuffix(checkpoint.Path, "/")
+		if checkpoint.Interval == "" && dataflow.Engine == EngineFlink {
+			checkpoint.Interval = defaultCheckpointInterval
+		}
+	}
+
+	return dataflow.Validate()
+}
+
+// Validate validates whether the configs of the dataflow module are valid.
+func (dataflow *Dataflow) Validate() error {
+	if dataflow.Engine != EngineSpark && dataflow.Engine != EngineFlink {
+		return fmt.Errorf("%w, got %q", ErrUnsupportedEngine, dataflow.Engine)
+	}
+	if dataflow.Image == "" {
+		return ErrEmptyImage
+	}
+	if dataflow.Application == "" {
+		return ErrEmptyApplication
+	}
+	if dataflow.Driver != nil {
+		if err := validateResources(*dataflow.Driver); err != nil {
+			return err
+		}
+	}
+	if dataflow.Executors != nil {
+		if err := validateResources(dataflow.Executors.Resources); err != nil {
+			return err
+		}
+		if dataflow.Executors.Replicas <= 0 {
+			return fmt.Errorf("%w, got %d", ErrInvalidReplicas, dataflow.Executors.Replicas)
+		}
+	}
+	if dataflow.Parallelism != 0 && dataflow.Engine != EngineFlink {
+		return ErrUnsupportedParallelism
+	}
+
+	checkpoint := dataflow.Checkpoint
+	if checkpoint == nil {
+		return nil
+	}
+	scheme, bucket, _ := strings.Cut(checkpoint.Path, "://")
+	if scheme != "s3" || bucket == "" || strings.HasPrefix(bucket, "/") {
+		return fmt.Errorf("%w, got %q", ErrInvalidCheckpointPath, checkpoint.Path)
+	}
+	if checkpoint.Interval != "" {
+		if dataflow.Engine != EngineFlink {
+			return ErrUnsupportedCheckpointInterval
+		}
+		if d, err := time.ParseDuration(checkpoint.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("%w, got %q", ErrInvalidCheckpointInterval, checkpoint.Interval)
+		}
+	}
+	return nil
+}
+
+// validateResources validates the resources of a process of the workload.
+func validateResources(resources Resources) error {
+	if resources.Cores <= 0 {
+		return fmt.Errorf("%w, got %d", ErrInvalidCores, resources.Cores)
+	}
+	if !memoryPattern.MatchString(resources.Memory) {
+		return fmt.Errorf("%w, got %q", ErrInvalidMemory, resources.Memory)
+	}
+	return nil
+}
+
+// completeResources fills the unset resources with the defaults.
+func completeResources(resources *Resources) {
+	if resources.Cores == 0 {
+		resources.Cores = defaultCores
+	}
+	if resources.Memory == "" {
+		resources.Memory = defaultMemory
+	}
+}
+
+// checkpointPath returns the path of the checkpoints of the workload, which is named after it.
+func (dataflow *Dataflow) checkpointPath(name string) string {
+	return dataflow.Checkpoint.Path + "/" + name
+}
+
+// credentialsEnvFrom returns the envFrom of the containers loading the credentials of the bucket
+// of the checkpoints, which are picked up by the S3 file systems of the engines.
+func (dataflow *Dataflow) credentialsEnvFrom() []interface{} {
+	if dataflow.Checkpoint == nil || dataflow.Checkpoint.CredentialsSecret == "" {
+		return nil
+	}
+	return []interface{}{
+		map[string]interface{}{"secretRef": map[string]interface{}{"name": dataflow.Checkpoint.CredentialsSecret}},
+	}
+}
+
+// wrapCustomResource wraps the custom resource of the operator into the Kusion resource.
+func wrapCustomResource(typeMeta metav1.TypeMeta, objectMeta metav1.ObjectMeta, spec map[string]interface{}) (*kusionapiv1.Resource, error) {
+	// Round trip the object through JSON, so that it only holds the JSON values accepted by the
+	// unstructured object.
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": typeMeta.APIVersion,
+		"kind":       typeMeta.Kind,
+		"metadata":   map[string]interface{}{"name": objectMeta.Name, "namespace": objectMeta.Namespace},
+		"spec":       spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err = json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, err
+	}
+
+	resourceID := module.KubernetesResourceID(typeMeta, objectMeta)
+	return module.WrapK8sResourceToKusionResource(resourceID, obj)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestDataflow_Generate(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+		expectedErr    error
+		expectedKind   string
+	}{
+		{
+			name: "spark",
+			devConfig: kusionapiv1.Accessory{
+				"engine":      "spark",
+				"image":       "spark-etl:v1",
+				"application": "local:///opt/app/etl.py",
+				"args":        []interface{}{"--date", "2024-01-01"},
+			},
+			expectedKind: sparkKind,
+		},
+		{
+			name: "flink",
+			devConfig: kusionapiv1.Accessory{
+				"engine":      "flink",
+				"image":       "flink-stream:v1",
+				"application": "local:///opt/flink/usrlib/stream.jar",
+				"checkpoint":  map[string]interface{}{"path": "s3://checkpoints/dataflow"},
+			},
+			platformConfig: kusionapiv1.GenericConfig{"flinkVersion": "v1_19"},
+			expectedKind:   flinkKind,
+		},
+		{
+			name:          "empty engine",
+			devConfig:     kusionapiv1.Accessory{"image": "spark-etl:v1", "application": "local:///opt/app/etl.py"},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrUnsupportedEngine,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Dataflow{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if assert.Len(t, response.Resources, 1) {
+				assert.Equal(t, tt.expectedKind, response.Resources[0].Attributes["kind"])
+			}
+		})
+	}
+}
+
+func TestDataflow_Validate(t *testing.T) {
+	valid := func(modify func(*Dataflow)) Dataflow {
+		dataflow := Dataflow{
+			Engine:      EngineFlink,
+			Image:       "flink-stream:v1",
+			Application: "local:///opt/flink/usrlib/stream.jar",
+			Driver:      &Resources{Cores: 1, Memory: "2048m"},
+			Executors:   &Executors{Resources: Resources{Cores: 2, Memory: "4g"}, Replicas: 2},
+			Checkpoint:  &Checkpoint{Path: "s3://checkpoints/dataflow", Interval: "30s"},
+		}
+		if modify != nil {
+			modify(&dataflow)
+		}
+		return dataflow
+	}
+
+	tests := []struct {
+		name        string
+		dataflow    Dataflow
+		expectedErr error
+	}{
+		{
+			name:     "valid",
+			dataflow: valid(nil),
+		},
+		{
+			name:        "empty image",
+			dataflow:    valid(func(d *Dataflow) { d.Image = "" }),
+			expectedErr: ErrEmptyImage,
+		},
+		{
+			name:        "empty application",
+			dataflow:    valid(func(d *Dataflow) { d.Application = "" }),
+			expectedErr: ErrEmptyApplication,
+		},
+		{
+			name:        "invalid cores",
+			dataflow:    valid(func(d *Dataflow) { d.Driver.Cores = -1 }),
+			expectedErr: ErrInvalidCores,
+		},
+		{
+			name:        "invalid memory",
+			dataflow:    valid(func(d *Dataflow) { d.Executors.Memory = "4Gi" }),
+			expectedErr: ErrInvalidMemory,
+		},
+		{
+			name:        "invalid replicas",
+			dataflow:    valid(func(d *Dataflow) { d.Executors.Replicas = 0 }),
+			expectedErr: ErrInvalidReplicas,
+		},
+		{
+			name: "parallelism of spark",
+			dataflow: valid(func(d *Dataflow) {
+				d.Engine = EngineSpark
+				d.Checkpoint = nil
+				d.Parallelism = 4
+			}),
+			expectedErr: ErrUnsupportedParallelism,
+		},
+		{
+			name:        "invalid checkpoint path",
+			dataflow:    valid(func(d *Dataflow) { d.Checkpoint.Path = "hdfs://checkpoints" }),
+			expectedErr: ErrInvalidCheckpointPath,
+		},
+		{
+			name:        "invalid checkpoint interval",
+			dataflow:    valid(func(d *Dataflow) { d.Checkpoint.Interval = "1 minute" }),
+			expectedErr: ErrInvalidCheckpointInterval,
+		},
+		{
+			name:        "checkpoint interval of spark",
+			dataflow:    valid(func(d *Dataflow) { d.Engine = EngineSpark }),
+			expectedErr: ErrUnsupportedCheckpointInterval,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dataflow.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	flinkAPIVersion = "flink.apache.org/v1beta1"
+	flinkKind       = "FlinkDeployment"
+
+	// flinkMainContainer is the name of the main container in the pod template of Flink, which
+	// the operator merges the containers of the JobManager and the TaskManagers into.
+	flinkMainContainer = "flink-main-container"
+)
+
+// generateFlinkDeployment generates the FlinkDeployment run by the Flink Kubernetes operator in
+// the application mode. The job is upgraded with the savepoints stored along with the
+// checkpoints if the checkpoint storage is configured, or stateless otherwise.
+func (dataflow *Dataflow) generateFlinkDeployment(request *module.GeneratorRequest) (*kusionapiv1.Resource, error) {
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.ResourceName(request, "", DataflowNamingRule),
+		Namespace: request.Project,
+	}
+
+	flinkConfiguration := make(map[string]string, len(dataflow.Conf)+5)
+	upgradeMode := "stateless"
+	if checkpoint := dataflow.Checkpoint; checkpoint != nil {
+		path := dataflow.checkpointPath(objectMeta.Name)
+		flinkConfiguration["state.checkpoints.dir"] = path + "/checkpoints"
+		flinkConfiguration["state.savepoints.dir"] = path + "/savepoints"
+		flinkConfiguration["execution.checkpointing.interval"] = checkpoint.Interval
+		if checkpoint.Endpoint != "" {
+			flinkConfiguration["s3.endpoint"] = checkpoint.Endpoint
+			flinkConfiguration["s3.path.style.access"] = "true"
+		}
+		upgradeMode = "savepoint"
+	}
+	// The conf declared by the application takes precedence.
+	for k, v := range dataflow.Conf {
+		flinkConfiguration[k] = v
+	}
+
+	job := map[string]interface{}{
+		"jarURI":      dataflow.Application,
+		"upgradeMode": upgradeMode,
+	}
+	if dataflow.MainClass != "" {
+		job["entryClass"] = dataflow.MainClass
+	}
+	if len(dataflow.Args) != 0 {
+		job["args"] = dataflow.Args
+	}
+	if dataflow.Parallelism != 0 {
+		job["parallelism"] = dataflow.Parallelism
+	}
+
+	spec := map[string]interface{}{
+		"image":          dataflow.Image,
+		"flinkVersion":   dataflow.platform.FlinkVersion,
+		"serviceAccount": dataflow.platform.ServiceAccount,
+		"jobManager": map[string]interface{}{
+			"resource": map[string]interface{}{"cpu": dataflow.Driver.Cores, "memory": dataflow.Driver.Memory},
+		},
+		"taskManager": map[string]interface{}{
+			"replicas": dataflow.Executors.Replicas,
+			"resource": map[string]interface{}{"cpu": dataflow.Executors.Cores, "memory": dataflow.Executors.Memory},
+		},
+		"job": job,
+	}
+	if len(flinkConfiguration) != 0 {
+		spec["flinkConfiguration"] = flinkConfiguration
+	}
+	if envFrom := dataflow.credentialsEnvFrom(); envFrom != nil {
+		spec["podTemplate"] = map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": flinkMainContainer, "envFrom": envFrom},
+				},
+			},
+		}
+	}
+
+	return wrapCustomResource(metav1.TypeMeta{APIVersion: flinkAPIVersion, Kind: flinkKind}, objectMeta, spec)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"testutil"
+)
+
+func TestDataflow_GenerateFlinkDeployment(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	dataflow := &Dataflow{
+		Engine:      EngineFlink,
+		Image:       "flink-stream:v1",
+		Application: "local:///opt/flink/usrlib/stream.jar",
+		Args:        []string{"--topic", "orders"},
+		Driver:      &Resources{Cores: 1, Memory: "2048m"},
+		Executors:   &Executors{Resources: Resources{Cores: 2, Memory: "4096m"}, Replicas: 2},
+		Parallelism: 4,
+		Checkpoint: &Checkpoint{
+			Path:              "s3://checkpoints/dataflow",
+			CredentialsSecret: "checkpoint-credentials",
+			Interval:          "30s",
+		},
+		platform: PlatformConfig{FlinkVersion: defaultFlinkVersion, ServiceAccount: defaultFlinkAccount},
+	}
+
+	resource, err := dataflow.generateFlinkDeployment(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "flink.apache.org/v1beta1:FlinkDeployment:default:default-dev-foo", resource.ID)
+
+	spec := resource.Attributes["spec"].(map[string]interface{})
+	assert.Equal(t, defaultFlinkVersion, spec["flinkVersion"])
+	assert.Equal(t, map[string]interface{}{
+		"state.checkpoints.dir":            "s3://checkpoints/dataflow/default-dev-foo/checkpoints",
+		"state.savepoints.dir":             "s3://checkpoints/dataflow/default-dev-foo/savepoints",
+		"execution.checkpointing.interval": "30s",
+	}, spec["flinkConfiguration"])
+	assert.Equal(t, map[string]interface{}{
+		"jarURI":      "local:///opt/flink/usrlib/stream.jar",
+		"args":        []interface{}{"--topic", "orders"},
+		"parallelism": float64(4),
+		"upgradeMode": "savepoint",
+	}, spec["job"])
+	assert.Equal(t, map[string]interface{}{
+		"replicas": float64(2),
+		"resource": map[string]interface{}{"cpu": float64(2), "memory": "4096m"},
+	}, spec["taskManager"])
+	assert.Equal(t, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{
+					"name":    flinkMainContainer,
+					"envFrom": []interface{}{map[string]interface{}{"secretRef": map[string]interface{}{"name": "checkpoint-credentials"}}},
+				},
+			},
+		},
+	}, spec["podTemplate"])
+}
+
+func TestDataflow_GenerateFlinkDeployment_Stateless(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	dataflow := &Dataflow{
+		Engine:      EngineFlink,
+		Image:       "flink-batch:v1",
+		Application: "local:///opt/flink/usrlib/batch.jar",
+		Driver:      &Resources{Cores: 1, Memory: "1024m"},
+		Executors:   &Executors{Resources: Resources{Cores: 1, Memory: "1024m"}, Replicas: 1},
+		platform:    PlatformConfig{FlinkVersion: defaultFlinkVersion, ServiceAccount: defaultFlinkAccount},
+	}
+
+	resource, err := dataflow.generateFlinkDeployment(request)
+	assert.NoError(t, err)
+	spec := resource.Attributes["spec"].(map[string]interface{})
+	assert.Equal(t, "stateless", spec["job"].(map[string]interface{})["upgradeMode"])
+	assert.NotContains(t, spec, "flinkConfiguration")
+	assert.NotContains(t, spec, "podTemplate")
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	sparkAPIVersion = "sparkoperator.k8s.io/v1beta2"
+	sparkKind       = "SparkApplication"
+)
+
+// generateSparkApplication generates the SparkApplication run by the Spark operator in the
+// cluster mode, which is restarted on failure. The checkpoints of the structured streaming
+// queries are stored under the checkpoint path with the s3a file system of Hadoop.
+func (dataflow *Dataflow) generateSparkApplication(request *module.GeneratorRequest) (*kusionapiv1.Resource, error) {
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.ResourceName(request, "", DataflowNamingRule),
+		Namespace: request.Project,
+	}
+
+	// The jars of Java and Scala are submitted the same way, and only Python differs.
+	appType := "Scala"
+	if strings.HasSuffix(dataflow.Application, ".py") {
+		appType = "Python"
+	}
+
+	driver := map[string]interface{}{
+		"cores":          dataflow.Driver.Cores,
+		"memory":         dataflow.Driver.Memory,
+		"serviceAccount": dataflow.platform.ServiceAccount,
+	}
+	executor := map[string]interface{}{
+		"cores":     dataflow.Executors.Cores,
+		"memory":    dataflow.Executors.Memory,
+		"instances": dataflow.Executors.Replicas,
+	}
+	if envFrom := dataflow.credentialsEnvFrom(); envFrom != nil {
+		driver["envFrom"] = envFrom
+		executor["envFrom"] = envFrom
+	}
+
+	sparkConf := make(map[string]string, len(dataflow.Conf)+2)
+	if checkpoint := dataflow.Checkpoint; checkpoint != nil {
+		sparkConf["spark.sql.streaming.checkpointLocation"] = "s3a://" +
+			strings.TrimPrefix(dataflow.checkpointPath(objectMeta.Name), "s3://")
+		if checkpoint.Endpoint != "" {
+			sparkConf["spark.hadoop.fs.s3a.endpoint"] = checkpoint.Endpoint
+		}
+	}
+	// The conf declared by the application takes precedence.
+	for k, v := range dataflow.Conf {
+		sparkConf[k] = v
+	}
+
+	spec := map[string]interface{}{
+		"type":                appType,
+		"mode":                "cluster",
+		"image":               dataflow.Image,
+		"sparkVersion":        dataflow.platform.SparkVersion,
+		"mainApplicationFile": dataflow.Application,
+		"restartPolicy":       map[string]interface{}{"type": "OnFailure"},
+		"driver":              driver,
+		"executor":            executor,
+	}
+	if dataflow.MainClass != "" {
+		spec["mainClass"] = dataflow.MainClass
+	}
+	if len(dataflow.Args) != 0 {
+		spec["arguments"] = dataflow.Args
+	}
+	if len(sparkConf) != 0 {
+		spec["sparkConf"] = sparkConf
+	}
+
+	return wrapCustomResource(metav1.TypeMeta{APIVersion: sparkAPIVersion, Kind: sparkKind}, objectMeta, spec)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"testutil"
+)
+
+func TestDataflow_GenerateSparkApplication(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	dataflow := &Dataflow{
+		Engine:      EngineSpark,
+		Image:       "spark-stream:v1",
+		Application: "local:///opt/app/stream.jar",
+		MainClass:   "com.example.Stream",
+		Args:        []string{"--topic", "orders"},
+		Driver:      &Resources{Cores: 1, Memory: "1024m"},
+		Executors:   &Executors{Resources: Resources{Cores: 2, Memory: "2g"}, Replicas: 3},
+		Conf:        map[string]string{"spark.sql.shuffle.partitions": "16"},
+		Checkpoint: &Checkpoint{
+			Path:              "s3://checkpoints/dataflow",
+			Endpoint:          "https://minio.example.com",
+			CredentialsSecret: "checkpoint-credentials",
+		},
+		platform: PlatformConfig{SparkVersion: defaultSparkVersion, ServiceAccount: defaultSparkAccount},
+	}
+
+	resource, err := dataflow.generateSparkApplication(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "sparkoperator.k8s.io/v1beta2:SparkApplication:default:default-dev-foo", resource.ID)
+
+	spec := resource.Attributes["spec"].(map[string]interface{})
+	assert.Equal(t, "Scala", spec["type"])
+	assert.Equal(t, "com.example.Stream", spec["mainClass"])
+	assert.Equal(t, []interface{}{"--topic", "orders"}, spec["arguments"])
+	assert.Equal(t, map[string]interface{}{
+		"spark.sql.streaming.checkpointLocation": "s3a://checkpoints/dataflow/default-dev-foo",
+		"spark.hadoop.fs.s3a.endpoint":           "https://minio.example.com",
+		"spark.sql.shuffle.partitions":           "16",
+	}, spec["sparkConf"])
+
+	envFrom := []interface{}{
+		map[string]interface{}{"secretRef": map[string]interface{}{"name": "checkpoint-credentials"}},
+	}
+	driver := spec["driver"].(map[string]interface{})
+	assert.Equal(t, defaultSparkAccount, driver["serviceAccount"])
+	assert.Equal(t, envFrom, driver["envFrom"])
+	executor := spec["executor"].(map[string]interface{})
+	assert.Equal(t, float64(3), executor["instances"])
+	assert.Equal(t, "2g", executor["memory"])
+	assert.Equal(t, envFrom, executor["envFrom"])
+}
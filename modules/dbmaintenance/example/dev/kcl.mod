@@ -0,0 +1,11 @@
+[package]
+name = "example"
+
+[dependencies]
+kam = { git = "https://github.com/KusionStack/kam.git", tag = "0.2.0" }
+service = { oci = "oci://ghcr.io/kusionstack/service", tag = "0.1.0" }
+postgres = { oci = "oci://ghcr.io/kusionstack/postgres", tag = "0.2.0" }
+dbmaintenance = { oci = "oci://ghcr.io/kusionstack/dbmaintenance", tag = "0.1.0" }
+
+[profile]
+entries = ["main.k"]
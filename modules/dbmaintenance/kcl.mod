@@ -0,0 +1,3 @@
+[package]
+name = "dbmaintenance"
+version = "0.1.0"
@@ -0,0 +1,151 @@
+package main
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	// The env vars of the connection details of the database, which are referenced by the
+	// arguments of the maintenance commands as $(DB_HOST), etc.
+	hostEnv     = "DB_HOST"
+	portEnv     = "DB_PORT"
+	usernameEnv = "DB_USERNAME"
+
+	// backoffLimit is the retries of the failed maintenance Jobs.
+	backoffLimit = 2
+)
+
+// CronJobNamingRule is the rule of the names of the CronJobs, whose Jobs are suffixed with the
+// scheduled time.
+var CronJobNamingRule = moduleutil.NamingRule{MaxLength: 52}
+
+// generateCronJob generates the CronJob running the maintenance task, which depends on the
+// Secret of the database module storing the credentials of the database.
+func (dbmaintenance *DBMaintenance) generateCronJob(request *module.GeneratorRequest, task Task) (*kusionapiv1.Resource, error) {
+	secretID := databaseSecretID(request, task)
+	secretName := module.KusionPathDependency(secretID, "metadata.name")
+	secretEnv := func(env, key string) v1.EnvVar {
+		return v1.EnvVar{
+			Name: env,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	env := []v1.EnvVar{
+		secretEnv(hostEnv, "hostAddress"),
+		secretEnv(portEnv, "port"),
+		secretEnv(usernameEnv, "username"),
+	}
+
+	container := v1.Container{Name: task.Name}
+	switch task.Database {
+	case DatabasePostgres:
+		container.Image = dbmaintenance.platform.PostgresImage
+		container.Env = append(env, secretEnv("PGPASSWORD", "password"))
+		container.Command = vacuumdbCommand(task)
+	case DatabaseMySQL:
+		container.Image = dbmaintenance.platform.MySQLImage
+		container.Env = append(env, secretEnv("MYSQL_PWD", "password"))
+		container.Command = mysqlcheckCommand(task)
+	}
+
+	var timeZone *string
+	if dbmaintenance.platform.TimeZone != "" {
+		timeZone = &dbmaintenance.platform.TimeZone
+	}
+	backoff := int32(backoffLimit)
+	cronJob := &batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "CronJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.ResourceName(request, task.Name, CronJobNamingRule),
+			Namespace: request.Project,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule: task.Schedule,
+			TimeZone: timeZone,
+			// The maintenance of the same tables never runs concurrently.
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoff,
+					Template: v1.PodTemplateSpec{
+						Spec: v1.PodSpec{
+							Containers:    []v1.Container{container},
+							RestartPolicy: v1.RestartPolicyNever,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(cronJob.TypeMeta, cronJob.ObjectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, cronJob)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = []string{secretID}
+	return resource, nil
+}
+
+// databaseSecretID returns the ID of the Secret generated by the database module of the task,
+// which is named after the databaseName of the module suffixed with the module name, e.g.
+// "v1:Secret:proj:proj-dev-app-postgres-postgres".
+func databaseSecretID(request *module.GeneratorRequest, task Task) string {
+	databaseName := task.DatabaseName
+	if databaseName == "" {
+		databaseName = moduleutil.ResourceName(request, task.Database, moduleutil.KubernetesNamingRule)
+	}
+	return module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Secret"},
+		metav1.ObjectMeta{Namespace: request.Project, Name: databaseName + "-" + task.Database},
+	)
+}
+
+// vacuumdbCommand returns the vacuumdb command of the postgres task, which reads the password
+// from PGPASSWORD.
+func vacuumdbCommand(task Task) []string {
+	command := []string{"vacuumdb", "--host=$(" + hostEnv + ")", "--port=$(" + portEnv + ")", "--username=$(" + usernameEnv + ")"}
+	switch task.Operation {
+	case OperationVacuumAnalyze:
+		command = append(command, "--analyze")
+	case OperationAnalyze:
+		command = append(command, "--analyze-only")
+	}
+	if task.DBName == "" {
+		return append(command, "--all")
+	}
+	command = append(command, "--dbname="+task.DBName)
+	for _, table := range task.Tables {
+		command = append(command, "--table="+table)
+	}
+	return command
+}
+
+// mysqlcheckCommand returns the mysqlcheck command of the mysql task, which reads the password
+// from MYSQL_PWD.
+func mysqlcheckCommand(task Task) []string {
+	command := []string{"mysqlcheck", "--host=$(" + hostEnv + ")", "--port=$(" + portEnv + ")", "--user=$(" + usernameEnv + ")"}
+	switch task.Operation {
+	case OperationOptimize:
+		command = append(command, "--optimize")
+	case OperationAnalyze:
+		command = append(command, "--analyze")
+	}
+	if task.DBName == "" {
+		return append(command, "--all-databases")
+	}
+	return append(append(command, task.DBName), task.Tables...)
+}
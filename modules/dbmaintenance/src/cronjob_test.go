@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestDBMaintenance_GenerateCronJob(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	dbmaintenance := &DBMaintenance{platform: PlatformConfig{PostgresImage: defaultPostgresImage}}
+	task := Task{
+		Name:         "vacuum",
+		Database:     DatabasePostgres,
+		DatabaseName: "orders",
+		Operation:    OperationVacuum,
+		Schedule:     "0 3 * * 0",
+	}
+
+	resource, err := dbmaintenance.generateCronJob(request, task)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v1:Secret:default:orders-postgres"}, resource.DependsOn)
+
+	spec := resource.Attributes["spec"].(map[string]interface{})
+	assert.Equal(t, "0 3 * * 0", spec["schedule"])
+	assert.Equal(t, "Forbid", spec["concurrencyPolicy"])
+	jobSpec := spec["jobTemplate"].(map[string]interface{})["spec"].(map[string]interface{})
+	podSpec := jobSpec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	container := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, defaultPostgresImage, container["image"])
+	env := container["env"].([]interface{})
+	if assert.Len(t, env, 4) {
+		password := env[3].(map[string]interface{})
+		assert.Equal(t, "PGPASSWORD", password["name"])
+		assert.Equal(t, map[string]interface{}{
+			"name": module.KusionPathDependency("v1:Secret:default:orders-postgres", "metadata.name"),
+			"key":  "password",
+		}, password["valueFrom"].(map[string]interface{})["secretKeyRef"])
+	}
+}
+
+func TestDatabaseSecretID(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	assert.Equal(t, "v1:Secret:default:default-dev-foo-mysql-mysql", databaseSecretID(request, Task{Database: DatabaseMySQL}))
+	assert.Equal(t, "v1:Secret:default:orders-mysql", databaseSecretID(request, Task{Database: DatabaseMySQL, DatabaseName: "orders"}))
+}
+
+func TestMaintenanceCommands(t *testing.T) {
+	connection := []string{"--host=$(DB_HOST)", "--port=$(DB_PORT)"}
+
+	assert.Equal(t,
+		append(append([]string{"vacuumdb"}, connection...), "--username=$(DB_USERNAME)", "--analyze", "--all"),
+		vacuumdbCommand(Task{Operation: OperationVacuumAnalyze}))
+	assert.Equal(t,
+		append(append([]string{"vacuumdb"}, connection...), "--username=$(DB_USERNAME)", "--analyze-only", "--dbname=shop", "--table=orders"),
+		vacuumdbCommand(Task{Operation: OperationAnalyze, DBName: "shop", Tables: []string{"orders"}}))
+	assert.Equal(t,
+		append(append([]string{"mysqlcheck"}, connection...), "--user=$(DB_USERNAME)", "--optimize", "--all-databases"),
+		mysqlcheckCommand(Task{Operation: OperationOptimize}))
+	assert.Equal(t,
+		append(append([]string{"mysqlcheck"}, connection...), "--user=$(DB_USERNAME)", "--analyze", "shop", "orders", "items"),
+		mysqlcheckCommand(Task{Operation: OperationAnalyze, DBName: "shop", Tables: []string{"orders", "items"}}))
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+// The database modules whose Secrets the maintenance tasks connect to the databases with.
+const (
+	DatabasePostgres = "postgres"
+	DatabaseMySQL    = "mysql"
+)
+
+// The operations of the maintenance tasks, where vacuum and vacuumAnalyze are only supported by
+// postgres, and optimize by mysql.
+const (
+	OperationVacuum        = "vacuum"
+	OperationVacuumAnalyze = "vacuumAnalyze"
+	OperationAnalyze       = "analyze"
+	OperationOptimize      = "optimize"
+)
+
+const (
+	defaultPostgresImage = "postgres:15"
+	defaultMySQLImage    = "mysql:8.0"
+)
+
+// supportedOperations are the operations supported by each database module.
+var supportedOperations = map[string][]string{
+	DatabasePostgres: {OperationVacuum, OperationVacuumAnalyze, OperationAnalyze},
+	DatabaseMySQL:    {OperationOptimize, OperationAnalyze},
+}
+
+var (
+	// taskNamePattern matches the names of the maintenance tasks.
+	taskNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+	// schedulePattern matches the five fields of the cron expressions, or the predefined
+	// schedules, e.g. @daily.
+	schedulePattern = regexp.MustCompile(`^(@(yearly|annually|monthly|weekly|daily|midnight|hourly)|\S+( \S+){4})$`)
+)
+
+var (
+	ErrEmptyTasks           = errors.New("at least one maintenance task must be specified")
+	ErrInvalidTaskName      = errors.New("name of maintenance task must consist of lowercase letters, digits and hyphens")
+	ErrDuplicateTaskName    = errors.New("duplicate name of maintenance task")
+	ErrUnsupportedDatabase  = errors.New("database of maintenance task must be postgres or mysql")
+	ErrUnsupportedOperation = errors.New("unsupported operation of maintenance task")
+	ErrInvalidSchedule      = errors.New("schedule of maintenance task must be a cron expression of five fields, e.g. 0 3 * * 0")
+	ErrTablesWithoutDBName  = errors.New("tables of maintenance task must be specified along with dbname")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, DBMaintenance{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&DBMaintenance{})
+}
+
+// DBMaintenance describes the scheduled maintenance tasks of the databases provisioned by the
+// postgres and mysql modules of the App, e.g. VACUUM ANALYZE and OPTIMIZE TABLE, which run as the
+// CronJobs connecting to the databases with the credentials in the Secrets of the modules.
+type DBMaintenance struct {
+	// Tasks are the maintenance tasks of the databases.
+	Tasks []Task `json:"tasks,omitempty" yaml:"tasks,omitempty"`
+
+	// The platform config of the dbmaintenance module.
+	platform PlatformConfig
+}
+
+// Task describes a maintenance task run on schedule.
+type Task struct {
+	// Name of the task, which suffixes the name of the CronJob and defaults to
+	// <database>-<operation>, e.g. postgres-vacuumanalyze.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Database is the database module the task runs against, postgres or mysql.
+	Database string `json:"database" yaml:"database"`
+	// DatabaseName is the databaseName of the database module, which defaults to the default name
+	// of the database of the App.
+	DatabaseName string `json:"databaseName,omitempty" yaml:"databaseName,omitempty"`
+	// Operation is the maintenance operation, vacuum, vacuumAnalyze or analyze of postgres, and
+	// optimize or analyze of mysql.
+	Operation string `json:"operation" yaml:"operation"`
+	// Schedule is the cron expression of the task, e.g. 0 3 * * 0.
+	Schedule string `json:"schedule" yaml:"schedule"`
+	// DBName is the logical database the task runs in, which defaults to all the databases.
+	DBName string `json:"dbname,omitempty" yaml:"dbname,omitempty"`
+	// Tables are the tables of the logical database the task runs on, which defaults to all the
+	// tables.
+	Tables []string `json:"tables,omitempty" yaml:"tables,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the dbmaintenance module in workspace.
+type PlatformConfig struct {
+	// PostgresImage is the image providing vacuumdb, which defaults to postgres:15.
+	PostgresImage string `json:"postgresImage,omitempty" yaml:"postgresImage,omitempty"`
+	// MySQLImage is the image providing mysqlcheck, which defaults to mysql:8.0.
+	MySQLImage string `json:"mysqlImage,omitempty" yaml:"mysqlImage,omitempty"`
+	// TimeZone is the time zone of the schedules, e.g. Asia/Shanghai, which defaults to the time
+	// zone of the kube-controller-manager.
+	TimeZone string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *DBMaintenance `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+}
+
+// Generate implements the generation logic of the dbmaintenance module.
+func (dbmaintenance *DBMaintenance) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "dbmaintenance", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("dbmaintenance", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// DBMaintenance does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("DBMaintenance does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the dbmaintenance module.
+	if err := dbmaintenance.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("dbmaintenance", moduleutil.PhaseComplete, err)
+	}
+
+	var resources []kusionapiv1.Resource
+	for _, task := range dbmaintenance.Tasks {
+		resource, err := dbmaintenance.generateCronJob(request, task)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *resource)
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the dbmaintenance module.
+func (dbmaintenance *DBMaintenance) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, DBMaintenance{}); err != nil {
+		return moduleutil.NewModuleError("dbmaintenance", moduleutil.PhaseValidate, fmt.Errorf("validate dbmaintenance dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("dbmaintenance", moduleutil.PhaseValidate, fmt.Errorf("validate dbmaintenance platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, dbmaintenance); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &dbmaintenance.platform); err != nil {
+			return err
+		}
+	}
+
+	if dbmaintenance.platform.PostgresImage == "" {
+		dbmaintenance.platform.PostgresImage = defaultPostgresImage
+	}
+	if dbmaintenance.platform.MySQLImage == "" {
+		dbmaintenance.platform.MySQLImage = defaultMySQLImage
+	}
+	for i := range dbmaintenance.Tasks {
+		task := &dbmaintenance.Tasks[i]
+		if task.Name == "" {
+			task.Name = strings.ToLower(task.Database + "-" + task.Operation)
+		}
+	}
+
+	return dbmaintenance.Validate()
+}
+
+// Validate validates whether the configs of the dbmaintenance module are valid.
+func (dbmaintenance *DBMaintenance) Validate() error {
+	if len(dbmaintenance.Tasks) == 0 {
+		return ErrEmptyTasks
+	}
+
+	names := map[string]bool{}
+	for _, task := range dbmaintenance.Tasks {
+		if !taskNamePattern.MatchString(task.Name) {
+			return fmt.Errorf("%w, got %q", ErrInvalidTaskName, task.Name)
+		}
+		if names[task.Name] {
+			return fmt.Errorf("%w, got %q", ErrDuplicateTaskName, task.Name)
+		}
+		names[task.Name] = true
+
+		operations, ok := supportedOperations[task.Database]
+		if !ok {
+			return fmt.Errorf("%w, got %q", ErrUnsupportedDatabase, task.Database)
+		}
+		if !slices.Contains(operations, task.Operation) {
+			return fmt.Errorf("%w, %s of %s must be one of %s", ErrUnsupportedOperation,
+				task.Operation, task.Database, strings.Join(operations, ", "))
+		}
+		if !schedulePattern.MatchString(task.Schedule) {
+			return fmt.Errorf("%w, got %q", ErrInvalidSchedule, task.Schedule)
+		}
+		if len(task.Tables) > 0 && task.DBName == "" {
+			return fmt.Errorf("%w of task %s", ErrTablesWithoutDBName, task.Name)
+		}
+	}
+
+	return nil
+}
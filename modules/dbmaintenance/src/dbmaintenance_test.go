@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestDBMaintenance_Generate(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+		expectedErr    error
+		expectedNames  []string
+	}{
+		{
+			name: "postgres and mysql",
+			devConfig: kusionapiv1.Accessory{
+				"tasks": []interface{}{
+					map[string]interface{}{"database": "postgres", "operation": "vacuumAnalyze", "schedule": "0 3 * * 0"},
+					map[string]interface{}{"name": "orders", "database": "mysql", "operation": "optimize", "schedule": "@weekly", "dbname": "shop", "tables": []interface{}{"orders"}},
+				},
+			},
+			platformConfig: kusionapiv1.GenericConfig{"timeZone": "Asia/Shanghai"},
+			expectedNames:  []string{"default-dev-foo-postgres-vacuumanalyze", "default-dev-foo-orders"},
+		},
+		{
+			name:          "empty tasks",
+			devConfig:     kusionapiv1.Accessory{},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrEmptyTasks,
+		},
+		{
+			name: "vacuum of mysql",
+			devConfig: kusionapiv1.Accessory{
+				"tasks": []interface{}{
+					map[string]interface{}{"database": "mysql", "operation": "vacuum", "schedule": "0 3 * * 0"},
+				},
+			},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrUnsupportedOperation,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&DBMaintenance{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedNames)) {
+				return
+			}
+			for i, name := range tt.expectedNames {
+				res := response.Resources[i]
+				assert.Equal(t, "CronJob", res.Attributes["kind"])
+				assert.Equal(t, name, res.Attributes["metadata"].(map[string]interface{})["name"])
+				assert.Equal(t, "Asia/Shanghai", res.Attributes["spec"].(map[string]interface{})["timeZone"])
+			}
+		})
+	}
+}
+
+func TestDBMaintenance_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		tasks       []Task
+		expectedErr error
+	}{
+		{
+			name: "valid",
+			tasks: []Task{
+				{Name: "vacuum", Database: DatabasePostgres, Operation: OperationVacuum, Schedule: "0 3 * * *"},
+				{Name: "analyze", Database: DatabasePostgres, Operation: OperationAnalyze, Schedule: "@daily", DBName: "shop", Tables: []string{"orders"}},
+			},
+		},
+		{
+			name: "invalid name",
+			tasks: []Task{
+				{Name: "Vacuum", Database: DatabasePostgres, Operation: OperationVacuum, Schedule: "0 3 * * *"},
+			},
+			expectedErr: ErrInvalidTaskName,
+		},
+		{
+			name: "duplicate name",
+			tasks: []Task{
+				{Name: "vacuum", Database: DatabasePostgres, Operation: OperationVacuum, Schedule: "0 3 * * *"},
+				{Name: "vacuum", Database: DatabasePostgres, Operation: OperationAnalyze, Schedule: "0 4 * * *"},
+			},
+			expectedErr: ErrDuplicateTaskName,
+		},
+		{
+			name: "unsupported database",
+			tasks: []Task{
+				{Name: "vacuum", Database: "redis", Operation: OperationVacuum, Schedule: "0 3 * * *"},
+			},
+			expectedErr: ErrUnsupportedDatabase,
+		},
+		{
+			name: "invalid schedule",
+			tasks: []Task{
+				{Name: "vacuum", Database: DatabasePostgres, Operation: OperationVacuum, Schedule: "0 3 * *"},
+			},
+			expectedErr: ErrInvalidSchedule,
+		},
+		{
+			name: "tables without dbname",
+			tasks: []Task{
+				{Name: "optimize", Database: DatabaseMySQL, Operation: OperationOptimize, Schedule: "0 3 * * *", Tables: []string{"orders"}},
+			},
+			expectedErr: ErrTablesWithoutDBName,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&DBMaintenance{Tasks: tt.tasks}).Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+[package]
+name = "featureflag"
+version = "0.1.0"
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+const (
+	resourceSuffix = "featureflag"
+
+	defaultEnvironment   = "development"
+	defaultProject       = "default"
+	defaultImage         = "unleashorg/unleash-server:5.12"
+	defaultDatabaseImage = "postgres:15"
+	defaultStorageSize   = "10Gi"
+
+	// allProjects is the project granting the access to all the projects of Unleash.
+	allProjects = "*"
+
+	// tokenKey is the key of the client API token in the Secret of the App.
+	tokenKey = "token"
+
+	// The env vars injected into the workload, which are read by the Unleash SDKs.
+	urlEnv     = "UNLEASH_URL"
+	appNameEnv = "UNLEASH_APP_NAME"
+	tokenEnv   = "UNLEASH_API_TOKEN"
+)
+
+// namePattern matches the names of the Unleash environments and projects.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,99}$`)
+
+// localEnvironments are the environments created by the on-cluster Unleash server.
+var localEnvironments = []string{"development", "production"}
+
+var (
+	ErrInvalidEndpoint     = errors.New("endpoint must be an http or https URL of the Unleash server")
+	ErrInvalidEnvironment  = errors.New("environment must consist of at most 100 letters, digits, underscores, dots and hyphens")
+	ErrInvalidProject      = errors.New("project must be * or consist of at most 100 letters, digits, underscores, dots and hyphens")
+	ErrLocalEnvironment    = errors.New("environment of the on-cluster Unleash server must be development or production")
+	ErrLocalProject        = errors.New("project of the on-cluster Unleash server must be default or *")
+	ErrInvalidStorageSize  = errors.New("storageSize must be a valid quantity, e.g. 10Gi")
+	ErrManagedLocalOptions = errors.New("image, databaseImage and storageSize are only supported by the on-cluster Unleash server")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, FeatureFlag{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&FeatureFlag{})
+}
+
+// FeatureFlag describes the feature flags evaluated by the workload with the Unleash SDK, which
+// connects to the Unleash server deployed on-cluster or the managed one referenced by the
+// platform, with the client API token provisioned for the App.
+type FeatureFlag struct {
+	// Environment is the Unleash environment the flags are evaluated in, which defaults to
+	// development.
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// Project is the Unleash project of the flags, which defaults to default, and * grants the
+	// access to all the projects.
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+
+	// The platform config of the featureflag module.
+	platform PlatformConfig
+}
+
+// PlatformConfig describes the platform config of the featureflag module in workspace.
+type PlatformConfig struct {
+	// Endpoint is the URL of the managed Unleash server, e.g. https://unleash.example.com, whose
+	// admin token configures the Unleash Terraform provider. The Unleash server is deployed
+	// on-cluster for each App if not set.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// Image is the image of the on-cluster Unleash server.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// DatabaseImage is the image of the PostgreSQL database of the on-cluster Unleash server.
+	DatabaseImage string `json:"databaseImage,omitempty" yaml:"databaseImage,omitempty"`
+	// StorageSize is the size of the volume of the PostgreSQL database, e.g. 10Gi.
+	StorageSize string `json:"storageSize,omitempty" yaml:"storageSize,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *FeatureFlag `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+}
+
+// Generate implements the generation logic of the featureflag module.
+func (featureflag *FeatureFlag) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "featureflag", &response, &err)
+
+	// Attach the endpoint of the Unleash API as the connection info, and finalize the generated
+	// resources with the standard metadata, the policy and Pod Security Standards checks, and the
+	// preview summary if enabled in the workspace context.
+	var endpoint string
+	defer func() {
+		if err == nil {
+			name := moduleutil.AppName(request) + "-featureflag-connection-info"
+			if err = moduleutil.AttachConnectionInfo("featureflag", name, request, response, map[string]string{"endpoint": endpoint}); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("featureflag", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// FeatureFlag does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("FeatureFlag does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the featureflag module.
+	if err := featureflag.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("featureflag", moduleutil.PhaseComplete, err)
+	}
+
+	// Provision the client API token of the App by the managed Unleash server, or deploy the
+	// Unleash server on-cluster with the token initialized on its start.
+	var resources []kusionapiv1.Resource
+	var token string
+	if featureflag.managed() {
+		resources, endpoint, token, err = featureflag.generateManagedResources(request)
+	} else {
+		resources, endpoint, token, err = featureflag.generateLocalResources(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := moduleutil.WrapK8sResource(featureflag.generateSecret(request, token))
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, *secret)
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   featureflag.generatePatcher(request, endpoint),
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the featureflag module.
+func (featureflag *FeatureFlag) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, FeatureFlag{}); err != nil {
+		return moduleutil.NewModuleError("featureflag", moduleutil.PhaseValidate, fmt.Errorf("validate featureflag dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("featureflag", moduleutil.PhaseValidate, fmt.Errorf("validate featureflag platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, featureflag); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &featureflag.platform); err != nil {
+			return err
+		}
+	}
+
+	if featureflag.Environment == "" {
+		featureflag.Environment = defaultEnvironment
+	}
+	if featureflag.Project == "" {
+		featureflag.Project = defaultProject
+	}
+	featureflag.platform.Endpoint = strings.TrimSuffix(featureflag.platform.Endpoint, "/")
+	if !featureflag.managed() {
+		if featureflag.platform.Image == "" {
+			featureflag.platform.Image = defaultImage
+		}
+		if featureflag.platform.DatabaseImage == "" {
+			featureflag.platform.DatabaseImage = defaultDatabaseImage
+		}
+		if featureflag.platform.StorageSize == "" {
+			featureflag.platform.StorageSize = defaultStorageSize
+		}
+	}
+
+	return featureflag.Validate()
+}
+
+// Validate validates whether the configs of the featureflag module are valid.
+func (featureflag *FeatureFlag) Validate() error {
+	if !namePattern.MatchString(featureflag.Environment) {
+		return fmt.Errorf("%w, got %q", ErrInvalidEnvironment, featureflag.Environment)
+	}
+	if featureflag.Project != allProjects && !namePattern.MatchString(featureflag.Project) {
+		return fmt.Errorf("%w, got %q", ErrInvalidProject, featureflag.Project)
+	}
+
+	platform := featureflag.platform
+	if featureflag.managed() {
+		u, err := url.Parse(platform.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("%w, got %q", ErrInvalidEndpoint, platform.Endpoint)
+		}
+		if platform.Image != "" || platform.DatabaseImage != "" || platform.StorageSize != "" {
+			return ErrManagedLocalOptions
+		}
+		return nil
+	}
+
+	// The open source Unleash server only comes with the default project and the development and
+	// production environments.
+	if !slices.Contains(localEnvironments, featureflag.Environment) {
+		return fmt.Errorf("%w, got %q", ErrLocalEnvironment, featureflag.Environment)
+	}
+	if featureflag.Project != defaultProject && featureflag.Project != allProjects {
+		return fmt.Errorf("%w, got %q", ErrLocalProject, featureflag.Project)
+	}
+	if _, err := resource.ParseQuantity(platform.StorageSize); err != nil {
+		return fmt.Errorf("%w, got %q", ErrInvalidStorageSize, platform.StorageSize)
+	}
+
+	return nil
+}
+
+// managed returns whether the flags are served by the managed Unleash server.
+func (featureflag *FeatureFlag) managed() bool {
+	return featureflag.platform.Endpoint != ""
+}
+
+// generateSecret generates the Secret delivering the client API token to the workload.
+func (featureflag *FeatureFlag) generateSecret(request *module.GeneratorRequest, token string) *v1.Secret {
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.ResourceName(request, resourceSuffix, moduleutil.KubernetesNamingRule),
+			Namespace: request.Project,
+		},
+		Type:       v1.SecretTypeOpaque,
+		StringData: map[string]string{tokenKey: token},
+	}
+}
+
+// generatePatcher generates the patcher injecting the endpoint of the Unleash API, the name of the
+// App and the client API token into the containers of the workload as the env vars.
+func (featureflag *FeatureFlag) generatePatcher(request *module.GeneratorRequest, endpoint string) *kusionapiv1.Patcher {
+	return &kusionapiv1.Patcher{
+		Environments: []v1.EnvVar{
+			{Name: urlEnv, Value: endpoint},
+			{Name: appNameEnv, Value: request.App},
+			{
+				Name: tokenEnv,
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: moduleutil.ResourceName(request, resourceSuffix, moduleutil.KubernetesNamingRule)},
+						Key:                  tokenKey,
+					},
+				},
+			},
+		},
+	}
+}
+
+// localSecret returns the fixed secret of the name in the App of the request for the on-cluster
+// Unleash server, which stays the same across the generations without any state.
+func localSecret(request *module.GeneratorRequest, name string) string {
+	hash := md5.Sum([]byte(request.Project + request.Stack + request.App + name))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestFeatureFlag_Generate(t *testing.T) {
+	managedConfig := kusionapiv1.GenericConfig{"endpoint": "https://unleash.example.com/"}
+
+	tests := []struct {
+		name             string
+		devConfig        kusionapiv1.Accessory
+		platformConfig   kusionapiv1.GenericConfig
+		expectedPhase    moduleutil.Phase
+		expectedErr      error
+		expectedKinds    []string
+		expectedEndpoint string
+	}{
+		{
+			name:             "local",
+			devConfig:        kusionapiv1.Accessory{},
+			expectedKinds:    []string{"Secret", "PersistentVolumeClaim", "Deployment", "Service", "Secret"},
+			expectedEndpoint: "http://default-dev-foo-unleash.default.svc:4242/api",
+		},
+		{
+			name:             "managed",
+			devConfig:        kusionapiv1.Accessory{"environment": "staging", "project": "*"},
+			platformConfig:   managedConfig,
+			expectedKinds:    []string{unleashAPIToken, "Secret"},
+			expectedEndpoint: "https://unleash.example.com/api",
+		},
+		{
+			name:          "custom environment of local server",
+			devConfig:     kusionapiv1.Accessory{"environment": "staging"},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrLocalEnvironment,
+		},
+		{
+			name:           "local options of managed server",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: kusionapiv1.GenericConfig{"endpoint": "https://unleash.example.com", "storageSize": "1Gi"},
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrManagedLocalOptions,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&FeatureFlag{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedKinds)) {
+				return
+			}
+			for i, kind := range tt.expectedKinds {
+				if resourceType, ok := response.Resources[i].Extensions["resourceType"]; ok {
+					assert.Equal(t, kind, resourceType)
+					continue
+				}
+				assert.Equal(t, kind, response.Resources[i].Attributes["kind"])
+			}
+			envs := response.Patcher.Environments
+			if assert.Len(t, envs, 3) {
+				assert.Equal(t, urlEnv, envs[0].Name)
+				assert.Equal(t, tt.expectedEndpoint, envs[0].Value)
+				assert.Equal(t, "foo", envs[1].Value)
+				assert.Equal(t, "default-dev-foo-featureflag", envs[2].ValueFrom.SecretKeyRef.Name)
+			}
+		})
+	}
+}
+
+func TestFeatureFlag_Validate(t *testing.T) {
+	local := PlatformConfig{StorageSize: "10Gi"}
+	managed := PlatformConfig{Endpoint: "https://unleash.example.com"}
+
+	tests := []struct {
+		name        string
+		featureflag FeatureFlag
+		expectedErr error
+	}{
+		{
+			name:        "valid local",
+			featureflag: FeatureFlag{Environment: "production", Project: allProjects, platform: local},
+		},
+		{
+			name:        "valid managed",
+			featureflag: FeatureFlag{Environment: "staging", Project: "checkout", platform: managed},
+		},
+		{
+			name:        "invalid environment",
+			featureflag: FeatureFlag{Environment: "-staging", Project: defaultProject, platform: managed},
+			expectedErr: ErrInvalidEnvironment,
+		},
+		{
+			name:        "invalid project",
+			featureflag: FeatureFlag{Environment: "staging", Project: "check out", platform: managed},
+			expectedErr: ErrInvalidProject,
+		},
+		{
+			name:        "invalid endpoint",
+			featureflag: FeatureFlag{Environment: "staging", Project: defaultProject, platform: PlatformConfig{Endpoint: "unleash.example.com"}},
+			expectedErr: ErrInvalidEndpoint,
+		},
+		{
+			name:        "custom project of local server",
+			featureflag: FeatureFlag{Environment: "development", Project: "checkout", platform: local},
+			expectedErr: ErrLocalProject,
+		},
+		{
+			name:        "invalid storage size",
+			featureflag: FeatureFlag{Environment: "development", Project: defaultProject, platform: PlatformConfig{StorageSize: "10G1"}},
+			expectedErr: ErrInvalidStorageSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.featureflag.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
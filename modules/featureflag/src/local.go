@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	localSuffix = "unleash"
+
+	unleashPort  = 4242
+	databasePort = 5432
+	databaseName = "unleash"
+	databaseUser = "unleash"
+
+	// The keys of the Secret of the on-cluster Unleash server.
+	databasePasswordKey = "databasePassword"
+	initTokensKey       = "initClientAPITokens"
+)
+
+// generateLocalResources generates the Unleash server deployed on-cluster for the App along with
+// its PostgreSQL database, and returns them along with the endpoint of the Unleash API and the
+// client API token initialized on the start of the server.
+func (featureflag *FeatureFlag) generateLocalResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, string, string, error) {
+	name := moduleutil.ResourceName(request, localSuffix, moduleutil.KubernetesNamingRule)
+
+	// The client API token is of the format <project>:<environment>.<secret>.
+	token := fmt.Sprintf("%s:%s.%s", featureflag.Project, featureflag.Environment, localSecret(request, tokenKey))
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+		},
+		Type: v1.SecretTypeOpaque,
+		StringData: map[string]string{
+			databasePasswordKey: localSecret(request, databasePasswordKey),
+			initTokensKey:       token,
+		},
+	}
+
+	pvc := &v1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    localLabels(name),
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: resource.MustParse(featureflag.platform.StorageSize),
+				},
+			},
+		},
+	}
+
+	service := &v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    localLabels(name),
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: unleashPort, TargetPort: intstr.FromString("http")},
+			},
+			Selector: localLabels(name),
+		},
+	}
+
+	var resources []kusionapiv1.Resource
+	for _, obj := range []moduleutil.K8sObject{secret, pvc, featureflag.generateLocalDeployment(request, name), service} {
+		res, err := moduleutil.WrapK8sResource(obj)
+		if err != nil {
+			return nil, "", "", err
+		}
+		resources = append(resources, *res)
+	}
+
+	endpoint := fmt.Sprintf("http://%s.%s.svc:%d/api", name, request.Project, unleashPort)
+	return resources, endpoint, token, nil
+}
+
+// generateLocalDeployment generates the Deployment of the Unleash server, whose PostgreSQL
+// database runs in the same Pod with the data on the PersistentVolumeClaim.
+func (featureflag *FeatureFlag) generateLocalDeployment(request *module.GeneratorRequest, name string) *appsv1.Deployment {
+	secretEnv := func(env, key string) v1.EnvVar {
+		return v1.EnvVar{
+			Name: env,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: name},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	replicas := int32(1)
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    localLabels(name),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: localLabels(name)},
+			// The volume of the database is mounted by one Pod at a time.
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: localLabels(name)},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "unleash",
+							Image: featureflag.platform.Image,
+							Env: []v1.EnvVar{
+								{Name: "DATABASE_HOST", Value: "localhost"},
+								{Name: "DATABASE_PORT", Value: fmt.Sprint(databasePort)},
+								{Name: "DATABASE_NAME", Value: databaseName},
+								{Name: "DATABASE_USERNAME", Value: databaseUser},
+								secretEnv("DATABASE_PASSWORD", databasePasswordKey),
+								{Name: "DATABASE_SSL", Value: "false"},
+								secretEnv("INIT_CLIENT_API_TOKENS", initTokensKey),
+							},
+							Ports: []v1.ContainerPort{{Name: "http", ContainerPort: unleashPort}},
+							ReadinessProbe: &v1.Probe{
+								ProbeHandler: v1.ProbeHandler{
+									HTTPGet: &v1.HTTPGetAction{Path: "/health", Port: intstr.FromString("http")},
+								},
+							},
+						},
+						{
+							Name:  "postgres",
+							Image: featureflag.platform.DatabaseImage,
+							Env: []v1.EnvVar{
+								{Name: "POSTGRES_DB", Value: databaseName},
+								{Name: "POSTGRES_USER", Value: databaseUser},
+								secretEnv("POSTGRES_PASSWORD", databasePasswordKey),
+								// The data lives in the subdirectory since the root of the volume
+								// may hold lost+found.
+								{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
+							},
+							Ports:        []v1.ContainerPort{{Name: "postgres", ContainerPort: databasePort}},
+							VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/var/lib/postgresql/data"}},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: "data",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// localLabels returns the labels selecting the Pods of the on-cluster Unleash server.
+func localLabels(name string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     "unleash",
+		"app.kubernetes.io/instance": name,
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"testutil"
+)
+
+func TestFeatureFlag_GenerateLocalResources(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	featureflag := &FeatureFlag{
+		Environment: "production",
+		Project:     defaultProject,
+		platform: PlatformConfig{
+			Image:         defaultImage,
+			DatabaseImage: defaultDatabaseImage,
+			StorageSize:   defaultStorageSize,
+		},
+	}
+
+	resources, endpoint, token, err := featureflag.generateLocalResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 4) {
+		return
+	}
+	assert.Equal(t, "http://default-dev-foo-unleash.default.svc:4242/api", endpoint)
+	assert.Regexp(t, `^default:production\.[0-9a-f]{32}$`, token)
+
+	secret := resources[0].Attributes["stringData"].(map[string]interface{})
+	assert.Equal(t, token, secret[initTokensKey])
+	assert.Len(t, secret[databasePasswordKey], 32)
+
+	// The secrets stay the same across the generations.
+	_, _, again, err := featureflag.generateLocalResources(request)
+	assert.NoError(t, err)
+	assert.Equal(t, token, again)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const unleashAPIToken = "unleash_api_token"
+
+// defaultUnleashProviderCfg is the provider config of the Unleash Terraform provider, whose
+// api_url and auth_token of the managed Unleash server are configured in the workspace, or by
+// UNLEASH_URL and AUTH_TOKEN.
+var defaultUnleashProviderCfg = module.ProviderConfig{
+	Source:  "Unleash/unleash",
+	Version: "2.0.0",
+}
+
+// generateManagedResources generates the client API token of the App provisioned by the managed
+// Unleash server, and returns it along with the endpoint of the Unleash API and the token.
+func (featureflag *FeatureFlag) generateManagedResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, string, string, error) {
+	name := moduleutil.ResourceName(request, resourceSuffix, moduleutil.KubernetesNamingRule)
+	id, err := module.TerraformResourceID(defaultUnleashProviderCfg, unleashAPIToken, name)
+	if err != nil {
+		return nil, "", "", err
+	}
+	token, err := module.WrapTFResourceToKusionResource(defaultUnleashProviderCfg, unleashAPIToken, id, map[string]interface{}{
+		"token_name":  moduleutil.AppName(request),
+		"type":        "client",
+		"environment": featureflag.Environment,
+		"projects":    []string{featureflag.Project},
+	}, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return []kusionapiv1.Resource{*token}, featureflag.platform.Endpoint + "/api", module.KusionPathDependency(token.ID, "secret"), nil
+}
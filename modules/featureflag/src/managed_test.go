@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestFeatureFlag_GenerateManagedResources(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	featureflag := &FeatureFlag{
+		Environment: "production",
+		Project:     allProjects,
+		platform:    PlatformConfig{Endpoint: "https://unleash.example.com"},
+	}
+
+	resources, endpoint, token, err := featureflag.generateManagedResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 1) {
+		return
+	}
+	assert.Equal(t, "https://unleash.example.com/api", endpoint)
+	assert.Equal(t, module.KusionPathDependency(resources[0].ID, "secret"), token)
+	assert.Equal(t, map[string]interface{}{
+		"token_name":  "default-dev-foo",
+		"type":        "client",
+		"environment": "production",
+		"projects":    []string{allProjects},
+	}, resources[0].Attributes)
+}
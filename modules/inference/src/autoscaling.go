@@ -0,0 +1,237 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// autoscaler type
+var (
+	AutoscalerHPAType  = "hpa"
+	AutoscalerKEDAType = "keda"
+)
+
+var (
+	ErrUnsupportAutoscaler    = errors.New("autoscaling type must be hpa or keda")
+	ErrRangeReplicas          = errors.New("min_replicas must be greater than 0 and less than or equal to max_replicas")
+	ErrEmptyAutoscalingMetric = errors.New("gpu_utilization or queue_length must be set for autoscaling")
+	ErrRangeGPUUtilization    = errors.New("gpu_utilization must be greater than 0 and less than or equal to 100")
+	ErrRangeQueueLength       = errors.New("queue_length must be greater than 0 if exist")
+	ErrEmptyQueueMetric       = errors.New("queue_metric must be set for the queue_length autoscaling")
+	ErrEmptyPrometheusAddress = errors.New("prometheus_address must be set for the keda autoscaling")
+)
+
+var (
+	inferAutoscalerSuffix     = "-infer-autoscaler"
+	kedaAPIVersion            = "keda.sh/v1alpha1"
+	gpuUtilizationMetric      = "DCGM_FI_DEV_GPU_UTIL"
+	defaultAutoscalingMinSize = 1
+)
+
+// AutoscalingConfig describes the autoscaling of the serving instances on the GPU duty cycle
+// reported by the NVIDIA DCGM exporter and the length of the inference queue reported by the
+// serving backend.
+type AutoscalingConfig struct {
+	// The autoscaler, hpa with the metrics served by the Prometheus adapter or keda with the
+	// Prometheus queries.
+	Type           string `yaml:"type,omitempty" json:"type,omitempty"`
+	MinReplicas    int    `yaml:"min_replicas,omitempty" json:"min_replicas,omitempty"`
+	MaxReplicas    int    `yaml:"max_replicas,omitempty" json:"max_replicas,omitempty"`
+	GPUUtilization int    `yaml:"gpu_utilization,omitempty" json:"gpu_utilization,omitempty"`
+	QueueLength    int    `yaml:"queue_length,omitempty" json:"queue_length,omitempty"`
+	QueueMetric    string `yaml:"queue_metric,omitempty" json:"queue_metric,omitempty"`
+	// The address of the Prometheus server queried by keda, e.g. http://prometheus.monitoring:9090.
+	PrometheusAddress string `yaml:"prometheus_address,omitempty" json:"prometheus_address,omitempty"`
+}
+
+// validateAutoscalingConfig validates the autoscaling config.
+func (infer *Inference) validateAutoscalingConfig() error {
+	autoscaling := infer.Autoscaling
+	if autoscaling == nil {
+		return nil
+	}
+	if autoscaling.Type != AutoscalerHPAType && autoscaling.Type != AutoscalerKEDAType {
+		return ErrUnsupportAutoscaler
+	}
+	if autoscaling.MinReplicas <= 0 || autoscaling.MinReplicas > autoscaling.MaxReplicas {
+		return ErrRangeReplicas
+	}
+	if autoscaling.GPUUtilization == 0 && autoscaling.QueueLength == 0 {
+		return ErrEmptyAutoscalingMetric
+	}
+	if autoscaling.GPUUtilization < 0 || autoscaling.GPUUtilization > 100 {
+		return ErrRangeGPUUtilization
+	}
+	if autoscaling.QueueLength < 0 {
+		return ErrRangeQueueLength
+	}
+	if autoscaling.QueueLength > 0 && autoscaling.QueueMetric == "" {
+		return ErrEmptyQueueMetric
+	}
+	if autoscaling.Type == AutoscalerKEDAType && autoscaling.PrometheusAddress == "" {
+		return ErrEmptyPrometheusAddress
+	}
+	return nil
+}
+
+// completeAutoscalingConfig sets the defaults of the autoscaling config.
+func (infer *Inference) completeAutoscalingConfig() {
+	autoscaling := infer.Autoscaling
+	if autoscaling == nil {
+		return
+	}
+	if autoscaling.Type == "" {
+		autoscaling.Type = AutoscalerHPAType
+	}
+	if autoscaling.MinReplicas == 0 {
+		autoscaling.MinReplicas = defaultAutoscalingMinSize
+	}
+}
+
+// generateAutoscaler generates the autoscaler resource of the serving Deployment of the framework.
+func (infer *Inference) generateAutoscaler(request *module.GeneratorRequest, deploymentID string) (*kusionapiv1.Resource, error) {
+	var autoscaler *kusionapiv1.Resource
+	var err error
+	switch infer.Autoscaling.Type {
+	case AutoscalerHPAType:
+		autoscaler, err = infer.generateHPA(request)
+	case AutoscalerKEDAType:
+		autoscaler, err = infer.generateScaledObject(request)
+	default:
+		return nil, ErrUnsupportAutoscaler
+	}
+	if err != nil {
+		return nil, err
+	}
+	autoscaler.DependsOn = []string{deploymentID}
+
+	return autoscaler, nil
+}
+
+// generateHPA generates the HorizontalPodAutoscaler resource scaling on the per-pod metrics, which
+// are served by the Prometheus adapter as the custom metrics.
+func (infer *Inference) generateHPA(request *module.GeneratorRequest) (*kusionapiv1.Resource, error) {
+	autoscaling := infer.Autoscaling
+
+	var metrics []autoscalingv2.MetricSpec
+	if autoscaling.GPUUtilization > 0 {
+		metrics = append(metrics, podsMetric(gpuUtilizationMetric, autoscaling.GPUUtilization))
+	}
+	if autoscaling.QueueLength > 0 {
+		metrics = append(metrics, podsMetric(autoscaling.QueueMetric, autoscaling.QueueLength))
+	}
+
+	minReplicas := int32(autoscaling.MinReplicas)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infer.frameworkName() + inferAutoscalerSuffix,
+			Namespace: request.Project,
+			Labels:    infer.generateMatchLabels(),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       infer.frameworkName() + inferDeploymentSuffix,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: int32(autoscaling.MaxReplicas),
+			Metrics:     metrics,
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(hpa.TypeMeta, hpa.ObjectMeta)
+	return module.WrapK8sResourceToKusionResource(resourceID, hpa)
+}
+
+func podsMetric(name string, target int) autoscalingv2.MetricSpec {
+	averageValue := resource.MustParse(strconv.Itoa(target))
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{
+				Name: name,
+			},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: &averageValue,
+			},
+		},
+	}
+}
+
+// generateScaledObject generates the KEDA ScaledObject resource scaling on the Prometheus queries
+// of the metrics summed over the serving pods, where the replicas are the sum divided by the
+// per-pod target.
+func (infer *Inference) generateScaledObject(request *module.GeneratorRequest) (*kusionapiv1.Resource, error) {
+	autoscaling := infer.Autoscaling
+	deploymentName := infer.frameworkName() + inferDeploymentSuffix
+	selector := fmt.Sprintf(`{namespace="%s",pod=~"%s-.*"}`, request.Project, deploymentName)
+
+	var triggers []interface{}
+	if autoscaling.GPUUtilization > 0 {
+		triggers = append(triggers, prometheusTrigger(autoscaling.PrometheusAddress,
+			"sum("+gpuUtilizationMetric+selector+")", autoscaling.GPUUtilization))
+	}
+	if autoscaling.QueueLength > 0 {
+		triggers = append(triggers, prometheusTrigger(autoscaling.PrometheusAddress,
+			"sum("+autoscaling.QueueMetric+selector+")", autoscaling.QueueLength))
+	}
+
+	scaledObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": kedaAPIVersion,
+			"kind":       "ScaledObject",
+			"metadata": map[string]interface{}{
+				"name":      infer.frameworkName() + inferAutoscalerSuffix,
+				"namespace": request.Project,
+				"labels":    toInterfaceMap(infer.generateMatchLabels()),
+			},
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"name": deploymentName,
+				},
+				"minReplicaCount": int64(autoscaling.MinReplicas),
+				"maxReplicaCount": int64(autoscaling.MaxReplicas),
+				"triggers":        triggers,
+			},
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: scaledObject.GetAPIVersion(), Kind: scaledObject.GetKind()},
+		metav1.ObjectMeta{Name: scaledObject.GetName(), Namespace: scaledObject.GetNamespace()},
+	)
+	return module.WrapK8sResourceToKusionResource(resourceID, scaledObject)
+}
+
+func prometheusTrigger(serverAddress, query string, threshold int) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "prometheus",
+		"metadata": map[string]interface{}{
+			"serverAddress": serverAddress,
+			"query":         query,
+			"threshold":     strconv.Itoa(threshold),
+		},
+	}
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	apiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestInferenceModule_ValidateAutoscalingConfig(t *testing.T) {
+	testcases := []struct {
+		name        string
+		autoscaling *AutoscalingConfig
+		expectedErr error
+	}{
+		{
+			name: "no autoscaling",
+		},
+		{
+			name:        "hpa on gpu utilization",
+			autoscaling: &AutoscalingConfig{Type: "hpa", MinReplicas: 1, MaxReplicas: 4, GPUUtilization: 80},
+		},
+		{
+			name: "keda on queue length",
+			autoscaling: &AutoscalingConfig{
+				Type:              "keda",
+				MinReplicas:       1,
+				MaxReplicas:       4,
+				QueueLength:       10,
+				QueueMetric:       "ollama_queue_length",
+				PrometheusAddress: "http://prometheus.monitoring:9090",
+			},
+		},
+		{
+			name:        "unsupported autoscaler",
+			autoscaling: &AutoscalingConfig{Type: "vpa", MinReplicas: 1, MaxReplicas: 4, GPUUtilization: 80},
+			expectedErr: ErrUnsupportAutoscaler,
+		},
+		{
+			name:        "max replicas less than min replicas",
+			autoscaling: &AutoscalingConfig{Type: "hpa", MinReplicas: 2, MaxReplicas: 1, GPUUtilization: 80},
+			expectedErr: ErrRangeReplicas,
+		},
+		{
+			name:        "no metric",
+			autoscaling: &AutoscalingConfig{Type: "hpa", MinReplicas: 1, MaxReplicas: 4},
+			expectedErr: ErrEmptyAutoscalingMetric,
+		},
+		{
+			name:        "gpu utilization out of range",
+			autoscaling: &AutoscalingConfig{Type: "hpa", MinReplicas: 1, MaxReplicas: 4, GPUUtilization: 120},
+			expectedErr: ErrRangeGPUUtilization,
+		},
+		{
+			name:        "queue length without metric",
+			autoscaling: &AutoscalingConfig{Type: "hpa", MinReplicas: 1, MaxReplicas: 4, QueueLength: 10},
+			expectedErr: ErrEmptyQueueMetric,
+		},
+		{
+			name:        "keda without prometheus",
+			autoscaling: &AutoscalingConfig{Type: "keda", MinReplicas: 1, MaxReplicas: 4, GPUUtilization: 80},
+			expectedErr: ErrEmptyPrometheusAddress,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			infer := &Inference{Autoscaling: tc.autoscaling}
+			err := infer.validateAutoscalingConfig()
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInferenceModule_CompleteAutoscalingConfig(t *testing.T) {
+	infer := &Inference{}
+	err := infer.CompleteConfig(apiv1.Accessory{
+		"model":     "qwen",
+		"framework": "Ollama",
+	}, apiv1.GenericConfig{
+		"autoscaling": map[string]interface{}{
+			"max_replicas":    4,
+			"gpu_utilization": 80,
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &AutoscalingConfig{
+		Type:           AutoscalerHPAType,
+		MinReplicas:    1,
+		MaxReplicas:    4,
+		GPUUtilization: 80,
+	}, infer.Autoscaling)
+}
+
+func TestInferenceModule_GenerateAutoscaler(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+	}
+
+	t.Run("hpa", func(t *testing.T) {
+		infer := &Inference{
+			Model:     "qwen",
+			Framework: "Ollama",
+			Autoscaling: &AutoscalingConfig{
+				Type:           "hpa",
+				MinReplicas:    1,
+				MaxReplicas:    4,
+				GPUUtilization: 80,
+				QueueLength:    10,
+				QueueMetric:    "ollama_queue_length",
+			},
+		}
+
+		res, _, err := infer.GenerateOllamaResource(r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "autoscaling/v2:HorizontalPodAutoscaler:test-project:ollama-infer-autoscaler", res[1].ID)
+		assert.Equal(t, []string{res[0].ID}, res[1].DependsOn)
+
+		hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+		assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(res[1].Attributes, hpa))
+		assert.Equal(t, "ollama-infer-deployment", hpa.Spec.ScaleTargetRef.Name)
+		assert.Equal(t, int32(4), hpa.Spec.MaxReplicas)
+		if assert.Len(t, hpa.Spec.Metrics, 2) {
+			assert.Equal(t, "DCGM_FI_DEV_GPU_UTIL", hpa.Spec.Metrics[0].Pods.Metric.Name)
+			assert.Equal(t, "80", hpa.Spec.Metrics[0].Pods.Target.AverageValue.String())
+			assert.Equal(t, "ollama_queue_length", hpa.Spec.Metrics[1].Pods.Metric.Name)
+		}
+	})
+
+	t.Run("keda", func(t *testing.T) {
+		infer := &Inference{
+			Model:     "qwen",
+			Framework: "Ollama",
+			Autoscaling: &AutoscalingConfig{
+				Type:              "keda",
+				MinReplicas:       1,
+				MaxReplicas:       4,
+				GPUUtilization:    80,
+				PrometheusAddress: "http://prometheus.monitoring:9090",
+			},
+		}
+
+		res, _, err := infer.GenerateOllamaResource(r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "keda.sh/v1alpha1:ScaledObject:test-project:ollama-infer-autoscaler", res[1].ID)
+
+		spec := res[1].Attributes["spec"].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"name": "ollama-infer-deployment"}, spec["scaleTargetRef"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{
+				"type": "prometheus",
+				"metadata": map[string]interface{}{
+					"serverAddress": "http://prometheus.monitoring:9090",
+					"query":         `sum(DCGM_FI_DEV_GPU_UTIL{namespace="test-project",pod=~"ollama-infer-deployment-.*"})`,
+					"threshold":     "80",
+				},
+			},
+		}, spec["triggers"])
+	})
+}
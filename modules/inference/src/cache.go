@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// model cache type
+var (
+	CachePVCType      = "pvc"
+	CacheHostPathType = "hostPath"
+)
+
+var (
+	ErrUnsupportCacheType = errors.New("cache type must be pvc or hostPath")
+	ErrEmptyCacheHostPath = errors.New("host_path must be set for the hostPath cache")
+	ErrInvalidCacheSize   = errors.New("cache size must be a valid storage quantity, e.g. 50Gi")
+	ErrRangeStartupTime   = errors.New("startup_timeout must be greater than or equal to 0")
+)
+
+var (
+	inferCacheSuffix         = "-infer-cache"
+	defaultCacheSize         = "50Gi"
+	defaultStartupTimeout    = 1800
+	startupProbePeriod       = 10
+	startupProbeInitialDelay = 10
+)
+
+// CacheConfig describes the persistent cache of the model files, which saves the downloads of the
+// models across the restarts of the pods.
+type CacheConfig struct {
+	Type         string `yaml:"type,omitempty" json:"type,omitempty"`
+	Size         string `yaml:"size,omitempty" json:"size,omitempty"`
+	StorageClass string `yaml:"storage_class,omitempty" json:"storage_class,omitempty"`
+	HostPath     string `yaml:"host_path,omitempty" json:"host_path,omitempty"`
+}
+
+// validateCacheConfig validates the model cache config.
+func (infer *Inference) validateCacheConfig() error {
+	cache := infer.Cache
+	if cache == nil {
+		return nil
+	}
+	switch cache.Type {
+	case CachePVCType:
+		if cache.Size != "" {
+			if _, err := resource.ParseQuantity(cache.Size); err != nil {
+				return ErrInvalidCacheSize
+			}
+		}
+	case CacheHostPathType:
+		if cache.HostPath == "" {
+			return ErrEmptyCacheHostPath
+		}
+	default:
+		return ErrUnsupportCacheType
+	}
+	return nil
+}
+
+// generateCacheVolumeSource generates the volume source of the model files, which is an emptyDir
+// without the cache config.
+func (infer *Inference) generateCacheVolumeSource() v1.VolumeSource {
+	cache := infer.Cache
+	switch {
+	case cache != nil && cache.Type == CachePVCType:
+		return v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: infer.frameworkName() + inferCacheSuffix,
+			},
+		}
+	case cache != nil && cache.Type == CacheHostPathType:
+		hostPathType := v1.HostPathDirectoryOrCreate
+		return v1.VolumeSource{
+			HostPath: &v1.HostPathVolumeSource{
+				Path: cache.HostPath,
+				Type: &hostPathType,
+			},
+		}
+	default:
+		return v1.VolumeSource{
+			EmptyDir: &v1.EmptyDirVolumeSource{},
+		}
+	}
+}
+
+// generateCachePVC generates the Kubernetes PersistentVolumeClaim resource of the model cache.
+func (infer *Inference) generateCachePVC(request *module.GeneratorRequest) (*kusionapiv1.Resource, error) {
+	size := infer.Cache.Size
+	if size == "" {
+		size = defaultCacheSize
+	}
+
+	pvc := &v1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      infer.frameworkName() + inferCacheSuffix,
+			Namespace: request.Project,
+			Labels:    infer.generateMatchLabels(),
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{
+				v1.ReadWriteOnce,
+			},
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: resource.MustParse(size),
+				},
+			},
+		},
+	}
+	if infer.Cache.StorageClass != "" {
+		pvc.Spec.StorageClassName = &infer.Cache.StorageClass
+	}
+
+	resourceID := module.KubernetesResourceID(pvc.TypeMeta, pvc.ObjectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, pvc)
+	if err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// generateStartupProbe generates the startup probe waiting for the model to be created, which
+// tolerates the long downloads of the models up to the startup timeout.
+func (infer *Inference) generateStartupProbe(command []string) *v1.Probe {
+	timeout := infer.StartupTimeout
+	if timeout == 0 {
+		timeout = defaultStartupTimeout
+	}
+	failureThreshold := (timeout + startupProbePeriod - 1) / startupProbePeriod
+
+	return &v1.Probe{
+		ProbeHandler: v1.ProbeHandler{
+			Exec: &v1.ExecAction{
+				Command: command,
+			},
+		},
+		InitialDelaySeconds: int32(startupProbeInitialDelay),
+		PeriodSeconds:       int32(startupProbePeriod),
+		FailureThreshold:    int32(failureThreshold),
+	}
+}
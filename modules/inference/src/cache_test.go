@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestInferenceModule_ValidateCacheConfig(t *testing.T) {
+	testcases := []struct {
+		name        string
+		cache       *CacheConfig
+		expectedErr error
+	}{
+		{
+			name: "no cache",
+		},
+		{
+			name:  "pvc cache",
+			cache: &CacheConfig{Type: "pvc", Size: "100Gi", StorageClass: "fast"},
+		},
+		{
+			name:        "invalid pvc size",
+			cache:       &CacheConfig{Type: "pvc", Size: "100 gigabytes"},
+			expectedErr: ErrInvalidCacheSize,
+		},
+		{
+			name:  "hostPath cache",
+			cache: &CacheConfig{Type: "hostPath", HostPath: "/data/models"},
+		},
+		{
+			name:        "hostPath cache without path",
+			cache:       &CacheConfig{Type: "hostPath"},
+			expectedErr: ErrEmptyCacheHostPath,
+		},
+		{
+			name:        "unsupported cache type",
+			cache:       &CacheConfig{Type: "nfs"},
+			expectedErr: ErrUnsupportCacheType,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			infer := &Inference{Cache: tc.cache}
+			err := infer.validateCacheConfig()
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInferenceModule_GenerateOllamaResourceWithCache(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+	}
+
+	t.Run("pvc cache", func(t *testing.T) {
+		infer := &Inference{
+			Model:          "qwen",
+			Framework:      "Ollama",
+			StartupTimeout: 600,
+			Cache:          &CacheConfig{Type: "pvc", StorageClass: "fast"},
+		}
+
+		res, _, err := infer.GenerateOllamaResource(r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "v1:PersistentVolumeClaim:test-project:ollama-infer-cache", res[0].ID)
+
+		pvc := &v1.PersistentVolumeClaim{}
+		assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(res[0].Attributes, pvc))
+		assert.Equal(t, "fast", *pvc.Spec.StorageClassName)
+		assert.Equal(t, "50Gi", pvc.Spec.Resources.Requests.Storage().String())
+
+		deployment := &appsv1.Deployment{}
+		assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(res[1].Attributes, deployment))
+		assert.Equal(t, appsv1.RecreateDeploymentStrategyType, deployment.Spec.Strategy.Type)
+		podSpec := deployment.Spec.Template.Spec
+		assert.Equal(t, "ollama-infer-cache", podSpec.Volumes[0].PersistentVolumeClaim.ClaimName)
+		probe := podSpec.Containers[0].StartupProbe
+		assert.Equal(t, []string{"ollama", "show", "qwen"}, probe.Exec.Command)
+		assert.Equal(t, int32(60), probe.FailureThreshold)
+	})
+
+	t.Run("hostPath cache", func(t *testing.T) {
+		infer := &Inference{
+			Model:     "qwen",
+			Framework: "Ollama",
+			Cache:     &CacheConfig{Type: "hostPath", HostPath: "/data/models"},
+		}
+
+		res, _, err := infer.GenerateOllamaResource(r)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, res, 4)
+
+		deployment := &appsv1.Deployment{}
+		assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(res[0].Attributes, deployment))
+		assert.Empty(t, deployment.Spec.Strategy.Type)
+		podSpec := deployment.Spec.Template.Spec
+		assert.Equal(t, "/data/models", podSpec.Volumes[0].HostPath.Path)
+		assert.Equal(t, int32(defaultStartupTimeout/startupProbePeriod), podSpec.Containers[0].StartupProbe.FailureThreshold)
+	})
+}
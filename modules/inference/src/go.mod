@@ -9,6 +9,7 @@ require (
 	k8s.io/apimachinery v0.31.3
 	kusionstack.io/kusion-api-go v0.13.0
 	kusionstack.io/kusion-module-framework v0.2.3-beta.6
+	moduleutil v0.0.0
 )
 
 require (
@@ -48,3 +49,5 @@ require (
 	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.1 // indirect
 )
+
+replace moduleutil => ../../../moduleutil
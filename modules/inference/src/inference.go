@@ -2,10 +2,9 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"runtime/debug"
+	"os"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -14,6 +13,7 @@ import (
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
 )
 
 // error type
@@ -68,6 +68,13 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Inference{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&Inference{})
 }
 
@@ -82,6 +89,28 @@ type Inference struct {
 	Temperature float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
 	NumPredict  int     `yaml:"num_predict,omitempty" json:"num_predict,omitempty"`
 	NumCtx      int     `yaml:"num_ctx,omitempty" json:"num_ctx,omitempty"`
+	// The persistent cache of the model files, and the seconds to wait for the model to be
+	// downloaded and created before restarting the pod.
+	Cache          *CacheConfig `yaml:"cache,omitempty" json:"cache,omitempty"`
+	StartupTimeout int          `yaml:"startup_timeout,omitempty" json:"startup_timeout,omitempty"`
+	// The models served behind the inference endpoint with their own resources, where the model
+	// is the default one of them.
+	Models []ModelConfig `yaml:"models,omitempty" json:"models,omitempty"`
+	// The autoscaling of the serving instances on the GPU utilization and the inference queue.
+	Autoscaling *AutoscalingConfig `yaml:"autoscaling,omitempty" json:"autoscaling,omitempty"`
+
+	// The model served by the generated resources when serving multiple models.
+	serving *ModelConfig
+}
+
+// PlatformConfig describes the platform config of the inference module in workspace.
+type PlatformConfig struct {
+	Inference `json:",inline"`
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `yaml:"podSecurity,omitempty" json:"podSecurity,omitempty"`
 }
 
 func (infer *Inference) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
@@ -89,13 +118,16 @@ func (infer *Inference) Generate(ctx context.Context, request *module.GeneratorR
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
-	// Handle the panic and update the returned error.
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "inference", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate inference module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in inference module generator but recovered with error: [%v] and stack %v and request %v", r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			if err = moduleutil.Finalize("inference", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -108,13 +140,13 @@ func (infer *Inference) Generate(ctx context.Context, request *module.GeneratorR
 	// Get the complete inference module configs.
 	if err := infer.CompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
 		logger.Debug("failed to get complete inference module configs: %v", err)
-		return nil, err
+		return nil, moduleutil.NewModuleError("inference", moduleutil.PhaseComplete, err)
 	}
 
 	// Validate the completed inference module configs.
 	if err := infer.ValidateConfig(); err != nil {
 		logger.Debug("failed to validate the inference module configs: %v", err)
-		return nil, err
+		return nil, moduleutil.NewModuleError("inference", moduleutil.PhaseValidate, err)
 	}
 
 	var resources []kusionapiv1.Resource
@@ -122,7 +154,11 @@ func (infer *Inference) Generate(ctx context.Context, request *module.GeneratorR
 
 	switch strings.ToLower(infer.Framework) {
 	case OllamaType:
-		resources, patcher, err = infer.GenerateOllamaResource(request)
+		if len(infer.Models) > 0 {
+			resources, patcher, err = infer.GenerateOllamaModelsResource(request)
+		} else {
+			resources, patcher, err = infer.GenerateOllamaResource(request)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -144,6 +180,15 @@ func (infer *Inference) CompleteConfig(devConfig kusionapiv1.Accessory, platform
 	infer.Temperature = defaultTemperature
 	infer.NumPredict = defaultNumPredict
 	infer.NumCtx = defaultNumCtx
+	infer.StartupTimeout = defaultStartupTimeout
+
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Inference{}); err != nil {
+		return moduleutil.NewModuleError("inference", moduleutil.PhaseValidate, fmt.Errorf("validate inference dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("inference", moduleutil.PhaseValidate, fmt.Errorf("validate inference platform config failed, %w", err))
+	}
 
 	// Retrieve the config items the developers are concerned about.
 	if devConfig != nil {
@@ -167,6 +212,7 @@ func (infer *Inference) CompleteConfig(devConfig kusionapiv1.Accessory, platform
 			return err
 		}
 	}
+	infer.completeAutoscalingConfig()
 	return nil
 }
 
@@ -187,7 +233,16 @@ func (infer *Inference) ValidateConfig() error {
 	if infer.NumCtx <= 0 {
 		return ErrRangeNumCtx
 	}
-	return nil
+	if infer.StartupTimeout < 0 {
+		return ErrRangeStartupTime
+	}
+	if err := infer.validateModels(); err != nil {
+		return err
+	}
+	if err := infer.validateAutoscalingConfig(); err != nil {
+		return err
+	}
+	return infer.validateCacheConfig()
 }
 
 func (infer *Inference) GenerateEnv(svcName string) (*kusionapiv1.Patcher, error) {
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	apiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
 )
 
 func TestInferenceModule_Generator(t *testing.T) {
@@ -78,15 +79,16 @@ func TestInferenceModule_CompleteConfig(t *testing.T) {
 			},
 			platformConfig: nil,
 			expectedInference: &Inference{
-				Model:       "qwen",
-				Framework:   "Ollama",
-				System:      "",
-				Template:    "",
-				TopK:        40,
-				TopP:        0.9,
-				Temperature: 0.8,
-				NumPredict:  128,
-				NumCtx:      2048,
+				Model:          "qwen",
+				Framework:      "Ollama",
+				System:         "",
+				Template:       "",
+				TopK:           40,
+				TopP:           0.9,
+				Temperature:    0.8,
+				NumPredict:     128,
+				NumCtx:         2048,
+				StartupTimeout: 1800,
 			},
 		},
 		{
@@ -102,15 +104,16 @@ func TestInferenceModule_CompleteConfig(t *testing.T) {
 			},
 			platformConfig: nil,
 			expectedInference: &Inference{
-				Model:       "qwen",
-				Framework:   "Ollama",
-				System:      "",
-				Template:    "",
-				TopK:        50,
-				TopP:        0.5,
-				Temperature: 0.5,
-				NumPredict:  256,
-				NumCtx:      4096,
+				Model:          "qwen",
+				Framework:      "Ollama",
+				System:         "",
+				Template:       "",
+				TopK:           50,
+				TopP:           0.5,
+				Temperature:    0.5,
+				NumPredict:     256,
+				NumCtx:         4096,
+				StartupTimeout: 1800,
 			},
 		},
 	}
@@ -124,6 +127,20 @@ func TestInferenceModule_CompleteConfig(t *testing.T) {
 	}
 }
 
+func TestInferenceModule_CompleteConfigInvalid(t *testing.T) {
+	infer := &Inference{}
+	err := infer.CompleteConfig(apiv1.Accessory{
+		"model":     "qwen",
+		"framework": "Ollama",
+		"topK":      50,
+		"num_ctx":   "4096",
+	}, nil)
+
+	assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "topK: unknown field")
+	assert.ErrorContains(t, err, "num_ctx: expected integer, got string")
+}
+
 func TestInferenceModule_ValidateConfig(t *testing.T) {
 	t.Run("validate no error", func(t *testing.T) {
 		infer := &Inference{
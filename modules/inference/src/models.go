@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+var (
+	ErrEmptyModel            = errors.New("model or models must be set")
+	ErrEmptyModelName        = errors.New("name of the served model must be set")
+	ErrDuplicateModel        = errors.New("served models must have distinct names")
+	ErrDefaultModelNotServed = errors.New("model must be one of the served models")
+	ErrInvalidModelResources = errors.New("invalid resources of the served model")
+)
+
+// inferenceURLEnv is the environment variable of the URL of the default model, and the URL of each
+// served model is in the variable suffixed with the model name, e.g. INFERENCE_URL_LLAMA3.
+var inferenceURLEnv = "INFERENCE_URL"
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ModelConfig describes a model served behind the inference endpoint along with the resources of
+// its serving instances.
+type ModelConfig struct {
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// The resources of the serving container in the form of <resourceName>: [<minValue>-]<maxValue>,
+	// e.g. cpu: "1-2" and nvidia.com/gpu: "1".
+	Resources map[string]string `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
+
+// validateModels validates the served models, where the model is the default one of them.
+func (infer *Inference) validateModels() error {
+	if len(infer.Models) == 0 {
+		if infer.Model == "" {
+			return ErrEmptyModel
+		}
+		return nil
+	}
+
+	names := make([]string, 0, len(infer.Models))
+	for _, model := range infer.Models {
+		if model.Name == "" {
+			return ErrEmptyModelName
+		}
+		name := modelName(model.Name)
+		if slices.Contains(names, name) {
+			return fmt.Errorf("%w: %s", ErrDuplicateModel, model.Name)
+		}
+		names = append(names, name)
+		if _, err := handleResourceRequirements(model.Resources); err != nil {
+			return fmt.Errorf("%w %s: %v", ErrInvalidModelResources, model.Name, err)
+		}
+	}
+	if infer.Model != "" && !slices.ContainsFunc(infer.Models, func(model ModelConfig) bool {
+		return model.Name == infer.Model
+	}) {
+		return ErrDefaultModelNotServed
+	}
+	return nil
+}
+
+// GenerateOllamaModelsResource generates the resources serving each of the models with a separate
+// Ollama Deployment behind its own proxy, and routes the workload to the models by the environment
+// variables of the proxy URLs.
+func (infer *Inference) GenerateOllamaModelsResource(request *module.GeneratorRequest) ([]kusionapiv1.Resource, *kusionapiv1.Patcher, error) {
+	var resources []kusionapiv1.Resource
+
+	defaultModel := infer.Model
+	if defaultModel == "" {
+		defaultModel = infer.Models[0].Name
+	}
+
+	var envVars []v1.EnvVar
+	for _, model := range infer.Models {
+		served := *infer
+		served.Model = model.Name
+		served.serving = &model
+
+		modelResources, patcher, err := served.GenerateOllamaResource(request)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, modelResources...)
+
+		svcName := patcher.Environments[0].Value
+		if model.Name == defaultModel {
+			envVars = append(envVars, v1.EnvVar{Name: inferenceURLEnv, Value: svcName})
+		}
+		envVars = append(envVars, v1.EnvVar{
+			Name:  inferenceURLEnv + "_" + strings.ToUpper(strings.ReplaceAll(modelName(model.Name), "-", "_")),
+			Value: svcName,
+		})
+	}
+
+	return resources, &kusionapiv1.Patcher{Environments: envVars}, nil
+}
+
+// frameworkName returns the name prefix of the Kubernetes resources of the framework, suffixed with
+// the served model when serving multiple models.
+func (infer *Inference) frameworkName() string {
+	if infer.serving != nil {
+		return strings.ToLower(infer.Framework) + "-" + modelName(infer.serving.Name)
+	}
+	return strings.ToLower(infer.Framework)
+}
+
+// proxyName returns the name prefix of the Kubernetes resources of the proxy, suffixed with the
+// served model when serving multiple models.
+func (infer *Inference) proxyName() string {
+	if infer.serving != nil {
+		return strings.ToLower(ProxyName) + "-" + modelName(infer.serving.Name)
+	}
+	return strings.ToLower(ProxyName)
+}
+
+// modelName converts the model name into the DNS label used in the resource names, e.g.
+// llama3:8b to llama3-8b.
+func modelName(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// handleResourceRequirements parses the resources of the served model and returns the
+// ResourceRequirements.
+func handleResourceRequirements(resources map[string]string) (v1.ResourceRequirements, error) {
+	result := v1.ResourceRequirements{}
+	if resources == nil {
+		return result, nil
+	}
+	for key, value := range resources {
+		resourceName := v1.ResourceName(key)
+		requests, limits, err := populateResourceLists(resourceName, value)
+		if err != nil {
+			return result, err
+		}
+		if requests != nil && result.Requests == nil {
+			result.Requests = make(v1.ResourceList)
+		}
+		maps.Copy(result.Requests, requests)
+		if limits != nil && result.Limits == nil {
+			result.Limits = make(v1.ResourceList)
+		}
+		maps.Copy(result.Limits, limits)
+	}
+	return result, nil
+}
+
+// populateResourceLists takes strings of form <resourceName>=[<minValue>-]<maxValue> and
+// returns request&limit ResourceList.
+func populateResourceLists(name v1.ResourceName, spec string) (v1.ResourceList, v1.ResourceList, error) {
+	requests := v1.ResourceList{}
+	limits := v1.ResourceList{}
+
+	parts := strings.Split(spec, "-")
+	if len(parts) == 1 {
+		resourceQuantity, err := resource.ParseQuantity(parts[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		limits[name] = resourceQuantity
+	} else if len(parts) == 2 {
+		resourceQuantity, err := resource.ParseQuantity(parts[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		requests[name] = resourceQuantity
+		resourceQuantity, err = resource.ParseQuantity(parts[1])
+		if err != nil {
+			return nil, nil, err
+		}
+		limits[name] = resourceQuantity
+	}
+
+	return requests, limits, nil
+}
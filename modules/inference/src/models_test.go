@@ -0,0 +1,132 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestInferenceModule_ValidateModels(t *testing.T) {
+	testcases := []struct {
+		name        string
+		model       string
+		models      []ModelConfig
+		expectedErr error
+	}{
+		{
+			name:  "single model",
+			model: "llama3",
+		},
+		{
+			name:        "no model",
+			expectedErr: ErrEmptyModel,
+		},
+		{
+			name:  "multiple models",
+			model: "qwen",
+			models: []ModelConfig{
+				{Name: "llama3:8b", Resources: map[string]string{"cpu": "1-2", "nvidia.com/gpu": "1"}},
+				{Name: "qwen"},
+			},
+		},
+		{
+			name:        "model without name",
+			models:      []ModelConfig{{Name: "llama3"}, {}},
+			expectedErr: ErrEmptyModelName,
+		},
+		{
+			name:        "duplicate models",
+			models:      []ModelConfig{{Name: "llama3:8b"}, {Name: "llama3-8b"}},
+			expectedErr: ErrDuplicateModel,
+		},
+		{
+			name:        "default model not served",
+			model:       "mistral",
+			models:      []ModelConfig{{Name: "llama3"}, {Name: "qwen"}},
+			expectedErr: ErrDefaultModelNotServed,
+		},
+		{
+			name:        "invalid resources",
+			models:      []ModelConfig{{Name: "llama3", Resources: map[string]string{"memory": "8 gigabytes"}}},
+			expectedErr: ErrInvalidModelResources,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			infer := &Inference{Model: tc.model, Models: tc.models}
+			err := infer.validateModels()
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestInferenceModule_GenerateOllamaModelsResource(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+	}
+
+	infer := &Inference{
+		Model:     "qwen",
+		Framework: "Ollama",
+		Models: []ModelConfig{
+			{Name: "llama3:8b", Resources: map[string]string{"cpu": "1-2", "nvidia.com/gpu": "1"}},
+			{Name: "qwen"},
+		},
+	}
+
+	res, patcher, err := infer.GenerateOllamaModelsResource(r)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ids []string
+	for _, resource := range res {
+		ids = append(ids, resource.ID)
+	}
+	assert.Equal(t, []string{
+		"apps/v1:Deployment:test-project:ollama-llama3-8b-infer-deployment",
+		"v1:Service:test-project:ollama-llama3-8b-infer-service",
+		"apps/v1:Deployment:test-project:proxy-llama3-8b-infer-deployment",
+		"v1:Service:test-project:proxy-llama3-8b-infer-service",
+		"apps/v1:Deployment:test-project:ollama-qwen-infer-deployment",
+		"v1:Service:test-project:ollama-qwen-infer-service",
+		"apps/v1:Deployment:test-project:proxy-qwen-infer-deployment",
+		"v1:Service:test-project:proxy-qwen-infer-service",
+	}, ids)
+
+	assert.Equal(t, []v1.EnvVar{
+		{Name: "INFERENCE_URL_LLAMA3_8B", Value: "proxy-llama3-8b-infer-service"},
+		{Name: "INFERENCE_URL", Value: "proxy-qwen-infer-service"},
+		{Name: "INFERENCE_URL_QWEN", Value: "proxy-qwen-infer-service"},
+	}, patcher.Environments)
+
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(res[0].Attributes, deployment))
+	assert.Equal(t, map[string]string{"accessory": "ollama", "model": "llama3-8b"}, deployment.Spec.Selector.MatchLabels)
+	container := deployment.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, "1", container.Resources.Requests.Cpu().String())
+	assert.Equal(t, "2", container.Resources.Limits.Cpu().String())
+	assert.Contains(t, container.Command[2], "ollama create llama3:8b -f Modelfile")
+
+	proxy := &appsv1.Deployment{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(res[2].Attributes, proxy))
+	assert.Contains(t, proxy.Spec.Template.Spec.Containers[0].Env, v1.EnvVar{Name: "FRAMEWORK_URL", Value: "ollama-llama3-8b-infer-service"})
+}
+
+func TestInferenceModule_ModelName(t *testing.T) {
+	assert.Equal(t, "llama3", modelName("llama3"))
+	assert.Equal(t, "llama3-8b", modelName("llama3:8b"))
+	assert.Equal(t, "qwen2-5-7b", modelName("Qwen2.5:7B"))
+	assert.Equal(t, "library-mistral", modelName("/library/mistral"))
+}
@@ -16,6 +16,15 @@ import (
 func (infer *Inference) GenerateOllamaResource(request *module.GeneratorRequest) ([]kusionapiv1.Resource, *kusionapiv1.Patcher, error) {
 	var resources []kusionapiv1.Resource
 
+	// Build Kubernetes PersistentVolumeClaim for the model cache of Ollama framework.
+	if infer.Cache != nil && infer.Cache.Type == CachePVCType {
+		pvc, err := infer.generateCachePVC(request)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *pvc)
+	}
+
 	// Build Kubernetes Deployment for Ollama framework.
 	deployment, err := infer.generateOllamaDeployment(request)
 	if err != nil {
@@ -23,6 +32,15 @@ func (infer *Inference) GenerateOllamaResource(request *module.GeneratorRequest)
 	}
 	resources = append(resources, *deployment)
 
+	// Build the autoscaler of the Kubernetes Deployment for Ollama framework.
+	if infer.Autoscaling != nil {
+		autoscaler, err := infer.generateAutoscaler(request, deployment.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *autoscaler)
+	}
+
 	// Build Kubernetes Service for Ollama framework.
 	svc, svcName, err := infer.generateOllamaService(request)
 	if err != nil {
@@ -84,10 +102,8 @@ func (infer *Inference) generateOllamaPodSpec(_ *module.GeneratorRequest) (v1.Po
 
 	volumes := []v1.Volume{
 		{
-			Name: strings.ToLower(infer.Framework) + inferStorageSuffix,
-			VolumeSource: v1.VolumeSource{
-				EmptyDir: &v1.EmptyDirVolumeSource{},
-			},
+			Name:         strings.ToLower(infer.Framework) + inferStorageSuffix,
+			VolumeSource: infer.generateCacheVolumeSource(),
 		},
 	}
 
@@ -111,6 +127,14 @@ func (infer *Inference) generateOllamaPodSpec(_ *module.GeneratorRequest) (v1.Po
 		},
 	}
 
+	var resources v1.ResourceRequirements
+	if infer.serving != nil {
+		var err error
+		if resources, err = handleResourceRequirements(infer.serving.Resources); err != nil {
+			return v1.PodSpec{}, err
+		}
+	}
+
 	image := OllamaImage
 	podSpec := v1.PodSpec{
 		Containers: []v1.Container{
@@ -119,7 +143,10 @@ func (infer *Inference) generateOllamaPodSpec(_ *module.GeneratorRequest) (v1.Po
 				Image:        image,
 				Ports:        ports,
 				Command:      modelPullCmd,
+				Resources:    resources,
 				VolumeMounts: volumeMounts,
+				// The model is served once created from the Modelfile after downloaded.
+				StartupProbe: infer.generateStartupProbe([]string{"ollama", "show", infer.Model}),
 			},
 		},
 		Volumes: volumes,
@@ -132,7 +159,7 @@ func (infer *Inference) generateOllamaDeployment(request *module.GeneratorReques
 	// Prepare the Pod Spec for Ollama framework.
 	podSpec, err := infer.generateOllamaPodSpec(request)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
 	// Create the Kubernetes Deployment for Ollama framework.
@@ -142,7 +169,7 @@ func (infer *Inference) generateOllamaDeployment(request *module.GeneratorReques
 			APIVersion: appsv1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      strings.ToLower(infer.Framework) + inferDeploymentSuffix,
+			Name:      infer.frameworkName() + inferDeploymentSuffix,
 			Namespace: request.Project,
 		},
 		Spec: appsv1.DeploymentSpec{
@@ -158,6 +185,14 @@ func (infer *Inference) generateOllamaDeployment(request *module.GeneratorReques
 		},
 	}
 
+	// The ReadWriteOnce model cache can't be mounted by the old and new pods on different nodes
+	// during the rolling update.
+	if infer.Cache != nil && infer.Cache.Type == CachePVCType {
+		deployment.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RecreateDeploymentStrategyType,
+		}
+	}
+
 	resourceID := module.KubernetesResourceID(deployment.TypeMeta, deployment.ObjectMeta)
 	resource, err := module.WrapK8sResourceToKusionResource(resourceID, deployment)
 	if err != nil {
@@ -170,7 +205,7 @@ func (infer *Inference) generateOllamaDeployment(request *module.GeneratorReques
 // generateService generates the Kubernetes Service resource for Ollama framework.
 func (infer *Inference) generateOllamaService(request *module.GeneratorRequest) (*kusionapiv1.Resource, string, error) {
 	// Prepare the service port for Ollama framework.
-	svcName := strings.ToLower(infer.Framework) + inferServiceSuffix
+	svcName := infer.frameworkName() + inferServiceSuffix
 	svcPort := []v1.ServicePort{
 		{
 			Port: int32(CalledPort),
@@ -210,16 +245,24 @@ func (infer *Inference) generateOllamaService(request *module.GeneratorRequest)
 
 // generateMatchLabels generates the match labels for the Kubernetes resources of Ollama framework.
 func (infer *Inference) generateMatchLabels() map[string]string {
-	return map[string]string{
+	labels := map[string]string{
 		"accessory": strings.ToLower(infer.Framework),
 	}
+	if infer.serving != nil {
+		labels["model"] = modelName(infer.serving.Name)
+	}
+	return labels
 }
 
 // generateMatchLabels generates the match labels for the Kubernetes resources of proxy.
 func (infer *Inference) generateMatchLabelsForProxy() map[string]string {
-	return map[string]string{
+	labels := map[string]string{
 		"accessory": strings.ToLower(ProxyName),
 	}
+	if infer.serving != nil {
+		labels["model"] = modelName(infer.serving.Name)
+	}
+	return labels
 }
 
 // generatePodSpec generates the Kubernetes PodSpec for proxy.
@@ -265,7 +308,7 @@ func (infer *Inference) generateProxyPodSpec(_ *module.GeneratorRequest, svcName
 func (infer *Inference) generateProxyDeployment(request *module.GeneratorRequest, svcName string) (*kusionapiv1.Resource, error) {
 	podSpec, err := infer.generateProxyPodSpec(request, svcName)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
 
 	deployment := &appsv1.Deployment{
@@ -274,7 +317,7 @@ func (infer *Inference) generateProxyDeployment(request *module.GeneratorRequest
 			APIVersion: appsv1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      strings.ToLower(ProxyName) + inferDeploymentSuffix,
+			Name:      infer.proxyName() + inferDeploymentSuffix,
 			Namespace: request.Project,
 		},
 		Spec: appsv1.DeploymentSpec{
@@ -301,7 +344,7 @@ func (infer *Inference) generateProxyDeployment(request *module.GeneratorRequest
 
 // generateService generates the Kubernetes Service resource for proxy.
 func (infer *Inference) generateProxyService(request *module.GeneratorRequest) (*kusionapiv1.Resource, string, error) {
-	svcName := strings.ToLower(ProxyName) + inferServiceSuffix
+	svcName := infer.proxyName() + inferServiceSuffix
 	svcPort := []v1.ServicePort{
 		{
 			Port: int32(CalledPort),
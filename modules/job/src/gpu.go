@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+// The GPU resources advertised by the NVIDIA device plugin, and the label of the GPU product set
+// by the GPU feature discovery.
+const (
+	GPUResourceName       = "nvidia.com/gpu"
+	SharedGPUResourceName = "nvidia.com/gpu.shared"
+	MIGResourcePrefix     = "nvidia.com/mig-"
+	GPUProductLabel       = "nvidia.com/gpu.product"
+)
+
+// The sharing modes of the GPUs.
+const (
+	GPUSharingExclusive = "exclusive"
+	GPUSharingShared    = "shared"
+)
+
+var (
+	ErrInvalidGPUCount        = errors.New("count of gpu must be greater than 0")
+	ErrInvalidMIGProfile      = errors.New("migProfile of gpu must be <compute>g.<memory>gb, e.g. 1g.10gb")
+	ErrInvalidGPUSharing      = errors.New("sharing of gpu must be exclusive or shared")
+	ErrSharedMIG              = errors.New("the MIG instances of gpu can not be shared")
+	ErrConflictingGPUProducts = errors.New("the containers must request the gpu of the same product")
+	ErrDuplicateGPUResource   = errors.New("the gpu resource must not be declared in both gpu and resources")
+)
+
+// migProfilePattern matches the MIG profiles, e.g. 1g.10gb.
+var migProfilePattern = regexp.MustCompile(`^[1-7]g\.[1-9][0-9]*gb$`)
+
+// defaultGPUScheduling is used to assign the GPU pods to the nodes if the GPU node pools are not
+// configured in workspace, which tolerates the common taint of the GPU nodes.
+var defaultGPUScheduling = Scheduling{
+	Tolerations: []Toleration{
+		{
+			Key:      GPUResourceName,
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	},
+}
+
+// validateGPU validates the GPUs requested by the containers, where the pods are assigned to the
+// nodes of one GPU product.
+func validateGPU(base *Base) error {
+	names := make([]string, 0, len(base.Containers))
+	for name := range base.Containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	product := ""
+	for _, name := range names {
+		c := base.Containers[name]
+		gpu := c.GPU
+		if gpu == nil {
+			continue
+		}
+		if gpu.Count <= 0 {
+			return fmt.Errorf("%w, got %d of container %s", ErrInvalidGPUCount, gpu.Count, name)
+		}
+		if gpu.MIGProfile != "" && !migProfilePattern.MatchString(gpu.MIGProfile) {
+			return fmt.Errorf("%w, got %q of container %s", ErrInvalidMIGProfile, gpu.MIGProfile, name)
+		}
+		switch gpu.Sharing {
+		case "", GPUSharingExclusive:
+		case GPUSharingShared:
+			if gpu.MIGProfile != "" {
+				return fmt.Errorf("%w, container %s", ErrSharedMIG, name)
+			}
+		default:
+			return fmt.Errorf("%w, got %q of container %s", ErrInvalidGPUSharing, gpu.Sharing, name)
+		}
+		if gpu.Product != "" {
+			if product != "" && product != gpu.Product {
+				return fmt.Errorf("%w, got %s and %s", ErrConflictingGPUProducts, product, gpu.Product)
+			}
+			product = gpu.Product
+		}
+		if _, ok := c.Resources[gpuResourceName(gpu)]; ok {
+			return fmt.Errorf("%w, got %s of container %s", ErrDuplicateGPUResource, gpuResourceName(gpu), name)
+		}
+	}
+	return nil
+}
+
+// completeGPU completes the resources of the containers requesting the GPUs, and assigns the pods
+// to the GPU node pools configured in workspace. The node selectors of the pools and the GPU
+// product are set into the pods along with the tolerations of the pools.
+func completeGPU(base *Base, config kusionapiv1.GenericConfig) error {
+	product, requested := "", false
+	for name, c := range base.Containers {
+		if c.GPU == nil {
+			continue
+		}
+		requested = true
+		if c.GPU.Product != "" {
+			product = c.GPU.Product
+		}
+		// The extended resources can not be overcommitted, whose limits are the requests.
+		resources := maps.Clone(c.Resources)
+		if resources == nil {
+			resources = make(map[string]string)
+		}
+		resources[gpuResourceName(c.GPU)] = strconv.Itoa(int(c.GPU.Count))
+		c.Resources = resources
+		base.Containers[name] = c
+	}
+	if !requested {
+		return nil
+	}
+
+	platform := defaultGPUScheduling
+	if value, ok := config[FieldGPU]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		platform = Scheduling{}
+		if err = yaml.Unmarshal(out, &platform); err != nil {
+			return fmt.Errorf("invalid gpu config in workspace, %w", err)
+		}
+	}
+
+	if base.Scheduling == nil {
+		base.Scheduling = &Scheduling{}
+	}
+	if len(platform.NodeSelector) != 0 || product != "" {
+		if base.Scheduling.NodeSelector == nil {
+			base.Scheduling.NodeSelector = make(map[string]string)
+		}
+		maps.Copy(base.Scheduling.NodeSelector, platform.NodeSelector)
+		if product != "" {
+			base.Scheduling.NodeSelector[GPUProductLabel] = product
+		}
+	}
+	base.Scheduling.Tolerations = appendTolerations(base.Scheduling.Tolerations, platform.Tolerations)
+	return nil
+}
+
+// gpuResourceName returns the name of the extended resource of the GPUs, i.e. nvidia.com/gpu of
+// the exclusive GPUs, nvidia.com/gpu.shared of the time-sliced ones, and nvidia.com/mig-<profile>
+// of the MIG instances.
+func gpuResourceName(gpu *GPU) string {
+	switch {
+	case gpu.MIGProfile != "":
+		return MIGResourcePrefix + gpu.MIGProfile
+	case gpu.Sharing == GPUSharingShared:
+		return SharedGPUResourceName
+	default:
+		return GPUResourceName
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestValidateGPU(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers map[string]Container
+		wantErr    error
+	}{
+		{
+			name:       "no gpu",
+			containers: map[string]Container{"web": {Image: "web:v1"}},
+		},
+		{
+			name: "valid gpus",
+			containers: map[string]Container{
+				"trainer": {Image: "trainer:v1", GPU: &GPU{Count: 2, Product: "NVIDIA-A100-SXM4-80GB"}},
+				"sidecar": {Image: "sidecar:v1", GPU: &GPU{Count: 1, MIGProfile: "1g.10gb", Product: "NVIDIA-A100-SXM4-80GB"}},
+			},
+		},
+		{
+			name:       "invalid count",
+			containers: map[string]Container{"trainer": {Image: "trainer:v1", GPU: &GPU{}}},
+			wantErr:    ErrInvalidGPUCount,
+		},
+		{
+			name:       "invalid mig profile",
+			containers: map[string]Container{"trainer": {Image: "trainer:v1", GPU: &GPU{Count: 1, MIGProfile: "1g"}}},
+			wantErr:    ErrInvalidMIGProfile,
+		},
+		{
+			name:       "invalid sharing",
+			containers: map[string]Container{"trainer": {Image: "trainer:v1", GPU: &GPU{Count: 1, Sharing: "mps"}}},
+			wantErr:    ErrInvalidGPUSharing,
+		},
+		{
+			name: "shared mig",
+			containers: map[string]Container{
+				"trainer": {Image: "trainer:v1", GPU: &GPU{Count: 1, MIGProfile: "1g.10gb", Sharing: GPUSharingShared}},
+			},
+			wantErr: ErrSharedMIG,
+		},
+		{
+			name: "conflicting products",
+			containers: map[string]Container{
+				"a": {Image: "a:v1", GPU: &GPU{Count: 1, Product: "NVIDIA-A100-SXM4-80GB"}},
+				"b": {Image: "b:v1", GPU: &GPU{Count: 1, Product: "Tesla-T4"}},
+			},
+			wantErr: ErrConflictingGPUProducts,
+		},
+		{
+			name: "duplicate resource",
+			containers: map[string]Container{
+				"trainer": {Image: "trainer:v1", Resources: map[string]string{GPUResourceName: "1"}, GPU: &GPU{Count: 1}},
+			},
+			wantErr: ErrDuplicateGPUResource,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGPU(&Base{Containers: tt.containers})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCompleteGPU(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          *Base
+		config        kusionapiv1.GenericConfig
+		wantResources map[string]string
+		want          *Scheduling
+	}{
+		{
+			name:   "no gpu",
+			base:   &Base{Containers: map[string]Container{"main": {Image: "web:v1"}}},
+			config: kusionapiv1.GenericConfig{},
+		},
+		{
+			name: "default gpu scheduling",
+			base: &Base{Containers: map[string]Container{
+				"main": {Image: "trainer:v1", Resources: map[string]string{"cpu": "4"}, GPU: &GPU{Count: 2, Product: "Tesla-T4"}},
+			}},
+			config:        kusionapiv1.GenericConfig{},
+			wantResources: map[string]string{"cpu": "4", GPUResourceName: "2"},
+			want: &Scheduling{
+				NodeSelector: map[string]string{GPUProductLabel: "Tesla-T4"},
+				Tolerations:  defaultGPUScheduling.Tolerations,
+			},
+		},
+		{
+			name: "gpu node pools in workspace",
+			base: &Base{
+				Containers: map[string]Container{
+					"main": {Image: "inference:v1", GPU: &GPU{Count: 1, Sharing: GPUSharingShared}},
+				},
+				Scheduling: &Scheduling{
+					NodeSelector: map[string]string{"disktype": "ssd"},
+					Tolerations:  []Toleration{{Key: "spot", Operator: corev1.TolerationOpExists}},
+				},
+			},
+			config: kusionapiv1.GenericConfig{
+				"gpu": map[string]any{
+					"nodeSelector": map[string]any{"pool": "gpu"},
+					"tolerations": []any{
+						map[string]any{
+							"key":      "pool",
+							"operator": "Equal",
+							"value":    "gpu",
+							"effect":   "NoSchedule",
+						},
+					},
+				},
+			},
+			wantResources: map[string]string{SharedGPUResourceName: "1"},
+			want: &Scheduling{
+				NodeSelector: map[string]string{"disktype": "ssd", "pool": "gpu"},
+				Tolerations: []Toleration{
+					{Key: "spot", Operator: corev1.TolerationOpExists},
+					{Key: "pool", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		},
+		{
+			name: "mig instances",
+			base: &Base{Containers: map[string]Container{
+				"main": {Image: "inference:v1", GPU: &GPU{Count: 1, MIGProfile: "3g.40gb"}},
+			}},
+			config:        kusionapiv1.GenericConfig{},
+			wantResources: map[string]string{"nvidia.com/mig-3g.40gb": "1"},
+			want:          &Scheduling{Tolerations: defaultGPUScheduling.Tolerations},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeGPU(tt.base, tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.Scheduling)
+			if tt.wantResources != nil {
+				assert.Equal(t, tt.wantResources, tt.base.Containers["main"].Resources)
+			}
+		})
+	}
+}
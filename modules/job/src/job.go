@@ -2,9 +2,8 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"runtime/debug"
+	"os"
 
 	"gopkg.in/yaml.v2"
 	batchv1 "k8s.io/api/batch/v1"
@@ -14,6 +13,7 @@ import (
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
 )
 
 func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
@@ -21,13 +21,16 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "job", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate Job module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in job module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			if err = moduleutil.Finalize("job", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -35,20 +38,33 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 		logger.Info("Job does not exist in AppConfig config")
 		return nil, nil
 	}
+	if err = moduleutil.ValidateConfig(request.DevConfig, Job{}); err != nil {
+		return nil, moduleutil.NewModuleError("job", moduleutil.PhaseValidate, fmt.Errorf("validate Job dev config failed, %w", err))
+	}
+	if err = moduleutil.ValidateConfig(request.PlatformConfig, PlatformConfig{}); err != nil {
+		return nil, moduleutil.NewModuleError("job", moduleutil.PhaseValidate, fmt.Errorf("validate Job platform config failed, %w", err))
+	}
 	out, err := yaml.Marshal(request.DevConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	if err = yaml.Unmarshal(out, j); err != nil {
-		return nil, fmt.Errorf("complete Job by dev config failed, %w", err)
+		return nil, moduleutil.NewModuleError("job", moduleutil.PhaseComplete, fmt.Errorf("complete Job by dev config failed, %w", err))
+	}
+
+	if err = validateOS(&j.Base); err != nil {
+		return nil, moduleutil.NewModuleError("job", moduleutil.PhaseValidate, err)
+	}
+	if err = validateGPU(&j.Base); err != nil {
+		return nil, moduleutil.NewModuleError("job", moduleutil.PhaseValidate, err)
 	}
 
 	if err = completeBaseWorkload(&j.Base, request.PlatformConfig); err != nil {
-		return nil, fmt.Errorf("complete Job by platform config failed, %w", err)
+		return nil, moduleutil.NewModuleError("job", moduleutil.PhaseComplete, fmt.Errorf("complete Job by platform config failed, %w", err))
 	}
 
-	uniqueAppName := module.UniqueAppName(request.Project, request.Stack, request.App)
+	uniqueAppName := moduleutil.AppName(request)
 
 	meta := metav1.ObjectMeta{
 		Namespace: request.Project,
@@ -67,6 +83,15 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 		return nil, err
 	}
 
+	containers, volumes = mountServiceAccountTokens(j.ServiceAccount, containers, volumes)
+
+	// Resolve the references to the outputs of the other modules, e.g. the database Secret, which
+	// the Job waits on.
+	dependsOn, err := moduleutil.ResolveOutputRefs(request, containers)
+	if err != nil {
+		return nil, err
+	}
+
 	res := make([]kusionapiv1.Resource, 0)
 	for _, cm := range configMaps {
 		cm.Namespace = request.Project
@@ -91,6 +116,19 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 			},
 		},
 	}
+	handleScheduling(&j.Base, &jobSpec.Template.Spec)
+
+	// Create the dedicated ServiceAccount the pods run as, which the workload waits on.
+	if sa := handleServiceAccount(&j.Base, uniqueAppName, &jobSpec.Template.Spec); sa != nil {
+		sa.Namespace = request.Project
+		resourceID := module.KubernetesResourceID(sa.TypeMeta, sa.ObjectMeta)
+		resource, err := module.WrapK8sResourceToKusionResource(resourceID, sa)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *resource)
+		dependsOn = append(dependsOn, resourceID)
+	}
 
 	if j.Schedule == "" {
 		k8sJob := &batchv1.Job{
@@ -107,6 +145,7 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 		if err != nil {
 			return nil, err
 		}
+		resource.DependsOn = dependsOn
 		res = append(res, *resource)
 
 		return &module.GeneratorResponse{
@@ -133,6 +172,7 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 	if err != nil {
 		return nil, err
 	}
+	resource.DependsOn = dependsOn
 	res = append(res, *resource)
 	return &module.GeneratorResponse{
 		Resources: res,
@@ -140,5 +180,12 @@ func (j *Job) Generate(ctx context.Context, request *module.GeneratorRequest) (r
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Job{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&Job{})
 }
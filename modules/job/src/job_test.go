@@ -6,9 +6,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	yamlv2 "gopkg.in/yaml.v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
 )
 
 func TestGenerate(t *testing.T) {
@@ -59,8 +64,14 @@ func TestGenerate(t *testing.T) {
 							"metadata": map[string]interface{}{
 								"creationTimestamp": nil,
 								"labels": map[string]interface{}{
-									"app.kubernetes.io/name":    "foo",
-									"app.kubernetes.io/part-of": "default",
+									"app.kubernetes.io/name":       "foo",
+									"app.kubernetes.io/part-of":    "default",
+									"app.kubernetes.io/managed-by": "kusion",
+									"kusionstack.io/project":       "default",
+									"kusionstack.io/stack":         "dev",
+								},
+								"annotations": map[string]interface{}{
+									"kusionstack.io/module": "job",
 								},
 								"name":      "default-dev-foo",
 								"namespace": "default",
@@ -126,3 +137,223 @@ func TestGenerate(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateInvalidConfig(t *testing.T) {
+	devConfig := map[string]interface{}{
+		"containers": map[string]interface{}{
+			"busybox": map[string]interface{}{
+				"image": "busybox:1.28",
+				"env": map[string]interface{}{
+					"FOO": "bar",
+				},
+				"livenessProbe": map[string]interface{}{
+					"probeHandler": map[string]interface{}{
+						"_type":  "service.container.probe.Exec",
+						"comand": []interface{}{"true"},
+					},
+				},
+			},
+		},
+		"shedule": "0 * * * *",
+	}
+	request := &module.GeneratorRequest{
+		Project:   "default",
+		Stack:     "dev",
+		App:       "foo",
+		DevConfig: devConfig,
+	}
+
+	_, err := (&Job{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "containers.busybox.livenessProbe.probeHandler.comand: unknown field")
+	assert.ErrorContains(t, err, "shedule: unknown field")
+	assert.NotContains(t, err.Error(), "env")
+	var moduleErr *moduleutil.ModuleError
+	if assert.ErrorAs(t, err, &moduleErr) {
+		assert.Equal(t, "job", moduleErr.Module)
+		assert.Equal(t, moduleutil.PhaseValidate, moduleErr.Phase)
+		assert.Equal(t, "containers.busybox.livenessProbe.probeHandler.comand", moduleErr.Path)
+	}
+}
+
+func TestGeneratePolicies(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"containers": map[string]interface{}{
+				"busybox": map[string]interface{}{"image": "busybox:1.28"},
+			},
+			"schedule": "0 * * * *",
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			moduleutil.PoliciesKey: []interface{}{
+				map[string]interface{}{
+					"name":     "require-limits",
+					"kinds":    []interface{}{"CronJob"},
+					"path":     "spec.jobTemplate.spec.template.spec.containers[*].resources.limits",
+					"operator": "absent",
+					"message":  "the containers must have resource limits",
+				},
+			},
+		},
+	}
+
+	_, err := (&Job{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, moduleutil.ErrPolicyViolation)
+	assert.ErrorContains(t, err, "require-limits: batch/v1:CronJob:default:default-dev-foo: the containers must have resource limits")
+
+	request.DevConfig["containers"] = map[string]interface{}{
+		"busybox": map[string]interface{}{
+			"image":     "busybox:1.28",
+			"resources": map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+		},
+	}
+	_, err = (&Job{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+}
+
+func TestGenerateWithModuleOutputs(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: map[string]interface{}{
+			"containers": map[string]interface{}{
+				"migrate": map[string]interface{}{
+					"image": "migrate:v1",
+					"env": map[string]interface{}{
+						"DB_HOST": "${postgres.host}",
+					},
+				},
+			},
+		},
+	}
+
+	response, err := (&Job{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, response.Resources, 1)
+	assert.Equal(t, []string{"v1:Secret:default:default-dev-foo-postgres-postgres"}, response.Resources[0].DependsOn)
+}
+
+func TestGenerateWindows(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"os": "windows",
+			"containers": map[string]interface{}{
+				"migrate": map[string]interface{}{"image": "mcr.microsoft.com/windows/servercore:ltsc2022"},
+			},
+		},
+	}
+
+	response, err := (&Job{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	job := &batchv1.Job{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[0].Attributes, job))
+	assert.Equal(t, &corev1.PodOS{Name: corev1.Windows}, job.Spec.Template.Spec.OS)
+	assert.Equal(t, map[string]string{corev1.LabelOSStable: OSWindows}, job.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "os", Operator: corev1.TolerationOpEqual, Value: OSWindows, Effect: corev1.TaintEffectNoSchedule},
+	}, job.Spec.Template.Spec.Tolerations)
+
+	request.DevConfig["os"] = "darwin"
+	_, err = (&Job{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, ErrUnsupportedOS)
+}
+
+func TestGenerateRuntimeClass(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"untrusted": true,
+			"containers": map[string]interface{}{
+				"busybox": map[string]interface{}{"image": "busybox:1.28"},
+			},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"runtimeClass": map[string]interface{}{"default": "runc", "untrusted": "gvisor"},
+		},
+	}
+
+	response, err := (&Job{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	job := &batchv1.Job{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[0].Attributes, job))
+	assert.Equal(t, "gvisor", *job.Spec.Template.Spec.RuntimeClassName)
+}
+
+func TestGenerateServiceAccount(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"serviceAccount": map[string]interface{}{
+				"tokens": []interface{}{
+					map[string]interface{}{"audience": "vault", "mountPath": "/var/run/secrets/vault"},
+				},
+			},
+			"containers": map[string]interface{}{
+				"busybox": map[string]interface{}{"image": "busybox:1.28"},
+			},
+		},
+	}
+
+	response, err := (&Job{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, response.Resources, 2)
+	assert.Equal(t, "v1:ServiceAccount:default:default-dev-foo", response.Resources[0].ID)
+	assert.Equal(t, []string{response.Resources[0].ID}, response.Resources[1].DependsOn)
+	job := &batchv1.Job{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[1].Attributes, job))
+	assert.Equal(t, "default-dev-foo", job.Spec.Template.Spec.ServiceAccountName)
+	assert.False(t, *job.Spec.Template.Spec.AutomountServiceAccountToken)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "serviceaccount-token-0", MountPath: "/var/run/secrets/vault", ReadOnly: true},
+	}, job.Spec.Template.Spec.Containers[0].VolumeMounts)
+}
+
+func TestGenerateGPU(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"containers": map[string]interface{}{
+				"trainer": map[string]interface{}{
+					"image": "trainer:v1",
+					"gpu":   map[string]interface{}{"count": 1, "migProfile": "1g.10gb"},
+				},
+			},
+		},
+	}
+
+	response, err := (&Job{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	job := &batchv1.Job{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[0].Attributes, job))
+	assert.Equal(t, corev1.ResourceList{
+		"nvidia.com/mig-1g.10gb": resource.MustParse("1"),
+	}, job.Spec.Template.Spec.Containers[0].Resources.Limits)
+	assert.Equal(t, defaultGPUScheduling.Tolerations[0].Key, job.Spec.Template.Spec.Tolerations[0].Key)
+
+	request.DevConfig["containers"].(map[string]interface{})["trainer"].(map[string]interface{})["gpu"] = map[string]interface{}{"count": 0}
+	_, err = (&Job{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, ErrInvalidGPUCount)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestCompleteServiceAccount(t *testing.T) {
+	automount, noAutomount := true, false
+	hour, day, minute := int64(3600), int64(86400), int64(60)
+	platformConfig := kusionapiv1.GenericConfig{
+		"serviceAccount": map[string]any{
+			"automountToken": true,
+			"tokens": []any{
+				map[string]any{"audience": "vault", "mountPath": "/var/run/secrets/vault", "expirationSeconds": 86400},
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		base    *Base
+		config  kusionapiv1.GenericConfig
+		want    *ServiceAccount
+		wantErr error
+	}{
+		{
+			name:   "no service account",
+			base:   &Base{},
+			config: platformConfig,
+		},
+		{
+			name:   "service account with defaults in workspace",
+			base:   &Base{ServiceAccount: &ServiceAccount{}},
+			config: platformConfig,
+			want: &ServiceAccount{
+				AutomountToken: &automount,
+				Tokens:         []ProjectedToken{{Audience: "vault", MountPath: "/var/run/secrets/vault", ExpirationSeconds: &day}},
+			},
+		},
+		{
+			name: "service account without automount",
+			base: &Base{ServiceAccount: &ServiceAccount{
+				Name:   "reader",
+				Tokens: []ProjectedToken{{Audience: "sts.amazonaws.com", MountPath: "/var/run/secrets/aws"}},
+			}},
+			config: kusionapiv1.GenericConfig{},
+			want: &ServiceAccount{
+				Name:           "reader",
+				AutomountToken: &noAutomount,
+				Tokens:         []ProjectedToken{{Audience: "sts.amazonaws.com", MountPath: "/var/run/secrets/aws", ExpirationSeconds: &hour}},
+			},
+		},
+		{
+			name:    "invalid name",
+			base:    &Base{ServiceAccount: &ServiceAccount{Name: "Reader"}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrInvalidServiceAccountName,
+		},
+		{
+			name:    "empty audience",
+			base:    &Base{ServiceAccount: &ServiceAccount{Tokens: []ProjectedToken{{MountPath: "/token"}}}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrEmptyTokenAudience,
+		},
+		{
+			name: "invalid expiration",
+			base: &Base{ServiceAccount: &ServiceAccount{Tokens: []ProjectedToken{
+				{Audience: "vault", MountPath: "/token", ExpirationSeconds: &minute},
+			}}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrInvalidTokenExpiration,
+		},
+		{
+			name: "duplicate mount path",
+			base: &Base{ServiceAccount: &ServiceAccount{Tokens: []ProjectedToken{
+				{Audience: "vault", MountPath: "/token"},
+				{Audience: "sts.amazonaws.com", MountPath: "/token/"},
+			}}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrInvalidTokenMountPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeServiceAccount(tt.base, tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.ServiceAccount)
+		})
+	}
+}
+
+func TestHandleServiceAccount(t *testing.T) {
+	noAutomount := false
+	spec := &corev1.PodSpec{}
+	sa := handleServiceAccount(&Base{ServiceAccount: &ServiceAccount{AutomountToken: &noAutomount}}, "default-dev-foo", spec)
+	if assert.NotNil(t, sa) {
+		assert.Equal(t, "default-dev-foo", sa.Name)
+		assert.Equal(t, &noAutomount, sa.AutomountServiceAccountToken)
+	}
+	assert.Equal(t, "default-dev-foo", spec.ServiceAccountName)
+	assert.Equal(t, &noAutomount, spec.AutomountServiceAccountToken)
+
+	spec = &corev1.PodSpec{}
+	sa = handleServiceAccount(&Base{ServiceAccount: &ServiceAccount{Name: "reader", AutomountToken: &noAutomount}}, "default-dev-foo", spec)
+	assert.Nil(t, sa)
+	assert.Equal(t, "reader", spec.ServiceAccountName)
+
+	spec = &corev1.PodSpec{}
+	assert.Nil(t, handleServiceAccount(&Base{}, "default-dev-foo", spec))
+	assert.Equal(t, &corev1.PodSpec{}, spec)
+}
+
+func TestMountServiceAccountTokens(t *testing.T) {
+	expiration := int64(3600)
+	containers := []corev1.Container{{Name: "app"}, {Name: "proxy"}}
+	containers, volumes := mountServiceAccountTokens(&ServiceAccount{
+		Tokens: []ProjectedToken{{Audience: "vault", MountPath: "/var/run/secrets/vault", ExpirationSeconds: &expiration}},
+	}, containers, nil)
+
+	assert.Equal(t, []corev1.Volume{
+		{
+			Name: "serviceaccount-token-0",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          "vault",
+							ExpirationSeconds: &expiration,
+							Path:              "token",
+						}},
+					},
+				},
+			},
+		},
+	}, volumes)
+	for _, c := range containers {
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: "serviceaccount-token-0", MountPath: "/var/run/secrets/vault", ReadOnly: true},
+		}, c.VolumeMounts)
+	}
+
+	containers, volumes = mountServiceAccountTokens(nil, []corev1.Container{{Name: "app"}}, nil)
+	assert.Nil(t, volumes)
+	assert.Nil(t, containers[0].VolumeMounts)
+}
@@ -1,6 +1,10 @@
 package main
 
-import "gopkg.in/yaml.v2"
+import (
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"moduleutil"
+)
 
 // Job is a kind of workload profile that describes how to run your application code. This is typically used for tasks that take from
 // a few seconds to a few days to complete.
@@ -47,6 +51,23 @@ type Container struct {
 	StartupProbe *Probe `yaml:"startupProbe,omitempty" json:"startupProbe,omitempty"`
 	// Actions that the management system should take in response to container lifecycle events.
 	Lifecycle *Lifecycle `yaml:"lifecycle,omitempty" json:"lifecycle,omitempty"`
+	// GPU requests the GPUs of the GPU node pools for the container.
+	GPU *GPU `yaml:"gpu,omitempty" json:"gpu,omitempty"`
+}
+
+// GPU describes the NVIDIA GPUs requested by the container, which are advertised by the NVIDIA
+// device plugin and labeled by the GPU feature discovery on the nodes.
+type GPU struct {
+	// Count is the number of the GPUs, or the MIG instances of the profile.
+	Count int32 `yaml:"count" json:"count"`
+	// Product is the product name of the GPUs the pods are assigned to, e.g. NVIDIA-A100-SXM4-80GB.
+	Product string `yaml:"product,omitempty" json:"product,omitempty"`
+	// MIGProfile is the profile of the MIG instances partitioned from the GPUs, e.g. 1g.10gb, which
+	// requires the mixed strategy of MIG.
+	MIGProfile string `yaml:"migProfile,omitempty" json:"migProfile,omitempty"`
+	// Sharing is exclusive or shared, defaults to exclusive. The shared GPUs are time-sliced with
+	// the other pods and advertised as nvidia.com/gpu.shared.
+	Sharing string `yaml:"sharing,omitempty" json:"sharing,omitempty"`
 }
 
 // FileSpec defines the target file in a Container
@@ -144,6 +165,93 @@ type LifecycleHandler struct {
 	*HTTPGetAction `yaml:",inline" json:",inline"`
 }
 
+// JSONSchema returns the JSON Schema of the probe handler, whose action is chosen by the _type field.
+func (ProbeHandler) JSONSchema() map[string]interface{} {
+	return handlerSchema(ExecAction{}, HTTPGetAction{}, TCPSocketAction{})
+}
+
+// JSONSchema returns the JSON Schema of the lifecycle handler, whose action is chosen by the _type field.
+func (LifecycleHandler) JSONSchema() map[string]interface{} {
+	return handlerSchema(ExecAction{}, HTTPGetAction{})
+}
+
+// handlerSchema merges the properties of the actions into the JSON Schema of a handler.
+func handlerSchema(actions ...interface{}) map[string]interface{} {
+	properties := map[string]interface{}{
+		"_type": map[string]interface{}{"type": "string"},
+	}
+	for _, action := range actions {
+		for name, property := range moduleutil.JSONSchema(action)["properties"].(map[string]interface{}) {
+			properties[name] = property
+		}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// PlatformConfig describes the platform config of the job module in workspace.
+type PlatformConfig struct {
+	// Labels and Annotations are merged into the ones of the workload.
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	// Replicas is the default number of containers that should be run.
+	Replicas *int32 `yaml:"replicas,omitempty" json:"replicas,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `yaml:"podSecurity,omitempty" json:"podSecurity,omitempty"`
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
+	// Windows is the node selectors and tolerations of the Windows node pools.
+	Windows *Scheduling `yaml:"windows,omitempty" json:"windows,omitempty"`
+	// GPU is the node selectors and tolerations of the GPU node pools.
+	GPU *Scheduling `yaml:"gpu,omitempty" json:"gpu,omitempty"`
+	// RuntimeClass is the runtime classes of the pods enforced by the platform.
+	RuntimeClass *RuntimeClass `yaml:"runtimeClass,omitempty" json:"runtimeClass,omitempty"`
+	// ServiceAccount is the default token automount and projected tokens of the ServiceAccounts.
+	ServiceAccount *ServiceAccount `yaml:"serviceAccount,omitempty" json:"serviceAccount,omitempty"`
+}
+
+// RuntimeClass describes the runtime classes of the pods enforced by the platform, e.g.
+//
+//	runtimeClass:
+//	  default: runc
+//	  untrusted: gvisor
+//	  allowed: [runc, gvisor, kata]
+type RuntimeClass struct {
+	// Default is the runtime class of the workloads which do not declare one.
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Untrusted is the sandboxed runtime class enforced on the untrusted workloads, e.g. gvisor
+	// or kata, which overrides the one declared in the workload.
+	Untrusted string `yaml:"untrusted,omitempty" json:"untrusted,omitempty"`
+	// Allowed is the runtime classes allowed to be declared in the workloads, any is allowed if empty.
+	Allowed []string `yaml:"allowed,omitempty" json:"allowed,omitempty"`
+}
+
+// Scheduling describes the node selectors and tolerations used to assign the pods to the node pools.
+type Scheduling struct {
+	// NodeSelector is a selector which must match a node's labels for the pod to be scheduled on that node.
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty" json:"nodeSelector,omitempty"`
+	// Tolerations allow the pods to be scheduled onto nodes with matching taints.
+	Tolerations []Toleration `yaml:"tolerations,omitempty" json:"tolerations,omitempty"`
+}
+
+// Toleration is attached to the pod to tolerate any taint that matches the triple <key,value,effect>.
+type Toleration struct {
+	// Key is the taint key that the toleration applies to. Empty means match all taint keys.
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+	// Operator represents a key's relationship to the value, Exists or Equal.
+	Operator corev1.TolerationOperator `yaml:"operator,omitempty" json:"operator,omitempty"`
+	// Value is the taint value the toleration matches to.
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Effect indicates the taint effect to match. Empty means match all taint effects.
+	Effect corev1.TaintEffect `yaml:"effect,omitempty" json:"effect,omitempty"`
+	// TolerationSeconds represents the period of time the toleration tolerates a NoExecute taint.
+	TolerationSeconds *int64 `yaml:"tolerationSeconds,omitempty" json:"tolerationSeconds,omitempty"`
+}
+
 type Protocol string
 
 const (
@@ -159,9 +267,13 @@ type Secret struct {
 }
 
 const (
-	FieldLabels      = "labels"
-	FieldAnnotations = "annotations"
-	FieldReplicas    = "replicas"
+	FieldLabels         = "labels"
+	FieldAnnotations    = "annotations"
+	FieldReplicas       = "replicas"
+	FieldWindows        = "windows"
+	FieldGPU            = "gpu"
+	FieldRuntimeClass   = "runtimeClass"
+	FieldServiceAccount = "serviceAccount"
 )
 
 // Base defines set of attributes shared by different workload profile, e.g. Service and Job.
@@ -177,4 +289,44 @@ type Base struct {
 	// Labels and Annotations can be used to attach arbitrary metadata as key-value pairs to resources.
 	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// OS is the operating system of the nodes the pods run on, linux or windows, defaults to linux.
+	OS string `json:"os,omitempty" yaml:"os,omitempty"`
+	// RuntimeClassName is the RuntimeClass used to run the pods, e.g. gvisor or kata.
+	RuntimeClassName string `json:"runtimeClassName,omitempty" yaml:"runtimeClassName,omitempty"`
+	// Untrusted marks the workload running untrusted code, which runs in the sandboxed runtime
+	// class enforced by the platform.
+	Untrusted bool `json:"untrusted,omitempty" yaml:"untrusted,omitempty"`
+	// ServiceAccount runs the pods as the dedicated ServiceAccount with the projected tokens.
+	ServiceAccount *ServiceAccount `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+	// Scheduling is the node selectors and tolerations of the node pools the pods are assigned to
+	// by the platform, which is not declared in the workload.
+	Scheduling *Scheduling `json:"-" yaml:"-"`
+}
+
+// The operating systems of the nodes the pods run on.
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+)
+
+// ServiceAccount describes the ServiceAccount the pods run as, and the tokens of it projected into
+// the pods, which are bound to the audiences and rotated by the kubelet before expiry.
+type ServiceAccount struct {
+	// Name of the existing ServiceAccount. A dedicated ServiceAccount named after the workload is
+	// generated if empty.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// AutomountToken mounts the default token of the ServiceAccount into the pods, defaults to false.
+	AutomountToken *bool `yaml:"automountToken,omitempty" json:"automountToken,omitempty"`
+	// Tokens are the tokens projected into every container.
+	Tokens []ProjectedToken `yaml:"tokens,omitempty" json:"tokens,omitempty"`
+}
+
+// ProjectedToken describes the ServiceAccount token of the audience projected into the containers.
+type ProjectedToken struct {
+	// Audience is the intended audience of the token, e.g. sts.amazonaws.com or vault.
+	Audience string `yaml:"audience" json:"audience"`
+	// ExpirationSeconds is the requested duration of validity of the token, defaults to 3600.
+	ExpirationSeconds *int64 `yaml:"expirationSeconds,omitempty" json:"expirationSeconds,omitempty"`
+	// MountPath is the directory the token file named token is mounted to.
+	MountPath string `yaml:"mountPath" json:"mountPath"`
 }
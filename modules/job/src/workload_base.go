@@ -1,14 +1,19 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/imdario/mergo"
 	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -19,6 +24,18 @@ import (
 	"kusionstack.io/kusion/pkg/util/net"
 )
 
+var (
+	ErrUnsupportedOS = errors.New("os must be linux or windows")
+
+	ErrUnsupportedRuntimeClass = errors.New("runtimeClassName is not allowed by the platform")
+	ErrEmptySandboxRuntime     = errors.New("untrusted workloads must specify a sandboxed runtimeClassName if not enforced by the platform")
+
+	ErrUnsupportedResource   = errors.New("resource must be cpu, memory, ephemeral-storage, hugepages-<size> or an extended resource named <domain>/<name>")
+	ErrOvercommittedResource = errors.New("request and limit of hugepages and extended resources must be equal")
+	ErrFractionalResource    = errors.New("extended resources must be whole numbers")
+	ErrHugePagesOnly         = errors.New("hugepages require cpu or memory to be specified")
+)
+
 func toOrderedContainers(
 	appContainers map[string]Container,
 	uniqueAppName string,
@@ -141,7 +158,57 @@ func handleResourceRequirementsV1(resources map[string]string) (corev1.ResourceR
 		}
 		maps.Copy(result.Limits, limits)
 	}
-	return result, nil
+	return result, validateResourceRequirements(resources, result)
+}
+
+// extendedResourcePattern matches the names of the extended resources advertised by the device
+// plugins, e.g. nvidia.com/gpu and amd.com/gpu.
+var extendedResourcePattern = regexp.MustCompile(
+	`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// validateResourceRequirements validates the names and values of the container resources. Besides
+// cpu, memory and ephemeral-storage, the hugepages and the extended resources are supported, which
+// can not be overcommitted, and the extended resources must be whole numbers.
+func validateResourceRequirements(resources map[string]string, result corev1.ResourceRequirements) error {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hugePages := false
+	for _, key := range names {
+		name := corev1.ResourceName(key)
+		switch {
+		case name == corev1.ResourceCPU || name == corev1.ResourceMemory || name == corev1.ResourceEphemeralStorage:
+			continue
+		case strings.HasPrefix(key, corev1.ResourceHugePagesPrefix):
+			if _, err := resource.ParseQuantity(strings.TrimPrefix(key, corev1.ResourceHugePagesPrefix)); err != nil {
+				return fmt.Errorf("%w, got %s", ErrUnsupportedResource, key)
+			}
+			hugePages = true
+		case extendedResourcePattern.MatchString(key) && !strings.Contains(key, "kubernetes.io/"):
+			if quantity, ok := result.Limits[name]; ok && quantity.MilliValue()%1000 != 0 {
+				return fmt.Errorf("%w, got %s=%s", ErrFractionalResource, key, resources[key])
+			}
+		default:
+			return fmt.Errorf("%w, got %s", ErrUnsupportedResource, key)
+		}
+
+		request, ok := result.Requests[name]
+		if limit := result.Limits[name]; ok && !request.Equal(limit) {
+			return fmt.Errorf("%w, got %s=%s", ErrOvercommittedResource, key, resources[key])
+		}
+	}
+
+	if hugePages {
+		_, cpu := result.Limits[corev1.ResourceCPU]
+		_, memory := result.Limits[corev1.ResourceMemory]
+		if !cpu && !memory {
+			return ErrHugePagesOnly
+		}
+	}
+	return nil
 }
 
 // populateResourceLists takes strings of form <resourceName>=[<minValue>-]<maxValue> and
@@ -419,9 +486,146 @@ func completeBaseWorkload(base *Base, config kusionapiv1.GenericConfig) error {
 			return err
 		}
 	}
+	if err = completeWindowsScheduling(base, config); err != nil {
+		return err
+	}
+	if err = completeGPU(base, config); err != nil {
+		return err
+	}
+	if err = completeRuntimeClass(base, config); err != nil {
+		return err
+	}
+	return completeServiceAccount(base, config)
+}
+
+// defaultWindowsScheduling is used to assign the Windows pods to the nodes if the Windows node pools
+// are not configured in workspace, which tolerates the common taint of the Windows nodes.
+var defaultWindowsScheduling = Scheduling{
+	Tolerations: []Toleration{
+		{
+			Key:      "os",
+			Operator: corev1.TolerationOpEqual,
+			Value:    OSWindows,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	},
+}
+
+// completeWindowsScheduling assigns the Windows pods to the Windows node pools configured in
+// workspace, whose node selectors and tolerations are set into the pods.
+func completeWindowsScheduling(base *Base, config kusionapiv1.GenericConfig) error {
+	if base.OS != OSWindows {
+		return nil
+	}
+	platform := defaultWindowsScheduling
+	if value, ok := config[FieldWindows]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		platform = Scheduling{}
+		if err = yaml.Unmarshal(out, &platform); err != nil {
+			return fmt.Errorf("invalid windows config in workspace, %w", err)
+		}
+	}
+
+	if base.Scheduling == nil {
+		base.Scheduling = &Scheduling{}
+	}
+	if base.Scheduling.NodeSelector == nil {
+		base.Scheduling.NodeSelector = make(map[string]string)
+	}
+	maps.Copy(base.Scheduling.NodeSelector, platform.NodeSelector)
+	base.Scheduling.NodeSelector[corev1.LabelOSStable] = OSWindows
+	base.Scheduling.Tolerations = appendTolerations(base.Scheduling.Tolerations, platform.Tolerations)
+	return nil
+}
+
+// appendTolerations appends the tolerations of the node pools absent from the existing ones.
+func appendTolerations(existing, tolerations []Toleration) []Toleration {
+	for _, t := range tolerations {
+		if !slices.ContainsFunc(existing, func(e Toleration) bool {
+			return e.Key == t.Key && e.Operator == t.Operator && e.Value == t.Value && e.Effect == t.Effect
+		}) {
+			existing = append(existing, t)
+		}
+	}
+	return existing
+}
+
+// completeRuntimeClass completes the runtime class of the workload with the one from workspace.
+// The sandboxed runtime class is enforced on the untrusted workloads, and the other workloads use
+// the default one if not declared, which must be one of the allowed runtime classes.
+func completeRuntimeClass(base *Base, config kusionapiv1.GenericConfig) error {
+	platform := &RuntimeClass{}
+	if value, ok := config[FieldRuntimeClass]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if err = yaml.Unmarshal(out, platform); err != nil {
+			return fmt.Errorf("invalid runtimeClass config in workspace, %w", err)
+		}
+	}
+
+	if base.Untrusted {
+		if platform.Untrusted != "" {
+			base.RuntimeClassName = platform.Untrusted
+			return nil
+		}
+		if base.RuntimeClassName == "" {
+			return ErrEmptySandboxRuntime
+		}
+	}
+	if base.RuntimeClassName == "" {
+		base.RuntimeClassName = platform.Default
+	}
+	if base.RuntimeClassName != "" && len(platform.Allowed) != 0 &&
+		!slices.Contains(platform.Allowed, base.RuntimeClassName) {
+		return fmt.Errorf("%w, got %s, allowed %v", ErrUnsupportedRuntimeClass, base.RuntimeClassName, platform.Allowed)
+	}
 	return nil
 }
 
+// validateOS validates the operating system of the workload. The job declares no security contexts,
+// so none of the Linux-only options can be set on the Windows pods.
+func validateOS(base *Base) error {
+	switch base.OS {
+	case "", OSLinux, OSWindows:
+		return nil
+	default:
+		return fmt.Errorf("%w, got %q", ErrUnsupportedOS, base.OS)
+	}
+}
+
+// handleScheduling sets the operating system, the runtime class, the node selectors and the
+// tolerations of the workload into the pod spec.
+func handleScheduling(base *Base, spec *corev1.PodSpec) {
+	if base.OS == OSWindows {
+		spec.OS = &corev1.PodOS{Name: corev1.Windows}
+	}
+	if base.RuntimeClassName != "" {
+		spec.RuntimeClassName = &base.RuntimeClassName
+	}
+	if base.Scheduling == nil {
+		return
+	}
+	spec.NodeSelector = base.Scheduling.NodeSelector
+	if len(base.Scheduling.Tolerations) == 0 {
+		return
+	}
+	spec.Tolerations = make([]corev1.Toleration, 0, len(base.Scheduling.Tolerations))
+	for _, t := range base.Scheduling.Tolerations {
+		spec.Tolerations = append(spec.Tolerations, corev1.Toleration{
+			Key:               t.Key,
+			Operator:          t.Operator,
+			Value:             t.Value,
+			Effect:            t.Effect,
+			TolerationSeconds: t.TolerationSeconds,
+		})
+	}
+}
+
 type secretReference struct {
 	Name string
 	Key  string
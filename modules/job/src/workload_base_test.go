@@ -0,0 +1,214 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestHandleResourceRequirementsV1(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources map[string]string
+		want      corev1.ResourceRequirements
+		wantErr   error
+	}{
+		{
+			name: "ephemeral storage, hugepages and extended resources",
+			resources: map[string]string{
+				"cpu":               "500m-1",
+				"memory":            "1Gi",
+				"ephemeral-storage": "1Gi-10Gi",
+				"hugepages-2Mi":     "100Mi-100Mi",
+				"nvidia.com/gpu":    "2",
+			},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:              resource.MustParse("500m"),
+					corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+					"hugepages-2Mi":                 resource.MustParse("100Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:              resource.MustParse("1"),
+					corev1.ResourceMemory:           resource.MustParse("1Gi"),
+					corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+					"hugepages-2Mi":                 resource.MustParse("100Mi"),
+					"nvidia.com/gpu":                resource.MustParse("2"),
+				},
+			},
+		},
+		{
+			name:      "unsupported resource",
+			resources: map[string]string{"gpu": "1"},
+			wantErr:   ErrUnsupportedResource,
+		},
+		{
+			name:      "invalid hugepages size",
+			resources: map[string]string{"memory": "1Gi", "hugepages-large": "1Gi"},
+			wantErr:   ErrUnsupportedResource,
+		},
+		{
+			name:      "kubernetes.io resource",
+			resources: map[string]string{"kubernetes.io/gpu": "1"},
+			wantErr:   ErrUnsupportedResource,
+		},
+		{
+			name:      "overcommitted extended resource",
+			resources: map[string]string{"amd.com/gpu": "1-2"},
+			wantErr:   ErrOvercommittedResource,
+		},
+		{
+			name:      "fractional extended resource",
+			resources: map[string]string{"nvidia.com/gpu": "500m"},
+			wantErr:   ErrFractionalResource,
+		},
+		{
+			name:      "hugepages only",
+			resources: map[string]string{"hugepages-1Gi": "2Gi"},
+			wantErr:   ErrHugePagesOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handleResourceRequirementsV1(tt.resources)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompleteWindowsScheduling(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   *Base
+		config kusionapiv1.GenericConfig
+		want   *Scheduling
+	}{
+		{
+			name:   "linux workload",
+			base:   &Base{},
+			config: kusionapiv1.GenericConfig{},
+			want:   nil,
+		},
+		{
+			name:   "default windows scheduling",
+			base:   &Base{OS: OSWindows},
+			config: kusionapiv1.GenericConfig{},
+			want: &Scheduling{
+				NodeSelector: map[string]string{corev1.LabelOSStable: OSWindows},
+				Tolerations:  defaultWindowsScheduling.Tolerations,
+			},
+		},
+		{
+			name: "windows node pools in workspace",
+			base: &Base{OS: OSWindows},
+			config: kusionapiv1.GenericConfig{
+				"windows": map[string]any{
+					"nodeSelector": map[string]any{"pool": "windows"},
+					"tolerations": []any{
+						map[string]any{
+							"key":      "pool",
+							"operator": "Equal",
+							"value":    "windows",
+							"effect":   "NoSchedule",
+						},
+					},
+				},
+			},
+			want: &Scheduling{
+				NodeSelector: map[string]string{corev1.LabelOSStable: OSWindows, "pool": "windows"},
+				Tolerations: []Toleration{
+					{Key: "pool", Operator: corev1.TolerationOpEqual, Value: "windows", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeWindowsScheduling(tt.base, tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.Scheduling)
+		})
+	}
+}
+
+func TestCompleteRuntimeClass(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"runtimeClass": map[string]any{
+			"default":   "runc",
+			"untrusted": "gvisor",
+			"allowed":   []any{"runc", "gvisor", "kata"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		base    *Base
+		config  kusionapiv1.GenericConfig
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "no runtime class",
+			base:   &Base{},
+			config: kusionapiv1.GenericConfig{},
+		},
+		{
+			name:   "default runtime class",
+			base:   &Base{},
+			config: platformConfig,
+			want:   "runc",
+		},
+		{
+			name:   "declared runtime class",
+			base:   &Base{RuntimeClassName: "kata"},
+			config: platformConfig,
+			want:   "kata",
+		},
+		{
+			name:    "runtime class not allowed",
+			base:    &Base{RuntimeClassName: "youki"},
+			config:  platformConfig,
+			wantErr: ErrUnsupportedRuntimeClass,
+		},
+		{
+			name:   "sandboxed runtime class enforced on untrusted workload",
+			base:   &Base{RuntimeClassName: "runc", Untrusted: true},
+			config: platformConfig,
+			want:   "gvisor",
+		},
+		{
+			name:   "untrusted workload with declared runtime class",
+			base:   &Base{RuntimeClassName: "kata", Untrusted: true},
+			config: kusionapiv1.GenericConfig{},
+			want:   "kata",
+		},
+		{
+			name:    "untrusted workload without runtime class",
+			base:    &Base{Untrusted: true},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrEmptySandboxRuntime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeRuntimeClass(tt.base, tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.RuntimeClassName)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveExtensions are the extensions of the archives of the manifests, which are extracted and
+// loaded as the directories.
+var ArchiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// maxArchiveSize is the maximum total size of the files extracted from an archive, which guards
+// against the decompression bombs.
+const maxArchiveSize = 64 << 20
+
+// ErrUnsafeArchive is returned when the archive has the entries escaping the extraction directory,
+// or exceeds the maximum extracted size.
+var ErrUnsafeArchive = errors.New("unsafe archive")
+
+// isArchive indicates the path is an archive of the manifests.
+func isArchive(path string) bool {
+	for _, ext := range ArchiveExtensions {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// extractArchive extracts the tar.gz or zip archive into a temporary directory and returns it,
+// which is removed by the caller after the manifests are loaded. Only the regular files and the
+// directories are extracted, and the entries escaping the directory, e.g. "../x" or "/x", are
+// refused.
+func extractArchive(path string) (dir string, err error) {
+	dir, err = os.MkdirTemp("", "k8s-manifest-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(dir)
+			dir = ""
+		}
+	}()
+
+	if strings.HasSuffix(strings.ToLower(path), ".zip") {
+		err = extractZip(path, dir)
+	} else {
+		err = extractTarGz(path, dir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error extracting %s: %w", path, err)
+	}
+	return dir, nil
+}
+
+// extractTarGz extracts the tar.gz archive into the directory.
+func extractTarGz(path, dir string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	var size int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := archiveEntryPath(dir, header.Name)
+		if err != nil {
+			return err
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if size += header.Size; size > maxArchiveSize {
+				return fmt.Errorf("%w, extracted size exceeds %d bytes", ErrUnsafeArchive, maxArchiveSize)
+			}
+			if err = writeArchiveFile(target, tr, header.Size); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// extractZip extracts the zip archive into the directory.
+func extractZip(path, dir string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	var size int64
+	for _, file := range zr.File {
+		target, err := archiveEntryPath(dir, file.Name)
+		if err != nil {
+			return err
+		}
+		if file.FileInfo().IsDir() {
+			if err = os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if size += int64(file.UncompressedSize64); size > maxArchiveSize {
+			return fmt.Errorf("%w, extracted size exceeds %d bytes", ErrUnsafeArchive, maxArchiveSize)
+		}
+
+		r, err := file.Open()
+		if err != nil {
+			return err
+		}
+		err = writeArchiveFile(target, r, int64(file.UncompressedSize64))
+		r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveEntryPath returns the path of the archive entry in the directory, refusing the entries
+// escaping it.
+func archiveEntryPath(dir, name string) (string, error) {
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") || strings.HasPrefix(name, `\`) {
+		return "", fmt.Errorf("%w, absolute entry %s", ErrUnsafeArchive, name)
+	}
+	target := filepath.Join(dir, name)
+	if rel, err := filepath.Rel(dir, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w, entry %s escapes the extraction directory", ErrUnsafeArchive, name)
+	}
+	return target, nil
+}
+
+// writeArchiveFile writes the content of the archive entry into the file, reading no more than
+// the declared size of the entry.
+func writeArchiveFile(target string, r io.Reader, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, io.LimitReader(r, size)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
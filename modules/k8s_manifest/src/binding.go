@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+// BindingWorkload is the binding to the workload of the App generated by the service or job module.
+const BindingWorkload = "workload"
+
+var (
+	ErrUnknownBinding  = errors.New("bindTo must be workload or the modules publishing outputs, i.e. postgres and mysql")
+	ErrUnboundWorkload = errors.New("bindTo workload requires the workload of the App")
+)
+
+// outputModules are the modules publishing their outputs in the Secret named after the default
+// database name of the App, which the manifests are bound to.
+var outputModules = []string{"postgres", "mysql"}
+
+// unboundKinds are the kinds of the manifests never bound, which the bound resources may depend on.
+var unboundKinds = []string{"Namespace", "CustomResourceDefinition"}
+
+// bindingIDs returns the IDs of the resources the manifests are bound to, i.e. the workload of
+// the App, or the Secret storing the outputs of the module.
+func bindingIDs(request *module.GeneratorRequest, bindTo []string) ([]string, error) {
+	ids := make([]string, 0, len(bindTo))
+	for _, binding := range bindTo {
+		var id string
+		switch {
+		case binding == BindingWorkload:
+			var err error
+			if id, err = workloadID(request); err != nil {
+				return nil, err
+			}
+		case slices.Contains(outputModules, binding):
+			name := strings.Join([]string{request.Project, request.Stack, request.App, binding}, "-") + "-" + binding
+			id = kubernetesResourceID("v1", "Secret", request.Project, name)
+		default:
+			return nil, fmt.Errorf("%w, got %s", ErrUnknownBinding, binding)
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// workloadID returns the ID of the workload generated by the service or job module, where the
+// type of the Service defaults to Deployment.
+func workloadID(request *module.GeneratorRequest) (string, error) {
+	if request.Workload == nil {
+		return "", ErrUnboundWorkload
+	}
+	typeMeta, _, err := moduleutil.WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return "", fmt.Errorf("%w, %w", ErrUnboundWorkload, err)
+	}
+	return kubernetesResourceID(typeMeta.APIVersion, typeMeta.Kind, request.Project, moduleutil.AppName(request)), nil
+}
+
+// kubernetesResourceID returns the ID of the Kubernetes resource.
+func kubernetesResourceID(apiVersion, kind, namespace, name string) string {
+	return module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: apiVersion, Kind: kind},
+		metav1.ObjectMeta{Namespace: namespace, Name: name},
+	)
+}
+
+// bindResources adds the resources the manifests are bound to into their dependencies, so that
+// they are applied after the resources of the App, e.g. the custom resources referencing the
+// workload. The Namespaces and CustomResourceDefinitions are never bound.
+func bindResources(request *module.GeneratorRequest, resources []kusionapiv1.Resource, bindTo []string) error {
+	if len(bindTo) == 0 {
+		return nil
+	}
+	ids, err := bindingIDs(request, bindTo)
+	if err != nil {
+		return err
+	}
+	for i := range resources {
+		if slices.Contains(unboundKinds, moduleutil.ResourceKind(resources[i])) {
+			continue
+		}
+		for _, id := range ids {
+			if !slices.Contains(resources[i].DependsOn, id) {
+				resources[i].DependsOn = append(resources[i].DependsOn, id)
+			}
+		}
+	}
+	return nil
+}
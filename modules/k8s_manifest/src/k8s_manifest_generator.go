@@ -2,13 +2,12 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"runtime/debug"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -17,11 +16,45 @@ import (
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
 )
 
 var FileExtensions = []string{".yaml", ".yml", ".json"}
 
+// ErrEmptyNamespaceInProd is returned when a namespaced manifest has no namespace in prod.
+var ErrEmptyNamespaceInProd = errors.New("empty namespace of the manifest in prod")
+
+// ErrMalformedManifest is returned when a manifest is not an object with the string apiVersion,
+// kind, metadata.name and metadata.namespace.
+var ErrMalformedManifest = errors.New("manifest must have string apiVersion, kind and metadata.name")
+
+// clusterScopedKinds are the kinds of the cluster-scoped resources, which have no namespaces.
+var clusterScopedKinds = []string{
+	"APIService",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"CSIDriver",
+	"CustomResourceDefinition",
+	"IngressClass",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"Node",
+	"PersistentVolume",
+	"PriorityClass",
+	"RuntimeClass",
+	"StorageClass",
+	"ValidatingWebhookConfiguration",
+	"VolumeSnapshotClass",
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Config{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&K8sManifest{
 		MergedPaths: make(map[string]bool),
 	})
@@ -30,10 +63,57 @@ func main() {
 // K8sManifest implements the Kusion Module generator interface.
 type K8sManifest struct {
 	// Paths is a list of the paths of the YAML files, or the directories of the
-	// raw Kubernetes manifests.
-	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+	// raw Kubernetes manifests, with their scoping options.
+	Paths []ManifestPath `yaml:"paths,omitempty" json:"paths,omitempty"`
 	// MergedPaths is a map of K8s manifest paths.
 	MergedPaths map[string]bool `yaml:"mergedPaths,omitempty" json:"mergedPaths,omitempty"`
+	// BindTo is the workload or the modules of the App the manifests depend on.
+	BindTo []string `yaml:"bindTo,omitempty" json:"bindTo,omitempty"`
+	// Strict validates the manifests of the known kinds with their typed objects.
+	Strict bool `yaml:"strict,omitempty" json:"strict,omitempty"`
+	// Prune labels the manifests with the prune labels.
+	Prune bool `yaml:"prune,omitempty" json:"prune,omitempty"`
+	// Render renders the Jsonnet and CUE sources in the paths.
+	Render *Render `yaml:"render,omitempty" json:"render,omitempty"`
+
+	// scopes are the scoping options of the merged paths.
+	scopes map[string]ManifestPath
+}
+
+// Config describes the dev config and platform config of the k8s_manifest module.
+type Config struct {
+	// Paths is a list of the paths of the YAML files, or the directories of the
+	// raw Kubernetes manifests, each written either as the path alone or as an object with the
+	// namespace and labels scoping the manifests loaded from it.
+	Paths []ManifestPath `yaml:"paths,omitempty" json:"paths,omitempty"`
+	// BindTo is the workload or the modules of the App the manifests depend on, i.e. workload,
+	// or the modules publishing outputs such as postgres and mysql.
+	BindTo []string `yaml:"bindTo,omitempty" json:"bindTo,omitempty"`
+	// Strict decodes the manifests of the known kinds, i.e. Deployment, Service, ConfigMap,
+	// Secret and Ingress, into their typed objects, and refuses the unknown fields, the
+	// mismatched value types and the structural errors such as the missing selectors. The other
+	// kinds, e.g. the custom resources, are passed through as they are.
+	Strict bool `yaml:"strict,omitempty" json:"strict,omitempty"`
+	// Prune labels the manifests with the App and the module owning them, and the content hash
+	// of the manifests of the release, so that the manifests removed from the sources between
+	// the releases are safely pruned by the labels.
+	Prune bool `yaml:"prune,omitempty" json:"prune,omitempty"`
+	// Render renders the .jsonnet and .cue files in the paths with the jsonnet and cue commands,
+	// and loads the manifests of their output. The sources are ignored unless configured.
+	Render *Render `yaml:"render,omitempty" json:"render,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the k8s_manifest module in workspace.
+type PlatformConfig struct {
+	Config `json:",inline"`
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
+	// Environment is the environment class of the workspace, dev, staging or prod, which the
+	// guardrails are keyed on.
+	Environment string `yaml:"environment,omitempty" json:"environment,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs of the manifests are checked
+	// against, i.e. privileged, baseline or restricted.
+	PodSecurity string `yaml:"podSecurity,omitempty" json:"podSecurity,omitempty"`
 }
 
 // Generate implements the generation logic of k8s_manifest module, which
@@ -43,93 +123,184 @@ func (k *K8sManifest) Generate(ctx context.Context, request *module.GeneratorReq
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "k8s_manifest", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate k8s_manifest module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in k8s_manifest module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			if err = moduleutil.Finalize("k8s_manifest", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
 	if err := k.CompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
 		logger.Debug("failed to get complete k8s_manifest module configs: %v", err)
-		return nil, err
+		return nil, moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseComplete, err)
 	}
 
 	// 1. Get all of the YAML files (.yaml and .yml) in paths.
 	// 2. Get all of the Kubernetes objects and append them into the Kusion Spec Resources.
 	manifestYAMLFiles := make(map[string][]interface{})
 	for path := range k.MergedPaths {
-		pathInfo, err := os.Stat(path)
+		// Load the manifests of the archives from the directories they are extracted to.
+		root := path
+		if isArchive(path) {
+			dir, err := extractArchive(path)
+			if err != nil {
+				return nil, err
+			}
+			defer os.RemoveAll(dir)
+			root = dir
+		}
+
+		pathInfo, err := os.Stat(root)
 		if err != nil {
 			return nil, err
 		}
 
 		if pathInfo.IsDir() {
-			if err = filepath.WalkDir(path, func(filePath string, d os.DirEntry, err error) error {
+			if err = filepath.WalkDir(root, func(filePath string, d os.DirEntry, err error) error {
 				if err != nil {
 					return err
 				}
 
+				if !d.IsDir() && k.Render.renders(filePath) {
+					return renderManifest(ctx, k.Render, filePath, k.scopes[path], manifestYAMLFiles)
+				}
+
 				if ignoreFile(filePath, FileExtensions) {
 					return nil
 				}
 
-				if err = appendManifest(filePath, manifestYAMLFiles); err != nil {
+				if err = appendManifest(filePath, k.scopes[path], manifestYAMLFiles); err != nil {
 					return err
 				}
 				return nil
 			}); err != nil {
 				return nil, err
 			}
+		} else if k.Render.renders(path) {
+			if err = renderManifest(ctx, k.Render, path, k.scopes[path], manifestYAMLFiles); err != nil {
+				return nil, err
+			}
 		} else {
-			if err = appendManifest(path, manifestYAMLFiles); err != nil {
+			if err = appendManifest(path, k.scopes[path], manifestYAMLFiles); err != nil {
 				return nil, err
 			}
 		}
 	}
 
 	resources := []kusionapiv1.Resource{}
-	for _, objList := range manifestYAMLFiles {
+	for filePath, objList := range manifestYAMLFiles {
 		for _, obj := range objList {
 			if obj == nil {
 				continue
 			}
 
-			apiVersion := obj.(map[string]interface{})["apiVersion"].(string)
-			kind := obj.(map[string]interface{})["kind"].(string)
-			metadata := obj.(map[string]interface{})["metadata"].(map[string]interface{})
-			name := metadata["name"].(string)
-
-			var namespace string
-			if ns, ok := metadata["namespace"]; ok {
-				namespace = ns.(string)
-			}
-
-			var kusionID string
-			if namespace == "" {
-				kusionID = apiVersion + ":" + kind + ":" + name
-			} else {
-				kusionID = apiVersion + ":" + kind + ":" + namespace + ":" + name
+			manifest, kusionID, err := manifestID(filePath, obj)
+			if err != nil {
+				return nil, moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, err)
 			}
 
+			normalizeManifest(manifest)
 			resources = append(resources, kusionapiv1.Resource{
 				ID:         kusionID,
 				Type:       kusionapiv1.Kubernetes,
-				Attributes: obj.(map[string]interface{}),
+				Attributes: manifest,
 			})
 		}
 	}
+	// Keep the order of the manifests stable across the runs.
+	sortResources(resources)
+
+	// Refuse the structural errors of the manifests of the known kinds in strict mode.
+	if k.Strict {
+		if err := validateTypedManifests(resources); err != nil {
+			return nil, moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, err)
+		}
+	}
+
+	// Make the manifests depend on the resources of the App they are bound to.
+	if err := bindResources(request, resources, k.BindTo); err != nil {
+		return nil, moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, err)
+	}
+
+	// Label the manifests with the prune labels.
+	if k.Prune {
+		if err := markPrunable(request, resources); err != nil {
+			return nil, err
+		}
+	}
+
+	// Refuse the manifests not allowed in the environment class of the workspace.
+	if err := checkGuardrails(request, resources); err != nil {
+		return nil, moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, err)
+	}
 
 	return &module.GeneratorResponse{
 		Resources: resources,
 	}, nil
 }
 
-// appendManifest appends manifest objects in K8s YAML file to a map.
-func appendManifest(filePath string, manifestYAMLFiles map[string][]interface{}) error {
+// checkGuardrails checks the manifests against the guardrails of the environment class of the
+// workspace, where the namespaced manifests in prod must have their namespaces.
+func checkGuardrails(request *module.GeneratorRequest, resources []kusionapiv1.Resource) error {
+	env, err := moduleutil.EnvironmentClass(request)
+	if err != nil {
+		return err
+	}
+	if env != moduleutil.EnvironmentProd {
+		return nil
+	}
+
+	for _, res := range resources {
+		kind := moduleutil.ResourceKind(res)
+		if slices.Contains(clusterScopedKinds, kind) {
+			continue
+		}
+		metadata, _ := res.Attributes["metadata"].(map[string]interface{})
+		if namespace, _ := metadata["namespace"].(string); namespace == "" {
+			return fmt.Errorf("%w: %s %s", ErrEmptyNamespaceInProd, kind, metadata["name"])
+		}
+	}
+	return nil
+}
+
+// appendManifest appends manifest objects in K8s YAML file to a map, scoped by the options of the
+// path the file is loaded from.
+// manifestID returns the manifest loaded from the file and its Kusion resource ID, i.e.
+// <apiVersion>:<kind>:[<namespace>:]<name>. It returns ErrMalformedManifest naming the file if the
+// manifest is not an object or its identifying fields are missing or not strings.
+func manifestID(filePath string, obj interface{}) (map[string]interface{}, string, error) {
+	manifest, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("%w, got %T in %s", ErrMalformedManifest, obj, filePath)
+	}
+	apiVersion, ok1 := manifest["apiVersion"].(string)
+	kind, ok2 := manifest["kind"].(string)
+	metadata, ok3 := manifest["metadata"].(map[string]interface{})
+	name, ok4 := metadata["name"].(string)
+	if !ok1 || !ok2 || !ok3 || !ok4 || apiVersion == "" || kind == "" || name == "" {
+		return nil, "", fmt.Errorf("%w, in %s", ErrMalformedManifest, filePath)
+	}
+
+	namespace := ""
+	if ns, ok := metadata["namespace"]; ok && ns != nil {
+		if namespace, ok = ns.(string); !ok {
+			return nil, "", fmt.Errorf("%w, got namespace %v of %s in %s", ErrMalformedManifest, ns, name, filePath)
+		}
+	}
+
+	if namespace == "" {
+		return manifest, apiVersion + ":" + kind + ":" + name, nil
+	}
+	return manifest, apiVersion + ":" + kind + ":" + namespace + ":" + name, nil
+}
+
+func appendManifest(filePath string, scope ManifestPath, manifestYAMLFiles map[string][]interface{}) error {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return err
@@ -150,6 +321,7 @@ func appendManifest(filePath string, manifestYAMLFiles map[string][]interface{})
 			continue
 		}
 
+		scope.scope(data)
 		manifestYAMLFiles[filePath] = append(manifestYAMLFiles[filePath], data)
 	}
 }
@@ -170,6 +342,14 @@ func ignoreFile(path string, extensions []string) bool {
 
 // CompleteConfig completes the k8s_manifest module configs with both devModuleConfig and platformModuleConfig.
 func (k *K8sManifest) CompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Config{}); err != nil {
+		return moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, fmt.Errorf("validate k8s_manifest dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, fmt.Errorf("validate k8s_manifest platform config failed, %w", err))
+	}
+
 	// Retrieve the config items the developers are concerned about.
 	if devConfig != nil {
 		devCfgYAMLStr, err := yaml.Marshal(devConfig)
@@ -181,8 +361,8 @@ func (k *K8sManifest) CompleteConfig(devConfig kusionapiv1.Accessory, platformCo
 			return err
 		}
 
-		for _, path := range k.Paths {
-			k.MergedPaths[path] = true
+		if err = k.mergePaths(k.Paths); err != nil {
+			return moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, err)
 		}
 	}
 
@@ -198,18 +378,46 @@ func (k *K8sManifest) CompleteConfig(devConfig kusionapiv1.Accessory, platformCo
 			return err
 		}
 
-		for _, path := range tmpK.Paths {
-			if k.MergedPaths[path] {
-				continue
+		if err = k.mergePaths(tmpK.Paths); err != nil {
+			return moduleutil.NewModuleError("k8s_manifest", moduleutil.PhaseValidate, err)
+		}
+		// The strict mode and the pruning enabled by the platform can't be turned off by the
+		// developers.
+		k.Strict = k.Strict || tmpK.Strict
+		k.Prune = k.Prune || tmpK.Prune
+		if k.Render == nil {
+			k.Render = tmpK.Render
+		}
+		for _, binding := range tmpK.BindTo {
+			if !slices.Contains(k.BindTo, binding) {
+				k.BindTo = append(k.BindTo, binding)
 			}
-
-			k.MergedPaths[path] = true
 		}
 	}
 
 	return nil
 }
 
+// mergePaths merges the paths into the merged paths, where the scoping options of the path merged
+// first, i.e. the one of the developers, take precedence.
+func (k *K8sManifest) mergePaths(paths []ManifestPath) error {
+	if k.scopes == nil {
+		k.scopes = make(map[string]ManifestPath)
+	}
+	for _, path := range paths {
+		if err := path.Validate(); err != nil {
+			return err
+		}
+		if k.MergedPaths[path.Path] {
+			continue
+		}
+
+		k.MergedPaths[path.Path] = true
+		k.scopes[path.Path] = path
+	}
+	return nil
+}
+
 // ValidateConfig validates the completed k8s_manifest module configs are valid or not.
 func (k *K8sManifest) ValidateConfig() error {
 	if len(k.MergedPaths) == 0 {
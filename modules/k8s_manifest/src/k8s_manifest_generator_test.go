@@ -1,3 +1,720 @@
 package main
 
-// TODO: add unit tests for k8s_manifest module.
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestK8sManifest_GenerateGolden(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+	}{
+		{
+			name: "dev-paths",
+			devConfig: kusionapiv1.Accessory{
+				"paths": []interface{}{"testdata/manifests/app.yaml"},
+			},
+		},
+		{
+			name: "platform-paths",
+			platformConfig: kusionapiv1.GenericConfig{
+				"paths": []interface{}{"testdata/manifests"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			testutil.AssertGolden(t, filepath.Join("testdata", tt.name+".golden.json"), response)
+		})
+	}
+}
+
+func TestK8sManifest_GenerateInvalid(t *testing.T) {
+	request := testutil.NewRequest().
+		WithDevConfig(kusionapiv1.Accessory{"path": "testdata/manifests"}).
+		Build()
+	_, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+	var moduleErr *moduleutil.ModuleError
+	if !errors.As(err, &moduleErr) || moduleErr.Phase != moduleutil.PhaseValidate || moduleErr.Path != "path" {
+		t.Errorf("Generate() error = %v, want the validate error of path", err)
+	}
+
+	request = testutil.NewRequest().
+		WithDevConfig(kusionapiv1.Accessory{"paths": []interface{}{"testdata/missing.yaml"}}).
+		Build()
+	_, err = (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Generate() error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	request = testutil.NewRequest().
+		WithDevConfig(kusionapiv1.Accessory{"paths": []interface{}{"testdata/malformed.yaml"}}).
+		Build()
+	_, err = (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+	if !errors.Is(err, ErrMalformedManifest) || !strings.Contains(err.Error(), "testdata/malformed.yaml") {
+		t.Errorf("Generate() error = %v, want %v naming the file", err, ErrMalformedManifest)
+	}
+}
+
+func TestK8sManifest_GenerateGuardrails(t *testing.T) {
+	tests := []struct {
+		name        string
+		paths       []interface{}
+		environment string
+		workspace   string
+		expectedErr error
+	}{
+		{
+			name:  "unnamespaced manifest in dev",
+			paths: []interface{}{"testdata/unnamespaced.yaml"},
+		},
+		{
+			name:        "unnamespaced manifest in prod",
+			paths:       []interface{}{"testdata/unnamespaced.yaml"},
+			environment: "prod",
+			expectedErr: ErrEmptyNamespaceInProd,
+		},
+		{
+			name:        "unnamespaced manifest in prod workspace",
+			paths:       []interface{}{"testdata/unnamespaced.yaml"},
+			workspace:   "prod-us-east",
+			expectedErr: ErrEmptyNamespaceInProd,
+		},
+		{
+			name:        "namespaced and cluster-scoped manifests in prod",
+			paths:       []interface{}{"testdata/manifests/app.yaml"},
+			environment: "prod",
+		},
+		{
+			name:        "invalid environment",
+			paths:       []interface{}{"testdata/manifests/app.yaml"},
+			environment: "qa",
+			expectedErr: moduleutil.ErrInvalidEnvironment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := testutil.NewRequest().WithDevConfig(kusionapiv1.Accessory{"paths": tt.paths})
+			if tt.environment != "" {
+				builder = builder.WithPlatformConfig(kusionapiv1.GenericConfig{moduleutil.EnvironmentKey: tt.environment})
+			}
+			if tt.workspace != "" {
+				builder = builder.WithContext(moduleutil.WorkspaceKey, tt.workspace)
+			}
+
+			_, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), builder.Build())
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Generate() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GeneratePodSecurity(t *testing.T) {
+	tests := []struct {
+		name        string
+		level       string
+		expectedErr error
+	}{
+		{
+			name:  "baseline",
+			level: moduleutil.PodSecurityBaseline,
+		},
+		{
+			name:        "restricted",
+			level:       moduleutil.PodSecurityRestricted,
+			expectedErr: moduleutil.ErrPodSecurityViolation,
+		},
+		{
+			name:        "invalid level",
+			level:       "strict",
+			expectedErr: moduleutil.ErrInvalidPodSecurity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithDevConfig(kusionapiv1.Accessory{"paths": []interface{}{"testdata/manifests/app.yaml"}}).
+				WithPlatformConfig(kusionapiv1.GenericConfig{moduleutil.PodSecurityKey: tt.level}).
+				Build()
+
+			_, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Generate() error = %v, want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GenerateBindTo(t *testing.T) {
+	tests := []struct {
+		name              string
+		workload          kusionapiv1.Accessory
+		bindTo            []interface{}
+		expectedDependsOn []string
+		expectedErr       error
+	}{
+		{
+			name:     "not bound",
+			workload: kusionapiv1.Accessory{"_type": "service.Service"},
+		},
+		{
+			name:              "service and database",
+			workload:          kusionapiv1.Accessory{"_type": "service.Service", "type": "CollaSet"},
+			bindTo:            []interface{}{"workload", "postgres"},
+			expectedDependsOn: []string{"apps.kusionstack.io/v1alpha1:CollaSet:default:default-dev-foo", "v1:Secret:default:default-dev-foo-postgres-postgres"},
+		},
+		{
+			name:              "knative service",
+			workload:          kusionapiv1.Accessory{"_type": "service.Service", "type": "KnativeService"},
+			bindTo:            []interface{}{"workload"},
+			expectedDependsOn: []string{"serving.knative.dev/v1:Service:default:default-dev-foo"},
+		},
+		{
+			name:              "cron job",
+			workload:          kusionapiv1.Accessory{"_type": "job.Job", "schedule": "0 * * * *"},
+			bindTo:            []interface{}{"workload"},
+			expectedDependsOn: []string{"batch/v1:CronJob:default:default-dev-foo"},
+		},
+		{
+			name:        "no workload",
+			bindTo:      []interface{}{"workload"},
+			expectedErr: ErrUnboundWorkload,
+		},
+		{
+			name:        "unknown binding",
+			workload:    kusionapiv1.Accessory{"_type": "service.Service"},
+			bindTo:      []interface{}{"network"},
+			expectedErr: ErrUnknownBinding,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devConfig := kusionapiv1.Accessory{"paths": []interface{}{"testdata/manifests/app.yaml"}}
+			if tt.bindTo != nil {
+				devConfig["bindTo"] = tt.bindTo
+			}
+			request := testutil.NewRequest().WithWorkload(tt.workload).WithDevConfig(devConfig).Build()
+
+			response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Generate() error = %v, want %v", err, tt.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+			for _, res := range response.Resources {
+				expected := tt.expectedDependsOn
+				if moduleutil.ResourceKind(res) == "Namespace" {
+					expected = nil
+				}
+				if !slices.Equal(res.DependsOn, expected) {
+					t.Errorf("DependsOn of %s = %v, want %v", res.ID, res.DependsOn, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GenerateStrict(t *testing.T) {
+	tests := []struct {
+		name             string
+		devConfig        kusionapiv1.Accessory
+		platformConfig   kusionapiv1.GenericConfig
+		expectedMessages []string
+	}{
+		{
+			name:      "valid manifests",
+			devConfig: kusionapiv1.Accessory{"paths": []interface{}{"testdata/manifests/app.yaml"}, "strict": true},
+		},
+		{
+			name:      "invalid manifests without strict",
+			devConfig: kusionapiv1.Accessory{"paths": []interface{}{"testdata/invalid.yaml"}},
+		},
+		{
+			name:           "invalid manifests with strict enforced by platform",
+			devConfig:      kusionapiv1.Accessory{"paths": []interface{}{"testdata/invalid.yaml"}},
+			platformConfig: kusionapiv1.GenericConfig{"strict": true},
+			expectedMessages: []string{
+				"apps/v1:Deployment:default:web: cannot unmarshal string into Go struct field Deployment.spec.replicas of type int32",
+				"apps/v1:Deployment:default:worker: spec.selector must match spec.template.metadata.labels",
+				"v1:Service:default:web: spec.ports must not be empty",
+				"v1:ConfigMap:default:web: invalid key app config of data",
+				"networking.k8s.io/v1:Ingress:default:web: spec.rules[0].http.paths[0].backend.service.port must set the number or name",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			_, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if len(tt.expectedMessages) == 0 {
+				if err != nil {
+					t.Fatalf("Generate() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidManifest) {
+				t.Fatalf("Generate() error = %v, want %v", err, ErrInvalidManifest)
+			}
+			for _, msg := range tt.expectedMessages {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("Generate() error = %v, want containing %q", err, msg)
+				}
+			}
+			if strings.Contains(err.Error(), "ServiceMonitor") {
+				t.Errorf("Generate() error = %v, want the custom resources passed through", err)
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GeneratePrune(t *testing.T) {
+	generate := func(paths ...interface{}) map[string]map[string]interface{} {
+		request := testutil.NewRequest().
+			WithDevConfig(kusionapiv1.Accessory{"paths": paths, "prune": true}).
+			Build()
+		response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		labels := make(map[string]map[string]interface{}, len(response.Resources))
+		for _, res := range response.Resources {
+			labels[res.ID] = res.Attributes["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+		}
+		return labels
+	}
+
+	release := generate("testdata/manifests/app.yaml")
+	deployment := release["apps/v1:Deployment:default:nginx"]
+	if deployment[PruneAppLabel] != "default-dev-foo" || deployment[PruneModuleLabel] != "k8s_manifest" {
+		t.Errorf("prune labels = %v, want the App and module", deployment)
+	}
+	hash, _ := deployment[PruneContentHashLabel].(string)
+	if len(hash) != contentHashLength || release["v1:Namespace:default"][PruneContentHashLabel] != hash {
+		t.Errorf("content hash = %v, want the same hash of the release", hash)
+	}
+	if deployment[moduleutil.LabelAppName] != "foo" {
+		t.Errorf("labels = %v, want the standard labels kept", deployment)
+	}
+
+	next := generate("testdata/manifests/app.yaml", "testdata/unnamespaced.yaml")
+	if next["apps/v1:Deployment:default:nginx"][PruneContentHashLabel] == hash {
+		t.Errorf("content hash = %v, want changed with the manifests", hash)
+	}
+	if again := generate("testdata/manifests/app.yaml"); again["apps/v1:Deployment:default:nginx"][PruneContentHashLabel] != hash {
+		t.Errorf("content hash = %v, want stable across the generations", again)
+	}
+}
+
+func TestK8sManifest_GenerateNormalized(t *testing.T) {
+	request := testutil.NewRequest().
+		WithDevConfig(kusionapiv1.Accessory{"paths": []interface{}{"testdata/unnormalized.yaml"}}).
+		Build()
+	response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	ids := make([]string, 0, len(response.Resources))
+	for _, res := range response.Resources {
+		ids = append(ids, res.ID)
+	}
+	if want := []string{
+		"apps/v1:Deployment:default:web",
+		"example.com/v1:Throttle:default:web",
+		"v1:PersistentVolumeClaim:default:data",
+		"v1:ResourceQuota:default:quota",
+		"v1:Service:default:web",
+	}; !slices.Equal(ids, want) {
+		t.Fatalf("resource IDs = %v, want sorted %v", ids, want)
+	}
+
+	deployment, err := json.Marshal(response.Resources[0].Attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"containerPort":8080,`,
+		`"limits":{"cpu":"500m","memory":"1Gi"}`,
+		`"requests":{"cpu":"1","memory":"500Mi"}`,
+	} {
+		if !strings.Contains(string(deployment), want) {
+			t.Errorf("Deployment = %s, want containing %s", deployment, want)
+		}
+	}
+	if strings.Contains(string(deployment), "creationTimestamp") || strings.Contains(string(deployment), "status") {
+		t.Errorf("Deployment = %s, want the null creationTimestamp and status stripped", deployment)
+	}
+
+	for i, want := range map[int]string{
+		1: `"spec":{"hard":{"rate":"1000m"},"limits":{"burst":0.5}}`,
+		2: `"resources":{"requests":{"storage":"10Gi"}}`,
+		3: `"hard":{"limits.memory":"2Gi","requests.cpu":"500m"}`,
+	} {
+		got, err := json.Marshal(response.Resources[i].Attributes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(got), want) {
+			t.Errorf("%s = %s, want containing %s", response.Resources[i].ID, got, want)
+		}
+	}
+
+	service, err := json.Marshal(response.Resources[4].Attributes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"ports":[{"name":"http","port":80,"targetPort":"http"}]`; !strings.Contains(string(service), want) {
+		t.Errorf("Service = %s, want containing %s", service, want)
+	}
+	if strings.Contains(string(service), "status") {
+		t.Errorf("Service = %s, want the empty status stripped", service)
+	}
+}
+
+func TestK8sManifest_GenerateScopedPaths(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedLabels map[string]map[string]interface{}
+		expectedErr    error
+	}{
+		{
+			name: "scoped and plain paths",
+			devConfig: kusionapiv1.Accessory{"paths": []interface{}{
+				map[string]interface{}{
+					"path":      "testdata/manifests/app.yaml",
+					"namespace": "infra",
+					"labels":    map[string]interface{}{"team": "platform", "app": "proxy"},
+				},
+				"testdata/unnamespaced.yaml",
+			}},
+			expectedLabels: map[string]map[string]interface{}{
+				"apps/v1:Deployment:infra:nginx": {"team": "platform", "app": "proxy"},
+				"v1:Namespace:default":           {"team": "platform", "app": "proxy"},
+				"v1:Service:nginx":               {},
+			},
+		},
+		{
+			name:      "dev scope takes precedence",
+			devConfig: kusionapiv1.Accessory{"paths": []interface{}{"testdata/manifests/app.yaml"}},
+			platformConfig: kusionapiv1.GenericConfig{"paths": []interface{}{
+				map[string]interface{}{"path": "testdata/manifests/app.yaml", "namespace": "infra"},
+			}},
+			expectedLabels: map[string]map[string]interface{}{
+				"apps/v1:Deployment:default:nginx": {},
+				"v1:Namespace:default":             {},
+			},
+		},
+		{
+			name: "invalid namespace",
+			devConfig: kusionapiv1.Accessory{"paths": []interface{}{
+				map[string]interface{}{"path": "testdata/manifests/app.yaml", "namespace": "Infra"},
+			}},
+			expectedErr: ErrInvalidManifestPath,
+		},
+		{
+			name: "unknown option",
+			devConfig: kusionapiv1.Accessory{"paths": []interface{}{
+				map[string]interface{}{"path": "testdata/manifests/app.yaml", "namespaces": "infra"},
+			}},
+			expectedErr: moduleutil.ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Generate() error = %v, want %v", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			resources := make(map[string]map[string]interface{}, len(response.Resources))
+			for _, res := range response.Resources {
+				resources[res.ID] = res.Attributes["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+			}
+			for id, labels := range tt.expectedLabels {
+				got, ok := resources[id]
+				if !ok {
+					t.Fatalf("resources = %v, want %s", resources, id)
+				}
+				for k, v := range labels {
+					if got[k] != v {
+						t.Errorf("labels of %s = %v, want %s=%v", id, got, k, v)
+					}
+				}
+				if len(labels) == 0 && got["team"] != nil {
+					t.Errorf("labels of %s = %v, want not scoped", id, got)
+				}
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GenerateArchives(t *testing.T) {
+	manifest, err := os.ReadFile("testdata/manifests/app.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTarGz := func(path string, files map[string][]byte) {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		gz := gzip.NewWriter(f)
+		tw := tar.NewWriter(gz)
+		for name, data := range files {
+			if err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = tw.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err = gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeZip := func(path string, files map[string][]byte) {
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		zw := zip.NewWriter(f)
+		for name, data := range files {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err = zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir := t.TempDir()
+	bundle := map[string][]byte{"release/app.yaml": manifest, "release/README.md": []byte("# release")}
+	tests := []struct {
+		name        string
+		path        string
+		write       func(string, map[string][]byte)
+		files       map[string][]byte
+		expectedIDs []string
+		expectedErr error
+	}{
+		{
+			name:        "tar.gz",
+			path:        filepath.Join(dir, "release.tar.gz"),
+			write:       writeTarGz,
+			files:       bundle,
+			expectedIDs: []string{"apps/v1:Deployment:default:nginx", "v1:Namespace:default"},
+		},
+		{
+			name:        "zip",
+			path:        filepath.Join(dir, "release.zip"),
+			write:       writeZip,
+			files:       bundle,
+			expectedIDs: []string{"apps/v1:Deployment:default:nginx", "v1:Namespace:default"},
+		},
+		{
+			name:        "path traversal",
+			path:        filepath.Join(dir, "evil.tgz"),
+			write:       writeTarGz,
+			files:       map[string][]byte{"../../evil.yaml": manifest},
+			expectedErr: ErrUnsafeArchive,
+		},
+		{
+			name:        "absolute path",
+			path:        filepath.Join(dir, "evil.zip"),
+			write:       writeZip,
+			files:       map[string][]byte{"/tmp/evil.yaml": manifest},
+			expectedErr: ErrUnsafeArchive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.write(tt.path, tt.files)
+			request := testutil.NewRequest().
+				WithDevConfig(kusionapiv1.Accessory{"paths": []interface{}{tt.path}}).
+				Build()
+
+			response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("Generate() error = %v, want %v", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			ids := make([]string, 0, len(response.Resources))
+			for _, res := range response.Resources {
+				ids = append(ids, res.ID)
+			}
+			if !slices.Equal(ids, tt.expectedIDs) {
+				t.Errorf("resource IDs = %v, want %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GenerateRender(t *testing.T) {
+	// The fake jsonnet and cue commands print the manifests carrying their arguments.
+	bin := t.TempDir()
+	commands := map[string]string{
+		"jsonnet": `printf '{"configMap":{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"jsonnet","namespace":"default"},"data":{"args":"%s"}}}' "$*"`,
+		"cue":     `printf '{"apiVersion":"v1","kind":"List","items":[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cue","namespace":"default"},"data":{"args":"%s"}}]}' "$*"`,
+	}
+	for name, script := range commands {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tests := []struct {
+		name         string
+		devConfig    kusionapiv1.Accessory
+		expectedArgs map[string]string
+		expectedErr  error
+	}{
+		{
+			name:         "not configured",
+			devConfig:    kusionapiv1.Accessory{"paths": []interface{}{"testdata/render"}},
+			expectedArgs: map[string]string{},
+		},
+		{
+			name: "jsonnet and cue",
+			devConfig: kusionapiv1.Accessory{
+				"paths": []interface{}{"testdata/render"},
+				"render": map[string]interface{}{
+					"jsonnet": map[string]interface{}{
+						"importPaths":  []interface{}{"vendor"},
+						"topLevelArgs": map[string]interface{}{"env": "dev"},
+					},
+					"cue": map[string]interface{}{
+						"tags":       map[string]interface{}{"env": "dev"},
+						"expression": "manifests",
+					},
+				},
+			},
+			expectedArgs: map[string]string{
+				"v1:ConfigMap:default:jsonnet": "-J vendor --tla-str env=dev testdata/render/app.jsonnet",
+				"v1:ConfigMap:default:cue":     "export --out json -t env=dev -e manifests testdata/render/app.cue",
+			},
+		},
+		{
+			name: "render failed",
+			devConfig: kusionapiv1.Accessory{
+				"paths":  []interface{}{"testdata/render/app.cue"},
+				"render": map[string]interface{}{"cue": map[string]interface{}{"expression": "missing"}},
+			},
+			expectedErr: ErrRenderFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.expectedErr != nil {
+				script := "#!/bin/sh\necho 'reference \"missing\" not found' >&2\nexit 1\n"
+				if err := os.WriteFile(filepath.Join(bin, "cue"), []byte(script), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			request := testutil.NewRequest().WithDevConfig(tt.devConfig).Build()
+
+			response, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) || !strings.Contains(err.Error(), `reference "missing" not found`) {
+					t.Fatalf("Generate() error = %v, want %v with the stderr", err, tt.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			args := make(map[string]string, len(response.Resources))
+			for _, res := range response.Resources {
+				data, _ := res.Attributes["data"].(map[string]interface{})
+				args[res.ID], _ = data["args"].(string)
+			}
+			if len(args) != len(tt.expectedArgs) {
+				t.Errorf("resources = %v, want %v", args, tt.expectedArgs)
+			}
+			for id, want := range tt.expectedArgs {
+				if args[id] != want {
+					t.Errorf("args of %s = %q, want %q", id, args[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestK8sManifest_GenerateRenderCommandNotFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	request := testutil.NewRequest().WithDevConfig(kusionapiv1.Accessory{
+		"paths":  []interface{}{"testdata/render/app.jsonnet"},
+		"render": map[string]interface{}{"jsonnet": map[string]interface{}{}},
+	}).Build()
+
+	_, err := (&K8sManifest{MergedPaths: map[string]bool{}}).Generate(context.Background(), request)
+	if !errors.Is(err, ErrRenderCommandNotFound) || !strings.Contains(err.Error(), "jsonnet is required") {
+		t.Fatalf("Generate() error = %v, want %v", err, ErrRenderCommandNotFound)
+	}
+}
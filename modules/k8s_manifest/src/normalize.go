@@ -0,0 +1,149 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+// containerFields are the fields of the pod specs holding the lists of the containers, whose
+// resources.limits and resources.requests are the maps of the resource quantities.
+var containerFields = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// portFields are the fields holding the port numbers.
+var portFields = map[string]bool{
+	"port":          true,
+	"containerPort": true,
+	"targetPort":    true,
+	"hostPort":      true,
+	"nodePort":      true,
+}
+
+// normalizeManifest normalizes the manifest in place, so that the same manifests written in
+// different formats generate the same resources. It strips the null creationTimestamps, e.g. the
+// ones of the pod templates written by kubectl, and the empty status, and changes the port
+// numbers written in strings or floats to the integers. The resource quantities, e.g. "0.5" to
+// "500m", are canonicalized only in the resources of the containers, the spec.hard of the
+// ResourceQuotas and the spec.resources of the PersistentVolumeClaims, so that the fields of the
+// custom resources sharing the names are left as they are. The keys of the maps are sorted when
+// serialized.
+func normalizeManifest(obj map[string]interface{}) {
+	if status, ok := obj["status"]; ok {
+		if m, isMap := status.(map[string]interface{}); status == nil || (isMap && len(m) == 0) {
+			delete(obj, "status")
+		}
+	}
+	normalizeValue(obj)
+
+	if obj["apiVersion"] != "v1" {
+		return
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	switch obj["kind"] {
+	case "ResourceQuota":
+		normalizeQuantities(spec["hard"])
+	case "PersistentVolumeClaim":
+		normalizeResources(spec["resources"])
+	}
+}
+
+// normalizeValue normalizes the creationTimestamps, the ports and the resources of the containers
+// in the nested maps and lists.
+func normalizeValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			switch {
+			case key == "creationTimestamp" && item == nil:
+				delete(v, key)
+				continue
+			case containerFields[key]:
+				if containers, ok := item.([]interface{}); ok {
+					for _, c := range containers {
+						if container, ok := c.(map[string]interface{}); ok {
+							normalizeResources(container["resources"])
+						}
+					}
+				}
+			case portFields[key]:
+				v[key] = normalizePort(item)
+				continue
+			}
+			normalizeValue(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			normalizeValue(item)
+		}
+	}
+}
+
+// normalizeResources canonicalizes the quantities of the limits and requests of the resource
+// requirements.
+func normalizeResources(value interface{}) {
+	if resources, ok := value.(map[string]interface{}); ok {
+		normalizeQuantities(resources["limits"])
+		normalizeQuantities(resources["requests"])
+	}
+}
+
+// normalizeQuantities canonicalizes the quantities of the map keyed by the resource names.
+func normalizeQuantities(value interface{}) {
+	if quantities, ok := value.(map[string]interface{}); ok {
+		for name, q := range quantities {
+			quantities[name] = normalizeQuantity(q)
+		}
+	}
+}
+
+// normalizeQuantity returns the canonical form of the quantity, or the value as it is if it is not
+// a quantity.
+func normalizeQuantity(value interface{}) interface{} {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case int:
+		s = strconv.Itoa(v)
+	default:
+		return value
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return value
+	}
+	return q.String()
+}
+
+// normalizePort returns the integer of the port number written in a string or float, or the value
+// as it is if it is a port name.
+func normalizePort(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if port, err := strconv.ParseInt(v, 10, 32); err == nil {
+			return port
+		}
+	case float64:
+		if v == math.Trunc(v) {
+			return int64(v)
+		}
+	}
+	return value
+}
+
+// sortResources sorts the resources by their IDs, so that the order of them is stable regardless
+// of the order the manifests are loaded.
+func sortResources(resources []kusionapiv1.Resource) {
+	sort.SliceStable(resources, func(i, j int) bool { return resources[i].ID < resources[j].ID })
+}
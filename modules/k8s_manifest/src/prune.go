@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+// The prune labels of the manifests, which select the manifests owned by the App and the module.
+// The manifests of the App and the module whose content hash differs from the one of the current
+// release are removed from the sources, and safe to be pruned, e.g. by
+//
+//	kubectl delete all -l 'prune.kusionstack.io/app=<app>,prune.kusionstack.io/module=k8s_manifest,prune.kusionstack.io/content-hash!=<hash>'
+const (
+	PruneAppLabel         = "prune.kusionstack.io/app"
+	PruneModuleLabel      = "prune.kusionstack.io/module"
+	PruneContentHashLabel = "prune.kusionstack.io/content-hash"
+)
+
+// contentHashLength is the length of the content hash, which is truncated to fit the label value.
+const contentHashLength = 32
+
+// markPrunable labels the manifests with the App and the module owning them, and the content hash
+// of all the manifests of the release, which overrides the prune labels in the manifests.
+func markPrunable(request *module.GeneratorRequest, resources []kusionapiv1.Resource) error {
+	hash, err := contentHash(resources)
+	if err != nil {
+		return err
+	}
+	labels := map[string]string{
+		PruneAppLabel:         moduleutil.AppName(request),
+		PruneModuleLabel:      "k8s_manifest",
+		PruneContentHashLabel: hash,
+	}
+	for i := range resources {
+		metadata, ok := resources[i].Attributes["metadata"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		merged := moduleutil.MergeMetadata(metadata["labels"], nil)
+		for k, v := range labels {
+			merged[k] = v
+		}
+		metadata["labels"] = merged
+	}
+	return nil
+}
+
+// contentHash returns the hash of the manifests in the order of their IDs, which changes once any
+// of the manifests is changed, added or removed.
+func contentHash(resources []kusionapiv1.Resource) (string, error) {
+	sorted := make([]kusionapiv1.Resource, len(resources))
+	copy(sorted, resources)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	h := sha256.New()
+	for _, res := range sorted {
+		data, err := json.Marshal(res.Attributes)
+		if err != nil {
+			return "", err
+		}
+		h.Write([]byte(res.ID))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:contentHashLength], nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	k8sYAML "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var (
+	// ErrRenderFailed is returned when the Jsonnet or CUE sources fail to be rendered.
+	ErrRenderFailed = errors.New("render failed")
+	// ErrRenderCommandNotFound is returned when the jsonnet or cue command is not found in PATH.
+	ErrRenderCommandNotFound = errors.New("render command not found in PATH")
+)
+
+// The extensions of the sources rendered into the manifests.
+const (
+	JsonnetExtension = ".jsonnet"
+	CUEExtension     = ".cue"
+)
+
+// Render configures the rendering of the Jsonnet and CUE sources found in the paths, which are
+// evaluated by the jsonnet and cue commands in PATH. The commands are not bundled with the module,
+// so they must be installed where kusion runs the module. The sources are ignored unless their
+// rendering is configured.
+type Render struct {
+	// Jsonnet renders the .jsonnet files.
+	Jsonnet *JsonnetRender `yaml:"jsonnet,omitempty" json:"jsonnet,omitempty"`
+	// CUE renders the .cue files.
+	CUE *CUERender `yaml:"cue,omitempty" json:"cue,omitempty"`
+}
+
+// JsonnetRender configures the evaluation of the Jsonnet sources.
+type JsonnetRender struct {
+	// ImportPaths are the library search directories, i.e. the -J flags of jsonnet.
+	ImportPaths []string `yaml:"importPaths,omitempty" json:"importPaths,omitempty"`
+	// TopLevelArgs are the string top-level arguments, i.e. the --tla-str flags of jsonnet.
+	TopLevelArgs map[string]string `yaml:"topLevelArgs,omitempty" json:"topLevelArgs,omitempty"`
+}
+
+// CUERender configures the evaluation of the CUE sources.
+type CUERender struct {
+	// Tags are the values injected into the fields with the @tag attributes, i.e. the -t flags of
+	// cue export.
+	Tags map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Expression is the expression of the manifests to export, i.e. the -e flag of cue export,
+	// which exports the whole source if empty.
+	Expression string `yaml:"expression,omitempty" json:"expression,omitempty"`
+}
+
+// renders indicates the file is a source rendered by the configured rendering.
+func (r *Render) renders(path string) bool {
+	if r == nil {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case JsonnetExtension:
+		return r.Jsonnet != nil
+	case CUEExtension:
+		return r.CUE != nil
+	}
+	return false
+}
+
+// command returns the command evaluating the source into JSON.
+func (r *Render) command(ctx context.Context, path string) *exec.Cmd {
+	if strings.EqualFold(filepath.Ext(path), JsonnetExtension) {
+		var args []string
+		for _, dir := range r.Jsonnet.ImportPaths {
+			args = append(args, "-J", dir)
+		}
+		for _, k := range sortedKeys(r.Jsonnet.TopLevelArgs) {
+			args = append(args, "--tla-str", k+"="+r.Jsonnet.TopLevelArgs[k])
+		}
+		return exec.CommandContext(ctx, "jsonnet", append(args, path)...)
+	}
+
+	args := []string{"export", "--out", "json"}
+	for _, k := range sortedKeys(r.CUE.Tags) {
+		args = append(args, "-t", k+"="+r.CUE.Tags[k])
+	}
+	if r.CUE.Expression != "" {
+		args = append(args, "-e", r.CUE.Expression)
+	}
+	return exec.CommandContext(ctx, "cue", append(args, path)...)
+}
+
+// renderManifest renders the Jsonnet or CUE source and appends the manifests of the output to a
+// map, scoped by the options of the path the source is loaded from. The output is either a
+// manifest, a list of manifests, a List, or an object of the manifests keyed by their names.
+func renderManifest(ctx context.Context, r *Render, filePath string, scope ManifestPath, manifestYAMLFiles map[string][]interface{}) error {
+	cmd := r.command(ctx, filePath)
+	if _, err := exec.LookPath(cmd.Args[0]); err != nil {
+		return fmt.Errorf("%w, %s is required to render %s, install it where kusion runs", ErrRenderCommandNotFound, cmd.Args[0], filePath)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w, %s %s: %v: %s", ErrRenderFailed, filepath.Base(cmd.Path), filePath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	decoder := k8sYAML.NewYAMLOrJSONDecoder(&stdout, 4096)
+	for {
+		var output interface{}
+		if err := decoder.Decode(&output); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("%w, error parsing the output of %s: %v", ErrRenderFailed, filePath, err)
+		}
+		for _, data := range flattenManifests(output) {
+			scope.scope(data)
+			manifestYAMLFiles[filePath] = append(manifestYAMLFiles[filePath], data)
+		}
+	}
+}
+
+// flattenManifests returns the manifests in the rendered output.
+func flattenManifests(output interface{}) []map[string]interface{} {
+	var manifests []map[string]interface{}
+	switch o := output.(type) {
+	case []interface{}:
+		for _, item := range o {
+			manifests = append(manifests, flattenManifests(item)...)
+		}
+	case map[string]interface{}:
+		if _, ok := o["kind"].(string); ok {
+			if items, ok := o["items"].([]interface{}); ok && strings.HasSuffix(o["kind"].(string), "List") {
+				return flattenManifests(items)
+			}
+			return []map[string]interface{}{o}
+		}
+		for _, k := range sortedKeys(o) {
+			manifests = append(manifests, flattenManifests(o[k])...)
+		}
+	}
+	return manifests
+}
+
+// sortedKeys returns the keys of the map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"moduleutil"
+)
+
+// ErrInvalidManifestPath is returned when the path of the manifests or its scoping options are
+// invalid.
+var ErrInvalidManifestPath = errors.New("invalid manifest path")
+
+// ManifestPath is the path of the YAML files, or the directory of the raw Kubernetes manifests,
+// along with the scoping rules of the manifests loaded from it. It's written either as the path
+// alone, or as an object with the scoping options, e.g.
+//
+//	paths:
+//	  - ./app
+//	  - path: ./infra
+//	    namespace: infra
+//	    labels:
+//	      team: platform
+type ManifestPath struct {
+	// Path is the path of the YAML files, or the directory of the raw Kubernetes manifests.
+	Path string `yaml:"path" json:"path"`
+	// Namespace overrides the namespaces of the namespaced manifests loaded from the path.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	// Labels are merged into the labels of the manifests loaded from the path, overriding the
+	// ones with the same keys.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// UnmarshalYAML decodes the manifest path written either as the path alone or as an object.
+func (p *ManifestPath) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*p = ManifestPath{Path: path}
+		return nil
+	}
+	type plain ManifestPath
+	return unmarshal((*plain)(p))
+}
+
+// JSONSchema returns the JSON Schema of the manifest path, which is either a string or an object.
+func (ManifestPath) JSONSchema() map[string]interface{} {
+	type plain ManifestPath
+	schema := moduleutil.JSONSchema(plain{})
+	schema["type"] = []string{"string", "object"}
+	return schema
+}
+
+// Validate returns ErrInvalidManifestPath if the path is empty, or the namespace or labels are
+// not valid.
+func (p ManifestPath) Validate() error {
+	if p.Path == "" {
+		return fmt.Errorf("%w, path must not be empty", ErrInvalidManifestPath)
+	}
+	if p.Namespace != "" {
+		if errs := validation.IsDNS1123Label(p.Namespace); len(errs) != 0 {
+			return fmt.Errorf("%w, namespace of %s: %s", ErrInvalidManifestPath, p.Path, strings.Join(errs, "; "))
+		}
+	}
+	for k, v := range p.Labels {
+		errs := append(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)...)
+		if len(errs) != 0 {
+			return fmt.Errorf("%w, label %s of %s: %s", ErrInvalidManifestPath, k, p.Path, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
+
+// scope applies the scoping rules of the path to the manifest, where the cluster-scoped manifests
+// keep no namespaces.
+func (p ManifestPath) scope(obj map[string]interface{}) {
+	if p.Namespace == "" && len(p.Labels) == 0 {
+		return
+	}
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	kind, _ := obj["kind"].(string)
+	if p.Namespace != "" && !slices.Contains(clusterScopedKinds, kind) {
+		metadata["namespace"] = p.Namespace
+	}
+	if len(p.Labels) != 0 {
+		labels := moduleutil.MergeMetadata(metadata["labels"], nil)
+		for k, v := range p.Labels {
+			labels[k] = v
+		}
+		metadata["labels"] = labels
+	}
+}
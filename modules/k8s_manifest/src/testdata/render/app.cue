@@ -0,0 +1,10 @@
+package app
+
+env: string @tag(env)
+
+manifests: [{
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: {name: "cue", namespace: "default"}
+	data: {"env": env}
+}]
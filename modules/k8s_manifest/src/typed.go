@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+)
+
+// ErrInvalidManifest is returned when the manifests of the known kinds have structural errors.
+var ErrInvalidManifest = errors.New("invalid manifest")
+
+// typedKinds are the known kinds decoded into the typed objects in strict mode, keyed by the
+// apiVersion and kind, and validated by the checks of their structural errors. The manifests of
+// the other kinds, e.g. the custom resources, are passed through as they are.
+var typedKinds = map[string]func() typedObject{
+	"apps/v1/Deployment":           func() typedObject { return &appsv1.Deployment{} },
+	"v1/Service":                   func() typedObject { return &corev1.Service{} },
+	"v1/ConfigMap":                 func() typedObject { return &corev1.ConfigMap{} },
+	"v1/Secret":                    func() typedObject { return &corev1.Secret{} },
+	"networking.k8s.io/v1/Ingress": func() typedObject { return &networkingv1.Ingress{} },
+}
+
+// typedObject is the typed object of a known kind.
+type typedObject interface {
+	metav1.Object
+}
+
+// validateTypedManifests decodes the manifests of the known kinds into the typed objects, which
+// refuses the unknown fields and the mismatched value types, and checks their structural errors,
+// e.g. the Deployments without selectors. It returns ErrInvalidManifest carrying all the errors.
+func validateTypedManifests(resources []kusionapiv1.Resource) error {
+	var messages []string
+	for _, res := range resources {
+		apiVersion, _ := res.Attributes["apiVersion"].(string)
+		newObject, ok := typedKinds[apiVersion+"/"+moduleutil.ResourceKind(res)]
+		if !ok {
+			continue
+		}
+		obj := newObject()
+		if err := decodeStrict(res.Attributes, obj); err != nil {
+			messages = append(messages, fmt.Sprintf("%s: %v", res.ID, err))
+			continue
+		}
+		for _, msg := range validateTypedObject(obj) {
+			messages = append(messages, fmt.Sprintf("%s: %s", res.ID, msg))
+		}
+	}
+	if len(messages) != 0 {
+		sort.Strings(messages)
+		return fmt.Errorf("%w, %s", ErrInvalidManifest, strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+// decodeStrict decodes the attributes into the typed object, refusing the unknown fields.
+func decodeStrict(attributes map[string]interface{}, obj typedObject) error {
+	data, err := json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(obj); err != nil {
+		return errors.New(strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+// validateTypedObject returns the structural errors of the typed object.
+func validateTypedObject(obj typedObject) []string {
+	var messages []string
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		selector := o.Spec.Selector
+		if selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0) {
+			messages = append(messages, "spec.selector must not be empty")
+		} else if s, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			messages = append(messages, fmt.Sprintf("invalid spec.selector: %v", err))
+		} else if !s.Matches(labels.Set(o.Spec.Template.Labels)) {
+			messages = append(messages, "spec.selector must match spec.template.metadata.labels")
+		}
+		if len(o.Spec.Template.Spec.Containers) == 0 {
+			messages = append(messages, "spec.template.spec.containers must not be empty")
+		}
+		for i, c := range o.Spec.Template.Spec.Containers {
+			if c.Name == "" || c.Image == "" {
+				messages = append(messages, fmt.Sprintf("spec.template.spec.containers[%d] must have the name and image", i))
+			}
+		}
+	case *corev1.Service:
+		if o.Spec.Type == corev1.ServiceTypeExternalName {
+			if o.Spec.ExternalName == "" {
+				messages = append(messages, "spec.externalName must not be empty for the ExternalName Service")
+			}
+			break
+		}
+		if len(o.Spec.Ports) == 0 && o.Spec.ClusterIP != corev1.ClusterIPNone {
+			messages = append(messages, "spec.ports must not be empty")
+		}
+		for i, port := range o.Spec.Ports {
+			if port.Port < 1 || port.Port > 65535 {
+				messages = append(messages, fmt.Sprintf("spec.ports[%d].port must be between 1 and 65535", i))
+			}
+		}
+	case *corev1.ConfigMap:
+		for key := range o.Data {
+			messages = append(messages, validateDataKey("data", key)...)
+			if _, ok := o.BinaryData[key]; ok {
+				messages = append(messages, fmt.Sprintf("key %s must not be in both data and binaryData", key))
+			}
+		}
+		for key := range o.BinaryData {
+			messages = append(messages, validateDataKey("binaryData", key)...)
+		}
+	case *corev1.Secret:
+		for key := range o.Data {
+			messages = append(messages, validateDataKey("data", key)...)
+		}
+		for key := range o.StringData {
+			messages = append(messages, validateDataKey("stringData", key)...)
+		}
+	case *networkingv1.Ingress:
+		if len(o.Spec.Rules) == 0 && o.Spec.DefaultBackend == nil {
+			messages = append(messages, "spec.rules or spec.defaultBackend must be set")
+		}
+		if o.Spec.DefaultBackend != nil {
+			messages = append(messages, validateIngressBackend("spec.defaultBackend", o.Spec.DefaultBackend)...)
+		}
+		for i, rule := range o.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for j, path := range rule.HTTP.Paths {
+				field := fmt.Sprintf("spec.rules[%d].http.paths[%d].backend", i, j)
+				messages = append(messages, validateIngressBackend(field, &path.Backend)...)
+			}
+		}
+	}
+	sort.Strings(messages)
+	return messages
+}
+
+// validateDataKey returns the errors of the key of the ConfigMap or Secret data.
+func validateDataKey(field, key string) []string {
+	if errs := validation.IsConfigMapKey(key); len(errs) != 0 {
+		return []string{fmt.Sprintf("invalid key %s of %s: %s", key, field, strings.Join(errs, "; "))}
+	}
+	return nil
+}
+
+// validateIngressBackend returns the errors of the Ingress backend, which must be either a
+// Service with its port or a resource.
+func validateIngressBackend(field string, backend *networkingv1.IngressBackend) []string {
+	switch {
+	case backend.Service != nil && backend.Resource != nil:
+		return []string{field + " must not set both service and resource"}
+	case backend.Service != nil:
+		if backend.Service.Name == "" {
+			return []string{field + ".service.name must not be empty"}
+		}
+		if backend.Service.Port.Number == 0 && backend.Service.Port.Name == "" {
+			return []string{field + ".service.port must set the number or name"}
+		}
+	case backend.Resource == nil:
+		return []string{field + " must set the service or resource"}
+	}
+	return nil
+}
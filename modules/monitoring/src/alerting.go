@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	// TeamLabel is the label of the alerts and the metrics identifying the team owning the workload.
+	TeamLabel = "team"
+
+	alertmanagerConfigAPIVersion = "monitoring.coreos.com/v1alpha1"
+	alertmanagerConfigKind       = "AlertmanagerConfig"
+	// blackholeReceiver is the receiver of the root route without any integration, the alerts are
+	// notified by the child route of each receiver instead.
+	blackholeReceiver = "blackhole"
+)
+
+// timeOfDay matches the time of the day in the format of HH:MM accepted by Alertmanager.
+var timeOfDay = regexp.MustCompile(`^(([01]\d|2[0-3]):[0-5]\d|24:00)$`)
+
+// complete validates the alerting and sets the defaults, where the receivers default to the ones
+// of the team in workspace.
+func (a *Alerting) complete(routing *AlertRouting) error {
+	if a.Team == "" {
+		return ErrEmptyAlertingTeam
+	}
+	if len(a.Receivers) == 0 && routing != nil {
+		a.Receivers = append([]Receiver(nil), routing.Teams[a.Team]...)
+	}
+	if len(a.Receivers) == 0 {
+		return fmt.Errorf("%w, got team %s", ErrEmptyAlertReceivers, a.Team)
+	}
+
+	names := map[string]bool{blackholeReceiver: true}
+	for _, r := range a.Receivers {
+		if r.Name == "" || names[r.Name] || (r.PagerDuty == nil && r.Slack == nil) {
+			return fmt.Errorf("%w, got %q", ErrInvalidAlertReceiver, r.Name)
+		}
+		if (r.PagerDuty != nil && !r.PagerDuty.RoutingKey.valid()) || (r.Slack != nil && !r.Slack.APIURL.valid()) {
+			return fmt.Errorf("%w, the secret of %q must have the name and key", ErrInvalidAlertReceiver, r.Name)
+		}
+		names[r.Name] = true
+	}
+
+	names = map[string]bool{}
+	for _, m := range a.MuteTimings {
+		if m.Name == "" || names[m.Name] || (len(m.Weekdays) == 0 && len(m.Times) == 0) {
+			return fmt.Errorf("%w, got %q", ErrInvalidMuteTiming, m.Name)
+		}
+		for _, t := range m.Times {
+			if !timeOfDay.MatchString(t.StartTime) || !timeOfDay.MatchString(t.EndTime) {
+				return fmt.Errorf("%w, the times of %q must be in the format of HH:MM", ErrInvalidMuteTiming, m.Name)
+			}
+		}
+		names[m.Name] = true
+	}
+
+	if len(a.GroupBy) == 0 {
+		a.GroupBy = []string{"alertname"}
+	}
+	return nil
+}
+
+func (s SecretKey) valid() bool {
+	return s.Name != "" && s.Key != ""
+}
+
+// routingLabels returns the labels attached to the alerts of the workload, which are matched by
+// the route of the AlertmanagerConfig.
+func (a *Alerting) routingLabels(app string) map[string]string {
+	return map[string]string{
+		TeamLabel:                   a.Team,
+		"kusion_monitoring_appname": app,
+	}
+}
+
+// buildAlertingResources creates the AlertmanagerConfig routing the alerts of the workload to the
+// receivers, which are only supported by the Prometheus operator. The alerts are matched by the
+// routing labels, and the operator restricts the route to the alerts of the namespace.
+func (g *MonitoringModule) buildAlertingResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	if g.Alerting == nil {
+		return nil, nil
+	}
+	if !g.OperatorMode {
+		return nil, ErrAlertingWithoutOperator
+	}
+
+	labels := g.Alerting.routingLabels(request.App)
+	var matchers []interface{}
+	for _, name := range []string{"kusion_monitoring_appname", TeamLabel} {
+		matchers = append(matchers, map[string]interface{}{"name": name, "value": labels[name], "matchType": "="})
+	}
+
+	var muteTimeIntervals []string
+	var muteTimings []interface{}
+	for _, m := range g.Alerting.MuteTimings {
+		muteTimeIntervals = append(muteTimeIntervals, m.Name)
+		times := make([]interface{}, 0, len(m.Times))
+		for _, t := range m.Times {
+			times = append(times, map[string]interface{}{"startTime": t.StartTime, "endTime": t.EndTime})
+		}
+		muteTimings = append(muteTimings, map[string]interface{}{
+			"name": m.Name,
+			"timeIntervals": []interface{}{
+				map[string]interface{}{"weekdays": m.Weekdays, "times": times},
+			},
+		})
+	}
+
+	// Each receiver is notified by a child route matching its severities, which continues to the
+	// next one so that an alert may reach both the pager and the chat channel.
+	receivers := []interface{}{map[string]interface{}{"name": blackholeReceiver}}
+	var routes []interface{}
+	for _, r := range g.Alerting.Receivers {
+		receiver := map[string]interface{}{"name": r.Name}
+		if r.PagerDuty != nil {
+			receiver["pagerdutyConfigs"] = []interface{}{
+				map[string]interface{}{"routingKey": r.PagerDuty.RoutingKey.toSecretKeySelector()},
+			}
+		}
+		if r.Slack != nil {
+			slack := map[string]interface{}{"apiURL": r.Slack.APIURL.toSecretKeySelector(), "sendResolved": true}
+			if r.Slack.Channel != "" {
+				slack["channel"] = r.Slack.Channel
+			}
+			receiver["slackConfigs"] = []interface{}{slack}
+		}
+		receivers = append(receivers, receiver)
+
+		route := map[string]interface{}{"receiver": r.Name, "continue": true}
+		if len(r.Severities) != 0 {
+			route["matchers"] = []interface{}{map[string]interface{}{
+				"name":      "severity",
+				"value":     strings.Join(r.Severities, "|"),
+				"matchType": "=~",
+			}}
+		}
+		if len(muteTimeIntervals) != 0 {
+			// The mute time intervals are not inherited by the child routes.
+			route["muteTimeIntervals"] = muteTimeIntervals
+		}
+		routes = append(routes, route)
+	}
+
+	route := map[string]interface{}{
+		"receiver": blackholeReceiver,
+		"groupBy":  g.Alerting.GroupBy,
+		"matchers": matchers,
+		"routes":   routes,
+	}
+	if g.Alerting.RepeatInterval != "" {
+		route["repeatInterval"] = g.Alerting.RepeatInterval
+	}
+	spec := map[string]interface{}{
+		"route":     route,
+		"receivers": receivers,
+	}
+	if len(muteTimings) != 0 {
+		spec["muteTimeIntervals"] = muteTimings
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      fmt.Sprintf("%s-alerting", moduleutil.AppName(request)),
+		Namespace: request.Project,
+		Labels:    map[string]string{"kusion_monitoring_appname": request.App},
+	}
+	if g.AlertRouting != nil {
+		for k, v := range g.AlertRouting.Labels {
+			objectMeta.Labels[k] = v
+		}
+	}
+	// Round trip the object through JSON, so that it only holds the JSON values accepted by the
+	// unstructured object.
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": alertmanagerConfigAPIVersion,
+		"kind":       alertmanagerConfigKind,
+		"metadata":   map[string]interface{}{"name": objectMeta.Name, "namespace": objectMeta.Namespace, "labels": objectMeta.Labels},
+		"spec":       spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err = json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, err
+	}
+
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: alertmanagerConfigAPIVersion, Kind: alertmanagerConfigKind}, objectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, obj)
+	if err != nil {
+		return nil, err
+	}
+	return []kusionapiv1.Resource{*resource}, nil
+}
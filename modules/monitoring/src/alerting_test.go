@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestAlerting_Complete(t *testing.T) {
+	pagerDuty := Receiver{
+		Name:      "payments-pager",
+		PagerDuty: &PagerDutyReceiver{RoutingKey: SecretKey{Name: "pagerduty", Key: "routingKey"}},
+	}
+	routing := &AlertRouting{Teams: map[string][]Receiver{"payments": {pagerDuty}}}
+
+	tests := []struct {
+		name     string
+		alerting *Alerting
+		wantErr  error
+	}{
+		{
+			name:     "empty team",
+			alerting: &Alerting{Receivers: []Receiver{pagerDuty}},
+			wantErr:  ErrEmptyAlertingTeam,
+		},
+		{
+			name:     "team without receivers",
+			alerting: &Alerting{Team: "checkout"},
+			wantErr:  ErrEmptyAlertReceivers,
+		},
+		{
+			name:     "receiver without integration",
+			alerting: &Alerting{Team: "payments", Receivers: []Receiver{{Name: "payments-slack"}}},
+			wantErr:  ErrInvalidAlertReceiver,
+		},
+		{
+			name:     "duplicate receivers",
+			alerting: &Alerting{Team: "payments", Receivers: []Receiver{pagerDuty, pagerDuty}},
+			wantErr:  ErrInvalidAlertReceiver,
+		},
+		{
+			name: "receiver without secret key",
+			alerting: &Alerting{Team: "payments", Receivers: []Receiver{
+				{Name: "payments-slack", Slack: &SlackReceiver{APIURL: SecretKey{Name: "slack"}}},
+			}},
+			wantErr: ErrInvalidAlertReceiver,
+		},
+		{
+			name: "invalid mute timing",
+			alerting: &Alerting{Team: "payments", MuteTimings: []MuteTiming{
+				{Name: "nightly", Times: []TimeRange{{StartTime: "1:00", EndTime: "03:00"}}},
+			}},
+			wantErr: ErrInvalidMuteTiming,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.alerting.complete(routing), tt.wantErr)
+		})
+	}
+
+	alerting := &Alerting{Team: "payments"}
+	require.NoError(t, alerting.complete(routing))
+	require.Equal(t, []Receiver{pagerDuty}, alerting.Receivers)
+	require.Equal(t, []string{"alertname"}, alerting.GroupBy)
+}
+
+func TestMonitoringGenerator_Alerting(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		PlatformConfig: kusionapiv1.GenericConfig{
+			OperatorModeKey: true,
+			MonitorTypeKey:  "Pod",
+			AlertingKey: map[string]interface{}{
+				"labels": map[string]interface{}{"alertmanagerConfig": "kusion"},
+				"teams": map[string]interface{}{
+					"payments": []interface{}{
+						map[string]interface{}{
+							"name":       "payments-pager",
+							"severities": []interface{}{"page"},
+							"pagerduty": map[string]interface{}{
+								"routingKey": map[string]interface{}{"name": "pagerduty", "key": "routingKey"},
+							},
+						},
+						map[string]interface{}{
+							"name": "payments-slack",
+							"slack": map[string]interface{}{
+								"apiURL":  map[string]interface{}{"name": "slack", "key": "webhook"},
+								"channel": "#payments-alerts",
+							},
+						},
+					},
+				},
+			},
+		},
+		DevConfig: kusionapiv1.Accessory{
+			PathKey: "/metrics",
+			PortKey: "web",
+			SLOKey: map[string]interface{}{
+				"availability": 99.9,
+				"windows": []interface{}{
+					map[string]interface{}{"longWindow": "1h", "shortWindow": "5m", "burnRate": 14.4},
+				},
+			},
+			AlertingKey: map[string]interface{}{
+				"team": "payments",
+				"muteTimings": []interface{}{
+					map[string]interface{}{
+						"name":     "weekly-maintenance",
+						"weekdays": []interface{}{"sunday"},
+						"times":    []interface{}{map[string]interface{}{"startTime": "02:00", "endTime": "04:00"}},
+					},
+				},
+			},
+		},
+	}
+
+	g := &MonitoringModule{}
+	response, err := g.Generate(context.TODO(), request)
+	require.NoError(t, err)
+	require.Len(t, response.Resources, 3)
+	require.Equal(t, map[string]string{"kusion_monitoring_appname": "test-app", TeamLabel: "payments"}, response.Patcher.Labels)
+	require.Equal(t, []interface{}{"kusion_monitoring_appname", TeamLabel},
+		response.Resources[0].Attributes["spec"].(map[string]interface{})["podTargetLabels"])
+
+	// The SLO alerts are attached with the routing labels.
+	groups := response.Resources[1].Attributes["spec"].(map[string]interface{})["groups"].([]interface{})
+	alert := groups[1].(map[string]interface{})["rules"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "payments", alert["labels"].(map[string]interface{})[TeamLabel])
+	require.Equal(t, "test-app", alert["labels"].(map[string]interface{})["kusion_monitoring_appname"])
+
+	config := response.Resources[2]
+	require.Equal(t, "monitoring.coreos.com/v1alpha1:AlertmanagerConfig:test-project:test-project-test-stack-test-app-alerting", config.ID)
+	require.Equal(t, "kusion", config.Attributes["metadata"].(map[string]interface{})["labels"].(map[string]interface{})["alertmanagerConfig"])
+	require.Equal(t, map[string]interface{}{
+		"route": map[string]interface{}{
+			"receiver": "blackhole",
+			"groupBy":  []interface{}{"alertname"},
+			"matchers": []interface{}{
+				map[string]interface{}{"name": "kusion_monitoring_appname", "value": "test-app", "matchType": "="},
+				map[string]interface{}{"name": TeamLabel, "value": "payments", "matchType": "="},
+			},
+			"routes": []interface{}{
+				map[string]interface{}{
+					"receiver":          "payments-pager",
+					"continue":          true,
+					"matchers":          []interface{}{map[string]interface{}{"name": "severity", "value": "page", "matchType": "=~"}},
+					"muteTimeIntervals": []interface{}{"weekly-maintenance"},
+				},
+				map[string]interface{}{
+					"receiver":          "payments-slack",
+					"continue":          true,
+					"muteTimeIntervals": []interface{}{"weekly-maintenance"},
+				},
+			},
+		},
+		"receivers": []interface{}{
+			map[string]interface{}{"name": "blackhole"},
+			map[string]interface{}{
+				"name": "payments-pager",
+				"pagerdutyConfigs": []interface{}{
+					map[string]interface{}{"routingKey": map[string]interface{}{"name": "pagerduty", "key": "routingKey"}},
+				},
+			},
+			map[string]interface{}{
+				"name": "payments-slack",
+				"slackConfigs": []interface{}{
+					map[string]interface{}{
+						"apiURL":       map[string]interface{}{"name": "slack", "key": "webhook"},
+						"channel":      "#payments-alerts",
+						"sendResolved": true,
+					},
+				},
+			},
+		},
+		"muteTimeIntervals": []interface{}{
+			map[string]interface{}{
+				"name": "weekly-maintenance",
+				"timeIntervals": []interface{}{
+					map[string]interface{}{
+						"weekdays": []interface{}{"sunday"},
+						"times":    []interface{}{map[string]interface{}{"startTime": "02:00", "endTime": "04:00"}},
+					},
+				},
+			},
+		},
+	}, config.Attributes["spec"])
+
+	// The AlertmanagerConfig is only supported by the Prometheus operator.
+	request.PlatformConfig[OperatorModeKey] = false
+	_, err = g.Generate(context.TODO(), request)
+	require.ErrorIs(t, err, ErrAlertingWithoutOperator)
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"runtime/debug"
+	"os"
 	"time"
 
 	prometheusv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
@@ -15,6 +15,7 @@ import (
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
 )
 
 func (g *MonitoringModule) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
@@ -22,13 +23,16 @@ func (g *MonitoringModule) Generate(ctx context.Context, request *module.Generat
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "monitoring", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate Monitoring module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in monitoring module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			if err = moduleutil.Finalize("monitoring", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -42,8 +46,25 @@ func (g *MonitoringModule) Generate(ctx context.Context, request *module.Generat
 	log.Debug("request.PlatformModuleConfig: ", request.PlatformConfig)
 	// Parse workspace configurations for monitoring generator.
 	if err := g.parseWorkspaceConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("monitoring", moduleutil.PhaseComplete, err)
+	}
+
+	// Create the blackbox probes of the workload.
+	probeResources, err := g.buildProbeResources(request)
+	if err != nil {
+		return nil, err
+	}
+	// Create the recording and alerting rules of the service level objectives.
+	sloResources, err := g.buildSLOResources(request)
+	if err != nil {
 		return nil, err
 	}
+	// Create the routing of the alerts to the receivers of the team.
+	alertingResources, err := g.buildAlertingResources(request)
+	if err != nil {
+		return nil, err
+	}
+	extraResources := append(append(probeResources, sloResources...), alertingResources...)
 
 	// If operator mode is enabled, create monitor objects.
 	if g != nil && g.OperatorMode {
@@ -60,12 +81,10 @@ func (g *MonitoringModule) Generate(ctx context.Context, request *module.Generat
 				return nil, err
 			}
 			patcher := &kusionapiv1.Patcher{
-				Labels: map[string]string{
-					"kusion_monitoring_appname": request.App,
-				},
+				Labels: g.workloadLabels(request),
 			}
 			return &module.GeneratorResponse{
-				Resources: []kusionapiv1.Resource{*resource},
+				Resources: append([]kusionapiv1.Resource{*resource}, extraResources...),
 				Patcher:   patcher,
 			}, nil
 		} else if g.MonitorType == PodMonitorType {
@@ -80,12 +99,10 @@ func (g *MonitoringModule) Generate(ctx context.Context, request *module.Generat
 				return nil, err
 			}
 			patcher := &kusionapiv1.Patcher{
-				Labels: map[string]string{
-					"kusion_monitoring_appname": request.App,
-				},
+				Labels: g.workloadLabels(request),
 			}
 			return &module.GeneratorResponse{
-				Resources: []kusionapiv1.Resource{*resource},
+				Resources: append([]kusionapiv1.Resource{*resource}, extraResources...),
 				Patcher:   patcher,
 			}, nil
 		} else {
@@ -94,28 +111,55 @@ func (g *MonitoringModule) Generate(ctx context.Context, request *module.Generat
 	} else {
 		// Operator mode is disabled. Patching workload annotations
 		log.Info("Operator mode is disabled. Patching workload annotations...")
+		// The annotations are only able to describe a single endpoint
+		if len(g.Endpoints) > 1 {
+			return nil, ErrMultipleEndpoints
+		}
 		// Patch workload annotations
 		annotations := map[string]string{
 			"prometheus.io/scrape": "true",
-			"prometheus.io/path":   g.Path,
-			"prometheus.io/port":   g.Port,
-			"prometheus.io/scheme": g.Scheme,
+			"prometheus.io/path":   g.Endpoints[0].Path,
+			"prometheus.io/port":   g.Endpoints[0].Port,
+			"prometheus.io/scheme": g.Endpoints[0].Scheme,
 		}
 		patchers := &kusionapiv1.Patcher{
 			Annotations: annotations,
 		}
+		// The SLO rules select the metrics of the workload by the monitoring label, which is
+		// expected to be mapped from the pod labels by the scrape config.
+		if g.SLO != nil {
+			patchers.Labels = map[string]string{
+				"kusion_monitoring_appname": request.App,
+			}
+		}
 		return &module.GeneratorResponse{
-			Patcher: patchers,
+			Resources: extraResources,
+			Patcher:   patchers,
 		}, nil
 	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, DevConfig{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&MonitoringModule{})
 }
 
 // parseWorkspaceConfig parses the config items for monitoring generator in workspace configurations.
 func (g *MonitoringModule) parseWorkspaceConfig(devConfig kusionapiv1.Accessory, workspaceConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, DevConfig{}); err != nil {
+		return moduleutil.NewModuleError("monitoring", moduleutil.PhaseValidate, fmt.Errorf("validate monitoring dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(workspaceConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("monitoring", moduleutil.PhaseValidate, fmt.Errorf("validate monitoring platform config failed, %w", err))
+	}
+
 	// get path and port from devConfig
 	if path, ok := devConfig[PathKey]; ok {
 		g.Path = path.(string)
@@ -123,6 +167,52 @@ func (g *MonitoringModule) parseWorkspaceConfig(devConfig kusionapiv1.Accessory,
 	if port, ok := devConfig[PortKey]; ok {
 		g.Port = port.(string)
 	}
+	if honorLabels, ok := devConfig[HonorLabelsKey]; ok {
+		g.HonorLabels = honorLabels.(bool)
+	}
+	g.Endpoints = nil
+	g.Blackbox = nil
+	g.SLO = nil
+	g.Alerting = nil
+	if alerting, ok := devConfig[AlertingKey]; ok && alerting != nil {
+		out, err := json.Marshal(alerting)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &g.Alerting); err != nil {
+			return fmt.Errorf("invalid alerting config: %w", err)
+		}
+	}
+	if slo, ok := devConfig[SLOKey]; ok && slo != nil {
+		out, err := json.Marshal(slo)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &g.SLO); err != nil {
+			return fmt.Errorf("invalid slo config: %w", err)
+		}
+		if err = g.SLO.complete(); err != nil {
+			return err
+		}
+	}
+	if blackbox, ok := devConfig[BlackboxKey]; ok && blackbox != nil {
+		out, err := json.Marshal(blackbox)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &g.Blackbox); err != nil {
+			return fmt.Errorf("invalid blackbox config: %w", err)
+		}
+	}
+	if endpoints, ok := devConfig[EndpointsKey]; ok {
+		out, err := json.Marshal(endpoints)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &g.Endpoints); err != nil {
+			return fmt.Errorf("invalid monitoring endpoints: %w", err)
+		}
+	}
 
 	// get operatorMode, monitorType, interval, timeout, scheme from workspaceConfig
 	if operatorMode, ok := workspaceConfig[OperatorModeKey]; ok {
@@ -147,6 +237,33 @@ func (g *MonitoringModule) parseWorkspaceConfig(devConfig kusionapiv1.Accessory,
 		g.Timeout = DefaultTimeout
 	}
 
+	g.Prober = nil
+	if prober, ok := workspaceConfig[ProberKey]; ok && prober != nil {
+		out, err := json.Marshal(prober)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &g.Prober); err != nil {
+			return fmt.Errorf("invalid prober config: %w", err)
+		}
+	}
+
+	g.AlertRouting = nil
+	if routing, ok := workspaceConfig[AlertingKey]; ok && routing != nil {
+		out, err := json.Marshal(routing)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &g.AlertRouting); err != nil {
+			return fmt.Errorf("invalid alerting config in workspace: %w", err)
+		}
+	}
+	if g.Alerting != nil {
+		if err := g.Alerting.complete(g.AlertRouting); err != nil {
+			return err
+		}
+	}
+
 	if scheme, ok := workspaceConfig[SchemeKey]; ok {
 		g.Scheme = scheme.(string)
 	} else {
@@ -168,6 +285,48 @@ func (g *MonitoringModule) parseWorkspaceConfig(devConfig kusionapiv1.Accessory,
 		return ErrTimeoutGreaterThanInterval
 	}
 
+	return g.completeEndpoints()
+}
+
+// completeEndpoints completes the scrape endpoints with the default values, and uses the path and
+// port as the only endpoint if no endpoints are declared.
+func (g *MonitoringModule) completeEndpoints() error {
+	if len(g.Endpoints) == 0 {
+		g.Endpoints = []Endpoint{{Path: g.Path, Port: g.Port}}
+	}
+
+	for i := range g.Endpoints {
+		endpoint := &g.Endpoints[i]
+		if endpoint.Port == "" {
+			return ErrEmptyEndpointPort
+		}
+		if endpoint.Path == "" {
+			endpoint.Path = g.Path
+		}
+		if endpoint.Scheme == "" {
+			endpoint.Scheme = g.Scheme
+		}
+		if endpoint.Interval == "" {
+			endpoint.Interval = g.Interval
+		}
+		if endpoint.Timeout == "" {
+			endpoint.Timeout = g.Timeout
+		}
+		endpoint.HonorLabels = endpoint.HonorLabels || g.HonorLabels
+
+		parsedTimeout, err := time.ParseDuration(string(endpoint.Timeout))
+		if err != nil {
+			return err
+		}
+		parsedInterval, err := time.ParseDuration(string(endpoint.Interval))
+		if err != nil {
+			return err
+		}
+		if parsedTimeout > parsedInterval {
+			return fmt.Errorf("invalid monitoring endpoint on port %s: %w", endpoint.Port, ErrTimeoutGreaterThanInterval)
+		}
+	}
+
 	return nil
 }
 
@@ -190,24 +349,31 @@ func (g *MonitoringModule) buildMonitorObject(request *module.GeneratorRequest,
 	// Create ServiceMonitor or PodMonitor based on the monitorType
 	if monitorType == ServiceMonitorType {
 		// Create ServiceMonitor
-		serviceEndpoint := prometheusv1.Endpoint{
-			Interval:      g.Interval,
-			ScrapeTimeout: g.Timeout,
-			Port:          g.Port,
-			Path:          g.Path,
-			Scheme:        g.Scheme,
-			BearerTokenSecret: &corev1.SecretKeySelector{
-				Key: "",
-			},
+		serviceEndpointList := make([]prometheusv1.Endpoint, 0, len(g.Endpoints))
+		for _, endpoint := range g.Endpoints {
+			serviceEndpoint := prometheusv1.Endpoint{
+				Interval:      endpoint.Interval,
+				ScrapeTimeout: endpoint.Timeout,
+				Port:          endpoint.Port,
+				Path:          endpoint.Path,
+				Scheme:        endpoint.Scheme,
+				HonorLabels:   endpoint.HonorLabels,
+				BearerTokenSecret: &corev1.SecretKeySelector{
+					Key: "",
+				},
+			}
+			if tlsConfig := endpoint.TLSConfig.toSafeTLSConfig(); tlsConfig != nil {
+				serviceEndpoint.TLSConfig = &prometheusv1.TLSConfig{SafeTLSConfig: *tlsConfig}
+			}
+			serviceEndpointList = append(serviceEndpointList, serviceEndpoint)
 		}
-		serviceEndpointList := []prometheusv1.Endpoint{serviceEndpoint}
 		serviceMonitor := &prometheusv1.ServiceMonitor{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       "ServiceMonitor",
 				APIVersion: prometheusv1.SchemeGroupVersion.String(),
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-service-monitor", module.UniqueAppName(request.Project, request.Stack, request.App)),
+				Name:      moduleutil.ResourceName(request, "service-monitor", moduleutil.KubernetesNamingRule),
 				Namespace: request.Project,
 			},
 			Spec: prometheusv1.ServiceMonitorSpec{
@@ -217,17 +383,27 @@ func (g *MonitoringModule) buildMonitorObject(request *module.GeneratorRequest,
 				Endpoints: serviceEndpointList,
 			},
 		}
+		// Attach the monitoring label to the metrics, which is used by the SLO rules to select the
+		// metrics of the workload, along with the team label routing the alerts on them.
+		serviceMonitor.Spec.TargetLabels = g.targetLabels()
 		return serviceMonitor, nil
 	} else if monitorType == PodMonitorType {
 		// Create PodMonitor
-		podMetricsEndpoint := prometheusv1.PodMetricsEndpoint{
-			Interval:      g.Interval,
-			ScrapeTimeout: g.Timeout,
-			Port:          g.Port,
-			Path:          g.Path,
-			Scheme:        g.Scheme,
+		podMetricsEndpointList := make([]prometheusv1.PodMetricsEndpoint, 0, len(g.Endpoints))
+		for _, endpoint := range g.Endpoints {
+			podMetricsEndpoint := prometheusv1.PodMetricsEndpoint{
+				Interval:      endpoint.Interval,
+				ScrapeTimeout: endpoint.Timeout,
+				Port:          endpoint.Port,
+				Path:          endpoint.Path,
+				Scheme:        endpoint.Scheme,
+				HonorLabels:   endpoint.HonorLabels,
+			}
+			if tlsConfig := endpoint.TLSConfig.toSafeTLSConfig(); tlsConfig != nil {
+				podMetricsEndpoint.TLSConfig = &prometheusv1.PodMetricsEndpointTLSConfig{SafeTLSConfig: *tlsConfig}
+			}
+			podMetricsEndpointList = append(podMetricsEndpointList, podMetricsEndpoint)
 		}
-		podMetricsEndpointList := []prometheusv1.PodMetricsEndpoint{podMetricsEndpoint}
 
 		podMonitor := &prometheusv1.PodMonitor{
 			TypeMeta: metav1.TypeMeta{
@@ -235,7 +411,7 @@ func (g *MonitoringModule) buildMonitorObject(request *module.GeneratorRequest,
 				APIVersion: prometheusv1.SchemeGroupVersion.String(),
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-pod-monitor", module.UniqueAppName(request.Project, request.Stack, request.App)),
+				Name:      moduleutil.ResourceName(request, "pod-monitor", moduleutil.KubernetesNamingRule),
 				Namespace: request.Project,
 			},
 			Spec: prometheusv1.PodMonitorSpec{
@@ -245,8 +421,193 @@ func (g *MonitoringModule) buildMonitorObject(request *module.GeneratorRequest,
 				PodMetricsEndpoints: podMetricsEndpointList,
 			},
 		}
+		podMonitor.Spec.PodTargetLabels = g.targetLabels()
 		return podMonitor, nil
 	}
 
 	return nil, fmt.Errorf("MonitorType should either be service or pod %s", monitorType)
 }
+
+// workloadLabels returns the labels patched to the workload, which are selected by the monitor
+// objects and attached to the metrics.
+func (g *MonitoringModule) workloadLabels(request *module.GeneratorRequest) map[string]string {
+	labels := map[string]string{
+		"kusion_monitoring_appname": request.App,
+	}
+	if g.Alerting != nil {
+		labels[TeamLabel] = g.Alerting.Team
+	}
+	return labels
+}
+
+// targetLabels returns the labels of the workload attached to its metrics by the monitor objects.
+func (g *MonitoringModule) targetLabels() []string {
+	var labels []string
+	if g.SLO != nil || g.Alerting != nil {
+		labels = append(labels, "kusion_monitoring_appname")
+	}
+	if g.Alerting != nil {
+		labels = append(labels, TeamLabel)
+	}
+	return labels
+}
+
+// toSafeTLSConfig converts the TLS config of the endpoint into the one of Prometheus operator.
+func (c *TLSConfig) toSafeTLSConfig() *prometheusv1.SafeTLSConfig {
+	if c == nil {
+		return nil
+	}
+
+	tlsConfig := &prometheusv1.SafeTLSConfig{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		KeySecret:          c.KeySecret.toSecretKeySelector(),
+	}
+	if c.CASecret != nil {
+		tlsConfig.CA.Secret = c.CASecret.toSecretKeySelector()
+	}
+	if c.CertSecret != nil {
+		tlsConfig.Cert.Secret = c.CertSecret.toSecretKeySelector()
+	}
+	return tlsConfig
+}
+
+func (s *SecretKey) toSecretKeySelector() *corev1.SecretKeySelector {
+	if s == nil {
+		return nil
+	}
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: s.Name},
+		Key:                  s.Key,
+	}
+}
+
+// buildProbeResources creates the Probe object in operator mode, or the ConfigMap holding the scrape
+// config of blackbox exporter otherwise, to perform the synthetic uptime checks of the workload.
+func (g *MonitoringModule) buildProbeResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	if g.Blackbox == nil {
+		return nil, nil
+	}
+	if g.Prober == nil || g.Prober.URL == "" {
+		return nil, ErrEmptyProber
+	}
+
+	probeModule := g.Blackbox.Module
+	if probeModule == "" {
+		probeModule = g.Prober.Module
+	}
+	if probeModule == "" {
+		probeModule = DefaultProbeModule
+	}
+	interval := g.Blackbox.Interval
+	if interval == "" {
+		interval = g.Interval
+	}
+	proberPath := g.Prober.Path
+	if proberPath == "" {
+		proberPath = DefaultProberPath
+	}
+	uniqueName := moduleutil.AppName(request)
+
+	var obj runtime.Object
+	var typeMeta metav1.TypeMeta
+	var objectMeta metav1.ObjectMeta
+	if g.OperatorMode {
+		targets := prometheusv1.ProbeTargets{}
+		if len(g.Blackbox.Targets) != 0 {
+			targets.StaticConfig = &prometheusv1.ProbeTargetStaticConfig{
+				Targets: g.Blackbox.Targets,
+			}
+		} else {
+			// Probe the Ingresses of the workload if no targets are declared.
+			targets.Ingress = &prometheusv1.ProbeTargetIngress{
+				Selector: metav1.LabelSelector{
+					MatchLabels: module.UniqueAppLabels(request.Project, request.App),
+				},
+			}
+		}
+		typeMeta = metav1.TypeMeta{
+			Kind:       "Probe",
+			APIVersion: prometheusv1.SchemeGroupVersion.String(),
+		}
+		objectMeta = metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-probe", uniqueName),
+			Namespace: request.Project,
+		}
+		obj = &prometheusv1.Probe{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Spec: prometheusv1.ProbeSpec{
+				ProberSpec: prometheusv1.ProberSpec{
+					URL:    g.Prober.URL,
+					Scheme: g.Prober.Scheme,
+					Path:   proberPath,
+				},
+				Module:        probeModule,
+				Targets:       targets,
+				Interval:      interval,
+				ScrapeTimeout: g.Timeout,
+			},
+		}
+	} else {
+		if len(g.Blackbox.Targets) == 0 {
+			return nil, ErrEmptyProbeTargets
+		}
+		// The scrape config follows the multi-target exporter pattern of blackbox exporter, which
+		// is written in JSON as a subset of YAML.
+		scrapeConfig := map[string]interface{}{
+			"scrape_configs": []interface{}{
+				map[string]interface{}{
+					"job_name":        fmt.Sprintf("probe/%s/%s", request.Project, uniqueName),
+					"metrics_path":    proberPath,
+					"scheme":          g.Prober.Scheme,
+					"scrape_interval": interval,
+					"scrape_timeout":  g.Timeout,
+					"params": map[string][]string{
+						"module": {probeModule},
+					},
+					"static_configs": []interface{}{
+						map[string]interface{}{"targets": g.Blackbox.Targets},
+					},
+					"relabel_configs": []interface{}{
+						map[string]interface{}{"source_labels": []string{"__address__"}, "target_label": "__param_target"},
+						map[string]interface{}{"source_labels": []string{"__param_target"}, "target_label": "instance"},
+						map[string]interface{}{"target_label": "__address__", "replacement": g.Prober.URL},
+					},
+				},
+			},
+		}
+		if g.Prober.Scheme == "" {
+			delete(scrapeConfig["scrape_configs"].([]interface{})[0].(map[string]interface{}), "scheme")
+		}
+		data, err := json.MarshalIndent(scrapeConfig, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		typeMeta = metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		}
+		objectMeta = metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-blackbox-scrape-config", uniqueName),
+			Namespace: request.Project,
+			Labels: map[string]string{
+				"kusion_monitoring_appname": request.App,
+			},
+		}
+		obj = &corev1.ConfigMap{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Data: map[string]string{
+				"scrape_config.yaml": string(data),
+			},
+		}
+	}
+
+	resourceID := module.KubernetesResourceID(typeMeta, objectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, obj)
+	if err != nil {
+		return nil, err
+	}
+	return []kusionapiv1.Resource{*resource}, nil
+}
@@ -11,6 +11,7 @@ import (
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
 )
 
 type TestCase struct {
@@ -46,9 +47,16 @@ func BuildMonitoringTestCase(
 					"apiVersion": "monitoring.coreos.com/v1",
 					"kind":       string(monitorKind),
 					"metadata": map[string]interface{}{
+						"annotations":       map[string]interface{}{moduleutil.AnnotationModule: "monitoring"},
 						"creationTimestamp": nil,
-						"name":              fmt.Sprintf("%s-%s-monitor", uniqueName, strings.ToLower(monitorType)),
-						"namespace":         projectName,
+						"labels": map[string]interface{}{
+							moduleutil.LabelAppName:   appName,
+							moduleutil.LabelManagedBy: moduleutil.ManagedByKusion,
+							moduleutil.LabelProject:   projectName,
+							moduleutil.LabelStack:     stackName,
+						},
+						"name":      fmt.Sprintf("%s-%s-monitor", uniqueName, strings.ToLower(monitorType)),
+						"namespace": projectName,
 					},
 					"spec": map[string]interface{}{
 						endpointType: []interface{}{
@@ -144,3 +152,184 @@ func TestMonitoringGenerator_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestMonitoringGenerator_MultipleEndpoints(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		PlatformConfig: kusionapiv1.GenericConfig{
+			OperatorModeKey: true,
+			MonitorTypeKey:  "Pod",
+			IntervalKey:     "30s",
+			TimeoutKey:      "15s",
+		},
+		DevConfig: kusionapiv1.Accessory{
+			PathKey:        "/metrics",
+			HonorLabelsKey: true,
+			EndpointsKey: []interface{}{
+				map[string]interface{}{
+					"port": "web",
+				},
+				map[string]interface{}{
+					"port":     "admin",
+					"path":     "/admin/metrics",
+					"scheme":   "https",
+					"interval": "10s",
+					"timeout":  "5s",
+					"tlsConfig": map[string]interface{}{
+						"serverName": "admin.test-app",
+						"caSecret": map[string]interface{}{
+							"name": "test-app-ca",
+							"key":  "ca.crt",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	g := &MonitoringModule{}
+	response, err := g.Generate(context.TODO(), request)
+	require.NoError(t, err)
+	require.Len(t, response.Resources, 1)
+
+	endpoints := response.Resources[0].Attributes["spec"].(map[string]interface{})["podMetricsEndpoints"].([]interface{})
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"bearerTokenSecret": map[string]interface{}{
+				"key": "",
+			},
+			"honorLabels":   true,
+			"interval":      "30s",
+			"scrapeTimeout": "15s",
+			"path":          "/metrics",
+			"port":          "web",
+			"scheme":        "http",
+		},
+		map[string]interface{}{
+			"bearerTokenSecret": map[string]interface{}{
+				"key": "",
+			},
+			"honorLabels":   true,
+			"interval":      "10s",
+			"scrapeTimeout": "5s",
+			"path":          "/admin/metrics",
+			"port":          "admin",
+			"scheme":        "https",
+			"tlsConfig": map[string]interface{}{
+				"ca": map[string]interface{}{
+					"secret": map[string]interface{}{
+						"name": "test-app-ca",
+						"key":  "ca.crt",
+					},
+				},
+				"cert":       map[string]interface{}{},
+				"serverName": "admin.test-app",
+			},
+		},
+	}, endpoints)
+
+	// The annotations are only able to describe a single endpoint.
+	request.PlatformConfig[OperatorModeKey] = false
+	_, err = g.Generate(context.TODO(), request)
+	require.ErrorIs(t, err, ErrMultipleEndpoints)
+}
+
+func TestMonitoringGenerator_Blackbox(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		PlatformConfig: kusionapiv1.GenericConfig{
+			OperatorModeKey: true,
+			MonitorTypeKey:  "Pod",
+			IntervalKey:     "30s",
+			TimeoutKey:      "15s",
+		},
+		DevConfig: kusionapiv1.Accessory{
+			PathKey: "/metrics",
+			PortKey: "web",
+			BlackboxKey: map[string]interface{}{
+				"targets": []interface{}{"https://test-app.example.com/healthz"},
+			},
+		},
+	}
+
+	g := &MonitoringModule{}
+	_, err := g.Generate(context.TODO(), request)
+	require.ErrorIs(t, err, ErrEmptyProber)
+
+	request.PlatformConfig[ProberKey] = map[string]interface{}{
+		"url": "blackbox-exporter.monitoring:9115",
+	}
+	response, err := g.Generate(context.TODO(), request)
+	require.NoError(t, err)
+	require.Len(t, response.Resources, 2)
+
+	probe := response.Resources[1]
+	require.Equal(t, "monitoring.coreos.com/v1:Probe:test-project:test-project-test-stack-test-app-probe", probe.ID)
+	require.Equal(t, map[string]interface{}{
+		"bearerTokenSecret": map[string]interface{}{
+			"key": "",
+		},
+		"prober": map[string]interface{}{
+			"url":  "blackbox-exporter.monitoring:9115",
+			"path": "/probe",
+		},
+		"module":        "http_2xx",
+		"interval":      "30s",
+		"scrapeTimeout": "15s",
+		"targets": map[string]interface{}{
+			"staticConfig": map[string]interface{}{
+				"static": []interface{}{"https://test-app.example.com/healthz"},
+			},
+		},
+	}, probe.Attributes["spec"])
+
+	// Without operator mode, the probe is written as a scrape config of Prometheus.
+	request.PlatformConfig[OperatorModeKey] = false
+	response, err = g.Generate(context.TODO(), request)
+	require.NoError(t, err)
+	require.Len(t, response.Resources, 1)
+	require.Equal(t, "v1:ConfigMap:test-project:test-project-test-stack-test-app-blackbox-scrape-config", response.Resources[0].ID)
+	scrapeConfig := response.Resources[0].Attributes["data"].(map[string]interface{})["scrape_config.yaml"].(string)
+	require.Contains(t, scrapeConfig, `"replacement": "blackbox-exporter.monitoring:9115"`)
+	require.Contains(t, scrapeConfig, `"https://test-app.example.com/healthz"`)
+
+	// The Ingresses of the workload can only be discovered by the Prometheus operator.
+	request.DevConfig[BlackboxKey] = map[string]interface{}{}
+	_, err = g.Generate(context.TODO(), request)
+	require.ErrorIs(t, err, ErrEmptyProbeTargets)
+}
+
+func TestMonitoringGenerator_InvalidConfig(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		PlatformConfig: kusionapiv1.GenericConfig{
+			OperatorModeKey: true,
+			IntervalKey:     30,
+		},
+		DevConfig: kusionapiv1.Accessory{
+			PathKey: "/metrics",
+			PortKey: "web",
+			EndpointsKey: []interface{}{
+				map[string]interface{}{"port": "web", "shceme": "https"},
+			},
+		},
+	}
+
+	_, err := (&MonitoringModule{}).Generate(context.TODO(), request)
+	require.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+	require.ErrorContains(t, err, "endpoints[0].shceme: unknown field")
+
+	request.DevConfig = kusionapiv1.Accessory{
+		PathKey: "/metrics",
+		PortKey: "web",
+	}
+	_, err = (&MonitoringModule{}).Generate(context.TODO(), request)
+	require.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+	require.ErrorContains(t, err, "interval: expected string, got integer")
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	prometheusv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+// defaultBurnRateWindows are the multi-window multi-burn-rate alerts recommended by the SRE workbook:
+// https://sre.google/workbook/alerting-on-slos/#6-multiwindow-multi-burn-rate-alerts
+var defaultBurnRateWindows = []BurnRateWindow{
+	{LongWindow: "1h", ShortWindow: "5m", BurnRate: 14.4, Severity: "page"},
+	{LongWindow: "6h", ShortWindow: "30m", BurnRate: 6, Severity: "page"},
+	{LongWindow: "1d", ShortWindow: "2h", BurnRate: 3, Severity: "ticket"},
+	{LongWindow: "3d", ShortWindow: "6h", BurnRate: 1, Severity: "ticket"},
+}
+
+// sli is a service level indicator compiled from the SLO, whose error ratio is recorded in each window.
+type sli struct {
+	name   string
+	target float64
+	// errorRatio returns the PromQL expression of the error ratio in the window.
+	errorRatio func(window prometheusv1.Duration) string
+}
+
+// complete validates the SLO and sets the defaults.
+func (s *SLO) complete() error {
+	if s.Availability == 0 && s.Latency == nil {
+		return ErrEmptySLOObjectives
+	}
+	if s.Availability != 0 && (s.Availability < 0 || s.Availability >= 100) {
+		return ErrInvalidSLOTarget
+	}
+	if s.Latency != nil {
+		if s.Latency.Target <= 0 || s.Latency.Target >= 100 {
+			return ErrInvalidSLOTarget
+		}
+		if s.Latency.Threshold == "" {
+			return ErrEmptyLatencyThreshold
+		}
+		if s.Latency.Metric == "" {
+			s.Latency.Metric = DefaultLatencyMetric
+		}
+	}
+	if s.RequestsMetric == "" {
+		s.RequestsMetric = DefaultRequestsMetric
+	}
+	if s.ErrorSelector == "" {
+		s.ErrorSelector = DefaultErrorSelector
+	}
+	if len(s.Windows) == 0 {
+		s.Windows = append([]BurnRateWindow(nil), defaultBurnRateWindows...)
+	}
+	for i := range s.Windows {
+		w := &s.Windows[i]
+		if w.LongWindow == "" || w.ShortWindow == "" || w.BurnRate <= 0 {
+			return ErrInvalidBurnRateWindow
+		}
+		if w.Severity == "" {
+			w.Severity = "page"
+		}
+	}
+	return nil
+}
+
+// buildSLOResources creates the PrometheusRule in operator mode, or the ConfigMap holding the rule
+// file of Prometheus otherwise, to record the error ratios and alert on the burn rates of the SLO.
+func (g *MonitoringModule) buildSLOResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	if g.SLO == nil {
+		return nil, nil
+	}
+
+	uniqueName := moduleutil.AppName(request)
+	var alertLabels map[string]string
+	if g.Alerting != nil {
+		alertLabels = g.Alerting.routingLabels(request.App)
+	}
+	groups := g.SLO.ruleGroups(request.Project, request.App, uniqueName, alertLabels)
+	labels := map[string]string{
+		"kusion_monitoring_appname": request.App,
+	}
+
+	var obj runtime.Object
+	var typeMeta metav1.TypeMeta
+	var objectMeta metav1.ObjectMeta
+	if g.OperatorMode {
+		typeMeta = metav1.TypeMeta{
+			Kind:       "PrometheusRule",
+			APIVersion: prometheusv1.SchemeGroupVersion.String(),
+		}
+		objectMeta = metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slo", uniqueName),
+			Namespace: request.Project,
+			Labels:    labels,
+		}
+		obj = &prometheusv1.PrometheusRule{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Spec: prometheusv1.PrometheusRuleSpec{
+				Groups: groups,
+			},
+		}
+	} else {
+		// The rule file is written in JSON as a subset of YAML.
+		data, err := json.MarshalIndent(prometheusv1.PrometheusRuleSpec{Groups: groups}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		typeMeta = metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		}
+		objectMeta = metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-slo-rules", uniqueName),
+			Namespace: request.Project,
+			Labels:    labels,
+		}
+		obj = &corev1.ConfigMap{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Data: map[string]string{
+				"slo_rules.yaml": string(data),
+			},
+		}
+	}
+
+	resourceID := module.KubernetesResourceID(typeMeta, objectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, obj)
+	if err != nil {
+		return nil, err
+	}
+	return []kusionapiv1.Resource{*resource}, nil
+}
+
+// ruleGroups compiles the SLO to a recording rule group of the error ratios in every window, and an
+// alerting rule group of the burn rates in every pair of windows, whose alerts are attached with the
+// routing labels.
+func (s *SLO) ruleGroups(namespace, app, uniqueName string, alertLabels map[string]string) []prometheusv1.RuleGroup {
+	selector := fmt.Sprintf(`namespace="%s",kusion_monitoring_appname="%s"`, namespace, app)
+
+	var slis []sli
+	if s.Availability != 0 {
+		slis = append(slis, sli{
+			name:   "availability",
+			target: s.Availability,
+			errorRatio: func(window prometheusv1.Duration) string {
+				return fmt.Sprintf("sum(rate(%s{%s,%s}[%s])) / sum(rate(%s{%s}[%s]))",
+					s.RequestsMetric, selector, s.ErrorSelector, window, s.RequestsMetric, selector, window)
+			},
+		})
+	}
+	if s.Latency != nil {
+		slis = append(slis, sli{
+			name:   "latency",
+			target: s.Latency.Target,
+			errorRatio: func(window prometheusv1.Duration) string {
+				return fmt.Sprintf(`1 - sum(rate(%s{%s,le="%s"}[%s])) / sum(rate(%s{%s,le="+Inf"}[%s]))`,
+					s.Latency.Metric, selector, s.Latency.Threshold, window, s.Latency.Metric, selector, window)
+			},
+		})
+	}
+
+	// Collect the distinct windows in order to record the error ratio once per window.
+	var windows []prometheusv1.Duration
+	seen := map[prometheusv1.Duration]bool{}
+	for _, w := range s.Windows {
+		for _, window := range []prometheusv1.Duration{w.ShortWindow, w.LongWindow} {
+			if !seen[window] {
+				seen[window] = true
+				windows = append(windows, window)
+			}
+		}
+	}
+
+	var recordingRules, alertingRules []prometheusv1.Rule
+	for _, indicator := range slis {
+		sloLabels := map[string]string{
+			"slo": fmt.Sprintf("%s-%s", app, indicator.name),
+		}
+		sloSelector := fmt.Sprintf(`slo="%s"`, sloLabels["slo"])
+		for _, window := range windows {
+			recordingRules = append(recordingRules, prometheusv1.Rule{
+				Record: fmt.Sprintf("slo:sli_error:ratio_rate%s", window),
+				Expr:   intstr.FromString(indicator.errorRatio(window)),
+				Labels: sloLabels,
+			})
+		}
+		errorBudget := fmt.Sprintf("(1 - %s / 100)", strconv.FormatFloat(indicator.target, 'f', -1, 64))
+		for _, w := range s.Windows {
+			burnRate := strconv.FormatFloat(w.BurnRate, 'f', -1, 64)
+			labels := map[string]string{
+				"slo":         sloLabels["slo"],
+				"severity":    w.Severity,
+				"long_window": string(w.LongWindow),
+			}
+			for k, v := range alertLabels {
+				labels[k] = v
+			}
+			alertingRules = append(alertingRules, prometheusv1.Rule{
+				Alert: fmt.Sprintf("SLOErrorBudgetBurn%s", sloAlertSuffix(indicator.name)),
+				Expr: intstr.FromString(fmt.Sprintf(
+					"slo:sli_error:ratio_rate%s{%s} > (%s * %s) and slo:sli_error:ratio_rate%s{%s} > (%s * %s)",
+					w.LongWindow, sloSelector, burnRate, errorBudget, w.ShortWindow, sloSelector, burnRate, errorBudget)),
+				Labels: labels,
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("%s of %s is burning the error budget %sx faster than the %s%% objective over %s",
+						indicator.name, app, burnRate, strconv.FormatFloat(indicator.target, 'f', -1, 64), w.LongWindow),
+				},
+			})
+		}
+	}
+
+	return []prometheusv1.RuleGroup{
+		{
+			Name:  fmt.Sprintf("%s-slo-recording", uniqueName),
+			Rules: recordingRules,
+		},
+		{
+			Name:  fmt.Sprintf("%s-slo-alerting", uniqueName),
+			Rules: alertingRules,
+		},
+	}
+}
+
+// sloAlertSuffix returns the alert name suffix of the service level indicator.
+func sloAlertSuffix(name string) string {
+	if name == "latency" {
+		return "Latency"
+	}
+	return "Availability"
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestSLO_Complete(t *testing.T) {
+	tests := []struct {
+		name    string
+		slo     *SLO
+		wantErr error
+	}{
+		{
+			name:    "empty objectives",
+			slo:     &SLO{},
+			wantErr: ErrEmptySLOObjectives,
+		},
+		{
+			name:    "invalid availability",
+			slo:     &SLO{Availability: 100},
+			wantErr: ErrInvalidSLOTarget,
+		},
+		{
+			name:    "empty latency threshold",
+			slo:     &SLO{Latency: &LatencyObjective{Target: 99}},
+			wantErr: ErrEmptyLatencyThreshold,
+		},
+		{
+			name:    "invalid burn rate window",
+			slo:     &SLO{Availability: 99.9, Windows: []BurnRateWindow{{LongWindow: "1h", BurnRate: 14.4}}},
+			wantErr: ErrInvalidBurnRateWindow,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, tt.slo.complete(), tt.wantErr)
+		})
+	}
+
+	slo := &SLO{Availability: 99.9, Latency: &LatencyObjective{Threshold: "0.3", Target: 99}}
+	require.NoError(t, slo.complete())
+	require.Equal(t, DefaultRequestsMetric, slo.RequestsMetric)
+	require.Equal(t, DefaultErrorSelector, slo.ErrorSelector)
+	require.Equal(t, DefaultLatencyMetric, slo.Latency.Metric)
+	require.Equal(t, defaultBurnRateWindows, slo.Windows)
+}
+
+func TestMonitoringGenerator_SLO(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		PlatformConfig: kusionapiv1.GenericConfig{
+			OperatorModeKey: true,
+			MonitorTypeKey:  "Pod",
+		},
+		DevConfig: kusionapiv1.Accessory{
+			PathKey: "/metrics",
+			PortKey: "web",
+			SLOKey: map[string]interface{}{
+				"availability": 99.9,
+				"windows": []interface{}{
+					map[string]interface{}{
+						"longWindow":  "1h",
+						"shortWindow": "5m",
+						"burnRate":    14.4,
+					},
+				},
+			},
+		},
+	}
+
+	g := &MonitoringModule{}
+	response, err := g.Generate(context.TODO(), request)
+	require.NoError(t, err)
+	require.Len(t, response.Resources, 2)
+	require.Equal(t, []interface{}{"kusion_monitoring_appname"},
+		response.Resources[0].Attributes["spec"].(map[string]interface{})["podTargetLabels"])
+
+	rule := response.Resources[1]
+	require.Equal(t, "monitoring.coreos.com/v1:PrometheusRule:test-project:test-project-test-stack-test-app-slo", rule.ID)
+	require.Equal(t, map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name": "test-project-test-stack-test-app-slo-recording",
+				"rules": []interface{}{
+					map[string]interface{}{
+						"record": "slo:sli_error:ratio_rate5m",
+						"expr": `sum(rate(http_requests_total{namespace="test-project",kusion_monitoring_appname="test-app",code=~"5.."}[5m])) / ` +
+							`sum(rate(http_requests_total{namespace="test-project",kusion_monitoring_appname="test-app"}[5m]))`,
+						"labels": map[string]interface{}{"slo": "test-app-availability"},
+					},
+					map[string]interface{}{
+						"record": "slo:sli_error:ratio_rate1h",
+						"expr": `sum(rate(http_requests_total{namespace="test-project",kusion_monitoring_appname="test-app",code=~"5.."}[1h])) / ` +
+							`sum(rate(http_requests_total{namespace="test-project",kusion_monitoring_appname="test-app"}[1h]))`,
+						"labels": map[string]interface{}{"slo": "test-app-availability"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"name": "test-project-test-stack-test-app-slo-alerting",
+				"rules": []interface{}{
+					map[string]interface{}{
+						"alert": "SLOErrorBudgetBurnAvailability",
+						"expr": `slo:sli_error:ratio_rate1h{slo="test-app-availability"} > (14.4 * (1 - 99.9 / 100)) and ` +
+							`slo:sli_error:ratio_rate5m{slo="test-app-availability"} > (14.4 * (1 - 99.9 / 100))`,
+						"labels": map[string]interface{}{
+							"slo":         "test-app-availability",
+							"severity":    "page",
+							"long_window": "1h",
+						},
+						"annotations": map[string]interface{}{
+							"summary": "availability of test-app is burning the error budget 14.4x faster than the 99.9% objective over 1h",
+						},
+					},
+				},
+			},
+		},
+	}, rule.Attributes["spec"])
+
+	// Without operator mode, the rules are written as a rule file of Prometheus.
+	request.PlatformConfig[OperatorModeKey] = false
+	response, err = g.Generate(context.TODO(), request)
+	require.NoError(t, err)
+	require.Len(t, response.Resources, 1)
+	require.Equal(t, "v1:ConfigMap:test-project:test-project-test-stack-test-app-slo-rules", response.Resources[0].ID)
+	require.Equal(t, map[string]string{"kusion_monitoring_appname": "test-app"}, response.Patcher.Labels)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	prometheusv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"moduleutil"
 )
 
 const (
@@ -14,18 +15,47 @@ const (
 	IntervalKey                    = "interval"
 	TimeoutKey                     = "timeout"
 	SchemeKey                      = "scheme"
+	EndpointsKey                   = "endpoints"
+	HonorLabelsKey                 = "honorLabels"
+	BlackboxKey                    = "blackbox"
+	ProberKey                      = "prober"
+	SLOKey                         = "slo"
+	AlertingKey                    = "alerting"
 	DefaultMonitorType             = "Service"
 	DefaultInterval                = "30s"
 	DefaultTimeout                 = "15s"
 	DefaultScheme                  = "http"
+	DefaultProbeModule             = "http_2xx"
+	DefaultProberPath              = "/probe"
 	PodMonitorType     MonitorType = "Pod"
 	ServiceMonitorType MonitorType = "Service"
 )
 
+// The default metrics of the service level indicators, which follow the conventions of Prometheus
+// client libraries.
+const (
+	DefaultRequestsMetric = "http_requests_total"
+	DefaultErrorSelector  = `code=~"5.."`
+	DefaultLatencyMetric  = "http_request_duration_seconds_bucket"
+)
+
 var (
 	ErrTimeoutGreaterThanInterval = errors.New("timeout cannot be greater than interval")
 	ErrPathAndPortEmpty           = errors.New("path and port must be present in monitoring configuration")
 	ErrEmptyMonitoringConfigBlock = errors.New("empty dev config for monitoring")
+	ErrEmptyEndpointPort          = errors.New("port must be present in each monitoring endpoint")
+	ErrMultipleEndpoints          = errors.New("multiple monitoring endpoints are only supported in operator mode")
+	ErrEmptyProber                = errors.New("prober must be present in workspace configuration for blackbox probes")
+	ErrEmptyProbeTargets          = errors.New("blackbox targets must be present when operator mode is disabled")
+	ErrEmptySLOObjectives         = errors.New("availability or latency objective must be present in slo")
+	ErrInvalidSLOTarget           = errors.New("slo target must be greater than 0 and less than 100")
+	ErrEmptyLatencyThreshold      = errors.New("threshold must be present in latency objective")
+	ErrInvalidBurnRateWindow      = errors.New("long window, short window and positive burn rate must be present in each burn rate window")
+	ErrAlertingWithoutOperator    = errors.New("alert routing is only supported in operator mode")
+	ErrEmptyAlertingTeam          = errors.New("team must be present in alerting")
+	ErrEmptyAlertReceivers        = errors.New("no alert receiver declared in alerting or configured for the team in workspace")
+	ErrInvalidAlertReceiver       = errors.New("each alert receiver must have a unique name and either pagerduty or slack")
+	ErrInvalidMuteTiming          = errors.New("each mute timing must have a unique name and the times or weekdays")
 )
 
 type (
@@ -43,4 +73,195 @@ type MonitoringModule struct {
 	// need to be the user-provided port name.
 	Port   string `yaml:"port,omitempty" json:"port,omitempty"`
 	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	// HonorLabels chooses the labels of the metrics when they collide with the target labels.
+	HonorLabels bool `yaml:"honorLabels,omitempty" json:"honorLabels,omitempty"`
+	// Endpoints are the multiple scrape endpoints of the workload, the path, scheme, interval,
+	// timeout and honorLabels above are used as the default of each endpoint.
+	Endpoints []Endpoint `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`
+	// Blackbox defines the synthetic uptime checks of the workload.
+	Blackbox *Blackbox `yaml:"blackbox,omitempty" json:"blackbox,omitempty"`
+	// Prober is the blackbox exporter configured in workspace to perform the uptime checks.
+	Prober *Prober `yaml:"prober,omitempty" json:"prober,omitempty"`
+	// SLO defines the service level objectives of the workload.
+	SLO *SLO `yaml:"slo,omitempty" json:"slo,omitempty"`
+	// Alerting routes the alerts of the workload to the on-call channels of the team.
+	Alerting *Alerting `yaml:"alerting,omitempty" json:"alerting,omitempty"`
+	// AlertRouting is the alert routing configured in workspace.
+	AlertRouting *AlertRouting `yaml:"alertRouting,omitempty" json:"alertRouting,omitempty"`
+}
+
+// DevConfig describes the monitoring config declared by the application.
+type DevConfig struct {
+	Path        string     `yaml:"path,omitempty" json:"path,omitempty"`
+	Port        string     `yaml:"port,omitempty" json:"port,omitempty"`
+	HonorLabels bool       `yaml:"honorLabels,omitempty" json:"honorLabels,omitempty"`
+	Endpoints   []Endpoint `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`
+	Blackbox    *Blackbox  `yaml:"blackbox,omitempty" json:"blackbox,omitempty"`
+	SLO         *SLO       `yaml:"slo,omitempty" json:"slo,omitempty"`
+	Alerting    *Alerting  `yaml:"alerting,omitempty" json:"alerting,omitempty"`
+}
+
+// PlatformConfig describes the monitoring config in workspace.
+type PlatformConfig struct {
+	OperatorMode bool                  `yaml:"operatorMode,omitempty" json:"operatorMode,omitempty"`
+	MonitorType  MonitorType           `yaml:"monitorType,omitempty" json:"monitorType,omitempty"`
+	Interval     prometheusv1.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout      prometheusv1.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Scheme       string                `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	Prober       *Prober               `yaml:"prober,omitempty" json:"prober,omitempty"`
+	Alerting     *AlertRouting         `yaml:"alerting,omitempty" json:"alerting,omitempty"`
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
+}
+
+// SLO defines the service level objectives of the workload, which are compiled to the recording
+// and multi-window multi-burn-rate alerting rules of Prometheus.
+type SLO struct {
+	// Availability is the target percentage of the successful requests, e.g. 99.9.
+	Availability float64 `yaml:"availability,omitempty" json:"availability,omitempty"`
+	// Latency is the objective of the request latency.
+	Latency *LatencyObjective `yaml:"latency,omitempty" json:"latency,omitempty"`
+	// RequestsMetric is the counter of the requests, default to http_requests_total.
+	RequestsMetric string `yaml:"requestsMetric,omitempty" json:"requestsMetric,omitempty"`
+	// ErrorSelector is the label matcher selecting the failed requests, default to code=~"5..".
+	ErrorSelector string `yaml:"errorSelector,omitempty" json:"errorSelector,omitempty"`
+	// Windows are the burn rate windows to alert on, default to the ones recommended by the SRE workbook.
+	Windows []BurnRateWindow `yaml:"windows,omitempty" json:"windows,omitempty"`
+}
+
+// LatencyObjective defines the target percentage of the requests served faster than the threshold.
+type LatencyObjective struct {
+	// Metric is the histogram bucket of the request duration, default to http_request_duration_seconds_bucket.
+	Metric string `yaml:"metric,omitempty" json:"metric,omitempty"`
+	// Threshold is the upper bound of the histogram bucket in seconds, e.g. 0.3.
+	Threshold string `yaml:"threshold" json:"threshold"`
+	// Target is the target percentage, e.g. 99.
+	Target float64 `yaml:"target" json:"target"`
+}
+
+// BurnRateWindow defines a pair of windows in which the error budget burns faster than the burn rate.
+type BurnRateWindow struct {
+	LongWindow  prometheusv1.Duration `yaml:"longWindow" json:"longWindow"`
+	ShortWindow prometheusv1.Duration `yaml:"shortWindow" json:"shortWindow"`
+	BurnRate    float64               `yaml:"burnRate" json:"burnRate"`
+	// Severity is the severity label of the alert, default to page.
+	Severity string `yaml:"severity,omitempty" json:"severity,omitempty"`
+}
+
+// Alerting defines the routing of the alerts of the workload, which are labeled with the team and
+// routed to the receivers by an AlertmanagerConfig.
+type Alerting struct {
+	// Team is the team owning the workload, which is attached as the team label to the alerts.
+	Team string `yaml:"team" json:"team"`
+	// Receivers are the on-call channels of the alerts, default to the receivers of the team in
+	// workspace.
+	Receivers []Receiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+	// MuteTimings are the time intervals in which the notifications are muted, e.g. the
+	// maintenance windows.
+	MuteTimings []MuteTiming `yaml:"muteTimings,omitempty" json:"muteTimings,omitempty"`
+	// GroupBy are the labels to group the alerts by, default to alertname.
+	GroupBy []string `yaml:"groupBy,omitempty" json:"groupBy,omitempty"`
+	// RepeatInterval is the interval to resend the notifications of the firing alerts.
+	RepeatInterval prometheusv1.Duration `yaml:"repeatInterval,omitempty" json:"repeatInterval,omitempty"`
+}
+
+// Receiver defines an on-call channel receiving the alerts of the severities.
+type Receiver struct {
+	Name string `yaml:"name" json:"name"`
+	// Severities are the severity labels of the alerts sent to the receiver, all if empty.
+	Severities []string `yaml:"severities,omitempty" json:"severities,omitempty"`
+	// PagerDuty sends the alerts to the PagerDuty service of the routing key.
+	PagerDuty *PagerDutyReceiver `yaml:"pagerduty,omitempty" json:"pagerduty,omitempty"`
+	// Slack sends the alerts to the Slack channel.
+	Slack *SlackReceiver `yaml:"slack,omitempty" json:"slack,omitempty"`
+}
+
+// PagerDutyReceiver defines the PagerDuty service receiving the alerts.
+type PagerDutyReceiver struct {
+	// RoutingKey references the integration key of the Events API v2 of the service.
+	RoutingKey SecretKey `yaml:"routingKey" json:"routingKey"`
+}
+
+// SlackReceiver defines the Slack channel receiving the alerts.
+type SlackReceiver struct {
+	// APIURL references the incoming webhook URL of Slack.
+	APIURL SecretKey `yaml:"apiURL" json:"apiURL"`
+	// Channel is the channel or user to send the notifications to, default to the channel of the
+	// webhook.
+	Channel string `yaml:"channel,omitempty" json:"channel,omitempty"`
+}
+
+// MuteTiming defines the time intervals in which the notifications are muted.
+type MuteTiming struct {
+	Name string `yaml:"name" json:"name"`
+	// Weekdays are the days or ranges of days of the week, e.g. saturday:sunday.
+	Weekdays []string `yaml:"weekdays,omitempty" json:"weekdays,omitempty"`
+	// Times are the time ranges of the day in UTC.
+	Times []TimeRange `yaml:"times,omitempty" json:"times,omitempty"`
+}
+
+// TimeRange defines a range of the day in the format of HH:MM.
+type TimeRange struct {
+	StartTime string `yaml:"startTime" json:"startTime"`
+	EndTime   string `yaml:"endTime" json:"endTime"`
+}
+
+// AlertRouting defines the alert routing in workspace.
+type AlertRouting struct {
+	// Teams are the default receivers of the teams.
+	Teams map[string][]Receiver `yaml:"teams,omitempty" json:"teams,omitempty"`
+	// Labels are attached to the AlertmanagerConfigs to be selected by the alertmanagerConfigSelector
+	// of Alertmanager.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+}
+
+// Blackbox defines the synthetic uptime checks of the workload performed by the blackbox exporter.
+type Blackbox struct {
+	// Targets are the public endpoints to probe, e.g. https://example.com/healthz. The Ingresses of
+	// the workload are probed if empty, which is only supported in operator mode.
+	Targets []string `yaml:"targets,omitempty" json:"targets,omitempty"`
+	// Module is the module of blackbox exporter used to probe the targets.
+	Module string `yaml:"module,omitempty" json:"module,omitempty"`
+	// Interval is the interval to probe the targets.
+	Interval prometheusv1.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+}
+
+// Prober defines the blackbox exporter that performs the probes.
+type Prober struct {
+	// URL is the address of the blackbox exporter, e.g. blackbox-exporter.monitoring:9115.
+	URL    string `yaml:"url" json:"url"`
+	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	Path   string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Module is the default module of blackbox exporter.
+	Module string `yaml:"module,omitempty" json:"module,omitempty"`
+}
+
+// Endpoint defines a scrape endpoint of the workload.
+type Endpoint struct {
+	Path        string                `yaml:"path,omitempty" json:"path,omitempty"`
+	Port        string                `yaml:"port,omitempty" json:"port,omitempty"`
+	Scheme      string                `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+	Interval    prometheusv1.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout     prometheusv1.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	HonorLabels bool                  `yaml:"honorLabels,omitempty" json:"honorLabels,omitempty"`
+	TLSConfig   *TLSConfig            `yaml:"tlsConfig,omitempty" json:"tlsConfig,omitempty"`
+}
+
+// TLSConfig defines the TLS parameters used to scrape the endpoint with https scheme.
+type TLSConfig struct {
+	// ServerName is used to verify the hostname of the targets.
+	ServerName string `yaml:"serverName,omitempty" json:"serverName,omitempty"`
+	// InsecureSkipVerify disables the certificate validation of the targets.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify,omitempty" json:"insecureSkipVerify,omitempty"`
+	// CASecret references the certificate authority used to verify the certificates of the targets.
+	CASecret *SecretKey `yaml:"caSecret,omitempty" json:"caSecret,omitempty"`
+	// CertSecret and KeySecret reference the client certificate and key for client-authentication.
+	CertSecret *SecretKey `yaml:"certSecret,omitempty" json:"certSecret,omitempty"`
+	KeySecret  *SecretKey `yaml:"keySecret,omitempty" json:"keySecret,omitempty"`
+}
+
+// SecretKey selects a key of a Secret in the namespace of the workload.
+type SecretKey struct {
+	Name string `yaml:"name" json:"name"`
+	Key  string `yaml:"key" json:"key"`
 }
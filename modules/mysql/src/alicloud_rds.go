@@ -2,14 +2,17 @@ package main
 
 import (
 	"errors"
-	"os"
 	"strings"
 
+	"dbutil"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 )
 
-var ErrEmptyAlicloudProviderRegion = errors.New("empty alicloud provider region")
+var (
+	ErrEmptyAlicloudProviderRegion = errors.New("empty alicloud provider region")
+	ErrInvalidEndpoints            = dbutil.ErrInvalidEndpoints
+)
 
 var (
 	alicloudRegionEnv    = "ALICLOUD_REGION"
@@ -37,11 +40,9 @@ func (mysql *MySQL) GenerateAlicloudResources(request *module.GeneratorRequest)
 	// Set the Alicloud provider with the default provider config.
 	alicloudProviderCfg := defaultAlicloudProviderCfg
 
-	// Get the Alicloud Terraform provider region, which should not be empty.
-	var region string
-	if region = module.TerraformProviderRegion(alicloudProviderCfg); region == "" {
-		region = os.Getenv(alicloudRegionEnv)
-	}
+	// Get the Alicloud Terraform provider region resolved from the configs, or the
+	// environment variable if not set, which should not be empty.
+	region := dbutil.ProviderRegion(mysql.Region, alicloudProviderCfg, alicloudRegionEnv)
 	if region == "" {
 		return nil, nil, ErrEmptyAlicloudProviderRegion
 	}
@@ -62,6 +63,15 @@ func (mysql *MySQL) GenerateAlicloudResources(request *module.GeneratorRequest)
 	}
 	resources = append(resources, *alicloudDBInstanceRes)
 
+	// Build alicloud_db_backup_policy resource retaining the binary logs.
+	if mysql.BinlogRetentionHours > 0 {
+		alicloudDBBackupPolicyRes, err := mysql.generateAlicloudDBBackupPolicy(alicloudProviderCfg, region, alicloudDBInstanceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *alicloudDBBackupPolicyRes)
+	}
+
 	// Build alicloud_db_connection resource.
 	var alicloudDBConnectionRes *kusionapiv1.Resource
 	var alicloudDBConnectionID string
@@ -121,6 +131,19 @@ func (mysql *MySQL) generateAlicloudDBInstance(alicloudProviderCfg module.Provid
 		"instance_name":    mysql.DatabaseName,
 	}
 
+	// Enable the TDE and the SSL of the instance, and refuse the connections not over TLS.
+	if tls := mysql.TLS; tls != nil {
+		if tls.TDE {
+			resAttrs["tde_status"] = "Enabled"
+		}
+		if tls.RequireSecureTransport {
+			resAttrs["ssl_action"] = "Open"
+			resAttrs["parameters"] = []map[string]interface{}{
+				{"name": requireSecureTransportParam, "value": "ON"},
+			}
+		}
+	}
+
 	// Set the serverless-specific attributes of the alicloud_db_instance resource.
 	if strings.Contains(mysql.Category, "serverless") {
 		resAttrs["db_instance_storage_type"] = "cloud_essd"
@@ -143,7 +166,7 @@ func (mysql *MySQL) generateAlicloudDBInstance(alicloudProviderCfg module.Provid
 		return nil, "", err
 	}
 
-	alicloudProviderCfg.ProviderMeta = map[string]any{"region": region}
+	alicloudProviderCfg.ProviderMeta = mysql.alicloudProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudDBInstance, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -166,7 +189,7 @@ func (mysql *MySQL) generateAlicloudDBConnection(alicloudProviderCfg module.Prov
 		return nil, "", err
 	}
 
-	alicloudProviderCfg.ProviderMeta = map[string]any{"region": region}
+	alicloudProviderCfg.ProviderMeta = mysql.alicloudProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudDBConnection, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -192,7 +215,7 @@ func (mysql *MySQL) generateAlicloudRDSAccount(alicloudProviderCfg module.Provid
 		return nil, err
 	}
 
-	alicloudProviderCfg.ProviderMeta = map[string]any{"region": region}
+	alicloudProviderCfg.ProviderMeta = mysql.alicloudProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudRDSAccount, id, resAttrs, nil)
 	if err != nil {
 		return nil, err
@@ -200,3 +223,10 @@ func (mysql *MySQL) generateAlicloudRDSAccount(alicloudProviderCfg module.Provid
 
 	return resource, nil
 }
+
+// alicloudProviderMeta returns the provider meta of the Alicloud provider in the region, which
+// assumes the RAM role, uses the custom endpoints and the security token if set in the platform
+// config.
+func (mysql *MySQL) alicloudProviderMeta(region string) map[string]any {
+	return mysql.providerOverride().AlicloudProviderMeta(region)
+}
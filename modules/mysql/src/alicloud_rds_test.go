@@ -107,3 +107,23 @@ func TestMySQLModule_GenerateAlicloudRDSAccount(t *testing.T) {
 	assert.NotNil(t, res)
 	assert.NoError(t, err)
 }
+
+func TestMySQLModule_AlicloudProviderMeta(t *testing.T) {
+	mysql := &MySQL{
+		AssumeRoleARN: "acs:ram::123456789012:role/kusion",
+		SessionName:   "kusion",
+		Endpoints:     map[string]string{"rds": "rds-vpc.cn-beijing.aliyuncs.com"},
+		SecurityToken: "test-token",
+	}
+
+	assert.Equal(t, map[string]any{
+		"region": "cn-beijing",
+		"assume_role": map[string]any{
+			"role_arn":     "acs:ram::123456789012:role/kusion",
+			"session_name": "kusion",
+		},
+		"endpoints":      map[string]any{"rds": "rds-vpc.cn-beijing.aliyuncs.com"},
+		"security_token": "test-token",
+	}, mysql.alicloudProviderMeta("cn-beijing"))
+	assert.Equal(t, map[string]any{"region": "cn-beijing"}, (&MySQL{}).alicloudProviderMeta("cn-beijing"))
+}
@@ -3,13 +3,17 @@ package main
 import (
 	"errors"
 	"fmt"
-	"os"
 
+	"dbutil"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 )
 
-var ErrEmptyAWSProviderRegion = errors.New("empty aws provider region")
+var (
+	ErrEmptyAWSProviderRegion = errors.New("empty aws provider region")
+	ErrEmptyAssumeRoleARN     = dbutil.ErrEmptyAssumeRoleARN
+	ErrInvalidAssumeRoleARN   = dbutil.ErrInvalidAssumeRoleARN
+)
 
 var (
 	awsRegionEnv     = "AWS_REGION"
@@ -41,11 +45,9 @@ func (mysql *MySQL) GenerateAWSResources(request *module.GeneratorRequest) ([]ku
 	// Set the AWS provider with the default provider config.
 	awsProviderCfg := defaultAWSProviderCfg
 
-	// Get the AWS Terraform provider region, which should not be empty.
-	var region string
-	if region = module.TerraformProviderRegion(awsProviderCfg); region == "" {
-		region = os.Getenv(awsRegionEnv)
-	}
+	// Get the AWS Terraform provider region resolved from the configs, or the
+	// environment variable if not set, which should not be empty.
+	region := dbutil.ProviderRegion(mysql.Region, awsProviderCfg, awsRegionEnv)
 	if region == "" {
 		return nil, nil, ErrEmptyAWSProviderRegion
 	}
@@ -69,6 +71,16 @@ func (mysql *MySQL) GenerateAWSResources(request *module.GeneratorRequest) ([]ku
 	if err != nil {
 		return nil, nil, err
 	}
+
+	// Build aws_db_parameter_group resource refusing the connections not over TLS.
+	if mysql.requireSecureTransport() {
+		parameterGroup, parameterGroupID, err := mysql.generateAWSDBParameterGroup(awsProviderCfg, region)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *parameterGroup)
+		awsDBInstance.Attributes["parameter_group_name"] = module.KusionPathDependency(parameterGroupID, "name")
+	}
 	resources = append(resources, *awsDBInstance)
 
 	hostAddress := module.KusionPathDependency(awsDBInstanceID, "address")
@@ -119,7 +131,7 @@ func (mysql *MySQL) generateAWSSecurityGroup(awsProviderCfg module.ProviderConfi
 		return nil, "", err
 	}
 
-	awsProviderCfg.ProviderMeta = map[string]any{"region": region}
+	awsProviderCfg.ProviderMeta = mysql.awsProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsSecurityGroup, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -149,12 +161,25 @@ func (mysql *MySQL) generateAWSDBInstance(awsProviderCfg module.ProviderConfig,
 		resAttrs["db_subnet_group_name"] = mysql.SubnetID
 	}
 
+	// RDS for MySQL doesn't support TDE, where the storage is encrypted with the KMS key instead.
+	if tls := mysql.TLS; tls != nil {
+		if tls.TDE {
+			resAttrs["storage_encrypted"] = true
+			if tls.KMSKeyID != "" {
+				resAttrs["kms_key_id"] = tls.KMSKeyID
+			}
+		}
+		if tls.CACertIdentifier != "" {
+			resAttrs["ca_cert_identifier"] = tls.CACertIdentifier
+		}
+	}
+
 	id, err := module.TerraformResourceID(awsProviderCfg, awsDBInstance, mysql.DatabaseName)
 	if err != nil {
 		return nil, "", err
 	}
 
-	awsProviderCfg.ProviderMeta = map[string]any{"region": region}
+	awsProviderCfg.ProviderMeta = mysql.awsProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsDBInstance, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -162,3 +187,20 @@ func (mysql *MySQL) generateAWSDBInstance(awsProviderCfg module.ProviderConfig,
 
 	return resource, id, nil
 }
+
+// awsProviderMeta returns the provider meta of the AWS provider in the region, which assumes the
+// IAM role and uses the named profile if set in the platform config.
+func (mysql *MySQL) awsProviderMeta(region string) map[string]any {
+	return mysql.providerOverride().AWSProviderMeta(region)
+}
+
+// providerOverride returns the override of the provider configs in the platform config.
+func (mysql *MySQL) providerOverride() dbutil.ProviderOverride {
+	return dbutil.ProviderOverride{
+		AssumeRoleARN: mysql.AssumeRoleARN,
+		SessionName:   mysql.SessionName,
+		Profile:       mysql.Profile,
+		Endpoints:     mysql.Endpoints,
+		SecurityToken: mysql.SecurityToken,
+	}
+}
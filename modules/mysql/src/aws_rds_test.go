@@ -84,3 +84,27 @@ func TestMySQLModule_GenerateAWSDBInstance(t *testing.T) {
 	assert.NotEqual(t, id, "")
 	assert.NoError(t, err)
 }
+
+func TestMySQLModule_AWSProviderMeta(t *testing.T) {
+	mysql := &MySQL{
+		Type:          "cloud",
+		DatabaseName:  "test-database",
+		InstanceType:  "db.t3.micro",
+		AssumeRoleARN: "arn:aws:iam::123456789012:role/kusion",
+		SessionName:   "kusion",
+		Profile:       "payments",
+	}
+
+	res, _, err := mysql.generateAWSDBInstance(defaultAWSProviderCfg, "test-region", "test-password-id", "test-security-group-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"region":  "test-region",
+		"profile": "payments",
+		"assume_role": map[string]any{
+			"role_arn":     "arn:aws:iam::123456789012:role/kusion",
+			"session_name": "kusion",
+		},
+	}, res.Extensions["providerMeta"])
+	assert.Equal(t, map[string]any{"region": "test-region"}, (&MySQL{}).awsProviderMeta("test-region"))
+}
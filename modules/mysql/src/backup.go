@@ -0,0 +1,349 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	alicloudDBBackupPolicy = "alicloud_db_backup_policy"
+
+	binlogJobSuffix       = "-binlog-retention-"
+	exportSuffix          = "-export"
+	exportDumpDir         = "/dump"
+	exportDestinationEnv  = "EXPORT_DESTINATION"
+	defaultUploadImage    = "amazon/aws-cli:2.15.0"
+	maxBinlogRetention    = 168
+	backupJobBackoffLimit = 10
+	// backupJobUser is the uid of the mysql user in the official MySQL images.
+	backupJobUser = 999
+)
+
+var (
+	ErrInvalidBinlogRetention   = errors.New("mysql binlogRetentionHours must be between 0 and 168")
+	ErrEmptyExportSchedule      = errors.New("empty schedule of mysql export")
+	ErrEmptyExportCredentials   = errors.New("empty credentialsSecret of mysql export")
+	ErrInvalidExportDestination = errors.New("invalid destination of mysql export, must be s3://<bucket>[/<prefix>] or oss://<bucket>[/<prefix>]")
+	ErrEmptyExportEndpoint      = errors.New("empty endpoint of mysql export to oss")
+)
+
+// ExportConfig describes the logical backups of the MySQL database exported to the bucket of the
+// S3 compatible object storage, which are dumped by mysqldump and uploaded by the AWS CLI in a
+// Kubernetes CronJob.
+type ExportConfig struct {
+	// The schedule of the exports in the cron format, e.g. "0 3 * * *".
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	// The bucket and the path prefix of the dumps, e.g. s3://backups/mysql or oss://backups/mysql.
+	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
+	// The endpoint of the object storage, which is required for oss, e.g.
+	// https://oss-cn-hangzhou.aliyuncs.com.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// The name of the Secret storing the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the bucket.
+	CredentialsSecret string `json:"credentialsSecret,omitempty" yaml:"credentialsSecret,omitempty"`
+	// The image running mysqldump, which defaults to the MySQL image of the database version.
+	DumpImage string `json:"dumpImage,omitempty" yaml:"dumpImage,omitempty"`
+	// The image running the AWS CLI uploading the dumps, which defaults to amazon/aws-cli.
+	UploadImage string `json:"uploadImage,omitempty" yaml:"uploadImage,omitempty"`
+}
+
+// completeExportConfig fills the unset fields of the export config with the defaults.
+func completeExportConfig(export *ExportConfig) {
+	if export == nil {
+		return
+	}
+	if export.UploadImage == "" {
+		export.UploadImage = defaultUploadImage
+	}
+	export.Destination = strings.TrimSuffix(export.Destination, "/")
+}
+
+// validateBackupConfig validates the binlog retention and the export config of the MySQL instance.
+func (mysql *MySQL) validateBackupConfig() error {
+	if mysql.BinlogRetentionHours < 0 || mysql.BinlogRetentionHours > maxBinlogRetention {
+		return ErrInvalidBinlogRetention
+	}
+
+	export := mysql.Export
+	if export == nil {
+		return nil
+	}
+	if export.Schedule == "" {
+		return ErrEmptyExportSchedule
+	}
+	if export.CredentialsSecret == "" {
+		return ErrEmptyExportCredentials
+	}
+	scheme, bucket, _ := strings.Cut(export.Destination, "://")
+	if (scheme != "s3" && scheme != "oss") || bucket == "" || strings.HasPrefix(bucket, "/") {
+		return fmt.Errorf("%w, %w", ErrInvalidExportDestination, &moduleutil.ConfigFieldError{
+			Path:   "export.destination",
+			Reason: export.Destination + " is not a bucket of s3 or oss",
+		})
+	}
+	if scheme == "oss" && export.Endpoint == "" {
+		return ErrEmptyExportEndpoint
+	}
+	return nil
+}
+
+// binlogArgs returns the arguments of the local MySQL server retaining the binary logs for the
+// hours. The binary logs are disabled by default before MySQL 8.0, which are enabled along with
+// the retention in days.
+func (mysql *MySQL) binlogArgs() []string {
+	if mysql.BinlogRetentionHours == 0 {
+		return nil
+	}
+	if strings.HasPrefix(mysql.Version, "5.") {
+		days := (mysql.BinlogRetentionHours + 23) / 24
+		return []string{"--log-bin=mysql-bin", "--server-id=1", "--expire-logs-days=" + strconv.Itoa(days)}
+	}
+	return []string{"--binlog-expire-logs-seconds=" + strconv.Itoa(mysql.BinlogRetentionHours*3600)}
+}
+
+// generateAlicloudDBBackupPolicy generates alicloud_db_backup_policy resource retaining the binary
+// logs of the Alicloud provided MySQL database instance for the hours.
+func (mysql *MySQL) generateAlicloudDBBackupPolicy(alicloudProviderCfg module.ProviderConfig,
+	region, dbInstanceID string,
+) (*kusionapiv1.Resource, error) {
+	resAttrs := map[string]interface{}{
+		"instance_id":               module.KusionPathDependency(dbInstanceID, "id"),
+		"enable_backup_log":         true,
+		"local_log_retention_hours": mysql.BinlogRetentionHours,
+	}
+
+	id, err := module.TerraformResourceID(alicloudProviderCfg, alicloudDBBackupPolicy, mysql.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	alicloudProviderCfg.ProviderMeta = mysql.alicloudProviderMeta(region)
+	return module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudDBBackupPolicy, id, resAttrs, nil)
+}
+
+// GenerateBackupResources generates the Kubernetes Job setting the binlog retention hours of the AWS
+// provided MySQL instance, which is only configurable with the stored procedure of RDS, and the
+// CronJob exporting the logical backups to the bucket. The resources connect with the credentials
+// in the database Secret.
+func (mysql *MySQL) GenerateBackupResources(request *module.GeneratorRequest, providerType string, resources []kusionapiv1.Resource) ([]kusionapiv1.Resource, error) {
+	var dependsOn []string
+	for _, res := range resources {
+		if _, ok := res.Extensions[moduleutil.OutputsExtensionKey]; ok {
+			dependsOn = append(dependsOn, res.ID)
+		}
+	}
+
+	var backupResources []kusionapiv1.Resource
+	if mysql.BinlogRetentionHours > 0 && strings.ToLower(providerType) == "aws" {
+		job, err := mysql.generateBinlogRetentionJob(request, dependsOn)
+		if err != nil {
+			return nil, err
+		}
+		backupResources = append(backupResources, *job)
+	}
+	if mysql.Export != nil {
+		cronJob, err := mysql.generateExportCronJob(request, dependsOn)
+		if err != nil {
+			return nil, err
+		}
+		backupResources = append(backupResources, *cronJob)
+	}
+
+	return backupResources, nil
+}
+
+// generateBinlogRetentionJob generates the Kubernetes Job calling mysql.rds_set_configuration. The
+// Job is named after the hours, so that a new Job is created once they change, as the template of
+// the Job is immutable.
+func (mysql *MySQL) generateBinlogRetentionJob(request *module.GeneratorRequest, dependsOn []string) (*kusionapiv1.Resource, error) {
+	statement := fmt.Sprintf("CALL mysql.rds_set_configuration('binlog retention hours', %d);", mysql.BinlogRetentionHours)
+	backoffLimit := int32(backupJobBackoffLimit)
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mysql.DatabaseName + binlogJobSuffix + strconv.Itoa(mysql.BinlogRetentionHours),
+			Namespace: request.Project,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				Spec: mysql.backupPodSpec(nil, []v1.Container{
+					{
+						Name:    dbEngine,
+						Image:   dbEngine + ":" + mysql.Version,
+						Command: []string{"sh", "-c", `mysql -h "$MYSQL_HOST" -P "$MYSQL_TCP_PORT" -u "$MYSQL_USER" -e "` + statement + `"`},
+						Env:     mysql.backupDBEnv(request),
+					},
+				}, nil),
+			},
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(job.TypeMeta, job.ObjectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, job)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = dependsOn
+
+	return resource, nil
+}
+
+// generateExportCronJob generates the Kubernetes CronJob exporting the logical backups, where the
+// init container dumps all the databases into the shared volume, and the container uploads the
+// dump to the bucket named after the time of the export.
+func (mysql *MySQL) generateExportCronJob(request *module.GeneratorRequest, dependsOn []string) (*kusionapiv1.Resource, error) {
+	export := mysql.Export
+
+	dumpImage := dbEngine + ":" + mysql.Version
+	if export.DumpImage != "" {
+		dumpImage = export.DumpImage
+	}
+	dumpFile := exportDumpDir + "/" + mysql.DatabaseName + ".sql"
+	dump := `set -e; mysqldump -h "$MYSQL_HOST" -P "$MYSQL_TCP_PORT" -u "$MYSQL_USER" --all-databases ` +
+		`--single-transaction --routines --triggers --events > ` + dumpFile + `; gzip -f ` + dumpFile
+
+	// The dumps to oss are uploaded with the S3 compatible API of OSS, which only supports the
+	// virtual hosted style of the bucket addresses.
+	destination := export.Destination
+	upload := "set -e; "
+	var uploadArgs string
+	if scheme, bucket, _ := strings.Cut(destination, "://"); scheme == "oss" {
+		destination = "s3://" + bucket
+		upload += "aws configure set default.s3.addressing_style virtual; "
+	}
+	if export.Endpoint != "" {
+		uploadArgs = " --endpoint-url " + export.Endpoint
+	}
+	upload += `aws s3 cp ` + dumpFile + `.gz "$` + exportDestinationEnv + `/` + mysql.DatabaseName +
+		`-$(date -u +%Y%m%dT%H%M%SZ).sql.gz"` + uploadArgs
+
+	uploadEnv := []v1.EnvVar{
+		{Name: exportDestinationEnv, Value: destination},
+		// The home directory of the AWS CLI config is the shared volume writable by the non-root user.
+		{Name: "HOME", Value: exportDumpDir},
+	}
+	if mysql.Region != "" {
+		uploadEnv = append(uploadEnv, v1.EnvVar{Name: "AWS_DEFAULT_REGION", Value: mysql.Region})
+	}
+	volumeMounts := []v1.VolumeMount{{Name: "dump", MountPath: exportDumpDir}}
+	backoffLimit := int32(backupJobBackoffLimit)
+
+	cronJob := &batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mysql.DatabaseName + exportSuffix,
+			Namespace: request.Project,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          export.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: v1.PodTemplateSpec{
+						Spec: mysql.backupPodSpec(
+							[]v1.Container{
+								{
+									Name:         "mysqldump",
+									Image:        dumpImage,
+									Command:      []string{"sh", "-c", dump},
+									Env:          mysql.backupDBEnv(request),
+									VolumeMounts: volumeMounts,
+								},
+							},
+							[]v1.Container{
+								{
+									Name:    "upload",
+									Image:   export.UploadImage,
+									Command: []string{"sh", "-c", upload},
+									Env:     uploadEnv,
+									EnvFrom: []v1.EnvFromSource{
+										{SecretRef: &v1.SecretEnvSource{LocalObjectReference: v1.LocalObjectReference{Name: export.CredentialsSecret}}},
+									},
+									VolumeMounts: volumeMounts,
+								},
+							},
+							[]v1.Volume{{Name: "dump", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}},
+						),
+					},
+				},
+			},
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(cronJob.TypeMeta, cronJob.ObjectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, cronJob)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = dependsOn
+
+	return resource, nil
+}
+
+// backupDBEnv returns the environment variables of the mysql clients connecting with the
+// credentials in the database Secret.
+func (mysql *MySQL) backupDBEnv(request *module.GeneratorRequest) []v1.EnvVar {
+	secretName := moduleutil.OutputSecretName(request, dbEngine)
+	secretEnv := func(name, key string) v1.EnvVar {
+		return v1.EnvVar{
+			Name: name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	return []v1.EnvVar{
+		secretEnv("MYSQL_HOST", "hostAddress"),
+		secretEnv("MYSQL_TCP_PORT", "port"),
+		secretEnv("MYSQL_USER", "username"),
+		secretEnv("MYSQL_PWD", "password"),
+	}
+}
+
+// backupPodSpec returns the spec of the pods of the backup Jobs, which run as the non-root user
+// under the restricted pod security standard.
+func (mysql *MySQL) backupPodSpec(initContainers, containers []v1.Container, volumes []v1.Volume) v1.PodSpec {
+	runAsUser := int64(backupJobUser)
+	runAsNonRoot, allowPrivilegeEscalation := true, false
+	for _, containers := range [][]v1.Container{initContainers, containers} {
+		for i := range containers {
+			containers[i].SecurityContext = &v1.SecurityContext{
+				AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+				Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+			}
+		}
+	}
+
+	return v1.PodSpec{
+		RestartPolicy: v1.RestartPolicyOnFailure,
+		SecurityContext: &v1.PodSecurityContext{
+			RunAsUser:      &runAsUser,
+			RunAsNonRoot:   &runAsNonRoot,
+			FSGroup:        &runAsUser,
+			SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+		},
+		InitContainers: initContainers,
+		Containers:     containers,
+		Volumes:        volumes,
+	}
+}
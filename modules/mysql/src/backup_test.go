@@ -0,0 +1,151 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+func TestMySQLModule_GetCompleteConfigBackup(t *testing.T) {
+	tests := []struct {
+		name           string
+		platformConfig kusionapiv1.GenericConfig
+		expectedExport *ExportConfig
+		expectedErr    error
+	}{
+		{
+			name: "export to s3",
+			platformConfig: kusionapiv1.GenericConfig{
+				"binlogRetentionHours": 24,
+				"export": map[string]interface{}{
+					"schedule":          "0 3 * * *",
+					"destination":       "s3://backups/mysql/",
+					"credentialsSecret": "backup-credentials",
+				},
+			},
+			expectedExport: &ExportConfig{
+				Schedule:          "0 3 * * *",
+				Destination:       "s3://backups/mysql",
+				CredentialsSecret: "backup-credentials",
+				UploadImage:       defaultUploadImage,
+			},
+		},
+		{
+			name:           "binlog retention over a week",
+			platformConfig: kusionapiv1.GenericConfig{"binlogRetentionHours": 169},
+			expectedErr:    ErrInvalidBinlogRetention,
+		},
+		{
+			name: "empty schedule",
+			platformConfig: kusionapiv1.GenericConfig{
+				"export": map[string]interface{}{"destination": "s3://backups", "credentialsSecret": "backup-credentials"},
+			},
+			expectedErr: ErrEmptyExportSchedule,
+		},
+		{
+			name: "empty credentials",
+			platformConfig: kusionapiv1.GenericConfig{
+				"export": map[string]interface{}{"schedule": "0 3 * * *", "destination": "s3://backups"},
+			},
+			expectedErr: ErrEmptyExportCredentials,
+		},
+		{
+			name: "invalid destination",
+			platformConfig: kusionapiv1.GenericConfig{
+				"export": map[string]interface{}{"schedule": "0 3 * * *", "destination": "gs://backups", "credentialsSecret": "backup-credentials"},
+			},
+			expectedErr: ErrInvalidExportDestination,
+		},
+		{
+			name: "oss without endpoint",
+			platformConfig: kusionapiv1.GenericConfig{
+				"export": map[string]interface{}{"schedule": "0 3 * * *", "destination": "oss://backups", "credentialsSecret": "backup-credentials"},
+			},
+			expectedErr: ErrEmptyExportEndpoint,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mysql := &MySQL{}
+			err := mysql.GetCompleteConfig(kusionapiv1.Accessory{"type": "local", "version": "8.0"}, tt.platformConfig)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 24, mysql.BinlogRetentionHours)
+			assert.Equal(t, tt.expectedExport, mysql.Export)
+		})
+	}
+}
+
+func TestMySQLModule_BinlogArgs(t *testing.T) {
+	assert.Nil(t, (&MySQL{Version: "8.0"}).binlogArgs())
+	assert.Equal(t, []string{"--binlog-expire-logs-seconds=86400"}, (&MySQL{Version: "8.0", BinlogRetentionHours: 24}).binlogArgs())
+	assert.Equal(t, []string{"--log-bin=mysql-bin", "--server-id=1", "--expire-logs-days=2"},
+		(&MySQL{Version: "5.7", BinlogRetentionHours: 25}).binlogArgs())
+}
+
+func TestMySQLModule_GenerateBackupResources(t *testing.T) {
+	request := &module.GeneratorRequest{Project: "test-project"}
+	dbSecret := kusionapiv1.Resource{ID: "v1:Secret:test-project:test-database-mysql", Extensions: map[string]interface{}{moduleutil.OutputsExtensionKey: map[string]interface{}{}}}
+
+	t.Run("binlog retention of aws", func(t *testing.T) {
+		mysql := &MySQL{DatabaseName: "test-database", Version: "8.0", BinlogRetentionHours: 48}
+
+		resources, err := mysql.GenerateBackupResources(request, "aws", []kusionapiv1.Resource{dbSecret})
+		assert.NoError(t, err)
+		if !assert.Len(t, resources, 1) {
+			return
+		}
+		assert.Equal(t, "batch/v1:Job:test-project:test-database-binlog-retention-48", resources[0].ID)
+		assert.Equal(t, []string{dbSecret.ID}, resources[0].DependsOn)
+		assert.Contains(t, resources[0].Attributes["spec"], "template")
+
+		resources, err = mysql.GenerateBackupResources(request, "alicloud", []kusionapiv1.Resource{dbSecret})
+		assert.NoError(t, err)
+		assert.Empty(t, resources)
+	})
+
+	t.Run("export to oss", func(t *testing.T) {
+		mysql := &MySQL{
+			DatabaseName: "test-database",
+			Version:      "8.0",
+			Export: &ExportConfig{
+				Schedule:          "0 3 * * *",
+				Destination:       "oss://backups/mysql",
+				Endpoint:          "https://oss-cn-hangzhou.aliyuncs.com",
+				CredentialsSecret: "backup-credentials",
+				UploadImage:       defaultUploadImage,
+			},
+		}
+
+		resources, err := mysql.GenerateBackupResources(request, "alicloud", []kusionapiv1.Resource{dbSecret})
+		assert.NoError(t, err)
+		if !assert.Len(t, resources, 1) {
+			return
+		}
+		assert.Equal(t, "batch/v1:CronJob:test-project:test-database-export", resources[0].ID)
+
+		podSpec := resources[0].Attributes["spec"].(map[string]interface{})["jobTemplate"].(map[string]interface{})["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})
+		upload := podSpec["containers"].([]interface{})[0].(map[string]interface{})
+		command := upload["command"].([]interface{})[2].(string)
+		assert.Contains(t, command, "default.s3.addressing_style virtual")
+		assert.Contains(t, command, "--endpoint-url https://oss-cn-hangzhou.aliyuncs.com")
+		assert.Contains(t, upload["env"], map[string]interface{}{"name": exportDestinationEnv, "value": "s3://backups/mysql"})
+	})
+}
+
+func TestMySQLModule_GenerateAlicloudDBBackupPolicy(t *testing.T) {
+	mysql := &MySQL{DatabaseName: "test-database", BinlogRetentionHours: 72}
+
+	resource, err := mysql.generateAlicloudDBBackupPolicy(defaultAlicloudProviderCfg, "cn-hangzhou", "aliyun:alicloud:alicloud_db_instance:test-database")
+	assert.NoError(t, err)
+	assert.Equal(t, alicloudDBBackupPolicy, resource.Extensions["resourceType"])
+	assert.Equal(t, true, resource.Attributes["enable_backup_log"])
+	assert.Equal(t, 72, resource.Attributes["local_log_retention_hours"])
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestMySQLModule_ConnectionInfo(t *testing.T) {
+	builder := testutil.NewRequest().
+		WithServiceWorkload("Deployment").
+		WithDevConfig(kusionapiv1.Accessory{"type": LocalDBType, "version": "8.0"}).
+		WithPlatformConfig(kusionapiv1.GenericConfig{"databaseName": "foo-db"})
+
+	response, err := (&MySQL{}).Generate(context.Background(), builder.Build())
+	assert.NoError(t, err)
+	for _, res := range response.Resources {
+		assert.Nil(t, moduleutil.ConnectionInfoData(res))
+	}
+
+	request := builder.WithContext(moduleutil.ConnectionInfoKey, true).Build()
+	response, err = (&MySQL{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	cm := response.Resources[len(response.Resources)-1]
+	assert.Equal(t, "v1:ConfigMap:"+request.Project+":foo-db-connection-info", cm.ID)
+	assert.Equal(t, map[string]string{
+		"mysql.host":       "foo-db-db-local-service",
+		"mysql.port":       "3306",
+		"mysql.secretName": moduleutil.OutputSecretName(request, dbEngine),
+	}, moduleutil.ConnectionInfoData(cm))
+}
@@ -12,6 +12,9 @@ require (
 	kusionstack.io/kusion v0.13.1-0.20241202025741-7b361d5e5899
 	kusionstack.io/kusion-api-go v0.13.0
 	kusionstack.io/kusion-module-framework v0.2.3-beta.6
+	dbutil v0.0.0
+	moduleutil v0.0.0
+	testutil v0.0.0
 )
 
 require (
@@ -59,3 +62,9 @@ require (
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.3 // indirect
 	sigs.k8s.io/yaml v1.4.0 // indirect
 )
+
+replace dbutil => ../../../dbutil
+
+replace moduleutil => ../../../moduleutil
+
+replace testutil => ../../../testutil
@@ -1,12 +1,11 @@
 package main
 
 import (
-	"crypto/md5"
-	"encoding/hex"
 	"strconv"
 
 	"kusionstack.io/kusion-module-framework/pkg/module"
 
+	"dbutil"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -140,7 +139,7 @@ func (mysql *MySQL) generateLocalPodSpec(_ *module.GeneratorRequest) (v1.PodSpec
 	ports := []v1.ContainerPort{
 		{
 			Name:          portName,
-			ContainerPort: int32(3306),
+			ContainerPort: int32(dbPort),
 		},
 	}
 
@@ -196,11 +195,19 @@ func (mysql *MySQL) generateLocalPodSpec(_ *module.GeneratorRequest) (v1.PodSpec
 		}
 	}
 
+	// Refuse the connections not over TLS, which are served with the certificates generated by the
+	// server on the initialization.
+	args := mysql.binlogArgs()
+	if mysql.requireSecureTransport() {
+		args = append(args, "--require-secure-transport=ON")
+	}
+
 	podSpec := v1.PodSpec{
 		Containers: []v1.Container{
 			{
 				Name:         mysql.DatabaseName,
 				Image:        image,
+				Args:         args,
 				Env:          env,
 				Ports:        ports,
 				VolumeMounts: volumeMounts,
@@ -283,7 +290,7 @@ func (mysql *MySQL) generateLocalService(request *module.GeneratorRequest) (*kus
 func (mysql *MySQL) generateLocalSvcPort() []v1.ServicePort {
 	svcPort := []v1.ServicePort{
 		{
-			Port: int32(3306),
+			Port: int32(dbPort),
 		},
 	}
 
@@ -299,10 +306,5 @@ func (mysql *MySQL) generateLocalMatchLabels() map[string]string {
 
 // generateLocalPassword generates a fixed password string with the specified length for the local MySQL instance.
 func (mysql *MySQL) generateLocalPassword(request *module.GeneratorRequest) string {
-	hashInput := request.Project + request.Stack + request.App + mysql.DatabaseName
-	hash := md5.Sum([]byte(hashInput))
-
-	hashString := hex.EncodeToString(hash[:])
-
-	return hashString[:16]
+	return dbutil.LocalPassword(request, mysql.DatabaseName)
 }
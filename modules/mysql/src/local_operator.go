@@ -0,0 +1,281 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// OperatorMySQL is the MySQL Operator for Kubernetes managing the local MySQL InnoDB Clusters.
+const OperatorMySQL = "mysql-operator"
+
+const (
+	innoDBClusterAPIVersion        = "mysql.oracle.com/v2"
+	operatorSecretSuffix           = "-db-cluster-secret"
+	operatorBackupProfile          = "s3-backup"
+	operatorRootHost               = "%"
+	defaultOperatorInstances       = 3
+	defaultOperatorRouterInstances = 1
+)
+
+var (
+	ErrUnsupportedOperator            = errors.New("unsupported mysql operator, only mysql-operator is supported")
+	ErrOperatorForCloudDB             = errors.New("mysql operator is only supported for the local mysql instance")
+	ErrInvalidOperatorInstances       = errors.New("mysql operator instances must be positive")
+	ErrInvalidOperatorRouterInstances = errors.New("mysql operator router instances must be positive")
+	ErrEmptyBackupBucket              = errors.New("empty bucketName or credentialsSecret of mysql backup")
+)
+
+// OperatorConfig describes the operator managing the local MySQL cluster, which provides the group
+// replication, failover and backup instead of the single-instance Deployment.
+type OperatorConfig struct {
+	// The operator managing the MySQL cluster, only mysql-operator is supported.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// The number of the MySQL server instances, one as the primary and the others as the secondaries.
+	Instances int `json:"instances,omitempty" yaml:"instances,omitempty"`
+	// The number of the MySQL Router instances routing the connections to the primary.
+	RouterInstances int `json:"routerInstances,omitempty" yaml:"routerInstances,omitempty"`
+	// The full version of the MySQL server, e.g. 8.0.36, which defaults to the version of the
+	// operator.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// The storage class of the data volumes of the MySQL server instances.
+	StorageClass string `json:"storageClass,omitempty" yaml:"storageClass,omitempty"`
+	// The backup of the MySQL cluster to the object storage.
+	Backup *BackupConfig `json:"backup,omitempty" yaml:"backup,omitempty"`
+}
+
+// BackupConfig describes the dump backup of the MySQL cluster to the S3 compatible object storage.
+type BackupConfig struct {
+	// The bucket of the object storage to store the dumps.
+	BucketName string `json:"bucketName,omitempty" yaml:"bucketName,omitempty"`
+	// The path prefix of the dumps in the bucket.
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	// The endpoint of the S3 compatible object storage, empty for AWS S3.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// The name of the Secret storing the credentials and config files of the object storage.
+	CredentialsSecret string `json:"credentialsSecret,omitempty" yaml:"credentialsSecret,omitempty"`
+	// The schedule of the dumps in the cron format, e.g. "0 0 * * *".
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+// completeOperatorConfig fills the unset fields of the operator config with the defaults.
+func completeOperatorConfig(operator *OperatorConfig) {
+	if operator == nil {
+		return
+	}
+	if operator.Type == "" {
+		operator.Type = OperatorMySQL
+	}
+	if operator.Instances == 0 {
+		operator.Instances = defaultOperatorInstances
+	}
+	if operator.RouterInstances == 0 {
+		operator.RouterInstances = defaultOperatorRouterInstances
+	}
+}
+
+// validateOperatorConfig validates the operator config of the MySQL instance.
+func (mysql *MySQL) validateOperatorConfig() error {
+	operator := mysql.Operator
+	if operator == nil {
+		return nil
+	}
+	if strings.ToLower(mysql.Type) != LocalDBType {
+		return ErrOperatorForCloudDB
+	}
+	if operator.Type != OperatorMySQL {
+		return ErrUnsupportedOperator
+	}
+	if operator.Instances < 0 {
+		return ErrInvalidOperatorInstances
+	}
+	if operator.RouterInstances < 0 {
+		return ErrInvalidOperatorRouterInstances
+	}
+	if operator.Backup != nil && (operator.Backup.BucketName == "" || operator.Backup.CredentialsSecret == "") {
+		return ErrEmptyBackupBucket
+	}
+	return nil
+}
+
+// GenerateOperatorResources generates the resources of the local MySQL InnoDB Cluster managed by
+// the MySQL Operator, which is expected to be installed in the Kubernetes cluster.
+func (mysql *MySQL) GenerateOperatorResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, *kusionapiv1.Patcher, error) {
+	var resources []kusionapiv1.Resource
+
+	// Build Kubernetes Secret for the root account of the MySQL cluster with the random password.
+	password := mysql.generateLocalPassword(request)
+	clusterSecret, err := mysql.generateOperatorSecret(request, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *clusterSecret)
+
+	// Build the InnoDBCluster of the MySQL server and router instances.
+	cluster, err := mysql.generateOperatorCluster(request, clusterSecret.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *cluster)
+
+	// Build Kubernetes Secret with the hostAddress, username and password of the MySQL cluster,
+	// where the Service of the cluster created by the operator routes the connections to the primary.
+	hostAddress := mysql.DatabaseName
+	dbSecret, patcher, err := mysql.GenerateDBSecret(request, hostAddress, mysql.Username, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *dbSecret)
+
+	return resources, patcher, nil
+}
+
+// generateOperatorSecret generates the Kubernetes Secret resource of the root account, in the keys
+// required by the InnoDBCluster.
+func (mysql *MySQL) generateOperatorSecret(request *module.GeneratorRequest, password string) (*kusionapiv1.Resource, error) {
+	data := make(map[string]string)
+	data["rootUser"] = mysql.Username
+	data["rootHost"] = operatorRootHost
+	data["rootPassword"] = password
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mysql.DatabaseName + operatorSecretSuffix,
+			Namespace: request.Project,
+		},
+		StringData: data,
+	}
+
+	resourceID := module.KubernetesResourceID(secret.TypeMeta, secret.ObjectMeta)
+	return module.WrapK8sResourceToKusionResource(resourceID, secret)
+}
+
+// generateOperatorCluster generates the InnoDBCluster resource of the MySQL instances.
+func (mysql *MySQL) generateOperatorCluster(request *module.GeneratorRequest, secretID string) (*kusionapiv1.Resource, error) {
+	operator := mysql.Operator
+
+	volumeClaim := map[string]interface{}{
+		"accessModes": []interface{}{"ReadWriteOnce"},
+		"resources": map[string]interface{}{
+			"requests": map[string]interface{}{
+				"storage": strconv.Itoa(mysql.Size) + "Gi",
+			},
+		},
+	}
+	if operator.StorageClass != "" {
+		volumeClaim["storageClassName"] = operator.StorageClass
+	}
+
+	spec := map[string]interface{}{
+		"secretName":       mysql.DatabaseName + operatorSecretSuffix,
+		"tlsUseSelfSigned": true,
+		"instances":        int64(operator.Instances),
+		"router": map[string]interface{}{
+			"instances": int64(operator.RouterInstances),
+		},
+		"datadirVolumeClaimTemplate": volumeClaim,
+	}
+	if operator.Version != "" {
+		spec["version"] = operator.Version
+	}
+	if mycnf := mysql.mycnf(); mycnf != "" {
+		spec["mycnf"] = mycnf
+	}
+	if backup := operator.Backup; backup != nil {
+		s3 := map[string]interface{}{
+			"bucketName": backup.BucketName,
+			"config":     backup.CredentialsSecret,
+		}
+		if backup.Prefix != "" {
+			s3["prefix"] = backup.Prefix
+		}
+		if backup.Endpoint != "" {
+			s3["endpoint"] = backup.Endpoint
+		}
+		spec["backupProfiles"] = []interface{}{
+			map[string]interface{}{
+				"name": operatorBackupProfile,
+				"dumpInstance": map[string]interface{}{
+					"storage": map[string]interface{}{
+						"s3": s3,
+					},
+				},
+			},
+		}
+		if backup.Schedule != "" {
+			spec["backupSchedules"] = []interface{}{
+				map[string]interface{}{
+					"name":              operatorBackupProfile,
+					"schedule":          backup.Schedule,
+					"backupProfileName": operatorBackupProfile,
+					"enabled":           true,
+				},
+			}
+		}
+	}
+
+	cluster := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": innoDBClusterAPIVersion,
+			"kind":       "InnoDBCluster",
+			"metadata": map[string]interface{}{
+				"name":      mysql.DatabaseName,
+				"namespace": request.Project,
+				"labels":    toInterfaceMap(mysql.generateLocalMatchLabels()),
+			},
+			"spec": spec,
+		},
+	}
+
+	resource, err := wrapUnstructured(cluster)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = []string{secretID}
+
+	return resource, nil
+}
+
+// mycnf returns the options of the MySQL servers managed by the operator, e.g. the retention of
+// the binary logs.
+func (mysql *MySQL) mycnf() string {
+	var options []string
+	if mysql.BinlogRetentionHours > 0 {
+		options = append(options, fmt.Sprintf("binlog_expire_logs_seconds=%d", mysql.BinlogRetentionHours*3600))
+	}
+	if mysql.requireSecureTransport() {
+		options = append(options, requireSecureTransportParam+"=ON")
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	return "[mysqld]\n" + strings.Join(options, "\n") + "\n"
+}
+
+// wrapUnstructured wraps the unstructured Kubernetes object into the Kusion resource.
+func wrapUnstructured(obj *unstructured.Unstructured) (*kusionapiv1.Resource, error) {
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind()},
+		metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace()},
+	)
+	return module.WrapK8sResourceToKusionResource(resourceID, obj)
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestMySQLModule_GetCompleteConfigOperator(t *testing.T) {
+	tests := []struct {
+		name             string
+		devConfig        kusionapiv1.Accessory
+		platformConfig   kusionapiv1.GenericConfig
+		expectedOperator *OperatorConfig
+		expectedErr      error
+	}{
+		{
+			name:      "default operator config",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{},
+			},
+			expectedOperator: &OperatorConfig{
+				Type:            OperatorMySQL,
+				Instances:       defaultOperatorInstances,
+				RouterInstances: defaultOperatorRouterInstances,
+			},
+		},
+		{
+			name:      "unsupported operator",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{"type": "percona-xtradb-cluster"},
+			},
+			expectedErr: ErrUnsupportedOperator,
+		},
+		{
+			name:      "operator for cloud mysql",
+			devConfig: kusionapiv1.Accessory{"type": "cloud", "version": "8.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"instanceType": "mysql.n2.serverless.1c",
+				"operator":     map[string]interface{}{},
+			},
+			expectedErr: ErrOperatorForCloudDB,
+		},
+		{
+			name:      "negative instances",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{"instances": -1},
+			},
+			expectedErr: ErrInvalidOperatorInstances,
+		},
+		{
+			name:      "negative router instances",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{"routerInstances": -1},
+			},
+			expectedErr: ErrInvalidOperatorRouterInstances,
+		},
+		{
+			name:      "backup without credentials",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{
+					"backup": map[string]interface{}{"bucketName": "backups"},
+				},
+			},
+			expectedErr: ErrEmptyBackupBucket,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mysql := &MySQL{}
+			err := mysql.GetCompleteConfig(tt.devConfig, tt.platformConfig)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOperator, mysql.Operator)
+		})
+	}
+}
@@ -6,16 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"runtime/debug"
+	"os"
+	"slices"
 	"strings"
 
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"dbutil"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
 	"kusionstack.io/kusion/pkg/workspace"
+	"moduleutil"
 )
 
 const (
@@ -24,16 +25,20 @@ const (
 )
 
 const (
-	dbEngine         = "mysql"
-	dbResSuffix      = "-mysql"
-	dbHostAddressEnv = "KUSION_DB_HOST"
-	dbUsernameEnv    = "KUSION_DB_USERNAME"
-	dbPasswordEnv    = "KUSION_DB_PASSWORD"
+	dbEngine    = "mysql"
+	dbResSuffix = "-mysql"
+	dbPort      = 3306
+
+	// secretChecksumAnnotationPrefix is the prefix of the pod annotation holding the checksum of
+	// the database Secret, which is followed by the name of the Secret.
+	secretChecksumAnnotationPrefix = "checksum.kusionstack.io/"
 )
 
 var (
 	ErrEmptyInstanceTypeForCloudDB = errors.New("empty instance type for cloud managed mysql instance")
 	ErrEmptyCloudProviderType      = errors.New("empty cloud provider type in mysql module config")
+	ErrPublicAccessInProd          = errors.New("cloud managed mysql instance open to the internet in prod")
+	ErrPasswordVersionForLocalDB   = errors.New("mysql passwordVersion is only supported for the cloud managed mysql instance")
 )
 
 var (
@@ -51,13 +56,6 @@ var (
 	defaultSize           int      = 10
 )
 
-var defaultRandomProviderCfg = module.ProviderConfig{
-	Source:  "hashicorp/random",
-	Version: "3.6.0",
-}
-
-var randomPassword = "random_password"
-
 // MySQL describes the attributes to locally deploy or create a cloud provider
 // managed MySQL database instance for the workload.
 type MySQL struct {
@@ -73,6 +71,8 @@ type MySQL struct {
 	Category string `json:"category,omitempty" yaml:"category,omitempty"`
 	// The operation account for the MySQL database.
 	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// The version of the password of the cloud provided MySQL instance, which is bumped to rotate it.
+	PasswordVersion string `json:"passwordVersion,omitempty" yaml:"passwordVersion,omitempty"`
 	// The list of IP addresses allowed to access the MySQL instance provided by the cloud vendor.
 	SecurityIPs []string `json:"securityIPs,omitempty" yaml:"securityIPs,omitempty"`
 	// The virtual subnet ID associated with the VPC that the cloud MySQL instance will be created in.
@@ -82,6 +82,91 @@ type MySQL struct {
 	PrivateRouting bool `json:"privateRouting,omitempty" yaml:"privateRouting,omitempty"`
 	// The specified name of the MySQL database instance.
 	DatabaseName string `json:"databaseName,omitempty" yaml:"databaseName,omitempty"`
+	// The operator managing the local MySQL cluster with high availability.
+	Operator *OperatorConfig `json:"operator,omitempty" yaml:"operator,omitempty"`
+	// The encryption at rest and in transit of the MySQL instance.
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// The hours of retaining the binary logs of the MySQL instance, at most 168.
+	BinlogRetentionHours int `json:"binlogRetentionHours,omitempty" yaml:"binlogRetentionHours,omitempty"`
+	// The logical backups of the MySQL database exported to the bucket of the object storage.
+	Export *ExportConfig `json:"export,omitempty" yaml:"export,omitempty"`
+	// The ARN of the IAM or RAM role assumed by the cloud provider, e.g. to provision in another account.
+	AssumeRoleARN string `json:"assumeRoleARN,omitempty" yaml:"assumeRoleARN,omitempty"`
+	// The session name of the assumed IAM role.
+	SessionName string `json:"sessionName,omitempty" yaml:"sessionName,omitempty"`
+	// The named profile in the shared credentials of the AWS provider.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// The custom endpoints of the Alicloud services by the service codes, e.g. rds.
+	Endpoints map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// The STS security token of the temporary credentials of the Alicloud provider.
+	SecurityToken string `json:"securityToken,omitempty" yaml:"securityToken,omitempty"`
+	// The region of the cloud provider resolved from the dev config and platform config.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// DevConfig describes the dev config of the mysql module declared by the application.
+type DevConfig struct {
+	// The deployment mode of the MySQL database.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// The MySQL database version to use.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// The region of the cloud provider, which overrides the one in the platform config.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// The version of the password of the cloud provided MySQL instance, which is bumped to rotate it.
+	PasswordVersion string `json:"passwordVersion,omitempty" yaml:"passwordVersion,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the mysql module in workspace.
+type PlatformConfig struct {
+	// The cloud vendor providing the MySQL instance, aws or alicloud.
+	Cloud string `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	// The type of the MySQL instance.
+	InstanceType string `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	// The allocated storage size of the MySQL instance.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// The edition of the MySQL instance provided by the cloud vendor.
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	// The operation account for the MySQL database.
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// The list of IP addresses allowed to access the MySQL instance provided by the cloud vendor.
+	SecurityIPs []string `json:"securityIPs,omitempty" yaml:"securityIPs,omitempty"`
+	// The virtual subnet ID associated with the VPC that the cloud MySQL instance will be created in.
+	SubnetID string `json:"subnetID,omitempty" yaml:"subnetID,omitempty"`
+	// Whether the host address of the cloud MySQL instance is via private network.
+	PrivateRouting bool `json:"privateRouting,omitempty" yaml:"privateRouting,omitempty"`
+	// The specified name of the MySQL database instance.
+	DatabaseName string `json:"databaseName,omitempty" yaml:"databaseName,omitempty"`
+	// The operator managing the local MySQL cluster with high availability.
+	Operator *OperatorConfig `json:"operator,omitempty" yaml:"operator,omitempty"`
+	// The encryption at rest and in transit of the MySQL instance.
+	TLS *TLSConfig `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// The hours of retaining the binary logs of the MySQL instance, at most 168.
+	BinlogRetentionHours int `json:"binlogRetentionHours,omitempty" yaml:"binlogRetentionHours,omitempty"`
+	// The logical backups of the MySQL database exported to the bucket of the object storage.
+	Export *ExportConfig `json:"export,omitempty" yaml:"export,omitempty"`
+	// The ARN of the IAM or RAM role assumed by the cloud provider, e.g. to provision in another account.
+	AssumeRoleARN string `json:"assumeRoleARN,omitempty" yaml:"assumeRoleARN,omitempty"`
+	// The session name of the assumed IAM role.
+	SessionName string `json:"sessionName,omitempty" yaml:"sessionName,omitempty"`
+	// The named profile in the shared credentials of the AWS provider.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// The custom endpoints of the Alicloud services by the service codes, e.g. rds.
+	Endpoints map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// The STS security token of the temporary credentials of the Alicloud provider.
+	SecurityToken string `json:"securityToken,omitempty" yaml:"securityToken,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *DevConfig `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+	// The environment class of the workspace, dev, staging or prod, which the guardrails are keyed on.
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// The hints of the generated Terraform resources, e.g. the provider aliases.
+	Terraform *moduleutil.TerraformHints `json:"terraform,omitempty" yaml:"terraform,omitempty"`
+	// The topology of the workspace, e.g. the region of the cloud provider.
+	Topology *dbutil.Topology `json:"topology,omitempty" yaml:"topology,omitempty"`
 }
 
 func (mysql *MySQL) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
@@ -89,13 +174,27 @@ func (mysql *MySQL) Generate(ctx context.Context, request *module.GeneratorReque
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "mysql", &response, &err)
+
+	// Attach the connection info of the database, hint the Terraform resources with the provider
+	// aliases and state groups, and finalize the generated resources with the standard metadata,
+	// the policy and Pod Security Standards checks, and the preview summary if enabled in the
+	// workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate mysql module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in mysql module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			name := mysql.DatabaseName + "-connection-info"
+			if err = moduleutil.AttachConnectionInfo("mysql", name, request, response, moduleutil.DatabaseConnectionInfo(response)); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.ApplyTerraformHints(request, response); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("mysql", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -109,12 +208,17 @@ func (mysql *MySQL) Generate(ctx context.Context, request *module.GeneratorReque
 	// Get the complete configs of the MySQL instance.
 	err = mysql.GetCompleteConfig(request.DevConfig, request.PlatformConfig)
 	if err != nil {
-		return nil, err
+		return nil, moduleutil.NewModuleError("mysql", moduleutil.PhaseComplete, err)
+	}
+
+	// Refuse the configs not allowed in the environment class of the workspace.
+	if err = mysql.CheckGuardrails(request); err != nil {
+		return nil, moduleutil.NewModuleError("mysql", moduleutil.PhaseValidate, err)
 	}
 
 	// Set the database name.
 	if mysql.DatabaseName == "" {
-		mysql.DatabaseName = GenerateDefaultMySQLName(request.Project, request.Stack, request.App)
+		mysql.DatabaseName = GenerateDefaultMySQLName(request, mysql.Type)
 	}
 
 	// Generate the MySQL intance resources based on the type and the cloud provider config.
@@ -123,7 +227,14 @@ func (mysql *MySQL) Generate(ctx context.Context, request *module.GeneratorReque
 	var providerType string
 	switch strings.ToLower(mysql.Type) {
 	case LocalDBType:
-		resources, patcher, err = mysql.GenerateLocalResources(request)
+		if mysql.Operator != nil {
+			resources, patcher, err = mysql.GenerateOperatorResources(request)
+		} else {
+			resources, patcher, err = mysql.GenerateLocalResources(request)
+		}
+		if err != nil {
+			return nil, err
+		}
 	case CloudDBType:
 		providerType, err = GetCloudProviderType(request.PlatformConfig)
 		if err != nil {
@@ -148,6 +259,14 @@ func (mysql *MySQL) Generate(ctx context.Context, request *module.GeneratorReque
 		return nil, fmt.Errorf("unsupported mysql type: %s", mysql.Type)
 	}
 
+	// Build Kubernetes Job setting the binlog retention of the AWS provided instance, and the
+	// CronJob exporting the logical backups after the database Secret is created.
+	backupResources, err := mysql.GenerateBackupResources(request, providerType, resources)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, backupResources...)
+
 	return &module.GeneratorResponse{
 		Resources: resources,
 		Patcher:   patcher,
@@ -157,66 +276,47 @@ func (mysql *MySQL) Generate(ctx context.Context, request *module.GeneratorReque
 // GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
 // configuration for the MySQL instance.
 func (mysql *MySQL) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
-	// Set the default values for MySQL instance if platformConfig not exists.
-	if platformConfig == nil {
-		mysql.Username = defaultUsername
-		mysql.Category = defaultCategory
-		mysql.SecurityIPs = defaultSecurityIPs
-		mysql.PrivateRouting = defaultPrivateRouting
-		mysql.Size = defaultSize
-	}
-
-	// Get the type and version of the MySQL instance in devConfig.
-	if mysqlType, ok := devConfig["type"]; ok {
-		mysql.Type = mysqlType.(string)
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, DevConfig{}); err != nil {
+		return moduleutil.NewModuleError("mysql", moduleutil.PhaseValidate, fmt.Errorf("validate MySQL dev config failed, %w", err))
 	}
-	if mysqlVersion, ok := devConfig["version"]; ok {
-		mysql.Version = mysqlVersion.(string)
-	}
-
-	// Get the other configs of the MySQL instance in platformConfig,
-	// and use the default values if some of them don't exist.
-	if username, ok := platformConfig["username"]; ok {
-		mysql.Username = username.(string)
-	} else {
-		mysql.Username = defaultUsername
-	}
-
-	if category, ok := platformConfig["category"]; ok {
-		mysql.Category = category.(string)
-	} else {
-		mysql.Category = defaultCategory
-	}
-
-	if securityIPs, ok := platformConfig["securityIPs"]; ok {
-		mysql.SecurityIPs = securityIPs.([]string)
-	} else {
-		mysql.SecurityIPs = defaultSecurityIPs
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("mysql", moduleutil.PhaseValidate, fmt.Errorf("validate MySQL platform config failed, %w", err))
 	}
 
-	if privateRouting, ok := platformConfig["privateRouting"]; ok {
-		mysql.PrivateRouting = privateRouting.(bool)
-	} else {
-		mysql.PrivateRouting = defaultPrivateRouting
-	}
-
-	if size, ok := platformConfig["size"]; ok {
-		mysql.Size = size.(int)
-	} else {
-		mysql.Size = defaultSize
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
 	}
 
-	if instanceType, ok := platformConfig["instanceType"]; ok {
-		mysql.InstanceType = instanceType.(string)
+	// Decode the platform config over the built-in defaults of the MySQL instance, and then the
+	// dev config over the platform config.
+	mysql.Username = defaultUsername
+	mysql.Category = defaultCategory
+	mysql.SecurityIPs = slices.Clone(defaultSecurityIPs)
+	mysql.PrivateRouting = defaultPrivateRouting
+	mysql.Size = defaultSize
+	for _, config := range []map[string]interface{}{platformConfig, devConfig} {
+		out, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, mysql); err != nil {
+			return fmt.Errorf("parse mysql config failed, %w", err)
+		}
 	}
-
-	if subnetID, ok := platformConfig["subnetID"]; ok {
-		mysql.SubnetID = subnetID.(string)
+	completeOperatorConfig(mysql.Operator)
+	completeExportConfig(mysql.Export)
+	if mysql.Endpoints != nil {
+		if mysql.Endpoints, err = dbutil.ParseEndpoints(mysql.Endpoints); err != nil {
+			return err
+		}
 	}
 
-	if databaseName, ok := platformConfig["databaseName"]; ok {
-		mysql.DatabaseName = databaseName.(string)
-	}
+	// Resolve the region of the cloud provider, which falls back to the environment variables
+	// of the cloud provider if empty.
+	mysql.Region = dbutil.ResolveRegion(devConfig, platformConfig)
 
 	return mysql.Validate()
 }
@@ -226,77 +326,34 @@ func (mysql *MySQL) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformC
 func (mysql *MySQL) GenerateDBSecret(request *module.GeneratorRequest, hostAddress, username, password string) (
 	*kusionapiv1.Resource, *kusionapiv1.Patcher, error,
 ) {
-	// Create the data map of Kubernetes Secret storing the database host address, username
-	// and password.
-	data := make(map[string]string)
-	data["hostAddress"] = hostAddress
-	data["username"] = username
-	data["password"] = password
-
-	// Create the Kubernetes Secret.
-	secret := &v1.Secret{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Secret",
-			APIVersion: v1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      mysql.DatabaseName + dbResSuffix,
-			Namespace: request.Project,
-		},
-		StringData: data,
+	// Deliver the CA certificates verifying the server in the Secret.
+	var extra map[string]string
+	if mysql.TLS != nil && mysql.TLS.CA != "" {
+		extra = map[string]string{caSecretKey: mysql.TLS.CA}
 	}
 
-	resourceID := module.KubernetesResourceID(secret.TypeMeta, secret.ObjectMeta)
-	resource, err := module.WrapK8sResourceToKusionResource(resourceID, secret)
+	// Create the Kubernetes Secret storing the database host address, port, username and password,
+	// which are also published as the outputs of the module, and inject the credentials into the
+	// workload as the environment variables with Kusion resource patcher. The pod template of the
+	// workload is annotated with the checksum of the Secret, so that the pods are restarted to pick
+	// up the new credentials once the Secret is regenerated.
+	name := moduleutil.OutputSecretName(request, dbEngine)
+	resource, patcher, err := dbutil.DBSecret{
+		Name:               name,
+		Namespace:          request.Project,
+		DatabaseName:       mysql.DatabaseName,
+		HostAddress:        hostAddress,
+		Port:               dbPort,
+		Username:           username,
+		Password:           password,
+		PasswordVersion:    mysql.PasswordVersion,
+		Extra:              extra,
+		ChecksumAnnotation: secretChecksumAnnotationPrefix + moduleutil.SanitizeName(name, moduleutil.KubernetesNamingRule),
+	}.Generate()
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// Inject the database credentials into the workload as the environment variables with
-	// Kusion resource patcher.
-	hostAddressKey := dbHostAddressEnv + "_" + strings.ToUpper(strings.ReplaceAll(mysql.DatabaseName, "-", "_"))
-	usernameKey := dbUsernameEnv + "_" + strings.ToUpper(strings.ReplaceAll(mysql.DatabaseName, "-", "_"))
-	passwordKey := dbPasswordEnv + "_" + strings.ToUpper(strings.ReplaceAll(mysql.DatabaseName, "-", "_"))
-
-	envVars := []v1.EnvVar{
-		{
-			Name: hostAddressKey,
-			ValueFrom: &v1.EnvVarSource{
-				SecretKeyRef: &v1.SecretKeySelector{
-					LocalObjectReference: v1.LocalObjectReference{
-						Name: secret.Name,
-					},
-					Key: "hostAddress",
-				},
-			},
-		},
-		{
-			Name: usernameKey,
-			ValueFrom: &v1.EnvVarSource{
-				SecretKeyRef: &v1.SecretKeySelector{
-					LocalObjectReference: v1.LocalObjectReference{
-						Name: secret.Name,
-					},
-					Key: "username",
-				},
-			},
-		},
-		{
-			Name: passwordKey,
-			ValueFrom: &v1.EnvVarSource{
-				SecretKeyRef: &v1.SecretKeySelector{
-					LocalObjectReference: v1.LocalObjectReference{
-						Name: secret.Name,
-					},
-					Key: "password",
-				},
-			},
-		},
-	}
-
-	patcher := &kusionapiv1.Patcher{
-		Environments: envVars,
-	}
+	moduleutil.PublishOutputs("mysql", resource)
 
 	return resource, patcher, nil
 }
@@ -304,42 +361,55 @@ func (mysql *MySQL) GenerateDBSecret(request *module.GeneratorRequest, hostAddre
 // GenerateTFRandomPassword generates the terraform random_password resource as the password
 // of the cloud provided MySQL database instance.
 func (mysql *MySQL) GenerateTFRandomPassword(request *module.GeneratorRequest) (*kusionapiv1.Resource, string, error) {
-	resAttrs := map[string]any{
-		"length":           16,
-		"special":          true,
-		"override_special": "_",
+	return dbutil.RandomPassword(mysql.DatabaseName+dbResSuffix, mysql.PasswordVersion)
+}
+
+// Validate validates whether the input of a MySQL database instance is valid.
+func (mysql *MySQL) Validate() error {
+	if mysql.Type == CloudDBType && mysql.InstanceType == "" {
+		return ErrEmptyInstanceTypeForCloudDB
 	}
 
-	// Set the random_password provider with the default provider config.
-	randomPasswordProvider := defaultRandomProviderCfg
+	// The password of the local instance is only applied on the initialization of its data, so it
+	// can't be rotated by the password version.
+	if mysql.PasswordVersion != "" && strings.ToLower(mysql.Type) != CloudDBType {
+		return ErrPasswordVersionForLocalDB
+	}
 
-	id, err := module.TerraformResourceID(randomPasswordProvider, randomPassword, mysql.DatabaseName+dbResSuffix)
-	if err != nil {
-		return nil, "", err
+	if err := mysql.validateOperatorConfig(); err != nil {
+		return err
 	}
 
-	resource, err := module.WrapTFResourceToKusionResource(randomPasswordProvider, randomPassword, id, resAttrs, nil)
-	if err != nil {
-		return nil, "", err
+	if err := mysql.validateBackupConfig(); err != nil {
+		return err
 	}
 
-	return resource, id, nil
-}
+	if err := mysql.validateTLSConfig(); err != nil {
+		return err
+	}
 
-// Validate validates whether the input of a MySQL database instance is valid.
-func (mysql *MySQL) Validate() error {
-	if mysql.Type == CloudDBType && mysql.InstanceType == "" {
-		return ErrEmptyInstanceTypeForCloudDB
+	if err := mysql.providerOverride().Validate(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// GenerateDefaultMySQLName generates the default name of the MySQL instance.
-func GenerateDefaultMySQLName(projectName, stackName, appName string) string {
-	strs := []string{projectName, stackName, appName, dbEngine}
+// GenerateDefaultMySQLName generates the default name of the MySQL instance by the naming
+// template in the workspace context, following the naming rule of the cloud provider if any.
+func GenerateDefaultMySQLName(request *module.GeneratorRequest, dbType string) string {
+	rule := moduleutil.KubernetesNamingRule
+	if strings.ToLower(dbType) == CloudDBType {
+		providerType, _ := GetCloudProviderType(request.PlatformConfig)
+		switch strings.ToLower(providerType) {
+		case "aws":
+			rule = moduleutil.AWSNamingRule
+		case "alicloud":
+			rule = moduleutil.AlicloudNamingRule
+		}
+	}
 
-	return strings.Join(strs, "-")
+	return moduleutil.ResourceName(request, dbEngine, rule)
 }
 
 // GetCloudProviderType returns the cloud provider type of the MySQL instance.
@@ -355,6 +425,39 @@ func GetCloudProviderType(platformConfig kusionapiv1.GenericConfig) (string, err
 	return "", ErrEmptyCloudProviderType
 }
 
+// CheckGuardrails checks the MySQL instance against the guardrails of the environment class
+// of the workspace, where the cloud managed instances in prod must not be open to the internet.
+func (mysql *MySQL) CheckGuardrails(request *module.GeneratorRequest) error {
+	env, err := moduleutil.EnvironmentClass(request)
+	if err != nil {
+		return err
+	}
+
+	if env == moduleutil.EnvironmentProd && strings.ToLower(mysql.Type) == CloudDBType {
+		for _, ip := range mysql.SecurityIPs {
+			if IsOpenToInternet(ip) {
+				return fmt.Errorf("%w, %w", ErrPublicAccessInProd, &moduleutil.ConfigFieldError{
+					Path:   "securityIPs",
+					Reason: ip + " is not allowed in prod",
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsOpenToInternet returns whether the CIDR record allows all the IP addresses, e.g. 0.0.0.0/0.
+func IsOpenToInternet(cidrStr string) bool {
+	_, ipNet, err := net.ParseCIDR(cidrStr)
+	if err != nil {
+		return false
+	}
+	ones, _ := ipNet.Mask.Size()
+
+	return ones == 0
+}
+
 // IsPublicAccessible returns whether the mysql database instance is publicly
 // accessible according to the securityIPs.
 func IsPublicAccessible(securityIPs []string) bool {
@@ -389,5 +492,12 @@ func IsCIDR(cidrStr string) bool {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, DevConfig{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&MySQL{})
 }
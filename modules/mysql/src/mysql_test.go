@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"dbutil"
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+	"testutil"
 )
 
 func TestMySQLModule_Generator(t *testing.T) {
@@ -188,6 +192,25 @@ func TestMySQLModule_GetCompleteConfig(t *testing.T) {
 				DatabaseName:   "test-database",
 			},
 		},
+		{
+			name:            "Defaults in platform config",
+			devModuleConfig: kusionapiv1.Accessory{"version": "8.4"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"defaults":     map[string]interface{}{"type": "cloud", "version": "8.0"},
+				"securityIPs":  []interface{}{"10.0.0.0/8"},
+				"instanceType": "test-instance-type",
+			},
+			expectedMySQL: &MySQL{
+				Type:           "cloud",
+				Version:        "8.4",
+				Username:       defaultUsername,
+				Category:       defaultCategory,
+				SecurityIPs:    []string{"10.0.0.0/8"},
+				PrivateRouting: defaultPrivateRouting,
+				Size:           defaultSize,
+				InstanceType:   "test-instance-type",
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -230,13 +253,14 @@ func TestMySQLModule_GenerateDBSecret(t *testing.T) {
 			APIVersion: v1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-database-mysql",
+			Name:      "test-project-test-stack-test-app-mysql-mysql",
 			Namespace: "test-project",
 		},
 		StringData: map[string]string{
 			"hostAddress": "test-host-address",
 			"username":    "test-username",
 			"password":    "test-password",
+			"port":        "3306",
 		},
 	}
 
@@ -245,6 +269,13 @@ func TestMySQLModule_GenerateDBSecret(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to wrap secret resource for unit test: %v", err)
 	}
+	expectedResource.Extensions[moduleutil.OutputsExtensionKey] = map[string]interface{}{
+		"host":       "hostAddress",
+		"port":       "port",
+		"username":   "username",
+		"password":   "password",
+		"secretName": "",
+	}
 
 	expectedPatcher := &kusionapiv1.Patcher{
 		Environments: []v1.EnvVar{
@@ -253,7 +284,7 @@ func TestMySQLModule_GenerateDBSecret(t *testing.T) {
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: "test-database-mysql",
+							Name: "test-project-test-stack-test-app-mysql-mysql",
 						},
 						Key: "hostAddress",
 					},
@@ -264,7 +295,7 @@ func TestMySQLModule_GenerateDBSecret(t *testing.T) {
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: "test-database-mysql",
+							Name: "test-project-test-stack-test-app-mysql-mysql",
 						},
 						Key: "username",
 					},
@@ -275,7 +306,7 @@ func TestMySQLModule_GenerateDBSecret(t *testing.T) {
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: "test-database-mysql",
+							Name: "test-project-test-stack-test-app-mysql-mysql",
 						},
 						Key: "password",
 					},
@@ -284,11 +315,24 @@ func TestMySQLModule_GenerateDBSecret(t *testing.T) {
 		},
 	}
 
+	checksum, err := dbutil.SecretChecksum(sec, "")
+	assert.Nil(t, err)
+	expectedPatcher.PodAnnotations = map[string]string{
+		"checksum.kusionstack.io/test-project-test-stack-test-app-mysql-mysql": checksum,
+	}
+
 	actualResource, actualPatcher, err := mysql.GenerateDBSecret(r, hostAddress, username, password)
 
 	assert.Nil(t, err)
 	assert.Equal(t, expectedResource, actualResource)
+	// The Secret overriding the database name is still the one the output references resolve to.
+	assert.Equal(t, moduleutil.OutputSecretID(r, dbEngine), actualResource.ID)
 	assert.Equal(t, expectedPatcher, actualPatcher)
+
+	// The checksum changes with the credentials, which restarts the pods of the workload.
+	_, rotatedPatcher, err := mysql.GenerateDBSecret(r, hostAddress, username, "rotated-password")
+	assert.Nil(t, err)
+	assert.NotEqual(t, checksum, rotatedPatcher.PodAnnotations["checksum.kusionstack.io/test-project-test-stack-test-app-mysql-mysql"])
 }
 
 func TestMySQLModule_GenerateTFRandomPassword(t *testing.T) {
@@ -356,6 +400,34 @@ func TestMySQLModule_GenerateTFRandomPassword(t *testing.T) {
 	})
 }
 
+func TestMySQLModule_GetCompleteConfigInvalid(t *testing.T) {
+	t.Run("unknown field in dev config", func(t *testing.T) {
+		mysql := &MySQL{}
+		err := mysql.GetCompleteConfig(kusionapiv1.Accessory{
+			"type":    "cloud",
+			"verison": "8.0",
+		}, nil)
+
+		assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "verison: unknown field")
+	})
+
+	t.Run("mismatched type in platform config", func(t *testing.T) {
+		mysql := &MySQL{}
+		err := mysql.GetCompleteConfig(kusionapiv1.Accessory{
+			"type":    "cloud",
+			"version": "8.0",
+		}, kusionapiv1.GenericConfig{
+			"size":     "20",
+			"defaults": map[string]interface{}{"verison": "8.0"},
+		})
+
+		assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "size: expected integer, got string")
+		assert.ErrorContains(t, err, "defaults.verison: unknown field")
+	})
+}
+
 func TestMySQLModule_Validate(t *testing.T) {
 	t.Run("cloud db with empty instanceType", func(t *testing.T) {
 		mysql := &MySQL{
@@ -379,6 +451,72 @@ func TestMySQLModule_Validate(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("local db with passwordVersion", func(t *testing.T) {
+		mysql := &MySQL{
+			Type:            "local",
+			Version:         "8.0",
+			PasswordVersion: "2",
+		}
+
+		assert.ErrorIs(t, mysql.Validate(), ErrPasswordVersionForLocalDB)
+	})
+
+	t.Run("session name without assumeRoleARN", func(t *testing.T) {
+		mysql := &MySQL{
+			Type:         "cloud",
+			InstanceType: "test-instance-type",
+			SessionName:  "kusion",
+		}
+
+		assert.ErrorIs(t, mysql.Validate(), ErrEmptyAssumeRoleARN)
+	})
+
+	t.Run("invalid assumeRoleARN", func(t *testing.T) {
+		mysql := &MySQL{
+			Type:          "cloud",
+			InstanceType:  "test-instance-type",
+			AssumeRoleARN: "kusion",
+		}
+
+		assert.ErrorIs(t, mysql.Validate(), ErrInvalidAssumeRoleARN)
+	})
+}
+
+func TestMySQLModule_CheckGuardrails(t *testing.T) {
+	prod := &module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{moduleutil.EnvironmentKey: "prod"},
+	}
+
+	t.Run("cloud db open to the internet in prod", func(t *testing.T) {
+		mysql := &MySQL{Type: "cloud", SecurityIPs: []string{"10.0.0.0/8", "0.0.0.0/0"}}
+
+		err := mysql.CheckGuardrails(prod)
+
+		assert.ErrorIs(t, err, ErrPublicAccessInProd)
+		var fieldErr *moduleutil.ConfigFieldError
+		if assert.ErrorAs(t, err, &fieldErr) {
+			assert.Equal(t, "securityIPs", fieldErr.Path)
+		}
+	})
+
+	t.Run("cloud db open to the internet in dev", func(t *testing.T) {
+		mysql := &MySQL{Type: "cloud", SecurityIPs: []string{"0.0.0.0/0"}}
+
+		assert.NoError(t, mysql.CheckGuardrails(&module.GeneratorRequest{}))
+	})
+
+	t.Run("cloud db with restricted securityIPs in prod", func(t *testing.T) {
+		mysql := &MySQL{Type: "cloud", SecurityIPs: []string{"10.0.0.0/8", "203.0.113.10"}}
+
+		assert.NoError(t, mysql.CheckGuardrails(prod))
+	})
+
+	t.Run("local db in prod", func(t *testing.T) {
+		mysql := &MySQL{Type: "local", SecurityIPs: []string{"0.0.0.0/0"}}
+
+		assert.NoError(t, mysql.CheckGuardrails(prod))
+	})
 }
 
 func TestIsPublicAccessible(t *testing.T) {
@@ -416,3 +554,176 @@ func TestIsPublicAccessible(t *testing.T) {
 		assert.Equal(t, tc.expected, actual)
 	}
 }
+
+func TestMySQLModule_GenerateGolden(t *testing.T) {
+	tests := []struct {
+		name           string
+		platformConfig kusionapiv1.GenericConfig
+	}{
+		{
+			name: "local",
+		},
+		{
+			name: "local-database-name",
+			platformConfig: kusionapiv1.GenericConfig{
+				"databaseName": "foo-db",
+				"username":     "foo",
+			},
+		},
+		{
+			name: "local-operator",
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{
+					"type":         "mysql-operator",
+					"version":      "8.0.36",
+					"storageClass": "standard",
+					"backup": map[string]interface{}{
+						"bucketName":        "backups",
+						"prefix":            "/foo",
+						"credentialsSecret": "backup-credentials",
+						"schedule":          "0 0 * * *",
+					},
+				},
+			},
+		},
+		{
+			name: "local-backup",
+			platformConfig: kusionapiv1.GenericConfig{
+				"binlogRetentionHours": 72,
+				"export": map[string]interface{}{
+					"schedule":          "0 3 * * *",
+					"destination":       "s3://backups/mysql",
+					"credentialsSecret": "backup-credentials",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(kusionapiv1.Accessory{"type": LocalDBType, "version": "8.0"}).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&MySQL{}).Generate(context.Background(), request)
+			if !assert.NoError(t, err) {
+				return
+			}
+			testutil.AssertGolden(t, filepath.Join("testdata", tt.name+".golden.json"), response)
+		})
+	}
+}
+
+func TestMySQLModule_GenerateCloudRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		env            map[string]string
+		expectedIDs    []string
+		expectedRegion string
+		expectedErr    error
+	}{
+		{
+			name: "aws region from env",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":        "aws",
+				"instanceType": "db.t3.micro",
+			},
+			env: map[string]string{awsRegionEnv: "us-east-1"},
+			expectedIDs: []string{
+				"hashicorp:random:random_password:default-dev-foo-mysql-mysql",
+				"hashicorp:aws:aws_security_group:default-dev-foo-mysql-mysql",
+				"hashicorp:aws:aws_db_instance:default-dev-foo-mysql",
+				"v1:Secret:default:default-dev-foo-mysql-mysql",
+			},
+		},
+		{
+			name: "alicloud region from env",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":        "alicloud",
+				"instanceType": "mysql.n2.serverless.1c",
+				"category":     "serverless_basic",
+				"subnetID":     "test-subnet-id",
+			},
+			env: map[string]string{alicloudRegionEnv: "cn-beijing"},
+			expectedIDs: []string{
+				"hashicorp:random:random_password:default-dev-foo-mysql-mysql",
+				"hashicorp:alicloud:alicloud_db_instance:default-dev-foo-mysql",
+				"hashicorp:alicloud:alicloud_db_connection:default-dev-foo-mysql",
+				"hashicorp:alicloud:alicloud_rds_account:default-dev-foo-mysql",
+				"v1:Secret:default:default-dev-foo-mysql-mysql",
+			},
+		},
+		{
+			name: "aws region from topology over env",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":            "aws",
+				"instanceType":     "db.t3.micro",
+				dbutil.TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			env:            map[string]string{awsRegionEnv: "us-east-1"},
+			expectedRegion: "us-west-2",
+		},
+		{
+			name:      "aws region from dev config over topology",
+			devConfig: kusionapiv1.Accessory{"region": "eu-west-1"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":            "aws",
+				"instanceType":     "db.t3.micro",
+				dbutil.TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expectedRegion: "eu-west-1",
+		},
+		{
+			name: "empty aws region",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":        "aws",
+				"instanceType": "db.t3.micro",
+			},
+			expectedErr: ErrEmptyAWSProviderRegion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Shield the test from the cloud credentials and regions of the host.
+			testutil.IsolateEnv(t, "AWS_", "ALICLOUD_")
+			testutil.SetEnv(t, tt.env)
+
+			devConfig := kusionapiv1.Accessory{"type": CloudDBType, "version": "8.0"}
+			for k, v := range tt.devConfig {
+				devConfig[k] = v
+			}
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&MySQL{}).Generate(context.Background(), request)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.expectedRegion != "" {
+				for _, res := range response.Resources {
+					if moduleutil.ResourceKind(res) == awsDBInstance {
+						assert.Equal(t, tt.expectedRegion, res.Extensions["providerMeta"].(map[string]any)["region"])
+					}
+				}
+				return
+			}
+			var ids []string
+			for _, res := range response.Resources {
+				ids = append(ids, res.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
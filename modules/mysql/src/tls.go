@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	awsDBParameterGroup         = "aws_db_parameter_group"
+	tlsSuffix                   = "-tls"
+	requireSecureTransportParam = "require_secure_transport"
+	// caSecretKey is the key of the database Secret storing the PEM encoded CA certificates.
+	caSecretKey = "ca"
+)
+
+var (
+	ErrTDEForLocalDB      = errors.New("mysql tde is only supported for the cloud managed mysql instance")
+	ErrInvalidCA          = errors.New("invalid mysql tls ca, must be the PEM encoded certificates")
+	ErrEmptyVersionForTLS = errors.New("empty mysql version for the parameter group family of requireSecureTransport")
+)
+
+// TLSConfig describes the encryption of the MySQL database, i.e. the transparent data encryption
+// at rest and the secure transport of the connections, where the CA certificates verifying the
+// server are delivered to the workload in the database Secret.
+type TLSConfig struct {
+	// Whether to refuse the connections not over TLS by require_secure_transport.
+	RequireSecureTransport bool `json:"requireSecureTransport,omitempty" yaml:"requireSecureTransport,omitempty"`
+	// Whether to enable the transparent data encryption of the cloud managed instance, which is the
+	// TDE of Alicloud RDS, and the storage encryption with the KMS key of AWS RDS.
+	TDE bool `json:"tde,omitempty" yaml:"tde,omitempty"`
+	// The ARN of the KMS key encrypting the storage of AWS RDS with tde, which defaults to the AWS
+	// managed key of RDS.
+	KMSKeyID string `json:"kmsKeyID,omitempty" yaml:"kmsKeyID,omitempty"`
+	// The PEM encoded CA certificates verifying the server, e.g. the certificate bundle of the
+	// region of AWS RDS, which is stored in the ca key of the database Secret.
+	CA string `json:"ca,omitempty" yaml:"ca,omitempty"`
+	// The identifier of the CA certificate of AWS RDS, e.g. rds-ca-rsa2048-g1, which is ignored by
+	// the other cloud providers.
+	CACertIdentifier string `json:"caCertIdentifier,omitempty" yaml:"caCertIdentifier,omitempty"`
+}
+
+// validateTLSConfig validates the tls config of the MySQL instance.
+func (mysql *MySQL) validateTLSConfig() error {
+	tls := mysql.TLS
+	if tls == nil {
+		return nil
+	}
+	if tls.TDE && strings.ToLower(mysql.Type) != CloudDBType {
+		return ErrTDEForLocalDB
+	}
+	if tls.CA != "" {
+		block, _ := pem.Decode([]byte(tls.CA))
+		if block == nil || block.Type != "CERTIFICATE" {
+			return fmt.Errorf("%w, %w", ErrInvalidCA, &moduleutil.ConfigFieldError{
+				Path:   "tls.ca",
+				Reason: "no PEM encoded certificate found",
+			})
+		}
+	}
+	return nil
+}
+
+// requireSecureTransport indicates the connections not over TLS are refused.
+func (mysql *MySQL) requireSecureTransport() bool {
+	return mysql.TLS != nil && mysql.TLS.RequireSecureTransport
+}
+
+// awsParameterGroupFamily returns the family of the parameter group of the MySQL version, e.g.
+// mysql8.0 of 8.0.36.
+func (mysql *MySQL) awsParameterGroupFamily() (string, error) {
+	parts := strings.Split(mysql.Version, ".")
+	if len(parts) < 2 || parts[0] == "" {
+		return "", ErrEmptyVersionForTLS
+	}
+	return dbEngine + parts[0] + "." + parts[1], nil
+}
+
+// generateAWSDBParameterGroup generates aws_db_parameter_group resource enabling the
+// require_secure_transport of the AWS provided MySQL database instance.
+func (mysql *MySQL) generateAWSDBParameterGroup(awsProviderCfg module.ProviderConfig, region string) (*kusionapiv1.Resource, string, error) {
+	family, err := mysql.awsParameterGroupFamily()
+	if err != nil {
+		return nil, "", err
+	}
+	resAttrs := map[string]interface{}{
+		"name":   mysql.DatabaseName + tlsSuffix,
+		"family": family,
+		"parameter": []map[string]interface{}{
+			{
+				"name":  requireSecureTransportParam,
+				"value": "1",
+			},
+		},
+	}
+
+	id, err := module.TerraformResourceID(awsProviderCfg, awsDBParameterGroup, mysql.DatabaseName+tlsSuffix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	awsProviderCfg.ProviderMeta = mysql.awsProviderMeta(region)
+	resource, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsDBParameterGroup, id, resAttrs, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resource, id, nil
+}
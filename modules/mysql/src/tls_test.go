@@ -0,0 +1,113 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBeTCCAR+gAwIBAgIUTestCertificateForMySQLTLS0wCgYIKoZIzj0EAwIw
+-----END CERTIFICATE-----
+`
+
+func TestMySQLModule_GetCompleteConfigTLS(t *testing.T) {
+	tests := []struct {
+		name        string
+		devConfig   kusionapiv1.Accessory
+		tls         map[string]interface{}
+		expectedTLS *TLSConfig
+		expectedErr error
+	}{
+		{
+			name:      "require secure transport with ca",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			tls:       map[string]interface{}{"requireSecureTransport": true, "ca": testCA},
+			expectedTLS: &TLSConfig{
+				RequireSecureTransport: true,
+				CA:                     testCA,
+			},
+		},
+		{
+			name:        "tde of local instance",
+			devConfig:   kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			tls:         map[string]interface{}{"tde": true},
+			expectedErr: ErrTDEForLocalDB,
+		},
+		{
+			name:        "invalid ca",
+			devConfig:   kusionapiv1.Accessory{"type": "local", "version": "8.0"},
+			tls:         map[string]interface{}{"ca": "not a certificate"},
+			expectedErr: ErrInvalidCA,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mysql := &MySQL{}
+			err := mysql.GetCompleteConfig(tt.devConfig, kusionapiv1.GenericConfig{"tls": tt.tls})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTLS, mysql.TLS)
+		})
+	}
+}
+
+func TestMySQLModule_GenerateDBSecretCA(t *testing.T) {
+	mysql := &MySQL{DatabaseName: "test-database", TLS: &TLSConfig{CA: testCA}}
+
+	secret, _, err := mysql.GenerateDBSecret(&module.GeneratorRequest{Project: "test-project"}, "test-host", "root", "test-password")
+	assert.NoError(t, err)
+	assert.Equal(t, testCA, secret.Attributes["stringData"].(map[string]interface{})[caSecretKey])
+}
+
+func TestMySQLModule_GenerateAWSDBParameterGroup(t *testing.T) {
+	mysql := &MySQL{DatabaseName: "test-database", Version: "8.0.36", TLS: &TLSConfig{RequireSecureTransport: true}}
+
+	resource, id, err := mysql.generateAWSDBParameterGroup(defaultAWSProviderCfg, "test-region")
+	assert.NoError(t, err)
+	assert.Equal(t, "hashicorp:aws:aws_db_parameter_group:test-database-tls", id)
+	assert.Equal(t, "mysql8.0", resource.Attributes["family"])
+
+	mysql.Version = ""
+	_, _, err = mysql.generateAWSDBParameterGroup(defaultAWSProviderCfg, "test-region")
+	assert.ErrorIs(t, err, ErrEmptyVersionForTLS)
+}
+
+func TestMySQLModule_GenerateDBInstanceTLS(t *testing.T) {
+	mysql := &MySQL{
+		Type:         "cloud",
+		Version:      "8.0",
+		DatabaseName: "test-database",
+		Category:     defaultCategory,
+		InstanceType: "test-instance-type",
+		TLS: &TLSConfig{
+			RequireSecureTransport: true,
+			TDE:                    true,
+			KMSKeyID:               "arn:aws:kms:us-east-1:123456789012:key/test",
+			CACertIdentifier:       "rds-ca-rsa2048-g1",
+		},
+	}
+
+	awsDBInstance, _, err := mysql.generateAWSDBInstance(defaultAWSProviderCfg, "test-region", "test-password-id", "test-security-group-id")
+	assert.NoError(t, err)
+	assert.Equal(t, true, awsDBInstance.Attributes["storage_encrypted"])
+	assert.Equal(t, "arn:aws:kms:us-east-1:123456789012:key/test", awsDBInstance.Attributes["kms_key_id"])
+	assert.Equal(t, "rds-ca-rsa2048-g1", awsDBInstance.Attributes["ca_cert_identifier"])
+
+	alicloudDBInstance, _, err := mysql.generateAlicloudDBInstance(defaultAlicloudProviderCfg, "test-region")
+	assert.NoError(t, err)
+	assert.Equal(t, "Enabled", alicloudDBInstance.Attributes["tde_status"])
+	assert.Equal(t, "Open", alicloudDBInstance.Attributes["ssl_action"])
+}
+
+func TestMySQLModule_Mycnf(t *testing.T) {
+	assert.Empty(t, (&MySQL{}).mycnf())
+	assert.Equal(t, "[mysqld]\nbinlog_expire_logs_seconds=3600\nrequire_secure_transport=ON\n",
+		(&MySQL{BinlogRetentionHours: 1, TLS: &TLSConfig{RequireSecureTransport: true}}).mycnf())
+}
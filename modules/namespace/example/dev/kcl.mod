@@ -0,0 +1,10 @@
+[package]
+name = "example"
+
+[dependencies]
+kam = { git = "https://github.com/KusionStack/kam.git", tag = "0.2.0" }
+service = { oci = "oci://ghcr.io/kusionstack/service", tag = "0.1.0" }
+namespace = { oci = "oci://ghcr.io/kusionstack/namespace", tag = "0.1.0" }
+
+[profile]
+entries = ["main.k"]
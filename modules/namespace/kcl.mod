@@ -0,0 +1,3 @@
+[package]
+name = "namespace"
+version = "0.1.0"
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+const (
+	NetworkPolicyDefaultDeny        = "DefaultDeny"
+	NetworkPolicyAllowSameNamespace = "AllowSameNamespace"
+)
+
+const (
+	// TeamLabel is the label of the Namespace holding the owning team.
+	TeamLabel = "kusionstack.io/team"
+
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	istioInjectionLabel    = "istio-injection"
+
+	// defaultTeamRole is the ClusterRole bound to the owning team if no team roles are configured.
+	defaultTeamRole = "edit"
+)
+
+var (
+	ErrInvalidPodSecurity   = errors.New("podSecurity must be privileged, baseline or restricted")
+	ErrInvalidNetworkPolicy = errors.New("networkPolicy must be DefaultDeny or AllowSameNamespace")
+	ErrInvalidTeam          = errors.New("team must be a valid label value")
+	ErrInvalidResourceQuota = errors.New("resourceQuota must be the resource names with valid quantities")
+	ErrMandatedLabel        = errors.New("labels must not override the labels mandated by the platform")
+	ErrEmptyTeamRole        = errors.New("teamRoles must not contain empty ClusterRole names")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Namespace{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Namespace{})
+}
+
+// Namespace describes the attributes of the namespace module declared by the application. The
+// Namespace is named after the project, and governed by the platform config in workspace.
+type Namespace struct {
+	// Team is the team owning the Namespace, which is labeled on the Namespace and bound to the
+	// team roles.
+	Team string `json:"team,omitempty" yaml:"team,omitempty"`
+	// Labels are the additional labels of the Namespace, which must not override the ones mandated
+	// by the platform.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Annotations are the additional annotations of the Namespace.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+
+	// The platform config of the Namespace.
+	platform PlatformConfig
+}
+
+// PlatformConfig describes the platform config of the namespace module in workspace.
+type PlatformConfig struct {
+	// PodSecurity is the Pod Security Standards level enforced, audited and warned in the
+	// Namespace, i.e. privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+	// IstioInjection enables or disables the istio sidecar injection of the Namespace, which is
+	// left unlabeled if not set.
+	IstioInjection *bool `json:"istioInjection,omitempty" yaml:"istioInjection,omitempty"`
+	// Labels are the labels mandated by the platform on the Namespace.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// NetworkPolicy is the default NetworkPolicy of the Namespace, i.e. DefaultDeny denying all
+	// the ingress traffic, or AllowSameNamespace allowing the ingress traffic within the Namespace
+	// only. No NetworkPolicy is generated if empty.
+	NetworkPolicy string `json:"networkPolicy,omitempty" yaml:"networkPolicy,omitempty"`
+	// ResourceQuota is the hard limits of the ResourceQuota of the Namespace, e.g.
+	// {"requests.cpu": "8", "pods": "50"}. No ResourceQuota is generated if empty.
+	ResourceQuota map[string]string `json:"resourceQuota,omitempty" yaml:"resourceQuota,omitempty"`
+	// TeamRoles are the ClusterRoles bound to the group of the owning team in the Namespace,
+	// defaults to edit.
+	TeamRoles []string `json:"teamRoles,omitempty" yaml:"teamRoles,omitempty"`
+	// TeamGroupPrefix is the prefix of the group name of the team in the identity provider, e.g.
+	// "oidc:", the group name is the team itself if empty.
+	TeamGroupPrefix string `json:"teamGroupPrefix,omitempty" yaml:"teamGroupPrefix,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Namespace `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// Generate implements the generation logic of the namespace module.
+func (namespace *Namespace) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "namespace", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("namespace", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Namespace does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Namespace does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the namespace module.
+	if err := namespace.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("namespace", moduleutil.PhaseComplete, err)
+	}
+
+	// Generate the Namespace, and the governance resources in it depending on the Namespace.
+	ns := namespace.generateNamespace(request)
+	nsResource, err := moduleutil.WrapK8sResource(ns)
+	if err != nil {
+		return nil, err
+	}
+	resources := []kusionapiv1.Resource{*nsResource}
+
+	var objects []moduleutil.K8sObject
+	if policy := namespace.generateNetworkPolicy(request); policy != nil {
+		objects = append(objects, policy)
+	}
+	if quota := namespace.generateResourceQuota(request); quota != nil {
+		objects = append(objects, quota)
+	}
+	for _, binding := range namespace.generateRoleBindings(request) {
+		objects = append(objects, binding)
+	}
+	for _, obj := range objects {
+		res, err := moduleutil.WrapK8sResource(obj)
+		if err != nil {
+			return nil, err
+		}
+		res.DependsOn = []string{nsResource.ID}
+		resources = append(resources, *res)
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the namespace module.
+func (namespace *Namespace) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Namespace{}); err != nil {
+		return moduleutil.NewModuleError("namespace", moduleutil.PhaseValidate, fmt.Errorf("validate namespace dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("namespace", moduleutil.PhaseValidate, fmt.Errorf("validate namespace platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, namespace); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &namespace.platform); err != nil {
+			return err
+		}
+	}
+	if namespace.Team != "" && len(namespace.platform.TeamRoles) == 0 {
+		namespace.platform.TeamRoles = []string{defaultTeamRole}
+	}
+
+	return namespace.Validate()
+}
+
+// Validate validates whether the configs of the namespace module are valid.
+func (namespace *Namespace) Validate() error {
+	platform := namespace.platform
+	switch platform.PodSecurity {
+	case "", PodSecurityPrivileged, PodSecurityBaseline, PodSecurityRestricted:
+	default:
+		return ErrInvalidPodSecurity
+	}
+	switch platform.NetworkPolicy {
+	case "", NetworkPolicyDefaultDeny, NetworkPolicyAllowSameNamespace:
+	default:
+		return ErrInvalidNetworkPolicy
+	}
+	for name, value := range platform.ResourceQuota {
+		if _, err := resource.ParseQuantity(value); err != nil || name == "" {
+			return fmt.Errorf("%w, got %s: %s", ErrInvalidResourceQuota, name, value)
+		}
+	}
+	for _, role := range platform.TeamRoles {
+		if role == "" {
+			return ErrEmptyTeamRole
+		}
+	}
+	if errs := validation.IsValidLabelValue(namespace.Team); len(errs) != 0 {
+		return fmt.Errorf("%w, got %s: %s", ErrInvalidTeam, namespace.Team, strings.Join(errs, "; "))
+	}
+
+	// The labels declared by the application must be valid, and must not override the mandated ones.
+	mandated := namespace.mandatedLabels()
+	for k, v := range namespace.Labels {
+		if errs := validation.IsQualifiedName(k); len(errs) != 0 {
+			return fmt.Errorf("invalid label %s: %s", k, strings.Join(errs, "; "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+			return fmt.Errorf("invalid value of label %s: %s", k, strings.Join(errs, "; "))
+		}
+		if _, ok := mandated[k]; ok {
+			return fmt.Errorf("%w, got %s", ErrMandatedLabel, k)
+		}
+	}
+
+	return nil
+}
+
+// mandatedLabels returns the labels of the Namespace mandated by the platform, including the
+// Pod Security Standards, the istio injection and the owning team.
+func (namespace *Namespace) mandatedLabels() map[string]string {
+	platform := namespace.platform
+	labels := make(map[string]string, len(platform.Labels)+5)
+	for k, v := range platform.Labels {
+		labels[k] = v
+	}
+	if platform.PodSecurity != "" {
+		for _, mode := range []string{"enforce", "audit", "warn"} {
+			labels[podSecurityLabelPrefix+mode] = platform.PodSecurity
+		}
+	}
+	if platform.IstioInjection != nil {
+		labels[istioInjectionLabel] = "disabled"
+		if *platform.IstioInjection {
+			labels[istioInjectionLabel] = "enabled"
+		}
+	}
+	if namespace.Team != "" {
+		labels[TeamLabel] = namespace.Team
+	}
+	return labels
+}
+
+// generateNamespace generates the Namespace named after the project.
+func (namespace *Namespace) generateNamespace(request *module.GeneratorRequest) *v1.Namespace {
+	labels := make(map[string]string, len(namespace.Labels))
+	for k, v := range namespace.Labels {
+		labels[k] = v
+	}
+	for k, v := range namespace.mandatedLabels() {
+		labels[k] = v
+	}
+	return &v1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Namespace",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        request.Project,
+			Labels:      labels,
+			Annotations: namespace.Annotations,
+		},
+	}
+}
+
+// generateNetworkPolicy generates the default NetworkPolicy selecting all the pods in the
+// Namespace, or nil if not configured.
+func (namespace *Namespace) generateNetworkPolicy(request *module.GeneratorRequest) *networkingv1.NetworkPolicy {
+	policy := &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "NetworkPolicy",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: request.Project,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+	switch namespace.platform.NetworkPolicy {
+	case NetworkPolicyDefaultDeny:
+		policy.Name = "default-deny-ingress"
+	case NetworkPolicyAllowSameNamespace:
+		policy.Name = "allow-same-namespace"
+		policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+			{From: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}},
+		}
+	default:
+		return nil
+	}
+	return policy
+}
+
+// generateResourceQuota generates the ResourceQuota of the Namespace, or nil if not configured.
+func (namespace *Namespace) generateResourceQuota(request *module.GeneratorRequest) *v1.ResourceQuota {
+	if len(namespace.platform.ResourceQuota) == 0 {
+		return nil
+	}
+	hard := make(v1.ResourceList, len(namespace.platform.ResourceQuota))
+	for name, value := range namespace.platform.ResourceQuota {
+		hard[v1.ResourceName(name)] = resource.MustParse(value)
+	}
+	return &v1.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "ResourceQuota",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "default",
+			Namespace: request.Project,
+		},
+		Spec: v1.ResourceQuotaSpec{Hard: hard},
+	}
+}
+
+// generateRoleBindings generates the RoleBindings binding the team roles to the group of the
+// owning team in the Namespace, or nil if the team is not declared.
+func (namespace *Namespace) generateRoleBindings(request *module.GeneratorRequest) []*rbacv1.RoleBinding {
+	if namespace.Team == "" {
+		return nil
+	}
+	roles := append([]string(nil), namespace.platform.TeamRoles...)
+	sort.Strings(roles)
+	bindings := make([]*rbacv1.RoleBinding, 0, len(roles))
+	for _, role := range roles {
+		bindings = append(bindings, &rbacv1.RoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "RoleBinding",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      moduleutil.SanitizeName(namespace.Team+"-"+role, moduleutil.KubernetesNamingRule),
+				Namespace: request.Project,
+			},
+			Subjects: []rbacv1.Subject{
+				{
+					APIGroup: rbacv1.GroupName,
+					Kind:     rbacv1.GroupKind,
+					Name:     namespace.platform.TeamGroupPrefix + namespace.Team,
+				},
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: rbacv1.GroupName,
+				Kind:     "ClusterRole",
+				Name:     role,
+			},
+		})
+	}
+	return bindings
+}
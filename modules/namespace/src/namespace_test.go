@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestNamespace_Generate(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+	}{
+		{
+			name:      "empty config",
+			devConfig: kusionapiv1.Accessory{},
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Namespace{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, response)
+		})
+	}
+}
+
+func TestNamespace_GenerateGovernance(t *testing.T) {
+	request := testutil.NewRequest().
+		WithServiceWorkload("Deployment").
+		WithDevConfig(kusionapiv1.Accessory{
+			"team":   "payments",
+			"labels": map[string]interface{}{"cost-center": "cc-42"},
+		}).
+		WithPlatformConfig(kusionapiv1.GenericConfig{
+			"podSecurity":     PodSecurityRestricted,
+			"istioInjection":  true,
+			"labels":          map[string]interface{}{"tier": "gold"},
+			"networkPolicy":   NetworkPolicyAllowSameNamespace,
+			"resourceQuota":   map[string]interface{}{"requests.cpu": "8", "pods": "50"},
+			"teamRoles":       []interface{}{"view", "edit"},
+			"teamGroupPrefix": "oidc:",
+		}).
+		Build()
+
+	response, err := (&Namespace{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	ids := make([]string, 0, len(response.Resources))
+	for _, res := range response.Resources {
+		ids = append(ids, res.ID)
+	}
+	ns := "v1:Namespace:" + request.Project
+	assert.Equal(t, []string{
+		ns,
+		"networking.k8s.io/v1:NetworkPolicy:" + request.Project + ":allow-same-namespace",
+		"v1:ResourceQuota:" + request.Project + ":default",
+		"rbac.authorization.k8s.io/v1:RoleBinding:" + request.Project + ":payments-edit",
+		"rbac.authorization.k8s.io/v1:RoleBinding:" + request.Project + ":payments-view",
+	}, ids)
+	for _, res := range response.Resources[1:] {
+		assert.Equal(t, []string{ns}, res.DependsOn)
+	}
+
+	labels := response.Resources[0].Attributes["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+	for k, v := range map[string]string{
+		"pod-security.kubernetes.io/enforce": PodSecurityRestricted,
+		"pod-security.kubernetes.io/audit":   PodSecurityRestricted,
+		"pod-security.kubernetes.io/warn":    PodSecurityRestricted,
+		"istio-injection":                    "enabled",
+		"tier":                               "gold",
+		"cost-center":                        "cc-42",
+		TeamLabel:                            "payments",
+	} {
+		assert.Equal(t, v, labels[k], k)
+	}
+
+	subjects := response.Resources[3].Attributes["subjects"].([]interface{})
+	assert.Equal(t, "oidc:payments", subjects[0].(map[string]interface{})["name"])
+}
+
+func TestNamespace_Validate(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expected       error
+	}{
+		{
+			name:           "invalid pod security",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: kusionapiv1.GenericConfig{"podSecurity": "strict"},
+			expected:       ErrInvalidPodSecurity,
+		},
+		{
+			name:           "invalid network policy",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: kusionapiv1.GenericConfig{"networkPolicy": "DenyAll"},
+			expected:       ErrInvalidNetworkPolicy,
+		},
+		{
+			name:           "invalid resource quota",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: kusionapiv1.GenericConfig{"resourceQuota": map[string]interface{}{"pods": "many"}},
+			expected:       ErrInvalidResourceQuota,
+		},
+		{
+			name:      "invalid team",
+			devConfig: kusionapiv1.Accessory{"team": "Payments Team"},
+			expected:  ErrInvalidTeam,
+		},
+		{
+			name:           "mandated label",
+			devConfig:      kusionapiv1.Accessory{"labels": map[string]interface{}{"istio-injection": "disabled"}},
+			platformConfig: kusionapiv1.GenericConfig{"istioInjection": true},
+			expected:       ErrMandatedLabel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Namespace{}).GetCompleteConfig(tt.devConfig, tt.platformConfig)
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
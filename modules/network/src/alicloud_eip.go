@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	FieldEIP       = "eip"
+	FieldRegion    = "region"
+	FieldVSwitchID = "vSwitchID"
+)
+
+var (
+	ErrUnsupportedEIP              = errors.New("eip works only for the public ports of alicloud")
+	ErrEmptyEIPVSwitchID           = errors.New("vSwitchID must be configured in the port of workspace for eip")
+	ErrEmptyEIPRegion              = errors.New("region must be configured in the port of workspace or ALICLOUD_REGION for eip")
+	ErrInvalidEIPBandwidth         = errors.New("bandwidth of eip must be between 1 and 10000 if exist")
+	ErrInvalidEIPChargeType        = errors.New("internetChargeType of eip must be PayByTraffic or PayByBandwidth")
+	ErrEIPOptionsWithAllocation    = errors.New("bandwidth and internetChargeType of eip work only for the eip created by the module")
+	ErrInvalidBandwidthPackageSize = errors.New("bandwidth of the bandwidth package created by the module must be between 2 and 20000")
+)
+
+const (
+	alicloudRegionEnv = "ALICLOUD_REGION"
+
+	alicloudSLBLoadBalancer         = "alicloud_slb_load_balancer"
+	alicloudEIPAddress              = "alicloud_eip_address"
+	alicloudEIPAssociation          = "alicloud_eip_association"
+	alicloudBandwidthPackage        = "alicloud_common_bandwidth_package"
+	alicloudBandwidthPackageAttach  = "alicloud_common_bandwidth_package_attachment"
+	alicloudLoadBalancerIDKey       = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-id"
+	alicloudOverrideListenersKey    = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-force-override-listeners"
+	alicloudPayByTraffic            = "PayByTraffic"
+	alicloudPayByBandwidth          = "PayByBandwidth"
+	defaultEIPBandwidth             = 5
+	defaultAlicloudLoadBalancerSpec = "slb.s1.small"
+)
+
+var defaultAlicloudProviderCfg = module.ProviderConfig{
+	Source:  "aliyun/alicloud",
+	Version: "1.209.1",
+}
+
+// EIP describes the elastic IP address bound to the load balancer of the public ports on
+// alicloud, instead of the ephemeral address picked by the cloud. The load balancer is created
+// as an intranet one by the module, and the EIP is associated with it.
+type EIP struct {
+	// AllocationID is the ID of the pre-allocated EIP, which is created by the module if empty.
+	AllocationID string `yaml:"allocationID,omitempty" json:"allocationID,omitempty"`
+
+	// Bandwidth is the peak bandwidth in Mbps of the EIP created by the module, defaults to 5.
+	Bandwidth int `yaml:"bandwidth,omitempty" json:"bandwidth,omitempty"`
+
+	// InternetChargeType is the billing method of the EIP created by the module, PayByTraffic or
+	// PayByBandwidth, defaults to PayByTraffic.
+	InternetChargeType string `yaml:"internetChargeType,omitempty" json:"internetChargeType,omitempty"`
+
+	// BandwidthPackage is the common bandwidth package the EIP is added to, which shares and
+	// limits the bandwidth of the EIPs in it.
+	BandwidthPackage *BandwidthPackage `yaml:"bandwidthPackage,omitempty" json:"bandwidthPackage,omitempty"`
+
+	// The region and the vSwitch of the load balancer, which are retrieved from platform config.
+	region    string
+	vSwitchID string
+}
+
+// BandwidthPackage describes the common bandwidth package of alicloud.
+type BandwidthPackage struct {
+	// ID is the ID of the pre-created bandwidth package, which is created by the module if empty.
+	ID string `yaml:"id,omitempty" json:"id,omitempty"`
+
+	// Bandwidth is the peak bandwidth in Mbps of the bandwidth package created by the module.
+	Bandwidth int `yaml:"bandwidth,omitempty" json:"bandwidth,omitempty"`
+}
+
+// CompleteEIPConfig completes the eip config with the region and the vSwitch of the load balancer
+// in the port of platform config.
+func (network *Network) CompleteEIPConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	value, ok := devConfig[FieldEIP]
+	if !ok || value == nil {
+		return nil
+	}
+	yamlStr, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	eip := &EIP{}
+	if err = yaml.Unmarshal(yamlStr, eip); err != nil {
+		return fmt.Errorf("failed to retrieve eip from dev config: %v", err)
+	}
+	if eip.AllocationID == "" {
+		if eip.Bandwidth == 0 {
+			eip.Bandwidth = defaultEIPBandwidth
+		}
+		if eip.InternetChargeType == "" {
+			eip.InternetChargeType = alicloudPayByTraffic
+		}
+	}
+
+	if portConfig, err := toMapStringInterface(platformConfig["port"]); err == nil {
+		eip.region, _ = portConfig[FieldRegion].(string)
+		eip.vSwitchID, _ = portConfig[FieldVSwitchID].(string)
+	}
+	if eip.region == "" {
+		eip.region = os.Getenv(alicloudRegionEnv)
+	}
+
+	network.EIP = eip
+	return nil
+}
+
+// ValidateEIP validates whether the eip config is valid or not.
+func (network *Network) ValidateEIP() error {
+	eip := network.EIP
+	if eip == nil {
+		return nil
+	}
+	ports := groupPorts(network.Ports)[suffixPublic]
+	if len(ports) == 0 || ports[0].Type != CSPAliCloud {
+		return ErrUnsupportedEIP
+	}
+	if eip.AllocationID != "" && (eip.Bandwidth != 0 || eip.InternetChargeType != "") {
+		return ErrEIPOptionsWithAllocation
+	}
+	if eip.Bandwidth < 0 || eip.Bandwidth > 10000 {
+		return ErrInvalidEIPBandwidth
+	}
+	if eip.InternetChargeType != "" && eip.InternetChargeType != alicloudPayByTraffic &&
+		eip.InternetChargeType != alicloudPayByBandwidth {
+		return ErrInvalidEIPChargeType
+	}
+	if pkg := eip.BandwidthPackage; pkg != nil && pkg.ID == "" && (pkg.Bandwidth < 2 || pkg.Bandwidth > 20000) {
+		return ErrInvalidBandwidthPackageSize
+	}
+	if eip.vSwitchID == "" {
+		return ErrEmptyEIPVSwitchID
+	}
+	if eip.region == "" {
+		return ErrEmptyEIPRegion
+	}
+
+	return nil
+}
+
+// GenerateEIPResources generates the intranet load balancer of the public ports along with the EIP
+// associated with it, and the bandwidth package the EIP is added to. It returns the annotations
+// making the public Service reuse the load balancer, and the IDs of the resources the Service
+// depends on.
+func (network *Network) GenerateEIPResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, map[string]string, []string, error) {
+	eip := network.EIP
+	if eip == nil {
+		return nil, nil, nil, nil
+	}
+	providerCfg := defaultAlicloudProviderCfg
+	providerCfg.ProviderMeta = map[string]any{"region": eip.region}
+	name := moduleutil.ResourceName(request, suffixPublic, moduleutil.AlicloudNamingRule)
+
+	var resources []kusionapiv1.Resource
+	wrap := func(resType string, attrs map[string]interface{}) (string, error) {
+		id, err := module.TerraformResourceID(providerCfg, resType, name)
+		if err != nil {
+			return "", err
+		}
+		resource, err := module.WrapTFResourceToKusionResource(providerCfg, resType, id, attrs, nil)
+		if err != nil {
+			return "", err
+		}
+		resources = append(resources, *resource)
+		return id, nil
+	}
+
+	slbID, err := wrap(alicloudSLBLoadBalancer, map[string]interface{}{
+		"load_balancer_name": name,
+		"address_type":       "intranet",
+		"vswitch_id":         eip.vSwitchID,
+		"load_balancer_spec": defaultAlicloudLoadBalancerSpec,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	allocationID := eip.AllocationID
+	if allocationID == "" {
+		eipID, err := wrap(alicloudEIPAddress, map[string]interface{}{
+			"address_name":         name,
+			"bandwidth":            fmt.Sprint(eip.Bandwidth),
+			"internet_charge_type": eip.InternetChargeType,
+			"payment_type":         "PayAsYouGo",
+		})
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		allocationID = module.KusionPathDependency(eipID, "id")
+	}
+
+	associationID, err := wrap(alicloudEIPAssociation, map[string]interface{}{
+		"allocation_id": allocationID,
+		"instance_id":   module.KusionPathDependency(slbID, "id"),
+		"instance_type": "SlbInstance",
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if pkg := eip.BandwidthPackage; pkg != nil {
+		packageID := pkg.ID
+		if packageID == "" {
+			id, err := wrap(alicloudBandwidthPackage, map[string]interface{}{
+				"bandwidth_package_name": name,
+				"bandwidth":              fmt.Sprint(pkg.Bandwidth),
+				"internet_charge_type":   alicloudPayByBandwidth,
+			})
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			packageID = module.KusionPathDependency(id, "id")
+		}
+		if _, err = wrap(alicloudBandwidthPackageAttach, map[string]interface{}{
+			"bandwidth_package_id": packageID,
+			"instance_id":          allocationID,
+		}); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	annotations := map[string]string{
+		alicloudLoadBalancerIDKey:    module.KusionPathDependency(slbID, "id"),
+		alicloudOverrideListenersKey: "true",
+	}
+	return resources, annotations, []string{slbID, associationID}, nil
+}
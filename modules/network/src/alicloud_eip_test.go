@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestNetworkModule_EIP(t *testing.T) {
+	newRequest := func(eip map[string]any, portType string) *module.GeneratorRequest {
+		return &module.GeneratorRequest{
+			Project:  "default",
+			Stack:    "dev",
+			App:      "foo",
+			Workload: kusionapiv1.Accessory{"type": "Deployment"},
+			DevConfig: kusionapiv1.Accessory{
+				"ports": []interface{}{
+					map[string]any{"port": 80, "protocol": "TCP", "public": true},
+				},
+				"eip": eip,
+			},
+			PlatformConfig: kusionapiv1.GenericConfig{
+				"port": map[string]any{
+					"type":      portType,
+					"region":    "cn-hangzhou",
+					"vSwitchID": "vsw-foo",
+				},
+			},
+		}
+	}
+	resourceID := func(resType string) string {
+		id, _ := module.TerraformResourceID(defaultAlicloudProviderCfg, resType, "default-dev-foo-public")
+		return id
+	}
+
+	t.Run("module-created eip and bandwidth package", func(t *testing.T) {
+		network := &Network{}
+		response, err := network.Generate(context.Background(), newRequest(map[string]any{
+			"bandwidthPackage": map[string]any{"bandwidth": 100},
+		}, CSPAliCloud))
+		assert.NoError(t, err)
+		assert.Len(t, response.Resources, 6)
+
+		slbID := resourceID(alicloudSLBLoadBalancer)
+		eipID := resourceID(alicloudEIPAddress)
+		associationID := resourceID(alicloudEIPAssociation)
+		packageID := resourceID(alicloudBandwidthPackage)
+		assert.Equal(t, slbID, response.Resources[0].ID)
+		assert.Equal(t, "intranet", response.Resources[0].Attributes["address_type"])
+		assert.Equal(t, "vsw-foo", response.Resources[0].Attributes["vswitch_id"])
+		assert.Equal(t, eipID, response.Resources[1].ID)
+		assert.Equal(t, "5", response.Resources[1].Attributes["bandwidth"])
+		assert.Equal(t, alicloudPayByTraffic, response.Resources[1].Attributes["internet_charge_type"])
+		assert.Equal(t, associationID, response.Resources[2].ID)
+		assert.Equal(t, module.KusionPathDependency(eipID, "id"), response.Resources[2].Attributes["allocation_id"])
+		assert.Equal(t, module.KusionPathDependency(slbID, "id"), response.Resources[2].Attributes["instance_id"])
+		assert.Equal(t, packageID, response.Resources[3].ID)
+		assert.Equal(t, "100", response.Resources[3].Attributes["bandwidth"])
+		assert.Equal(t, module.KusionPathDependency(packageID, "id"), response.Resources[4].Attributes["bandwidth_package_id"])
+		assert.Equal(t, module.KusionPathDependency(eipID, "id"), response.Resources[4].Attributes["instance_id"])
+
+		svc := &v1.Service{}
+		err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[5].Attributes, svc)
+		assert.NoError(t, err)
+		assert.Equal(t, module.KusionPathDependency(slbID, "id"), svc.Annotations[alicloudLoadBalancerIDKey])
+		assert.Equal(t, "true", svc.Annotations[alicloudOverrideListenersKey])
+		assert.Equal(t, []string{slbID, associationID}, response.Resources[5].DependsOn)
+	})
+
+	t.Run("pre-allocated eip and bandwidth package", func(t *testing.T) {
+		network := &Network{}
+		response, err := network.Generate(context.Background(), newRequest(map[string]any{
+			"allocationID":     "eip-foo",
+			"bandwidthPackage": map[string]any{"id": "cbwp-foo"},
+		}, CSPAliCloud))
+		assert.NoError(t, err)
+		assert.Len(t, response.Resources, 4)
+		assert.Equal(t, "eip-foo", response.Resources[1].Attributes["allocation_id"])
+		assert.Equal(t, resourceID(alicloudBandwidthPackageAttach), response.Resources[2].ID)
+		assert.Equal(t, "cbwp-foo", response.Resources[2].Attributes["bandwidth_package_id"])
+		assert.Equal(t, "eip-foo", response.Resources[2].Attributes["instance_id"])
+	})
+
+	tests := []struct {
+		name     string
+		eip      map[string]any
+		portType string
+		expected error
+	}{
+		{
+			name:     "aws port",
+			eip:      map[string]any{},
+			portType: CSPAWS,
+			expected: ErrUnsupportedEIP,
+		},
+		{
+			name:     "bandwidth with allocation",
+			eip:      map[string]any{"allocationID": "eip-foo", "bandwidth": 10},
+			portType: CSPAliCloud,
+			expected: ErrEIPOptionsWithAllocation,
+		},
+		{
+			name:     "invalid bandwidth",
+			eip:      map[string]any{"bandwidth": 10001},
+			portType: CSPAliCloud,
+			expected: ErrInvalidEIPBandwidth,
+		},
+		{
+			name:     "invalid charge type",
+			eip:      map[string]any{"internetChargeType": "PayByHour"},
+			portType: CSPAliCloud,
+			expected: ErrInvalidEIPChargeType,
+		},
+		{
+			name:     "bandwidth package without bandwidth",
+			eip:      map[string]any{"bandwidthPackage": map[string]any{}},
+			portType: CSPAliCloud,
+			expected: ErrInvalidBandwidthPackageSize,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := &Network{}
+			_, err := network.Generate(context.Background(), newRequest(tt.eip, tt.portType))
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+
+	t.Run("empty vSwitchID", func(t *testing.T) {
+		request := newRequest(map[string]any{}, CSPAliCloud)
+		request.PlatformConfig["port"] = map[string]any{"type": CSPAliCloud, "region": "cn-hangzhou"}
+		network := &Network{}
+		_, err := network.Generate(context.Background(), request)
+		assert.ErrorIs(t, err, ErrEmptyEIPVSwitchID)
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+func TestNetworkModule_ConnectionInfo(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project:  "default",
+		Stack:    "dev",
+		App:      "foo",
+		Workload: kusionapiv1.Accessory{"type": "Deployment"},
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{"port": 80, "protocol": "TCP", "public": true, "hostname": "foo.example.com"},
+				map[string]any{"port": 8080, "protocol": "TCP"},
+			},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"port": map[string]any{"type": "aws"},
+		},
+	}
+
+	response, err := (&Network{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 2)
+
+	request.Context = kusionapiv1.GenericConfig{moduleutil.ConnectionInfoKey: true, moduleutil.PreviewSummaryKey: true}
+	response, err = (&Network{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 3)
+
+	cm := response.Resources[2]
+	assert.Equal(t, "v1:ConfigMap:default:default-dev-foo-network-connection-info", cm.ID)
+	expected := map[string]string{
+		"network.public.80":    "foo.example.com:80",
+		"network.private.8080": "default-dev-foo-private.default.svc:8080",
+	}
+	assert.Equal(t, expected, moduleutil.ConnectionInfoData(cm))
+	assert.Equal(t, expected, response.Resources[0].Extensions[moduleutil.SummaryExtensionKey].(moduleutil.Summary).ConnectionInfo)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidHealthCheckPath      = errors.New("path of healthCheck must start with /")
+	ErrInvalidHealthCheckPort      = errors.New("port of healthCheck must be between 1 and 65535 if exist")
+	ErrInvalidHealthCheckInterval  = errors.New("intervalSeconds of healthCheck must be between 5 and 50 if exist")
+	ErrInvalidHealthCheckTimeout   = errors.New("timeoutSeconds of healthCheck must be between 2 and 60, and less than intervalSeconds if exist")
+	ErrInvalidHealthCheckThreshold = errors.New("healthyThreshold and unhealthyThreshold of healthCheck must be between 2 and 10 if exist")
+	ErrUnsupportedStickiness       = errors.New("stickiness works only for the http ports of alicloud for now")
+	ErrInvalidStickiness           = errors.New("stickiness must specify either cookie or cookieTimeoutSeconds between 1 and 86400")
+)
+
+// HealthCheck describes the health check of the load balancer to the backends, which is translated
+// to the annotations of the cloud vendor. The defaults of the cloud vendor are used for the unset
+// fields.
+type HealthCheck struct {
+	// Path is the HTTP path of the health check, the TCP health check is used if empty.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+
+	// Port is the backend port of the health check, defaults to the traffic port.
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// IntervalSeconds is the interval between the health checks.
+	IntervalSeconds int `yaml:"intervalSeconds,omitempty" json:"intervalSeconds,omitempty"`
+
+	// TimeoutSeconds is the timeout of each health check.
+	TimeoutSeconds int `yaml:"timeoutSeconds,omitempty" json:"timeoutSeconds,omitempty"`
+
+	// HealthyThreshold is the consecutive successes before the backend is considered healthy.
+	HealthyThreshold int `yaml:"healthyThreshold,omitempty" json:"healthyThreshold,omitempty"`
+
+	// UnhealthyThreshold is the consecutive failures before the backend is considered unhealthy.
+	UnhealthyThreshold int `yaml:"unhealthyThreshold,omitempty" json:"unhealthyThreshold,omitempty"`
+}
+
+// Stickiness describes the cookie-based session stickiness of the load balancer, which is
+// different from the ClientIP session affinity of the Services.
+type Stickiness struct {
+	// Cookie is the name of the cookie set by the backends to stick the sessions on, the load
+	// balancer inserts its own cookie if empty.
+	Cookie string `yaml:"cookie,omitempty" json:"cookie,omitempty"`
+
+	// CookieTimeoutSeconds is the timeout of the cookie inserted by the load balancer, works only
+	// when the cookie is empty.
+	CookieTimeoutSeconds int `yaml:"cookieTimeoutSeconds,omitempty" json:"cookieTimeoutSeconds,omitempty"`
+}
+
+// validateLoadBalancerOptions validates the health check and the stickiness of the load balancers.
+func (network *Network) validateLoadBalancerOptions() error {
+	if hc := network.HealthCheck; hc != nil {
+		if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+			return ErrInvalidHealthCheckPath
+		}
+		if hc.Port < 0 || hc.Port > 65535 {
+			return ErrInvalidHealthCheckPort
+		}
+		if hc.IntervalSeconds != 0 && (hc.IntervalSeconds < 5 || hc.IntervalSeconds > 50) {
+			return ErrInvalidHealthCheckInterval
+		}
+		if hc.TimeoutSeconds != 0 && (hc.TimeoutSeconds < 2 || hc.TimeoutSeconds > 60 ||
+			(hc.IntervalSeconds != 0 && hc.TimeoutSeconds >= hc.IntervalSeconds)) {
+			return ErrInvalidHealthCheckTimeout
+		}
+		for _, threshold := range []int{hc.HealthyThreshold, hc.UnhealthyThreshold} {
+			if threshold != 0 && (threshold < 2 || threshold > 10) {
+				return ErrInvalidHealthCheckThreshold
+			}
+		}
+	}
+
+	if s := network.Stickiness; s != nil {
+		if (s.Cookie == "") == (s.CookieTimeoutSeconds == 0) || s.CookieTimeoutSeconds < 0 ||
+			s.CookieTimeoutSeconds > 86400 {
+			return ErrInvalidStickiness
+		}
+		// The sticky sessions are supported only by the HTTP and HTTPS listeners of alicloud.
+		groupedPorts := groupPorts(network.Ports)
+		for _, exposure := range []string{suffixPublic, suffixInternal} {
+			for _, port := range groupedPorts[exposure] {
+				if port.Type != CSPAliCloud || port.Protocol != ProtocolTCP ||
+					(port.AppProtocol != AppProtocolHTTP && (port.TLS == nil || port.AppProtocol != "")) {
+					return ErrUnsupportedStickiness
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadBalancerOptionAnnotations returns the cloud-specific annotations of the health check and
+// the stickiness of the load balancer.
+func loadBalancerOptionAnnotations(ports []Port, options *ServiceOptions) map[string]string {
+	annotations := make(map[string]string)
+	set := func(key string, value int) {
+		if value != 0 {
+			annotations[key] = strconv.Itoa(value)
+		}
+	}
+
+	switch ports[0].Type {
+	case CSPAWS:
+		const prefix = "service.beta.kubernetes.io/aws-load-balancer-healthcheck-"
+		if hc := options.HealthCheck; hc != nil {
+			if hc.Path != "" {
+				annotations[prefix+"protocol"] = "HTTP"
+				annotations[prefix+"path"] = hc.Path
+			}
+			set(prefix+"port", hc.Port)
+			set(prefix+"interval", hc.IntervalSeconds)
+			set(prefix+"timeout", hc.TimeoutSeconds)
+			set(prefix+"healthy-threshold", hc.HealthyThreshold)
+			set(prefix+"unhealthy-threshold", hc.UnhealthyThreshold)
+		}
+	case CSPAliCloud:
+		const prefix = "service.beta.kubernetes.io/alibaba-cloud-loadbalancer-"
+		if hc := options.HealthCheck; hc != nil {
+			annotations[prefix+"health-check-flag"] = "on"
+			if hc.Path != "" {
+				annotations[prefix+"health-check-type"] = "http"
+				annotations[prefix+"health-check-uri"] = hc.Path
+				set(prefix+"health-check-timeout", hc.TimeoutSeconds)
+			} else {
+				annotations[prefix+"health-check-type"] = "tcp"
+				set(prefix+"health-check-connect-timeout", hc.TimeoutSeconds)
+			}
+			set(prefix+"health-check-connect-port", hc.Port)
+			set(prefix+"health-check-interval", hc.IntervalSeconds)
+			set(prefix+"healthy-threshold", hc.HealthyThreshold)
+			set(prefix+"unhealthy-threshold", hc.UnhealthyThreshold)
+		}
+		if s := options.Stickiness; s != nil {
+			annotations[prefix+"sticky-session"] = "on"
+			if s.Cookie != "" {
+				annotations[prefix+"sticky-session-type"] = "server"
+				annotations[prefix+"cookie"] = s.Cookie
+			} else {
+				annotations[prefix+"sticky-session-type"] = "insert"
+				set(prefix+"cookie-timeout", s.CookieTimeoutSeconds)
+			}
+		}
+	}
+
+	return annotations
+}
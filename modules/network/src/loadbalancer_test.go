@@ -0,0 +1,147 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestNetworkModule_LoadBalancerOptions(t *testing.T) {
+	newRequest := func(portType string, options map[string]any) *module.GeneratorRequest {
+		devConfig := kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{"port": 80, "protocol": "TCP", "appProtocol": "http", "public": true},
+			},
+		}
+		for k, v := range options {
+			devConfig[k] = v
+		}
+		return &module.GeneratorRequest{
+			Project:        "test-project",
+			Stack:          "test-stack",
+			App:            "test-app",
+			DevConfig:      devConfig,
+			PlatformConfig: kusionapiv1.GenericConfig{"port": map[string]any{"type": portType}},
+		}
+	}
+	healthCheck := map[string]any{
+		"path":               "/healthz",
+		"intervalSeconds":    10,
+		"timeoutSeconds":     5,
+		"healthyThreshold":   2,
+		"unhealthyThreshold": 3,
+	}
+
+	tests := []struct {
+		name     string
+		portType string
+		options  map[string]any
+		expected map[string]string
+	}{
+		{
+			name:     "aws health check",
+			portType: CSPAWS,
+			options:  map[string]any{"healthCheck": healthCheck},
+			expected: map[string]string{
+				"service.beta.kubernetes.io/aws-load-balancer-healthcheck-protocol":            "HTTP",
+				"service.beta.kubernetes.io/aws-load-balancer-healthcheck-path":                "/healthz",
+				"service.beta.kubernetes.io/aws-load-balancer-healthcheck-interval":            "10",
+				"service.beta.kubernetes.io/aws-load-balancer-healthcheck-timeout":             "5",
+				"service.beta.kubernetes.io/aws-load-balancer-healthcheck-healthy-threshold":   "2",
+				"service.beta.kubernetes.io/aws-load-balancer-healthcheck-unhealthy-threshold": "3",
+			},
+		},
+		{
+			name:     "alicloud health check and inserted cookie",
+			portType: CSPAliCloud,
+			options: map[string]any{
+				"healthCheck": healthCheck,
+				"stickiness":  map[string]any{"cookieTimeoutSeconds": 1800},
+			},
+			expected: map[string]string{
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-flag":     "on",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-type":     "http",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-uri":      "/healthz",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-interval": "10",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-timeout":  "5",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-healthy-threshold":     "2",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-unhealthy-threshold":   "3",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-sticky-session":        "on",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-sticky-session-type":   "insert",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-cookie-timeout":        "1800",
+			},
+		},
+		{
+			name:     "alicloud tcp health check and server cookie",
+			portType: CSPAliCloud,
+			options: map[string]any{
+				"healthCheck": map[string]any{"port": 8081, "timeoutSeconds": 3},
+				"stickiness":  map[string]any{"cookie": "SESSIONID"},
+			},
+			expected: map[string]string{
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-flag":            "on",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-type":            "tcp",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-connect-port":    "8081",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-health-check-connect-timeout": "3",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-sticky-session":               "on",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-sticky-session-type":          "server",
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-cookie":                       "SESSIONID",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRequest(tt.portType, tt.options)
+			network := &Network{}
+			assert.NoError(t, network.GetCompleteConfig(r.DevConfig, r.PlatformConfig))
+			ports := groupPorts(network.Ports)[suffixPublic]
+			assert.Equal(t, tt.expected, loadBalancerOptionAnnotations(ports, &network.ServiceOptions))
+		})
+	}
+
+	errTests := []struct {
+		name     string
+		portType string
+		options  map[string]any
+		expected error
+	}{
+		{
+			name:     "invalid path",
+			portType: CSPAWS,
+			options:  map[string]any{"healthCheck": map[string]any{"path": "healthz"}},
+			expected: ErrInvalidHealthCheckPath,
+		},
+		{
+			name:     "timeout not less than interval",
+			portType: CSPAWS,
+			options:  map[string]any{"healthCheck": map[string]any{"intervalSeconds": 5, "timeoutSeconds": 5}},
+			expected: ErrInvalidHealthCheckTimeout,
+		},
+		{
+			name:     "invalid threshold",
+			portType: CSPAliCloud,
+			options:  map[string]any{"healthCheck": map[string]any{"unhealthyThreshold": 1}},
+			expected: ErrInvalidHealthCheckThreshold,
+		},
+		{
+			name:     "stickiness on aws",
+			portType: CSPAWS,
+			options:  map[string]any{"stickiness": map[string]any{"cookieTimeoutSeconds": 60}},
+			expected: ErrUnsupportedStickiness,
+		},
+		{
+			name:     "stickiness with both cookie and timeout",
+			portType: CSPAliCloud,
+			options:  map[string]any{"stickiness": map[string]any{"cookie": "SESSIONID", "cookieTimeoutSeconds": 60}},
+			expected: ErrInvalidStickiness,
+		},
+	}
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRequest(tt.portType, tt.options)
+			assert.ErrorIs(t, (&Network{}).GetCompleteConfig(r.DevConfig, r.PlatformConfig), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html"
+
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	FieldMaintenance = "maintenance"
+
+	suffixMaintenance = "maintenance"
+
+	// maintenanceLabel is the pod label marking the maintenance backend.
+	maintenanceLabel = "kusionstack.io/maintenance"
+	// configChecksumAnnotation is the pod annotation holding the checksum of the page.
+	configChecksumAnnotation = "kusionstack.io/config-checksum"
+	// maintenancePort is the port the maintenance page is served on.
+	maintenancePort = 8080
+
+	defaultMaintenanceImage   = "nginxinc/nginx-unprivileged:1.27-alpine"
+	defaultMaintenanceMessage = "The service is under maintenance, please try again later."
+
+	maintenanceConfKey = "default.conf"
+	maintenancePageKey = "maintenance.html"
+)
+
+var (
+	ErrMaintenanceWithoutPorts   = errors.New("maintenance requires at least one port exposed by the Services")
+	ErrMaintenanceUDPPort        = errors.New("maintenance works only for the TCP ports")
+	ErrInvalidMaintenanceReplica = errors.New("replicas of maintenance must not be negative")
+	ErrInvalidRetryAfter         = errors.New("retryAfterSeconds of maintenance must not be negative")
+)
+
+// Maintenance describes the maintenance backend serving a static "under maintenance" page with
+// the 503 status, which the Services of the exposed ports along with the preview Service are
+// switched to when enabled, e.g. during the migrations. The HTTPRoute of preview is left as it is,
+// whose backends are all switched. The workload keeps running and is reachable again once disabled.
+type Maintenance struct {
+	// Enabled switches the traffic of the exposed ports to the maintenance backend.
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Message is the message shown on the default maintenance page.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+
+	// Page is the HTML of the maintenance page, which overrides the default one.
+	Page string `yaml:"page,omitempty" json:"page,omitempty"`
+
+	// RetryAfterSeconds is the value of the Retry-After header of the responses, which is not
+	// set if zero.
+	RetryAfterSeconds int `yaml:"retryAfterSeconds,omitempty" json:"retryAfterSeconds,omitempty"`
+
+	// Replicas is the number of the maintenance pods, defaults to 1.
+	Replicas int32 `yaml:"replicas,omitempty" json:"replicas,omitempty"`
+
+	// Image is the nginx image serving the page, which is retrieved from platform config if empty.
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+}
+
+// MaintenancePlatformConfig describes the platform config of the maintenance backend.
+type MaintenancePlatformConfig struct {
+	// Image is the default nginx image serving the maintenance page, which must listen on 8080
+	// as a non-root user, e.g. the nginx-unprivileged one.
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+}
+
+// CompleteMaintenanceConfig completes the maintenance config with the defaults and the image in
+// platform config.
+func (network *Network) CompleteMaintenanceConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	value, ok := devConfig[FieldMaintenance]
+	if !ok || value == nil {
+		return nil
+	}
+	yamlStr, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	maintenance := &Maintenance{}
+	if err = yaml.Unmarshal(yamlStr, maintenance); err != nil {
+		return fmt.Errorf("failed to retrieve maintenance from dev config: %v", err)
+	}
+
+	if maintenance.Image == "" {
+		if pc, ok := platformConfig[FieldMaintenance]; ok && pc != nil {
+			yamlStr, err = yaml.Marshal(pc)
+			if err != nil {
+				return err
+			}
+			platform := &MaintenancePlatformConfig{}
+			if err = yaml.Unmarshal(yamlStr, platform); err != nil {
+				return fmt.Errorf("failed to retrieve maintenance from platform config: %v", err)
+			}
+			maintenance.Image = platform.Image
+		}
+	}
+	if maintenance.Image == "" {
+		maintenance.Image = defaultMaintenanceImage
+	}
+	if maintenance.Message == "" {
+		maintenance.Message = defaultMaintenanceMessage
+	}
+	if maintenance.Replicas == 0 {
+		maintenance.Replicas = 1
+	}
+
+	network.Maintenance = maintenance
+	return nil
+}
+
+// ValidateMaintenance validates whether the maintenance config is valid or not.
+func (network *Network) ValidateMaintenance() error {
+	maintenance := network.Maintenance
+	if maintenance == nil {
+		return nil
+	}
+	if maintenance.Replicas < 0 {
+		return ErrInvalidMaintenanceReplica
+	}
+	if maintenance.RetryAfterSeconds < 0 {
+		return ErrInvalidRetryAfter
+	}
+	if !maintenance.Enabled {
+		return nil
+	}
+
+	exposed := false
+	for _, ports := range groupPorts(network.Ports) {
+		for _, port := range ports {
+			if port.Protocol != ProtocolTCP {
+				return fmt.Errorf("%w, got %d/%s", ErrMaintenanceUDPPort, port.Port, port.Protocol)
+			}
+			exposed = true
+		}
+	}
+	if !exposed {
+		return ErrMaintenanceWithoutPorts
+	}
+
+	return nil
+}
+
+// maintenanceEnabled returns whether the traffic is switched to the maintenance backend.
+func (network *Network) maintenanceEnabled() bool {
+	return network.Maintenance != nil && network.Maintenance.Enabled
+}
+
+// maintenanceLabels returns the labels of the maintenance backend, which are named after the App
+// with the maintenance suffix, so that they are disjoint from the selectors of the workload and
+// the maintenance pods are never adopted by the workload nor selected by its Services.
+func maintenanceLabels(request *module.GeneratorRequest) map[string]string {
+	name := moduleutil.SanitizeName(request.App+"-"+suffixMaintenance, moduleutil.KubernetesNamingRule)
+	labels := module.UniqueAppLabels(request.Project, name)
+	labels[maintenanceLabel] = "true"
+	return labels
+}
+
+// switchToMaintenance switches the Service of the exposed ports to the maintenance backend, whose
+// selector is replaced to select the maintenance pods only, and targets the port of the page.
+func switchToMaintenance(request *module.GeneratorRequest, svc *v1.Service) {
+	svc.Spec.Selector = maintenanceLabels(request)
+	for i := range svc.Spec.Ports {
+		svc.Spec.Ports[i].TargetPort = intstr.FromInt(maintenancePort)
+		svc.Spec.Ports[i].AppProtocol = nil
+	}
+}
+
+// GenerateMaintenanceResources generates the ConfigMap of the maintenance page along with the
+// Deployment serving it, which are generated only when the maintenance is enabled.
+func (network *Network) GenerateMaintenanceResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	if !network.maintenanceEnabled() {
+		return nil, nil
+	}
+	maintenance := network.Maintenance
+	name := moduleutil.ResourceName(request, suffixMaintenance, moduleutil.KubernetesNamingRule)
+	labels := maintenanceLabels(request)
+
+	page := maintenance.Page
+	if page == "" {
+		page = maintenancePage(maintenance.Message)
+	}
+	cm := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    labels,
+		},
+		Data: map[string]string{
+			maintenanceConfKey: maintenanceConf(maintenance.RetryAfterSeconds),
+			maintenancePageKey: page,
+		},
+	}
+	cmID := module.KubernetesResourceID(cm.TypeMeta, cm.ObjectMeta)
+	cmResource, err := module.WrapK8sResourceToKusionResource(cmID, cm)
+	if err != nil {
+		return nil, err
+	}
+
+	nonRoot, readOnly, privileged := true, true, false
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &maintenance.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+					// Restart the pods once the page is changed.
+					Annotations: map[string]string{
+						configChecksumAnnotation: fmt.Sprintf("%x", sha256.Sum256([]byte(cm.Data[maintenanceConfKey]+page))),
+					},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  suffixMaintenance,
+							Image: maintenance.Image,
+							Ports: []v1.ContainerPort{
+								{Name: "http", ContainerPort: maintenancePort, Protocol: v1.ProtocolTCP},
+							},
+							ReadinessProbe: &v1.Probe{
+								ProbeHandler: v1.ProbeHandler{
+									HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(maintenancePort)},
+								},
+							},
+							Resources: v1.ResourceRequirements{
+								Requests: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse("10m"),
+									v1.ResourceMemory: resource.MustParse("16Mi"),
+								},
+								Limits: v1.ResourceList{
+									v1.ResourceCPU:    resource.MustParse("100m"),
+									v1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{Name: "conf", MountPath: "/etc/nginx/conf.d", ReadOnly: true},
+								{Name: "page", MountPath: "/usr/share/nginx/html", ReadOnly: true},
+								{Name: "tmp", MountPath: "/tmp"},
+							},
+							SecurityContext: &v1.SecurityContext{
+								RunAsNonRoot:             &nonRoot,
+								ReadOnlyRootFilesystem:   &readOnly,
+								AllowPrivilegeEscalation: &privileged,
+								Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+								SeccompProfile:           &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						configMapVolume("conf", name, maintenanceConfKey),
+						configMapVolume("page", name, maintenancePageKey),
+						{Name: "tmp", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+					},
+				},
+			},
+		},
+	}
+	deploymentID := module.KubernetesResourceID(deployment.TypeMeta, deployment.ObjectMeta)
+	deploymentResource, err := module.WrapK8sResourceToKusionResource(deploymentID, deployment)
+	if err != nil {
+		return nil, err
+	}
+	deploymentResource.DependsOn = []string{cmID}
+
+	return []kusionapiv1.Resource{*cmResource, *deploymentResource}, nil
+}
+
+// maintenanceDeploymentID returns the ID of the Deployment serving the maintenance page.
+func maintenanceDeploymentID(request *module.GeneratorRequest) string {
+	return module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+		metav1.ObjectMeta{Name: moduleutil.ResourceName(request, suffixMaintenance, moduleutil.KubernetesNamingRule), Namespace: request.Project},
+	)
+}
+
+// configMapVolume returns the volume projecting the key of the ConfigMap.
+func configMapVolume(name, configMap, key string) v1.Volume {
+	return v1.Volume{
+		Name: name,
+		VolumeSource: v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{Name: configMap},
+				Items:                []v1.KeyToPath{{Key: key, Path: key}},
+			},
+		},
+	}
+}
+
+// maintenanceConf returns the nginx config serving the maintenance page with the 503 status for
+// all the requests except the health checks of the readiness probe.
+func maintenanceConf(retryAfterSeconds int) string {
+	retryAfter := ""
+	if retryAfterSeconds > 0 {
+		retryAfter = fmt.Sprintf("        add_header Retry-After %d always;\n", retryAfterSeconds)
+	}
+	return fmt.Sprintf(`server {
+    listen %d;
+    server_tokens off;
+    error_page 503 /%s;
+
+    location = /healthz {
+        access_log off;
+        return 200;
+    }
+
+    location = /%s {
+        internal;
+        root /usr/share/nginx/html;
+%s    }
+
+    location / {
+        return 503;
+    }
+}
+`, maintenancePort, maintenancePageKey, maintenancePageKey, retryAfter)
+}
+
+// maintenancePage returns the default maintenance page showing the message.
+func maintenancePage(message string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Under Maintenance</title>
+</head>
+<body>
+<h1>Under Maintenance</h1>
+<p>%s</p>
+</body>
+</html>
+`, html.EscapeString(message))
+}
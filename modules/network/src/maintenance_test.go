@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestNetworkModule_Maintenance(t *testing.T) {
+	newRequest := func(ports []interface{}, maintenance map[string]any) *module.GeneratorRequest {
+		return &module.GeneratorRequest{
+			Project:  "default",
+			Stack:    "dev",
+			App:      "foo",
+			Workload: kusionapiv1.Accessory{"type": "Deployment"},
+			DevConfig: kusionapiv1.Accessory{
+				"ports":       ports,
+				"maintenance": maintenance,
+			},
+			PlatformConfig: kusionapiv1.GenericConfig{
+				"maintenance": map[string]any{"image": "registry.example.com/nginx-unprivileged:1.27"},
+			},
+		}
+	}
+	ports := []interface{}{
+		map[string]any{"port": 80, "targetPort": 3000, "protocol": "TCP", "appProtocol": "http"},
+	}
+
+	network := &Network{}
+	request := newRequest(ports, map[string]any{
+		"enabled":           true,
+		"message":           "Back at <b>10:00</b>",
+		"retryAfterSeconds": 600,
+	})
+	response, err := network.Generate(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 3)
+
+	// The selector of the Service is replaced with the labels of the maintenance pods, which are
+	// disjoint from the selector of the workload.
+	svc := &v1.Service{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[0].Attributes, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/part-of": "default",
+		"app.kubernetes.io/name":    "foo-maintenance",
+		maintenanceLabel:            "true",
+	}, svc.Spec.Selector)
+	assert.Equal(t, intstr.FromInt(maintenancePort), svc.Spec.Ports[0].TargetPort)
+	assert.Nil(t, svc.Spec.Ports[0].AppProtocol)
+	assert.Equal(t, []string{"apps/v1:Deployment:default:default-dev-foo-maintenance"}, response.Resources[0].DependsOn)
+
+	cm := &v1.ConfigMap{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[1].Attributes, cm)
+	assert.NoError(t, err)
+	assert.Equal(t, "default-dev-foo-maintenance", cm.Name)
+	assert.Contains(t, cm.Data[maintenancePageKey], "Back at &lt;b&gt;10:00&lt;/b&gt;")
+	assert.Contains(t, cm.Data[maintenanceConfKey], "add_header Retry-After 600 always;")
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[2].Attributes, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, "default-dev-foo-maintenance", deployment.Name)
+	assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+	assert.Equal(t, svc.Spec.Selector, deployment.Spec.Template.Labels)
+	assert.Equal(t, svc.Spec.Selector, deployment.Spec.Selector.MatchLabels)
+	assert.Equal(t, "registry.example.com/nginx-unprivileged:1.27", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []string{response.Resources[1].ID}, response.Resources[2].DependsOn)
+	appSelected := true
+	for k, v := range module.UniqueAppLabels(request.Project, request.App) {
+		appSelected = appSelected && deployment.Spec.Template.Labels[k] == v
+	}
+	assert.False(t, appSelected, "the maintenance pods must not be selected by the workload")
+
+	// The workload serves the traffic once the maintenance is disabled.
+	network = &Network{}
+	response, err = network.Generate(context.Background(), newRequest(ports, map[string]any{"enabled": false}))
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 1)
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[0].Attributes, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, module.UniqueAppLabels("default", "foo"), svc.Spec.Selector)
+	assert.Equal(t, intstr.FromInt(3000), svc.Spec.Ports[0].TargetPort)
+	assert.Empty(t, response.Resources[0].DependsOn)
+}
+
+func TestNetworkModule_MaintenancePreview(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project:  "default",
+		Stack:    "dev",
+		App:      "foo",
+		Workload: kusionapiv1.Accessory{"type": "Deployment"},
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{"port": 8080, "protocol": "TCP"},
+			},
+			"preview":     map[string]any{"revision": "v2"},
+			"maintenance": map[string]any{"enabled": true},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"preview": map[string]any{
+				"gateway": map[string]any{"name": "internal", "namespace": "infra"},
+			},
+		},
+	}
+
+	response, err := (&Network{}).Generate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 5)
+
+	// The preview Service is switched to the maintenance backend instead of the canary pods.
+	preview := response.Resources[1]
+	assert.Equal(t, "v1:Service:default:default-dev-foo-preview", preview.ID)
+	svc := &v1.Service{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(preview.Attributes, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, maintenanceLabels(r), svc.Spec.Selector)
+	assert.NotContains(t, svc.Spec.Selector, defaultRevisionLabel)
+	assert.Equal(t, intstr.FromInt(maintenancePort), svc.Spec.Ports[0].TargetPort)
+	assert.Equal(t, []string{"apps/v1:Deployment:default:default-dev-foo-maintenance"}, preview.DependsOn)
+}
+
+func TestNetwork_ValidateMaintenance(t *testing.T) {
+	tests := []struct {
+		name        string
+		ports       []Port
+		maintenance *Maintenance
+		wantErr     error
+	}{
+		{
+			name:        "disabled without ports",
+			maintenance: &Maintenance{Replicas: 1},
+		},
+		{
+			name:        "enabled",
+			ports:       []Port{{Port: 80, TargetPort: 80, Protocol: ProtocolTCP, Public: true}},
+			maintenance: &Maintenance{Enabled: true, Replicas: 2},
+		},
+		{
+			name:        "enabled without ports",
+			ports:       []Port{{Port: 80, TargetPort: 80, Protocol: ProtocolTCP, Mode: ModeHostPort, HostPort: 80}},
+			maintenance: &Maintenance{Enabled: true, Replicas: 1},
+			wantErr:     ErrMaintenanceWithoutPorts,
+		},
+		{
+			name:        "udp port",
+			ports:       []Port{{Port: 53, TargetPort: 53, Protocol: ProtocolUDP}},
+			maintenance: &Maintenance{Enabled: true, Replicas: 1},
+			wantErr:     ErrMaintenanceUDPPort,
+		},
+		{
+			name:        "invalid replicas",
+			maintenance: &Maintenance{Replicas: -1},
+			wantErr:     ErrInvalidMaintenanceReplica,
+		},
+		{
+			name:        "invalid retry after",
+			maintenance: &Maintenance{Replicas: 1, RetryAfterSeconds: -1},
+			wantErr:     ErrInvalidRetryAfter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := &Network{Ports: tt.ports, Maintenance: tt.maintenance}
+			err := network.ValidateMaintenance()
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
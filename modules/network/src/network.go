@@ -5,27 +5,38 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"runtime/debug"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
 
 	"kusionstack.io/kusion-module-framework/pkg/util/workspace"
+	"moduleutil"
 )
 
 const (
 	FieldType        = "type"
 	FieldLabels      = "labels"
 	FieldAnnotations = "annotations"
+
+	FieldHostnameAnnotations = "hostnameAnnotations"
 )
 
+// externalDNSHostnameAnnotation is the annotation watched by ExternalDNS to create the DNS records.
+const externalDNSHostnameAnnotation = "external-dns.alpha.kubernetes.io/hostname"
+
 const (
 	CSPAWS      = "aws"
 	CSPAliCloud = "alicloud"
@@ -36,12 +47,36 @@ const (
 	ProtocolUDP = "UDP"
 )
 
+const (
+	AppProtocolHTTP  = "http"
+	AppProtocolHTTP2 = "http2"
+	AppProtocolGRPC  = "grpc"
+)
+
+const (
+	ModeNodePort = "NodePort"
+	ModeHostPort = "HostPort"
+)
+
 const (
 	k8sKindService = "Service"
 	suffixPublic   = "public"
 	suffixPrivate  = "private"
+	suffixInternal = "internal"
+	suffixNodePort = "nodeport"
 )
 
+// internalLoadBalancerAnnotations are the cloud-specific annotations to provision an internal
+// load balancer, which is only accessible within the VPC.
+var internalLoadBalancerAnnotations = map[string]map[string]string{
+	CSPAWS: {
+		"service.beta.kubernetes.io/aws-load-balancer-internal": "true",
+	},
+	CSPAliCloud: {
+		"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-address-type": "intranet",
+	},
+}
+
 var (
 	ErrEmptyPortConfig   = errors.New("empty port config")
 	ErrEmptyType         = errors.New("type must not be empty when public")
@@ -50,12 +85,80 @@ var (
 	ErrInvalidTargetPort = errors.New("targetPort must be between 1 and 65535 if exist")
 	ErrInvalidProtocol   = errors.New("protocol must be TCP or UDP")
 	ErrEmptySvcWorkload  = errors.New("network port should be binded to a service workload")
+
+	ErrInvalidAppProtocol            = errors.New("appProtocol must be http, http2 or grpc, and works only for TCP port")
+	ErrPublicAndInternal             = errors.New("port must not be both public and internal")
+	ErrInvalidSessionAffinity        = errors.New("sessionAffinity must be None or ClientIP")
+	ErrInvalidSessionAffinityTimeout = errors.New("sessionAffinityTimeoutSeconds must be between 1 and 86400 if exist")
+	ErrInvalidMode                   = errors.New("mode only support NodePort and HostPort for now")
+	ErrExclusiveMode                 = errors.New("port with mode must not be public or internal")
+	ErrInvalidNodePort               = errors.New("nodePort must be between 30000 and 32767 if exist, and works only in NodePort mode")
+	ErrInvalidHostPort               = errors.New("hostPort must be between 1 and 65535 if exist, and works only in HostPort mode")
+	ErrTLSWithoutLoadBalancer        = errors.New("tls works only for the public or internal port with TCP protocol")
+	ErrEmptyCertificateID            = errors.New("certificateID must not be empty in tls")
+	ErrMultipleCertificates          = errors.New("ports exposed by the same load balancer must use the same certificate")
+	ErrUnsupportedRedirect           = errors.New("redirectPort of tls only support alicloud for now")
+	ErrConflictRedirectPort          = errors.New("redirectPort of tls must not conflict with the exposed ports")
+	ErrHostnameWithoutLoadBalancer   = errors.New("hostname works only for the public or internal port")
+	ErrInvalidIPFamilies             = errors.New("ipFamilies must be IPv4 or IPv6 without duplicates, and at most 2 families are allowed")
+	ErrInvalidIPFamilyPolicy         = errors.New("ipFamilyPolicy must be SingleStack, PreferDualStack or RequireDualStack")
+	ErrSingleStackWithDualFamilies   = errors.New("ipFamilies must not contain 2 families when ipFamilyPolicy is SingleStack")
+	ErrInvalidExternalTrafficPolicy  = errors.New("externalTrafficPolicy must be Cluster or Local")
+	ErrInvalidIPAllowlist            = errors.New("ipAllowlist must be a list of CIDRs, e.g. 10.0.0.0/8")
 )
 
 // Network describes the network accessories of workload, which typically contains the exposed
 // ports, load balancer and other related resource configs.
 type Network struct {
 	Ports []Port `yaml:"ports,omitempty" json:"ports,omitempty"`
+
+	// Preview routes the requests with the preview header to the canary revision.
+	Preview *Preview `yaml:"preview,omitempty" json:"preview,omitempty"`
+
+	// RateLimit limits the requests of the port by the gateway controller in workspace.
+	RateLimit *RateLimit `yaml:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+
+	// EIP binds the elastic IP address to the load balancer of the public ports on alicloud.
+	EIP *EIP `yaml:"eip,omitempty" json:"eip,omitempty"`
+
+	// Maintenance switches the traffic of the exposed ports to the static maintenance page.
+	Maintenance *Maintenance `yaml:"maintenance,omitempty" json:"maintenance,omitempty"`
+
+	ServiceOptions `yaml:",inline" json:",inline"`
+}
+
+// ServiceOptions defines the options applied to the Services generated for the exposed ports.
+type ServiceOptions struct {
+	// Headless makes the Service of private ports headless, i.e. without a cluster IP, so that
+	// the DNS lookup of the Service returns the pod IPs directly.
+	Headless bool `yaml:"headless,omitempty" json:"headless,omitempty"`
+
+	// SessionAffinity supports "ClientIP" and "None", which is used to maintain session affinity.
+	SessionAffinity string `yaml:"sessionAffinity,omitempty" json:"sessionAffinity,omitempty"`
+
+	// SessionAffinityTimeoutSeconds specifies the seconds of ClientIP type session sticky time.
+	SessionAffinityTimeoutSeconds int `yaml:"sessionAffinityTimeoutSeconds,omitempty" json:"sessionAffinityTimeoutSeconds,omitempty"`
+
+	// ExternalTrafficPolicy supports "Cluster" and "Local", works only for the load balancer Services.
+	ExternalTrafficPolicy string `yaml:"externalTrafficPolicy,omitempty" json:"externalTrafficPolicy,omitempty"`
+
+	// IPFamilies is the list of IP families, i.e. "IPv4" and "IPv6", assigned to the Services, the
+	// first one is used as the primary family.
+	IPFamilies []string `yaml:"ipFamilies,omitempty" json:"ipFamilies,omitempty"`
+
+	// IPFamilyPolicy supports "SingleStack", "PreferDualStack" and "RequireDualStack", which
+	// represents the dual-stack-ness requested by the Services.
+	IPFamilyPolicy string `yaml:"ipFamilyPolicy,omitempty" json:"ipFamilyPolicy,omitempty"`
+
+	// IPAllowlist is the list of CIDRs allowed to access the load balancer Services, which are
+	// accessible from any address if empty.
+	IPAllowlist []string `yaml:"ipAllowlist,omitempty" json:"ipAllowlist,omitempty"`
+
+	// HealthCheck tunes the health check of the load balancers to the backends.
+	HealthCheck *HealthCheck `yaml:"healthCheck,omitempty" json:"healthCheck,omitempty"`
+
+	// Stickiness enables the cookie-based session stickiness of the load balancers.
+	Stickiness *Stickiness `yaml:"stickiness,omitempty" json:"stickiness,omitempty"`
 }
 
 // Port defines the exposed port of workload, which can be used to describe how
@@ -74,9 +177,42 @@ type Port struct {
 	// Protocol is protocol used to expose the port, support ProtocolTCP and ProtocolUDP.
 	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
 
+	// AppProtocol is the application protocol hint of the port, supports AppProtocolHTTP,
+	// AppProtocolHTTP2 and AppProtocolGRPC, which is used to configure the listeners of the
+	// load balancer.
+	AppProtocol string `yaml:"appProtocol,omitempty" json:"appProtocol,omitempty"`
+
 	// Public defines whether to expose the port through Internet.
 	Public bool `yaml:"public,omitempty" json:"public,omitempty"`
 
+	// Internal defines whether to expose the port through an internal load balancer, which is
+	// only accessible within the VPC.
+	Internal bool `yaml:"internal,omitempty" json:"internal,omitempty"`
+
+	// Hostname is the DNS name of the port, which is created automatically by ExternalDNS, works
+	// only when the Public or Internal is true.
+	Hostname string `yaml:"hostname,omitempty" json:"hostname,omitempty"`
+
+	// HostnameAnnotations are the provider-specific annotation keys set with the hostname along with
+	// the ExternalDNS one, which is retrieved from platform config.
+	HostnameAnnotations []string `yaml:"hostnameAnnotations,omitempty" json:"hostnameAnnotations,omitempty"`
+
+	// TLS configures the HTTPS listener with the certificate at the load balancer, works only
+	// when the Public or Internal is true.
+	TLS *TLS `yaml:"tls,omitempty" json:"tls,omitempty"`
+
+	// Mode is the exposure mode for the clusters without load balancers, supports ModeNodePort
+	// and ModeHostPort for now.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// NodePort is the fixed port on each node, works only in ModeNodePort. The port is allocated
+	// by Kubernetes if empty.
+	NodePort int `yaml:"nodePort,omitempty" json:"nodePort,omitempty"`
+
+	// HostPort is the port on the host of pods, works only in ModeHostPort. If empty, set it
+	// the same as the targetPort.
+	HostPort int `yaml:"hostPort,omitempty" json:"hostPort,omitempty"`
+
 	// Labels are the attached labels of the port, works only when the Public is true.
 	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
 
@@ -84,18 +220,84 @@ type Port struct {
 	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 }
 
+// PlatformConfig describes the platform config of the network module in workspace.
+type PlatformConfig struct {
+	// Port is the platform config of the ports exposed by the load balancers.
+	Port *PortPlatformConfig `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Defaults is the default dev config, which is merged with the one declared by the application.
+	Defaults *Network `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the containers and volumes patched into the
+	// workload are checked against, i.e. privileged, baseline or restricted.
+	PodSecurity string `yaml:"podSecurity,omitempty" json:"podSecurity,omitempty"`
+
+	// Preview is the platform config of the preview routing.
+	Preview *PreviewPlatformConfig `yaml:"preview,omitempty" json:"preview,omitempty"`
+
+	// RateLimit is the platform config of the rate limit.
+	RateLimit *RateLimitPlatformConfig `yaml:"rateLimit,omitempty" json:"rateLimit,omitempty"`
+
+	// Maintenance is the platform config of the maintenance backend.
+	Maintenance *MaintenancePlatformConfig `yaml:"maintenance,omitempty" json:"maintenance,omitempty"`
+}
+
+// PortPlatformConfig describes the load balancer of the exposed ports.
+type PortPlatformConfig struct {
+	// Type is the specific cloud vendor that provides load balancer.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+
+	// Labels are the attached labels of the public and internal ports.
+	Labels map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+
+	// Annotations are the attached annotations of the public and internal ports.
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+
+	// HostnameAnnotations are the provider-specific annotation keys set with the hostname.
+	HostnameAnnotations []string `yaml:"hostnameAnnotations,omitempty" json:"hostnameAnnotations,omitempty"`
+
+	// Region is the region of the load balancer created for the EIP, which is retrieved from the
+	// ALICLOUD_REGION environment variable if empty.
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+
+	// VSwitchID is the vSwitch of the load balancer created for the EIP.
+	VSwitchID string `yaml:"vSwitchID,omitempty" json:"vSwitchID,omitempty"`
+}
+
+// TLS defines the TLS termination of the port at the load balancer.
+type TLS struct {
+	// CertificateID is the ID of the certificate in the cloud vendor, i.e. the ARN of the ACM
+	// certificate for CSPAWS, and the ID of the SSL certificate for CSPAliCloud.
+	CertificateID string `yaml:"certificateID,omitempty" json:"certificateID,omitempty"`
+
+	// RedirectPort is the HTTP port of the load balancer whose requests are redirected to the
+	// HTTPS port, works only for CSPAliCloud for now.
+	RedirectPort int `yaml:"redirectPort,omitempty" json:"redirectPort,omitempty"`
+}
+
 func (network *Network) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
 	// Get the module logger with the generator context.
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "network", &response, &err)
+
+	// Attach the connection info of the exposed ports, and finalize the generated resources with
+	// the standard metadata, the policy and Pod Security Standards checks, and the preview summary
+	// if enabled in the workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate network module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in network module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			name := moduleutil.ResourceName(request, "network-connection-info", moduleutil.KubernetesNamingRule)
+			if err = moduleutil.AttachConnectionInfo("network", name, request, response, network.connectionInfo(request)); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("network", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -108,7 +310,7 @@ func (network *Network) Generate(ctx context.Context, request *module.GeneratorR
 
 	// Get the complete configs of the Network accessory.
 	if err := network.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
-		return nil, err
+		return nil, moduleutil.NewModuleError("network", moduleutil.PhaseComplete, err)
 	}
 	if len(network.Ports) != 0 && request.Workload == nil {
 		return nil, ErrEmptySvcWorkload
@@ -122,19 +324,86 @@ func (network *Network) Generate(ctx context.Context, request *module.GeneratorR
 	}
 	resources = append(resources, res...)
 
+	// Generate the preview Service and routing of the canary revision.
+	res, err = network.GeneratePreviewResources(request)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, res...)
+
+	// Generate the rate limit of the port enforced by the gateway controller.
+	res, err = network.GenerateRateLimitResources(request)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, res...)
+
+	// Generate the maintenance backend the Services are switched to.
+	res, err = network.GenerateMaintenanceResources(request)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, res...)
+
+	// Generate the patcher of the workload for the host ports.
+	patcher, err := network.GenerateHostPortPatcher(request)
+	if err != nil {
+		return nil, err
+	}
+
 	return &module.GeneratorResponse{
 		Resources: resources,
+		Patcher:   patcher,
 	}, nil
 }
 
 // GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
 // configuration for the Network accessory.
 func (network *Network) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Network{}); err != nil {
+		return moduleutil.NewModuleError("network", moduleutil.PhaseValidate, fmt.Errorf("validate Network dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("network", moduleutil.PhaseValidate, fmt.Errorf("validate Network platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
 	// Get the complete port config.
 	if err := network.CompletePortConfig(devConfig, platformConfig); err != nil {
 		return err
 	}
 
+	// Get the Service options.
+	if err := network.CompleteServiceOptions(devConfig); err != nil {
+		return err
+	}
+
+	// Get the preview config.
+	if err := network.CompletePreviewConfig(devConfig, platformConfig); err != nil {
+		return err
+	}
+
+	// Get the rate limit config.
+	if err := network.CompleteRateLimitConfig(devConfig, platformConfig); err != nil {
+		return err
+	}
+
+	// Get the eip config.
+	if err := network.CompleteEIPConfig(devConfig, platformConfig); err != nil {
+		return err
+	}
+
+	// Get the maintenance config.
+	if err := network.CompleteMaintenanceConfig(devConfig, platformConfig); err != nil {
+		return err
+	}
+
 	return network.Validate()
 }
 
@@ -192,7 +461,10 @@ func (network *Network) CompletePortConfig(devConfig kusionapiv1.Accessory, plat
 		if network.Ports[i].TargetPort == 0 {
 			network.Ports[i].TargetPort = network.Ports[i].Port
 		}
-		if network.Ports[i].Public {
+		if network.Ports[i].Mode == ModeHostPort && network.Ports[i].HostPort == 0 {
+			network.Ports[i].HostPort = network.Ports[i].TargetPort
+		}
+		if network.Ports[i].Public || network.Ports[i].Internal {
 			// Get port type from platform config.
 			if portConfig == nil {
 				return ErrEmptyPortConfig
@@ -222,6 +494,33 @@ func (network *Network) CompletePortConfig(devConfig kusionapiv1.Accessory, plat
 				return err
 			}
 			network.Ports[i].Annotations = annotations
+
+			// Get the provider-specific hostname annotations from platform config.
+			if hostnameAnnotations, ok := portConfig[FieldHostnameAnnotations]; ok {
+				yamlStr, err := yaml.Marshal(hostnameAnnotations)
+				if err != nil {
+					return err
+				}
+				if err = yaml.Unmarshal(yamlStr, &network.Ports[i].HostnameAnnotations); err != nil {
+					return fmt.Errorf("failed to retrieve hostnameAnnotations from platform config: %v", err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// CompleteServiceOptions completes the options of the Services generated for the exposed ports.
+// The IP families of the dual-stack clusters are usually set in the defaults of platform config.
+func (network *Network) CompleteServiceOptions(devConfig kusionapiv1.Accessory) error {
+	if devConfig != nil {
+		yamlStr, err := yaml.Marshal(map[string]interface{}(devConfig))
+		if err != nil {
+			return err
+		}
+		if err = yaml.Unmarshal(yamlStr, &network.ServiceOptions); err != nil {
+			return err
 		}
 	}
 
@@ -235,6 +534,31 @@ func (network *Network) Validate() error {
 		return err
 	}
 
+	// Validate the Service options.
+	if err := network.ValidateServiceOptions(); err != nil {
+		return err
+	}
+
+	// Validate the preview config.
+	if err := network.ValidatePreview(); err != nil {
+		return err
+	}
+
+	// Validate the rate limit config.
+	if err := network.ValidateRateLimit(); err != nil {
+		return err
+	}
+
+	// Validate the eip config.
+	if err := network.ValidateEIP(); err != nil {
+		return err
+	}
+
+	// Validate the maintenance config.
+	if err := network.ValidateMaintenance(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -250,49 +574,181 @@ func (network *Network) ValidatePortConfig() error {
 		if port.Protocol != ProtocolTCP && port.Protocol != ProtocolUDP {
 			return ErrInvalidProtocol
 		}
+		if port.AppProtocol != "" && (port.Protocol != ProtocolTCP ||
+			(port.AppProtocol != AppProtocolHTTP && port.AppProtocol != AppProtocolHTTP2 && port.AppProtocol != AppProtocolGRPC)) {
+			return ErrInvalidAppProtocol
+		}
+		if port.Public && port.Internal {
+			return ErrPublicAndInternal
+		}
+		if port.Mode != "" && port.Mode != ModeNodePort && port.Mode != ModeHostPort {
+			return ErrInvalidMode
+		}
+		if port.Mode != "" && (port.Public || port.Internal) {
+			return ErrExclusiveMode
+		}
+		if port.NodePort != 0 && (port.Mode != ModeNodePort || port.NodePort < 30000 || port.NodePort > 32767) {
+			return ErrInvalidNodePort
+		}
+		if port.HostPort != 0 && (port.Mode != ModeHostPort || port.HostPort < 1 || port.HostPort > 65535) {
+			return ErrInvalidHostPort
+		}
+		if err := validateTLS(port); err != nil {
+			return err
+		}
+		if port.Hostname != "" {
+			if !(port.Public || port.Internal) {
+				return ErrHostnameWithoutLoadBalancer
+			}
+			if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(port.Hostname, "*.")); len(errs) != 0 {
+				return fmt.Errorf("invalid hostname %s: %s", port.Hostname, strings.Join(errs, "; "))
+			}
+		}
+	}
+
+	// The certificate is configured per load balancer.
+	for _, ports := range groupPorts(network.Ports) {
+		var certificateID string
+		exposedPorts := make(map[int]bool)
+		for _, port := range ports {
+			exposedPorts[port.Port] = true
+		}
+		for _, port := range ports {
+			if port.TLS == nil {
+				continue
+			}
+			if certificateID != "" && certificateID != port.TLS.CertificateID {
+				return ErrMultipleCertificates
+			}
+			certificateID = port.TLS.CertificateID
+			if port.TLS.RedirectPort != 0 {
+				if exposedPorts[port.TLS.RedirectPort] {
+					return ErrConflictRedirectPort
+				}
+				exposedPorts[port.TLS.RedirectPort] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTLS validates whether the tls config of the port is valid or not.
+func validateTLS(port Port) error {
+	if port.TLS == nil {
+		return nil
+	}
+	if !(port.Public || port.Internal) || port.Protocol != ProtocolTCP {
+		return ErrTLSWithoutLoadBalancer
+	}
+	if port.TLS.CertificateID == "" {
+		return ErrEmptyCertificateID
+	}
+	if port.TLS.RedirectPort != 0 {
+		if port.TLS.RedirectPort < 1 || port.TLS.RedirectPort > 65535 {
+			return ErrInvalidPort
+		}
+		if port.Type != CSPAliCloud {
+			return ErrUnsupportedRedirect
+		}
 	}
 
 	return nil
 }
 
+// ValidateServiceOptions validates whether the Service options are valid or not.
+func (network *Network) ValidateServiceOptions() error {
+	switch v1.ServiceAffinity(network.SessionAffinity) {
+	case "", v1.ServiceAffinityNone, v1.ServiceAffinityClientIP:
+	default:
+		return ErrInvalidSessionAffinity
+	}
+	if network.SessionAffinityTimeoutSeconds < 0 || network.SessionAffinityTimeoutSeconds > 86400 {
+		return ErrInvalidSessionAffinityTimeout
+	}
+	switch v1.ServiceExternalTrafficPolicy(network.ExternalTrafficPolicy) {
+	case "", v1.ServiceExternalTrafficPolicyCluster, v1.ServiceExternalTrafficPolicyLocal:
+	default:
+		return ErrInvalidExternalTrafficPolicy
+	}
+	if len(network.IPFamilies) > 2 {
+		return ErrInvalidIPFamilies
+	}
+	families := make(map[string]bool)
+	for _, family := range network.IPFamilies {
+		if (family != string(v1.IPv4Protocol) && family != string(v1.IPv6Protocol)) || families[family] {
+			return ErrInvalidIPFamilies
+		}
+		families[family] = true
+	}
+	switch v1.IPFamilyPolicy(network.IPFamilyPolicy) {
+	case "", v1.IPFamilyPolicyPreferDualStack, v1.IPFamilyPolicyRequireDualStack:
+	case v1.IPFamilyPolicySingleStack:
+		if len(network.IPFamilies) == 2 {
+			return ErrSingleStackWithDualFamilies
+		}
+	default:
+		return ErrInvalidIPFamilyPolicy
+	}
+	for _, cidr := range network.IPAllowlist {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("%w, got %s", ErrInvalidIPAllowlist, cidr)
+		}
+	}
+
+	return network.validateLoadBalancerOptions()
+}
+
 // GeneratePortResources generates the resources related to the network port.
 func (network *Network) GeneratePortResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
-	var resources []kusionapiv1.Resource
-	privatePorts, publicPorts := splitPorts(network.Ports)
-	if len(privatePorts) != 0 {
-		svc := generatePortK8sSvc(request, false, privatePorts)
-		resourceID := module.KubernetesResourceID(svc.TypeMeta, svc.ObjectMeta)
-		resource, err := module.WrapK8sResourceToKusionResource(resourceID, svc)
-		if err != nil {
-			return nil, err
-		}
-		resources = append(resources, *resource)
+	// The public Service reuses the load balancer the EIP is associated with.
+	resources, eipAnnotations, eipDependsOn, err := network.GenerateEIPResources(request)
+	if err != nil {
+		return nil, err
 	}
-	if len(publicPorts) != 0 {
-		svc := generatePortK8sSvc(request, true, publicPorts)
+	groupedPorts := groupPorts(network.Ports)
+	for _, exposure := range []string{suffixPrivate, suffixPublic, suffixInternal, suffixNodePort} {
+		ports := groupedPorts[exposure]
+		if len(ports) == 0 {
+			continue
+		}
+		svc := generatePortK8sSvc(request, exposure, ports, &network.ServiceOptions)
+		if exposure == suffixPublic {
+			for k, v := range eipAnnotations {
+				svc.Annotations[k] = v
+			}
+		}
+		if network.maintenanceEnabled() {
+			switchToMaintenance(request, svc)
+		}
 		resourceID := module.KubernetesResourceID(svc.TypeMeta, svc.ObjectMeta)
 		resource, err := module.WrapK8sResourceToKusionResource(resourceID, svc)
 		if err != nil {
 			return nil, err
 		}
+		if exposure == suffixPublic {
+			resource.DependsOn = slices.Clone(eipDependsOn)
+		}
+		// Switch the traffic once the maintenance backend is ready.
+		if network.maintenanceEnabled() {
+			resource.DependsOn = append(resource.DependsOn, maintenanceDeploymentID(request))
+		}
 		resources = append(resources, *resource)
 	}
 
 	return resources, nil
 }
 
-// generatePortK8sSvc generates the Kubernetes Service resource for the network port.
-func generatePortK8sSvc(request *module.GeneratorRequest, public bool, ports []Port) *v1.Service {
-	appUname := module.UniqueAppName(request.Project, request.Stack, request.App)
-	var name string
-	if public {
-		name = fmt.Sprintf("%s-%s", appUname, suffixPublic)
-	} else {
-		name = fmt.Sprintf("%s-%s", appUname, suffixPrivate)
-	}
+// generatePortK8sSvc generates the Kubernetes Service resource for the network port, the exposure
+// is one of suffixPrivate, suffixPublic and suffixInternal.
+func generatePortK8sSvc(request *module.GeneratorRequest, exposure string, ports []Port, options *ServiceOptions) *v1.Service {
+	name := moduleutil.ResourceName(request, exposure, moduleutil.KubernetesNamingRule)
+	loadBalancer := exposure == suffixPublic || exposure == suffixInternal
 	svcType := v1.ServiceTypeClusterIP
-	if public {
+	if loadBalancer {
 		svcType = v1.ServiceTypeLoadBalancer
+	} else if exposure == suffixNodePort {
+		svcType = v1.ServiceTypeNodePort
 	}
 
 	svcLabels, ok := request.Workload["labels"]
@@ -327,7 +783,7 @@ func generatePortK8sSvc(request *module.GeneratorRequest, public bool, ports []P
 		},
 	}
 
-	if public {
+	if loadBalancer {
 		if len(svc.Labels) == 0 {
 			svc.Labels = make(map[string]string)
 		}
@@ -343,22 +799,87 @@ func generatePortK8sSvc(request *module.GeneratorRequest, public bool, ports []P
 		for k, v := range annotations {
 			svc.Annotations[k] = v
 		}
+		if exposure == suffixInternal {
+			for k, v := range internalLoadBalancerAnnotations[ports[0].Type] {
+				svc.Annotations[k] = v
+			}
+		}
+		for k, v := range hostnameAnnotations(ports) {
+			svc.Annotations[k] = v
+		}
+		annotations, redirectPorts := listenerAnnotations(ports)
+		for k, v := range annotations {
+			svc.Annotations[k] = v
+		}
+		for k, v := range loadBalancerOptionAnnotations(ports, options) {
+			svc.Annotations[k] = v
+		}
+		svc.Spec.Ports = append(svc.Spec.Ports, toSvcPorts(name, redirectPorts)...)
+		svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicy(options.ExternalTrafficPolicy)
+		svc.Spec.LoadBalancerSourceRanges = options.IPAllowlist
+	} else if exposure == suffixNodePort {
+		svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicy(options.ExternalTrafficPolicy)
+	} else if options.Headless {
+		svc.Spec.ClusterIP = v1.ClusterIPNone
+	}
+
+	for _, family := range options.IPFamilies {
+		svc.Spec.IPFamilies = append(svc.Spec.IPFamilies, v1.IPFamily(family))
+	}
+	if options.IPFamilyPolicy != "" {
+		policy := v1.IPFamilyPolicy(options.IPFamilyPolicy)
+		svc.Spec.IPFamilyPolicy = &policy
+	}
+
+	if options.SessionAffinity != "" {
+		svc.Spec.SessionAffinity = v1.ServiceAffinity(options.SessionAffinity)
+	}
+	if options.SessionAffinityTimeoutSeconds != 0 {
+		timeout := int32(options.SessionAffinityTimeoutSeconds)
+		svc.Spec.SessionAffinityConfig = &v1.SessionAffinityConfig{
+			ClientIP: &v1.ClientIPConfig{TimeoutSeconds: &timeout},
+		}
 	}
 
 	return svc
 }
 
-// splitPorts splits the network ports into private ports and public ports.
-func splitPorts(ports []Port) ([]Port, []Port) {
-	var privatePorts, publicPorts []Port
+// connectionInfo returns the addresses of the exposed ports keyed by "<exposure>.<port>", which
+// are the hostnames if set, or the DNS names of the Services within the cluster.
+func (network *Network) connectionInfo(request *module.GeneratorRequest) map[string]string {
+	info := make(map[string]string)
+	for exposure, ports := range groupPorts(network.Ports) {
+		svcName := moduleutil.ResourceName(request, exposure, moduleutil.KubernetesNamingRule)
+		for _, port := range ports {
+			address := fmt.Sprintf("%s.%s.svc:%d", svcName, request.Project, port.Port)
+			if port.Hostname != "" {
+				address = fmt.Sprintf("%s:%d", port.Hostname, port.Port)
+			}
+			info[fmt.Sprintf("%s.%d", exposure, port.Port)] = address
+		}
+	}
+	return info
+}
+
+// groupPorts groups the network ports by the exposure, i.e. suffixPrivate, suffixPublic, suffixInternal
+// and suffixNodePort. The ports in ModeHostPort are exposed on the pods directly without Services.
+func groupPorts(ports []Port) map[string][]Port {
+	groupedPorts := make(map[string][]Port)
 	for _, port := range ports {
-		if port.Public {
-			publicPorts = append(publicPorts, port)
-		} else {
-			privatePorts = append(privatePorts, port)
+		switch {
+		case port.Mode == ModeHostPort:
+			continue
+		case port.Mode == ModeNodePort:
+			groupedPorts[suffixNodePort] = append(groupedPorts[suffixNodePort], port)
+		case port.Public:
+			groupedPorts[suffixPublic] = append(groupedPorts[suffixPublic], port)
+		case port.Internal:
+			groupedPorts[suffixInternal] = append(groupedPorts[suffixInternal], port)
+		default:
+			groupedPorts[suffixPrivate] = append(groupedPorts[suffixPrivate], port)
 		}
 	}
-	return privatePorts, publicPorts
+	return groupedPorts
 }
 
 // toSvcPorts returns the Kubernetes ServicePort resource.
@@ -370,11 +891,220 @@ func toSvcPorts(name string, ports []Port) []v1.ServicePort {
 			Port:       int32(port.Port),
 			TargetPort: intstr.FromInt(port.TargetPort),
 			Protocol:   v1.Protocol(port.Protocol),
+			NodePort:   int32(port.NodePort),
+		}
+		if port.AppProtocol != "" {
+			svcPorts[i].AppProtocol = &ports[i].AppProtocol
 		}
 	}
 	return svcPorts
 }
 
+// hostnameAnnotations returns the annotations of the hostnames declared in the ports, which are
+// joined by comma as the ports share the same load balancer.
+func hostnameAnnotations(ports []Port) map[string]string {
+	var hostnames []string
+	for _, port := range ports {
+		if port.Hostname != "" && !slices.Contains(hostnames, port.Hostname) {
+			hostnames = append(hostnames, port.Hostname)
+		}
+	}
+	if len(hostnames) == 0 {
+		return nil
+	}
+
+	value := strings.Join(hostnames, ",")
+	annotations := map[string]string{externalDNSHostnameAnnotation: value}
+	for _, key := range ports[0].HostnameAnnotations {
+		annotations[key] = value
+	}
+	return annotations
+}
+
+// listenerAnnotations returns the cloud-specific annotations to configure the listeners of the load
+// balancer according to the tls and appProtocol of the ports, along with the HTTP ports to be
+// redirected to the HTTPS ones.
+func listenerAnnotations(ports []Port) (map[string]string, []Port) {
+	var certificateID string
+	var sslPorts, protocolPorts, forwardPorts []string
+	var redirectPorts []Port
+	// httpOnly indicates whether all the TCP ports are served by HTTP/1.x backends.
+	httpOnly, hasHTTP, hasUDP := true, false, false
+	for _, port := range ports {
+		if port.Protocol == ProtocolUDP {
+			hasUDP = true
+			continue
+		}
+		switch {
+		case port.AppProtocol == AppProtocolHTTP, port.TLS != nil && port.AppProtocol == "":
+			hasHTTP = true
+		default:
+			httpOnly = false
+		}
+
+		if port.TLS == nil {
+			if port.AppProtocol == AppProtocolHTTP {
+				protocolPorts = append(protocolPorts, fmt.Sprintf("http:%d", port.Port))
+			}
+			continue
+		}
+		certificateID = port.TLS.CertificateID
+		sslPorts = append(sslPorts, strconv.Itoa(port.Port))
+		protocolPorts = append(protocolPorts, fmt.Sprintf("https:%d", port.Port))
+		if port.TLS.RedirectPort != 0 {
+			protocolPorts = append(protocolPorts, fmt.Sprintf("http:%d", port.TLS.RedirectPort))
+			forwardPorts = append(forwardPorts, fmt.Sprintf("%d:%d", port.TLS.RedirectPort, port.Port))
+			redirectPorts = append(redirectPorts, Port{
+				Port:        port.TLS.RedirectPort,
+				TargetPort:  port.TargetPort,
+				Protocol:    ProtocolTCP,
+				AppProtocol: AppProtocolHTTP,
+			})
+		}
+	}
+
+	annotations := make(map[string]string)
+	switch ports[0].Type {
+	case CSPAWS:
+		// The classic load balancer does not support UDP, use the network load balancer instead.
+		if hasUDP {
+			annotations["service.beta.kubernetes.io/aws-load-balancer-type"] = "nlb"
+		}
+		// The HTTP/2 and gRPC backends are served by the TCP or SSL listeners.
+		if httpOnly && hasHTTP {
+			annotations["service.beta.kubernetes.io/aws-load-balancer-backend-protocol"] = "http"
+		} else if certificateID != "" {
+			annotations["service.beta.kubernetes.io/aws-load-balancer-backend-protocol"] = "tcp"
+		}
+		if certificateID != "" {
+			annotations["service.beta.kubernetes.io/aws-load-balancer-ssl-cert"] = certificateID
+			annotations["service.beta.kubernetes.io/aws-load-balancer-ssl-ports"] = strings.Join(sslPorts, ",")
+		}
+		return annotations, nil
+	case CSPAliCloud:
+		// The ports not declared in protocol-port are served by the TCP or UDP listeners.
+		if len(protocolPorts) != 0 {
+			annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-protocol-port"] = strings.Join(protocolPorts, ",")
+		}
+		if certificateID != "" {
+			annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-cert-id"] = certificateID
+		}
+		if len(forwardPorts) != 0 {
+			annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-forward-port"] = strings.Join(forwardPorts, ",")
+		}
+		return annotations, redirectPorts
+	default:
+		return nil, nil
+	}
+}
+
+// GenerateHostPortPatcher generates the JSON patches which add the host ports to the containers of
+// the workload. The host port is added to the container which declares the target port, or the
+// first container in the order of names if none of them declares it.
+func (network *Network) GenerateHostPortPatcher(request *module.GeneratorRequest) (*kusionapiv1.Patcher, error) {
+	var hostPorts []Port
+	for _, port := range network.Ports {
+		if port.Mode == ModeHostPort {
+			hostPorts = append(hostPorts, port)
+		}
+	}
+	if len(hostPorts) == 0 {
+		return nil, nil
+	}
+
+	typeMeta, _, err := moduleutil.WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.AppName(request),
+		Namespace: request.Project,
+	}
+
+	var workload workloadContainers
+	yamlStr, err := yaml.Marshal(map[string]interface{}(request.Workload))
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(yamlStr, &workload); err != nil {
+		return nil, err
+	}
+	containerNames := make([]string, 0, len(workload.Containers))
+	for name := range workload.Containers {
+		containerNames = append(containerNames, name)
+	}
+	sort.Strings(containerNames)
+	if len(containerNames) == 0 {
+		return nil, fmt.Errorf("no container found in the workload for the host ports")
+	}
+
+	var operations []map[string]interface{}
+	createdPorts := make(map[int]bool)
+	for _, port := range hostPorts {
+		idx := 0
+		for i, name := range containerNames {
+			if workload.Containers[name].declares(port.TargetPort) {
+				idx = i
+				break
+			}
+		}
+
+		containerPort := v1.ContainerPort{
+			ContainerPort: int32(port.TargetPort),
+			HostPort:      int32(port.HostPort),
+			Protocol:      v1.Protocol(port.Protocol),
+		}
+		if len(workload.Containers[containerNames[idx]].Ports) == 0 && !createdPorts[idx] {
+			operations = append(operations, map[string]interface{}{
+				"op":    "add",
+				"path":  fmt.Sprintf("/spec/template/spec/containers/%d/ports", idx),
+				"value": []v1.ContainerPort{containerPort},
+			})
+			createdPorts[idx] = true
+			continue
+		}
+		operations = append(operations, map[string]interface{}{
+			"op":    "add",
+			"path":  fmt.Sprintf("/spec/template/spec/containers/%d/ports/-", idx),
+			"value": containerPort,
+		})
+	}
+
+	payload, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	return &kusionapiv1.Patcher{
+		JSONPatchers: map[string]kusionapiv1.JSONPatcher{
+			module.KubernetesResourceID(typeMeta, objectMeta): {
+				Type:    kusionapiv1.JSONPatch,
+				Payload: payload,
+			},
+		},
+	}, nil
+}
+
+// workloadContainers holds the container ports declared in the workload.
+type workloadContainers struct {
+	Containers map[string]workloadContainer `yaml:"containers"`
+}
+
+type workloadContainer struct {
+	Ports []struct {
+		ContainerPort int `yaml:"containerPort"`
+	} `yaml:"ports"`
+}
+
+// declares returns whether the container port is declared in the container.
+func (c workloadContainer) declares(containerPort int) bool {
+	for _, p := range c.Ports {
+		if p.ContainerPort == containerPort {
+			return true
+		}
+	}
+	return false
+}
+
 // toMapStringInterface changes the input interface (usually map[interface{}]interface{})
 // into map[string]interface{}.
 func toMapStringInterface(i any) (map[string]interface{}, error) {
@@ -397,5 +1127,12 @@ func toMapStringInterface(i any) (map[string]interface{}, error) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Network{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&Network{})
 }
@@ -2,11 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
 )
 
 func TestNetworkModule_Generator(t *testing.T) {
@@ -79,6 +84,56 @@ func TestNetworkModule_Generator(t *testing.T) {
 	}
 }
 
+func TestNetworkModule_GenerateGolden(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+	}{
+		{
+			name: "private-port",
+			devConfig: kusionapiv1.Accessory{
+				"ports": []interface{}{
+					map[string]any{"port": 8080, "targetPort": 80, "protocol": "TCP"},
+				},
+			},
+		},
+		{
+			name: "public-port",
+			devConfig: kusionapiv1.Accessory{
+				"ports": []interface{}{
+					map[string]any{"port": 80, "public": true, "protocol": "TCP"},
+					map[string]any{"port": 443, "public": true, "protocol": "TCP"},
+				},
+			},
+			platformConfig: kusionapiv1.GenericConfig{
+				"port": map[string]any{
+					"type": "alicloud",
+					"annotations": map[string]any{
+						"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-spec": "slb.s1.small",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Network{}).Generate(context.Background(), request)
+			if !assert.NoError(t, err) {
+				return
+			}
+			testutil.AssertGolden(t, filepath.Join("testdata", tt.name+".golden.json"), response)
+		})
+	}
+}
+
 func TestNetworkModule_GetCompleteConfig(t *testing.T) {
 	testcases := []struct {
 		name                 string
@@ -120,6 +175,36 @@ func TestNetworkModule_GetCompleteConfig(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			name: "Unknown field in dev config",
+			devModuleConfig: kusionapiv1.Accessory{
+				"ports": []interface{}{
+					map[string]any{
+						"port":    8080,
+						"protocl": "TCP",
+					},
+				},
+			},
+			platformModuleConfig: nil,
+			expectedErr:          errors.New("ports[0].protocl: unknown field"),
+		},
+		{
+			name: "Mismatched type in platform config",
+			devModuleConfig: kusionapiv1.Accessory{
+				"ports": []interface{}{
+					map[string]any{
+						"port": 8080,
+					},
+				},
+			},
+			platformModuleConfig: kusionapiv1.GenericConfig{
+				"port": map[string]any{
+					"type":   "alicloud",
+					"labels": []string{"foo"},
+				},
+			},
+			expectedErr: errors.New("port.labels: expected object, got array"),
+		},
 	}
 
 	for _, tc := range testcases {
@@ -177,6 +262,213 @@ func TestNetworkModule_Validate(t *testing.T) {
 			},
 			expectedErr: ErrInvalidProtocol,
 		},
+		{
+			name: "AppProtocol for UDP port",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:        53,
+						TargetPort:  53,
+						Protocol:    "UDP",
+						AppProtocol: "grpc",
+					},
+				},
+			},
+			expectedErr: ErrInvalidAppProtocol,
+		},
+		{
+			name: "Public and internal port",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						Public:     true,
+						Internal:   true,
+					},
+				},
+			},
+			expectedErr: ErrPublicAndInternal,
+		},
+		{
+			name: "Invalid mode",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						Mode:       "ExternalName",
+					},
+				},
+			},
+			expectedErr: ErrInvalidMode,
+		},
+		{
+			name: "Public port with mode",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						Public:     true,
+						Mode:       ModeNodePort,
+					},
+				},
+			},
+			expectedErr: ErrExclusiveMode,
+		},
+		{
+			name: "Invalid node port",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						Mode:       ModeNodePort,
+						NodePort:   8080,
+					},
+				},
+			},
+			expectedErr: ErrInvalidNodePort,
+		},
+		{
+			name: "Host port without HostPort mode",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						HostPort:   8080,
+					},
+				},
+			},
+			expectedErr: ErrInvalidHostPort,
+		},
+		{
+			name: "TLS on private port",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       443,
+						TargetPort: 8080,
+						Protocol:   "TCP",
+						TLS:        &TLS{CertificateID: "cert-id"},
+					},
+				},
+			},
+			expectedErr: ErrTLSWithoutLoadBalancer,
+		},
+		{
+			name: "Multiple certificates",
+			network: &Network{
+				Ports: []Port{
+					{
+						Type:       CSPAliCloud,
+						Port:       443,
+						TargetPort: 8080,
+						Protocol:   "TCP",
+						Public:     true,
+						TLS:        &TLS{CertificateID: "cert-id"},
+					},
+					{
+						Type:       CSPAliCloud,
+						Port:       8443,
+						TargetPort: 8080,
+						Protocol:   "TCP",
+						Public:     true,
+						TLS:        &TLS{CertificateID: "another-cert-id"},
+					},
+				},
+			},
+			expectedErr: ErrMultipleCertificates,
+		},
+		{
+			name: "Redirect on aws",
+			network: &Network{
+				Ports: []Port{
+					{
+						Type:       CSPAWS,
+						Port:       443,
+						TargetPort: 8080,
+						Protocol:   "TCP",
+						Public:     true,
+						TLS:        &TLS{CertificateID: "arn:aws:acm:cert", RedirectPort: 80},
+					},
+				},
+			},
+			expectedErr: ErrUnsupportedRedirect,
+		},
+		{
+			name: "Hostname on private port",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						Hostname:   "foo.example.com",
+					},
+				},
+			},
+			expectedErr: ErrHostnameWithoutLoadBalancer,
+		},
+		{
+			name: "Invalid hostname",
+			network: &Network{
+				Ports: []Port{
+					{
+						Port:       80,
+						TargetPort: 80,
+						Protocol:   "TCP",
+						Public:     true,
+						Hostname:   "foo_bar.example.com",
+					},
+				},
+			},
+			expectedErr: errors.New("invalid hostname foo_bar.example.com"),
+		},
+		{
+			name: "Invalid ip families",
+			network: &Network{
+				ServiceOptions: ServiceOptions{
+					IPFamilies: []string{"IPv4", "IPv4"},
+				},
+			},
+			expectedErr: ErrInvalidIPFamilies,
+		},
+		{
+			name: "Single stack with dual families",
+			network: &Network{
+				ServiceOptions: ServiceOptions{
+					IPFamilies:     []string{"IPv6", "IPv4"},
+					IPFamilyPolicy: "SingleStack",
+				},
+			},
+			expectedErr: ErrSingleStackWithDualFamilies,
+		},
+		{
+			name: "Invalid session affinity",
+			network: &Network{
+				ServiceOptions: ServiceOptions{
+					SessionAffinity: "Cookie",
+				},
+			},
+			expectedErr: ErrInvalidSessionAffinity,
+		},
+		{
+			name: "Invalid external traffic policy",
+			network: &Network{
+				ServiceOptions: ServiceOptions{
+					ExternalTrafficPolicy: "Global",
+				},
+			},
+			expectedErr: ErrInvalidExternalTrafficPolicy,
+		},
 		{
 			name: "Valid port",
 			network: &Network{
@@ -203,3 +495,331 @@ func TestNetworkModule_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkModule_GeneratePortResources(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		Workload: kusionapiv1.Accessory{
+			"_type": "service.Service",
+			"type":  "service",
+		},
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{
+					"port":     8080,
+					"protocol": "TCP",
+				},
+				map[string]any{
+					"port":     80,
+					"protocol": "TCP",
+					"internal": true,
+				},
+			},
+			"headless":                      true,
+			"sessionAffinity":               "ClientIP",
+			"sessionAffinityTimeoutSeconds": 600,
+			"externalTrafficPolicy":         "Local",
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"port": map[string]any{
+				"type": "aws",
+			},
+		},
+	}
+
+	network := &Network{}
+	err := network.GetCompleteConfig(r.DevConfig, r.PlatformConfig)
+	assert.NoError(t, err)
+
+	groupedPorts := groupPorts(network.Ports)
+	timeout := int32(600)
+
+	private := generatePortK8sSvc(r, suffixPrivate, groupedPorts[suffixPrivate], &network.ServiceOptions)
+	assert.Equal(t, "test-project-test-stack-test-app-private", private.Name)
+	assert.Equal(t, v1.ServiceTypeClusterIP, private.Spec.Type)
+	assert.Equal(t, v1.ClusterIPNone, private.Spec.ClusterIP)
+	assert.Equal(t, v1.ServiceExternalTrafficPolicy(""), private.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, v1.ServiceAffinityClientIP, private.Spec.SessionAffinity)
+	assert.Equal(t, &v1.SessionAffinityConfig{ClientIP: &v1.ClientIPConfig{TimeoutSeconds: &timeout}}, private.Spec.SessionAffinityConfig)
+
+	internal := generatePortK8sSvc(r, suffixInternal, groupedPorts[suffixInternal], &network.ServiceOptions)
+	assert.Equal(t, "test-project-test-stack-test-app-internal", internal.Name)
+	assert.Equal(t, v1.ServiceTypeLoadBalancer, internal.Spec.Type)
+	assert.Equal(t, "", internal.Spec.ClusterIP)
+	assert.Equal(t, v1.ServiceExternalTrafficPolicyLocal, internal.Spec.ExternalTrafficPolicy)
+	assert.Equal(t, "true", internal.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"])
+}
+
+func TestNetworkModule_NodePortAndHostPort(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		Workload: kusionapiv1.Accessory{
+			"_type": "service.Service",
+			"type":  "DaemonSet",
+			"containers": map[string]any{
+				"agent": map[string]any{
+					"image": "agent:v1",
+				},
+				"proxy": map[string]any{
+					"image": "proxy:v1",
+					"ports": []any{
+						map[string]any{"containerPort": 8443},
+					},
+				},
+			},
+		},
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{
+					"port":     80,
+					"protocol": "TCP",
+					"mode":     "NodePort",
+					"nodePort": 30080,
+				},
+				map[string]any{
+					"port":       443,
+					"targetPort": 8443,
+					"protocol":   "TCP",
+					"mode":       "HostPort",
+				},
+				map[string]any{
+					"port":     9100,
+					"protocol": "TCP",
+					"mode":     "HostPort",
+					"hostPort": 19100,
+				},
+			},
+		},
+	}
+
+	network := &Network{}
+	res, err := network.Generate(context.Background(), r)
+	assert.NoError(t, err)
+	assert.Len(t, res.Resources, 1)
+	assert.Equal(t, "v1:Service:test-project:test-project-test-stack-test-app-nodeport", res.Resources[0].ID)
+
+	groupedPorts := groupPorts(network.Ports)
+	svc := generatePortK8sSvc(r, suffixNodePort, groupedPorts[suffixNodePort], &network.ServiceOptions)
+	assert.Equal(t, v1.ServiceTypeNodePort, svc.Spec.Type)
+	assert.Equal(t, int32(30080), svc.Spec.Ports[0].NodePort)
+
+	patcher := res.Patcher.JSONPatchers["apps/v1:DaemonSet:test-project:test-project-test-stack-test-app"]
+	assert.Equal(t, kusionapiv1.JSONPatch, patcher.Type)
+	assert.JSONEq(t, `[
+		{"op": "add", "path": "/spec/template/spec/containers/1/ports/-", "value": {"containerPort": 8443, "hostPort": 8443, "protocol": "TCP"}},
+		{"op": "add", "path": "/spec/template/spec/containers/0/ports", "value": [{"containerPort": 9100, "hostPort": 19100, "protocol": "TCP"}]}
+	]`, string(patcher.Payload))
+}
+
+func TestNetworkModule_IPFamilies(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{
+					"port":     8080,
+					"protocol": "TCP",
+				},
+			},
+			"ipFamilyPolicy": "RequireDualStack",
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"defaults": map[string]any{
+				"ipFamilies":     []any{"IPv6", "IPv4"},
+				"ipFamilyPolicy": "PreferDualStack",
+			},
+		},
+	}
+
+	network := &Network{}
+	err := network.GetCompleteConfig(r.DevConfig, r.PlatformConfig)
+	assert.NoError(t, err)
+
+	svc := generatePortK8sSvc(r, suffixPrivate, network.Ports, &network.ServiceOptions)
+	policy := v1.IPFamilyPolicyRequireDualStack
+	assert.Equal(t, []v1.IPFamily{v1.IPv6Protocol, v1.IPv4Protocol}, svc.Spec.IPFamilies)
+	assert.Equal(t, &policy, svc.Spec.IPFamilyPolicy)
+}
+
+func TestNetworkModule_IPAllowlist(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{
+					"port":     80,
+					"protocol": "TCP",
+					"public":   true,
+				},
+				map[string]any{
+					"port":     8080,
+					"protocol": "TCP",
+				},
+			},
+			"ipAllowlist": []any{"203.0.113.0/24", "2001:db8::/32"},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"port": map[string]any{"type": "aws"},
+		},
+	}
+
+	network := &Network{}
+	err := network.GetCompleteConfig(r.DevConfig, r.PlatformConfig)
+	assert.NoError(t, err)
+
+	groupedPorts := groupPorts(network.Ports)
+	svc := generatePortK8sSvc(r, suffixPublic, groupedPorts[suffixPublic], &network.ServiceOptions)
+	assert.Equal(t, []string{"203.0.113.0/24", "2001:db8::/32"}, svc.Spec.LoadBalancerSourceRanges)
+	svc = generatePortK8sSvc(r, suffixPrivate, groupedPorts[suffixPrivate], &network.ServiceOptions)
+	assert.Nil(t, svc.Spec.LoadBalancerSourceRanges)
+
+	r.DevConfig["ipAllowlist"] = []any{"203.0.113.1"}
+	err = (&Network{}).GetCompleteConfig(r.DevConfig, r.PlatformConfig)
+	assert.ErrorIs(t, err, ErrInvalidIPAllowlist)
+}
+
+func TestNetworkModule_TLS(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{
+					"port":       443,
+					"targetPort": 8080,
+					"protocol":   "TCP",
+					"public":     true,
+					"tls": map[string]any{
+						"certificateID": "cert-id",
+						"redirectPort":  80,
+					},
+				},
+			},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"port": map[string]any{
+				"type": "alicloud",
+			},
+		},
+	}
+
+	network := &Network{}
+	err := network.GetCompleteConfig(r.DevConfig, r.PlatformConfig)
+	assert.NoError(t, err)
+
+	svc := generatePortK8sSvc(r, suffixPublic, network.Ports, &network.ServiceOptions)
+	appProtocol := AppProtocolHTTP
+	assert.Equal(t, "cert-id", svc.Annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-cert-id"])
+	assert.Equal(t, "https:443,http:80", svc.Annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-protocol-port"])
+	assert.Equal(t, "80:443", svc.Annotations["service.beta.kubernetes.io/alibaba-cloud-loadbalancer-forward-port"])
+	assert.Equal(t, []v1.ServicePort{
+		{
+			Name:       "test-project-test-stack-test-app-public-443-tcp",
+			Port:       443,
+			TargetPort: intstr.FromInt(8080),
+			Protocol:   v1.ProtocolTCP,
+		},
+		{
+			Name:        "test-project-test-stack-test-app-public-80-tcp",
+			Port:        80,
+			TargetPort:  intstr.FromInt(8080),
+			Protocol:    v1.ProtocolTCP,
+			AppProtocol: &appProtocol,
+		},
+	}, svc.Spec.Ports)
+}
+
+func TestNetworkModule_Hostname(t *testing.T) {
+	r := &module.GeneratorRequest{
+		Project: "test-project",
+		Stack:   "test-stack",
+		App:     "test-app",
+		DevConfig: kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{
+					"port":     80,
+					"protocol": "TCP",
+					"public":   true,
+					"hostname": "foo.example.com",
+				},
+				map[string]any{
+					"port":     443,
+					"protocol": "TCP",
+					"public":   true,
+					"hostname": "foo.example.com",
+				},
+			},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"port": map[string]any{
+				"type":                "aws",
+				"hostnameAnnotations": []any{"external-dns.alpha.kubernetes.io/internal-hostname"},
+			},
+		},
+	}
+
+	network := &Network{}
+	err := network.GetCompleteConfig(r.DevConfig, r.PlatformConfig)
+	assert.NoError(t, err)
+
+	svc := generatePortK8sSvc(r, suffixPublic, network.Ports, &network.ServiceOptions)
+	assert.Equal(t, "foo.example.com", svc.Annotations["external-dns.alpha.kubernetes.io/hostname"])
+	assert.Equal(t, "foo.example.com", svc.Annotations["external-dns.alpha.kubernetes.io/internal-hostname"])
+}
+
+func TestListenerAnnotations(t *testing.T) {
+	testcases := []struct {
+		name     string
+		ports    []Port
+		expected map[string]string
+	}{
+		{
+			name: "aws http and udp ports",
+			ports: []Port{
+				{Type: CSPAWS, Port: 80, Protocol: ProtocolTCP, AppProtocol: AppProtocolHTTP},
+				{Type: CSPAWS, Port: 53, Protocol: ProtocolUDP},
+			},
+			expected: map[string]string{
+				"service.beta.kubernetes.io/aws-load-balancer-type":             "nlb",
+				"service.beta.kubernetes.io/aws-load-balancer-backend-protocol": "http",
+			},
+		},
+		{
+			name: "aws grpc port with tls",
+			ports: []Port{
+				{Type: CSPAWS, Port: 443, Protocol: ProtocolTCP, AppProtocol: AppProtocolGRPC, TLS: &TLS{CertificateID: "arn"}},
+			},
+			expected: map[string]string{
+				"service.beta.kubernetes.io/aws-load-balancer-backend-protocol": "tcp",
+				"service.beta.kubernetes.io/aws-load-balancer-ssl-cert":         "arn",
+				"service.beta.kubernetes.io/aws-load-balancer-ssl-ports":        "443",
+			},
+		},
+		{
+			name: "alicloud http and grpc ports",
+			ports: []Port{
+				{Type: CSPAliCloud, Port: 80, Protocol: ProtocolTCP, AppProtocol: AppProtocolHTTP},
+				{Type: CSPAliCloud, Port: 9090, Protocol: ProtocolTCP, AppProtocol: AppProtocolGRPC},
+			},
+			expected: map[string]string{
+				"service.beta.kubernetes.io/alibaba-cloud-loadbalancer-protocol-port": "http:80",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotations, _ := listenerAnnotations(tc.ports)
+			assert.Equal(t, tc.expected, annotations)
+		})
+	}
+}
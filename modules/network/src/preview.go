@@ -0,0 +1,291 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	FieldPreview = "preview"
+
+	suffixPreview = "preview"
+
+	apiVersionHTTPRoute = "gateway.networking.k8s.io/v1"
+	k8sKindHTTPRoute    = "HTTPRoute"
+
+	// defaultRevisionLabel is the pod label distinguishing the revisions of the workload.
+	defaultRevisionLabel = "app.kubernetes.io/version"
+	// defaultPreviewHeader is the request header routing the requests to the preview revision.
+	defaultPreviewHeader = "x-preview"
+)
+
+var (
+	ErrEmptyPreviewRevision = errors.New("revision must not be empty in preview")
+	ErrInvalidPreviewPort   = errors.New("port of preview must be an exposed TCP port served by HTTP")
+	ErrEmptyPreviewGateway  = errors.New("gateway must be specified in preview or configured in workspace")
+	ErrInvalidPreviewHeader = errors.New("header of preview must be a valid HTTP header name")
+)
+
+// Preview describes the preview Service selecting the pods of the canary revision, along with the
+// HTTPRoute routing the requests with the preview header to it and the others to the primary
+// Service, which is used to test the canary revision manually before the rollout.
+type Preview struct {
+	// Revision is the value of the revision label of the canary pods.
+	Revision string `yaml:"revision,omitempty" json:"revision,omitempty"`
+
+	// RevisionLabel is the pod label distinguishing the revisions, defaults to app.kubernetes.io/version.
+	RevisionLabel string `yaml:"revisionLabel,omitempty" json:"revisionLabel,omitempty"`
+
+	// Port is the exposed port routed to the preview Service, defaults to the first exposed TCP port.
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Header is the name of the request header routing to the preview Service, defaults to x-preview.
+	Header string `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// Value is the exact value of the header routing to the preview Service, defaults to the revision.
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+
+	// Hostnames are the hostnames matched by the HTTPRoute, any hostname of the gateway is matched
+	// if empty.
+	Hostnames []string `yaml:"hostnames,omitempty" json:"hostnames,omitempty"`
+
+	// Gateway is the Gateway the HTTPRoute is attached to, which is retrieved from platform config
+	// if empty.
+	Gateway *Gateway `yaml:"gateway,omitempty" json:"gateway,omitempty"`
+}
+
+// PreviewPlatformConfig describes the platform config of the preview routing.
+type PreviewPlatformConfig struct {
+	// Gateway is the default Gateway the HTTPRoutes are attached to.
+	Gateway *Gateway `yaml:"gateway,omitempty" json:"gateway,omitempty"`
+}
+
+// Gateway references the Gateway of the Gateway API.
+type Gateway struct {
+	// Name of the Gateway.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// Namespace of the Gateway, defaults to the namespace of the HTTPRoute.
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// SectionName is the name of the listener of the Gateway, any listener is used if empty.
+	SectionName string `yaml:"sectionName,omitempty" json:"sectionName,omitempty"`
+}
+
+// CompletePreviewConfig completes the preview config with the defaults and the gateway in platform
+// config.
+func (network *Network) CompletePreviewConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	value, ok := devConfig[FieldPreview]
+	if !ok || value == nil {
+		return nil
+	}
+	yamlStr, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	preview := &Preview{}
+	if err = yaml.Unmarshal(yamlStr, preview); err != nil {
+		return fmt.Errorf("failed to retrieve preview from dev config: %v", err)
+	}
+
+	if preview.RevisionLabel == "" {
+		preview.RevisionLabel = defaultRevisionLabel
+	}
+	if preview.Header == "" {
+		preview.Header = defaultPreviewHeader
+	}
+	if preview.Value == "" {
+		preview.Value = preview.Revision
+	}
+	if preview.Port == 0 {
+		for _, port := range network.Ports {
+			if port.Mode != ModeHostPort && port.Protocol == ProtocolTCP {
+				preview.Port = port.Port
+				break
+			}
+		}
+	}
+	if preview.Gateway == nil {
+		if pc, ok := platformConfig[FieldPreview]; ok && pc != nil {
+			yamlStr, err = yaml.Marshal(pc)
+			if err != nil {
+				return err
+			}
+			platform := &PreviewPlatformConfig{}
+			if err = yaml.Unmarshal(yamlStr, platform); err != nil {
+				return fmt.Errorf("failed to retrieve preview from platform config: %v", err)
+			}
+			preview.Gateway = platform.Gateway
+		}
+	}
+
+	network.Preview = preview
+	return nil
+}
+
+// ValidatePreview validates whether the preview config is valid or not.
+func (network *Network) ValidatePreview() error {
+	preview := network.Preview
+	if preview == nil {
+		return nil
+	}
+	if preview.Revision == "" {
+		return ErrEmptyPreviewRevision
+	}
+	if errs := validation.IsQualifiedName(preview.RevisionLabel); len(errs) != 0 {
+		return fmt.Errorf("invalid revisionLabel %s: %s", preview.RevisionLabel, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsValidLabelValue(preview.Revision); len(errs) != 0 {
+		return fmt.Errorf("invalid revision %s: %s", preview.Revision, strings.Join(errs, "; "))
+	}
+	if _, _, ok := network.previewPort(); !ok {
+		return ErrInvalidPreviewPort
+	}
+	if errs := validation.IsHTTPHeaderName(preview.Header); len(errs) != 0 {
+		return fmt.Errorf("%w, got %s", ErrInvalidPreviewHeader, preview.Header)
+	}
+	for _, hostname := range preview.Hostnames {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(hostname, "*.")); len(errs) != 0 {
+			return fmt.Errorf("invalid hostname %s: %s", hostname, strings.Join(errs, "; "))
+		}
+	}
+	if preview.Gateway == nil || preview.Gateway.Name == "" {
+		return ErrEmptyPreviewGateway
+	}
+
+	return nil
+}
+
+// previewPort returns the exposed port routed to the preview Service along with its exposure.
+func (network *Network) previewPort() (Port, string, bool) {
+	return network.httpPort(network.Preview.Port)
+}
+
+// httpPort returns the exposed TCP port served by HTTP along with its exposure, which is routed by
+// the HTTPRoute or the Ingress.
+func (network *Network) httpPort(number int) (Port, string, bool) {
+	groupedPorts := groupPorts(network.Ports)
+	for _, exposure := range []string{suffixPrivate, suffixPublic, suffixInternal, suffixNodePort} {
+		for _, port := range groupedPorts[exposure] {
+			if port.Port == number && port.Protocol == ProtocolTCP &&
+				(port.AppProtocol == "" || port.AppProtocol == AppProtocolHTTP) {
+				return port, exposure, true
+			}
+		}
+	}
+	return Port{}, "", false
+}
+
+// gatewayParentRef returns the parent reference of the HTTPRoute attached to the Gateway.
+func gatewayParentRef(gateway *Gateway) map[string]interface{} {
+	parentRef := map[string]interface{}{"name": gateway.Name}
+	if gateway.Namespace != "" {
+		parentRef["namespace"] = gateway.Namespace
+	}
+	if gateway.SectionName != "" {
+		parentRef["sectionName"] = gateway.SectionName
+	}
+	return parentRef
+}
+
+// GeneratePreviewResources generates the preview Service selecting the pods of the canary revision,
+// and the HTTPRoute routing the requests with the preview header to it.
+func (network *Network) GeneratePreviewResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	preview := network.Preview
+	if preview == nil {
+		return nil, nil
+	}
+	port, exposure, _ := network.previewPort()
+	primaryName := moduleutil.ResourceName(request, exposure, moduleutil.KubernetesNamingRule)
+
+	// The preview Service is a ClusterIP Service of the port regardless of the exposure.
+	options := network.ServiceOptions
+	options.Headless = false
+	svc := generatePortK8sSvc(request, suffixPreview, []Port{port}, &options)
+	// The preview Service is switched to the maintenance backend along with the primary one, so
+	// that the requests with the preview header never reach the canary pods in maintenance.
+	if network.maintenanceEnabled() {
+		switchToMaintenance(request, svc)
+	} else {
+		svc.Spec.Selector[preview.RevisionLabel] = preview.Revision
+	}
+	svcID := module.KubernetesResourceID(svc.TypeMeta, svc.ObjectMeta)
+	svcResource, err := module.WrapK8sResourceToKusionResource(svcID, svc)
+	if err != nil {
+		return nil, err
+	}
+	if network.maintenanceEnabled() {
+		svcResource.DependsOn = []string{maintenanceDeploymentID(request)}
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{gatewayParentRef(preview.Gateway)},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"headers": []interface{}{
+							map[string]interface{}{
+								"type":  "Exact",
+								"name":  preview.Header,
+								"value": preview.Value,
+							},
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": svc.Name, "port": int64(port.Port)},
+				},
+			},
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": primaryName, "port": int64(port.Port)},
+				},
+			},
+		},
+	}
+	if len(preview.Hostnames) != 0 {
+		hostnames := make([]interface{}, 0, len(preview.Hostnames))
+		for _, hostname := range preview.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		spec["hostnames"] = hostnames
+	}
+
+	labels := make(map[string]interface{}, len(svc.Labels))
+	for k, v := range svc.Labels {
+		labels[k] = v
+	}
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersionHTTPRoute,
+			"kind":       k8sKindHTTPRoute,
+			"metadata": map[string]interface{}{
+				"name":      svc.Name,
+				"namespace": svc.Namespace,
+				"labels":    labels,
+			},
+			"spec": spec,
+		},
+	}
+	routeID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: apiVersionHTTPRoute, Kind: k8sKindHTTPRoute},
+		metav1.ObjectMeta{Name: svc.Name, Namespace: svc.Namespace},
+	)
+	routeResource, err := module.WrapK8sResourceToKusionResource(routeID, route)
+	if err != nil {
+		return nil, err
+	}
+	routeResource.DependsOn = []string{svcID}
+
+	return []kusionapiv1.Resource{*svcResource, *routeResource}, nil
+}
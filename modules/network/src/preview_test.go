@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestNetworkModule_Preview(t *testing.T) {
+	newRequest := func(preview map[string]any) *module.GeneratorRequest {
+		return &module.GeneratorRequest{
+			Project:  "default",
+			Stack:    "dev",
+			App:      "foo",
+			Workload: kusionapiv1.Accessory{"type": "Deployment"},
+			DevConfig: kusionapiv1.Accessory{
+				"ports": []interface{}{
+					map[string]any{"port": 53, "protocol": "UDP"},
+					map[string]any{"port": 8080, "protocol": "TCP"},
+				},
+				"preview": preview,
+			},
+			PlatformConfig: kusionapiv1.GenericConfig{
+				"preview": map[string]any{
+					"gateway": map[string]any{"name": "internal", "namespace": "infra"},
+				},
+			},
+		}
+	}
+
+	network := &Network{}
+	response, err := network.Generate(context.Background(), newRequest(map[string]any{
+		"revision":  "v2",
+		"hostnames": []any{"foo.example.com"},
+	}))
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 3)
+
+	svc := &v1.Service{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[1].Attributes, svc)
+	assert.NoError(t, err)
+	assert.Equal(t, "default-dev-foo-preview", svc.Name)
+	assert.Equal(t, v1.ServiceTypeClusterIP, svc.Spec.Type)
+	assert.Equal(t, "v2", svc.Spec.Selector[defaultRevisionLabel])
+	assert.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, int32(8080), svc.Spec.Ports[0].Port)
+
+	route := response.Resources[2]
+	assert.Equal(t, "gateway.networking.k8s.io/v1:HTTPRoute:default:default-dev-foo-preview", route.ID)
+	assert.Equal(t, []string{response.Resources[1].ID}, route.DependsOn)
+	assert.Equal(t, map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "internal", "namespace": "infra"},
+		},
+		"hostnames": []interface{}{"foo.example.com"},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"headers": []interface{}{
+							map[string]interface{}{"type": "Exact", "name": "x-preview", "value": "v2"},
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "default-dev-foo-preview", "port": int64(8080)},
+				},
+			},
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "default-dev-foo-private", "port": int64(8080)},
+				},
+			},
+		},
+	}, route.Attributes["spec"])
+
+	testcases := []struct {
+		name        string
+		preview     map[string]any
+		expectedErr error
+	}{
+		{
+			name:        "empty revision",
+			preview:     map[string]any{"port": 8080},
+			expectedErr: ErrEmptyPreviewRevision,
+		},
+		{
+			name:        "udp port",
+			preview:     map[string]any{"revision": "v2", "port": 53},
+			expectedErr: ErrInvalidPreviewPort,
+		},
+		{
+			name:        "invalid header",
+			preview:     map[string]any{"revision": "v2", "header": "x preview"},
+			expectedErr: ErrInvalidPreviewHeader,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := (&Network{}).Generate(context.Background(), newRequest(tc.preview))
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+
+	request := newRequest(map[string]any{"revision": "v2"})
+	request.PlatformConfig = nil
+	_, err = (&Network{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, ErrEmptyPreviewGateway)
+}
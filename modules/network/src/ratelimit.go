@@ -0,0 +1,390 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	FieldRateLimit = "rateLimit"
+
+	suffixRateLimit = "ratelimit"
+
+	// RateLimitControllerEnvoyGateway enforces the rate limit by the BackendTrafficPolicy of Envoy
+	// Gateway attached to the HTTPRoute.
+	RateLimitControllerEnvoyGateway = "envoy-gateway"
+	// RateLimitControllerNginx enforces the rate limit by the annotations of ingress-nginx on the
+	// Ingress.
+	RateLimitControllerNginx = "nginx"
+
+	apiVersionBackendTrafficPolicy = "gateway.envoyproxy.io/v1alpha1"
+	k8sKindBackendTrafficPolicy    = "BackendTrafficPolicy"
+
+	// nginxLimitRPSAnnotation and nginxLimitRPMAnnotation are the annotations of ingress-nginx
+	// limiting the requests per second and per minute from a client IP.
+	nginxLimitRPSAnnotation = "nginx.ingress.kubernetes.io/limit-rps"
+	nginxLimitRPMAnnotation = "nginx.ingress.kubernetes.io/limit-rpm"
+
+	defaultRateLimitUnit    = "Second"
+	defaultIngressClassName = "nginx"
+)
+
+var (
+	ErrInvalidRateLimitPort           = errors.New("port of rateLimit must be an exposed TCP port served by HTTP")
+	ErrEmptyRateLimitGateway          = errors.New("gateway of rateLimit must be configured in workspace if the port is not routed by preview")
+	ErrInvalidRateLimitRequests       = errors.New("requests of rateLimit must be positive")
+	ErrInvalidRateLimitUnit           = errors.New("unit of rateLimit must be Second, Minute, Hour or Day")
+	ErrUnsupportedRateLimitUnit       = errors.New("unit of rateLimit must be Second or Minute for the nginx controller")
+	ErrUnsupportedRateLimitController = errors.New("controller of rateLimit must be envoy-gateway or nginx")
+)
+
+// RateLimit describes the rate limit of the requests of the port at the edge, which is enforced by
+// the gateway controller configured in workspace, i.e. the policy of Envoy Gateway attached to the
+// HTTPRoute of the port, or the annotations of ingress-nginx on the Ingress of the port.
+type RateLimit struct {
+	// Requests is the number of the requests allowed in the unit of time.
+	Requests int `yaml:"requests,omitempty" json:"requests,omitempty"`
+
+	// Unit is the unit of time of the limit, Second, Minute, Hour or Day, defaults to Second.
+	Unit string `yaml:"unit,omitempty" json:"unit,omitempty"`
+
+	// Port is the exposed port whose requests are limited, defaults to the port of preview if any,
+	// otherwise the first exposed TCP port.
+	Port int `yaml:"port,omitempty" json:"port,omitempty"`
+
+	// Hostnames are the hostnames matched by the HTTPRoute or the Ingress generated for the limit,
+	// any hostname is matched if empty.
+	Hostnames []string `yaml:"hostnames,omitempty" json:"hostnames,omitempty"`
+
+	// Controller is the gateway controller enforcing the limit, which is retrieved from platform
+	// config.
+	Controller string `yaml:"-" json:"-"`
+
+	// Gateway is the Gateway the HTTPRoute is attached to, which is retrieved from platform config.
+	Gateway *Gateway `yaml:"-" json:"-"`
+
+	// IngressClassName is the class of the Ingress, which is retrieved from platform config.
+	IngressClassName string `yaml:"-" json:"-"`
+}
+
+// RateLimitPlatformConfig describes the platform config of the rate limit.
+type RateLimitPlatformConfig struct {
+	// Controller is the gateway controller enforcing the limit, envoy-gateway or nginx, defaults
+	// to envoy-gateway.
+	Controller string `yaml:"controller,omitempty" json:"controller,omitempty"`
+
+	// Gateway is the Gateway the HTTPRoutes are attached to, which is required by envoy-gateway
+	// unless the port is routed by preview.
+	Gateway *Gateway `yaml:"gateway,omitempty" json:"gateway,omitempty"`
+
+	// IngressClassName is the class of the Ingresses of nginx, defaults to nginx.
+	IngressClassName string `yaml:"ingressClassName,omitempty" json:"ingressClassName,omitempty"`
+}
+
+// CompleteRateLimitConfig completes the rate limit config with the defaults and the controller in
+// platform config.
+func (network *Network) CompleteRateLimitConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	value, ok := devConfig[FieldRateLimit]
+	if !ok || value == nil {
+		return nil
+	}
+	yamlStr, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	rateLimit := &RateLimit{}
+	if err = yaml.Unmarshal(yamlStr, rateLimit); err != nil {
+		return fmt.Errorf("failed to retrieve rateLimit from dev config: %v", err)
+	}
+
+	if pc, ok := platformConfig[FieldRateLimit]; ok && pc != nil {
+		yamlStr, err = yaml.Marshal(pc)
+		if err != nil {
+			return err
+		}
+		platform := &RateLimitPlatformConfig{}
+		if err = yaml.Unmarshal(yamlStr, platform); err != nil {
+			return fmt.Errorf("failed to retrieve rateLimit from platform config: %v", err)
+		}
+		rateLimit.Controller = platform.Controller
+		rateLimit.Gateway = platform.Gateway
+		rateLimit.IngressClassName = platform.IngressClassName
+	}
+	if rateLimit.Controller == "" {
+		rateLimit.Controller = RateLimitControllerEnvoyGateway
+	}
+	if rateLimit.IngressClassName == "" {
+		rateLimit.IngressClassName = defaultIngressClassName
+	}
+	if rateLimit.Unit == "" {
+		rateLimit.Unit = defaultRateLimitUnit
+	}
+	if rateLimit.Port == 0 {
+		if network.Preview != nil {
+			rateLimit.Port = network.Preview.Port
+		} else {
+			for _, port := range network.Ports {
+				if port.Mode != ModeHostPort && port.Protocol == ProtocolTCP {
+					rateLimit.Port = port.Port
+					break
+				}
+			}
+		}
+	}
+
+	network.RateLimit = rateLimit
+	return nil
+}
+
+// ValidateRateLimit validates whether the rate limit config is valid or not.
+func (network *Network) ValidateRateLimit() error {
+	rateLimit := network.RateLimit
+	if rateLimit == nil {
+		return nil
+	}
+	if rateLimit.Requests <= 0 {
+		return ErrInvalidRateLimitRequests
+	}
+	switch rateLimit.Unit {
+	case "Second", "Minute", "Hour", "Day":
+	default:
+		return fmt.Errorf("%w, got %s", ErrInvalidRateLimitUnit, rateLimit.Unit)
+	}
+	if _, _, ok := network.httpPort(rateLimit.Port); !ok {
+		return fmt.Errorf("%w, got %d", ErrInvalidRateLimitPort, rateLimit.Port)
+	}
+	for _, hostname := range rateLimit.Hostnames {
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(hostname, "*.")); len(errs) != 0 {
+			return fmt.Errorf("invalid hostname %s: %s", hostname, strings.Join(errs, "; "))
+		}
+	}
+
+	switch rateLimit.Controller {
+	case RateLimitControllerEnvoyGateway:
+		if !network.rateLimitPreview() && (rateLimit.Gateway == nil || rateLimit.Gateway.Name == "") {
+			return ErrEmptyRateLimitGateway
+		}
+	case RateLimitControllerNginx:
+		// ingress-nginx only limits the requests per second or per minute.
+		if rateLimit.Unit != "Second" && rateLimit.Unit != "Minute" {
+			return fmt.Errorf("%w, got %s", ErrUnsupportedRateLimitUnit, rateLimit.Unit)
+		}
+	default:
+		return fmt.Errorf("%w, got %s", ErrUnsupportedRateLimitController, rateLimit.Controller)
+	}
+
+	return nil
+}
+
+// rateLimitPreview returns whether the limited port is routed by the HTTPRoute of preview, which
+// the policy of Envoy Gateway is attached to instead of a dedicated HTTPRoute.
+func (network *Network) rateLimitPreview() bool {
+	return network.Preview != nil && network.Preview.Port == network.RateLimit.Port
+}
+
+// GenerateRateLimitResources generates the resources limiting the requests of the port by the
+// controller in platform config.
+func (network *Network) GenerateRateLimitResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	if network.RateLimit == nil {
+		return nil, nil
+	}
+	switch network.RateLimit.Controller {
+	case RateLimitControllerNginx:
+		return network.generateRateLimitIngress(request)
+	default:
+		return network.generateRateLimitPolicy(request)
+	}
+}
+
+// generateRateLimitPolicy generates the BackendTrafficPolicy of Envoy Gateway attached to the
+// HTTPRoute of the port, which is the one of preview if the port is routed by preview, otherwise
+// the HTTPRoute generated for the limit routing the requests to the Service of the port. The limit
+// is local to each Envoy proxy replica rather than global across them.
+func (network *Network) generateRateLimitPolicy(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	rateLimit := network.RateLimit
+	labels := make(map[string]interface{})
+	for k, v := range module.UniqueAppLabels(request.Project, request.App) {
+		labels[k] = v
+	}
+
+	var resources []kusionapiv1.Resource
+	routeName := moduleutil.ResourceName(request, suffixPreview, moduleutil.KubernetesNamingRule)
+	if !network.rateLimitPreview() {
+		routeName = moduleutil.ResourceName(request, suffixRateLimit, moduleutil.KubernetesNamingRule)
+		port, exposure, _ := network.httpPort(rateLimit.Port)
+		primary := metav1.ObjectMeta{
+			Name:      moduleutil.ResourceName(request, exposure, moduleutil.KubernetesNamingRule),
+			Namespace: request.Project,
+		}
+		spec := map[string]interface{}{
+			"parentRefs": []interface{}{gatewayParentRef(rateLimit.Gateway)},
+			"rules": []interface{}{
+				map[string]interface{}{
+					"backendRefs": []interface{}{
+						map[string]interface{}{"name": primary.Name, "port": int64(port.Port)},
+					},
+				},
+			},
+		}
+		if len(rateLimit.Hostnames) != 0 {
+			hostnames := make([]interface{}, 0, len(rateLimit.Hostnames))
+			for _, hostname := range rateLimit.Hostnames {
+				hostnames = append(hostnames, hostname)
+			}
+			spec["hostnames"] = hostnames
+		}
+		route := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": apiVersionHTTPRoute,
+				"kind":       k8sKindHTTPRoute,
+				"metadata": map[string]interface{}{
+					"name":      routeName,
+					"namespace": request.Project,
+					"labels":    labels,
+				},
+				"spec": spec,
+			},
+		}
+		routeID := module.KubernetesResourceID(
+			metav1.TypeMeta{APIVersion: apiVersionHTTPRoute, Kind: k8sKindHTTPRoute},
+			metav1.ObjectMeta{Name: routeName, Namespace: request.Project},
+		)
+		routeResource, err := module.WrapK8sResourceToKusionResource(routeID, route)
+		if err != nil {
+			return nil, err
+		}
+		routeResource.DependsOn = []string{
+			module.KubernetesResourceID(metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Service"}, primary),
+		}
+		resources = append(resources, *routeResource)
+	}
+	routeID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: apiVersionHTTPRoute, Kind: k8sKindHTTPRoute},
+		metav1.ObjectMeta{Name: routeName, Namespace: request.Project},
+	)
+
+	policy := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersionBackendTrafficPolicy,
+			"kind":       k8sKindBackendTrafficPolicy,
+			"metadata": map[string]interface{}{
+				"name":      routeName,
+				"namespace": request.Project,
+				"labels":    labels,
+			},
+			"spec": map[string]interface{}{
+				"targetRefs": []interface{}{
+					map[string]interface{}{
+						"group": "gateway.networking.k8s.io",
+						"kind":  k8sKindHTTPRoute,
+						"name":  routeName,
+					},
+				},
+				"rateLimit": map[string]interface{}{
+					"type": "Local",
+					"local": map[string]interface{}{
+						"rules": []interface{}{
+							map[string]interface{}{
+								"limit": map[string]interface{}{
+									"requests": int64(rateLimit.Requests),
+									"unit":     rateLimit.Unit,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	policyID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: apiVersionBackendTrafficPolicy, Kind: k8sKindBackendTrafficPolicy},
+		metav1.ObjectMeta{Name: routeName, Namespace: request.Project},
+	)
+	policyResource, err := module.WrapK8sResourceToKusionResource(policyID, policy)
+	if err != nil {
+		return nil, err
+	}
+	policyResource.DependsOn = []string{routeID}
+
+	return append(resources, *policyResource), nil
+}
+
+// generateRateLimitIngress generates the Ingress of ingress-nginx routing the requests to the
+// Service of the port, which is annotated with the limit of the requests per second or per minute
+// from each client IP.
+func (network *Network) generateRateLimitIngress(request *module.GeneratorRequest) ([]kusionapiv1.Resource, error) {
+	rateLimit := network.RateLimit
+	port, exposure, _ := network.httpPort(rateLimit.Port)
+	primaryName := moduleutil.ResourceName(request, exposure, moduleutil.KubernetesNamingRule)
+
+	annotation := nginxLimitRPSAnnotation
+	if rateLimit.Unit == "Minute" {
+		annotation = nginxLimitRPMAnnotation
+	}
+	pathType := networkingv1.PathTypePrefix
+	rule := networkingv1.IngressRuleValue{
+		HTTP: &networkingv1.HTTPIngressRuleValue{
+			Paths: []networkingv1.HTTPIngressPath{
+				{
+					Path:     "/",
+					PathType: &pathType,
+					Backend: networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: primaryName,
+							Port: networkingv1.ServiceBackendPort{Number: int32(port.Port)},
+						},
+					},
+				},
+			},
+		},
+	}
+	var rules []networkingv1.IngressRule
+	for _, hostname := range rateLimit.Hostnames {
+		rules = append(rules, networkingv1.IngressRule{Host: hostname, IngressRuleValue: rule})
+	}
+	if len(rules) == 0 {
+		rules = []networkingv1.IngressRule{{IngressRuleValue: rule}}
+	}
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: networkingv1.SchemeGroupVersion.String(),
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        moduleutil.ResourceName(request, suffixRateLimit, moduleutil.KubernetesNamingRule),
+			Namespace:   request.Project,
+			Labels:      module.UniqueAppLabels(request.Project, request.App),
+			Annotations: map[string]string{annotation: strconv.Itoa(rateLimit.Requests)},
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &rateLimit.IngressClassName,
+			Rules:            rules,
+		},
+	}
+	ingressID := module.KubernetesResourceID(ingress.TypeMeta, ingress.ObjectMeta)
+	ingressResource, err := module.WrapK8sResourceToKusionResource(ingressID, ingress)
+	if err != nil {
+		return nil, err
+	}
+	ingressResource.DependsOn = []string{
+		module.KubernetesResourceID(
+			metav1.TypeMeta{APIVersion: v1.SchemeGroupVersion.String(), Kind: "Service"},
+			metav1.ObjectMeta{Name: primaryName, Namespace: request.Project},
+		),
+	}
+
+	return []kusionapiv1.Resource{*ingressResource}, nil
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestNetworkModule_RateLimit(t *testing.T) {
+	newRequest := func(rateLimit map[string]any, preview bool) *module.GeneratorRequest {
+		devConfig := kusionapiv1.Accessory{
+			"ports": []interface{}{
+				map[string]any{"port": 8080, "protocol": "TCP"},
+			},
+			"rateLimit": rateLimit,
+		}
+		if preview {
+			devConfig["preview"] = map[string]any{"revision": "v2"}
+		}
+		return &module.GeneratorRequest{
+			Project:   "default",
+			Stack:     "dev",
+			App:       "foo",
+			Workload:  kusionapiv1.Accessory{"type": "Deployment"},
+			DevConfig: devConfig,
+			PlatformConfig: kusionapiv1.GenericConfig{
+				"preview": map[string]any{
+					"gateway": map[string]any{"name": "internal", "namespace": "infra"},
+				},
+			},
+		}
+	}
+
+	response, err := (&Network{}).Generate(context.Background(), newRequest(map[string]any{"requests": 100}, true))
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 4)
+
+	route, policy := response.Resources[2], response.Resources[3]
+	assert.Equal(t, "gateway.envoyproxy.io/v1alpha1:BackendTrafficPolicy:default:default-dev-foo-preview", policy.ID)
+	assert.Equal(t, []string{route.ID}, policy.DependsOn)
+	assert.Equal(t, map[string]interface{}{
+		"targetRefs": []interface{}{
+			map[string]interface{}{
+				"group": "gateway.networking.k8s.io",
+				"kind":  "HTTPRoute",
+				"name":  "default-dev-foo-preview",
+			},
+		},
+		"rateLimit": map[string]interface{}{
+			"type": "Local",
+			"local": map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{
+						"limit": map[string]interface{}{"requests": int64(100), "unit": "Second"},
+					},
+				},
+			},
+		},
+	}, policy.Attributes["spec"])
+
+	// Without preview, the policy is attached to the HTTPRoute generated for the limit.
+	request := newRequest(map[string]any{"requests": 600, "unit": "Minute", "hostnames": []any{"foo.example.com"}}, false)
+	request.PlatformConfig["rateLimit"] = map[string]any{
+		"gateway": map[string]any{"name": "public", "namespace": "infra"},
+	}
+	response, err = (&Network{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 3)
+	route, policy = response.Resources[1], response.Resources[2]
+	assert.Equal(t, "gateway.networking.k8s.io/v1:HTTPRoute:default:default-dev-foo-ratelimit", route.ID)
+	assert.Equal(t, []string{response.Resources[0].ID}, route.DependsOn)
+	assert.Equal(t, map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "public", "namespace": "infra"},
+		},
+		"hostnames": []interface{}{"foo.example.com"},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "default-dev-foo-private", "port": int64(8080)},
+				},
+			},
+		},
+	}, route.Attributes["spec"])
+	assert.Equal(t, []string{route.ID}, policy.DependsOn)
+	assert.Equal(t, "default-dev-foo-ratelimit", policy.Attributes["spec"].(map[string]interface{})["targetRefs"].([]interface{})[0].(map[string]interface{})["name"])
+
+	// The nginx controller annotates the Ingress generated for the limit.
+	request = newRequest(map[string]any{"requests": 600, "unit": "Minute"}, false)
+	request.PlatformConfig["rateLimit"] = map[string]any{"controller": "nginx", "ingressClassName": "internal-nginx"}
+	response, err = (&Network{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Len(t, response.Resources, 2)
+	ingress := &networkingv1.Ingress{}
+	assert.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(response.Resources[1].Attributes, ingress))
+	assert.Equal(t, "networking.k8s.io/v1:Ingress:default:default-dev-foo-ratelimit", response.Resources[1].ID)
+	assert.Equal(t, "600", ingress.Annotations[nginxLimitRPMAnnotation])
+	assert.Equal(t, "internal-nginx", *ingress.Spec.IngressClassName)
+	assert.Len(t, ingress.Spec.Rules, 1)
+	assert.Empty(t, ingress.Spec.Rules[0].Host)
+	assert.Equal(t, networkingv1.IngressServiceBackend{
+		Name: "default-dev-foo-private",
+		Port: networkingv1.ServiceBackendPort{Number: 8080},
+	}, *ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service)
+	assert.Equal(t, []string{response.Resources[0].ID}, response.Resources[1].DependsOn)
+
+	testcases := []struct {
+		name        string
+		rateLimit   map[string]any
+		preview     bool
+		platform    map[string]any
+		expectedErr error
+	}{
+		{
+			name:        "without gateway",
+			rateLimit:   map[string]any{"requests": 100},
+			expectedErr: ErrEmptyRateLimitGateway,
+		},
+		{
+			name:        "port not exposed",
+			rateLimit:   map[string]any{"requests": 100, "port": 9090},
+			preview:     true,
+			expectedErr: ErrInvalidRateLimitPort,
+		},
+		{
+			name:        "zero requests",
+			rateLimit:   map[string]any{"unit": "Minute"},
+			preview:     true,
+			expectedErr: ErrInvalidRateLimitRequests,
+		},
+		{
+			name:        "invalid unit",
+			rateLimit:   map[string]any{"requests": 100, "unit": "Week"},
+			preview:     true,
+			expectedErr: ErrInvalidRateLimitUnit,
+		},
+		{
+			name:        "unsupported controller",
+			rateLimit:   map[string]any{"requests": 100},
+			preview:     true,
+			platform:    map[string]any{"controller": "traefik"},
+			expectedErr: ErrUnsupportedRateLimitController,
+		},
+		{
+			name:        "unsupported unit of nginx",
+			rateLimit:   map[string]any{"requests": 100, "unit": "Hour"},
+			platform:    map[string]any{"controller": "nginx"},
+			expectedErr: ErrUnsupportedRateLimitUnit,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := newRequest(tc.rateLimit, tc.preview)
+			if tc.platform != nil {
+				request.PlatformConfig["rateLimit"] = tc.platform
+			}
+			_, err := (&Network{}).Generate(context.Background(), request)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+[package]
+name = "notification"
+version = "0.1.0"
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	alicloudMNSTopic                = "alicloud_mns_topic"
+	alicloudDirectMailAddress       = "alicloud_direct_mail_mail_address"
+	alicloudRAMUser                 = "alicloud_ram_user"
+	alicloudRAMPolicy               = "alicloud_ram_policy"
+	alicloudRAMUserPolicyAttachment = "alicloud_ram_user_policy_attachment"
+	alicloudRAMAccessKey            = "alicloud_ram_access_key"
+)
+
+var defaultAlicloudProviderCfg = module.ProviderConfig{
+	Source:  "aliyun/alicloud",
+	Version: "1.209.1",
+}
+
+// generateAlicloudResources generates the MNS topics, the sender address of DirectMail, and the
+// RAM user allowed to send the email, the SMS and the messages to the topics, and returns the
+// resources along with the data of the Secret.
+func (notification *Notification) generateAlicloudResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, map[string]string, error) {
+	providerCfg := defaultAlicloudProviderCfg
+	region := notification.platform.Region
+	providerCfg.ProviderMeta = map[string]any{"region": region}
+	data := map[string]string{regionKey: region}
+
+	var resources []kusionapiv1.Resource
+	var topicNames []string
+	for _, topic := range notification.Topics {
+		name := moduleutil.ResourceName(request, topic, moduleutil.AlicloudNamingRule)
+		res, err := moduleutil.WrapTFResource(providerCfg, alicloudMNSTopic, name, map[string]interface{}{"name": name})
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *res)
+		data[topicKeyPrefix+topic] = name
+		topicNames = append(topicNames, name)
+	}
+
+	// Create the sender address of the triggered email under the domain verified by the platform.
+	if notification.Email != nil {
+		res, err := moduleutil.WrapTFResource(providerCfg, alicloudDirectMailAddress, moduleutil.ResourceName(request, resourceSuffix, moduleutil.AlicloudNamingRule), map[string]interface{}{
+			"account_name": notification.Email.Sender,
+			"sendtype":     "trigger",
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *res)
+		data[emailSenderKey] = notification.Email.Sender
+	}
+	if notification.SMS != nil {
+		data[smsSenderKey] = notification.SMS.SignName
+	}
+
+	policyDocument, err := json.Marshal(notification.alicloudPolicyDocument(region, topicNames))
+	if err != nil {
+		return nil, nil, err
+	}
+	name := moduleutil.ResourceName(request, resourceSuffix, moduleutil.AlicloudNamingRule)
+	user, err := moduleutil.WrapTFResource(providerCfg, alicloudRAMUser, name, map[string]interface{}{
+		"name":     name,
+		"comments": fmt.Sprintf("The user sending the notifications of %s", moduleutil.AppName(request)),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := moduleutil.WrapTFResource(providerCfg, alicloudRAMPolicy, name, map[string]interface{}{
+		"policy_name":     name,
+		"policy_document": string(policyDocument),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment, err := moduleutil.WrapTFResource(providerCfg, alicloudRAMUserPolicyAttachment, name, map[string]interface{}{
+		"policy_name": module.KusionPathDependency(policy.ID, "policy_name"),
+		"policy_type": "Custom",
+		"user_name":   module.KusionPathDependency(user.ID, "name"),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	accessKey, err := moduleutil.WrapTFResource(providerCfg, alicloudRAMAccessKey, name, map[string]interface{}{
+		"user_name": module.KusionPathDependency(user.ID, "name"),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *user, *policy, *attachment, *accessKey)
+	data[accessKeyIDKey] = module.KusionPathDependency(accessKey.ID, "id")
+	data[accessKeySecretKey] = module.KusionPathDependency(accessKey.ID, "secret")
+
+	return resources, data, nil
+}
+
+// alicloudPolicyDocument returns the RAM policy allowing the workload to send the email by
+// DirectMail, the SMS, and the messages to its topics.
+func (notification *Notification) alicloudPolicyDocument(region string, topicNames []string) map[string]interface{} {
+	var statements []interface{}
+	if notification.Email != nil {
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   []string{"dm:SingleSendMail", "dm:BatchSendMail"},
+			"Resource": "*",
+		})
+	}
+	if notification.SMS != nil {
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   []string{"dysms:SendSms", "dysms:SendBatchSms"},
+			"Resource": "*",
+		})
+	}
+	if len(topicNames) != 0 {
+		resources := make([]string, 0, len(topicNames))
+		for _, name := range topicNames {
+			resources = append(resources, fmt.Sprintf("acs:mns:%s:*:/topics/%s", region, name))
+		}
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   "mns:PublishMessage",
+			"Resource": resources,
+		})
+	}
+	return map[string]interface{}{
+		"Version":   "1",
+		"Statement": statements,
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestNotification_GenerateAlicloudResources(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	notification := &Notification{
+		Topics:   []string{"order-events"},
+		platform: PlatformConfig{Cloud: CloudAlicloud, Region: "cn-hangzhou"},
+	}
+
+	resources, data, err := notification.generateAlicloudResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 5) {
+		return
+	}
+	user, policy, attachment, accessKey := resources[1], resources[2], resources[3], resources[4]
+	assert.Equal(t, module.KusionPathDependency(policy.ID, "policy_name"), attachment.Attributes["policy_name"])
+	assert.Equal(t, module.KusionPathDependency(user.ID, "name"), attachment.Attributes["user_name"])
+	assert.Equal(t, map[string]string{
+		regionKey:                       "cn-hangzhou",
+		topicKeyPrefix + "order-events": "default-dev-foo-order-events",
+		accessKeyIDKey:                  module.KusionPathDependency(accessKey.ID, "id"),
+		accessKeySecretKey:              module.KusionPathDependency(accessKey.ID, "secret"),
+	}, data)
+
+	document := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(policy.Attributes["policy_document"].(string)), &document))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   "mns:PublishMessage",
+			"Resource": []interface{}{"acs:mns:cn-hangzhou:*:/topics/default-dev-foo-order-events"},
+		},
+	}, document["Statement"])
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	awsSNSTopic      = "aws_sns_topic"
+	awsIAMUser       = "aws_iam_user"
+	awsIAMUserPolicy = "aws_iam_user_policy"
+	awsIAMAccessKey  = "aws_iam_access_key"
+)
+
+var defaultAWSProviderCfg = module.ProviderConfig{
+	Source:  "hashicorp/aws",
+	Version: "5.0.1",
+}
+
+// generateAWSResources generates the SNS topics and the IAM user allowed to send the email by
+// SES, the SMS and the messages to the topics by SNS, and returns the resources along with the
+// data of the Secret.
+func (notification *Notification) generateAWSResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, map[string]string, error) {
+	providerCfg := defaultAWSProviderCfg
+	region := notification.platform.Region
+	providerCfg.ProviderMeta = map[string]any{"region": region}
+	data := map[string]string{regionKey: region}
+
+	var resources []kusionapiv1.Resource
+	var topicARNs []string
+	for _, topic := range notification.Topics {
+		name := moduleutil.ResourceName(request, topic, moduleutil.AWSNamingRule)
+		res, err := moduleutil.WrapTFResource(providerCfg, awsSNSTopic, name, map[string]interface{}{"name": name})
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *res)
+		data[topicKeyPrefix+topic] = module.KusionPathDependency(res.ID, "arn")
+		// The ARN of the topic is matched by its name, since the account is only known at apply time.
+		topicARNs = append(topicARNs, fmt.Sprintf("arn:%s:sns:%s:*:%s", awsPartition(region), region, name))
+	}
+
+	policy, err := json.Marshal(notification.awsPolicyDocument(region, topicARNs))
+	if err != nil {
+		return nil, nil, err
+	}
+	name := moduleutil.ResourceName(request, resourceSuffix, moduleutil.AWSNamingRule)
+	user, err := moduleutil.WrapTFResource(providerCfg, awsIAMUser, name, map[string]interface{}{"name": name})
+	if err != nil {
+		return nil, nil, err
+	}
+	userPolicy, err := moduleutil.WrapTFResource(providerCfg, awsIAMUserPolicy, name, map[string]interface{}{
+		"name":   name,
+		"user":   module.KusionPathDependency(user.ID, "name"),
+		"policy": string(policy),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	accessKey, err := moduleutil.WrapTFResource(providerCfg, awsIAMAccessKey, name, map[string]interface{}{
+		"user": module.KusionPathDependency(user.ID, "name"),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *user, *userPolicy, *accessKey)
+	data[accessKeyIDKey] = module.KusionPathDependency(accessKey.ID, "id")
+	data[accessKeySecretKey] = module.KusionPathDependency(accessKey.ID, "secret")
+
+	if notification.Email != nil {
+		data[emailSenderKey] = notification.Email.Sender
+	}
+	if notification.SMS != nil && notification.SMS.SenderID != "" {
+		data[smsSenderKey] = notification.SMS.SenderID
+	}
+
+	return resources, data, nil
+}
+
+// awsPolicyDocument returns the IAM policy allowing the workload to send the email from its
+// sender, the SMS to the phone numbers, and the messages to its topics.
+func (notification *Notification) awsPolicyDocument(region string, topicARNs []string) map[string]interface{} {
+	partition := awsPartition(region)
+	var statements []interface{}
+	if notification.Email != nil {
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   []string{"ses:SendEmail", "ses:SendRawEmail"},
+			"Resource": fmt.Sprintf("arn:%s:ses:%s:*:identity/%s", partition, region, notification.platform.EmailDomain),
+			"Condition": map[string]interface{}{
+				"StringEquals": map[string]interface{}{"ses:FromAddress": notification.Email.Sender},
+			},
+		})
+	}
+	if notification.SMS != nil {
+		// The SMS are published to the phone numbers instead of the topics.
+		statements = append(statements, map[string]interface{}{
+			"Effect":      "Allow",
+			"Action":      "sns:Publish",
+			"NotResource": fmt.Sprintf("arn:%s:sns:*:*:*", partition),
+		})
+	}
+	if len(topicARNs) != 0 {
+		statements = append(statements, map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   "sns:Publish",
+			"Resource": topicARNs,
+		})
+	}
+	return map[string]interface{}{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	}
+}
+
+// awsPartition returns the partition of the AWS region.
+func awsPartition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	default:
+		return "aws"
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestNotification_GenerateAWSResources(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	notification := &Notification{
+		Email:  &Email{Sender: "noreply@example.com"},
+		SMS:    &SMS{},
+		Topics: []string{"order-events"},
+		platform: PlatformConfig{
+			Cloud:       CloudAWS,
+			Region:      "cn-north-1",
+			EmailDomain: "example.com",
+		},
+	}
+
+	resources, data, err := notification.generateAWSResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 4) {
+		return
+	}
+	topic, user, userPolicy, accessKey := resources[0], resources[1], resources[2], resources[3]
+	assert.Equal(t, "default-dev-foo-order-events", topic.Attributes["name"])
+	assert.Equal(t, "default-dev-foo-notification", user.Attributes["name"])
+	assert.Equal(t, module.KusionPathDependency(user.ID, "name"), userPolicy.Attributes["user"])
+	assert.Equal(t, map[string]string{
+		regionKey:                       "cn-north-1",
+		topicKeyPrefix + "order-events": module.KusionPathDependency(topic.ID, "arn"),
+		accessKeyIDKey:                  module.KusionPathDependency(accessKey.ID, "id"),
+		accessKeySecretKey:              module.KusionPathDependency(accessKey.ID, "secret"),
+		emailSenderKey:                  "noreply@example.com",
+	}, data)
+
+	policy := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(userPolicy.Attributes["policy"].(string)), &policy))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"Effect":    "Allow",
+			"Action":    []interface{}{"ses:SendEmail", "ses:SendRawEmail"},
+			"Resource":  "arn:aws-cn:ses:cn-north-1:*:identity/example.com",
+			"Condition": map[string]interface{}{"StringEquals": map[string]interface{}{"ses:FromAddress": "noreply@example.com"}},
+		},
+		map[string]interface{}{
+			"Effect":      "Allow",
+			"Action":      "sns:Publish",
+			"NotResource": "arn:aws-cn:sns:*:*:*",
+		},
+		map[string]interface{}{
+			"Effect":   "Allow",
+			"Action":   "sns:Publish",
+			"Resource": []interface{}{"arn:aws-cn:sns:cn-north-1:*:default-dev-foo-order-events"},
+		},
+	}, policy["Statement"])
+}
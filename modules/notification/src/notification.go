@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+// The cloud vendors providing the notification services.
+const (
+	CloudAWS      = "aws"
+	CloudAlicloud = "alicloud"
+)
+
+const (
+	resourceSuffix = "notification"
+
+	// The keys of the Secret delivering the settings and the credentials to the workload.
+	regionKey          = "region"
+	accessKeyIDKey     = "accessKeyID"
+	accessKeySecretKey = "accessKeySecret"
+	emailSenderKey     = "emailSender"
+	smsSenderKey       = "smsSender"
+	topicKeyPrefix     = "topic."
+
+	// The env vars injected into the workload, where the topics are injected by the env vars
+	// prefixed with topicEnvPrefix, e.g. NOTIFICATION_TOPIC_ORDER_EVENTS.
+	regionEnv          = "NOTIFICATION_REGION"
+	accessKeyIDEnv     = "NOTIFICATION_ACCESS_KEY_ID"
+	accessKeySecretEnv = "NOTIFICATION_ACCESS_KEY_SECRET"
+	emailSenderEnv     = "NOTIFICATION_EMAIL_SENDER"
+	smsSenderEnv       = "NOTIFICATION_SMS_SENDER"
+	topicEnvPrefix     = "NOTIFICATION_TOPIC_"
+)
+
+// topicPattern matches the names of the topics, which are valid on both aws and alicloud.
+var topicPattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`)
+
+var (
+	ErrEmptyCloud            = errors.New("empty cloud in the platform config, which must be aws or alicloud")
+	ErrUnsupportedCloud      = errors.New("cloud must be aws or alicloud")
+	ErrEmptyRegion           = errors.New("region must be configured in the platform config, or by AWS_REGION or ALICLOUD_REGION")
+	ErrEmptyChannels         = errors.New("at least one of email, sms and topics must be configured")
+	ErrEmptyEmailDomain      = errors.New("email requires the emailDomain verified by the platform in the platform config")
+	ErrInvalidEmailSender    = errors.New("sender of email must be an address of the emailDomain")
+	ErrInvalidTopic          = errors.New("topic must start with a lowercase letter and consist of at most 63 lowercase letters, digits and hyphens")
+	ErrDuplicateTopic        = errors.New("duplicate topic")
+	ErrEmptySMSSignName      = errors.New("sms on alicloud requires the signName approved by the platform")
+	ErrSMSSenderIDOnAlicloud = errors.New("senderID of sms is only supported by aws")
+	ErrSMSSignNameOnAWS      = errors.New("signName of sms is only supported by alicloud")
+	ErrInvalidSMSSenderID    = errors.New("senderID of sms must consist of at most 11 letters and digits with at least one letter")
+)
+
+// smsSenderIDPattern matches the alphanumeric sender IDs of SNS.
+var smsSenderIDPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,11}$`)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Notification{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Notification{})
+}
+
+// Notification describes the transactional notifications sent by the workload by email, SMS or
+// the topics fanning out to the subscribers, whose settings and sending credentials are injected
+// into the workload.
+type Notification struct {
+	// Email is the email sent by SES on aws or DirectMail on alicloud.
+	Email *Email `json:"email,omitempty" yaml:"email,omitempty"`
+	// SMS is the SMS sent by SNS on aws or the SMS service on alicloud.
+	SMS *SMS `json:"sms,omitempty" yaml:"sms,omitempty"`
+	// Topics are the topics published by the workload, which are the SNS topics on aws and the
+	// MNS topics on alicloud.
+	Topics []string `json:"topics,omitempty" yaml:"topics,omitempty"`
+
+	// The platform config of the notification module.
+	platform PlatformConfig
+}
+
+// Email describes the email sent by the workload.
+type Email struct {
+	// Sender is the sender address of the email, which must be of the verified domain.
+	Sender string `json:"sender,omitempty" yaml:"sender,omitempty"`
+}
+
+// SMS describes the SMS sent by the workload.
+type SMS struct {
+	// SenderID is the alphanumeric sender ID of SNS on aws.
+	SenderID string `json:"senderID,omitempty" yaml:"senderID,omitempty"`
+	// SignName is the approved signature of the SMS on alicloud.
+	SignName string `json:"signName,omitempty" yaml:"signName,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the notification module in workspace.
+type PlatformConfig struct {
+	// Cloud is the cloud vendor providing the notification services, aws or alicloud.
+	Cloud string `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	// Region is the region of the cloud provider, which falls back to AWS_REGION or
+	// ALICLOUD_REGION.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// EmailDomain is the domain verified by the platform, e.g. the SES identity on aws or the
+	// DirectMail domain on alicloud, which the senders of the email belong to.
+	EmailDomain string `json:"emailDomain,omitempty" yaml:"emailDomain,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Notification `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// Generate implements the generation logic of the notification module.
+func (notification *Notification) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "notification", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("notification", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Notification does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Notification does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the notification module.
+	if err := notification.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("notification", moduleutil.PhaseComplete, err)
+	}
+
+	// Generate the cloud resources along with the data of the Secret, whose values may be the
+	// Kusion path references to the topics and the access key resolved at apply time.
+	var resources []kusionapiv1.Resource
+	var data map[string]string
+	switch notification.platform.Cloud {
+	case CloudAWS:
+		resources, data, err = notification.generateAWSResources(request)
+	case CloudAlicloud:
+		resources, data, err = notification.generateAlicloudResources(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := moduleutil.WrapK8sResource(notification.generateSecret(request, data))
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, *secret)
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   notification.generatePatcher(request, data),
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the notification module.
+func (notification *Notification) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Notification{}); err != nil {
+		return moduleutil.NewModuleError("notification", moduleutil.PhaseValidate, fmt.Errorf("validate notification dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("notification", moduleutil.PhaseValidate, fmt.Errorf("validate notification platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, notification); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &notification.platform); err != nil {
+			return err
+		}
+	}
+
+	notification.platform.Cloud = strings.ToLower(notification.platform.Cloud)
+	if notification.platform.Region == "" {
+		switch notification.platform.Cloud {
+		case CloudAWS:
+			notification.platform.Region = os.Getenv("AWS_REGION")
+		case CloudAlicloud:
+			notification.platform.Region = os.Getenv("ALICLOUD_REGION")
+		}
+	}
+
+	return notification.Validate()
+}
+
+// Validate validates whether the configs of the notification module are valid.
+func (notification *Notification) Validate() error {
+	cloud := notification.platform.Cloud
+	switch cloud {
+	case "":
+		return ErrEmptyCloud
+	case CloudAWS, CloudAlicloud:
+	default:
+		return fmt.Errorf("%w, got %s", ErrUnsupportedCloud, cloud)
+	}
+	if notification.platform.Region == "" {
+		return ErrEmptyRegion
+	}
+	if notification.Email == nil && notification.SMS == nil && len(notification.Topics) == 0 {
+		return ErrEmptyChannels
+	}
+
+	if email := notification.Email; email != nil {
+		domain := notification.platform.EmailDomain
+		if domain == "" {
+			return ErrEmptyEmailDomain
+		}
+		address, err := mail.ParseAddress(email.Sender)
+		if err != nil || address.Address != email.Sender || !strings.HasSuffix(strings.ToLower(email.Sender), "@"+strings.ToLower(domain)) {
+			return fmt.Errorf("%w %s, got %q", ErrInvalidEmailSender, domain, email.Sender)
+		}
+	}
+
+	if sms := notification.SMS; sms != nil {
+		switch cloud {
+		case CloudAWS:
+			if sms.SignName != "" {
+				return ErrSMSSignNameOnAWS
+			}
+			if sms.SenderID != "" && (!smsSenderIDPattern.MatchString(sms.SenderID) || strings.Trim(sms.SenderID, "0123456789") == "") {
+				return fmt.Errorf("%w, got %q", ErrInvalidSMSSenderID, sms.SenderID)
+			}
+		case CloudAlicloud:
+			if sms.SenderID != "" {
+				return ErrSMSSenderIDOnAlicloud
+			}
+			if sms.SignName == "" {
+				return ErrEmptySMSSignName
+			}
+		}
+	}
+
+	topics := map[string]bool{}
+	for _, topic := range notification.Topics {
+		if !topicPattern.MatchString(topic) {
+			return fmt.Errorf("%w, got %q", ErrInvalidTopic, topic)
+		}
+		if topics[topic] {
+			return fmt.Errorf("%w, got %q", ErrDuplicateTopic, topic)
+		}
+		topics[topic] = true
+	}
+
+	return nil
+}
+
+// generateSecret generates the Secret delivering the settings and the credentials to the
+// workload.
+func (notification *Notification) generateSecret(request *module.GeneratorRequest, data map[string]string) *v1.Secret {
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.ResourceName(request, resourceSuffix, moduleutil.KubernetesNamingRule),
+			Namespace: request.Project,
+		},
+		Type:       v1.SecretTypeOpaque,
+		StringData: data,
+	}
+}
+
+// generatePatcher generates the patcher injecting the keys of the Secret into the containers of
+// the workload as the env vars.
+func (notification *Notification) generatePatcher(request *module.GeneratorRequest, data map[string]string) *kusionapiv1.Patcher {
+	envNames := map[string]string{
+		regionKey:          regionEnv,
+		accessKeyIDKey:     accessKeyIDEnv,
+		accessKeySecretKey: accessKeySecretEnv,
+		emailSenderKey:     emailSenderEnv,
+		smsSenderKey:       smsSenderEnv,
+	}
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	envs := make([]v1.EnvVar, 0, len(keys))
+	for _, key := range keys {
+		name, ok := envNames[key]
+		if !ok {
+			name = topicEnv(strings.TrimPrefix(key, topicKeyPrefix))
+		}
+		envs = append(envs, v1.EnvVar{
+			Name: name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: moduleutil.ResourceName(request, resourceSuffix, moduleutil.KubernetesNamingRule)},
+					Key:                  key,
+				},
+			},
+		})
+	}
+	return &kusionapiv1.Patcher{Environments: envs}
+}
+
+// topicEnv returns the env var of the topic, e.g. NOTIFICATION_TOPIC_ORDER_EVENTS of the topic
+// order-events.
+func topicEnv(topic string) string {
+	return topicEnvPrefix + strings.ToUpper(strings.ReplaceAll(topic, "-", "_"))
+}
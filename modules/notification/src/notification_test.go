@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestNotification_Generate(t *testing.T) {
+	awsConfig := kusionapiv1.GenericConfig{"cloud": "aws", "region": "us-west-2", "emailDomain": "example.com"}
+	alicloudConfig := kusionapiv1.GenericConfig{"cloud": "alicloud", "region": "cn-hangzhou", "emailDomain": "example.com"}
+
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+		expectedErr    error
+		expectedKinds  []string
+		expectedEnvs   []string
+	}{
+		{
+			name: "aws",
+			devConfig: kusionapiv1.Accessory{
+				"email":  map[string]interface{}{"sender": "noreply@example.com"},
+				"sms":    map[string]interface{}{"senderID": "Orders"},
+				"topics": []interface{}{"order-events"},
+			},
+			platformConfig: awsConfig,
+			expectedKinds:  []string{awsSNSTopic, awsIAMUser, awsIAMUserPolicy, awsIAMAccessKey, "Secret"},
+			expectedEnvs: []string{
+				accessKeyIDEnv, accessKeySecretEnv, emailSenderEnv, regionEnv, smsSenderEnv, "NOTIFICATION_TOPIC_ORDER_EVENTS",
+			},
+		},
+		{
+			name: "alicloud",
+			devConfig: kusionapiv1.Accessory{
+				"email": map[string]interface{}{"sender": "noreply@example.com"},
+				"sms":   map[string]interface{}{"signName": "Orders"},
+			},
+			platformConfig: alicloudConfig,
+			expectedKinds: []string{
+				alicloudDirectMailAddress, alicloudRAMUser, alicloudRAMPolicy, alicloudRAMUserPolicyAttachment, alicloudRAMAccessKey, "Secret",
+			},
+			expectedEnvs: []string{accessKeyIDEnv, accessKeySecretEnv, emailSenderEnv, regionEnv, smsSenderEnv},
+		},
+		{
+			name:           "sender of another domain",
+			devConfig:      kusionapiv1.Accessory{"email": map[string]interface{}{"sender": "noreply@example.org"}},
+			platformConfig: awsConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrInvalidEmailSender,
+		},
+		{
+			name:           "sms without sign name on alicloud",
+			devConfig:      kusionapiv1.Accessory{"sms": map[string]interface{}{}},
+			platformConfig: alicloudConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrEmptySMSSignName,
+		},
+		{
+			name:           "empty channels",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: awsConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrEmptyChannels,
+		},
+		{
+			name:          "empty cloud",
+			devConfig:     kusionapiv1.Accessory{"topics": []interface{}{"order-events"}},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrEmptyCloud,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Notification{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedKinds)) {
+				return
+			}
+			for i, kind := range tt.expectedKinds {
+				if resourceType, ok := response.Resources[i].Extensions["resourceType"]; ok {
+					assert.Equal(t, kind, resourceType)
+					continue
+				}
+				assert.Equal(t, kind, response.Resources[i].Attributes["kind"])
+			}
+			var envs []string
+			for _, env := range response.Patcher.Environments {
+				assert.Equal(t, "default-dev-foo-notification", env.ValueFrom.SecretKeyRef.Name)
+				envs = append(envs, env.Name)
+			}
+			assert.Equal(t, tt.expectedEnvs, envs)
+		})
+	}
+}
+
+func TestNotification_Validate(t *testing.T) {
+	aws := PlatformConfig{Cloud: CloudAWS, Region: "us-west-2", EmailDomain: "example.com"}
+	alicloud := PlatformConfig{Cloud: CloudAlicloud, Region: "cn-hangzhou"}
+
+	tests := []struct {
+		name         string
+		notification Notification
+		expectedErr  error
+	}{
+		{
+			name:         "valid",
+			notification: Notification{Email: &Email{Sender: "NoReply@Example.com"}, Topics: []string{"orders"}, platform: aws},
+		},
+		{
+			name:         "unsupported cloud",
+			notification: Notification{platform: PlatformConfig{Cloud: "gcp", Region: "us-west1"}},
+			expectedErr:  ErrUnsupportedCloud,
+		},
+		{
+			name:         "email without domain",
+			notification: Notification{Email: &Email{Sender: "noreply@example.com"}, platform: alicloud},
+			expectedErr:  ErrEmptyEmailDomain,
+		},
+		{
+			name:         "sender with display name",
+			notification: Notification{Email: &Email{Sender: "Orders <noreply@example.com>"}, platform: aws},
+			expectedErr:  ErrInvalidEmailSender,
+		},
+		{
+			name:         "invalid sender id",
+			notification: Notification{SMS: &SMS{SenderID: "12345"}, platform: aws},
+			expectedErr:  ErrInvalidSMSSenderID,
+		},
+		{
+			name:         "sign name on aws",
+			notification: Notification{SMS: &SMS{SignName: "Orders"}, platform: aws},
+			expectedErr:  ErrSMSSignNameOnAWS,
+		},
+		{
+			name:         "sender id on alicloud",
+			notification: Notification{SMS: &SMS{SenderID: "Orders", SignName: "Orders"}, platform: alicloud},
+			expectedErr:  ErrSMSSenderIDOnAlicloud,
+		},
+		{
+			name:         "invalid topic",
+			notification: Notification{Topics: []string{"Order_Events"}, platform: aws},
+			expectedErr:  ErrInvalidTopic,
+		},
+		{
+			name:         "duplicate topic",
+			notification: Notification{Topics: []string{"orders", "orders"}, platform: aws},
+			expectedErr:  ErrDuplicateTopic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.notification.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
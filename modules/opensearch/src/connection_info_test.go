@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+func TestOpenSearch_ConnectionInfo(t *testing.T) {
+	domainID := "hashicorp:aws:aws_opensearch_domain:test-project-test-stack-test-app"
+	newResponse := func() *module.GeneratorResponse {
+		return &module.GeneratorResponse{
+			Resources: []kusionapiv1.Resource{{ID: domainID, Type: kusionapiv1.Terraform}},
+		}
+	}
+	k := &OpenSearch{Region: "us-east-1"}
+	request := &module.GeneratorRequest{Project: "test-project", Stack: "test-stack", App: "test-app"}
+
+	response := newResponse()
+	assert.NoError(t, moduleutil.AttachConnectionInfo("opensearch", "foo", request, response, k.connectionInfo(response)))
+	assert.Len(t, response.Resources, 1)
+
+	request.Context = kusionapiv1.GenericConfig{moduleutil.ConnectionInfoKey: true}
+	assert.NoError(t, moduleutil.AttachConnectionInfo("opensearch", "foo", request, response, k.connectionInfo(response)))
+	assert.Len(t, response.Resources, 2)
+	assert.Equal(t, "v1:ConfigMap:test-project:foo", response.Resources[1].ID)
+	assert.Equal(t, map[string]string{
+		"opensearch.endpoint": "$kusion_path." + domainID + ".endpoint",
+		"opensearch.region":   "us-east-1",
+	}, moduleutil.ConnectionInfoData(response.Resources[1]))
+	assert.Equal(t, map[string]string{
+		"opensearch.endpoint": "$kusion_path." + domainID + ".endpoint",
+		"opensearch.region":   "us-east-1",
+	}, moduleutil.Summarize("opensearch", response.Resources).ConnectionInfo)
+}
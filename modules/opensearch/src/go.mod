@@ -9,6 +9,7 @@ require (
 	k8s.io/api v0.31.3
 	kusionstack.io/kusion-api-go v0.13.0
 	kusionstack.io/kusion-module-framework v0.2.3-beta.6
+	moduleutil v0.0.0
 )
 
 require (
@@ -51,3 +52,5 @@ require (
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.3 // indirect
 	sigs.k8s.io/yaml v1.4.0 // indirect
 )
+
+replace moduleutil => ../../../moduleutil
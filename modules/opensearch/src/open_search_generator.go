@@ -3,20 +3,31 @@ package main
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"os"
 
 	corev1 "k8s.io/api/core/v1"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, OpenSearch{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&OpenSearch{})
 }
 
+// awsRegionEnv is the environment variable of the AWS region.
+const awsRegionEnv = "AWS_REGION"
+
 // OpenSearch implements the Kusion Module generator interface.
 type OpenSearch struct {
 	// DevConfigs
@@ -34,6 +45,17 @@ type OpenSearch struct {
 	// Region represent the aws region
 	Region    string      `json:"region" yaml:"region"`
 	Statement []Statement `json:"statement" yaml:"statement"`
+	// Terraform contains the hints of the generated Terraform resources, e.g. the provider aliases.
+	Terraform *moduleutil.TerraformHints `json:"terraform,omitempty" yaml:"terraform,omitempty"`
+	// Topology contains the topology of the workspace, e.g. the region used if Region is not set.
+	Topology *Topology `json:"topology,omitempty" yaml:"topology,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the openSearch module in workspace.
+type PlatformConfig struct {
+	OpenSearch `json:",inline"`
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
 }
 
 type ClusterConfig struct {
@@ -78,15 +100,27 @@ func (k *OpenSearch) Generate(ctx context.Context, request *module.GeneratorRequ
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "opensearch", &response, &err)
+
+	// Attach the connection info of the domain, hint the Terraform resources with the provider
+	// aliases and state groups, and finalize the generated resources with the standard metadata,
+	// the policy and Pod Security Standards checks, and the preview summary if enabled in the
+	// workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			switch x := r.(type) {
-			case error:
-				err = x
-			default:
-				err = errors.New("unknown panic")
+		if err == nil {
+			name := moduleutil.ResourceName(request, "opensearch-connection-info", moduleutil.KubernetesNamingRule)
+			if err = moduleutil.AttachConnectionInfo("opensearch", name, request, response, k.connectionInfo(response)); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.ApplyTerraformHints(request, response); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("opensearch", request, response); err != nil {
+				response = nil
 			}
-			logger.Error("failed to generate openSearch module: %v", r)
 		}
 	}()
 
@@ -103,13 +137,13 @@ func (k *OpenSearch) Generate(ctx context.Context, request *module.GeneratorRequ
 	// Get the complete openSearch module configs.
 	if err = k.CompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
 		logger.Debug("failed to get complete openSearch module configs: %v", err)
-		return nil, err
+		return nil, moduleutil.NewModuleError("opensearch", moduleutil.PhaseComplete, err)
 	}
 
 	// Validate the completed openSearch module configs.
 	if err := k.ValidateConfig(); err != nil {
 		logger.Error("failed to validate the openSearch module configs: %s", err.Error())
-		return nil, err
+		return nil, moduleutil.NewModuleError("opensearch", moduleutil.PhaseValidate, err)
 	}
 
 	var resources []kusionapiv1.Resource
@@ -146,6 +180,14 @@ func validateRequest(request *module.GeneratorRequest) (*module.GeneratorRespons
 
 // CompleteConfig completes the openSearch module configs with both DevConfig and platformModuleConfig.
 func (k *OpenSearch) CompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, OpenSearch{}); err != nil {
+		return moduleutil.NewModuleError("opensearch", moduleutil.PhaseValidate, fmt.Errorf("validate openSearch dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("opensearch", moduleutil.PhaseValidate, fmt.Errorf("validate openSearch platform config failed, %w", err))
+	}
+
 	if devConfig != nil {
 		devCfgYamlStr, err := json.Marshal(devConfig)
 		if err != nil {
@@ -168,6 +210,12 @@ func (k *OpenSearch) CompleteConfig(devConfig kusionapiv1.Accessory, platformCon
 		}
 	}
 
+	// Resolve the region with the precedence of the dev config over the platform config, and
+	// fall back to the region in the environment variable.
+	if k.Region = ResolveRegion(devConfig, platformConfig); k.Region == "" {
+		k.Region = os.Getenv(awsRegionEnv)
+	}
+
 	return nil
 }
 
@@ -222,7 +270,7 @@ func (k *OpenSearch) GenerateOpenSearchDomain(request *module.GeneratorRequest)
 	}
 
 	// Generate Kusion resource ID and extensions
-	appUniqueName := module.UniqueAppName(request.Project, request.Stack, request.App)
+	appUniqueName := moduleutil.AppName(request)
 	resType := "aws_opensearch_domain"
 	resourceID, err := module.TerraformResourceID(providerConfig, resType, appUniqueName)
 	if err != nil {
@@ -249,3 +297,19 @@ func (k *OpenSearch) GenerateOpenSearchDomain(request *module.GeneratorRequest)
 
 	return resource, patcher, nil
 }
+
+// connectionInfo returns the endpoint and region of the OpenSearch domain in the response.
+func (k *OpenSearch) connectionInfo(response *module.GeneratorResponse) map[string]string {
+	if response == nil {
+		return nil
+	}
+	for _, res := range response.Resources {
+		if res.Type == kusionapiv1.Terraform {
+			return map[string]string{
+				"endpoint": module.KusionPathDependency(res.ID, "endpoint"),
+				"region":   k.Region,
+			}
+		}
+	}
+	return nil
+}
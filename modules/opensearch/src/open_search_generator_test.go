@@ -26,10 +26,10 @@ func TestOpenSearch_CompleteConfig(t *testing.T) {
 		"statement": []map[string]interface{}{
 			{
 				"effect": "Allow",
-				"principal": []Principal{
+				"principals": []map[string]interface{}{
 					{
-						Type:        "AWS",
-						Identifiers: []string{"arn:aws:iam::12345678901:role/yak-role"},
+						"type":        "AWS",
+						"identifiers": []string{"arn:aws:iam::12345678901:role/yak-role"},
 					},
 				},
 				"action": []string{"ec2:RunInstances", "s3:*"},
@@ -68,6 +68,19 @@ func TestOpenSearch_CompleteConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "CompleteConfig with unknown field in platformConfig",
+			os:   &OpenSearch{},
+			args: args{
+				devConfig: devConfig,
+				platformConfig: kusionapiv1.GenericConfig{
+					"clusterConfig": map[string]interface{}{
+						"instanceTyp": "t2.micro.search",
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "CompleteConfig with empty statements",
 			os:   &OpenSearch{},
@@ -247,6 +260,7 @@ func TestOpenSearch_Generate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(awsRegionEnv, "us-east-1")
 			_, err := tt.os.Generate(context.Background(), tt.request)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
@@ -254,3 +268,41 @@ func TestOpenSearch_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenSearch_CompleteConfigRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		env            string
+		expected       string
+	}{
+		{
+			name:           "dev config over platform config",
+			devConfig:      kusionapiv1.Accessory{"region": "eu-west-1"},
+			platformConfig: kusionapiv1.GenericConfig{"region": "us-west-1"},
+			expected:       "eu-west-1",
+		},
+		{
+			name:           "topology over env",
+			platformConfig: kusionapiv1.GenericConfig{TopologyKey: map[string]interface{}{"region": "us-west-2"}},
+			env:            "us-east-1",
+			expected:       "us-west-2",
+		},
+		{
+			name:     "env",
+			env:      "us-east-1",
+			expected: "us-east-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(awsRegionEnv, tt.env)
+
+			os := &OpenSearch{}
+			assert.NoError(t, os.CompleteConfig(tt.devConfig, tt.platformConfig))
+			assert.Equal(t, tt.expected, os.Region)
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+// TopologyKey is the key of the section in the platform config describing the topology of the
+// workspace, e.g.
+//
+//	topology:
+//	  region: us-west-2
+const TopologyKey = "topology"
+
+// Topology describes the topology of the workspace where the cloud resources are provisioned.
+type Topology struct {
+	// The region of the cloud provider.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+}
+
+// ResolveRegion resolves the region of the cloud provider from the configs, with the precedence:
+//
+//  1. The region in the dev config.
+//  2. The region in the platform config.
+//  3. The region of the topology in the platform config.
+//
+// It returns an empty string if none is set, and the callers fall back to the region in the
+// environment variables of the cloud provider.
+func ResolveRegion(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) string {
+	if region, ok := devConfig["region"].(string); ok && region != "" {
+		return region
+	}
+	if region, ok := platformConfig["region"].(string); ok && region != "" {
+		return region
+	}
+	if topology, ok := platformConfig[TopologyKey].(map[string]interface{}); ok {
+		if region, ok := topology["region"].(string); ok && region != "" {
+			return region
+		}
+	}
+	return ""
+}
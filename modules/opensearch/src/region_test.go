@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestResolveRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expected       string
+	}{
+		{
+			name: "empty configs",
+		},
+		{
+			name:      "dev config",
+			devConfig: kusionapiv1.Accessory{"region": "us-east-1"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"region":    "us-west-1",
+				TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expected: "us-east-1",
+		},
+		{
+			name:      "platform config",
+			devConfig: kusionapiv1.Accessory{"region": ""},
+			platformConfig: kusionapiv1.GenericConfig{
+				"region":    "us-west-1",
+				TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expected: "us-west-1",
+		},
+		{
+			name: "topology",
+			platformConfig: kusionapiv1.GenericConfig{
+				TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expected: "us-west-2",
+		},
+		{
+			name: "topology without region",
+			platformConfig: kusionapiv1.GenericConfig{
+				TopologyKey: map[string]interface{}{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResolveRegion(tt.devConfig, tt.platformConfig))
+		})
+	}
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"runtime/debug"
+	"os"
 	"strconv"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"kusionstack.io/kube-api/apps/v1alpha1"
@@ -16,22 +18,79 @@ import (
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+const (
+	// PoliciesKey is the key of the per-workspace rollout policies in the platform config.
+	PoliciesKey = "policies"
+	// WorkspaceKey is the key of the workspace name in the workspace context.
+	WorkspaceKey = "workspace"
+	// PodDisruptionBudgetKey is the key of the PodDisruptionBudget config.
+	PodDisruptionBudgetKey = "podDisruptionBudget"
+	// LabelChecksKey is the key of the label check rules of the PodTransitionRule.
+	LabelChecksKey = "labelChecks"
 )
 
 type OpsRuleModule struct{}
 
+// Config describes the opsRule config declared by the developer and in workspace.
+type Config struct {
+	MaxUnavailable          *IntOrString         `json:"maxUnavailable,omitempty"`
+	MaxSurge                *IntOrString         `json:"maxSurge,omitempty"`
+	Replicas                *int32               `json:"replicas,omitempty"`
+	MinReadySeconds         *int32               `json:"minReadySeconds,omitempty"`
+	ProgressDeadlineSeconds *int32               `json:"progressDeadlineSeconds,omitempty"`
+	RevisionHistoryLimit    *int32               `json:"revisionHistoryLimit,omitempty"`
+	PodDisruptionBudget     *PodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+	MinAvailable            *IntOrString         `json:"minAvailable,omitempty"`
+	LabelChecks             []LabelCheck         `json:"labelChecks,omitempty"`
+}
+
+// PlatformConfig describes the opsRule config in workspace, along with the rollout policies per workspace.
+type PlatformConfig struct {
+	Config   `json:",inline"`
+	Policies map[string]Config `json:"policies,omitempty"`
+}
+
+// PodDisruptionBudget describes the PodDisruptionBudget of the workload.
+type PodDisruptionBudget struct {
+	MinAvailable   *IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// LabelCheck describes the labels that pods of CollaSet must carry before they are transitioned.
+type LabelCheck struct {
+	Name     string            `json:"name"`
+	Stage    string            `json:"stage,omitempty"`
+	Requires map[string]string `json:"requires"`
+}
+
+// IntOrString is the value of either int or string in the opsRule config, e.g. 1 or "10%".
+type IntOrString intstr.IntOrString
+
+// JSONSchema returns the JSON Schema of the value of either int or string.
+func (IntOrString) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": []string{"integer", "string"}}
+}
+
 func (o *OpsRuleModule) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
 	// Get the module logger with the generator context.
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "opsrule", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context. The request
+	// is resolved by the rollout policy below, which drops the per-workspace rollout policies
+	// sharing the policies key with the policy rules.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate opsrule module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in opsrule module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			if err = moduleutil.Finalize("opsrule", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -41,6 +100,20 @@ func (o *OpsRuleModule) Generate(ctx context.Context, request *module.GeneratorR
 		return nil, nil
 	}
 
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err = moduleutil.ValidateConfig(request.DevConfig, Config{}); err != nil {
+		return nil, moduleutil.NewModuleError("opsrule", moduleutil.PhaseValidate, fmt.Errorf("validate opsRule dev config failed, %w", err))
+	}
+	if err = moduleutil.ValidateConfig(request.PlatformConfig, PlatformConfig{}); err != nil {
+		return nil, moduleutil.NewModuleError("opsrule", moduleutil.PhaseValidate, fmt.Errorf("validate opsRule platform config failed, %w", err))
+	}
+
+	// Apply the rollout policy of the workspace.
+	request, err = ResolveRolloutPolicy(request)
+	if err != nil {
+		return nil, err
+	}
+
 	// Job does not support maxUnavailable
 	if workloadType, ok := request.Workload["_type"]; ok && strings.Contains(workloadType.(string), ".Job") {
 		log.Infof("Job does not support opsRule")
@@ -58,7 +131,7 @@ func (o *OpsRuleModule) Generate(ctx context.Context, request *module.GeneratorR
 				Kind:       "PodTransitionRule",
 			},
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      module.UniqueAppName(request.Project, request.Stack, request.App),
+				Name:      moduleutil.AppName(request),
 				Namespace: request.Project,
 			},
 			Spec: v1alpha1.PodTransitionRuleSpec{
@@ -77,18 +150,297 @@ func (o *OpsRuleModule) Generate(ctx context.Context, request *module.GeneratorR
 				},
 			},
 		}
+		extraRules, err := GetTransitionRules(request.DevConfig, request.PlatformConfig)
+		if err != nil {
+			return nil, err
+		}
+		ptr.Spec.Rules = append(ptr.Spec.Rules, extraRules...)
 		resourceID := module.KubernetesResourceID(ptr.TypeMeta, ptr.ObjectMeta)
 		resource, err := module.WrapK8sResourceToKusionResource(resourceID, ptr)
 		if err != nil {
 			return nil, err
 		}
+		resources := []kusionapiv1.Resource{*resource}
+		pdb, err := GetPodDisruptionBudget(request)
+		if err != nil {
+			return nil, err
+		}
+		if pdb != nil {
+			resources = append(resources, *pdb)
+		}
 		return &module.GeneratorResponse{
-			Resources: []kusionapiv1.Resource{*resource},
+			Resources: resources,
 		}, nil
 	}
+
+	// Deployment is the default type of Service if not specified.
+	if workloadType, ok := request.Workload["type"]; !ok || workloadType == nil || strings.ToLower(workloadType.(string)) == "deployment" {
+		patcher, err := GetDeploymentPatcher(request)
+		if err != nil {
+			return nil, err
+		}
+		pdb, err := GetPodDisruptionBudget(request)
+		if err != nil {
+			return nil, err
+		}
+		if patcher == nil && pdb == nil {
+			return nil, nil
+		}
+		response := &module.GeneratorResponse{
+			Patcher: patcher,
+		}
+		if pdb != nil {
+			response.Resources = []kusionapiv1.Resource{*pdb}
+		}
+		return response, nil
+	}
 	return nil, nil
 }
 
+// GetDeploymentPatcher returns the patcher to configure the rolling update strategy and the revision
+// history controls of the generated Deployment, the developer config takes precedence over the
+// platform config.
+func GetDeploymentPatcher(request *module.GeneratorRequest) (*kusionapiv1.Patcher, error) {
+	spec := make(map[string]interface{})
+
+	rollingUpdate := make(map[string]interface{})
+	for _, key := range []string{"maxSurge", "maxUnavailable"} {
+		value, err := getIntOrString(request.DevConfig, request.PlatformConfig, key)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			rollingUpdate[key] = value
+		}
+	}
+	if len(rollingUpdate) != 0 {
+		spec["strategy"] = map[string]interface{}{
+			"type":          appsv1.RollingUpdateDeploymentStrategyType,
+			"rollingUpdate": rollingUpdate,
+		}
+	}
+
+	for _, key := range []string{"replicas", "minReadySeconds", "progressDeadlineSeconds", "revisionHistoryLimit"} {
+		value, err := getInt32(request.DevConfig, request.PlatformConfig, key)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			spec[key] = *value
+		}
+	}
+	if len(spec) == 0 {
+		return nil, nil
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"spec": spec})
+	if err != nil {
+		return nil, err
+	}
+	typeMeta := metav1.TypeMeta{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "Deployment",
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.AppName(request),
+		Namespace: request.Project,
+	}
+	return &kusionapiv1.Patcher{
+		JSONPatchers: map[string]kusionapiv1.JSONPatcher{
+			module.KubernetesResourceID(typeMeta, objectMeta): {
+				Type:    kusionapiv1.MergePatch,
+				Payload: payload,
+			},
+		},
+	}, nil
+}
+
+// ResolveRolloutPolicy returns a copy of the request with the rollout policy of the current workspace
+// applied. The policies are declared per workspace in the platform config, and take precedence over
+// both the developer config and the platform defaults:
+//
+//	kusionstack/opsrule@v0.1:
+//	  maxUnavailable: 25%
+//	  policies:
+//	    prod:
+//	      maxUnavailable: 0
+//	      podDisruptionBudget:
+//	        minAvailable: 1
+//	    dev:
+//	      maxUnavailable: 50%
+//
+// The workspace is identified by the workspace key of the workspace context, or by the stack name
+// following the convention that stacks are named after their workspaces.
+func ResolveRolloutPolicy(request *module.GeneratorRequest) (*module.GeneratorRequest, error) {
+	policies, ok := request.PlatformConfig[PoliciesKey]
+	if !ok || policies == nil {
+		return request, nil
+	}
+	policyMap, ok := policies.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("illegal opsRule config. opsRule.policies is not a map")
+	}
+
+	workspace := request.Stack
+	if name, ok := request.Context[WorkspaceKey].(string); ok && name != "" {
+		workspace = name
+	}
+
+	resolved := *request
+	resolved.PlatformConfig = make(kusionapiv1.GenericConfig, len(request.PlatformConfig))
+	for k, v := range request.PlatformConfig {
+		if k != PoliciesKey {
+			resolved.PlatformConfig[k] = v
+		}
+	}
+	policy, ok := policyMap[workspace]
+	if !ok || policy == nil {
+		return &resolved, nil
+	}
+	policyConfig, ok := policy.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("illegal opsRule config. opsRule.policies.%s is not a map", workspace)
+	}
+
+	resolved.DevConfig = make(kusionapiv1.Accessory, len(request.DevConfig)+len(policyConfig))
+	for k, v := range request.DevConfig {
+		resolved.DevConfig[k] = v
+	}
+	for k, v := range policyConfig {
+		resolved.DevConfig[k] = v
+		resolved.PlatformConfig[k] = v
+	}
+	return &resolved, nil
+}
+
+// GetPodDisruptionBudget returns the PodDisruptionBudget of the workload if it is configured, the
+// developer config takes precedence over the platform config.
+func GetPodDisruptionBudget(request *module.GeneratorRequest) (*kusionapiv1.Resource, error) {
+	config, ok := request.DevConfig[PodDisruptionBudgetKey]
+	if !ok || config == nil {
+		config, ok = request.PlatformConfig[PodDisruptionBudgetKey]
+		if !ok || config == nil {
+			return nil, nil
+		}
+	}
+	pdbConfig, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("illegal opsRule config. opsRule.%s is not a map", PodDisruptionBudgetKey)
+	}
+
+	minAvailable, err := getIntOrString(pdbConfig, nil, "minAvailable")
+	if err != nil {
+		return nil, err
+	}
+	maxUnavailable, err := getIntOrString(pdbConfig, nil, "maxUnavailable")
+	if err != nil {
+		return nil, err
+	}
+	if (minAvailable == nil) == (maxUnavailable == nil) {
+		return nil, fmt.Errorf("illegal opsRule config. exactly one of minAvailable and maxUnavailable must be set in opsRule.%s", PodDisruptionBudgetKey)
+	}
+
+	pdb := &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: policyv1.SchemeGroupVersion.String(),
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.AppName(request),
+			Namespace: request.Project,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: module.UniqueAppLabels(request.Project, request.App),
+			},
+		},
+	}
+	resourceID := module.KubernetesResourceID(pdb.TypeMeta, pdb.ObjectMeta)
+	return module.WrapK8sResourceToKusionResource(resourceID, pdb)
+}
+
+// GetTransitionRules returns the transition rules of the PodTransitionRule besides maxUnavailable,
+// which protect the pods of CollaSet from being transitioned, e.g. deleted during the rolling update
+// or scaling in, until the rules are satisfied. The developer config takes precedence over the
+// platform config.
+//
+//	kusionstack/opsrule@v0.1:
+//	  minAvailable: 2
+//	  labelChecks:
+//	    - name: trafficOff
+//	      stage: PreTrafficOff
+//	      requires:
+//	        app.example.io/traffic-off: "true"
+func GetTransitionRules(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) ([]v1alpha1.TransitionRule, error) {
+	var rules []v1alpha1.TransitionRule
+
+	minAvailable, err := getIntOrString(devConfig, platformConfig, "minAvailable")
+	if err != nil {
+		return nil, err
+	}
+	if minAvailable != nil {
+		rules = append(rules, v1alpha1.TransitionRule{
+			Name: "minAvailable",
+			TransitionRuleDefinition: v1alpha1.TransitionRuleDefinition{
+				AvailablePolicy: &v1alpha1.AvailableRule{
+					MinAvailableValue: minAvailable,
+				},
+			},
+		})
+	}
+
+	labelChecks, ok := devConfig[LabelChecksKey]
+	if !ok || labelChecks == nil {
+		labelChecks, ok = platformConfig[LabelChecksKey]
+		if !ok || labelChecks == nil {
+			return rules, nil
+		}
+	}
+	checks, ok := labelChecks.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("illegal opsRule config. opsRule.%s is not a list", LabelChecksKey)
+	}
+	for i, check := range checks {
+		checkConfig, ok := check.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("illegal opsRule config. opsRule.%s[%d] is not a map", LabelChecksKey, i)
+		}
+		name, _ := checkConfig["name"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("illegal opsRule config. opsRule.%s[%d].name must be set", LabelChecksKey, i)
+		}
+		requires, ok := checkConfig["requires"].(map[string]interface{})
+		if !ok || len(requires) == 0 {
+			return nil, fmt.Errorf("illegal opsRule config. opsRule.%s[%d].requires must be a non-empty map", LabelChecksKey, i)
+		}
+		matchLabels := make(map[string]string, len(requires))
+		for k, v := range requires {
+			value, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("illegal opsRule config. opsRule.%s[%d].requires.%s is not string", LabelChecksKey, i, k)
+			}
+			matchLabels[k] = value
+		}
+		rule := v1alpha1.TransitionRule{
+			Name: name,
+			TransitionRuleDefinition: v1alpha1.TransitionRuleDefinition{
+				LabelCheck: &v1alpha1.LabelCheckRule{
+					Requires: &metav1.LabelSelector{
+						MatchLabels: matchLabels,
+					},
+				},
+			},
+		}
+		if stage, ok := checkConfig["stage"].(string); ok && stage != "" {
+			rule.Stage = &stage
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
 func GetMaxUnavailable(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) (intstr.IntOrString, error) {
 	var maxUnavailable interface{}
 	key := "maxUnavailable"
@@ -120,6 +472,58 @@ func GetMaxUnavailable(devConfig kusionapiv1.Accessory, platformConfig kusionapi
 	return intstr.Parse(mu), nil
 }
 
+// getIntOrString returns the int or string value of the key from the developer config, or from the
+// platform config if it is not set by the developer. Nil is returned if the key is set in neither.
+func getIntOrString(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig, key string) (*intstr.IntOrString, error) {
+	value, ok := devConfig[key]
+	if !ok || value == nil || value == "" {
+		value, ok = platformConfig[key]
+		if !ok || value == nil || value == "" {
+			return nil, nil
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		result := intstr.Parse(v)
+		return &result, nil
+	case int:
+		result := intstr.FromInt32(int32(v))
+		return &result, nil
+	default:
+		return nil, fmt.Errorf("illegal opsRule config. opsRule.%s is not string or int", key)
+	}
+}
+
+// getInt32 returns the int32 value of the key from the developer config, or from the platform
+// config if it is not set by the developer. Nil is returned if the key is set in neither.
+func getInt32(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig, key string) (*int32, error) {
+	value, ok := devConfig[key]
+	if !ok || value == nil {
+		value, ok = platformConfig[key]
+		if !ok || value == nil {
+			return nil, nil
+		}
+	}
+
+	v, ok := value.(int)
+	if !ok {
+		return nil, fmt.Errorf("illegal opsRule config. opsRule.%s is not int", key)
+	}
+	if v < 0 {
+		return nil, fmt.Errorf("illegal opsRule config. opsRule.%s must not be negative", key)
+	}
+	result := int32(v)
+	return &result, nil
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Config{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&OpsRuleModule{})
 }
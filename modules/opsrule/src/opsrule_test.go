@@ -2,12 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"kusionstack.io/kube-api/apps/v1alpha1"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
 )
 
 func TestOpsRuleModule_Generate(t *testing.T) {
@@ -18,9 +24,16 @@ func TestOpsRuleModule_Generate(t *testing.T) {
 			"apiVersion": "apps.kusionstack.io/v1alpha1",
 			"kind":       "PodTransitionRule",
 			"metadata": map[string]interface{}{
+				"annotations":       map[string]interface{}{"kusionstack.io/module": "opsrule"},
 				"creationTimestamp": interface{}(nil),
-				"name":              "default-dev-foo",
-				"namespace":         "default",
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/managed-by": "kusion",
+					"app.kubernetes.io/name":       "foo",
+					"kusionstack.io/project":       "default",
+					"kusionstack.io/stack":         "dev",
+				},
+				"name":      "default-dev-foo",
+				"namespace": "default",
 			},
 			"spec": map[string]interface{}{
 				"rules": []interface{}{map[string]interface{}{
@@ -48,9 +61,16 @@ func TestOpsRuleModule_Generate(t *testing.T) {
 			"apiVersion": "apps.kusionstack.io/v1alpha1",
 			"kind":       "PodTransitionRule",
 			"metadata": map[string]interface{}{
+				"annotations":       map[string]interface{}{"kusionstack.io/module": "opsrule"},
 				"creationTimestamp": interface{}(nil),
-				"name":              "default-dev-foo",
-				"namespace":         "default",
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/managed-by": "kusion",
+					"app.kubernetes.io/name":       "foo",
+					"kusionstack.io/project":       "default",
+					"kusionstack.io/stack":         "dev",
+				},
+				"name":      "default-dev-foo",
+				"namespace": "default",
 			},
 			"spec": map[string]interface{}{
 				"rules": []interface{}{map[string]interface{}{
@@ -167,3 +187,344 @@ func TestOpsRuleModule_Generate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetDeploymentPatcher(t *testing.T) {
+	resourceID := "apps/v1:Deployment:default:default-dev-foo"
+
+	tests := []struct {
+		name           string
+		devConfig      map[string]interface{}
+		platformConfig map[string]interface{}
+		want           *kusionapiv1.Patcher
+		wantErr        bool
+	}{
+		{
+			name: "no deployment controls",
+			want: nil,
+		},
+		{
+			name: "developer config takes precedence over platform config",
+			devConfig: map[string]interface{}{
+				"maxSurge":             "50%",
+				"maxUnavailable":       "30%",
+				"revisionHistoryLimit": 5,
+			},
+			platformConfig: map[string]interface{}{
+				"maxUnavailable":          1,
+				"minReadySeconds":         10,
+				"progressDeadlineSeconds": 300,
+				"revisionHistoryLimit":    10,
+			},
+			want: &kusionapiv1.Patcher{
+				JSONPatchers: map[string]kusionapiv1.JSONPatcher{
+					resourceID: {
+						Type:    kusionapiv1.MergePatch,
+						Payload: []byte(`{"spec":{"minReadySeconds":10,"progressDeadlineSeconds":300,"revisionHistoryLimit":5,"strategy":{"rollingUpdate":{"maxSurge":"50%","maxUnavailable":"30%"},"type":"RollingUpdate"}}}`),
+					},
+				},
+			},
+		},
+		{
+			name: "illegal revision history limit",
+			devConfig: map[string]interface{}{
+				"revisionHistoryLimit": "5",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetDeploymentPatcher(&module.GeneratorRequest{
+				Project:        "default",
+				Stack:          "dev",
+				App:            "foo",
+				DevConfig:      tt.devConfig,
+				PlatformConfig: tt.platformConfig,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetDeploymentPatcher() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetDeploymentPatcher() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRolloutPolicy(t *testing.T) {
+	platformConfig := map[string]interface{}{
+		"maxUnavailable": "25%",
+		"policies": map[string]interface{}{
+			"prod": map[string]interface{}{
+				"maxUnavailable": 0,
+				"podDisruptionBudget": map[string]interface{}{
+					"minAvailable": 1,
+				},
+			},
+			"dev": map[string]interface{}{
+				"maxUnavailable": "50%",
+			},
+		},
+	}
+
+	tests := []struct {
+		name               string
+		stack              string
+		context            map[string]interface{}
+		wantDevConfig      kusionapiv1.Accessory
+		wantPlatformConfig kusionapiv1.GenericConfig
+		wantErr            bool
+	}{
+		{
+			name:          "workspace resolved from stack",
+			stack:         "dev",
+			wantDevConfig: kusionapiv1.Accessory{"maxUnavailable": "50%", "maxSurge": 1},
+			wantPlatformConfig: kusionapiv1.GenericConfig{
+				"maxUnavailable": "50%",
+			},
+		},
+		{
+			name:          "workspace resolved from context",
+			stack:         "dev",
+			context:       map[string]interface{}{"workspace": "prod"},
+			wantDevConfig: kusionapiv1.Accessory{"maxUnavailable": 0, "maxSurge": 1, "podDisruptionBudget": map[string]interface{}{"minAvailable": 1}},
+			wantPlatformConfig: kusionapiv1.GenericConfig{
+				"maxUnavailable":      0,
+				"podDisruptionBudget": map[string]interface{}{"minAvailable": 1},
+			},
+		},
+		{
+			name:          "no policy of workspace",
+			stack:         "staging",
+			wantDevConfig: kusionapiv1.Accessory{"maxUnavailable": "30%", "maxSurge": 1},
+			wantPlatformConfig: kusionapiv1.GenericConfig{
+				"maxUnavailable": "25%",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveRolloutPolicy(&module.GeneratorRequest{
+				Project:        "default",
+				Stack:          tt.stack,
+				App:            "foo",
+				DevConfig:      map[string]interface{}{"maxUnavailable": "30%", "maxSurge": 1},
+				PlatformConfig: platformConfig,
+				Context:        tt.context,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResolveRolloutPolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got.DevConfig, tt.wantDevConfig) {
+				t.Errorf("ResolveRolloutPolicy() got dev config = %v, want %v", got.DevConfig, tt.wantDevConfig)
+			}
+			if !reflect.DeepEqual(got.PlatformConfig, tt.wantPlatformConfig) {
+				t.Errorf("ResolveRolloutPolicy() got platform config = %v, want %v", got.PlatformConfig, tt.wantPlatformConfig)
+			}
+		})
+	}
+}
+
+func TestGetPodDisruptionBudget(t *testing.T) {
+	tests := []struct {
+		name      string
+		devConfig map[string]interface{}
+		wantSpec  map[string]interface{}
+		wantErr   bool
+	}{
+		{
+			name: "no pod disruption budget",
+		},
+		{
+			name: "min available",
+			devConfig: map[string]interface{}{
+				"podDisruptionBudget": map[string]interface{}{
+					"minAvailable": "50%",
+				},
+			},
+			wantSpec: map[string]interface{}{
+				"minAvailable": "50%",
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{
+						"app.kubernetes.io/name": "foo", "app.kubernetes.io/part-of": "default",
+					},
+				},
+			},
+		},
+		{
+			name: "both min available and max unavailable",
+			devConfig: map[string]interface{}{
+				"podDisruptionBudget": map[string]interface{}{
+					"minAvailable":   1,
+					"maxUnavailable": 1,
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetPodDisruptionBudget(&module.GeneratorRequest{
+				Project:   "default",
+				Stack:     "dev",
+				App:       "foo",
+				DevConfig: tt.devConfig,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetPodDisruptionBudget() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantSpec == nil {
+				if got != nil {
+					t.Errorf("GetPodDisruptionBudget() got = %v, want nil", got)
+				}
+				return
+			}
+			if got.ID != "policy/v1:PodDisruptionBudget:default:default-dev-foo" {
+				t.Errorf("GetPodDisruptionBudget() got ID = %v", got.ID)
+			}
+			if !reflect.DeepEqual(got.Attributes["spec"], tt.wantSpec) {
+				t.Errorf("GetPodDisruptionBudget() got spec = %v, want %v", got.Attributes["spec"], tt.wantSpec)
+			}
+		})
+	}
+}
+
+func TestGetTransitionRules(t *testing.T) {
+	stage := "PreTrafficOff"
+	minAvailable := intstr.FromInt32(2)
+
+	tests := []struct {
+		name           string
+		devConfig      map[string]interface{}
+		platformConfig map[string]interface{}
+		want           []v1alpha1.TransitionRule
+		wantErr        bool
+	}{
+		{
+			name: "no transition rules",
+			want: nil,
+		},
+		{
+			name: "developer config takes precedence over platform config",
+			devConfig: map[string]interface{}{
+				"labelChecks": []interface{}{
+					map[string]interface{}{
+						"name":  "trafficOff",
+						"stage": "PreTrafficOff",
+						"requires": map[string]interface{}{
+							"app.example.io/traffic-off": "true",
+						},
+					},
+				},
+			},
+			platformConfig: map[string]interface{}{
+				"minAvailable": 2,
+				"labelChecks": []interface{}{
+					map[string]interface{}{
+						"name": "ignored",
+						"requires": map[string]interface{}{
+							"app.example.io/ignored": "true",
+						},
+					},
+				},
+			},
+			want: []v1alpha1.TransitionRule{
+				{
+					Name: "minAvailable",
+					TransitionRuleDefinition: v1alpha1.TransitionRuleDefinition{
+						AvailablePolicy: &v1alpha1.AvailableRule{
+							MinAvailableValue: &minAvailable,
+						},
+					},
+				},
+				{
+					Name:  "trafficOff",
+					Stage: &stage,
+					TransitionRuleDefinition: v1alpha1.TransitionRuleDefinition{
+						LabelCheck: &v1alpha1.LabelCheckRule{
+							Requires: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app.example.io/traffic-off": "true",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "label check without requires",
+			devConfig: map[string]interface{}{
+				"labelChecks": []interface{}{
+					map[string]interface{}{
+						"name": "trafficOff",
+					},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetTransitionRules(tt.devConfig, tt.platformConfig)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetTransitionRules() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetTransitionRules() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpsRuleModule_GenerateInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		wantErr        string
+	}{
+		{
+			name:      "unknown field in dev config",
+			devConfig: kusionapiv1.Accessory{"maxUnavaliable": "30%"},
+			wantErr:   "maxUnavaliable: unknown field",
+		},
+		{
+			name:      "mismatched type in dev config",
+			devConfig: kusionapiv1.Accessory{"maxUnavailable": true},
+			wantErr:   "maxUnavailable: expected integer or string, got boolean",
+		},
+		{
+			name: "unknown field in workspace policies",
+			platformConfig: kusionapiv1.GenericConfig{
+				"policies": map[string]interface{}{
+					"prod": map[string]interface{}{
+						"podDisruptionBudget": map[string]interface{}{"minAvaliable": 1},
+					},
+				},
+			},
+			wantErr: "policies.prod.podDisruptionBudget.minAvaliable: unknown field",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &module.GeneratorRequest{
+				Project:        "default",
+				Stack:          "dev",
+				App:            "foo",
+				Workload:       map[string]interface{}{"type": "CollaSet"},
+				DevConfig:      tt.devConfig,
+				PlatformConfig: tt.platformConfig,
+			}
+			_, err := (&OpsRuleModule{}).Generate(context.Background(), request)
+			if !errors.Is(err, moduleutil.ErrInvalidConfig) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
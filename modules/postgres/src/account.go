@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	accountJobSuffix       = "-account-"
+	accountJobBackoffLimit = 10
+	accountScriptEnv       = "PSQL_SCRIPT"
+	// accountJobUser is the uid of the postgres user in the official PostgreSQL images.
+	accountJobUser = 999
+)
+
+var (
+	ErrInvalidConnectionLimit = errors.New("postgres account connectionLimit must not be negative")
+	ErrInvalidAccountTimeout  = errors.New("invalid postgres account timeout, must be a non-negative duration of whole milliseconds")
+)
+
+// AccountConfig describes the settings enforced on the application account of the PostgreSQL
+// database, which are applied with ALTER ROLE by a bootstrap Job once the database is ready.
+type AccountConfig struct {
+	// The maximum number of the concurrent connections of the account.
+	ConnectionLimit int `json:"connectionLimit,omitempty" yaml:"connectionLimit,omitempty"`
+	// The maximum duration of the statements of the account, e.g. 30s.
+	StatementTimeout string `json:"statementTimeout,omitempty" yaml:"statementTimeout,omitempty"`
+	// The maximum idle duration of the sessions of the account in an open transaction, e.g. 1m.
+	IdleInTransactionTimeout string `json:"idleInTransactionTimeout,omitempty" yaml:"idleInTransactionTimeout,omitempty"`
+	// The maximum idle duration of the sessions of the account not in a transaction, e.g. 10m,
+	// which requires PostgreSQL 14 or later.
+	IdleSessionTimeout string `json:"idleSessionTimeout,omitempty" yaml:"idleSessionTimeout,omitempty"`
+	// The image of the bootstrap Job running psql, which defaults to the PostgreSQL image of the
+	// database version.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
+// validateAccountConfig validates the account config of the PostgreSQL instance.
+func (postgres *PostgreSQL) validateAccountConfig() error {
+	account := postgres.Account
+	if account == nil {
+		return nil
+	}
+	if account.ConnectionLimit < 0 {
+		return ErrInvalidConnectionLimit
+	}
+	for _, setting := range []struct{ field, timeout string }{
+		{"statementTimeout", account.StatementTimeout},
+		{"idleInTransactionTimeout", account.IdleInTransactionTimeout},
+		{"idleSessionTimeout", account.IdleSessionTimeout},
+	} {
+		if _, err := timeoutMillis(setting.timeout); err != nil {
+			return fmt.Errorf("%w, %w", ErrInvalidAccountTimeout, &moduleutil.ConfigFieldError{
+				Path:   "account." + setting.field,
+				Reason: setting.timeout + " is not a valid timeout",
+			})
+		}
+	}
+	return nil
+}
+
+// timeoutMillis returns the milliseconds of the timeout, or zero if empty.
+func timeoutMillis(timeout string) (int64, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d < 0 || d%time.Millisecond != 0 {
+		return 0, ErrInvalidAccountTimeout
+	}
+	return d.Milliseconds(), nil
+}
+
+// accountStatements returns the ALTER ROLE statements applying the account settings.
+func (postgres *PostgreSQL) accountStatements() []string {
+	account := postgres.Account
+	if account == nil {
+		return nil
+	}
+	role := `"` + strings.ReplaceAll(postgres.Username, `"`, `""`) + `"`
+
+	var statements []string
+	if account.ConnectionLimit > 0 {
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s CONNECTION LIMIT %d;", role, account.ConnectionLimit))
+	}
+	for _, setting := range []struct{ name, timeout string }{
+		{"statement_timeout", account.StatementTimeout},
+		{"idle_in_transaction_session_timeout", account.IdleInTransactionTimeout},
+		{"idle_session_timeout", account.IdleSessionTimeout},
+	} {
+		if setting.timeout == "" {
+			continue
+		}
+		millis, _ := timeoutMillis(setting.timeout)
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s SET %s = %d;", role, setting.name, millis))
+	}
+	return statements
+}
+
+// GenerateAccountJob generates the Kubernetes Job applying the account settings and creating the
+// replication account of cdc with psql, which connects with the credentials in the database
+// Secret. The Job is named after the hash of the statements, so that a new Job is created once
+// the settings change, as the template of the Job is immutable. It returns nil if neither the
+// account settings nor cdc are configured.
+func (postgres *PostgreSQL) GenerateAccountJob(request *module.GeneratorRequest, providerType string, resources []kusionapiv1.Resource) (*kusionapiv1.Resource, error) {
+	statements := append(postgres.accountStatements(), postgres.cdcStatements(providerType)...)
+	if len(statements) == 0 {
+		return nil, nil
+	}
+	secretName := moduleutil.OutputSecretName(request, dbEngine)
+	cdcSecretName := postgres.DatabaseName + dbResSuffix + cdcSuffix
+	var dependsOn []string
+	for _, res := range resources {
+		metadata, _ := res.Attributes["metadata"].(map[string]interface{})
+		if _, ok := res.Extensions[moduleutil.OutputsExtensionKey]; ok || (moduleutil.ResourceKind(res) == "Secret" && metadata["name"] == cdcSecretName) {
+			dependsOn = append(dependsOn, res.ID)
+		}
+	}
+	script := strings.Join(statements, "\n")
+	sum := sha256.Sum256([]byte(script))
+
+	image := dbEngine + ":" + postgres.Version
+	if postgres.Account != nil && postgres.Account.Image != "" {
+		image = postgres.Account.Image
+	}
+	secretEnv := func(name, secretName, key string) v1.EnvVar {
+		return v1.EnvVar{
+			Name: name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: secretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	env := []v1.EnvVar{
+		secretEnv("PGHOST", secretName, "hostAddress"),
+		secretEnv("PGPORT", secretName, "port"),
+		secretEnv("PGUSER", secretName, "username"),
+		secretEnv("PGPASSWORD", secretName, "password"),
+		{Name: "PGDATABASE", Value: dbEngine},
+		{Name: "PGCONNECT_TIMEOUT", Value: "10"},
+		{Name: accountScriptEnv, Value: script},
+	}
+	// The statements are read from the standard input rather than the -c flag, which interpolates
+	// the psql variables, e.g. the password of the replication account.
+	command := `printf '%s\n' "$` + accountScriptEnv + `" | psql -v ON_ERROR_STOP=1`
+	if postgres.CDC != nil {
+		env = append(env, secretEnv(cdcPasswordEnv, cdcSecretName, "password"))
+		command += ` -v ` + cdcPasswordPsqlVariable + `="$` + cdcPasswordEnv + `"`
+	}
+	backoffLimit := int32(accountJobBackoffLimit)
+	runAsUser := int64(accountJobUser)
+	runAsNonRoot, allowPrivilegeEscalation := true, false
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgres.DatabaseName + accountJobSuffix + hex.EncodeToString(sum[:])[:8],
+			Namespace: request.Project,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyOnFailure,
+					SecurityContext: &v1.PodSecurityContext{
+						RunAsUser:      &runAsUser,
+						RunAsNonRoot:   &runAsNonRoot,
+						SeccompProfile: &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []v1.Container{
+						{
+							Name:    "psql",
+							Image:   image,
+							Command: []string{"sh", "-c", command},
+							Env:     env,
+							SecurityContext: &v1.SecurityContext{
+								AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+								Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(job.TypeMeta, job.ObjectMeta)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, job)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = dependsOn
+
+	return resource, nil
+}
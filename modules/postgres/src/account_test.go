@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestPostgreSQLModule_GetCompleteConfigAccount(t *testing.T) {
+	tests := []struct {
+		name               string
+		account            map[string]interface{}
+		expectedStatements []string
+		expectedErr        error
+	}{
+		{
+			name:    "connection limit and timeouts",
+			account: map[string]interface{}{"connectionLimit": 20, "statementTimeout": "30s", "idleSessionTimeout": "1h"},
+			expectedStatements: []string{
+				`ALTER ROLE "kusion_default" CONNECTION LIMIT 20;`,
+				`ALTER ROLE "kusion_default" SET statement_timeout = 30000;`,
+				`ALTER ROLE "kusion_default" SET idle_session_timeout = 3600000;`,
+			},
+		},
+		{
+			name:    "no settings",
+			account: map[string]interface{}{"image": "postgres:16"},
+		},
+		{
+			name:        "negative connection limit",
+			account:     map[string]interface{}{"connectionLimit": -1},
+			expectedErr: ErrInvalidConnectionLimit,
+		},
+		{
+			name:        "invalid timeout",
+			account:     map[string]interface{}{"idleInTransactionTimeout": "1 minute"},
+			expectedErr: ErrInvalidAccountTimeout,
+		},
+		{
+			name:        "sub-millisecond timeout",
+			account:     map[string]interface{}{"statementTimeout": "1500us"},
+			expectedErr: ErrInvalidAccountTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postgres := &PostgreSQL{}
+			err := postgres.GetCompleteConfig(
+				kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+				kusionapiv1.GenericConfig{"account": tt.account},
+			)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatements, postgres.accountStatements())
+		})
+	}
+}
@@ -2,14 +2,17 @@ package main
 
 import (
 	"errors"
-	"os"
 	"strings"
 
+	"dbutil"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 )
 
-var ErrEmptyAlicloudProviderRegion = errors.New("empty alicloud provider region")
+var (
+	ErrEmptyAlicloudProviderRegion = errors.New("empty alicloud provider region")
+	ErrInvalidEndpoints            = dbutil.ErrInvalidEndpoints
+)
 
 var (
 	alicloudRegionEnv    = "ALICLOUD_REGION"
@@ -34,14 +37,17 @@ type alicloudServerlessConfig struct {
 func (postgres *PostgreSQL) GenerateAlicloudResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, *kusionapiv1.Patcher, error) {
 	var resources []kusionapiv1.Resource
 
+	// Only the serverless instances of Alicloud are paused automatically.
+	if postgres.Schedule != nil && !strings.Contains(postgres.Category, "serverless") {
+		return nil, nil, ErrScheduleUnsupported
+	}
+
 	// Set the Alicloud provider with the default provider config.
 	alicloudProviderCfg := defaultAlicloudProviderCfg
 
-	// Get the Alicloud Terraform provider region, which should not be empty.
-	var region string
-	if region = module.TerraformProviderRegion(alicloudProviderCfg); region == "" {
-		region = os.Getenv(alicloudRegionEnv)
-	}
+	// Get the Alicloud Terraform provider region resolved from the configs, or the
+	// environment variable if not set, which should not be empty.
+	region := dbutil.ProviderRegion(postgres.Region, alicloudProviderCfg, alicloudRegionEnv)
 	if region == "" {
 		return nil, nil, ErrEmptyAlicloudProviderRegion
 	}
@@ -121,6 +127,13 @@ func (postgres *PostgreSQL) generateAlicloudDBInstance(alicloudProviderCfg modul
 		"instance_name":    postgres.DatabaseName,
 	}
 
+	// Enable the logical replication for cdc.
+	if postgres.CDC != nil {
+		resAttrs["parameters"] = []map[string]interface{}{
+			{"name": "wal_level", "value": "logical"},
+		}
+	}
+
 	// Set the serverless-specific attributes of the alicloud_db_instance resource.
 	if strings.Contains(postgres.Category, "serverless") {
 		resAttrs["db_instance_storage_type"] = "cloud_essd"
@@ -130,7 +143,7 @@ func (postgres *PostgreSQL) generateAlicloudDBInstance(alicloudProviderCfg modul
 			MaxCapacity: 8,
 			MinCapacity: 1,
 		}
-		serverlessConfig.AutoPause = false
+		serverlessConfig.AutoPause = postgres.Schedule != nil
 		serverlessConfig.SwitchForce = false
 
 		resAttrs["serverless_config"] = []alicloudServerlessConfig{
@@ -143,7 +156,7 @@ func (postgres *PostgreSQL) generateAlicloudDBInstance(alicloudProviderCfg modul
 		return nil, "", err
 	}
 
-	alicloudProviderCfg.ProviderMeta = map[string]any{"region": region}
+	alicloudProviderCfg.ProviderMeta = postgres.alicloudProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudDBInstance, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -167,7 +180,7 @@ func (postgres *PostgreSQL) generateAlicloudDBConnection(alicloudProviderCfg mod
 		return nil, "", err
 	}
 
-	alicloudProviderCfg.ProviderMeta = map[string]any{"region": region}
+	alicloudProviderCfg.ProviderMeta = postgres.alicloudProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudDBConnection, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -193,7 +206,7 @@ func (postgres *PostgreSQL) generateAlicloudRDSAccount(alicloudProviderCfg modul
 		return nil, err
 	}
 
-	alicloudProviderCfg.ProviderMeta = map[string]any{"region": region}
+	alicloudProviderCfg.ProviderMeta = postgres.alicloudProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(alicloudProviderCfg, alicloudRDSAccount, id, resAttrs, nil)
 	if err != nil {
 		return nil, err
@@ -201,3 +214,10 @@ func (postgres *PostgreSQL) generateAlicloudRDSAccount(alicloudProviderCfg modul
 
 	return resource, nil
 }
+
+// alicloudProviderMeta returns the provider meta of the Alicloud provider in the region, which
+// assumes the RAM role, uses the custom endpoints and the security token if set in the platform
+// config.
+func (postgres *PostgreSQL) alicloudProviderMeta(region string) map[string]any {
+	return postgres.providerOverride().AlicloudProviderMeta(region)
+}
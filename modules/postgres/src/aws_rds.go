@@ -3,13 +3,17 @@ package main
 import (
 	"errors"
 	"fmt"
-	"os"
 
+	"dbutil"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 )
 
-var ErrEmptyAWSProviderRegion = errors.New("empty aws provider region")
+var (
+	ErrEmptyAWSProviderRegion = errors.New("empty aws provider region")
+	ErrEmptyAssumeRoleARN     = dbutil.ErrEmptyAssumeRoleARN
+	ErrInvalidAssumeRoleARN   = dbutil.ErrInvalidAssumeRoleARN
+)
 
 var (
 	awsRegionEnv     = "AWS_REGION"
@@ -41,11 +45,9 @@ func (postgres *PostgreSQL) GenerateAWSResources(request *module.GeneratorReques
 	// Set the AWS provider with the default provider config.
 	awsProviderCfg := defaultAWSProviderCfg
 
-	// Get the AWS Terraform provider region, which should not be empty.
-	var region string
-	if region = module.TerraformProviderRegion(awsProviderCfg); region == "" {
-		region = os.Getenv(awsRegionEnv)
-	}
+	// Get the AWS Terraform provider region resolved from the configs, or the
+	// environment variable if not set, which should not be empty.
+	region := dbutil.ProviderRegion(postgres.Region, awsProviderCfg, awsRegionEnv)
 	if region == "" {
 		return nil, nil, ErrEmptyAWSProviderRegion
 	}
@@ -69,8 +71,27 @@ func (postgres *PostgreSQL) GenerateAWSResources(request *module.GeneratorReques
 	if err != nil {
 		return nil, nil, err
 	}
+
+	// Build aws_db_parameter_group resource enabling the logical replication for cdc.
+	if postgres.CDC != nil {
+		parameterGroup, parameterGroupID, err := postgres.generateAWSDBParameterGroup(awsProviderCfg, region)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *parameterGroup)
+		awsDBInstance.Attributes["parameter_group_name"] = module.KusionPathDependency(parameterGroupID, "name")
+	}
 	resources = append(resources, *awsDBInstance)
 
+	// Build the EventBridge schedules stopping and starting the aws_db_instance.
+	if postgres.Schedule != nil {
+		schedules, err := postgres.generateAWSSchedules(awsProviderCfg, region, awsDBInstanceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, schedules...)
+	}
+
 	hostAddress := module.KusionPathDependency(awsDBInstanceID, "address")
 	password := module.KusionPathDependency(randomPasswordID, "result")
 
@@ -119,7 +140,7 @@ func (postgres *PostgreSQL) generateAWSSecurityGroup(awsProviderCfg module.Provi
 		return nil, "", err
 	}
 
-	awsProviderCfg.ProviderMeta = map[string]any{"region": region}
+	awsProviderCfg.ProviderMeta = postgres.awsProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsSecurityGroup, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -154,7 +175,7 @@ func (postgres *PostgreSQL) generateAWSDBInstance(awsProviderCfg module.Provider
 		return nil, "", err
 	}
 
-	awsProviderCfg.ProviderMeta = map[string]any{"region": region}
+	awsProviderCfg.ProviderMeta = postgres.awsProviderMeta(region)
 	resource, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsDBInstance, id, resAttrs, nil)
 	if err != nil {
 		return nil, "", err
@@ -162,3 +183,20 @@ func (postgres *PostgreSQL) generateAWSDBInstance(awsProviderCfg module.Provider
 
 	return resource, id, nil
 }
+
+// awsProviderMeta returns the provider meta of the AWS provider in the region, which assumes the
+// IAM role and uses the named profile if set in the platform config.
+func (postgres *PostgreSQL) awsProviderMeta(region string) map[string]any {
+	return postgres.providerOverride().AWSProviderMeta(region)
+}
+
+// providerOverride returns the override of the provider configs in the platform config.
+func (postgres *PostgreSQL) providerOverride() dbutil.ProviderOverride {
+	return dbutil.ProviderOverride{
+		AssumeRoleARN: postgres.AssumeRoleARN,
+		SessionName:   postgres.SessionName,
+		Profile:       postgres.Profile,
+		Endpoints:     postgres.Endpoints,
+		SecurityToken: postgres.SecurityToken,
+	}
+}
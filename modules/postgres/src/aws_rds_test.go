@@ -84,3 +84,27 @@ func TestPostgreSQLModule_GenerateAWSDBInstance(t *testing.T) {
 	assert.NotEqual(t, id, "")
 	assert.NoError(t, err)
 }
+
+func TestPostgreSQLModule_AWSProviderMeta(t *testing.T) {
+	postgres := &PostgreSQL{
+		Type:          "cloud",
+		DatabaseName:  "test-database",
+		InstanceType:  "db.t3.micro",
+		AssumeRoleARN: "arn:aws:iam::123456789012:role/kusion",
+		SessionName:   "kusion",
+		Profile:       "payments",
+	}
+
+	res, _, err := postgres.generateAWSDBInstance(defaultAWSProviderCfg, "test-region", "test-password-id", "test-security-group-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"region":  "test-region",
+		"profile": "payments",
+		"assume_role": map[string]any{
+			"role_arn":     "arn:aws:iam::123456789012:role/kusion",
+			"session_name": "kusion",
+		},
+	}, res.Extensions["providerMeta"])
+	assert.Equal(t, map[string]any{"region": "test-region"}, (&PostgreSQL{}).awsProviderMeta("test-region"))
+}
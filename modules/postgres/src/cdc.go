@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"dbutil"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	awsDBParameterGroup = "aws_db_parameter_group"
+
+	cdcSuffix                = "-cdc"
+	debeziumSuffix           = "-debezium"
+	debeziumConnectorKey     = "connector.json"
+	debeziumConnectorClass   = "io.debezium.connector.postgresql.PostgresConnector"
+	defaultCDCUsername       = "kusion_cdc"
+	defaultCDCPublication    = "dbz_publication"
+	cdcPasswordEnv           = "CDC_PASSWORD"
+	cdcPasswordPsqlVariable  = "cdc_password"
+	awsReplicationRole       = "rds_replication"
+	awsLogicalReplicationKey = "rds.logical_replication"
+)
+
+var ErrEmptyVersionForCDC = errors.New("empty postgres version for the parameter group family of cdc")
+
+// CDCConfig describes the logical replication of the PostgreSQL database for the change data
+// capture, which sets the wal_level to logical, creates the replication account, and optionally
+// generates the connector config of Debezium.
+type CDCConfig struct {
+	// The name of the replication account, which defaults to kusion_cdc.
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// Whether to generate the ConfigMap of the Debezium PostgreSQL connector config.
+	Debezium bool `json:"debezium,omitempty" yaml:"debezium,omitempty"`
+	// The name of the replication slot of the connector, which defaults to the database name.
+	SlotName string `json:"slotName,omitempty" yaml:"slotName,omitempty"`
+	// The name of the publication of the connector, which defaults to dbz_publication.
+	PublicationName string `json:"publicationName,omitempty" yaml:"publicationName,omitempty"`
+}
+
+// completeCDCConfig fills the unset fields of the cdc config with the defaults.
+func completeCDCConfig(cdc *CDCConfig) {
+	if cdc == nil {
+		return
+	}
+	if cdc.Username == "" {
+		cdc.Username = defaultCDCUsername
+	}
+	if cdc.PublicationName == "" {
+		cdc.PublicationName = defaultCDCPublication
+	}
+}
+
+// cdcSlotName returns the name of the replication slot, which only allows the lower case letters,
+// numbers and underscores.
+func (postgres *PostgreSQL) cdcSlotName() string {
+	if postgres.CDC.SlotName != "" {
+		return postgres.CDC.SlotName
+	}
+	return strings.ReplaceAll(strings.ToLower(postgres.DatabaseName), "-", "_")
+}
+
+// awsParameterGroupFamily returns the family of the parameter group of the PostgreSQL version,
+// e.g. postgres14 of 14.7, and postgres9.6 of 9.6.2.
+func (postgres *PostgreSQL) awsParameterGroupFamily() (string, error) {
+	if postgres.Version == "" {
+		return "", ErrEmptyVersionForCDC
+	}
+	parts := strings.Split(postgres.Version, ".")
+	if parts[0] == "9" && len(parts) > 1 {
+		return dbEngine + parts[0] + "." + parts[1], nil
+	}
+	return dbEngine + parts[0], nil
+}
+
+// generateAWSDBParameterGroup generates aws_db_parameter_group resource enabling the logical
+// replication of the AWS provided PostgreSQL database instance.
+func (postgres *PostgreSQL) generateAWSDBParameterGroup(awsProviderCfg module.ProviderConfig, region string) (*kusionapiv1.Resource, string, error) {
+	family, err := postgres.awsParameterGroupFamily()
+	if err != nil {
+		return nil, "", err
+	}
+	resAttrs := map[string]interface{}{
+		"name":   postgres.DatabaseName + cdcSuffix,
+		"family": family,
+		"parameter": []map[string]interface{}{
+			{
+				"name":         awsLogicalReplicationKey,
+				"value":        "1",
+				"apply_method": "pending-reboot",
+			},
+		},
+	}
+
+	id, err := module.TerraformResourceID(awsProviderCfg, awsDBParameterGroup, postgres.DatabaseName+cdcSuffix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	awsProviderCfg.ProviderMeta = postgres.awsProviderMeta(region)
+	resource, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsDBParameterGroup, id, resAttrs, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resource, id, nil
+}
+
+// cdcStatements returns the statements creating the replication account, whose password is the
+// psql variable of cdc_password. The master accounts of AWS RDS can't grant the REPLICATION
+// attribute, and grant the rds_replication role instead.
+func (postgres *PostgreSQL) cdcStatements(providerType string) []string {
+	if postgres.CDC == nil {
+		return nil
+	}
+	name := strings.ReplaceAll(postgres.CDC.Username, "'", "''")
+	role := `"` + strings.ReplaceAll(postgres.CDC.Username, `"`, `""`) + `"`
+
+	statements := []string{
+		fmt.Sprintf(`SELECT format('CREATE ROLE %%I LOGIN', '%s') WHERE NOT EXISTS (SELECT FROM pg_roles WHERE rolname = '%s')\gexec`, name, name),
+		fmt.Sprintf("ALTER ROLE %s WITH LOGIN PASSWORD :'%s';", role, cdcPasswordPsqlVariable),
+	}
+	if strings.ToLower(providerType) == "aws" {
+		statements = append(statements, fmt.Sprintf("GRANT %s TO %s;", awsReplicationRole, role))
+	} else {
+		statements = append(statements, fmt.Sprintf("ALTER ROLE %s WITH REPLICATION;", role))
+	}
+	return statements
+}
+
+// GenerateCDCResources generates the Secret of the credentials of the replication account, and the
+// ConfigMap of the Debezium connector config if enabled. The password of the account of the cloud
+// provided instance is generated by the random_password resource.
+func (postgres *PostgreSQL) GenerateCDCResources(request *module.GeneratorRequest, resources []kusionapiv1.Resource) ([]kusionapiv1.Resource, error) {
+	if postgres.CDC == nil {
+		return nil, nil
+	}
+
+	var cdcResources []kusionapiv1.Resource
+	password := dbutil.LocalPassword(request, postgres.DatabaseName+cdcSuffix)
+	if strings.ToLower(postgres.Type) == CloudDBType {
+		randomPasswordRes, randomPasswordID, err := postgres.generateCDCRandomPassword()
+		if err != nil {
+			return nil, err
+		}
+		cdcResources = append(cdcResources, *randomPasswordRes)
+		password = module.KusionPathDependency(randomPasswordID, "result")
+	}
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgres.DatabaseName + dbResSuffix + cdcSuffix,
+			Namespace: request.Project,
+		},
+		StringData: map[string]string{
+			"username": postgres.CDC.Username,
+			"password": password,
+		},
+	}
+	secretRes, err := module.WrapK8sResourceToKusionResource(module.KubernetesResourceID(secret.TypeMeta, secret.ObjectMeta), secret)
+	if err != nil {
+		return nil, err
+	}
+	cdcResources = append(cdcResources, *secretRes)
+
+	if !postgres.CDC.Debezium {
+		return cdcResources, nil
+	}
+
+	// The host address is the one in the database Secret, which references the address of the cloud
+	// provided instance, and the password is read from the Secret by the KubernetesSecretConfigProvider
+	// of Kafka Connect.
+	var hostAddress string
+	for _, res := range resources {
+		if _, ok := res.Extensions[moduleutil.OutputsExtensionKey]; ok {
+			data, _ := res.Attributes["stringData"].(map[string]interface{})
+			hostAddress = fmt.Sprint(data["hostAddress"])
+		}
+	}
+	database := dbEngine
+	if strings.ToLower(postgres.Type) == LocalDBType {
+		database = postgres.DatabaseName
+	}
+	connector, err := json.MarshalIndent(map[string]interface{}{
+		"name": postgres.DatabaseName,
+		"config": map[string]string{
+			"connector.class":             debeziumConnectorClass,
+			"plugin.name":                 "pgoutput",
+			"database.hostname":           hostAddress,
+			"database.port":               fmt.Sprint(dbPort),
+			"database.user":               postgres.CDC.Username,
+			"database.password":           fmt.Sprintf("${secrets:%s/%s:password}", secret.Namespace, secret.Name),
+			"database.dbname":             database,
+			"topic.prefix":                postgres.DatabaseName,
+			"slot.name":                   postgres.cdcSlotName(),
+			"publication.name":            postgres.CDC.PublicationName,
+			"publication.autocreate.mode": "disabled",
+		},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	configMap := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ConfigMap",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgres.DatabaseName + debeziumSuffix,
+			Namespace: request.Project,
+		},
+		Data: map[string]string{debeziumConnectorKey: string(connector)},
+	}
+	configMapRes, err := module.WrapK8sResourceToKusionResource(module.KubernetesResourceID(configMap.TypeMeta, configMap.ObjectMeta), configMap)
+	if err != nil {
+		return nil, err
+	}
+	cdcResources = append(cdcResources, *configMapRes)
+
+	return cdcResources, nil
+}
+
+// generateCDCRandomPassword generates the terraform random_password resource as the password of
+// the replication account of the cloud provided PostgreSQL database instance.
+func (postgres *PostgreSQL) generateCDCRandomPassword() (*kusionapiv1.Resource, string, error) {
+	return dbutil.RandomPassword(postgres.DatabaseName+dbResSuffix+cdcSuffix, "")
+}
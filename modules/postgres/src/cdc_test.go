@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestPostgreSQLModule_GetCompleteConfigCDC(t *testing.T) {
+	postgres := &PostgreSQL{}
+	err := postgres.GetCompleteConfig(
+		kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+		kusionapiv1.GenericConfig{"cdc": map[string]interface{}{"slotName": "orders"}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &CDCConfig{
+		Username:        defaultCDCUsername,
+		SlotName:        "orders",
+		PublicationName: defaultCDCPublication,
+	}, postgres.CDC)
+}
+
+func TestPostgreSQLModule_CDCStatements(t *testing.T) {
+	postgres := &PostgreSQL{CDC: &CDCConfig{Username: "cdc"}}
+	create := []string{
+		`SELECT format('CREATE ROLE %I LOGIN', 'cdc') WHERE NOT EXISTS (SELECT FROM pg_roles WHERE rolname = 'cdc')\gexec`,
+		`ALTER ROLE "cdc" WITH LOGIN PASSWORD :'cdc_password';`,
+	}
+	assert.Equal(t, append(create, `GRANT rds_replication TO "cdc";`), postgres.cdcStatements("aws"))
+	assert.Equal(t, append(create, `ALTER ROLE "cdc" WITH REPLICATION;`), postgres.cdcStatements("alicloud"))
+	assert.Nil(t, (&PostgreSQL{}).cdcStatements(""))
+}
+
+func TestPostgreSQLModule_AWSParameterGroupFamily(t *testing.T) {
+	tests := []struct {
+		version        string
+		expectedFamily string
+		expectedErr    error
+	}{
+		{version: "14.7", expectedFamily: "postgres14"},
+		{version: "16", expectedFamily: "postgres16"},
+		{version: "9.6.2", expectedFamily: "postgres9.6"},
+		{version: "", expectedErr: ErrEmptyVersionForCDC},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			family, err := (&PostgreSQL{Version: tt.version}).awsParameterGroupFamily()
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedFamily, family)
+		})
+	}
+}
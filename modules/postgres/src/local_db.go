@@ -1,12 +1,11 @@
 package main
 
 import (
-	"crypto/md5"
-	"encoding/hex"
 	"strconv"
 
 	"kusionstack.io/kusion-module-framework/pkg/module"
 
+	"dbutil"
 	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -142,7 +141,7 @@ func (postgres *PostgreSQL) generateLocalPodSpec(_ *module.GeneratorRequest) (v1
 	ports := []v1.ContainerPort{
 		{
 			Name:          portName,
-			ContainerPort: int32(5432),
+			ContainerPort: int32(dbPort),
 		},
 	}
 
@@ -199,11 +198,18 @@ func (postgres *PostgreSQL) generateLocalPodSpec(_ *module.GeneratorRequest) (v1
 		},
 	}
 
+	// Enable the logical replication for cdc.
+	var args []string
+	if postgres.CDC != nil {
+		args = []string{"-c", "wal_level=logical"}
+	}
+
 	podSpec := v1.PodSpec{
 		Containers: []v1.Container{
 			{
 				Name:         postgres.DatabaseName,
 				Image:        image,
+				Args:         args,
 				Env:          env,
 				Ports:        ports,
 				VolumeMounts: volumeMounts,
@@ -286,7 +292,7 @@ func (postgres *PostgreSQL) generateLocalService(request *module.GeneratorReques
 func (postgres *PostgreSQL) generateLocalSvcPort() []v1.ServicePort {
 	svcPort := []v1.ServicePort{
 		{
-			Port: int32(5432),
+			Port: int32(dbPort),
 		},
 	}
 
@@ -302,10 +308,5 @@ func (postgres *PostgreSQL) generateLocalMatchLabels() map[string]string {
 
 // generateLocalPassword generates a fixed password string with the specified length for the local PostgreSQL instance.
 func (postgres *PostgreSQL) generateLocalPassword(request *module.GeneratorRequest) string {
-	hashInput := request.Project + request.Stack + request.App + postgres.DatabaseName
-	hash := md5.Sum([]byte(hashInput))
-
-	hashString := hex.EncodeToString(hash[:])
-
-	return hashString[:16]
+	return dbutil.LocalPassword(request, postgres.DatabaseName)
 }
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// OperatorCloudNativePG is the CloudNativePG operator managing the local PostgreSQL clusters.
+const OperatorCloudNativePG = "cloudnative-pg"
+
+const (
+	cnpgAPIVersion           = "postgresql.cnpg.io/v1"
+	cnpgImageRepository      = "ghcr.io/cloudnative-pg/postgresql"
+	cnpgReadWriteSuffix      = "-rw"
+	cnpgBackupSuffix         = "-backup"
+	defaultOperatorInstances = 3
+	backupAccessKeyIDKey     = "ACCESS_KEY_ID"
+	backupSecretKeyKey       = "ACCESS_SECRET_KEY"
+)
+
+var (
+	ErrUnsupportedOperator      = errors.New("unsupported postgres operator, only cloudnative-pg is supported")
+	ErrOperatorForCloudDB       = errors.New("postgres operator is only supported for the local postgres instance")
+	ErrInvalidOperatorInstances = errors.New("postgres operator instances must be positive")
+	ErrEmptyBackupDestination   = errors.New("empty destinationPath or credentialsSecret of postgres backup")
+)
+
+// OperatorConfig describes the operator managing the local PostgreSQL cluster, which provides the
+// streaming replication, failover and backup instead of the single-instance Deployment.
+type OperatorConfig struct {
+	// The operator managing the PostgreSQL cluster, only cloudnative-pg is supported.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// The number of the PostgreSQL instances, one as the primary and the others as the replicas.
+	Instances int `json:"instances,omitempty" yaml:"instances,omitempty"`
+	// The operand image of the PostgreSQL instances, which defaults to the CloudNativePG image of
+	// the PostgreSQL version.
+	ImageName string `json:"imageName,omitempty" yaml:"imageName,omitempty"`
+	// The storage class of the volumes of the PostgreSQL instances.
+	StorageClass string `json:"storageClass,omitempty" yaml:"storageClass,omitempty"`
+	// The backup of the PostgreSQL cluster to the object storage.
+	Backup *BackupConfig `json:"backup,omitempty" yaml:"backup,omitempty"`
+}
+
+// BackupConfig describes the backup of the PostgreSQL cluster to the S3 compatible object storage.
+type BackupConfig struct {
+	// The path of the object storage to store the base backups and WAL files, e.g. s3://bucket/path.
+	DestinationPath string `json:"destinationPath,omitempty" yaml:"destinationPath,omitempty"`
+	// The endpoint of the S3 compatible object storage, empty for AWS S3.
+	EndpointURL string `json:"endpointURL,omitempty" yaml:"endpointURL,omitempty"`
+	// The name of the Secret storing the credentials of the object storage in the keys of
+	// ACCESS_KEY_ID and ACCESS_SECRET_KEY.
+	CredentialsSecret string `json:"credentialsSecret,omitempty" yaml:"credentialsSecret,omitempty"`
+	// The retention policy of the backups, e.g. 30d.
+	RetentionPolicy string `json:"retentionPolicy,omitempty" yaml:"retentionPolicy,omitempty"`
+	// The schedule of the base backups in the cron format with seconds, e.g. "0 0 0 * * *".
+	Schedule string `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+// completeOperatorConfig fills the unset fields of the operator config with the defaults.
+func completeOperatorConfig(operator *OperatorConfig) {
+	if operator == nil {
+		return
+	}
+	if operator.Type == "" {
+		operator.Type = OperatorCloudNativePG
+	}
+	if operator.Instances == 0 {
+		operator.Instances = defaultOperatorInstances
+	}
+}
+
+// validateOperatorConfig validates the operator config of the PostgreSQL instance.
+func (postgres *PostgreSQL) validateOperatorConfig() error {
+	operator := postgres.Operator
+	if operator == nil {
+		return nil
+	}
+	if strings.ToLower(postgres.Type) != LocalDBType {
+		return ErrOperatorForCloudDB
+	}
+	if operator.Type != OperatorCloudNativePG {
+		return ErrUnsupportedOperator
+	}
+	if operator.Instances < 0 {
+		return ErrInvalidOperatorInstances
+	}
+	if operator.Backup != nil && (operator.Backup.DestinationPath == "" || operator.Backup.CredentialsSecret == "") {
+		return ErrEmptyBackupDestination
+	}
+	return nil
+}
+
+// GenerateOperatorResources generates the resources of the local PostgreSQL cluster managed by the
+// CloudNativePG operator, which is expected to be installed in the Kubernetes cluster.
+func (postgres *PostgreSQL) GenerateOperatorResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, *kusionapiv1.Patcher, error) {
+	var resources []kusionapiv1.Resource
+
+	// Build Kubernetes Secret for the random password of the owner of the PostgreSQL database,
+	// which bootstraps the cluster.
+	password := postgres.generateLocalPassword(request)
+	localSecret, err := postgres.generateLocalSecret(request, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *localSecret)
+
+	// Build the CloudNativePG Cluster of the PostgreSQL instances.
+	cluster, err := postgres.generateOperatorCluster(request, localSecret.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *cluster)
+
+	// Build the CloudNativePG ScheduledBackup of the base backups.
+	if postgres.Operator.Backup != nil && postgres.Operator.Backup.Schedule != "" {
+		scheduledBackup, err := postgres.generateOperatorScheduledBackup(request, cluster.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		resources = append(resources, *scheduledBackup)
+	}
+
+	// Build Kubernetes Secret with the hostAddress, username and password of the PostgreSQL cluster,
+	// where the read-write Service created by the operator always points to the primary instance.
+	hostAddress := postgres.DatabaseName + cnpgReadWriteSuffix
+	dbSecret, patcher, err := postgres.GenerateDBSecret(request, hostAddress, postgres.Username, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	resources = append(resources, *dbSecret)
+
+	return resources, patcher, nil
+}
+
+// generateOperatorCluster generates the CloudNativePG Cluster resource of the PostgreSQL instances.
+func (postgres *PostgreSQL) generateOperatorCluster(request *module.GeneratorRequest, secretID string) (*kusionapiv1.Resource, error) {
+	operator := postgres.Operator
+
+	imageName := operator.ImageName
+	if imageName == "" {
+		imageName = cnpgImageRepository + ":" + postgres.Version
+	}
+	storage := map[string]interface{}{
+		"size": strconv.Itoa(postgres.Size) + "Gi",
+	}
+	if operator.StorageClass != "" {
+		storage["storageClass"] = operator.StorageClass
+	}
+
+	spec := map[string]interface{}{
+		"instances": int64(operator.Instances),
+		"imageName": imageName,
+		"storage":   storage,
+		"bootstrap": map[string]interface{}{
+			"initdb": map[string]interface{}{
+				"database": postgres.DatabaseName,
+				"owner":    postgres.Username,
+				"secret": map[string]interface{}{
+					"name": postgres.DatabaseName + localSecretSuffix,
+				},
+			},
+		},
+	}
+	if postgres.CDC != nil {
+		spec["postgresql"] = map[string]interface{}{
+			"parameters": map[string]interface{}{"wal_level": "logical"},
+		}
+	}
+	if backup := operator.Backup; backup != nil {
+		objectStore := map[string]interface{}{
+			"destinationPath": backup.DestinationPath,
+			"s3Credentials": map[string]interface{}{
+				"accessKeyId": map[string]interface{}{
+					"name": backup.CredentialsSecret,
+					"key":  backupAccessKeyIDKey,
+				},
+				"secretAccessKey": map[string]interface{}{
+					"name": backup.CredentialsSecret,
+					"key":  backupSecretKeyKey,
+				},
+			},
+		}
+		if backup.EndpointURL != "" {
+			objectStore["endpointURL"] = backup.EndpointURL
+		}
+		backupSpec := map[string]interface{}{
+			"barmanObjectStore": objectStore,
+		}
+		if backup.RetentionPolicy != "" {
+			backupSpec["retentionPolicy"] = backup.RetentionPolicy
+		}
+		spec["backup"] = backupSpec
+	}
+
+	cluster := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": cnpgAPIVersion,
+			"kind":       "Cluster",
+			"metadata": map[string]interface{}{
+				"name":      postgres.DatabaseName,
+				"namespace": request.Project,
+				"labels":    toInterfaceMap(postgres.generateLocalMatchLabels()),
+			},
+			"spec": spec,
+		},
+	}
+
+	resource, err := wrapUnstructured(cluster)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = []string{secretID}
+
+	return resource, nil
+}
+
+// generateOperatorScheduledBackup generates the CloudNativePG ScheduledBackup resource of the base
+// backups of the PostgreSQL cluster.
+func (postgres *PostgreSQL) generateOperatorScheduledBackup(request *module.GeneratorRequest, clusterID string) (*kusionapiv1.Resource, error) {
+	scheduledBackup := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": cnpgAPIVersion,
+			"kind":       "ScheduledBackup",
+			"metadata": map[string]interface{}{
+				"name":      postgres.DatabaseName + cnpgBackupSuffix,
+				"namespace": request.Project,
+			},
+			"spec": map[string]interface{}{
+				"schedule":             postgres.Operator.Backup.Schedule,
+				"backupOwnerReference": "self",
+				"cluster": map[string]interface{}{
+					"name": postgres.DatabaseName,
+				},
+			},
+		},
+	}
+
+	resource, err := wrapUnstructured(scheduledBackup)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = []string{clusterID}
+
+	return resource, nil
+}
+
+// wrapUnstructured wraps the unstructured Kubernetes object into the Kusion resource.
+func wrapUnstructured(obj *unstructured.Unstructured) (*kusionapiv1.Resource, error) {
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: obj.GetAPIVersion(), Kind: obj.GetKind()},
+		metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace()},
+	)
+	return module.WrapK8sResourceToKusionResource(resourceID, obj)
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
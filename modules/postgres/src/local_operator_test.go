@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestPostgreSQLModule_GetCompleteConfigOperator(t *testing.T) {
+	tests := []struct {
+		name             string
+		devConfig        kusionapiv1.Accessory
+		platformConfig   kusionapiv1.GenericConfig
+		expectedOperator *OperatorConfig
+		expectedErr      error
+	}{
+		{
+			name:      "default operator config",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{},
+			},
+			expectedOperator: &OperatorConfig{
+				Type:      OperatorCloudNativePG,
+				Instances: defaultOperatorInstances,
+			},
+		},
+		{
+			name:      "unsupported operator",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{"type": "zalando"},
+			},
+			expectedErr: ErrUnsupportedOperator,
+		},
+		{
+			name:      "operator for cloud postgres",
+			devConfig: kusionapiv1.Accessory{"type": "cloud", "version": "16.4"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"instanceType": "db.t3.micro",
+				"operator":     map[string]interface{}{},
+			},
+			expectedErr: ErrOperatorForCloudDB,
+		},
+		{
+			name:      "negative instances",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{"instances": -1},
+			},
+			expectedErr: ErrInvalidOperatorInstances,
+		},
+		{
+			name:      "backup without credentials",
+			devConfig: kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{
+					"backup": map[string]interface{}{"destinationPath": "s3://backups/foo"},
+				},
+			},
+			expectedErr: ErrEmptyBackupDestination,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postgres := &PostgreSQL{}
+			err := postgres.GetCompleteConfig(tt.devConfig, tt.platformConfig)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedOperator, postgres.Operator)
+		})
+	}
+}
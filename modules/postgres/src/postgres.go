@@ -6,16 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"runtime/debug"
+	"os"
+	"slices"
 	"strings"
 
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"dbutil"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
 	"kusionstack.io/kusion/pkg/workspace"
+	"moduleutil"
 )
 
 const (
@@ -24,16 +25,20 @@ const (
 )
 
 const (
-	dbEngine         = "postgres"
-	dbResSuffix      = "-postgres"
-	dbHostAddressEnv = "KUSION_DB_HOST"
-	dbUsernameEnv    = "KUSION_DB_USERNAME"
-	dbPasswordEnv    = "KUSION_DB_PASSWORD"
+	dbEngine    = "postgres"
+	dbResSuffix = "-postgres"
+	dbPort      = 5432
+
+	// secretChecksumAnnotationPrefix is the prefix of the pod annotation holding the checksum of
+	// the database Secret, which is followed by the name of the Secret.
+	secretChecksumAnnotationPrefix = "checksum.kusionstack.io/"
 )
 
 var (
 	ErrEmptyInstanceTypeForCloudDB = errors.New("empty instance type for cloud managed postgres instance")
 	ErrEmptyCloudProviderType      = errors.New("empty cloud provider type in postgres module config")
+	ErrPublicAccessInProd          = errors.New("cloud managed postgres instance open to the internet in prod")
+	ErrPasswordVersionForLocalDB   = errors.New("postgres passwordVersion is only supported for the cloud managed postgres instance")
 )
 
 var (
@@ -53,13 +58,6 @@ var (
 	defaultSize           int      = 10
 )
 
-var defaultRandomProviderCfg = module.ProviderConfig{
-	Source:  "hashicorp/random",
-	Version: "3.6.0",
-}
-
-var randomPassword = "random_password"
-
 // PostgreSQL describes the attributes to locally deploy or create a cloud provider
 // managed PostgreSQL database instance for the workload.
 type PostgreSQL struct {
@@ -75,6 +73,8 @@ type PostgreSQL struct {
 	Category string `json:"category,omitempty" yaml:"category,omitempty"`
 	// The operation account for the PostgreSQL database.
 	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// The version of the password of the cloud provided PostgreSQL instance, which is bumped to rotate it.
+	PasswordVersion string `json:"passwordVersion,omitempty" yaml:"passwordVersion,omitempty"`
 	// The list of IP addresses allowed to access the PostgreSQL instance provided by the cloud vendor.
 	SecurityIPs []string `json:"securityIPs,omitempty" yaml:"securityIPs,omitempty"`
 	// The virtual subnet ID associated with the VPC that the cloud PostgreSQL instance will be created in.
@@ -84,6 +84,91 @@ type PostgreSQL struct {
 	PrivateRouting bool `json:"privateRouting,omitempty" yaml:"privateRouting,omitempty"`
 	// The specified name of the PostgreSQL database instance.
 	DatabaseName string `json:"databaseName,omitempty" yaml:"databaseName,omitempty"`
+	// The operator managing the local PostgreSQL cluster with high availability.
+	Operator *OperatorConfig `json:"operator,omitempty" yaml:"operator,omitempty"`
+	// The ARN of the IAM or RAM role assumed by the cloud provider, e.g. to provision in another account.
+	AssumeRoleARN string `json:"assumeRoleARN,omitempty" yaml:"assumeRoleARN,omitempty"`
+	// The session name of the assumed IAM role.
+	SessionName string `json:"sessionName,omitempty" yaml:"sessionName,omitempty"`
+	// The named profile in the shared credentials of the AWS provider.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// The custom endpoints of the Alicloud services by the service codes, e.g. rds.
+	Endpoints map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// The STS security token of the temporary credentials of the Alicloud provider.
+	SecurityToken string `json:"securityToken,omitempty" yaml:"securityToken,omitempty"`
+	// The region of the cloud provider resolved from the dev config and platform config.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// The connection limit and timeouts enforced on the application account.
+	Account *AccountConfig `json:"account,omitempty" yaml:"account,omitempty"`
+	// The logical replication for the change data capture.
+	CDC *CDCConfig `json:"cdc,omitempty" yaml:"cdc,omitempty"`
+	// The scheduled pause and resume of the cloud managed instance.
+	Schedule *ScheduleConfig `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+// DevConfig describes the dev config of the postgres module declared by the application.
+type DevConfig struct {
+	// The deployment mode of the PostgreSQL database.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// The PostgreSQL database version to use.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	// The region of the cloud provider, which overrides the one in the platform config.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// The version of the password of the cloud provided PostgreSQL instance, which is bumped to rotate it.
+	PasswordVersion string `json:"passwordVersion,omitempty" yaml:"passwordVersion,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the postgres module in workspace.
+type PlatformConfig struct {
+	// The cloud vendor providing the PostgreSQL instance, aws or alicloud.
+	Cloud string `json:"cloud,omitempty" yaml:"cloud,omitempty"`
+	// The type of the PostgreSQL instance.
+	InstanceType string `json:"instanceType,omitempty" yaml:"instanceType,omitempty"`
+	// The allocated storage size of the PostgreSQL instance.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// The edition of the PostgreSQL instance provided by the cloud vendor.
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	// The operation account for the PostgreSQL database.
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// The list of IP addresses allowed to access the PostgreSQL instance provided by the cloud vendor.
+	SecurityIPs []string `json:"securityIPs,omitempty" yaml:"securityIPs,omitempty"`
+	// The virtual subnet ID associated with the VPC that the cloud PostgreSQL instance will be created in.
+	SubnetID string `json:"subnetID,omitempty" yaml:"subnetID,omitempty"`
+	// Whether the host address of the cloud PostgreSQL instance is via private network.
+	PrivateRouting bool `json:"privateRouting,omitempty" yaml:"privateRouting,omitempty"`
+	// The specified name of the PostgreSQL database instance.
+	DatabaseName string `json:"databaseName,omitempty" yaml:"databaseName,omitempty"`
+	// The operator managing the local PostgreSQL cluster with high availability.
+	Operator *OperatorConfig `json:"operator,omitempty" yaml:"operator,omitempty"`
+	// The ARN of the IAM or RAM role assumed by the cloud provider, e.g. to provision in another account.
+	AssumeRoleARN string `json:"assumeRoleARN,omitempty" yaml:"assumeRoleARN,omitempty"`
+	// The session name of the assumed IAM role.
+	SessionName string `json:"sessionName,omitempty" yaml:"sessionName,omitempty"`
+	// The named profile in the shared credentials of the AWS provider.
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+	// The custom endpoints of the Alicloud services by the service codes, e.g. rds.
+	Endpoints map[string]string `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// The STS security token of the temporary credentials of the Alicloud provider.
+	SecurityToken string `json:"securityToken,omitempty" yaml:"securityToken,omitempty"`
+	// The connection limit and timeouts enforced on the application account.
+	Account *AccountConfig `json:"account,omitempty" yaml:"account,omitempty"`
+	// The logical replication for the change data capture.
+	CDC *CDCConfig `json:"cdc,omitempty" yaml:"cdc,omitempty"`
+	// The scheduled pause and resume of the cloud managed instance in the non-prod workspaces.
+	Schedule *ScheduleConfig `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *DevConfig `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+	// The environment class of the workspace, dev, staging or prod, which the guardrails are keyed on.
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// The hints of the generated Terraform resources, e.g. the provider aliases.
+	Terraform *moduleutil.TerraformHints `json:"terraform,omitempty" yaml:"terraform,omitempty"`
+	// The topology of the workspace, e.g. the region of the cloud provider.
+	Topology *dbutil.Topology `json:"topology,omitempty" yaml:"topology,omitempty"`
 }
 
 func (postgres *PostgreSQL) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
@@ -91,13 +176,27 @@ func (postgres *PostgreSQL) Generate(ctx context.Context, request *module.Genera
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "postgres", &response, &err)
+
+	// Attach the connection info of the database, hint the Terraform resources with the provider
+	// aliases and state groups, and finalize the generated resources with the standard metadata,
+	// the policy and Pod Security Standards checks, and the preview summary if enabled in the
+	// workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate postgres module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in postgres generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			name := postgres.DatabaseName + "-connection-info"
+			if err = moduleutil.AttachConnectionInfo("postgres", name, request, response, moduleutil.DatabaseConnectionInfo(response)); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.ApplyTerraformHints(request, response); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("postgres", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -111,12 +210,17 @@ func (postgres *PostgreSQL) Generate(ctx context.Context, request *module.Genera
 	// Get the complete configs of the PostgreSQL instance.
 	err = postgres.GetCompleteConfig(request.DevConfig, request.PlatformConfig)
 	if err != nil {
-		return nil, err
+		return nil, moduleutil.NewModuleError("postgres", moduleutil.PhaseComplete, err)
+	}
+
+	// Refuse the configs not allowed in the environment class of the workspace.
+	if err = postgres.CheckGuardrails(request); err != nil {
+		return nil, moduleutil.NewModuleError("postgres", moduleutil.PhaseValidate, err)
 	}
 
 	// Set the database name.
 	if postgres.DatabaseName == "" {
-		postgres.DatabaseName = GenerateDefaultPostgreSQLName(request.Project, request.Stack, request.App)
+		postgres.DatabaseName = GenerateDefaultPostgreSQLName(request, postgres.Type)
 	}
 
 	// Generate the PostgreSQL intance resources based on the type and the cloud provider config.
@@ -125,7 +229,14 @@ func (postgres *PostgreSQL) Generate(ctx context.Context, request *module.Genera
 	var providerType string
 	switch strings.ToLower(postgres.Type) {
 	case LocalDBType:
-		resources, patcher, err = postgres.GenerateLocalResources(request)
+		if postgres.Operator != nil {
+			resources, patcher, err = postgres.GenerateOperatorResources(request)
+		} else {
+			resources, patcher, err = postgres.GenerateLocalResources(request)
+		}
+		if err != nil {
+			return nil, err
+		}
 	case CloudDBType:
 		providerType, err = GetCloudProviderType(request.PlatformConfig)
 		if err != nil {
@@ -150,6 +261,23 @@ func (postgres *PostgreSQL) Generate(ctx context.Context, request *module.Genera
 		return nil, fmt.Errorf("unsupported postgres type: %s", postgres.Type)
 	}
 
+	// Build the Secret of the replication account and the Debezium connector config for cdc.
+	cdcResources, err := postgres.GenerateCDCResources(request, resources)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, cdcResources...)
+
+	// Build Kubernetes Job applying the settings of the application account and creating the
+	// replication account after the database Secrets are created.
+	accountJob, err := postgres.GenerateAccountJob(request, providerType, resources)
+	if err != nil {
+		return nil, err
+	}
+	if accountJob != nil {
+		resources = append(resources, *accountJob)
+	}
+
 	return &module.GeneratorResponse{
 		Resources: resources,
 		Patcher:   patcher,
@@ -159,66 +287,48 @@ func (postgres *PostgreSQL) Generate(ctx context.Context, request *module.Genera
 // GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
 // configuration for the PostgreSQL instance.
 func (postgres *PostgreSQL) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
-	// Set the default values for PostgreSQL instance if platformConfig not exists.
-	if platformConfig == nil {
-		postgres.Username = defaultUsername
-		postgres.Category = defaultCategory
-		postgres.SecurityIPs = defaultSecurityIPs
-		postgres.PrivateRouting = defaultPrivateRouting
-		postgres.Size = defaultSize
-	}
-
-	// Get the type and version of the PostgreSQL instance in devConfig.
-	if postgresType, ok := devConfig["type"]; ok {
-		postgres.Type = postgresType.(string)
-	}
-	if postgresVersion, ok := devConfig["version"]; ok {
-		postgres.Version = postgresVersion.(string)
-	}
-
-	// Get the other configs of the PostgreSQL instance in platformConfig,
-	// and use the default values if some of them don't exist.
-	if username, ok := platformConfig["username"]; ok {
-		postgres.Username = username.(string)
-	} else {
-		postgres.Username = defaultUsername
-	}
-
-	if category, ok := platformConfig["category"]; ok {
-		postgres.Category = category.(string)
-	} else {
-		postgres.Category = defaultCategory
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, DevConfig{}); err != nil {
+		return moduleutil.NewModuleError("postgres", moduleutil.PhaseValidate, fmt.Errorf("validate PostgreSQL dev config failed, %w", err))
 	}
-
-	if securityIPs, ok := platformConfig["securityIPs"]; ok {
-		postgres.SecurityIPs = securityIPs.([]string)
-	} else {
-		postgres.SecurityIPs = defaultSecurityIPs
-	}
-
-	if privateRouting, ok := platformConfig["privateRouting"]; ok {
-		postgres.PrivateRouting = privateRouting.(bool)
-	} else {
-		postgres.PrivateRouting = defaultPrivateRouting
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("postgres", moduleutil.PhaseValidate, fmt.Errorf("validate PostgreSQL platform config failed, %w", err))
 	}
 
-	if size, ok := platformConfig["size"]; ok {
-		postgres.Size = size.(int)
-	} else {
-		postgres.Size = defaultSize
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
 	}
 
-	if instanceType, ok := platformConfig["instanceType"]; ok {
-		postgres.InstanceType = instanceType.(string)
+	// Decode the platform config over the built-in defaults of the PostgreSQL instance, and then
+	// the dev config over the platform config.
+	postgres.Username = defaultUsername
+	postgres.Category = defaultCategory
+	postgres.SecurityIPs = slices.Clone(defaultSecurityIPs)
+	postgres.PrivateRouting = defaultPrivateRouting
+	postgres.Size = defaultSize
+	for _, config := range []map[string]interface{}{platformConfig, devConfig} {
+		out, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, postgres); err != nil {
+			return fmt.Errorf("parse postgres config failed, %w", err)
+		}
 	}
-
-	if subnetID, ok := platformConfig["subnetID"]; ok {
-		postgres.SubnetID = subnetID.(string)
+	completeOperatorConfig(postgres.Operator)
+	completeCDCConfig(postgres.CDC)
+	completeScheduleConfig(postgres.Schedule)
+	if postgres.Endpoints != nil {
+		if postgres.Endpoints, err = dbutil.ParseEndpoints(postgres.Endpoints); err != nil {
+			return err
+		}
 	}
 
-	if databaseName, ok := platformConfig["databaseName"]; ok {
-		postgres.DatabaseName = databaseName.(string)
-	}
+	// Resolve the region of the cloud provider, which falls back to the environment variables
+	// of the cloud provider if empty.
+	postgres.Region = dbutil.ResolveRegion(devConfig, platformConfig)
 
 	return postgres.Validate()
 }
@@ -228,77 +338,27 @@ func (postgres *PostgreSQL) GetCompleteConfig(devConfig kusionapiv1.Accessory, p
 func (postgres *PostgreSQL) GenerateDBSecret(request *module.GeneratorRequest, hostAddress, username, password string) (
 	*kusionapiv1.Resource, *kusionapiv1.Patcher, error,
 ) {
-	// Create the data map of Kubernetes Secret storing the database host address, username
-	// and password.
-	data := make(map[string]string)
-	data["hostAddress"] = hostAddress
-	data["username"] = username
-	data["password"] = password
-
-	// Create the Kubernetes Secret.
-	secret := &v1.Secret{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Secret",
-			APIVersion: v1.SchemeGroupVersion.String(),
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      postgres.DatabaseName + dbResSuffix,
-			Namespace: request.Project,
-		},
-		StringData: data,
-	}
-
-	resourceID := module.KubernetesResourceID(secret.TypeMeta, secret.ObjectMeta)
-	resource, err := module.WrapK8sResourceToKusionResource(resourceID, secret)
+	// Create the Kubernetes Secret storing the database host address, port, username and password,
+	// which are also published as the outputs of the module, and inject the credentials into the
+	// workload as the environment variables with Kusion resource patcher. The pod template of the
+	// workload is annotated with the checksum of the Secret, so that the pods are restarted to pick
+	// up the new credentials once the Secret is regenerated.
+	name := moduleutil.OutputSecretName(request, dbEngine)
+	resource, patcher, err := dbutil.DBSecret{
+		Name:               name,
+		Namespace:          request.Project,
+		DatabaseName:       postgres.DatabaseName,
+		HostAddress:        hostAddress,
+		Port:               dbPort,
+		Username:           username,
+		Password:           password,
+		PasswordVersion:    postgres.PasswordVersion,
+		ChecksumAnnotation: secretChecksumAnnotationPrefix + moduleutil.SanitizeName(name, moduleutil.KubernetesNamingRule),
+	}.Generate()
 	if err != nil {
 		return nil, nil, err
 	}
-
-	// Inject the database credentials into the workload as the environment variables with
-	// Kusion resource patcher.
-	hostAddressKey := dbHostAddressEnv + "_" + strings.ToUpper(strings.ReplaceAll(postgres.DatabaseName, "-", "_"))
-	usernameKey := dbUsernameEnv + "_" + strings.ToUpper(strings.ReplaceAll(postgres.DatabaseName, "-", "_"))
-	passwordKey := dbPasswordEnv + "_" + strings.ToUpper(strings.ReplaceAll(postgres.DatabaseName, "-", "_"))
-
-	envVars := []v1.EnvVar{
-		{
-			Name: hostAddressKey,
-			ValueFrom: &v1.EnvVarSource{
-				SecretKeyRef: &v1.SecretKeySelector{
-					LocalObjectReference: v1.LocalObjectReference{
-						Name: secret.Name,
-					},
-					Key: "hostAddress",
-				},
-			},
-		},
-		{
-			Name: usernameKey,
-			ValueFrom: &v1.EnvVarSource{
-				SecretKeyRef: &v1.SecretKeySelector{
-					LocalObjectReference: v1.LocalObjectReference{
-						Name: secret.Name,
-					},
-					Key: "username",
-				},
-			},
-		},
-		{
-			Name: passwordKey,
-			ValueFrom: &v1.EnvVarSource{
-				SecretKeyRef: &v1.SecretKeySelector{
-					LocalObjectReference: v1.LocalObjectReference{
-						Name: secret.Name,
-					},
-					Key: "password",
-				},
-			},
-		},
-	}
-
-	patcher := &kusionapiv1.Patcher{
-		Environments: envVars,
-	}
+	moduleutil.PublishOutputs("postgres", resource)
 
 	return resource, patcher, nil
 }
@@ -306,42 +366,55 @@ func (postgres *PostgreSQL) GenerateDBSecret(request *module.GeneratorRequest, h
 // GenerateTFRandomPassword generates the terraform random_password resource as the password
 // of the cloud provided PostgreSQL database instance.
 func (postgres *PostgreSQL) GenerateTFRandomPassword(request *module.GeneratorRequest) (*kusionapiv1.Resource, string, error) {
-	resAttrs := map[string]any{
-		"length":           16,
-		"special":          true,
-		"override_special": "_",
+	return dbutil.RandomPassword(postgres.DatabaseName+dbResSuffix, postgres.PasswordVersion)
+}
+
+// Validate validates whether the input of a PostgreSQL database instance is valid.
+func (postgres *PostgreSQL) Validate() error {
+	if postgres.Type == CloudDBType && postgres.InstanceType == "" {
+		return ErrEmptyInstanceTypeForCloudDB
 	}
 
-	// Set the random_password provider with the default provider config.
-	randomPasswordProvider := defaultRandomProviderCfg
+	// The password of the local instance is only applied on the initialization of its data, so it
+	// can't be rotated by the password version.
+	if postgres.PasswordVersion != "" && strings.ToLower(postgres.Type) != CloudDBType {
+		return ErrPasswordVersionForLocalDB
+	}
 
-	id, err := module.TerraformResourceID(randomPasswordProvider, randomPassword, postgres.DatabaseName+dbResSuffix)
-	if err != nil {
-		return nil, "", err
+	if err := postgres.validateOperatorConfig(); err != nil {
+		return err
 	}
 
-	resource, err := module.WrapTFResourceToKusionResource(randomPasswordProvider, randomPassword, id, resAttrs, nil)
-	if err != nil {
-		return nil, "", err
+	if err := postgres.validateAccountConfig(); err != nil {
+		return err
 	}
 
-	return resource, id, nil
-}
+	if err := postgres.validateScheduleConfig(); err != nil {
+		return err
+	}
 
-// Validate validates whether the input of a PostgreSQL database instance is valid.
-func (postgres *PostgreSQL) Validate() error {
-	if postgres.Type == CloudDBType && postgres.InstanceType == "" {
-		return ErrEmptyInstanceTypeForCloudDB
+	if err := postgres.providerOverride().Validate(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// GenerateDefaultPostgreSQLName generates the default name of the PostgreSQL instance.
-func GenerateDefaultPostgreSQLName(projectName, stackName, appName string) string {
-	strs := []string{projectName, stackName, appName, dbEngine}
+// GenerateDefaultPostgreSQLName generates the default name of the PostgreSQL instance by the naming
+// template in the workspace context, following the naming rule of the cloud provider if any.
+func GenerateDefaultPostgreSQLName(request *module.GeneratorRequest, dbType string) string {
+	rule := moduleutil.KubernetesNamingRule
+	if strings.ToLower(dbType) == CloudDBType {
+		providerType, _ := GetCloudProviderType(request.PlatformConfig)
+		switch strings.ToLower(providerType) {
+		case "aws":
+			rule = moduleutil.AWSNamingRule
+		case "alicloud":
+			rule = moduleutil.AlicloudNamingRule
+		}
+	}
 
-	return strings.Join(strs, "-")
+	return moduleutil.ResourceName(request, dbEngine, rule)
 }
 
 // GetCloudProviderType returns the cloud provider type of the PostgreSQL instance.
@@ -357,6 +430,44 @@ func GetCloudProviderType(platformConfig kusionapiv1.GenericConfig) (string, err
 	return "", ErrEmptyCloudProviderType
 }
 
+// CheckGuardrails checks the PostgreSQL instance against the guardrails of the environment class
+// of the workspace, where the cloud managed instances in prod must not be open to the internet,
+// nor paused on schedule.
+func (postgres *PostgreSQL) CheckGuardrails(request *module.GeneratorRequest) error {
+	env, err := moduleutil.EnvironmentClass(request)
+	if err != nil {
+		return err
+	}
+
+	if err = postgres.checkScheduleGuardrails(env); err != nil {
+		return err
+	}
+
+	if env == moduleutil.EnvironmentProd && strings.ToLower(postgres.Type) == CloudDBType {
+		for _, ip := range postgres.SecurityIPs {
+			if IsOpenToInternet(ip) {
+				return fmt.Errorf("%w, %w", ErrPublicAccessInProd, &moduleutil.ConfigFieldError{
+					Path:   "securityIPs",
+					Reason: ip + " is not allowed in prod",
+				})
+			}
+		}
+	}
+
+	return nil
+}
+
+// IsOpenToInternet returns whether the CIDR record allows all the IP addresses, e.g. 0.0.0.0/0.
+func IsOpenToInternet(cidrStr string) bool {
+	_, ipNet, err := net.ParseCIDR(cidrStr)
+	if err != nil {
+		return false
+	}
+	ones, _ := ipNet.Mask.Size()
+
+	return ones == 0
+}
+
 // IsPublicAccessible returns whether the postgres database instance is publicly
 // accessible according to the securityIPs.
 func IsPublicAccessible(securityIPs []string) bool {
@@ -391,5 +502,12 @@ func IsCIDR(cidrStr string) bool {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, DevConfig{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&PostgreSQL{})
 }
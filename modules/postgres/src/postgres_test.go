@@ -4,14 +4,18 @@ import (
 	"context"
 	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"dbutil"
 	"github.com/bytedance/mockey"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+	"testutil"
 )
 
 func TestPostgreSQLModule_Generator(t *testing.T) {
@@ -187,6 +191,25 @@ func TestPostgreSQLModule_GetCompleteConfig(t *testing.T) {
 				DatabaseName:   "test-database",
 			},
 		},
+		{
+			name:            "Defaults in platform config",
+			devModuleConfig: kusionapiv1.Accessory{"version": "16.0"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"defaults":     map[string]interface{}{"type": "cloud", "version": "14.0"},
+				"securityIPs":  []interface{}{"10.0.0.0/8"},
+				"instanceType": "test-instance-type",
+			},
+			expectedPostgreSQL: &PostgreSQL{
+				Type:           "cloud",
+				Version:        "16.0",
+				Username:       defaultUsername,
+				Category:       defaultCategory,
+				SecurityIPs:    []string{"10.0.0.0/8"},
+				PrivateRouting: defaultPrivateRouting,
+				Size:           defaultSize,
+				InstanceType:   "test-instance-type",
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -229,13 +252,14 @@ func TestPostgreSQLModule_GenerateDBSecret(t *testing.T) {
 			APIVersion: v1.SchemeGroupVersion.String(),
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test-database-postgres",
+			Name:      "test-project-test-stack-test-app-postgres-postgres",
 			Namespace: "test-project",
 		},
 		StringData: map[string]string{
 			"hostAddress": "test-host-address",
 			"username":    "test-username",
 			"password":    "test-password",
+			"port":        "5432",
 		},
 	}
 
@@ -244,6 +268,13 @@ func TestPostgreSQLModule_GenerateDBSecret(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to wrap secret resource for unit test: %v", err)
 	}
+	expectedResource.Extensions[moduleutil.OutputsExtensionKey] = map[string]interface{}{
+		"host":       "hostAddress",
+		"port":       "port",
+		"username":   "username",
+		"password":   "password",
+		"secretName": "",
+	}
 
 	expectedPatcher := &kusionapiv1.Patcher{
 		Environments: []v1.EnvVar{
@@ -252,7 +283,7 @@ func TestPostgreSQLModule_GenerateDBSecret(t *testing.T) {
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: "test-database-postgres",
+							Name: "test-project-test-stack-test-app-postgres-postgres",
 						},
 						Key: "hostAddress",
 					},
@@ -263,7 +294,7 @@ func TestPostgreSQLModule_GenerateDBSecret(t *testing.T) {
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: "test-database-postgres",
+							Name: "test-project-test-stack-test-app-postgres-postgres",
 						},
 						Key: "username",
 					},
@@ -274,7 +305,7 @@ func TestPostgreSQLModule_GenerateDBSecret(t *testing.T) {
 				ValueFrom: &v1.EnvVarSource{
 					SecretKeyRef: &v1.SecretKeySelector{
 						LocalObjectReference: v1.LocalObjectReference{
-							Name: "test-database-postgres",
+							Name: "test-project-test-stack-test-app-postgres-postgres",
 						},
 						Key: "password",
 					},
@@ -283,11 +314,24 @@ func TestPostgreSQLModule_GenerateDBSecret(t *testing.T) {
 		},
 	}
 
+	checksum, err := dbutil.SecretChecksum(sec, "")
+	assert.Nil(t, err)
+	expectedPatcher.PodAnnotations = map[string]string{
+		"checksum.kusionstack.io/test-project-test-stack-test-app-postgres-postgres": checksum,
+	}
+
 	actualResource, actualPatchers, err := postgres.GenerateDBSecret(r, hostAddress, username, password)
 
 	assert.Nil(t, err)
 	assert.Equal(t, expectedPatcher, actualPatchers)
 	assert.Equal(t, expectedResource, actualResource)
+	// The Secret overriding the database name is still the one the output references resolve to.
+	assert.Equal(t, moduleutil.OutputSecretID(r, dbEngine), actualResource.ID)
+
+	// The checksum changes with the credentials, which restarts the pods of the workload.
+	_, rotatedPatchers, err := postgres.GenerateDBSecret(r, hostAddress, username, "rotated-password")
+	assert.Nil(t, err)
+	assert.NotEqual(t, checksum, rotatedPatchers.PodAnnotations["checksum.kusionstack.io/test-project-test-stack-test-app-postgres-postgres"])
 }
 
 func TestPostgreSQLModule_GenerateTFRandomPassword(t *testing.T) {
@@ -355,6 +399,34 @@ func TestPostgreSQLModule_GenerateTFRandomPassword(t *testing.T) {
 	})
 }
 
+func TestPostgreSQLModule_GetCompleteConfigInvalid(t *testing.T) {
+	t.Run("unknown field in dev config", func(t *testing.T) {
+		postgres := &PostgreSQL{}
+		err := postgres.GetCompleteConfig(kusionapiv1.Accessory{
+			"type":    "cloud",
+			"verison": "14.0",
+		}, nil)
+
+		assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "verison: unknown field")
+	})
+
+	t.Run("mismatched type in platform config", func(t *testing.T) {
+		postgres := &PostgreSQL{}
+		err := postgres.GetCompleteConfig(kusionapiv1.Accessory{
+			"type":    "cloud",
+			"version": "14.0",
+		}, kusionapiv1.GenericConfig{
+			"size":     "20",
+			"defaults": map[string]interface{}{"verison": "14.0"},
+		})
+
+		assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "size: expected integer, got string")
+		assert.ErrorContains(t, err, "defaults.verison: unknown field")
+	})
+}
+
 func TestPostgreSQLModule_Validate(t *testing.T) {
 	t.Run("cloud db with empty instanceType", func(t *testing.T) {
 		postgres := &PostgreSQL{
@@ -378,6 +450,72 @@ func TestPostgreSQLModule_Validate(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("local db with passwordVersion", func(t *testing.T) {
+		postgres := &PostgreSQL{
+			Type:            "local",
+			Version:         "14.0",
+			PasswordVersion: "2",
+		}
+
+		assert.ErrorIs(t, postgres.Validate(), ErrPasswordVersionForLocalDB)
+	})
+
+	t.Run("session name without assumeRoleARN", func(t *testing.T) {
+		postgres := &PostgreSQL{
+			Type:         "cloud",
+			InstanceType: "test-instance-type",
+			SessionName:  "kusion",
+		}
+
+		assert.ErrorIs(t, postgres.Validate(), ErrEmptyAssumeRoleARN)
+	})
+
+	t.Run("invalid assumeRoleARN", func(t *testing.T) {
+		postgres := &PostgreSQL{
+			Type:          "cloud",
+			InstanceType:  "test-instance-type",
+			AssumeRoleARN: "kusion",
+		}
+
+		assert.ErrorIs(t, postgres.Validate(), ErrInvalidAssumeRoleARN)
+	})
+}
+
+func TestPostgreSQLModule_CheckGuardrails(t *testing.T) {
+	prod := &module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{moduleutil.EnvironmentKey: "prod"},
+	}
+
+	t.Run("cloud db open to the internet in prod", func(t *testing.T) {
+		postgres := &PostgreSQL{Type: "cloud", SecurityIPs: []string{"10.0.0.0/8", "0.0.0.0/0"}}
+
+		err := postgres.CheckGuardrails(prod)
+
+		assert.ErrorIs(t, err, ErrPublicAccessInProd)
+		var fieldErr *moduleutil.ConfigFieldError
+		if assert.ErrorAs(t, err, &fieldErr) {
+			assert.Equal(t, "securityIPs", fieldErr.Path)
+		}
+	})
+
+	t.Run("cloud db open to the internet in dev", func(t *testing.T) {
+		postgres := &PostgreSQL{Type: "cloud", SecurityIPs: []string{"0.0.0.0/0"}}
+
+		assert.NoError(t, postgres.CheckGuardrails(&module.GeneratorRequest{}))
+	})
+
+	t.Run("cloud db with restricted securityIPs in prod", func(t *testing.T) {
+		postgres := &PostgreSQL{Type: "cloud", SecurityIPs: []string{"10.0.0.0/8", "203.0.113.10"}}
+
+		assert.NoError(t, postgres.CheckGuardrails(prod))
+	})
+
+	t.Run("local db in prod", func(t *testing.T) {
+		postgres := &PostgreSQL{Type: "local", SecurityIPs: []string{"0.0.0.0/0"}}
+
+		assert.NoError(t, postgres.CheckGuardrails(prod))
+	})
 }
 
 func TestIsPublicAccessible(t *testing.T) {
@@ -415,3 +553,180 @@ func TestIsPublicAccessible(t *testing.T) {
 		assert.Equal(t, tc.expected, actual)
 	}
 }
+
+func TestPostgreSQLModule_GenerateGolden(t *testing.T) {
+	tests := []struct {
+		name           string
+		platformConfig kusionapiv1.GenericConfig
+	}{
+		{
+			name: "local",
+		},
+		{
+			name: "local-database-name",
+			platformConfig: kusionapiv1.GenericConfig{
+				"databaseName": "foo-db",
+				"username":     "foo",
+			},
+		},
+		{
+			name: "local-operator",
+			platformConfig: kusionapiv1.GenericConfig{
+				"operator": map[string]interface{}{
+					"type":         "cloudnative-pg",
+					"storageClass": "standard",
+					"backup": map[string]interface{}{
+						"destinationPath":   "s3://backups/foo",
+						"credentialsSecret": "backup-credentials",
+						"retentionPolicy":   "30d",
+						"schedule":          "0 0 0 * * *",
+					},
+				},
+			},
+		},
+		{
+			name: "local-account",
+			platformConfig: kusionapiv1.GenericConfig{
+				"account": map[string]interface{}{
+					"connectionLimit":          20,
+					"statementTimeout":         "30s",
+					"idleInTransactionTimeout": "1m",
+				},
+			},
+		},
+		{
+			name: "local-cdc",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cdc": map[string]interface{}{"debezium": true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(kusionapiv1.Accessory{"type": LocalDBType, "version": "14.0"}).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&PostgreSQL{}).Generate(context.Background(), request)
+			if !assert.NoError(t, err) {
+				return
+			}
+			testutil.AssertGolden(t, filepath.Join("testdata", tt.name+".golden.json"), response)
+		})
+	}
+}
+
+func TestPostgreSQLModule_GenerateCloudRegion(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		env            map[string]string
+		expectedIDs    []string
+		expectedRegion string
+		expectedErr    error
+	}{
+		{
+			name: "aws region from env",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":        "aws",
+				"instanceType": "db.t3.micro",
+			},
+			env: map[string]string{awsRegionEnv: "us-east-1"},
+			expectedIDs: []string{
+				"hashicorp:random:random_password:default-dev-foo-postgres-postgres",
+				"hashicorp:aws:aws_security_group:default-dev-foo-postgres-postgres",
+				"hashicorp:aws:aws_db_instance:default-dev-foo-postgres",
+				"v1:Secret:default:default-dev-foo-postgres-postgres",
+			},
+		},
+		{
+			name: "alicloud region from env",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":        "alicloud",
+				"instanceType": "postgres.n2.serverless.1c",
+				"category":     "serverless_basic",
+				"subnetID":     "test-subnet-id",
+			},
+			env: map[string]string{alicloudRegionEnv: "cn-beijing"},
+			expectedIDs: []string{
+				"hashicorp:random:random_password:default-dev-foo-postgres-postgres",
+				"hashicorp:alicloud:alicloud_db_instance:default-dev-foo-postgres",
+				"hashicorp:alicloud:alicloud_db_connection:default-dev-foo-postgres",
+				"hashicorp:alicloud:alicloud_rds_account:default-dev-foo-postgres",
+				"v1:Secret:default:default-dev-foo-postgres-postgres",
+			},
+		},
+		{
+			name: "aws region from topology over env",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":            "aws",
+				"instanceType":     "db.t3.micro",
+				dbutil.TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			env:            map[string]string{awsRegionEnv: "us-east-1"},
+			expectedRegion: "us-west-2",
+		},
+		{
+			name:      "aws region from dev config over topology",
+			devConfig: kusionapiv1.Accessory{"region": "eu-west-1"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":            "aws",
+				"instanceType":     "db.t3.micro",
+				dbutil.TopologyKey: map[string]interface{}{"region": "us-west-2"},
+			},
+			expectedRegion: "eu-west-1",
+		},
+		{
+			name: "empty aws region",
+			platformConfig: kusionapiv1.GenericConfig{
+				"cloud":        "aws",
+				"instanceType": "db.t3.micro",
+			},
+			expectedErr: ErrEmptyAWSProviderRegion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Shield the test from the cloud credentials and regions of the host.
+			testutil.IsolateEnv(t, "AWS_", "ALICLOUD_")
+			testutil.SetEnv(t, tt.env)
+
+			devConfig := kusionapiv1.Accessory{"type": CloudDBType, "version": "14.0"}
+			for k, v := range tt.devConfig {
+				devConfig[k] = v
+			}
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&PostgreSQL{}).Generate(context.Background(), request)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.expectedRegion != "" {
+				for _, res := range response.Resources {
+					if moduleutil.ResourceKind(res) == awsDBInstance {
+						assert.Equal(t, tt.expectedRegion, res.Extensions["providerMeta"].(map[string]any)["region"])
+					}
+				}
+				return
+			}
+			var ids []string
+			for _, res := range response.Resources {
+				ids = append(ids, res.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	awsIAMRole           = "aws_iam_role"
+	awsIAMRolePolicy     = "aws_iam_role_policy"
+	awsSchedulerSchedule = "aws_scheduler_schedule"
+
+	scheduleSuffix        = "-schedule"
+	defaultScheduleZone   = "UTC"
+	awsSchedulerService   = "scheduler.amazonaws.com"
+	awsStopDBInstanceARN  = "arn:aws:scheduler:::aws-sdk:rds:stopDBInstance"
+	awsStartDBInstanceARN = "arn:aws:scheduler:::aws-sdk:rds:startDBInstance"
+)
+
+var (
+	ErrEmptySchedule       = errors.New("empty stop and start of postgres schedule")
+	ErrScheduleForLocalDB  = errors.New("postgres schedule is only supported for the cloud managed postgres instance")
+	ErrScheduleUnsupported = errors.New("postgres schedule is only supported for the serverless category of alicloud")
+	ErrScheduleInProd      = errors.New("scheduled pause of postgres instance in prod")
+)
+
+// ScheduleConfig describes the scheduled pause and resume of the cloud managed PostgreSQL instance
+// to save the cost of the non-prod workspaces. The AWS RDS instances are stopped and started by
+// the EventBridge Scheduler, and the Alicloud serverless instances are paused automatically once
+// idle.
+type ScheduleConfig struct {
+	// The schedule expression of stopping the AWS RDS instance, e.g. cron(0 20 ? * MON-FRI *).
+	Stop string `json:"stop,omitempty" yaml:"stop,omitempty"`
+	// The schedule expression of starting the AWS RDS instance, e.g. cron(0 8 ? * MON-FRI *).
+	Start string `json:"start,omitempty" yaml:"start,omitempty"`
+	// The timezone of the schedule expressions, which defaults to UTC.
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+}
+
+// completeScheduleConfig fills the unset fields of the schedule config with the defaults.
+func completeScheduleConfig(schedule *ScheduleConfig) {
+	if schedule == nil {
+		return
+	}
+	if schedule.Timezone == "" {
+		schedule.Timezone = defaultScheduleZone
+	}
+}
+
+// validateScheduleConfig validates the schedule config of the PostgreSQL instance.
+func (postgres *PostgreSQL) validateScheduleConfig() error {
+	if postgres.Schedule == nil {
+		return nil
+	}
+	if strings.ToLower(postgres.Type) != CloudDBType {
+		return ErrScheduleForLocalDB
+	}
+	return nil
+}
+
+// checkScheduleGuardrails refuses the scheduled pause of the instances in prod.
+func (postgres *PostgreSQL) checkScheduleGuardrails(env moduleutil.Environment) error {
+	if postgres.Schedule != nil && env == moduleutil.EnvironmentProd {
+		return fmt.Errorf("%w, %w", ErrScheduleInProd, &moduleutil.ConfigFieldError{
+			Path:   "schedule",
+			Reason: "the instances in prod must not be paused",
+		})
+	}
+	return nil
+}
+
+// generateAWSSchedules generates the IAM role assumed by the EventBridge Scheduler, and the
+// schedules stopping and starting the AWS RDS instance.
+func (postgres *PostgreSQL) generateAWSSchedules(awsProviderCfg module.ProviderConfig, region, dbInstanceID string) ([]kusionapiv1.Resource, error) {
+	schedule := postgres.Schedule
+	if schedule.Stop == "" && schedule.Start == "" {
+		return nil, ErrEmptySchedule
+	}
+	awsProviderCfg.ProviderMeta = postgres.awsProviderMeta(region)
+	name := postgres.DatabaseName + scheduleSuffix
+
+	var resources []kusionapiv1.Resource
+	assumeRolePolicy, err := json.Marshal(map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{{
+			"Effect":    "Allow",
+			"Principal": map[string]interface{}{"Service": awsSchedulerService},
+			"Action":    "sts:AssumeRole",
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	roleID, err := module.TerraformResourceID(awsProviderCfg, awsIAMRole, name)
+	if err != nil {
+		return nil, err
+	}
+	role, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsIAMRole, roleID, map[string]interface{}{
+		"name":               name,
+		"assume_role_policy": string(assumeRolePolicy),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, *role)
+
+	rolePolicy, err := json.Marshal(map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []map[string]interface{}{{
+			"Effect":   "Allow",
+			"Action":   []string{"rds:StopDBInstance", "rds:StartDBInstance"},
+			"Resource": module.KusionPathDependency(dbInstanceID, "arn"),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	policyID, err := module.TerraformResourceID(awsProviderCfg, awsIAMRolePolicy, name)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsIAMRolePolicy, policyID, map[string]interface{}{
+		"name":   name,
+		"role":   module.KusionPathDependency(roleID, "id"),
+		"policy": string(rolePolicy),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, *policy)
+
+	input, err := json.Marshal(map[string]string{"DbInstanceIdentifier": postgres.DatabaseName})
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range []struct{ suffix, expression, arn string }{
+		{"-stop", schedule.Stop, awsStopDBInstanceARN},
+		{"-start", schedule.Start, awsStartDBInstanceARN},
+	} {
+		if action.expression == "" {
+			continue
+		}
+		id, err := module.TerraformResourceID(awsProviderCfg, awsSchedulerSchedule, postgres.DatabaseName+action.suffix)
+		if err != nil {
+			return nil, err
+		}
+		res, err := module.WrapTFResourceToKusionResource(awsProviderCfg, awsSchedulerSchedule, id, map[string]interface{}{
+			"name":                         postgres.DatabaseName + action.suffix,
+			"schedule_expression":          action.expression,
+			"schedule_expression_timezone": schedule.Timezone,
+			"flexible_time_window": []map[string]interface{}{
+				{"mode": "OFF"},
+			},
+			"target": []map[string]interface{}{
+				{
+					"arn":      action.arn,
+					"role_arn": module.KusionPathDependency(roleID, "arn"),
+					"input":    string(input),
+				},
+			},
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		res.DependsOn = []string{dbInstanceID, policyID}
+		resources = append(resources, *res)
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestPostgreSQLModule_GetCompleteConfigSchedule(t *testing.T) {
+	tests := []struct {
+		name             string
+		devConfig        kusionapiv1.Accessory
+		expectedSchedule *ScheduleConfig
+		expectedErr      error
+	}{
+		{
+			name:      "cloud instance",
+			devConfig: kusionapiv1.Accessory{"type": "cloud", "version": "16.4"},
+			expectedSchedule: &ScheduleConfig{
+				Stop:     "cron(0 20 ? * MON-FRI *)",
+				Timezone: defaultScheduleZone,
+			},
+		},
+		{
+			name:        "local instance",
+			devConfig:   kusionapiv1.Accessory{"type": "local", "version": "16.4"},
+			expectedErr: ErrScheduleForLocalDB,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postgres := &PostgreSQL{}
+			err := postgres.GetCompleteConfig(tt.devConfig, kusionapiv1.GenericConfig{
+				"cloud":        "aws",
+				"instanceType": "db.t3.micro",
+				"schedule":     map[string]interface{}{"stop": "cron(0 20 ? * MON-FRI *)"},
+			})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedSchedule, postgres.Schedule)
+		})
+	}
+}
+
+func TestPostgreSQLModule_CheckScheduleGuardrails(t *testing.T) {
+	postgres := &PostgreSQL{Type: CloudDBType, Schedule: &ScheduleConfig{Stop: "cron(0 20 * * ? *)"}}
+
+	err := postgres.CheckGuardrails(&module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{"environment": "prod"},
+	})
+	assert.ErrorIs(t, err, ErrScheduleInProd)
+
+	err = postgres.CheckGuardrails(&module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{"environment": "dev"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestPostgreSQLModule_GenerateAWSSchedules(t *testing.T) {
+	postgres := &PostgreSQL{
+		Type:         CloudDBType,
+		DatabaseName: "test-database",
+		Schedule: &ScheduleConfig{
+			Stop:     "cron(0 20 ? * MON-FRI *)",
+			Start:    "cron(0 8 ? * MON-FRI *)",
+			Timezone: "Asia/Shanghai",
+		},
+	}
+
+	resources, err := postgres.generateAWSSchedules(defaultAWSProviderCfg, "test-region", "hashicorp:aws:aws_db_instance:test-database")
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 4) {
+		return
+	}
+	assert.Equal(t, "hashicorp:aws:aws_iam_role:test-database-schedule", resources[0].ID)
+	assert.Equal(t, "hashicorp:aws:aws_iam_role_policy:test-database-schedule", resources[1].ID)
+	assert.Equal(t, "hashicorp:aws:aws_scheduler_schedule:test-database-stop", resources[2].ID)
+	assert.Equal(t, "hashicorp:aws:aws_scheduler_schedule:test-database-start", resources[3].ID)
+
+	stop := resources[2].Attributes
+	assert.Equal(t, "cron(0 20 ? * MON-FRI *)", stop["schedule_expression"])
+	assert.Equal(t, "Asia/Shanghai", stop["schedule_expression_timezone"])
+	target := stop["target"].([]map[string]interface{})[0]
+	assert.Equal(t, awsStopDBInstanceARN, target["arn"])
+	assert.Equal(t, `{"DbInstanceIdentifier":"test-database"}`, target["input"])
+
+	postgres.Schedule = &ScheduleConfig{}
+	_, err = postgres.generateAWSSchedules(defaultAWSProviderCfg, "test-region", "hashicorp:aws:aws_db_instance:test-database")
+	assert.ErrorIs(t, err, ErrEmptySchedule)
+}
+
+func TestPostgreSQLModule_GenerateAlicloudAutoPause(t *testing.T) {
+	postgres := &PostgreSQL{
+		Type:         CloudDBType,
+		DatabaseName: "test-database",
+		Category:     "serverless_basic",
+		Schedule:     &ScheduleConfig{},
+	}
+
+	res, _, err := postgres.generateAlicloudDBInstance(defaultAlicloudProviderCfg, "test-region")
+	assert.NoError(t, err)
+	serverlessConfig := res.Attributes["serverless_config"].([]alicloudServerlessConfig)
+	assert.True(t, serverlessConfig[0].AutoPause)
+
+	postgres.Category = defaultCategory
+	_, _, err = postgres.GenerateAlicloudResources(&module.GeneratorRequest{})
+	assert.ErrorIs(t, err, ErrScheduleUnsupported)
+}
@@ -0,0 +1,3 @@
+[package]
+name = "profiling"
+version = "0.1.0"
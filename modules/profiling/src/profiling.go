@@ -0,0 +1,639 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+// The language runtimes of the workload.
+const (
+	// RuntimeGo exposes the pprof endpoints scraped by the profiling agent of the platform, which
+	// is enabled by the pod annotations.
+	RuntimeGo = "go"
+	// RuntimeJava attaches the Pyroscope java agent by JAVA_TOOL_OPTIONS, which is copied to the
+	// pod by an init container.
+	RuntimeJava = "java"
+	// RuntimePython, RuntimeNodeJS, RuntimeRuby and RuntimeDotNet push the profiles with the
+	// Pyroscope SDK of the application, which is configured by the env vars.
+	RuntimePython = "python"
+	RuntimeNodeJS = "nodejs"
+	RuntimeRuby   = "ruby"
+	RuntimeDotNet = "dotnet"
+	// RuntimeEBPF profiles the processes of the pod by the eBPF profiler of the Grafana Alloy
+	// sidecar regardless of the language, which requires the privileged container.
+	RuntimeEBPF = "ebpf"
+)
+
+const (
+	defaultPprofPort      = 6060
+	defaultAgentURL       = "https://github.com/grafana/pyroscope-java/releases/download/v0.14.0/pyroscope.jar"
+	defaultDownloadImage  = "curlimages/curl:8.10.1"
+	defaultAlloyImage     = "grafana/alloy:v1.4.2"
+	scrapeAnnotationGroup = "profiles.grafana.com/"
+
+	// The env vars of the Pyroscope agent and SDKs.
+	serverAddressEnv     = "PYROSCOPE_SERVER_ADDRESS"
+	applicationNameEnv   = "PYROSCOPE_APPLICATION_NAME"
+	labelsEnv            = "PYROSCOPE_LABELS"
+	tenantIDEnv          = "PYROSCOPE_TENANT_ID"
+	basicAuthUserEnv     = "PYROSCOPE_BASIC_AUTH_USER"
+	basicAuthPasswordEnv = "PYROSCOPE_BASIC_AUTH_PASSWORD"
+	javaToolOptionsEnv   = "JAVA_TOOL_OPTIONS"
+
+	usernameKey = "username"
+	passwordKey = "password"
+
+	nameSuffix         = "-profiling"
+	agentVolumeName    = "pyroscope-agent"
+	agentDir           = "/pyroscope"
+	agentJar           = agentDir + "/pyroscope.jar"
+	alloyContainerName = "profiling-agent"
+	alloyConfigFile    = "config.alloy"
+	alloyConfigDir     = "/etc/alloy"
+	credentialsDir     = "/etc/profiling"
+	alloyConfigVolume  = "profiling-config"
+	credentialsVolume  = "profiling-credentials"
+	tenantHeader       = "X-Scope-OrgID"
+	downloadContainer  = "pyroscope-agent-download"
+)
+
+var (
+	// goProfileTypes are the profile types of the pprof endpoints of Go.
+	goProfileTypes = []string{"cpu", "memory", "goroutine", "block", "mutex"}
+	// defaultGoProfileTypes are the profile types scraped by default.
+	defaultGoProfileTypes = []string{"cpu", "memory"}
+)
+
+var (
+	ErrEmptyRuntime         = errors.New("runtime must not be empty")
+	ErrUnsupportedRuntime   = errors.New("runtime must be go, java, python, nodejs, ruby, dotnet or ebpf")
+	ErrEmptyServerAddress   = errors.New("empty serverAddress in the platform config, which is required by the runtimes pushing the profiles")
+	ErrInvalidServerAddress = errors.New("serverAddress must be an absolute http or https url")
+	ErrInvalidPort          = errors.New("port must be between 1 and 65535")
+	ErrInvalidProfileType   = errors.New("profileTypes must be cpu, memory, goroutine, block or mutex")
+	ErrProfileTypesNotGo    = errors.New("profileTypes is only supported by the go runtime")
+	ErrPrivilegedNotAllowed = errors.New("ebpf runtime runs the privileged sidecar, which is not allowed by the platform")
+	ErrEBPFForJob           = errors.New("ebpf runtime is not supported by the job workload, which never completes with the sidecar")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Profiling{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Profiling{})
+}
+
+// Profiling describes the continuous profiling of the workload by Pyroscope, which is enabled by
+// the way of the language runtime of the workload.
+type Profiling struct {
+	// Runtime is the language runtime of the workload, which is go, java, python, nodejs, ruby,
+	// dotnet or ebpf.
+	Runtime string `json:"runtime,omitempty" yaml:"runtime,omitempty"`
+	// Port is the port of the pprof endpoints of the go runtime, which defaults to 6060.
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+	// ProfileTypes are the profile types scraped from the go runtime, which default to cpu and
+	// memory.
+	ProfileTypes []string `json:"profileTypes,omitempty" yaml:"profileTypes,omitempty"`
+	// Tags are the labels attached to the profiles pushed by the agent, the SDK or the sidecar.
+	Tags map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+
+	// The platform config of the profiling module.
+	platform PlatformConfig
+}
+
+// PlatformConfig describes the platform config of the profiling module in workspace.
+type PlatformConfig struct {
+	// ServerAddress is the url of the Pyroscope server receiving the pushed profiles, which is
+	// required by the runtimes other than go.
+	ServerAddress string `json:"serverAddress,omitempty" yaml:"serverAddress,omitempty"`
+	// BasicAuth is the basic auth credentials of the Pyroscope server.
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	// TenantID is the tenant of the profiles in the multi-tenant Pyroscope, where "$project" is
+	// replaced by the project name.
+	TenantID string `json:"tenantID,omitempty" yaml:"tenantID,omitempty"`
+	// JavaAgentURL is the url to download the Pyroscope java agent from.
+	JavaAgentURL string `json:"javaAgentURL,omitempty" yaml:"javaAgentURL,omitempty"`
+	// DownloadImage is the image of the init container downloading the java agent by curl.
+	DownloadImage string `json:"downloadImage,omitempty" yaml:"downloadImage,omitempty"`
+	// AlloyImage is the image of the Grafana Alloy sidecar of the ebpf runtime.
+	AlloyImage string `json:"alloyImage,omitempty" yaml:"alloyImage,omitempty"`
+	// AllowPrivileged allows the privileged sidecar of the ebpf runtime.
+	AllowPrivileged bool `json:"allowPrivileged,omitempty" yaml:"allowPrivileged,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Profiling `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the containers and volumes patched into the
+	// workload are checked against, i.e. privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+}
+
+// BasicAuth describes the basic auth credentials.
+type BasicAuth struct {
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// Generate implements the generation logic of the profiling module.
+func (profiling *Profiling) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "profiling", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("profiling", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Profiling does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Profiling does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the profiling module.
+	if err := profiling.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("profiling", moduleutil.PhaseComplete, err)
+	}
+	if profiling.Runtime == RuntimeEBPF && isJob(request.Workload) {
+		return nil, moduleutil.NewModuleError("profiling", moduleutil.PhaseValidate, ErrEBPFForJob)
+	}
+
+	// The go runtime is scraped by the profiling agent of the platform.
+	if profiling.Runtime == RuntimeGo {
+		return &module.GeneratorResponse{
+			Patcher: &kusionapiv1.Patcher{PodAnnotations: profiling.scrapeAnnotations()},
+		}, nil
+	}
+
+	var resources []kusionapiv1.Resource
+	if profiling.platform.BasicAuth != nil {
+		secret, err := moduleutil.WrapK8sResource(profiling.generateSecret(request))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *secret)
+	}
+
+	var patcher *kusionapiv1.Patcher
+	switch profiling.Runtime {
+	case RuntimeEBPF:
+		configMap, err := moduleutil.WrapK8sResource(profiling.generateAlloyConfigMap(request))
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *configMap)
+		if patcher, err = profiling.generateAlloyPatcher(request); err != nil {
+			return nil, err
+		}
+	case RuntimeJava:
+		if patcher, err = profiling.generateJavaAgentPatcher(request); err != nil {
+			return nil, err
+		}
+	default:
+		patcher = &kusionapiv1.Patcher{Environments: profiling.sdkEnvs(request)}
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   patcher,
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the profiling module.
+func (profiling *Profiling) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Profiling{}); err != nil {
+		return moduleutil.NewModuleError("profiling", moduleutil.PhaseValidate, fmt.Errorf("validate profiling dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("profiling", moduleutil.PhaseValidate, fmt.Errorf("validate profiling platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, profiling); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &profiling.platform); err != nil {
+			return err
+		}
+	}
+
+	profiling.Runtime = strings.ToLower(profiling.Runtime)
+	if profiling.Runtime == RuntimeGo {
+		if profiling.Port == 0 {
+			profiling.Port = defaultPprofPort
+		}
+		if len(profiling.ProfileTypes) == 0 {
+			profiling.ProfileTypes = defaultGoProfileTypes
+		}
+	}
+	if profiling.platform.JavaAgentURL == "" {
+		profiling.platform.JavaAgentURL = defaultAgentURL
+	}
+	if profiling.platform.DownloadImage == "" {
+		profiling.platform.DownloadImage = defaultDownloadImage
+	}
+	if profiling.platform.AlloyImage == "" {
+		profiling.platform.AlloyImage = defaultAlloyImage
+	}
+
+	return profiling.Validate()
+}
+
+// Validate validates whether the configs of the profiling module are valid.
+func (profiling *Profiling) Validate() error {
+	switch profiling.Runtime {
+	case "":
+		return ErrEmptyRuntime
+	case RuntimeGo:
+		if profiling.Port < 1 || profiling.Port > 65535 {
+			return fmt.Errorf("%w, got %d", ErrInvalidPort, profiling.Port)
+		}
+		for _, t := range profiling.ProfileTypes {
+			if !slices.Contains(goProfileTypes, t) {
+				return fmt.Errorf("%w, got %s", ErrInvalidProfileType, t)
+			}
+		}
+		return nil
+	case RuntimeJava, RuntimePython, RuntimeNodeJS, RuntimeRuby, RuntimeDotNet, RuntimeEBPF:
+	default:
+		return fmt.Errorf("%w, got %s", ErrUnsupportedRuntime, profiling.Runtime)
+	}
+
+	// The other runtimes push the profiles to the server.
+	if len(profiling.ProfileTypes) != 0 {
+		return ErrProfileTypesNotGo
+	}
+	platform := profiling.platform
+	if platform.ServerAddress == "" {
+		return ErrEmptyServerAddress
+	}
+	if u, err := url.Parse(platform.ServerAddress); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w, %w", ErrInvalidServerAddress, &moduleutil.ConfigFieldError{Path: "serverAddress", Reason: "got " + platform.ServerAddress})
+	}
+	if profiling.Runtime == RuntimeEBPF && !platform.AllowPrivileged {
+		return ErrPrivilegedNotAllowed
+	}
+	return nil
+}
+
+// scrapeAnnotations returns the pod annotations enabling the profiling agent of the platform to
+// scrape the pprof endpoints of the profile types.
+func (profiling *Profiling) scrapeAnnotations() map[string]string {
+	annotations := make(map[string]string, 2*len(profiling.ProfileTypes))
+	for _, t := range profiling.ProfileTypes {
+		annotations[scrapeAnnotationGroup+t+".scrape"] = "true"
+		annotations[scrapeAnnotationGroup+t+".port"] = strconv.Itoa(profiling.Port)
+	}
+	return annotations
+}
+
+// resourceName returns the name of the credentials Secret and the Alloy ConfigMap.
+func resourceName(request *module.GeneratorRequest) string {
+	return moduleutil.AppName(request) + nameSuffix
+}
+
+// tenantID returns the tenant of the profiles of the project.
+func (profiling *Profiling) tenantID(request *module.GeneratorRequest) string {
+	return strings.ReplaceAll(profiling.platform.TenantID, "$project", request.Project)
+}
+
+// labels returns the tags of the profiles along with the project and stack, in the sorted order.
+func (profiling *Profiling) labels(request *module.GeneratorRequest) [][2]string {
+	tags := map[string]string{
+		"project": request.Project,
+		"stack":   request.Stack,
+	}
+	for k, v := range profiling.Tags {
+		tags[k] = v
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	labels := make([][2]string, 0, len(keys))
+	for _, k := range keys {
+		labels = append(labels, [2]string{k, tags[k]})
+	}
+	return labels
+}
+
+// generateSecret generates the Secret of the basic auth credentials of the Pyroscope server.
+func (profiling *Profiling) generateSecret(request *module.GeneratorRequest) *v1.Secret {
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceName(request),
+			Namespace: request.Project,
+		},
+		Type: v1.SecretTypeOpaque,
+		StringData: map[string]string{
+			usernameKey: profiling.platform.BasicAuth.Username,
+			passwordKey: profiling.platform.BasicAuth.Password,
+		},
+	}
+}
+
+// sdkEnvs returns the env vars configuring the Pyroscope java agent and SDKs.
+func (profiling *Profiling) sdkEnvs(request *module.GeneratorRequest) []v1.EnvVar {
+	var labels []string
+	for _, l := range profiling.labels(request) {
+		labels = append(labels, l[0]+"="+l[1])
+	}
+	envs := []v1.EnvVar{
+		{Name: serverAddressEnv, Value: profiling.platform.ServerAddress},
+		{Name: applicationNameEnv, Value: moduleutil.AppName(request)},
+		{Name: labelsEnv, Value: strings.Join(labels, ",")},
+	}
+	if tenant := profiling.tenantID(request); tenant != "" {
+		envs = append(envs, v1.EnvVar{Name: tenantIDEnv, Value: tenant})
+	}
+	if profiling.platform.BasicAuth != nil {
+		for _, env := range []struct{ name, key string }{
+			{basicAuthUserEnv, usernameKey},
+			{basicAuthPasswordEnv, passwordKey},
+		} {
+			envs = append(envs, v1.EnvVar{
+				Name: env.name,
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: resourceName(request)},
+						Key:                  env.key,
+					},
+				},
+			})
+		}
+	}
+	return envs
+}
+
+// generateJavaAgentPatcher generates the patcher attaching the Pyroscope java agent to the JVMs of
+// the containers, which is downloaded to the shared emptyDir by an init container.
+func (profiling *Profiling) generateJavaAgentPatcher(request *module.GeneratorRequest) (*kusionapiv1.Patcher, error) {
+	envs := append(profiling.sdkEnvs(request), v1.EnvVar{Name: javaToolOptionsEnv, Value: "-javaagent:" + agentJar})
+	patcher := &kusionapiv1.Patcher{Environments: envs}
+	if request.Workload == nil {
+		return patcher, nil
+	}
+	typeMeta, podSpecPath, err := moduleutil.WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return nil, err
+	}
+
+	download := v1.Container{
+		Name:    downloadContainer,
+		Image:   profiling.platform.DownloadImage,
+		Command: []string{"curl", "-fsSL", "-o", agentJar, profiling.platform.JavaAgentURL},
+		VolumeMounts: []v1.VolumeMount{
+			{Name: agentVolumeName, MountPath: agentDir},
+		},
+		SecurityContext: restrictedSecurityContext(),
+	}
+	operations := []map[string]interface{}{
+		appendOperation(podSpecPath+"/initContainers", workloadDeclaresInitContainers(request.Workload), download),
+		appendOperation(podSpecPath+"/volumes", moduleutil.WorkloadDeclaresVolumes(request.Workload), v1.Volume{
+			Name:         agentVolumeName,
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		}),
+	}
+	// Mount the agent to each container, in the order of the containers generated by the workload.
+	mount := v1.VolumeMount{Name: agentVolumeName, MountPath: agentDir, ReadOnly: true}
+	for i, mounts := range moduleutil.WorkloadContainerMounts(request.Workload) {
+		operations = append(operations, appendOperation(fmt.Sprintf("%s/containers/%d/volumeMounts", podSpecPath, i), mounts, mount))
+	}
+
+	payload, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	patcher.JSONPatchers = map[string]kusionapiv1.JSONPatcher{
+		module.KubernetesResourceID(typeMeta, workloadObjectMeta(request)): {
+			Type:    kusionapiv1.JSONPatch,
+			Payload: payload,
+		},
+	}
+	return patcher, nil
+}
+
+// alloyConfig returns the config of the Grafana Alloy sidecar, which profiles the processes in the
+// pod by eBPF and writes the profiles to the Pyroscope server.
+func (profiling *Profiling) alloyConfig(request *module.GeneratorRequest) string {
+	var b strings.Builder
+	b.WriteString("discovery.process \"local\" {}\n\n")
+	b.WriteString("pyroscope.ebpf \"default\" {\n")
+	b.WriteString("  targets    = discovery.process.local.targets\n")
+	b.WriteString("  forward_to = [pyroscope.write.default.receiver]\n")
+	b.WriteString("}\n\n")
+	b.WriteString("pyroscope.write \"default\" {\n")
+	b.WriteString("  endpoint {\n")
+	fmt.Fprintf(&b, "    url = %q\n", profiling.platform.ServerAddress)
+	if auth := profiling.platform.BasicAuth; auth != nil {
+		b.WriteString("    basic_auth {\n")
+		fmt.Fprintf(&b, "      username      = %q\n", auth.Username)
+		fmt.Fprintf(&b, "      password_file = %q\n", credentialsDir+"/"+passwordKey)
+		b.WriteString("    }\n")
+	}
+	if tenant := profiling.tenantID(request); tenant != "" {
+		fmt.Fprintf(&b, "    headers = {%q = %q}\n", tenantHeader, tenant)
+	}
+	b.WriteString("  }\n")
+	b.WriteString("  external_labels = {\n")
+	fmt.Fprintf(&b, "    %q = %q,\n", "service_name", moduleutil.AppName(request))
+	for _, l := range profiling.labels(request) {
+		fmt.Fprintf(&b, "    %q = %q,\n", l[0], l[1])
+	}
+	b.WriteString("  }\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// generateAlloyConfigMap generates the ConfigMap of the Alloy config.
+func (profiling *Profiling) generateAlloyConfigMap(request *module.GeneratorRequest) *v1.ConfigMap {
+	return &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceName(request),
+			Namespace: request.Project,
+		},
+		Data: map[string]string{alloyConfigFile: profiling.alloyConfig(request)},
+	}
+}
+
+// generateAlloyPatcher generates the JSON patch adding the privileged Alloy sidecar sharing the
+// process namespace of the pod, which is required to load the eBPF programs and read the symbols
+// of the processes of the other containers.
+func (profiling *Profiling) generateAlloyPatcher(request *module.GeneratorRequest) (*kusionapiv1.Patcher, error) {
+	if request.Workload == nil {
+		return nil, nil
+	}
+	typeMeta, podSpecPath, err := moduleutil.WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return nil, err
+	}
+
+	privileged := true
+	sidecar := v1.Container{
+		Name:  alloyContainerName,
+		Image: profiling.platform.AlloyImage,
+		Args:  []string{"run", alloyConfigDir + "/" + alloyConfigFile, "--storage.path=/tmp/alloy"},
+		VolumeMounts: []v1.VolumeMount{
+			{Name: alloyConfigVolume, MountPath: alloyConfigDir, ReadOnly: true},
+		},
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("100m"),
+				v1.ResourceMemory: resource.MustParse("128Mi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("512Mi"),
+			},
+		},
+		SecurityContext: &v1.SecurityContext{Privileged: &privileged},
+	}
+	volumes := []v1.Volume{
+		{
+			Name: alloyConfigVolume,
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: resourceName(request)},
+				},
+			},
+		},
+	}
+	if profiling.platform.BasicAuth != nil {
+		sidecar.VolumeMounts = append(sidecar.VolumeMounts, v1.VolumeMount{Name: credentialsVolume, MountPath: credentialsDir, ReadOnly: true})
+		volumes = append(volumes, v1.Volume{
+			Name: credentialsVolume,
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{SecretName: resourceName(request)},
+			},
+		})
+	}
+
+	operations := []map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  podSpecPath + "/shareProcessNamespace",
+			"value": true,
+		},
+		{
+			"op":    "add",
+			"path":  podSpecPath + "/containers/-",
+			"value": sidecar,
+		},
+	}
+	exists := moduleutil.WorkloadDeclaresVolumes(request.Workload)
+	for _, volume := range volumes {
+		operations = append(operations, appendOperation(podSpecPath+"/volumes", exists, volume))
+		exists = true
+	}
+
+	payload, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	return &kusionapiv1.Patcher{
+		JSONPatchers: map[string]kusionapiv1.JSONPatcher{
+			module.KubernetesResourceID(typeMeta, workloadObjectMeta(request)): {
+				Type:    kusionapiv1.JSONPatch,
+				Payload: payload,
+			},
+		},
+	}, nil
+}
+
+// appendOperation returns the JSON patch operation appending the value to the array at the path,
+// or creating the array of the value if the array does not exist.
+func appendOperation(path string, exists bool, value interface{}) map[string]interface{} {
+	if exists {
+		return map[string]interface{}{"op": "add", "path": path + "/-", "value": value}
+	}
+	return map[string]interface{}{"op": "add", "path": path, "value": []interface{}{value}}
+}
+
+// restrictedSecurityContext returns the security context of the init container conforming to the
+// restricted Pod Security Standards.
+func restrictedSecurityContext() *v1.SecurityContext {
+	allowPrivilegeEscalation := false
+	runAsNonRoot := true
+	return &v1.SecurityContext{
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		RunAsNonRoot:             &runAsNonRoot,
+		Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+		SeccompProfile:           &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// workloadObjectMeta returns the ObjectMeta of the workload generated by the service or job module.
+func workloadObjectMeta(request *module.GeneratorRequest) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      moduleutil.AppName(request),
+		Namespace: request.Project,
+	}
+}
+
+// isJob returns whether the workload is a job.
+func isJob(workload kusionapiv1.Accessory) bool {
+	kind, _ := workload["_type"].(string)
+	return strings.Contains(kind, ".Job")
+}
+
+// workloadDeclaresInitContainers returns whether the pod spec of the workload has the init
+// containers.
+func workloadDeclaresInitContainers(workload kusionapiv1.Accessory) bool {
+	initContainers, _ := workload["initContainers"].(map[string]interface{})
+	return len(initContainers) != 0
+}
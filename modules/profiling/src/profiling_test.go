@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestProfiling_Generate(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"serverAddress":   "http://pyroscope.monitoring:4040",
+		"basicAuth":       map[string]interface{}{"username": "kusion", "password": "secret"},
+		"tenantID":        "$project",
+		"allowPrivileged": true,
+	}
+
+	tests := []struct {
+		name           string
+		job            bool
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+		expectedErr    error
+		expectedKinds  []string
+	}{
+		{
+			name:          "go",
+			devConfig:     kusionapiv1.Accessory{"runtime": "go"},
+			expectedKinds: []string{},
+		},
+		{
+			name:           "java",
+			devConfig:      kusionapiv1.Accessory{"runtime": "java"},
+			platformConfig: platformConfig,
+			expectedKinds:  []string{"Secret"},
+		},
+		{
+			name:           "ebpf",
+			devConfig:      kusionapiv1.Accessory{"runtime": "ebpf"},
+			platformConfig: platformConfig,
+			expectedKinds:  []string{"Secret", "ConfigMap"},
+		},
+		{
+			name:      "ebpf under baseline pod security",
+			devConfig: kusionapiv1.Accessory{"runtime": "ebpf"},
+			platformConfig: kusionapiv1.GenericConfig{
+				"serverAddress":   "http://pyroscope.monitoring:4040",
+				"allowPrivileged": true,
+				"podSecurity":     "baseline",
+			},
+			expectedPhase: moduleutil.PhaseGenerate,
+			expectedErr:   moduleutil.ErrPodSecurityViolation,
+		},
+		{
+			name:           "ebpf of job",
+			job:            true,
+			devConfig:      kusionapiv1.Accessory{"runtime": "ebpf"},
+			platformConfig: platformConfig,
+			expectedPhase:  moduleutil.PhaseValidate,
+			expectedErr:    ErrEBPFForJob,
+		},
+		{
+			name:           "ebpf not allowed",
+			devConfig:      kusionapiv1.Accessory{"runtime": "ebpf"},
+			platformConfig: kusionapiv1.GenericConfig{"serverAddress": "http://pyroscope.monitoring:4040"},
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrPrivilegedNotAllowed,
+		},
+		{
+			name:          "python without server address",
+			devConfig:     kusionapiv1.Accessory{"runtime": "python"},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrEmptyServerAddress,
+		},
+		{
+			name:          "empty runtime",
+			devConfig:     kusionapiv1.Accessory{},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrEmptyRuntime,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := testutil.NewRequest().WithServiceWorkload("Deployment")
+			if tt.job {
+				builder = builder.WithJobWorkload()
+			}
+			request := builder.WithDevConfig(tt.devConfig).WithPlatformConfig(tt.platformConfig).Build()
+
+			response, err := (&Profiling{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedKinds)) {
+				return
+			}
+			for i, kind := range tt.expectedKinds {
+				assert.Equal(t, kind, response.Resources[i].Attributes["kind"])
+			}
+		})
+	}
+}
+
+func TestProfiling_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		profiling   Profiling
+		expectedErr error
+	}{
+		{
+			name:        "unsupported runtime",
+			profiling:   Profiling{Runtime: "php"},
+			expectedErr: ErrUnsupportedRuntime,
+		},
+		{
+			name:        "invalid port",
+			profiling:   Profiling{Runtime: RuntimeGo, Port: 70000},
+			expectedErr: ErrInvalidPort,
+		},
+		{
+			name:        "invalid profile type",
+			profiling:   Profiling{Runtime: RuntimeGo, Port: 6060, ProfileTypes: []string{"wall"}},
+			expectedErr: ErrInvalidProfileType,
+		},
+		{
+			name:        "profile types of java",
+			profiling:   Profiling{Runtime: RuntimeJava, ProfileTypes: []string{"cpu"}},
+			expectedErr: ErrProfileTypesNotGo,
+		},
+		{
+			name:        "invalid server address",
+			profiling:   Profiling{Runtime: RuntimeNodeJS, platform: PlatformConfig{ServerAddress: "pyroscope:4040"}},
+			expectedErr: ErrInvalidServerAddress,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.profiling.Validate(), tt.expectedErr)
+		})
+	}
+}
+
+func TestProfiling_ScrapeAnnotations(t *testing.T) {
+	profiling := &Profiling{Runtime: RuntimeGo, Port: 6060, ProfileTypes: []string{"cpu", "goroutine"}}
+
+	assert.Equal(t, map[string]string{
+		"profiles.grafana.com/cpu.scrape":       "true",
+		"profiles.grafana.com/cpu.port":         "6060",
+		"profiles.grafana.com/goroutine.scrape": "true",
+		"profiles.grafana.com/goroutine.port":   "6060",
+	}, profiling.scrapeAnnotations())
+}
+
+func TestProfiling_GenerateJavaAgentPatcher(t *testing.T) {
+	request := testutil.NewRequest().WithWorkload(kusionapiv1.Accessory{
+		"_type": "service.Service",
+		"type":  "Deployment",
+		"containers": map[string]interface{}{
+			"worker": map[string]interface{}{},
+			"api":    map[string]interface{}{"files": map[string]interface{}{"/etc/app.conf": map[string]interface{}{}}},
+		},
+	}).Build()
+	profiling := &Profiling{
+		Runtime: RuntimeJava,
+		Tags:    map[string]string{"team": "payments"},
+		platform: PlatformConfig{
+			ServerAddress: "http://pyroscope.monitoring:4040",
+			TenantID:      "$project",
+			JavaAgentURL:  defaultAgentURL,
+			DownloadImage: defaultDownloadImage,
+		},
+	}
+
+	patcher, err := profiling.generateJavaAgentPatcher(request)
+	assert.NoError(t, err)
+	envs := map[string]string{}
+	for _, env := range patcher.Environments {
+		envs[env.Name] = env.Value
+	}
+	assert.Equal(t, map[string]string{
+		serverAddressEnv:   "http://pyroscope.monitoring:4040",
+		applicationNameEnv: "default-dev-foo",
+		labelsEnv:          "project=default,stack=dev,team=payments",
+		tenantIDEnv:        "default",
+		javaToolOptionsEnv: "-javaagent:/pyroscope/pyroscope.jar",
+	}, envs)
+
+	id := "apps/v1:Deployment:default:default-dev-foo"
+	if !assert.Contains(t, patcher.JSONPatchers, id) {
+		return
+	}
+	var operations []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(patcher.JSONPatchers[id].Payload, &operations))
+	var paths []string
+	for _, op := range operations {
+		paths = append(paths, op["path"].(string))
+	}
+	assert.Equal(t, []string{
+		"/spec/template/spec/initContainers",
+		"/spec/template/spec/volumes/-",
+		"/spec/template/spec/containers/0/volumeMounts/-",
+		"/spec/template/spec/containers/1/volumeMounts",
+	}, paths)
+}
+
+func TestProfiling_GenerateAlloy(t *testing.T) {
+	request := testutil.NewRequest().WithServiceWorkload("CollaSet").Build()
+	profiling := &Profiling{
+		Runtime: RuntimeEBPF,
+		platform: PlatformConfig{
+			ServerAddress:   "http://pyroscope.monitoring:4040",
+			BasicAuth:       &BasicAuth{Username: "kusion", Password: "secret"},
+			AlloyImage:      defaultAlloyImage,
+			AllowPrivileged: true,
+		},
+	}
+
+	config := profiling.generateAlloyConfigMap(request).Data[alloyConfigFile]
+	assert.Contains(t, config, `url = "http://pyroscope.monitoring:4040"`)
+	assert.Contains(t, config, `password_file = "/etc/profiling/password"`)
+	assert.Contains(t, config, `"service_name" = "default-dev-foo",`)
+	assert.NotContains(t, config, "secret")
+
+	patcher, err := profiling.generateAlloyPatcher(request)
+	assert.NoError(t, err)
+	id := "apps.kusionstack.io/v1alpha1:CollaSet:default:default-dev-foo"
+	if !assert.Contains(t, patcher.JSONPatchers, id) {
+		return
+	}
+	var operations []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(patcher.JSONPatchers[id].Payload, &operations))
+	if !assert.Len(t, operations, 4) {
+		return
+	}
+	assert.Equal(t, "/spec/template/spec/shareProcessNamespace", operations[0]["path"])
+	sidecar := operations[1]["value"].(map[string]interface{})
+	assert.Equal(t, alloyContainerName, sidecar["name"])
+	assert.Equal(t, true, sidecar["securityContext"].(map[string]interface{})["privileged"])
+	assert.Equal(t, "/spec/template/spec/volumes", operations[2]["path"])
+	assert.Equal(t, "/spec/template/spec/volumes/-", operations[3]["path"])
+}
@@ -0,0 +1,3 @@
+[package]
+name = "rbac"
+version = "0.1.0"
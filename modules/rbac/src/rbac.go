@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+const (
+	// wildcard matches all the API groups, resources or verbs in the rules.
+	wildcard = "*"
+)
+
+var (
+	ErrEmptyRules              = errors.New("rules must not be empty")
+	ErrInvalidRule             = errors.New("rule must declare the resources and the verbs")
+	ErrRuleNotAllowed          = errors.New("rule is not allowed by the platform")
+	ErrClusterScopedNotAllowed = errors.New("clusterScoped is not allowed by the platform")
+	ErrInvalidServiceAccount   = errors.New("serviceAccountName must be a valid DNS subdomain")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Rbac{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Rbac{})
+}
+
+// Rbac describes the Kubernetes API permissions required by the workload of the application,
+// which are granted to the ServiceAccount of the workload by a Role and a RoleBinding, or a
+// ClusterRole and a ClusterRoleBinding if cluster scoped.
+type Rbac struct {
+	// Rules are the permissions required by the workload.
+	Rules []Rule `json:"rules,omitempty" yaml:"rules,omitempty"`
+	// ClusterScoped grants the permissions across the cluster with a ClusterRole, which must be
+	// allowed by the platform.
+	ClusterScoped bool `json:"clusterScoped,omitempty" yaml:"clusterScoped,omitempty"`
+	// ServiceAccountName is the existing ServiceAccount granted the permissions, defaults to the
+	// ServiceAccount declared in the workload. A ServiceAccount named after the workload is
+	// generated and set to the workload if neither is declared.
+	ServiceAccountName string `json:"serviceAccountName,omitempty" yaml:"serviceAccountName,omitempty"`
+
+	// The platform config of the rbac module.
+	platform PlatformConfig
+}
+
+// Rule describes the permission of the verbs on the resources in the API groups.
+type Rule struct {
+	// APIGroups are the API groups of the resources, "" for the core API group.
+	APIGroups []string `json:"apiGroups,omitempty" yaml:"apiGroups,omitempty"`
+	// Resources are the resources, e.g. configmaps or pods/log.
+	Resources []string `json:"resources,omitempty" yaml:"resources,omitempty"`
+	// Verbs are the verbs, e.g. get, list or watch.
+	Verbs []string `json:"verbs,omitempty" yaml:"verbs,omitempty"`
+	// ResourceNames restricts the rule to the resources of the names, all if empty.
+	ResourceNames []string `json:"resourceNames,omitempty" yaml:"resourceNames,omitempty"`
+}
+
+// PlatformConfig describes the platform config of the rbac module in workspace.
+type PlatformConfig struct {
+	// AllowedRules is the allow-list of the rules. Each rule declared by the application must be
+	// covered by one of the allowed rules, where "*" matches all. All the rules are allowed if empty.
+	AllowedRules []Rule `json:"allowedRules,omitempty" yaml:"allowedRules,omitempty"`
+	// AllowClusterScoped allows the applications to grant the cluster scoped permissions.
+	AllowClusterScoped bool `json:"allowClusterScoped,omitempty" yaml:"allowClusterScoped,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Rbac `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+}
+
+// Generate implements the generation logic of the rbac module.
+func (rbac *Rbac) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "rbac", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("rbac", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Rbac does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Rbac does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the rbac module.
+	if err := rbac.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("rbac", moduleutil.PhaseComplete, err)
+	}
+
+	// Generate the ServiceAccount if not declared, the Role and the RoleBinding depending on them.
+	var resources []kusionapiv1.Resource
+	var dependsOn []string
+	var patcher *kusionapiv1.Patcher
+	serviceAccountName := rbac.ServiceAccountName
+	if serviceAccountName == "" {
+		serviceAccountName = workloadServiceAccount(request)
+	}
+	if serviceAccountName == "" {
+		sa := rbac.generateServiceAccount(request)
+		res, err := moduleutil.WrapK8sResource(sa)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, *res)
+		dependsOn = append(dependsOn, res.ID)
+		serviceAccountName = sa.Name
+
+		if patcher, err = rbac.generateServiceAccountPatcher(request, serviceAccountName); err != nil {
+			return nil, err
+		}
+	}
+
+	role := rbac.generateRole(request)
+	roleResource, err := moduleutil.WrapK8sResource(role)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, *roleResource)
+	dependsOn = append(dependsOn, roleResource.ID)
+
+	binding, err := moduleutil.WrapK8sResource(rbac.generateRoleBinding(request, role, serviceAccountName))
+	if err != nil {
+		return nil, err
+	}
+	binding.DependsOn = dependsOn
+	resources = append(resources, *binding)
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   patcher,
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the rbac module.
+func (rbac *Rbac) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Rbac{}); err != nil {
+		return moduleutil.NewModuleError("rbac", moduleutil.PhaseValidate, fmt.Errorf("validate rbac dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("rbac", moduleutil.PhaseValidate, fmt.Errorf("validate rbac platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, rbac); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &rbac.platform); err != nil {
+			return err
+		}
+	}
+
+	return rbac.Validate()
+}
+
+// Validate validates whether the configs of the rbac module are valid.
+func (rbac *Rbac) Validate() error {
+	if len(rbac.Rules) == 0 {
+		return ErrEmptyRules
+	}
+	if rbac.ClusterScoped && !rbac.platform.AllowClusterScoped {
+		return ErrClusterScopedNotAllowed
+	}
+	if rbac.ServiceAccountName != "" {
+		if errs := validation.IsDNS1123Subdomain(rbac.ServiceAccountName); len(errs) != 0 {
+			return fmt.Errorf("%w, got %s: %s", ErrInvalidServiceAccount, rbac.ServiceAccountName, strings.Join(errs, "; "))
+		}
+	}
+	for _, rule := range rbac.Rules {
+		if len(rule.Resources) == 0 || len(rule.Verbs) == 0 {
+			return fmt.Errorf("%w, got %s", ErrInvalidRule, rule)
+		}
+		if !rbac.allowed(rule) {
+			return fmt.Errorf("%w, got %s", ErrRuleNotAllowed, rule)
+		}
+	}
+	return nil
+}
+
+// allowed returns whether the rule is covered by one of the allowed rules of the platform.
+func (rbac *Rbac) allowed(rule Rule) bool {
+	if len(rbac.platform.AllowedRules) == 0 {
+		return true
+	}
+	apiGroups := rule.APIGroups
+	if len(apiGroups) == 0 {
+		apiGroups = []string{""}
+	}
+	for _, allowed := range rbac.platform.AllowedRules {
+		if !covers(allowed.APIGroups, apiGroups) || !covers(allowed.Resources, rule.Resources) ||
+			!covers(allowed.Verbs, rule.Verbs) {
+			continue
+		}
+		// The allowed rule restricted to the resource names only covers the rules restricted to
+		// a subset of them.
+		if len(allowed.ResourceNames) != 0 && (len(rule.ResourceNames) == 0 || !covers(allowed.ResourceNames, rule.ResourceNames)) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// covers returns whether all the values are in the allowed ones, where "*" covers all.
+func covers(allowed, values []string) bool {
+	set := make(map[string]bool, len(allowed))
+	for _, a := range allowed {
+		if a == wildcard {
+			return true
+		}
+		set[a] = true
+	}
+	if len(allowed) == 0 {
+		set[""] = true
+	}
+	for _, v := range values {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the rule in the form of verbs:apiGroups/resources/resourceNames.
+func (r Rule) String() string {
+	return fmt.Sprintf("%s:%s/%s/%s", strings.Join(r.Verbs, ","), strings.Join(r.APIGroups, ","),
+		strings.Join(r.Resources, ","), strings.Join(r.ResourceNames, ","))
+}
+
+// generateServiceAccount generates the ServiceAccount named after the workload.
+func (rbac *Rbac) generateServiceAccount(request *module.GeneratorRequest) *v1.ServiceAccount {
+	return &v1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.AppName(request),
+			Namespace: request.Project,
+		},
+	}
+}
+
+// generateRole generates the Role of the rules, or the ClusterRole if cluster scoped.
+func (rbac *Rbac) generateRole(request *module.GeneratorRequest) moduleutil.K8sObject {
+	rules := make([]rbacv1.PolicyRule, 0, len(rbac.Rules))
+	for _, rule := range rbac.Rules {
+		apiGroups := rule.APIGroups
+		if len(apiGroups) == 0 {
+			apiGroups = []string{""}
+		}
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     apiGroups,
+			Resources:     rule.Resources,
+			Verbs:         rule.Verbs,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	if rbac.ClusterScoped {
+		return &rbacv1.ClusterRole{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "ClusterRole",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: moduleutil.AppName(request),
+			},
+			Rules: rules,
+		}
+	}
+	return &rbacv1.Role{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "Role",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.AppName(request),
+			Namespace: request.Project,
+		},
+		Rules: rules,
+	}
+}
+
+// generateRoleBinding generates the RoleBinding binding the role to the ServiceAccount, or the
+// ClusterRoleBinding if cluster scoped.
+func (rbac *Rbac) generateRoleBinding(request *module.GeneratorRequest, role moduleutil.K8sObject, serviceAccountName string) moduleutil.K8sObject {
+	subjects := []rbacv1.Subject{
+		{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      serviceAccountName,
+			Namespace: request.Project,
+		},
+	}
+	roleRef := rbacv1.RoleRef{
+		APIGroup: rbacv1.GroupName,
+		Kind:     role.GetObjectKind().GroupVersionKind().Kind,
+		Name:     role.GetName(),
+	}
+	if rbac.ClusterScoped {
+		return &rbacv1.ClusterRoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: rbacv1.SchemeGroupVersion.String(),
+				Kind:       "ClusterRoleBinding",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: moduleutil.AppName(request),
+			},
+			Subjects: subjects,
+			RoleRef:  roleRef,
+		}
+	}
+	return &rbacv1.RoleBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rbacv1.SchemeGroupVersion.String(),
+			Kind:       "RoleBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      moduleutil.AppName(request),
+			Namespace: request.Project,
+		},
+		Subjects: subjects,
+		RoleRef:  roleRef,
+	}
+}
+
+// generateServiceAccountPatcher generates the JSON patch which sets the ServiceAccount to the pod
+// template of the workload, or nil if the workload is not declared.
+func (rbac *Rbac) generateServiceAccountPatcher(request *module.GeneratorRequest, serviceAccountName string) (*kusionapiv1.Patcher, error) {
+	if request.Workload == nil {
+		return nil, nil
+	}
+	typeMeta, podSpecPath, err := moduleutil.WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.AppName(request),
+		Namespace: request.Project,
+	}
+
+	payload, err := json.Marshal([]map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  podSpecPath + "/serviceAccountName",
+			"value": serviceAccountName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &kusionapiv1.Patcher{
+		JSONPatchers: map[string]kusionapiv1.JSONPatcher{
+			module.KubernetesResourceID(typeMeta, objectMeta): {
+				Type:    kusionapiv1.JSONPatch,
+				Payload: payload,
+			},
+		},
+	}, nil
+}
+
+// workloadServiceAccount returns the name of the ServiceAccount declared in the workload, which is
+// the dedicated one named after the workload if its name is not declared, or empty if the workload
+// does not declare the ServiceAccount.
+func workloadServiceAccount(request *module.GeneratorRequest) string {
+	sa, ok := request.Workload["serviceAccount"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if name, _ := sa["name"].(string); name != "" {
+		return name
+	}
+	return moduleutil.AppName(request)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+	"testutil"
+)
+
+func TestRbac_Generate(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+	}{
+		{
+			name: "rules",
+			devConfig: kusionapiv1.Accessory{
+				"rules": []interface{}{
+					map[string]interface{}{"resources": []interface{}{"configmaps"}, "verbs": []interface{}{"get"}},
+				},
+			},
+		},
+		{
+			name:          "empty config",
+			devConfig:     kusionapiv1.Accessory{},
+			expectedPhase: moduleutil.PhaseComplete,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Rbac{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, response)
+		})
+	}
+}
+
+func TestRbac_GenerateServiceAccount(t *testing.T) {
+	rules := []interface{}{
+		map[string]interface{}{
+			"resources": []interface{}{"configmaps"},
+			"verbs":     []interface{}{"get", "watch"},
+		},
+	}
+	tests := []struct {
+		name          string
+		request       *module.GeneratorRequest
+		expectedIDs   []string
+		expectedPatch map[string]string
+	}{
+		{
+			name: "deployment",
+			request: testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(kusionapiv1.Accessory{"rules": rules}).
+				Build(),
+			expectedIDs: []string{
+				"v1:ServiceAccount:default:default-dev-foo",
+				"rbac.authorization.k8s.io/v1:Role:default:default-dev-foo",
+				"rbac.authorization.k8s.io/v1:RoleBinding:default:default-dev-foo",
+			},
+			expectedPatch: map[string]string{
+				"apps/v1:Deployment:default:default-dev-foo": "/spec/template/spec/serviceAccountName",
+			},
+		},
+		{
+			name: "cron job",
+			request: testutil.NewRequest().
+				WithWorkload(kusionapiv1.Accessory{"_type": "job.Job", "schedule": "0 * * * *"}).
+				WithDevConfig(kusionapiv1.Accessory{"rules": rules}).
+				Build(),
+			expectedIDs: []string{
+				"v1:ServiceAccount:default:default-dev-foo",
+				"rbac.authorization.k8s.io/v1:Role:default:default-dev-foo",
+				"rbac.authorization.k8s.io/v1:RoleBinding:default:default-dev-foo",
+			},
+			expectedPatch: map[string]string{
+				"batch/v1:CronJob:default:default-dev-foo": "/spec/jobTemplate/spec/template/spec/serviceAccountName",
+			},
+		},
+		{
+			name: "service account declared in workload",
+			request: testutil.NewRequest().
+				WithWorkload(kusionapiv1.Accessory{
+					"_type":          "service.Service",
+					"serviceAccount": map[string]interface{}{"name": "reader"},
+				}).
+				WithDevConfig(kusionapiv1.Accessory{"rules": rules}).
+				Build(),
+			expectedIDs: []string{
+				"rbac.authorization.k8s.io/v1:Role:default:default-dev-foo",
+				"rbac.authorization.k8s.io/v1:RoleBinding:default:default-dev-foo",
+			},
+		},
+		{
+			name: "existing service account and cluster scoped",
+			request: testutil.NewRequest().
+				WithServiceWorkload("CollaSet").
+				WithDevConfig(kusionapiv1.Accessory{
+					"rules":              rules,
+					"clusterScoped":      true,
+					"serviceAccountName": "reader",
+				}).
+				WithPlatformConfig(kusionapiv1.GenericConfig{"allowClusterScoped": true}).
+				Build(),
+			expectedIDs: []string{
+				"rbac.authorization.k8s.io/v1:ClusterRole:default-dev-foo",
+				"rbac.authorization.k8s.io/v1:ClusterRoleBinding:default-dev-foo",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := (&Rbac{}).Generate(context.Background(), tt.request)
+			if !assert.NoError(t, err) {
+				return
+			}
+			ids := make([]string, 0, len(response.Resources))
+			for _, res := range response.Resources {
+				ids = append(ids, res.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+
+			binding := response.Resources[len(response.Resources)-1]
+			assert.Equal(t, ids[:len(ids)-1], binding.DependsOn)
+			subjects := binding.Attributes["subjects"].([]interface{})
+			if tt.expectedPatch == nil {
+				assert.Nil(t, response.Patcher)
+				assert.Equal(t, "reader", subjects[0].(map[string]interface{})["name"])
+				return
+			}
+			assert.Equal(t, "default-dev-foo", subjects[0].(map[string]interface{})["name"])
+			if assert.NotNil(t, response.Patcher) {
+				assert.Len(t, response.Patcher.JSONPatchers, len(tt.expectedPatch))
+				for id, path := range tt.expectedPatch {
+					var operations []map[string]interface{}
+					assert.NoError(t, json.Unmarshal(response.Patcher.JSONPatchers[id].Payload, &operations))
+					assert.Equal(t, []map[string]interface{}{
+						{"op": "add", "path": path, "value": "default-dev-foo"},
+					}, operations)
+				}
+			}
+		})
+	}
+}
+
+func TestRbac_Validate(t *testing.T) {
+	allowed := []Rule{
+		{APIGroups: []string{""}, Resources: []string{"configmaps", "secrets"}, Verbs: []string{"get", "list", "watch"}},
+		{APIGroups: []string{"apps"}, Resources: []string{wildcard}, Verbs: []string{"get"}},
+		{Resources: []string{"leases"}, APIGroups: []string{"coordination.k8s.io"}, Verbs: []string{wildcard}, ResourceNames: []string{"leader"}},
+	}
+	tests := []struct {
+		name     string
+		rbac     Rbac
+		expected error
+	}{
+		{
+			name: "allowed",
+			rbac: Rbac{Rules: []Rule{
+				{Resources: []string{"secrets"}, Verbs: []string{"get", "watch"}},
+				{APIGroups: []string{"apps"}, Resources: []string{"deployments", "statefulsets"}, Verbs: []string{"get"}},
+				{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"update"}, ResourceNames: []string{"leader"}},
+			}},
+		},
+		{
+			name:     "empty rules",
+			expected: ErrEmptyRules,
+		},
+		{
+			name:     "rule without verbs",
+			rbac:     Rbac{Rules: []Rule{{Resources: []string{"secrets"}}}},
+			expected: ErrInvalidRule,
+		},
+		{
+			name:     "verb not allowed",
+			rbac:     Rbac{Rules: []Rule{{Resources: []string{"secrets"}, Verbs: []string{"delete"}}}},
+			expected: ErrRuleNotAllowed,
+		},
+		{
+			name:     "wildcard not allowed",
+			rbac:     Rbac{Rules: []Rule{{APIGroups: []string{wildcard}, Resources: []string{"deployments"}, Verbs: []string{"get"}}}},
+			expected: ErrRuleNotAllowed,
+		},
+		{
+			name:     "resource names not restricted",
+			rbac:     Rbac{Rules: []Rule{{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: []string{"update"}}}},
+			expected: ErrRuleNotAllowed,
+		},
+		{
+			name:     "cluster scoped not allowed",
+			rbac:     Rbac{Rules: []Rule{{Resources: []string{"secrets"}, Verbs: []string{"get"}}}, ClusterScoped: true},
+			expected: ErrClusterScopedNotAllowed,
+		},
+		{
+			name:     "invalid service account",
+			rbac:     Rbac{Rules: []Rule{{Resources: []string{"secrets"}, Verbs: []string{"get"}}}, ServiceAccountName: "Reader"},
+			expected: ErrInvalidServiceAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rbac.platform.AllowedRules = allowed
+			err := tt.rbac.Validate()
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
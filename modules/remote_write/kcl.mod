@@ -0,0 +1,3 @@
+[package]
+name = "remote_write"
+version = "0.1.0"
@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+const (
+	// ModePushgateway pushes the metrics to the Pushgateway, which suits the batch jobs finished
+	// before being scraped.
+	ModePushgateway = "pushgateway"
+	// ModeAgent runs the Prometheus agent as the sidecar of the workload, which scrapes the metrics
+	// of the workload on localhost and forwards them by remote write.
+	ModeAgent = "agent"
+
+	defaultAgentImage = "prom/prometheus:v2.53.0"
+	defaultPath       = "/metrics"
+	defaultInterval   = "30s"
+
+	// The keys of the credentials Secret.
+	urlKey         = "url"
+	usernameKey    = "username"
+	passwordKey    = "password"
+	bearerTokenKey = "bearerToken"
+
+	// The env vars of the Pushgateway injected into the workload.
+	pushgatewayURLEnv         = "PUSHGATEWAY_URL"
+	pushgatewayJobEnv         = "PUSHGATEWAY_JOB"
+	pushgatewayUsernameEnv    = "PUSHGATEWAY_USERNAME"
+	pushgatewayPasswordEnv    = "PUSHGATEWAY_PASSWORD"
+	pushgatewayBearerTokenEnv = "PUSHGATEWAY_BEARER_TOKEN"
+
+	nameSuffix         = "-remote-write"
+	agentContainerName = "remote-write-agent"
+	agentConfigFile    = "prometheus.yml"
+	agentConfigDir     = "/etc/prometheus"
+	credentialsDir     = "/etc/remote-write"
+	walDir             = "/prometheus"
+	tenantHeader       = "X-Scope-OrgID"
+	// agentUID is the uid of the nobody user running the Prometheus image.
+	agentUID = 65534
+	// checksumAnnotation rolls out the workload once the agent config changes.
+	checksumAnnotation = "remote-write.kusionstack.io/checksum"
+)
+
+var (
+	ErrInvalidMode            = errors.New("mode must be pushgateway or agent")
+	ErrEmptyURL               = errors.New("empty remote write url in the platform config")
+	ErrEmptyPushgatewayURL    = errors.New("empty pushgatewayURL in the platform config")
+	ErrInvalidURL             = errors.New("url must be an absolute http or https url")
+	ErrInvalidPort            = errors.New("port must be between 1 and 65535")
+	ErrEmptyPort              = errors.New("port of the metrics must be set in the agent mode")
+	ErrInvalidInterval        = errors.New("interval must be a duration, e.g. 30s or 1m")
+	ErrConflictingCredentials = errors.New("basicAuth and bearerToken must not be both set")
+	ErrEmptyBasicAuthUsername = errors.New("empty username of basicAuth")
+	ErrAgentForJob            = errors.New("agent mode is not supported by the job workload, which never completes with the sidecar")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, RemoteWrite{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&RemoteWrite{})
+}
+
+// RemoteWrite describes how the application delivers its metrics to the metrics backend of the
+// platform, instead of being scraped by Prometheus. The batch jobs push the metrics to the
+// Pushgateway with the injected env vars, and the other workloads run the Prometheus agent sidecar
+// forwarding the metrics by remote write.
+type RemoteWrite struct {
+	// Mode is pushgateway or agent, which defaults to pushgateway for the job workload and agent
+	// for the others.
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	// Port is the port of the metrics exposed by the workload and scraped by the agent sidecar.
+	Port int `json:"port,omitempty" yaml:"port,omitempty"`
+	// Path is the path of the metrics scraped by the agent sidecar, which defaults to /metrics.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Interval is the scrape interval of the agent sidecar, which defaults to 30s.
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+	// Labels are the external labels attached to the metrics forwarded by the agent sidecar, along
+	// with the project, stack and app labels.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	// The platform config of the remote_write module.
+	platform PlatformConfig
+}
+
+// PlatformConfig describes the platform config of the remote_write module in workspace.
+type PlatformConfig struct {
+	// URL is the remote write endpoint of the metrics backend, e.g.
+	// https://mimir.example.com/api/v1/push, which is required by the agent mode.
+	URL string `json:"url,omitempty" yaml:"url,omitempty"`
+	// PushgatewayURL is the url of the Pushgateway, which is required by the pushgateway mode.
+	PushgatewayURL string `json:"pushgatewayURL,omitempty" yaml:"pushgatewayURL,omitempty"`
+	// BasicAuth is the basic auth credentials of the remote write endpoint or the Pushgateway.
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+	// BearerToken is the bearer token of the remote write endpoint or the Pushgateway.
+	BearerToken string `json:"bearerToken,omitempty" yaml:"bearerToken,omitempty"`
+	// Tenant is sent in the X-Scope-OrgID header of the remote write requests to the multi-tenant
+	// backends such as Mimir and Cortex, where "$project" is replaced by the project name.
+	Tenant string `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	// AgentImage is the image of the agent sidecar, which defaults to prom/prometheus:v2.53.0.
+	AgentImage string `json:"agentImage,omitempty" yaml:"agentImage,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *RemoteWrite `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the containers and volumes patched into the
+	// workload are checked against, i.e. privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+}
+
+// BasicAuth describes the basic auth credentials.
+type BasicAuth struct {
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+}
+
+// Generate implements the generation logic of the remote_write module.
+func (remoteWrite *RemoteWrite) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "remote_write", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
+	defer func() {
+		if err == nil {
+			if err = moduleutil.Finalize("remote_write", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// RemoteWrite does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("RemoteWrite does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the remote_write module.
+	if err := remoteWrite.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("remote_write", moduleutil.PhaseComplete, err)
+	}
+	if err := remoteWrite.completeMode(request.Workload); err != nil {
+		return nil, moduleutil.NewModuleError("remote_write", moduleutil.PhaseValidate, err)
+	}
+
+	// Generate the credentials Secret, and deliver it to the workload by the Pushgateway env vars
+	// or the agent sidecar.
+	secret, err := moduleutil.WrapK8sResource(remoteWrite.generateSecret(request))
+	if err != nil {
+		return nil, err
+	}
+	resources := []kusionapiv1.Resource{*secret}
+
+	if remoteWrite.Mode == ModePushgateway {
+		return &module.GeneratorResponse{
+			Resources: resources,
+			Patcher:   remoteWrite.generatePushgatewayPatcher(request),
+		}, nil
+	}
+
+	configMap, err := remoteWrite.generateAgentConfigMap(request)
+	if err != nil {
+		return nil, err
+	}
+	configMapResource, err := moduleutil.WrapK8sResource(configMap)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, *configMapResource)
+
+	patcher, err := remoteWrite.generateAgentPatcher(request, configMap)
+	if err != nil {
+		return nil, err
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   patcher,
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the remote_write module.
+func (remoteWrite *RemoteWrite) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, RemoteWrite{}); err != nil {
+		return moduleutil.NewModuleError("remote_write", moduleutil.PhaseValidate, fmt.Errorf("validate remote_write dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("remote_write", moduleutil.PhaseValidate, fmt.Errorf("validate remote_write platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, remoteWrite); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &remoteWrite.platform); err != nil {
+			return err
+		}
+	}
+
+	if remoteWrite.Path == "" {
+		remoteWrite.Path = defaultPath
+	}
+	if remoteWrite.Interval == "" {
+		remoteWrite.Interval = defaultInterval
+	}
+	if remoteWrite.platform.AgentImage == "" {
+		remoteWrite.platform.AgentImage = defaultAgentImage
+	}
+
+	return remoteWrite.Validate()
+}
+
+// Validate validates whether the configs of the remote_write module are valid.
+func (remoteWrite *RemoteWrite) Validate() error {
+	switch remoteWrite.Mode {
+	case "", ModePushgateway, ModeAgent:
+	default:
+		return fmt.Errorf("%w, got %s", ErrInvalidMode, remoteWrite.Mode)
+	}
+	if remoteWrite.Port < 0 || remoteWrite.Port > 65535 {
+		return fmt.Errorf("%w, got %d", ErrInvalidPort, remoteWrite.Port)
+	}
+	if _, err := time.ParseDuration(remoteWrite.Interval); err != nil {
+		return fmt.Errorf("%w, got %s", ErrInvalidInterval, remoteWrite.Interval)
+	}
+
+	platform := remoteWrite.platform
+	for _, field := range []struct{ path, value string }{
+		{"url", platform.URL},
+		{"pushgatewayURL", platform.PushgatewayURL},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if u, err := url.Parse(field.value); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w, %w", ErrInvalidURL, &moduleutil.ConfigFieldError{Path: field.path, Reason: "got " + field.value})
+		}
+	}
+	if platform.BasicAuth != nil {
+		if platform.BearerToken != "" {
+			return ErrConflictingCredentials
+		}
+		if platform.BasicAuth.Username == "" {
+			return ErrEmptyBasicAuthUsername
+		}
+	}
+	return nil
+}
+
+// completeMode defaults the mode by the workload, and validates the configs required by the mode.
+func (remoteWrite *RemoteWrite) completeMode(workload kusionapiv1.Accessory) error {
+	isJob := false
+	if kind, _ := workload["_type"].(string); strings.Contains(kind, ".Job") {
+		isJob = true
+	}
+	if remoteWrite.Mode == "" {
+		remoteWrite.Mode = ModeAgent
+		if isJob {
+			remoteWrite.Mode = ModePushgateway
+		}
+	}
+
+	if remoteWrite.Mode == ModePushgateway {
+		if remoteWrite.platform.PushgatewayURL == "" {
+			return ErrEmptyPushgatewayURL
+		}
+		return nil
+	}
+	if isJob {
+		return ErrAgentForJob
+	}
+	if remoteWrite.platform.URL == "" {
+		return ErrEmptyURL
+	}
+	if remoteWrite.Port == 0 {
+		return ErrEmptyPort
+	}
+	return nil
+}
+
+// resourceName returns the name of the credentials Secret and the agent ConfigMap.
+func resourceName(request *module.GeneratorRequest) string {
+	return moduleutil.AppName(request) + nameSuffix
+}
+
+// generateSecret generates the Secret of the url and the credentials of the remote write endpoint
+// or the Pushgateway.
+func (remoteWrite *RemoteWrite) generateSecret(request *module.GeneratorRequest) *v1.Secret {
+	platform := remoteWrite.platform
+	data := map[string]string{urlKey: platform.URL}
+	if remoteWrite.Mode == ModePushgateway {
+		data[urlKey] = platform.PushgatewayURL
+	}
+	if platform.BasicAuth != nil {
+		data[usernameKey] = platform.BasicAuth.Username
+		data[passwordKey] = platform.BasicAuth.Password
+	}
+	if platform.BearerToken != "" {
+		data[bearerTokenKey] = platform.BearerToken
+	}
+
+	return &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceName(request),
+			Namespace: request.Project,
+		},
+		Type:       v1.SecretTypeOpaque,
+		StringData: data,
+	}
+}
+
+// generatePushgatewayPatcher generates the patcher injecting the url, the job name and the
+// credentials of the Pushgateway into the containers of the workload.
+func (remoteWrite *RemoteWrite) generatePushgatewayPatcher(request *module.GeneratorRequest) *kusionapiv1.Patcher {
+	secretEnv := func(name, key string) v1.EnvVar {
+		return v1.EnvVar{
+			Name: name,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: resourceName(request)},
+					Key:                  key,
+				},
+			},
+		}
+	}
+
+	envs := []v1.EnvVar{
+		secretEnv(pushgatewayURLEnv, urlKey),
+		{Name: pushgatewayJobEnv, Value: moduleutil.AppName(request)},
+	}
+	platform := remoteWrite.platform
+	if platform.BasicAuth != nil {
+		envs = append(envs, secretEnv(pushgatewayUsernameEnv, usernameKey), secretEnv(pushgatewayPasswordEnv, passwordKey))
+	}
+	if platform.BearerToken != "" {
+		envs = append(envs, secretEnv(pushgatewayBearerTokenEnv, bearerTokenKey))
+	}
+	return &kusionapiv1.Patcher{Environments: envs}
+}
+
+// agentConfig is the config of the Prometheus agent scraping the workload on localhost.
+type agentConfig struct {
+	Global        agentGlobalConfig   `yaml:"global"`
+	ScrapeConfigs []agentScrapeConfig `yaml:"scrape_configs"`
+	RemoteWrite   []agentRemoteWrite  `yaml:"remote_write"`
+}
+
+type agentGlobalConfig struct {
+	ScrapeInterval string            `yaml:"scrape_interval"`
+	ExternalLabels map[string]string `yaml:"external_labels,omitempty"`
+}
+
+type agentScrapeConfig struct {
+	JobName       string              `yaml:"job_name"`
+	MetricsPath   string              `yaml:"metrics_path"`
+	StaticConfigs []agentStaticConfig `yaml:"static_configs"`
+}
+
+type agentStaticConfig struct {
+	Targets []string `yaml:"targets"`
+}
+
+type agentRemoteWrite struct {
+	URL           string            `yaml:"url"`
+	BasicAuth     map[string]string `yaml:"basic_auth,omitempty"`
+	Authorization map[string]string `yaml:"authorization,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"`
+}
+
+// agentConfig returns the config of the agent sidecar, which reads the credentials from the files
+// of the mounted Secret instead of embedding them in the ConfigMap.
+func (remoteWrite *RemoteWrite) agentConfig(request *module.GeneratorRequest) agentConfig {
+	labels := map[string]string{
+		"project": request.Project,
+		"stack":   request.Stack,
+		"app":     request.App,
+	}
+	for k, v := range remoteWrite.Labels {
+		labels[k] = v
+	}
+
+	platform := remoteWrite.platform
+	target := agentRemoteWrite{URL: platform.URL}
+	if platform.BasicAuth != nil {
+		target.BasicAuth = map[string]string{
+			"username":      platform.BasicAuth.Username,
+			"password_file": credentialsDir + "/" + passwordKey,
+		}
+	}
+	if platform.BearerToken != "" {
+		target.Authorization = map[string]string{
+			"credentials_file": credentialsDir + "/" + bearerTokenKey,
+		}
+	}
+	if platform.Tenant != "" {
+		target.Headers = map[string]string{
+			tenantHeader: strings.ReplaceAll(platform.Tenant, "$project", request.Project),
+		}
+	}
+
+	return agentConfig{
+		Global: agentGlobalConfig{
+			ScrapeInterval: remoteWrite.Interval,
+			ExternalLabels: labels,
+		},
+		ScrapeConfigs: []agentScrapeConfig{
+			{
+				JobName:     moduleutil.AppName(request),
+				MetricsPath: remoteWrite.Path,
+				StaticConfigs: []agentStaticConfig{
+					{Targets: []string{"localhost:" + strconv.Itoa(remoteWrite.Port)}},
+				},
+			},
+		},
+		RemoteWrite: []agentRemoteWrite{target},
+	}
+}
+
+// generateAgentConfigMap generates the ConfigMap of the agent config.
+func (remoteWrite *RemoteWrite) generateAgentConfigMap(request *module.GeneratorRequest) (*v1.ConfigMap, error) {
+	config, err := yaml.Marshal(remoteWrite.agentConfig(request))
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      resourceName(request),
+			Namespace: request.Project,
+		},
+		Data: map[string]string{agentConfigFile: string(config)},
+	}, nil
+}
+
+// agentContainer returns the Prometheus agent sidecar, which runs as the nobody user under the
+// restricted Pod Security Standards.
+func (remoteWrite *RemoteWrite) agentContainer() v1.Container {
+	allowPrivilegeEscalation := false
+	runAsNonRoot := true
+	readOnlyRootFilesystem := true
+	uid := int64(agentUID)
+	return v1.Container{
+		Name:  agentContainerName,
+		Image: remoteWrite.platform.AgentImage,
+		Args: []string{
+			"--enable-feature=agent",
+			"--config.file=" + agentConfigDir + "/" + agentConfigFile,
+			"--storage.agent.path=" + walDir,
+		},
+		VolumeMounts: []v1.VolumeMount{
+			{Name: "remote-write-config", MountPath: agentConfigDir, ReadOnly: true},
+			{Name: "remote-write-credentials", MountPath: credentialsDir, ReadOnly: true},
+			{Name: "remote-write-wal", MountPath: walDir},
+		},
+		Resources: v1.ResourceRequirements{
+			Requests: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse("50m"),
+				v1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: v1.ResourceList{
+				v1.ResourceMemory: resource.MustParse("256Mi"),
+			},
+		},
+		SecurityContext: &v1.SecurityContext{
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+			RunAsNonRoot:             &runAsNonRoot,
+			RunAsUser:                &uid,
+			ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+			Capabilities:             &v1.Capabilities{Drop: []v1.Capability{"ALL"}},
+			SeccompProfile:           &v1.SeccompProfile{Type: v1.SeccompProfileTypeRuntimeDefault},
+		},
+	}
+}
+
+// agentVolumes returns the volumes of the agent config, the credentials and the WAL.
+func agentVolumes(request *module.GeneratorRequest) []v1.Volume {
+	return []v1.Volume{
+		{
+			Name: "remote-write-config",
+			VolumeSource: v1.VolumeSource{
+				ConfigMap: &v1.ConfigMapVolumeSource{
+					LocalObjectReference: v1.LocalObjectReference{Name: resourceName(request)},
+				},
+			},
+		},
+		{
+			Name: "remote-write-credentials",
+			VolumeSource: v1.VolumeSource{
+				Secret: &v1.SecretVolumeSource{SecretName: resourceName(request)},
+			},
+		},
+		{
+			Name:         "remote-write-wal",
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		},
+	}
+}
+
+// generateAgentPatcher generates the JSON patch adding the agent sidecar and its volumes to the pod
+// template of the workload, and the pod annotation of the checksum of the agent config.
+func (remoteWrite *RemoteWrite) generateAgentPatcher(request *module.GeneratorRequest, configMap *v1.ConfigMap) (*kusionapiv1.Patcher, error) {
+	sum := sha256.Sum256([]byte(configMap.Data[agentConfigFile]))
+	patcher := &kusionapiv1.Patcher{
+		PodAnnotations: map[string]string{checksumAnnotation: hex.EncodeToString(sum[:])},
+	}
+	if request.Workload == nil {
+		return patcher, nil
+	}
+	typeMeta, podSpecPath, err := moduleutil.WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.AppName(request),
+		Namespace: request.Project,
+	}
+
+	operations := []map[string]interface{}{
+		{
+			"op":    "add",
+			"path":  podSpecPath + "/containers/-",
+			"value": remoteWrite.agentContainer(),
+		},
+	}
+	// Append to the volumes of the pod spec if declared, or the patch replaces them.
+	volumes := agentVolumes(request)
+	if moduleutil.WorkloadDeclaresVolumes(request.Workload) {
+		for _, volume := range volumes {
+			operations = append(operations, map[string]interface{}{
+				"op":    "add",
+				"path":  podSpecPath + "/volumes/-",
+				"value": volume,
+			})
+		}
+	} else {
+		operations = append(operations, map[string]interface{}{
+			"op":    "add",
+			"path":  podSpecPath + "/volumes",
+			"value": volumes,
+		})
+	}
+
+	payload, err := json.Marshal(operations)
+	if err != nil {
+		return nil, err
+	}
+	patcher.JSONPatchers = map[string]kusionapiv1.JSONPatcher{
+		module.KubernetesResourceID(typeMeta, objectMeta): {
+			Type:    kusionapiv1.JSONPatch,
+			Payload: payload,
+		},
+	}
+	return patcher, nil
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+func TestRemoteWrite_Generate(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"url":            "https://mimir.example.com/api/v1/push",
+		"pushgatewayURL": "http://pushgateway.monitoring:9091",
+		"basicAuth":      map[string]interface{}{"username": "tenant", "password": "secret"},
+		"tenant":         "$project",
+	}
+
+	tests := []struct {
+		name           string
+		job            bool
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		expectedPhase  moduleutil.Phase
+		expectedErr    error
+		expectedKinds  []string
+	}{
+		{
+			name:           "agent of service",
+			devConfig:      kusionapiv1.Accessory{"port": 8080},
+			platformConfig: platformConfig,
+			expectedKinds:  []string{"Secret", "ConfigMap"},
+		},
+		{
+			name:           "pushgateway of job",
+			job:            true,
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: platformConfig,
+			expectedKinds:  []string{"Secret"},
+		},
+		{
+			name:           "agent of job",
+			job:            true,
+			devConfig:      kusionapiv1.Accessory{"mode": "agent", "port": 8080},
+			platformConfig: platformConfig,
+			expectedPhase:  moduleutil.PhaseValidate,
+			expectedErr:    ErrAgentForJob,
+		},
+		{
+			name:           "agent without port",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: platformConfig,
+			expectedPhase:  moduleutil.PhaseValidate,
+			expectedErr:    ErrEmptyPort,
+		},
+		{
+			name:           "pushgateway without url",
+			job:            true,
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: kusionapiv1.GenericConfig{"url": "https://mimir.example.com/api/v1/push"},
+			expectedPhase:  moduleutil.PhaseValidate,
+			expectedErr:    ErrEmptyPushgatewayURL,
+		},
+		{
+			name:           "invalid mode",
+			devConfig:      kusionapiv1.Accessory{"mode": "scrape"},
+			platformConfig: platformConfig,
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrInvalidMode,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := testutil.NewRequest().WithServiceWorkload("Deployment")
+			if tt.job {
+				builder = builder.WithJobWorkload()
+			}
+			request := builder.WithDevConfig(tt.devConfig).WithPlatformConfig(tt.platformConfig).Build()
+
+			response, err := (&RemoteWrite{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedKinds)) {
+				return
+			}
+			for i, kind := range tt.expectedKinds {
+				assert.Equal(t, kind, response.Resources[i].Attributes["kind"])
+			}
+		})
+	}
+}
+
+func TestRemoteWrite_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		remoteWrite RemoteWrite
+		expectedErr error
+	}{
+		{
+			name:        "valid",
+			remoteWrite: RemoteWrite{Port: 8080, Interval: "1m", platform: PlatformConfig{URL: "https://mimir.example.com/api/v1/push"}},
+		},
+		{
+			name:        "invalid port",
+			remoteWrite: RemoteWrite{Port: 65536, Interval: "1m"},
+			expectedErr: ErrInvalidPort,
+		},
+		{
+			name:        "invalid interval",
+			remoteWrite: RemoteWrite{Interval: "1 minute"},
+			expectedErr: ErrInvalidInterval,
+		},
+		{
+			name:        "invalid url",
+			remoteWrite: RemoteWrite{Interval: "1m", platform: PlatformConfig{PushgatewayURL: "pushgateway:9091"}},
+			expectedErr: ErrInvalidURL,
+		},
+		{
+			name: "conflicting credentials",
+			remoteWrite: RemoteWrite{Interval: "1m", platform: PlatformConfig{
+				BasicAuth:   &BasicAuth{Username: "tenant", Password: "secret"},
+				BearerToken: "token",
+			}},
+			expectedErr: ErrConflictingCredentials,
+		},
+		{
+			name:        "empty username",
+			remoteWrite: RemoteWrite{Interval: "1m", platform: PlatformConfig{BasicAuth: &BasicAuth{Password: "secret"}}},
+			expectedErr: ErrEmptyBasicAuthUsername,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.remoteWrite.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRemoteWrite_GeneratePushgatewayPatcher(t *testing.T) {
+	request := testutil.NewRequest().WithJobWorkload().Build()
+	remoteWrite := &RemoteWrite{
+		Mode:     ModePushgateway,
+		platform: PlatformConfig{PushgatewayURL: "http://pushgateway.monitoring:9091", BearerToken: "token"},
+	}
+
+	secret := remoteWrite.generateSecret(request)
+	assert.Equal(t, "default-dev-foo-remote-write", secret.Name)
+	assert.Equal(t, map[string]string{urlKey: "http://pushgateway.monitoring:9091", bearerTokenKey: "token"}, secret.StringData)
+
+	patcher := remoteWrite.generatePushgatewayPatcher(request)
+	if !assert.Len(t, patcher.Environments, 3) {
+		return
+	}
+	assert.Equal(t, pushgatewayURLEnv, patcher.Environments[0].Name)
+	assert.Equal(t, urlKey, patcher.Environments[0].ValueFrom.SecretKeyRef.Key)
+	assert.Equal(t, "default-dev-foo", patcher.Environments[1].Value)
+	assert.Equal(t, pushgatewayBearerTokenEnv, patcher.Environments[2].Name)
+}
+
+func TestRemoteWrite_GenerateAgentConfigMap(t *testing.T) {
+	request := testutil.NewRequest().WithServiceWorkload("Deployment").Build()
+	remoteWrite := &RemoteWrite{
+		Port:     8080,
+		Path:     defaultPath,
+		Interval: defaultInterval,
+		Labels:   map[string]string{"team": "payments"},
+		platform: PlatformConfig{
+			URL:       "https://mimir.example.com/api/v1/push",
+			BasicAuth: &BasicAuth{Username: "tenant", Password: "secret"},
+			Tenant:    "$project",
+		},
+	}
+
+	configMap, err := remoteWrite.generateAgentConfigMap(request)
+	assert.NoError(t, err)
+	assert.NotContains(t, configMap.Data[agentConfigFile], "secret")
+
+	config := agentConfig{}
+	assert.NoError(t, yaml.Unmarshal([]byte(configMap.Data[agentConfigFile]), &config))
+	assert.Equal(t, "payments", config.Global.ExternalLabels["team"])
+	assert.Equal(t, []string{"localhost:8080"}, config.ScrapeConfigs[0].StaticConfigs[0].Targets)
+	assert.Equal(t, agentRemoteWrite{
+		URL:       "https://mimir.example.com/api/v1/push",
+		BasicAuth: map[string]string{"username": "tenant", "password_file": "/etc/remote-write/password"},
+		Headers:   map[string]string{tenantHeader: testutil.DefaultProject},
+	}, config.RemoteWrite[0])
+}
+
+func TestRemoteWrite_GenerateAgentPatcher(t *testing.T) {
+	remoteWrite := &RemoteWrite{Port: 8080, platform: PlatformConfig{AgentImage: defaultAgentImage}}
+
+	tests := []struct {
+		name          string
+		workload      kusionapiv1.Accessory
+		expectedID    string
+		expectedPaths []string
+	}{
+		{
+			name:          "deployment without volumes",
+			workload:      kusionapiv1.Accessory{"_type": "service.Service", "type": "Deployment"},
+			expectedID:    "apps/v1:Deployment:default:default-dev-foo",
+			expectedPaths: []string{"/spec/template/spec/containers/-", "/spec/template/spec/volumes"},
+		},
+		{
+			name: "collaset with files",
+			workload: kusionapiv1.Accessory{
+				"_type": "service.Service",
+				"type":  "CollaSet",
+				"containers": map[string]interface{}{
+					"main": map[string]interface{}{"files": map[string]interface{}{"/etc/app.conf": map[string]interface{}{}}},
+				},
+			},
+			expectedID: "apps.kusionstack.io/v1alpha1:CollaSet:default:default-dev-foo",
+			expectedPaths: []string{
+				"/spec/template/spec/containers/-",
+				"/spec/template/spec/volumes/-",
+				"/spec/template/spec/volumes/-",
+				"/spec/template/spec/volumes/-",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().WithWorkload(tt.workload).Build()
+			configMap, err := remoteWrite.generateAgentConfigMap(request)
+			assert.NoError(t, err)
+
+			patcher, err := remoteWrite.generateAgentPatcher(request, configMap)
+			assert.NoError(t, err)
+			assert.Len(t, patcher.PodAnnotations[checksumAnnotation], 64)
+			if !assert.Contains(t, patcher.JSONPatchers, tt.expectedID) {
+				return
+			}
+
+			var operations []map[string]interface{}
+			assert.NoError(t, json.Unmarshal(patcher.JSONPatchers[tt.expectedID].Payload, &operations))
+			var paths []string
+			for _, op := range operations {
+				paths = append(paths, op["path"].(string))
+			}
+			assert.Equal(t, tt.expectedPaths, paths)
+			assert.Equal(t, agentContainerName, operations[0]["value"].(map[string]interface{})["name"])
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+var (
+	ErrInvalidReloadURL       = errors.New("url of configReload must be an http or https URL, e.g. http://localhost:8080/-/reload")
+	ErrInvalidReloadMethod    = errors.New("method of configReload must be GET, POST or PUT")
+	ErrUnknownReloadVolume    = errors.New("volumes of configReload must be the ConfigMap volumes of the workload")
+	ErrEmptyReloadVolumes     = errors.New("configReload requires at least one ConfigMap volume to watch")
+	ErrWindowsConfigReload    = errors.New("configReload is not supported by the windows workload")
+	configReloadMethods       = []string{"GET", "POST", "PUT"}
+	configReloadSchemes       = []string{"http", "https"}
+	defaultConfigReloadMethod = "POST"
+)
+
+const (
+	configReloaderName      = "config-reloader"
+	configReloadMountPrefix = "/etc/config-reload"
+	defaultConfigReloader   = "ghcr.io/jimmidyson/configmap-reload:v0.14.0"
+
+	// configReloaderUser is the nobody user the configmap-reload image runs as.
+	configReloaderUser = int64(65534)
+)
+
+// defaultConfigReloaderResources are the resources of the config-reloader sidecar, which only
+// watches the files and calls the reload endpoint.
+var defaultConfigReloaderResources = map[string]string{
+	"cpu":    "10m-100m",
+	"memory": "16Mi-64Mi",
+}
+
+// completeConfigReload completes the config reload with the image, resources and method of the
+// config-reloader sidecar from workspace, and validates it.
+func completeConfigReload(base *Base, config kusionapiv1.GenericConfig) error {
+	reload := base.ConfigReload
+	if reload == nil {
+		return nil
+	}
+	if base.OS == OSWindows {
+		return ErrWindowsConfigReload
+	}
+	if value, ok := config[FieldConfigReload]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		platform := &ConfigReload{}
+		if err = yaml.Unmarshal(out, platform); err != nil {
+			return fmt.Errorf("invalid configReload config in workspace, %w", err)
+		}
+		if reload.Image == "" {
+			reload.Image = platform.Image
+		}
+		if reload.Resources == nil {
+			reload.Resources = platform.Resources
+		}
+		if reload.Method == "" {
+			reload.Method = platform.Method
+		}
+	}
+	if reload.Image == "" {
+		reload.Image = defaultConfigReloader
+	}
+	if reload.Resources == nil {
+		reload.Resources = defaultConfigReloaderResources
+	}
+	if reload.Method == "" {
+		reload.Method = defaultConfigReloadMethod
+	}
+
+	u, err := url.Parse(reload.URL)
+	if err != nil || !slices.Contains(configReloadSchemes, u.Scheme) || u.Host == "" {
+		return fmt.Errorf("%w, got %q", ErrInvalidReloadURL, reload.URL)
+	}
+	if !slices.Contains(configReloadMethods, reload.Method) {
+		return fmt.Errorf("%w, got %q", ErrInvalidReloadMethod, reload.Method)
+	}
+	for _, name := range reload.Volumes {
+		if v, ok := base.Volumes[name]; !ok || v.ConfigMap == nil {
+			return fmt.Errorf("%w, got %s", ErrUnknownReloadVolume, name)
+		}
+	}
+	return nil
+}
+
+// mountConfigReloader appends the config-reloader sidecar to the containers, which mounts the
+// ConfigMap volumes of the pod, and calls the reload endpoint of the application once the
+// ConfigMaps are updated and synced into the volumes by the kubelet. The ConfigMap volumes of
+// the files of the containers and the workload are all watched if the volumes are not declared.
+func mountConfigReloader(reload *ConfigReload, containers []corev1.Container, volumes []corev1.Volume) ([]corev1.Container, error) {
+	if reload == nil {
+		return containers, nil
+	}
+
+	args := []string{"--webhook-url=" + reload.URL, "--webhook-method=" + reload.Method}
+	var mounts []corev1.VolumeMount
+	for _, v := range volumes {
+		if !reload.watches(v) {
+			continue
+		}
+		mountPath := path.Join(configReloadMountPrefix, v.Name)
+		mounts = append(mounts, corev1.VolumeMount{Name: v.Name, MountPath: mountPath, ReadOnly: true})
+		args = append(args, "--volume-dir="+mountPath)
+	}
+	if len(mounts) == 0 {
+		return nil, ErrEmptyReloadVolumes
+	}
+
+	resources, err := handleResourceRequirementsV1(reload.Resources)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resources of config-reloader, %w", err)
+	}
+	nonRoot, readOnly, privileged := true, true, false
+	user := configReloaderUser
+	return append(containers, corev1.Container{
+		Name:         configReloaderName,
+		Image:        reload.Image,
+		Args:         args,
+		Resources:    resources,
+		VolumeMounts: mounts,
+		// The sidecar satisfies the restricted level of the Pod Security Standards.
+		SecurityContext: &corev1.SecurityContext{
+			RunAsNonRoot:             &nonRoot,
+			RunAsUser:                &user,
+			ReadOnlyRootFilesystem:   &readOnly,
+			AllowPrivilegeEscalation: &privileged,
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+	}), nil
+}
+
+// watches returns whether the volume is watched by the config-reloader, i.e. the declared
+// ConfigMap volume, or any ConfigMap volume if the volumes are not declared.
+func (reload *ConfigReload) watches(v corev1.Volume) bool {
+	return v.ConfigMap != nil && (len(reload.Volumes) == 0 || slices.Contains(reload.Volumes, v.Name))
+}
+
+// unwatchedConfigMaps returns the ConfigMaps not mounted by the volumes watched by the
+// config-reloader. The watched ones are reloaded in place by the application, so they are left
+// out of the config checksum, which would restart the pods otherwise.
+func unwatchedConfigMaps(reload *ConfigReload, configMaps []corev1.ConfigMap, volumes []corev1.Volume) []corev1.ConfigMap {
+	if reload == nil {
+		return configMaps
+	}
+	var watched []string
+	for _, v := range volumes {
+		if reload.watches(v) {
+			watched = append(watched, v.ConfigMap.Name)
+		}
+	}
+	var result []corev1.ConfigMap
+	for _, cm := range configMaps {
+		if !slices.Contains(watched, cm.Name) {
+			result = append(result, cm)
+		}
+	}
+	return result
+}
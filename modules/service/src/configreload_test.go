@@ -0,0 +1,192 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestCompleteConfigReload(t *testing.T) {
+	volumes := map[string]Volume{
+		"rules": {ConfigMap: &ConfigMapVolumeSource{Name: "prometheus-rules"}},
+		"data":  {EmptyDir: &EmptyDirVolumeSource{}},
+	}
+	tests := []struct {
+		name    string
+		base    *Base
+		config  kusionapiv1.GenericConfig
+		want    *ConfigReload
+		wantErr error
+	}{
+		{
+			name:   "no config reload",
+			base:   &Base{},
+			config: kusionapiv1.GenericConfig{},
+		},
+		{
+			name:   "defaults",
+			base:   &Base{ConfigReload: &ConfigReload{URL: "http://localhost:9090/-/reload"}},
+			config: kusionapiv1.GenericConfig{},
+			want: &ConfigReload{
+				URL:       "http://localhost:9090/-/reload",
+				Method:    "POST",
+				Image:     defaultConfigReloader,
+				Resources: defaultConfigReloaderResources,
+			},
+		},
+		{
+			name: "defaults in workspace",
+			base: &Base{
+				Volumes:      volumes,
+				ConfigReload: &ConfigReload{URL: "http://localhost:9090/-/reload", Volumes: []string{"rules"}},
+			},
+			config: kusionapiv1.GenericConfig{
+				"configReload": map[string]any{
+					"image":     "registry.example.com/configmap-reload:v0.14.0",
+					"method":    "PUT",
+					"resources": map[string]any{"cpu": "50m", "memory": "32Mi"},
+				},
+			},
+			want: &ConfigReload{
+				URL:       "http://localhost:9090/-/reload",
+				Method:    "PUT",
+				Volumes:   []string{"rules"},
+				Image:     "registry.example.com/configmap-reload:v0.14.0",
+				Resources: map[string]string{"cpu": "50m", "memory": "32Mi"},
+			},
+		},
+		{
+			name:    "invalid url",
+			base:    &Base{ConfigReload: &ConfigReload{URL: "localhost:9090/-/reload"}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrInvalidReloadURL,
+		},
+		{
+			name:    "invalid method",
+			base:    &Base{ConfigReload: &ConfigReload{URL: "http://localhost:9090/-/reload", Method: "DELETE"}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrInvalidReloadMethod,
+		},
+		{
+			name: "unknown volume",
+			base: &Base{
+				Volumes:      volumes,
+				ConfigReload: &ConfigReload{URL: "http://localhost:9090/-/reload", Volumes: []string{"data"}},
+			},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrUnknownReloadVolume,
+		},
+		{
+			name:    "windows",
+			base:    &Base{OS: OSWindows, ConfigReload: &ConfigReload{URL: "http://localhost:9090/-/reload"}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrWindowsConfigReload,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeConfigReload(tt.base, tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.ConfigReload)
+		})
+	}
+}
+
+func TestMountConfigReloader(t *testing.T) {
+	volumes := []corev1.Volume{
+		{
+			Name: "default-dev-foo-main-0",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "default-dev-foo-main-0"}},
+			},
+		},
+		{
+			Name:         "data",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+		{
+			Name: "rules",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "prometheus-rules"}},
+			},
+		},
+	}
+	reload := &ConfigReload{
+		URL:       "http://localhost:9090/-/reload",
+		Method:    "POST",
+		Image:     defaultConfigReloader,
+		Resources: map[string]string{"cpu": "10m-100m"},
+	}
+
+	containers, err := mountConfigReloader(reload, []corev1.Container{{Name: "main"}}, volumes)
+	assert.NoError(t, err)
+	assert.Len(t, containers, 2)
+	reloader := containers[1]
+	assert.Equal(t, configReloaderName, reloader.Name)
+	assert.Equal(t, defaultConfigReloader, reloader.Image)
+	assert.Equal(t, []string{
+		"--webhook-url=http://localhost:9090/-/reload",
+		"--webhook-method=POST",
+		"--volume-dir=/etc/config-reload/default-dev-foo-main-0",
+		"--volume-dir=/etc/config-reload/rules",
+	}, reloader.Args)
+	assert.Equal(t, []corev1.VolumeMount{
+		{Name: "default-dev-foo-main-0", MountPath: "/etc/config-reload/default-dev-foo-main-0", ReadOnly: true},
+		{Name: "rules", MountPath: "/etc/config-reload/rules", ReadOnly: true},
+	}, reloader.VolumeMounts)
+	assert.Equal(t, resource.MustParse("10m"), reloader.Resources.Requests[corev1.ResourceCPU])
+	assert.Equal(t, resource.MustParse("100m"), reloader.Resources.Limits[corev1.ResourceCPU])
+	assert.True(t, *reloader.SecurityContext.RunAsNonRoot)
+	assert.False(t, *reloader.SecurityContext.AllowPrivilegeEscalation)
+
+	// Only the declared volumes are watched.
+	reload.Volumes = []string{"rules"}
+	containers, err = mountConfigReloader(reload, []corev1.Container{{Name: "main"}}, volumes)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--webhook-url=http://localhost:9090/-/reload",
+		"--webhook-method=POST",
+		"--volume-dir=/etc/config-reload/rules",
+	}, containers[1].Args)
+
+	_, err = mountConfigReloader(reload, []corev1.Container{{Name: "main"}}, volumes[1:2])
+	assert.ErrorIs(t, err, ErrEmptyReloadVolumes)
+
+	containers, err = mountConfigReloader(nil, []corev1.Container{{Name: "main"}}, volumes)
+	assert.NoError(t, err)
+	assert.Len(t, containers, 1)
+}
+
+func TestUnwatchedConfigMaps(t *testing.T) {
+	volumes := []corev1.Volume{
+		{
+			Name: "main",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "default-dev-foo-main-0"}},
+			},
+		},
+		{
+			Name: "rules",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "default-dev-foo-rules"}},
+			},
+		},
+	}
+	configMaps := []corev1.ConfigMap{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-main-0"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-rules"}},
+	}
+
+	assert.Equal(t, configMaps, unwatchedConfigMaps(nil, configMaps, volumes))
+	assert.Equal(t, configMaps[:1], unwatchedConfigMaps(&ConfigReload{Volumes: []string{"rules"}}, configMaps, volumes))
+	assert.Empty(t, unwatchedConfigMaps(&ConfigReload{}, configMaps, volumes))
+}
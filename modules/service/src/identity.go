@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+var (
+	ErrUnsupportedIdentityDriver = errors.New("driver of identity must be cert-manager or spiffe")
+	ErrEmptyIdentityIssuer       = errors.New("issuer of identity must be specified for the cert-manager driver")
+	ErrInvalidIdentityDuration   = errors.New("duration and renewBefore of identity must be valid durations, e.g. 24h")
+)
+
+// The CSI drivers issuing the workload identity certificates.
+const (
+	IdentityDriverCertManager = "cert-manager"
+	IdentityDriverSPIFFE      = "spiffe"
+)
+
+const (
+	identityVolumeName       = "workload-identity"
+	defaultIdentityMountPath = "/var/run/secrets/workload-identity"
+	certManagerCSIDriver     = "csi.cert-manager.io"
+	spiffeCSIDriver          = "spiffe.csi.cert-manager.io"
+	certManagerIssuerKind    = "Issuer"
+	certManagerGroup         = "cert-manager.io"
+
+	// defaultIdentityURI is the SPIFFE ID of the pods signed into the certificates of the
+	// cert-manager driver, which is the same as the one issued by the spiffe driver.
+	defaultIdentityURI = "spiffe://cluster.local/ns/${POD_NAMESPACE}/sa/${SERVICE_ACCOUNT_NAME}"
+)
+
+// completeIdentity completes the workload identity with the defaults from workspace, the fields
+// declared in the workload take precedence.
+func completeIdentity(base *Base, config kusionapiv1.GenericConfig) error {
+	identity := base.Identity
+	if identity == nil {
+		return nil
+	}
+	if value, ok := config[FieldIdentity]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		platform := &WorkloadIdentity{}
+		if err = yaml.Unmarshal(out, platform); err != nil {
+			return fmt.Errorf("invalid identity config in workspace, %w", err)
+		}
+		if identity.Driver == "" {
+			identity.Driver = platform.Driver
+		}
+		if identity.Issuer == nil {
+			identity.Issuer = platform.Issuer
+		}
+		if identity.MountPath == "" {
+			identity.MountPath = platform.MountPath
+		}
+		if identity.Duration == "" {
+			identity.Duration = platform.Duration
+		}
+		if identity.RenewBefore == "" {
+			identity.RenewBefore = platform.RenewBefore
+		}
+	}
+	if identity.Driver == "" {
+		identity.Driver = IdentityDriverCertManager
+	}
+	if identity.MountPath == "" {
+		identity.MountPath = defaultIdentityMountPath
+	}
+	return validateIdentity(identity)
+}
+
+// validateIdentity validates the completed workload identity.
+func validateIdentity(identity *WorkloadIdentity) error {
+	switch identity.Driver {
+	case IdentityDriverCertManager:
+		if identity.Issuer == nil || identity.Issuer.Name == "" {
+			return ErrEmptyIdentityIssuer
+		}
+	case IdentityDriverSPIFFE:
+	default:
+		return fmt.Errorf("%w, got %s", ErrUnsupportedIdentityDriver, identity.Driver)
+	}
+	for _, d := range []string{identity.Duration, identity.RenewBefore} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("%w, got %s", ErrInvalidIdentityDuration, d)
+		}
+	}
+	return nil
+}
+
+// mountIdentity mounts the CSI volume of the workload identity certificates into every container,
+// the certificates are issued on the pod creation and rotated by the driver.
+func mountIdentity(identity *WorkloadIdentity, containers []corev1.Container, volumes []corev1.Volume) ([]corev1.Container, []corev1.Volume) {
+	if identity == nil {
+		return containers, volumes
+	}
+
+	readOnly := true
+	source := &corev1.CSIVolumeSource{Driver: spiffeCSIDriver, ReadOnly: &readOnly}
+	if identity.Driver == IdentityDriverCertManager {
+		kind, group := identity.Issuer.Kind, identity.Issuer.Group
+		if kind == "" {
+			kind = certManagerIssuerKind
+		}
+		if group == "" {
+			group = certManagerGroup
+		}
+		attributes := map[string]string{
+			"csi.cert-manager.io/issuer-name":  identity.Issuer.Name,
+			"csi.cert-manager.io/issuer-kind":  kind,
+			"csi.cert-manager.io/issuer-group": group,
+			"csi.cert-manager.io/uri-sans":     defaultIdentityURI,
+		}
+		if len(identity.DNSNames) != 0 {
+			attributes["csi.cert-manager.io/dns-names"] = strings.Join(identity.DNSNames, ",")
+		}
+		if identity.Duration != "" {
+			attributes["csi.cert-manager.io/duration"] = identity.Duration
+		}
+		if identity.RenewBefore != "" {
+			attributes["csi.cert-manager.io/renew-before"] = identity.RenewBefore
+		}
+		source = &corev1.CSIVolumeSource{Driver: certManagerCSIDriver, ReadOnly: &readOnly, VolumeAttributes: attributes}
+	}
+
+	volumes = append(volumes, corev1.Volume{
+		Name:         identityVolumeName,
+		VolumeSource: corev1.VolumeSource{CSI: source},
+	})
+	for i := range containers {
+		containers[i].VolumeMounts = append(containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      identityVolumeName,
+			MountPath: identity.MountPath,
+			ReadOnly:  true,
+		})
+	}
+	return containers, volumes
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestCompleteIdentity(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"identity": map[string]any{
+			"issuer":   map[string]any{"name": "mesh-ca", "kind": "ClusterIssuer"},
+			"duration": "24h",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		base    *Base
+		config  kusionapiv1.GenericConfig
+		want    *WorkloadIdentity
+		wantErr error
+	}{
+		{
+			name:   "no identity",
+			base:   &Base{},
+			config: platformConfig,
+		},
+		{
+			name:   "identity with defaults in workspace",
+			base:   &Base{Identity: &WorkloadIdentity{Duration: "1h"}},
+			config: platformConfig,
+			want: &WorkloadIdentity{
+				Driver:    IdentityDriverCertManager,
+				Issuer:    &CertificateIssuer{Name: "mesh-ca", Kind: "ClusterIssuer"},
+				MountPath: defaultIdentityMountPath,
+				Duration:  "1h",
+			},
+		},
+		{
+			name:   "spiffe identity",
+			base:   &Base{Identity: &WorkloadIdentity{Driver: IdentityDriverSPIFFE, MountPath: "/certs"}},
+			config: kusionapiv1.GenericConfig{},
+			want:   &WorkloadIdentity{Driver: IdentityDriverSPIFFE, MountPath: "/certs"},
+		},
+		{
+			name:    "empty issuer",
+			base:    &Base{Identity: &WorkloadIdentity{}},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrEmptyIdentityIssuer,
+		},
+		{
+			name:    "unsupported driver",
+			base:    &Base{Identity: &WorkloadIdentity{Driver: "vault"}},
+			config:  platformConfig,
+			wantErr: ErrUnsupportedIdentityDriver,
+		},
+		{
+			name:    "invalid duration",
+			base:    &Base{Identity: &WorkloadIdentity{RenewBefore: "1 day"}},
+			config:  platformConfig,
+			wantErr: ErrInvalidIdentityDuration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeIdentity(tt.base, tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.Identity)
+		})
+	}
+}
+
+func TestMountIdentity(t *testing.T) {
+	readOnly := true
+	containers := []corev1.Container{{Name: "app"}, {Name: "proxy"}}
+	containers, volumes := mountIdentity(&WorkloadIdentity{
+		Driver:    IdentityDriverCertManager,
+		Issuer:    &CertificateIssuer{Name: "mesh-ca"},
+		MountPath: defaultIdentityMountPath,
+		DNSNames:  []string{"${POD_NAME}.${POD_NAMESPACE}.svc"},
+		Duration:  "24h",
+	}, containers, nil)
+
+	assert.Equal(t, []corev1.Volume{
+		{
+			Name: identityVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				CSI: &corev1.CSIVolumeSource{
+					Driver:   "csi.cert-manager.io",
+					ReadOnly: &readOnly,
+					VolumeAttributes: map[string]string{
+						"csi.cert-manager.io/issuer-name":  "mesh-ca",
+						"csi.cert-manager.io/issuer-kind":  "Issuer",
+						"csi.cert-manager.io/issuer-group": "cert-manager.io",
+						"csi.cert-manager.io/uri-sans":     defaultIdentityURI,
+						"csi.cert-manager.io/dns-names":    "${POD_NAME}.${POD_NAMESPACE}.svc",
+						"csi.cert-manager.io/duration":     "24h",
+					},
+				},
+			},
+		},
+	}, volumes)
+	for _, c := range containers {
+		assert.Equal(t, []corev1.VolumeMount{
+			{Name: identityVolumeName, MountPath: defaultIdentityMountPath, ReadOnly: true},
+		}, c.VolumeMounts)
+	}
+
+	_, volumes = mountIdentity(&WorkloadIdentity{Driver: IdentityDriverSPIFFE, MountPath: "/certs"}, nil, nil)
+	assert.Equal(t, &corev1.CSIVolumeSource{Driver: "spiffe.csi.cert-manager.io", ReadOnly: &readOnly}, volumes[0].CSI)
+
+	containers, volumes = mountIdentity(nil, []corev1.Container{{Name: "app"}}, nil)
+	assert.Nil(t, volumes)
+	assert.Nil(t, containers[0].VolumeMounts)
+}
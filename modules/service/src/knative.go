@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+var (
+	ErrKnativeType               = errors.New("knative is only supported by KnativeService")
+	ErrKnativeUnsupportedField   = errors.New("field is not supported by KnativeService")
+	ErrInvalidKnativeScale       = errors.New("minScale must be between 0 and maxScale of knative")
+	ErrInvalidKnativeConcurrency = errors.New("target and containerConcurrency of knative must be greater than or equal to 0")
+	ErrKnativeMultiplePorts      = errors.New("at most one container port is supported by KnativeService")
+	ErrInvalidRevision           = errors.New("revision of knative must be a DNS label")
+	ErrInvalidTrafficTarget      = errors.New("traffic target must specify exactly one of revision and latestRevision")
+	ErrInvalidTrafficPercent     = errors.New("percents of the traffic targets must be between 0 and 100 and sum to 100")
+	ErrDuplicateTrafficTag       = errors.New("tags of the traffic targets must be unique")
+)
+
+const (
+	knativeAPIVersion  = "serving.knative.dev/v1"
+	knativeServiceKind = "Service"
+)
+
+// The annotations of the revision template read by the Knative autoscaler.
+const (
+	minScaleAnnotation     = "autoscaling.knative.dev/min-scale"
+	maxScaleAnnotation     = "autoscaling.knative.dev/max-scale"
+	initialScaleAnnotation = "autoscaling.knative.dev/initial-scale"
+	targetAnnotation       = "autoscaling.knative.dev/target"
+)
+
+// validateKnative validates the Knative config of the Service, and the fields of the Service not
+// supported by KnativeService, whose pods are scaled and routed by Knative.
+func validateKnative(svc *Service) error {
+	if svc.Type != KnativeService {
+		if svc.Knative != nil {
+			return fmt.Errorf("%w, got type %s", ErrKnativeType, svc.Type)
+		}
+		return nil
+	}
+
+	unsupported := map[string]bool{
+		"updateStrategy":       svc.UpdateStrategy != nil,
+		"hostNetwork":          svc.HostNetwork,
+		"tolerateControlPlane": svc.TolerateControlPlane,
+		"scalingSchedule":      svc.ScalingSchedule != nil,
+	}
+	for _, field := range []string{"updateStrategy", "hostNetwork", "tolerateControlPlane", "scalingSchedule"} {
+		if unsupported[field] {
+			return fmt.Errorf("%w: %s", ErrKnativeUnsupportedField, field)
+		}
+	}
+
+	ports := 0
+	for _, c := range svc.Containers {
+		ports += len(c.Ports)
+	}
+	if ports > 1 {
+		return fmt.Errorf("%w, got %d", ErrKnativeMultiplePorts, ports)
+	}
+
+	knative := svc.Knative
+	if knative == nil {
+		return nil
+	}
+	if knative.MinScale != nil && (*knative.MinScale < 0 || knative.MaxScale > 0 && *knative.MinScale > knative.MaxScale) {
+		return fmt.Errorf("%w, got %d and %d", ErrInvalidKnativeScale, *knative.MinScale, knative.MaxScale)
+	}
+	if knative.MaxScale < 0 {
+		return fmt.Errorf("%w, got maxScale %d", ErrInvalidKnativeScale, knative.MaxScale)
+	}
+	if knative.Target < 0 || knative.ContainerConcurrency < 0 {
+		return fmt.Errorf("%w, got %d and %d", ErrInvalidKnativeConcurrency, knative.Target, knative.ContainerConcurrency)
+	}
+	if knative.Revision != "" && len(validation.IsDNS1123Label(knative.Revision)) != 0 {
+		return fmt.Errorf("%w, got %q", ErrInvalidRevision, knative.Revision)
+	}
+	return validateTraffic(knative.Traffic)
+}
+
+// validateTraffic validates the traffic targets splitting the requests across the revisions.
+func validateTraffic(traffic []TrafficTarget) error {
+	if len(traffic) == 0 {
+		return nil
+	}
+	total := int64(0)
+	tags := make(map[string]struct{})
+	for i, target := range traffic {
+		if (target.Revision == "") == !target.LatestRevision {
+			return fmt.Errorf("%w, got traffic target %d", ErrInvalidTrafficTarget, i)
+		}
+		if target.Revision != "" && len(validation.IsDNS1123Label(target.Revision)) != 0 {
+			return fmt.Errorf("%w, got %q of traffic target %d", ErrInvalidRevision, target.Revision, i)
+		}
+		if target.Percent < 0 || target.Percent > 100 {
+			return fmt.Errorf("%w, got %d of traffic target %d", ErrInvalidTrafficPercent, target.Percent, i)
+		}
+		if target.Tag != "" {
+			if _, ok := tags[target.Tag]; ok {
+				return fmt.Errorf("%w, got %q", ErrDuplicateTrafficTag, target.Tag)
+			}
+			tags[target.Tag] = struct{}{}
+		}
+		total += target.Percent
+	}
+	if total != 100 {
+		return fmt.Errorf("%w, got %d in total", ErrInvalidTrafficPercent, total)
+	}
+	return nil
+}
+
+// generateKnativeService generates the Knative Service running the pods, which scales the
+// revisions by the concurrent requests, down to zero if minScale is 0, and routes the requests
+// across the revisions by the traffic targets. The replicas of the workload are the initial scale
+// of the new revisions.
+func generateKnativeService(svc *Service, objectMeta metav1.ObjectMeta, template corev1.PodTemplateSpec) (*unstructured.Unstructured, error) {
+	knative := svc.Knative
+	if knative == nil {
+		knative = &Knative{}
+	}
+
+	annotations := make(map[string]string, len(template.Annotations)+4)
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+	if knative.MinScale != nil {
+		annotations[minScaleAnnotation] = strconv.Itoa(int(*knative.MinScale))
+	}
+	if knative.MaxScale > 0 {
+		annotations[maxScaleAnnotation] = strconv.Itoa(int(knative.MaxScale))
+	}
+	if svc.Replicas != nil {
+		annotations[initialScaleAnnotation] = strconv.Itoa(int(*svc.Replicas))
+	}
+	if knative.Target > 0 {
+		annotations[targetAnnotation] = strconv.Itoa(int(knative.Target))
+	}
+
+	templateMeta := map[string]interface{}{
+		"labels":      template.Labels,
+		"annotations": annotations,
+	}
+	if knative.Revision != "" {
+		templateMeta["name"] = revisionName(objectMeta.Name, knative.Revision)
+	}
+
+	// Knative validates the container concurrency in the revision spec along with the pod spec,
+	// which is inlined into it.
+	podSpec, err := toUnstructuredMap(template.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if knative.ContainerConcurrency > 0 {
+		podSpec["containerConcurrency"] = knative.ContainerConcurrency
+	}
+
+	spec := map[string]interface{}{
+		"template": map[string]interface{}{
+			"metadata": templateMeta,
+			"spec":     podSpec,
+		},
+	}
+	if len(knative.Traffic) != 0 {
+		traffic := make([]interface{}, 0, len(knative.Traffic))
+		for _, target := range knative.Traffic {
+			t := map[string]interface{}{"percent": target.Percent}
+			if target.LatestRevision {
+				t["latestRevision"] = true
+			} else {
+				t["revisionName"] = revisionName(objectMeta.Name, target.Revision)
+			}
+			if target.Tag != "" {
+				t["tag"] = target.Tag
+			}
+			traffic = append(traffic, t)
+		}
+		spec["traffic"] = traffic
+	}
+
+	metadata := map[string]interface{}{
+		"name":      objectMeta.Name,
+		"namespace": objectMeta.Namespace,
+		"labels":    objectMeta.Labels,
+	}
+	if len(objectMeta.Annotations) != 0 {
+		metadata["annotations"] = objectMeta.Annotations
+	}
+	object, err := toUnstructuredMap(map[string]interface{}{
+		"apiVersion": knativeAPIVersion,
+		"kind":       knativeServiceKind,
+		"metadata":   metadata,
+		"spec":       spec,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: object}, nil
+}
+
+// revisionName returns the name of the revision of the Knative Service, which must be prefixed
+// with the name of the Service.
+func revisionName(service, revision string) string {
+	return service + "-" + revision
+}
+
+// toUnstructuredMap round trips the value through JSON, so that it only holds the JSON values
+// accepted by the unstructured object.
+func toUnstructuredMap(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]interface{})
+	if err = json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+func TestValidateKnative(t *testing.T) {
+	zero, two := int32(0), int32(2)
+	port := []ContainerPort{{Name: "http", ContainerPort: 8080}}
+
+	tests := []struct {
+		name    string
+		svc     *Service
+		wantErr error
+	}{
+		{
+			name: "deployment",
+			svc:  &Service{Type: Deployment},
+		},
+		{
+			name:    "knative config of deployment",
+			svc:     &Service{Type: Deployment, Knative: &Knative{}},
+			wantErr: ErrKnativeType,
+		},
+		{
+			name: "valid knative service",
+			svc: &Service{Type: KnativeService, Knative: &Knative{
+				MinScale: &zero,
+				MaxScale: 10,
+				Target:   50,
+				Revision: "v2",
+				Traffic: []TrafficTarget{
+					{Revision: "v1", Percent: 90},
+					{LatestRevision: true, Percent: 10, Tag: "canary"},
+				},
+			}},
+		},
+		{
+			name:    "scaling schedule",
+			svc:     &Service{Type: KnativeService, ScalingSchedule: &ScalingSchedule{}},
+			wantErr: ErrKnativeUnsupportedField,
+		},
+		{
+			name: "multiple ports",
+			svc: &Service{Type: KnativeService, Base: Base{Containers: map[string]Container{
+				"web":     {Image: "web:v1", Ports: port},
+				"metrics": {Image: "metrics:v1", Ports: port},
+			}}},
+			wantErr: ErrKnativeMultiplePorts,
+		},
+		{
+			name:    "min scale above max scale",
+			svc:     &Service{Type: KnativeService, Knative: &Knative{MinScale: &two, MaxScale: 1}},
+			wantErr: ErrInvalidKnativeScale,
+		},
+		{
+			name:    "negative concurrency",
+			svc:     &Service{Type: KnativeService, Knative: &Knative{ContainerConcurrency: -1}},
+			wantErr: ErrInvalidKnativeConcurrency,
+		},
+		{
+			name:    "invalid revision",
+			svc:     &Service{Type: KnativeService, Knative: &Knative{Revision: "V2"}},
+			wantErr: ErrInvalidRevision,
+		},
+		{
+			name: "traffic target of both revisions",
+			svc: &Service{Type: KnativeService, Knative: &Knative{Traffic: []TrafficTarget{
+				{Revision: "v1", LatestRevision: true, Percent: 100},
+			}}},
+			wantErr: ErrInvalidTrafficTarget,
+		},
+		{
+			name: "traffic percents",
+			svc: &Service{Type: KnativeService, Knative: &Knative{Traffic: []TrafficTarget{
+				{Revision: "v1", Percent: 50},
+				{LatestRevision: true, Percent: 20},
+			}}},
+			wantErr: ErrInvalidTrafficPercent,
+		},
+		{
+			name: "duplicate tags",
+			svc: &Service{Type: KnativeService, Knative: &Knative{Traffic: []TrafficTarget{
+				{Revision: "v1", Percent: 50, Tag: "stable"},
+				{LatestRevision: true, Percent: 50, Tag: "stable"},
+			}}},
+			wantErr: ErrDuplicateTrafficTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKnative(tt.svc)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGenerateKnativeService(t *testing.T) {
+	svc := &Service{}
+	got, err := svc.Generate(context.Background(), &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"type":     "KnativeService",
+			"replicas": 2,
+			"containers": map[string]interface{}{
+				"web": map[string]interface{}{
+					"image": "web:v2",
+				},
+			},
+			"knative": map[string]interface{}{
+				"minScale":             0,
+				"maxScale":             10,
+				"target":               50,
+				"containerConcurrency": 100,
+				"revision":             "v2",
+				"traffic": []interface{}{
+					map[string]interface{}{"revision": "v1", "percent": 90},
+					map[string]interface{}{"latestRevision": true, "percent": 10, "tag": "canary"},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	if !assert.Equal(t, 1, len(got.Resources)) {
+		return
+	}
+
+	knativeService := got.Resources[0]
+	assert.Equal(t, "serving.knative.dev/v1:Service:default:default-dev-foo", knativeService.ID)
+	spec := knativeService.Attributes["spec"].(map[string]interface{})
+	template := spec["template"].(map[string]interface{})
+	metadata := template["metadata"].(map[string]interface{})
+	assert.Equal(t, "default-dev-foo-v2", metadata["name"])
+	assert.Equal(t, map[string]interface{}{
+		minScaleAnnotation:     "0",
+		maxScaleAnnotation:     "10",
+		initialScaleAnnotation: "2",
+		targetAnnotation:       "50",
+	}, metadata["annotations"])
+	revisionSpec := template["spec"].(map[string]interface{})
+	assert.Equal(t, float64(100), revisionSpec["containerConcurrency"])
+	assert.Len(t, revisionSpec["containers"], 1)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"revisionName": "default-dev-foo-v1", "percent": float64(90)},
+		map[string]interface{}{"latestRevision": true, "percent": float64(10), "tag": "canary"},
+	}, spec["traffic"])
+
+	// The pod spec of the Knative Service is checked like the other workloads.
+	request := &module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{moduleutil.PodSecurityKey: moduleutil.PodSecurityRestricted},
+	}
+	err = moduleutil.CheckPodSecurity(request, &module.GeneratorResponse{Resources: []kusionapiv1.Resource{knativeService}})
+	assert.ErrorIs(t, err, moduleutil.ErrPodSecurityViolation)
+	assert.ErrorContains(t, err, "serving.knative.dev/v1:Service:default:default-dev-foo: container")
+}
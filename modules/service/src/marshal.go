@@ -32,6 +32,14 @@ func (p *ProbeHandler) MarshalJSON() ([]byte, error) {
 			TypeWrapper:     TypeWrapper{p.Type},
 			TCPSocketAction: p.TCPSocketAction,
 		})
+	case TypeGRPC:
+		return json.Marshal(struct {
+			TypeWrapper `json:",inline"`
+			*GRPCAction `json:",inline"`
+		}{
+			TypeWrapper: TypeWrapper{p.Type},
+			GRPCAction:  p.GRPCAction,
+		})
 	default:
 		return nil, errors.New("unrecognized probe handler type")
 	}
@@ -64,6 +72,14 @@ func (p *ProbeHandler) MarshalYAML() (interface{}, error) {
 			TypeWrapper:     TypeWrapper{Type: p.Type},
 			TCPSocketAction: *p.TCPSocketAction,
 		}, nil
+	case TypeGRPC:
+		return struct {
+			TypeWrapper `yaml:",inline" json:",inline"`
+			GRPCAction  `yaml:",inline" json:",inline"`
+		}{
+			TypeWrapper: TypeWrapper{Type: p.Type},
+			GRPCAction:  *p.GRPCAction,
+		}, nil
 	}
 
 	return nil, nil
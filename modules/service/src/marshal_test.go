@@ -73,6 +73,23 @@ func TestContainerMarshalJSON(t *testing.T) {
 			},
 			result: `{"image":"nginx:v1","readinessProbe":{"probeHandler":{"_type":"service.container.probe.Tcp","url":"127.0.0.1:8080"},"initialDelaySeconds":10}}`,
 		},
+		{
+			input: Container{
+				Image: "nginx:v1",
+				LivenessProbe: &Probe{
+					ProbeHandler: &ProbeHandler{
+						TypeWrapper: TypeWrapper{Type: "service.container.probe.Grpc"},
+						GRPCAction: &GRPCAction{
+							Port:    9090,
+							Service: "health",
+						},
+					},
+					FailureThreshold:       3,
+					TerminationGracePeriod: &[]int64{30}[0],
+				},
+			},
+			result: `{"image":"nginx:v1","livenessProbe":{"probeHandler":{"_type":"service.container.probe.Grpc","port":9090,"service":"health"},"failureThreshold":3,"terminationGracePeriod":30}}`,
+		},
 		{
 			input: Container{
 				Image: "nginx:v1",
@@ -281,6 +298,29 @@ readinessProbe:
     _type: service.container.probe.Tcp
     url: 127.0.0.1:8080
   initialDelaySeconds: 10
+`,
+		},
+		{
+			input: Container{
+				Image: "nginx:v1",
+				LivenessProbe: &Probe{
+					ProbeHandler: &ProbeHandler{
+						TypeWrapper: TypeWrapper{Type: "service.container.probe.Grpc"},
+						GRPCAction: &GRPCAction{
+							Port: 9090,
+						},
+					},
+					FailureThreshold:       3,
+					TerminationGracePeriod: &[]int64{30}[0],
+				},
+			},
+			result: `image: nginx:v1
+livenessProbe:
+  probeHandler:
+    _type: service.container.probe.Grpc
+    port: 9090
+  failureThreshold: 3
+  terminationGracePeriod: 30
 `,
 		},
 		{
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+var (
+	ErrEmptyScalingWindows      = errors.New("at least one window must be specified in scalingSchedule")
+	ErrInvalidScalingCron       = errors.New("start and end of the scaling window must be cron expressions of 5 fields")
+	ErrInvalidScalingReplicas   = errors.New("replicas of the scaling window must be greater than 0")
+	ErrDaemonSetScalingSchedule = errors.New("scalingSchedule is not supported by DaemonSet")
+)
+
+const (
+	kedaAPIVersion     = "keda.sh/v1alpha1"
+	scaledObjectKind   = "ScaledObject"
+	defaultTimezone    = "UTC"
+	defaultMinReplicas = int32(1)
+	cronFields         = 5
+)
+
+// validateScalingSchedule validates the scaling schedule of the Service.
+func validateScalingSchedule(svc *Service) error {
+	schedule := svc.ScalingSchedule
+	if schedule == nil {
+		return nil
+	}
+	if svc.Type == DaemonSet {
+		return ErrDaemonSetScalingSchedule
+	}
+	if len(schedule.Windows) == 0 {
+		return ErrEmptyScalingWindows
+	}
+	for i, w := range schedule.Windows {
+		if len(strings.Fields(w.Start)) != cronFields || len(strings.Fields(w.End)) != cronFields {
+			return fmt.Errorf("%w, got window %d from %q to %q", ErrInvalidScalingCron, i, w.Start, w.End)
+		}
+		if w.Replicas <= 0 {
+			return fmt.Errorf("%w, got %d of window %d", ErrInvalidScalingReplicas, w.Replicas, i)
+		}
+	}
+	return nil
+}
+
+// generateScaledObject generates the KEDA ScaledObject scaling the workload on the cron windows.
+// The replicas declared in the workload are kept out of the windows, and the largest replicas of
+// the windows are the maximum. The replicas of the workload itself are left to KEDA.
+func generateScaledObject(svc *Service, typeMeta metav1.TypeMeta, objectMeta metav1.ObjectMeta, workloadID string) (*kusionapiv1.Resource, error) {
+	schedule := svc.ScalingSchedule
+	minReplicas := defaultMinReplicas
+	if svc.Replicas != nil {
+		minReplicas = *svc.Replicas
+	}
+	maxReplicas := minReplicas
+	timezone := schedule.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+
+	triggers := make([]interface{}, 0, len(schedule.Windows))
+	for _, w := range schedule.Windows {
+		triggers = append(triggers, map[string]interface{}{
+			"type": "cron",
+			"metadata": map[string]interface{}{
+				"timezone":        timezone,
+				"start":           w.Start,
+				"end":             w.End,
+				"desiredReplicas": strconv.Itoa(int(w.Replicas)),
+			},
+		})
+		maxReplicas = max(maxReplicas, w.Replicas)
+	}
+
+	scaledObject := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": kedaAPIVersion,
+			"kind":       scaledObjectKind,
+			"metadata": map[string]interface{}{
+				"name":      objectMeta.Name,
+				"namespace": objectMeta.Namespace,
+				"labels":    toInterfaceMap(objectMeta.Labels),
+			},
+			"spec": map[string]interface{}{
+				"scaleTargetRef": map[string]interface{}{
+					"apiVersion": typeMeta.APIVersion,
+					"kind":       typeMeta.Kind,
+					"name":       objectMeta.Name,
+				},
+				"minReplicaCount": int64(minReplicas),
+				"maxReplicaCount": int64(maxReplicas),
+				"triggers":        triggers,
+			},
+		},
+	}
+
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: kedaAPIVersion, Kind: scaledObjectKind},
+		metav1.ObjectMeta{Name: objectMeta.Name, Namespace: objectMeta.Namespace},
+	)
+	resource, err := module.WrapK8sResourceToKusionResource(resourceID, scaledObject)
+	if err != nil {
+		return nil, err
+	}
+	resource.DependsOn = []string{workloadID}
+	return resource, nil
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
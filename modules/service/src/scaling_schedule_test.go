@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestValidateScalingSchedule(t *testing.T) {
+	tests := []struct {
+		name    string
+		svc     *Service
+		wantErr error
+	}{
+		{
+			name: "no scaling schedule",
+			svc:  &Service{},
+		},
+		{
+			name: "valid scaling schedule",
+			svc: &Service{ScalingSchedule: &ScalingSchedule{
+				Windows: []ScalingWindow{{Start: "0 8 * * 1-5", End: "0 20 * * 1-5", Replicas: 10}},
+			}},
+		},
+		{
+			name: "daemonset",
+			svc: &Service{Type: DaemonSet, ScalingSchedule: &ScalingSchedule{
+				Windows: []ScalingWindow{{Start: "0 8 * * 1-5", End: "0 20 * * 1-5", Replicas: 10}},
+			}},
+			wantErr: ErrDaemonSetScalingSchedule,
+		},
+		{
+			name:    "empty windows",
+			svc:     &Service{ScalingSchedule: &ScalingSchedule{}},
+			wantErr: ErrEmptyScalingWindows,
+		},
+		{
+			name: "invalid cron",
+			svc: &Service{ScalingSchedule: &ScalingSchedule{
+				Windows: []ScalingWindow{{Start: "@daily", End: "0 20 * * 1-5", Replicas: 10}},
+			}},
+			wantErr: ErrInvalidScalingCron,
+		},
+		{
+			name: "invalid replicas",
+			svc: &Service{ScalingSchedule: &ScalingSchedule{
+				Windows: []ScalingWindow{{Start: "0 8 * * 1-5", End: "0 20 * * 1-5"}},
+			}},
+			wantErr: ErrInvalidScalingReplicas,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateScalingSchedule(tt.svc)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestGenerateScalingSchedule(t *testing.T) {
+	svc := &Service{}
+	got, err := svc.Generate(context.Background(), &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"replicas": 2,
+			"containers": map[string]interface{}{
+				"web": map[string]interface{}{
+					"image": "web:v1",
+				},
+			},
+			"scalingSchedule": map[string]interface{}{
+				"timezone": "Asia/Shanghai",
+				"windows": []interface{}{
+					map[string]interface{}{"start": "0 8 * * 1-5", "end": "0 20 * * 1-5", "replicas": 10},
+					map[string]interface{}{"start": "0 10 * * 0,6", "end": "0 18 * * 0,6", "replicas": 4},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(got.Resources))
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[0].Attributes, deployment)
+	assert.NoError(t, err)
+	assert.Nil(t, deployment.Spec.Replicas)
+
+	scaledObject := got.Resources[1]
+	assert.Equal(t, "keda.sh/v1alpha1:ScaledObject:default:default-dev-foo", scaledObject.ID)
+	assert.Equal(t, []string{got.Resources[0].ID}, scaledObject.DependsOn)
+	assert.Equal(t, map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"name":       "default-dev-foo",
+		},
+		"minReplicaCount": int64(2),
+		"maxReplicaCount": int64(10),
+		"triggers": []interface{}{
+			map[string]interface{}{
+				"type": "cron",
+				"metadata": map[string]interface{}{
+					"timezone":        "Asia/Shanghai",
+					"start":           "0 8 * * 1-5",
+					"end":             "0 20 * * 1-5",
+					"desiredReplicas": "10",
+				},
+			},
+			map[string]interface{}{
+				"type": "cron",
+				"metadata": map[string]interface{}{
+					"timezone":        "Asia/Shanghai",
+					"start":           "0 10 * * 0,6",
+					"end":             "0 18 * * 0,6",
+					"desiredReplicas": "4",
+				},
+			},
+		},
+	}, scaledObject.Attributes["spec"])
+}
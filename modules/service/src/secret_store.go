@@ -0,0 +1,593 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+var (
+	ErrEmptySecretStore       = errors.New("secretStore must be configured in workspace for external secrets")
+	ErrUnsupportedSecretStore = errors.New("secretStore provider must be one of aws, alicloud and vault")
+	ErrEmptySecretStoreRegion = errors.New("region must be specified in secretStore of aws and alicloud")
+	ErrInvalidSecretRef       = errors.New("external secret data must be in the format of ref://name/property?version=version")
+	ErrEmptySecretStoreCreds  = errors.New("credentials of secretStore must be set by the environment variables")
+	ErrEmptySecretStoreRef    = errors.New("storeRef must be specified in secretStore of externalsecret mode")
+)
+
+// secretStore resolves the value of the external secret from the secret manager.
+type secretStore interface {
+	GetSecret(ctx context.Context, ref externalSecretRef) ([]byte, error)
+}
+
+// externalSecretRef references a secret, or a property of it, in the secret manager.
+type externalSecretRef struct {
+	Name     string
+	Property string
+	Version  string
+}
+
+// parseExternalSecretRef parses the external secret reference in the format of
+// ref://name/property?version=version, in which both the property and version are optional.
+func parseExternalSecretRef(ref string) (externalSecretRef, error) {
+	result := externalSecretRef{}
+	if !strings.HasPrefix(ref, "ref://") {
+		return result, ErrInvalidSecretRef
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return result, fmt.Errorf("%w, %v", ErrInvalidSecretRef, err)
+	}
+	result.Name = u.Host
+	result.Property = strings.TrimPrefix(u.Path, "/")
+	result.Version = u.Query().Get("version")
+	if result.Name == "" {
+		return result, ErrInvalidSecretRef
+	}
+	return result, nil
+}
+
+// newSecretStore creates the secret store of the provider, the credentials are read from the
+// environment variables the same as the official clients of the providers, which must be set.
+func newSecretStore(spec *SecretStore) (secretStore, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch spec.Provider {
+	case SecretStoreAWS:
+		if spec.Region == "" {
+			return nil, ErrEmptySecretStoreRegion
+		}
+		if err := requireEnv("AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY"); err != nil {
+			return nil, err
+		}
+		endpoint := spec.Server
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", spec.Region)
+		}
+		return &awsSecretStore{
+			client:       client,
+			endpoint:     endpoint,
+			region:       spec.Region,
+			accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	case SecretStoreAlicloud:
+		if spec.Region == "" {
+			return nil, ErrEmptySecretStoreRegion
+		}
+		if err := requireEnv("ALICLOUD_ACCESS_KEY", "ALICLOUD_SECRET_KEY"); err != nil {
+			return nil, err
+		}
+		endpoint := spec.Server
+		if endpoint == "" {
+			endpoint = fmt.Sprintf("https://kms.%s.aliyuncs.com", spec.Region)
+		}
+		return &alicloudSecretStore{
+			client:    client,
+			endpoint:  endpoint,
+			accessKey: os.Getenv("ALICLOUD_ACCESS_KEY"),
+			secretKey: os.Getenv("ALICLOUD_SECRET_KEY"),
+		}, nil
+	case SecretStoreVault:
+		server := spec.Server
+		if server == "" {
+			if err := requireEnv("VAULT_ADDR"); err != nil {
+				return nil, err
+			}
+			server = os.Getenv("VAULT_ADDR")
+		}
+		if err := requireEnv("VAULT_TOKEN"); err != nil {
+			return nil, err
+		}
+		path := spec.Path
+		if path == "" {
+			path = "secret"
+		}
+		version := spec.Version
+		if version == "" {
+			version = "v2"
+		}
+		return &vaultSecretStore{
+			client:  client,
+			server:  strings.TrimSuffix(server, "/"),
+			path:    strings.Trim(path, "/"),
+			version: version,
+			token:   os.Getenv("VAULT_TOKEN"),
+		}, nil
+	default:
+		return nil, ErrUnsupportedSecretStore
+	}
+}
+
+// requireEnv returns the error listing the environment variables of the credentials not set.
+func requireEnv(names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if os.Getenv(name) == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%w, missing %s", ErrEmptySecretStoreCreds, strings.Join(missing, " and "))
+	}
+	return nil
+}
+
+// awsSecretStore resolves the secrets from AWS Secrets Manager.
+type awsSecretStore struct {
+	client       *http.Client
+	endpoint     string
+	region       string
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+func (s *awsSecretStore) GetSecret(ctx context.Context, ref externalSecretRef) ([]byte, error) {
+	input := map[string]string{"SecretId": ref.Name}
+	if ref.Version != "" {
+		input["VersionId"] = ref.Version
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+"/", strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	s.sign(req, body, time.Now().UTC())
+
+	out := struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary []byte `json:"SecretBinary"`
+	}{}
+	if err = doJSONRequest(s.client, req, &out); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s from aws secrets manager, %w", ref.Name, err)
+	}
+	value := []byte(out.SecretString)
+	if out.SecretString == "" {
+		value = out.SecretBinary
+	}
+	return secretProperty(value, ref)
+}
+
+// sign signs the request with AWS Signature Version 4.
+func (s *awsSecretStore) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	bodyHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method, "/", "", canonicalHeaders.String(), signedHeaders, hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.Join([]string{date, s.region, "secretsmanager", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, data := range []string{date, s.region, "secretsmanager", "aws4_request"} {
+		key = hmacSHA256(key, data)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+}
+
+// alicloudSecretStore resolves the secrets from Alicloud KMS Secrets Manager.
+type alicloudSecretStore struct {
+	client    *http.Client
+	endpoint  string
+	accessKey string
+	secretKey string
+}
+
+func (s *alicloudSecretStore) GetSecret(ctx context.Context, ref externalSecretRef) ([]byte, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	params := map[string]string{
+		"Action":           "GetSecretValue",
+		"SecretName":       ref.Name,
+		"Format":           "JSON",
+		"Version":          "2016-01-20",
+		"AccessKeyId":      s.accessKey,
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureVersion": "1.0",
+		"SignatureNonce":   hex.EncodeToString(nonce),
+		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if ref.Version != "" {
+		params["VersionId"] = ref.Version
+	}
+	query := s.sign(params)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.endpoint+"/?"+query, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := struct {
+		SecretData string `json:"SecretData"`
+	}{}
+	if err = doJSONRequest(s.client, req, &out); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s from alicloud kms, %w", ref.Name, err)
+	}
+	return secretProperty([]byte(out.SecretData), ref)
+}
+
+// sign returns the query string signed with the signature of the Alicloud RPC API.
+func (s *alicloudSecretStore) sign(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(params[k]))
+	}
+	canonicalized := strings.Join(pairs, "&")
+	stringToSign := "GET&" + percentEncode("/") + "&" + percentEncode(canonicalized)
+	mac := hmac.New(sha1.New, []byte(s.secretKey+"&"))
+	mac.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	return canonicalized + "&Signature=" + percentEncode(signature)
+}
+
+// vaultSecretStore resolves the secrets from the KV secrets engine of HashiCorp Vault.
+type vaultSecretStore struct {
+	client  *http.Client
+	server  string
+	path    string
+	version string
+	token   string
+}
+
+func (s *vaultSecretStore) GetSecret(ctx context.Context, ref externalSecretRef) ([]byte, error) {
+	endpoint := fmt.Sprintf("%s/v1/%s/%s", s.server, s.path, ref.Name)
+	if s.version == "v2" {
+		endpoint = fmt.Sprintf("%s/v1/%s/data/%s", s.server, s.path, ref.Name)
+		if ref.Version != "" {
+			endpoint += "?version=" + url.QueryEscape(ref.Version)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+
+	out := struct {
+		Data map[string]any `json:"data"`
+	}{}
+	if err = doJSONRequest(s.client, req, &out); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s from vault, %w", ref.Name, err)
+	}
+	data := out.Data
+	if s.version == "v2" {
+		data, _ = out.Data["data"].(map[string]any)
+	}
+	value, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return secretProperty(value, ref)
+}
+
+// doJSONRequest sends the request and decodes the JSON response into out.
+func doJSONRequest(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// secretProperty returns the property of the secret value if the property is referenced, in
+// which case the secret value is expected to be a JSON object.
+func secretProperty(value []byte, ref externalSecretRef) ([]byte, error) {
+	if ref.Property == "" {
+		return value, nil
+	}
+	properties := make(map[string]any)
+	if err := json.Unmarshal(value, &properties); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object to get property %s from", ref.Name, ref.Property)
+	}
+	property, ok := properties[ref.Property]
+	if !ok {
+		return nil, fmt.Errorf("property %s is not found in secret %s", ref.Property, ref.Name)
+	}
+	if str, ok := property.(string); ok {
+		return []byte(str), nil
+	}
+	return json.Marshal(property)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// percentEncode encodes the string as required by the signature of the Alicloud RPC API.
+func percentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
+
+// secretProviderClassObject is an object of the SecretProviderClass to mount, whose fields are
+// the union of the ones of the supported providers.
+type secretProviderClassObject struct {
+	ObjectName    string              `yaml:"objectName"`
+	ObjectType    string              `yaml:"objectType,omitempty"`
+	ObjectVersion string              `yaml:"objectVersion,omitempty"`
+	ObjectAlias   string              `yaml:"objectAlias,omitempty"`
+	JMESPath      []map[string]string `yaml:"jmesPath,omitempty"`
+	SecretPath    string              `yaml:"secretPath,omitempty"`
+	SecretKey     string              `yaml:"secretKey,omitempty"`
+}
+
+// secretProviderClass generates the SecretProviderClass of the external secret, named after the
+// secret, to mount the data of the secret as files named after the data keys.
+func secretProviderClass(spec *SecretStore, name string, secret Secret) (*unstructured.Unstructured, error) {
+	var provider string
+	parameters := make(map[string]interface{})
+	switch spec.Provider {
+	case SecretStoreAWS:
+		provider = "aws"
+	case SecretStoreAlicloud:
+		provider = "alibabacloud"
+	case SecretStoreVault:
+		provider = "vault"
+		if spec.Server != "" {
+			parameters["vaultAddress"] = spec.Server
+		}
+		if spec.Role != "" {
+			parameters["roleName"] = spec.Role
+		}
+	default:
+		return nil, ErrUnsupportedSecretStore
+	}
+	if spec.Region != "" && spec.Provider != SecretStoreVault {
+		parameters["region"] = spec.Region
+	}
+
+	var objects []secretProviderClassObject
+	err := module.ForeachOrdered(secret.Data, func(k string, v string) error {
+		ref, err := parseExternalSecretRef(v)
+		if err != nil {
+			return fmt.Errorf("invalid data %s of secret %s, %w", k, name, err)
+		}
+		if spec.Provider == SecretStoreVault {
+			if ref.Property == "" {
+				return fmt.Errorf("property must be specified in data %s of secret %s to mount from vault", k, name)
+			}
+			path := strings.Trim(spec.Path, "/")
+			if path == "" {
+				path = "secret"
+			}
+			secretPath := fmt.Sprintf("%s/%s", path, ref.Name)
+			if spec.Version == "" || spec.Version == "v2" {
+				secretPath = fmt.Sprintf("%s/data/%s", path, ref.Name)
+				if ref.Version != "" {
+					secretPath += "?version=" + ref.Version
+				}
+			}
+			objects = append(objects, secretProviderClassObject{
+				ObjectName: k,
+				SecretPath: secretPath,
+				SecretKey:  ref.Property,
+			})
+			return nil
+		}
+
+		object := secretProviderClassObject{
+			ObjectName:    ref.Name,
+			ObjectType:    "secretsmanager",
+			ObjectVersion: ref.Version,
+		}
+		if spec.Provider == SecretStoreAlicloud {
+			object.ObjectType = "kms"
+		}
+		if ref.Property == "" {
+			object.ObjectAlias = k
+		} else {
+			object.JMESPath = []map[string]string{{"path": ref.Property, "objectAlias": k}}
+		}
+		objects = append(objects, object)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out, err := yaml.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
+	parameters["objects"] = string(out)
+
+	providerClass := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "secrets-store.csi.x-k8s.io/v1",
+			"kind":       "SecretProviderClass",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"provider":   provider,
+				"parameters": parameters,
+			},
+		},
+	}
+	return providerClass, nil
+}
+
+// externalSecret generates the ExternalSecret of External Secrets Operator, named after the
+// secret, which syncs the data of the secret from the ClusterSecretStore in the Secret of the
+// same name, so that the data are never stored in the Kusion state.
+func externalSecret(spec *SecretStore, name string, secret Secret) (*unstructured.Unstructured, error) {
+	if spec.StoreRef == "" {
+		return nil, ErrEmptySecretStoreRef
+	}
+	var data []interface{}
+	err := module.ForeachOrdered(secret.Data, func(k string, v string) error {
+		ref, err := parseExternalSecretRef(v)
+		if err != nil {
+			return fmt.Errorf("invalid data %s of secret %s, %w", k, name, err)
+		}
+		remoteRef := map[string]interface{}{"key": ref.Name}
+		if ref.Property != "" {
+			remoteRef["property"] = ref.Property
+		}
+		if ref.Version != "" {
+			remoteRef["version"] = ref.Version
+		}
+		data = append(data, map[string]interface{}{"secretKey": k, "remoteRef": remoteRef})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	target := map[string]interface{}{"name": name, "creationPolicy": "Owner"}
+	if secret.Immutable {
+		target["immutable"] = true
+	}
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "external-secrets.io/v1beta1",
+			"kind":       "ExternalSecret",
+			"metadata": map[string]interface{}{
+				"name": name,
+			},
+			"spec": map[string]interface{}{
+				"refreshInterval": "1h",
+				"secretStoreRef": map[string]interface{}{
+					"kind": "ClusterSecretStore",
+					"name": spec.StoreRef,
+				},
+				"target": target,
+				"data":   data,
+			},
+		},
+	}, nil
+}
+
+// mountSecretsByCSI replaces the Secret volumes of the secrets mounted via the Secrets Store CSI
+// driver with the CSI volumes. The secrets are not able to be referenced by environment variables
+// since they are not materialized as Secrets. The ExternalSecrets syncing the Secrets are skipped.
+func mountSecretsByCSI(secretResources []unstructured.Unstructured, containers []corev1.Container, volumes []corev1.Volume) error {
+	names := make(map[string]bool, len(secretResources))
+	for _, res := range secretResources {
+		if res.GetKind() == "SecretProviderClass" {
+			names[res.GetName()] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	for _, c := range containers {
+		for _, env := range c.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil && names[env.ValueFrom.SecretKeyRef.Name] {
+				return fmt.Errorf("secret %s mounted via csi can not be referenced by environment variable %s", env.ValueFrom.SecretKeyRef.Name, env.Name)
+			}
+		}
+		for _, envFrom := range c.EnvFrom {
+			if envFrom.SecretRef != nil && names[envFrom.SecretRef.Name] {
+				return fmt.Errorf("secret %s mounted via csi can not be referenced by envFrom", envFrom.SecretRef.Name)
+			}
+		}
+	}
+
+	readOnly := true
+	for i := range volumes {
+		source := volumes[i].Secret
+		if source == nil || !names[source.SecretName] {
+			continue
+		}
+		volumes[i].VolumeSource = corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:   "secrets-store.csi.k8s.io",
+				ReadOnly: &readOnly,
+				VolumeAttributes: map[string]string{
+					"secretProviderClass": source.SecretName,
+				},
+			},
+		}
+	}
+	return nil
+}
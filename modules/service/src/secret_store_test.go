@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseExternalSecretRef(t *testing.T) {
+	ref, err := parseExternalSecretRef("ref://db-credentials/password?version=2")
+	assert.NoError(t, err)
+	assert.Equal(t, externalSecretRef{Name: "db-credentials", Property: "password", Version: "2"}, ref)
+
+	ref, err = parseExternalSecretRef("ref://api-token")
+	assert.NoError(t, err)
+	assert.Equal(t, externalSecretRef{Name: "api-token"}, ref)
+
+	_, err = parseExternalSecretRef("secret://api-token")
+	assert.ErrorIs(t, err, ErrInvalidSecretRef)
+}
+
+func TestNewSecretStore(t *testing.T) {
+	_, err := newSecretStore(&SecretStore{Provider: "azure"})
+	assert.ErrorIs(t, err, ErrUnsupportedSecretStore)
+
+	_, err = newSecretStore(&SecretStore{Provider: SecretStoreAWS})
+	assert.ErrorIs(t, err, ErrEmptySecretStoreRegion)
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	_, err = newSecretStore(&SecretStore{Provider: SecretStoreAWS, Region: "us-east-1"})
+	assert.ErrorIs(t, err, ErrEmptySecretStoreCreds)
+	assert.ErrorContains(t, err, "missing AWS_SECRET_ACCESS_KEY")
+
+	t.Setenv("VAULT_ADDR", "")
+	_, err = newSecretStore(&SecretStore{Provider: SecretStoreVault})
+	assert.ErrorIs(t, err, ErrEmptySecretStoreCreds)
+}
+
+func TestVaultSecretStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/kv/data/db-credentials", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("version"))
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		_, _ = w.Write([]byte(`{"data":{"data":{"username":"admin","password":"secret"}}}`))
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_TOKEN", "vault-token")
+
+	store, err := newSecretStore(&SecretStore{Provider: SecretStoreVault, Server: server.URL, Path: "kv"})
+	assert.NoError(t, err)
+	value, err := store.GetSecret(context.Background(), externalSecretRef{Name: "db-credentials", Property: "password", Version: "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(value))
+
+	_, err = store.GetSecret(context.Background(), externalSecretRef{Name: "db-credentials", Property: "host", Version: "2"})
+	assert.ErrorContains(t, err, "property host is not found in secret db-credentials")
+}
+
+func TestAWSSecretStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"),
+			"AWS4-HMAC-SHA256 Credential=access-key/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/secretsmanager/aws4_request")
+		_, _ = w.Write([]byte(`{"SecretString":"token-value"}`))
+	}))
+	defer server.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+
+	store, err := newSecretStore(&SecretStore{Provider: SecretStoreAWS, Region: "us-east-1", Server: server.URL})
+	assert.NoError(t, err)
+	value, err := store.GetSecret(context.Background(), externalSecretRef{Name: "api-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "token-value", string(value))
+}
+
+func TestAlicloudSecretStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "GetSecretValue", query.Get("Action"))
+		assert.Equal(t, "api-token", query.Get("SecretName"))
+		assert.Equal(t, "access-key", query.Get("AccessKeyId"))
+		assert.NotEmpty(t, query.Get("Signature"))
+		_, _ = w.Write([]byte(`{"SecretData":"token-value"}`))
+	}))
+	defer server.Close()
+	t.Setenv("ALICLOUD_ACCESS_KEY", "access-key")
+	t.Setenv("ALICLOUD_SECRET_KEY", "secret-key")
+
+	store, err := newSecretStore(&SecretStore{Provider: SecretStoreAlicloud, Region: "cn-hangzhou", Server: server.URL})
+	assert.NoError(t, err)
+	value, err := store.GetSecret(context.Background(), externalSecretRef{Name: "api-token"})
+	assert.NoError(t, err)
+	assert.Equal(t, "token-value", string(value))
+}
+
+func TestSecretProviderClass(t *testing.T) {
+	secret := Secret{
+		Type: SecretTypeExternal,
+		Data: map[string]string{
+			"password": "ref://db-credentials/password?version=2",
+			"token":    "ref://api-token",
+		},
+	}
+
+	providerClass, err := secretProviderClass(&SecretStore{Provider: SecretStoreAWS, Region: "us-east-1"}, "app-secrets", secret)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"provider": "aws",
+		"parameters": map[string]interface{}{
+			"region": "us-east-1",
+			"objects": `- objectName: db-credentials
+  objectType: secretsmanager
+  objectVersion: "2"
+  jmesPath:
+  - objectAlias: password
+    path: password
+- objectName: api-token
+  objectType: secretsmanager
+  objectAlias: token
+`,
+		},
+	}, providerClass.Object["spec"])
+
+	providerClass, err = secretProviderClass(&SecretStore{Provider: SecretStoreVault, Server: "https://vault:8200", Role: "app"}, "app-secrets",
+		Secret{Type: SecretTypeExternal, Data: map[string]string{"password": "ref://db-credentials/password"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"provider": "vault",
+		"parameters": map[string]interface{}{
+			"vaultAddress": "https://vault:8200",
+			"roleName":     "app",
+			"objects": `- objectName: password
+  secretPath: secret/data/db-credentials
+  secretKey: password
+`,
+		},
+	}, providerClass.Object["spec"])
+
+	_, err = secretProviderClass(&SecretStore{Provider: SecretStoreVault}, "app-secrets", secret)
+	assert.ErrorContains(t, err, "property must be specified in data token of secret app-secrets to mount from vault")
+}
+
+func TestExternalSecret(t *testing.T) {
+	secret := Secret{
+		Type: SecretTypeExternal,
+		Data: map[string]string{
+			"password": "ref://db-credentials/password?version=2",
+			"token":    "ref://api-token",
+		},
+		Immutable: true,
+	}
+	_, err := externalSecret(&SecretStore{Provider: SecretStoreAWS}, "app-secrets", secret)
+	assert.ErrorIs(t, err, ErrEmptySecretStoreRef)
+
+	es, err := externalSecret(&SecretStore{Provider: SecretStoreAWS, StoreRef: "aws-secrets-manager"}, "app-secrets", secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "app-secrets", es.GetName())
+	assert.Equal(t, map[string]interface{}{
+		"refreshInterval": "1h",
+		"secretStoreRef":  map[string]interface{}{"kind": "ClusterSecretStore", "name": "aws-secrets-manager"},
+		"target":          map[string]interface{}{"name": "app-secrets", "creationPolicy": "Owner", "immutable": true},
+		"data": []interface{}{
+			map[string]interface{}{
+				"secretKey": "password",
+				"remoteRef": map[string]interface{}{"key": "db-credentials", "property": "password", "version": "2"},
+			},
+			map[string]interface{}{
+				"secretKey": "token",
+				"remoteRef": map[string]interface{}{"key": "api-token"},
+			},
+		},
+	}, es.Object["spec"])
+
+	// The Secrets synced by the ExternalSecrets are mounted as they are.
+	volumes := []corev1.Volume{{Name: "secrets", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app-secrets"}}}}
+	assert.NoError(t, mountSecretsByCSI([]unstructured.Unstructured{*es}, nil, volumes))
+	assert.NotNil(t, volumes[0].Secret)
+}
+
+func TestMountSecretsByCSI(t *testing.T) {
+	providerClass, err := secretProviderClass(&SecretStore{Provider: SecretStoreAlicloud, Region: "cn-hangzhou"}, "app-secrets",
+		Secret{Type: SecretTypeExternal, Data: map[string]string{"token": "ref://api-token"}})
+	assert.NoError(t, err)
+	providerClasses := []unstructured.Unstructured{*providerClass}
+
+	volumes := []corev1.Volume{
+		{Name: "app-secrets", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "app-secrets"}}},
+		{Name: "other", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "other"}}},
+	}
+	err = mountSecretsByCSI(providerClasses, []corev1.Container{{Name: "app"}}, volumes)
+	assert.NoError(t, err)
+	readOnly := true
+	assert.Equal(t, &corev1.CSIVolumeSource{
+		Driver:           "secrets-store.csi.k8s.io",
+		ReadOnly:         &readOnly,
+		VolumeAttributes: map[string]string{"secretProviderClass": "app-secrets"},
+	}, volumes[0].CSI)
+	assert.Nil(t, volumes[0].Secret)
+	assert.NotNil(t, volumes[1].Secret)
+
+	containers := []corev1.Container{{
+		Name: "app",
+		Env: []corev1.EnvVar{{
+			Name: "TOKEN",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "app-secrets"},
+					Key:                  "token",
+				},
+			},
+		}},
+	}}
+	err = mountSecretsByCSI(providerClasses, containers, nil)
+	assert.ErrorContains(t, err, "secret app-secrets mounted via csi can not be referenced by environment variable TOKEN")
+}
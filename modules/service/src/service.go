@@ -2,22 +2,23 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"runtime/debug"
+	"os"
 
 	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"kusionstack.io/kube-api/apps/v1alpha1"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/log"
 	"kusionstack.io/kusion-module-framework/pkg/module"
 	"kusionstack.io/kusion-module-framework/pkg/server"
 	"kusionstack.io/kusion-module-framework/pkg/util/workspace"
+	"moduleutil"
 )
 
 var (
@@ -26,6 +27,7 @@ var (
 	ErrInvalidTargetPort     = errors.New("targetPort must be between 1 and 65535 if exist")
 	ErrInvalidProtocol       = errors.New("protocol must be TCP or UDP")
 	ErrDuplicatePortProtocol = errors.New("port-protocol pair must not be duplicate")
+	ErrCollaSetRollingUpdate = errors.New("maxUnavailable and maxSurge are not supported by CollaSet, use opsRule instead")
 )
 
 func (svc *Service) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
@@ -33,13 +35,16 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 	logger := log.GetModuleLogger(ctx)
 	logger.Info("Generating resources...")
 
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "service", &response, &err)
+
+	// Finalize the generated resources with the standard metadata, the policy and Pod Security
+	// Standards checks, and the preview summary if enabled in the workspace context.
 	defer func() {
-		if r := recover(); r != nil {
-			logger.Debug("failed to generate Service module: %v", r)
-			response = nil
-			rawRequest, _ := json.Marshal(request)
-			err = fmt.Errorf("panic in service module generator but recovered with error: [%v] and stack %v and request %v",
-				r, string(debug.Stack()), string(rawRequest))
+		if err == nil {
+			if err = moduleutil.Finalize("service", request, response); err != nil {
+				response = nil
+			}
 		}
 	}()
 
@@ -47,28 +52,88 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 		logger.Info("Service does not exist in AppConfig config")
 		return nil, nil
 	}
+	if err = moduleutil.ValidateConfig(request.DevConfig, Service{}); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseValidate, fmt.Errorf("validate Service dev config failed, %w", err))
+	}
+	if err = moduleutil.ValidateConfig(request.PlatformConfig, PlatformConfig{}); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseValidate, fmt.Errorf("validate Service platform config failed, %w", err))
+	}
 	out, err := yaml.Marshal(request.DevConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	if err = yaml.Unmarshal(out, svc); err != nil {
-		return nil, fmt.Errorf("complete Service by dev config failed, %w", err)
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseComplete, fmt.Errorf("complete Service by dev config failed, %w", err))
+	}
+
+	if err = validateOS(&svc.Base); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseValidate, err)
+	}
+	if err = validateGPU(&svc.Base); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseValidate, err)
 	}
 
 	if err = completeServiceInput(svc, request.PlatformConfig); err != nil {
-		return nil, fmt.Errorf("complete Service by platform config failed, %w", err)
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseComplete, fmt.Errorf("complete Service by platform config failed, %w", err))
+	}
+	if err = validateScalingSchedule(svc); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseValidate, err)
+	}
+	if err = validateKnative(svc); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseValidate, err)
 	}
 
-	uniqueAppName := module.UniqueAppName(request.Project, request.Stack, request.App)
+	uniqueAppName := moduleutil.AppName(request)
+
+	// Create the ConfigMaps declared in the App's configuration.
+	workloadConfigMaps := handleConfigMaps(&svc.Base, uniqueAppName)
 
 	// Create a slice of containers based on the App's containers along with related volumes and configMaps.
 	containers, volumes, configMaps, err := toOrderedContainers(svc.Containers, uniqueAppName)
 	if err != nil {
 		return nil, err
 	}
+	configMaps = append(configMaps, workloadConfigMaps...)
+
+	// Resolve the references to the outputs of the other modules, e.g. the database Secret, which
+	// the workload waits on.
+	dependsOn, err := moduleutil.ResolveOutputRefs(request, containers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the volumes declared in the App's configuration along with the PVCs to be created.
+	workloadVolumes, pvcs, err := handleVolumes(&svc.Base, uniqueAppName)
+	if err != nil {
+		return nil, err
+	}
+	volumes = append(volumes, workloadVolumes...)
+
+	// Collect the image pull secrets along with the generated registry credentials.
+	imagePullSecrets, registrySecret, err := handleRegistryCredentials(&svc.Base, request.PlatformConfig, uniqueAppName)
+	if err != nil {
+		return nil, err
+	}
 
-	topologySpreadConstraints := handleTopologySpreadConstraints(svc.TopologySpreadConstraints)
+	// Create the Secrets declared in the App's configuration, the external secrets are mounted via
+	// the Secrets Store CSI driver with SecretProviderClasses in csi mode, or synced by the
+	// ExternalSecrets in externalsecret mode.
+	secrets, secretResources, err := handleSecrets(ctx, &svc.Base, request.PlatformConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err = mountSecretsByCSI(secretResources, containers, volumes); err != nil {
+		return nil, err
+	}
+	containers, volumes = mountIdentity(svc.Identity, containers, volumes)
+	containers, volumes = mountServiceAccountTokens(svc.ServiceAccount, containers, volumes)
+	if containers, err = mountConfigReloader(svc.ConfigReload, containers, volumes); err != nil {
+		return nil, moduleutil.NewModuleError("service", moduleutil.PhaseGenerate, err)
+	}
+	if registrySecret != nil {
+		secrets = append(secrets, *registrySecret)
+	}
 
 	res := make([]kusionapiv1.Resource, 0)
 	// Create ConfigMap objects based on the App's configuration.
@@ -82,6 +147,43 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 		res = append(res, *resource)
 	}
 
+	// Create PersistentVolumeClaim objects based on the App's volumes.
+	for _, pvc := range pvcs {
+		pvc.Namespace = request.Project
+		resourceID := module.KubernetesResourceID(pvc.TypeMeta, pvc.ObjectMeta)
+		resource, err := module.WrapK8sResourceToKusionResource(resourceID, &pvc)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *resource)
+	}
+
+	// Create the Secrets along with the Secret of registry credentials.
+	for _, secret := range secrets {
+		secret.Namespace = request.Project
+		resourceID := module.KubernetesResourceID(secret.TypeMeta, secret.ObjectMeta)
+		resource, err := module.WrapK8sResourceToKusionResource(resourceID, &secret)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *resource)
+	}
+
+	// Create the SecretProviderClasses of the secrets mounted via csi, or the ExternalSecrets
+	// syncing the secrets.
+	for _, secretResource := range secretResources {
+		secretResource.SetNamespace(request.Project)
+		resourceID := module.KubernetesResourceID(
+			metav1.TypeMeta{APIVersion: secretResource.GetAPIVersion(), Kind: secretResource.GetKind()},
+			metav1.ObjectMeta{Name: secretResource.GetName(), Namespace: secretResource.GetNamespace()},
+		)
+		resource, err := module.WrapK8sResourceToKusionResource(resourceID, &secretResource)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *resource)
+	}
+
 	labels := module.MergeMaps(module.UniqueAppLabels(request.Project, request.App), svc.Labels)
 	annotations := module.MergeMaps(svc.Annotations)
 	selectors := module.UniqueAppLabels(request.Project, request.App)
@@ -94,20 +196,68 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 		Name:        uniqueAppName,
 		Namespace:   request.Project,
 	}
+	// The selectors are merged at last to make sure the pods are always selected by the workload.
+	podLabels := module.MergeMaps(labels, svc.PodLabels, selectors)
+	podAnnotations := module.MergeMaps(annotations, svc.PodAnnotations)
+	if svc.ConfigChecksum {
+		// The ConfigMaps watched by the config-reloader are reloaded without restarting the pods.
+		checksum, err := configChecksum(unwatchedConfigMaps(svc.ConfigReload, configMaps, volumes), secrets)
+		if err != nil {
+			return nil, err
+		}
+		if podAnnotations == nil {
+			podAnnotations = make(map[string]string)
+		}
+		podAnnotations[ConfigChecksumAnnotation] = checksum
+	}
 	podTemplateSpec := corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:      labels,
-			Annotations: annotations,
+			Labels:      podLabels,
+			Annotations: podAnnotations,
 		},
 		Spec: corev1.PodSpec{
-			TopologySpreadConstraints: topologySpreadConstraints,
-			Containers:                containers,
-			Volumes:                   volumes,
+			Containers:                    containers,
+			Volumes:                       volumes,
+			TerminationGracePeriodSeconds: svc.TerminationGracePeriodSeconds,
+			SecurityContext:               toV1PodSecurityContext(svc.SecurityContext),
+			ImagePullSecrets:              imagePullSecrets,
 		},
 	}
+	handleScheduling(&svc.Base, &podTemplateSpec.Spec)
+	if svc.OS == OSWindows {
+		podTemplateSpec.Spec.OS = &corev1.PodOS{Name: corev1.Windows}
+	}
+	if svc.RuntimeClassName != "" {
+		podTemplateSpec.Spec.RuntimeClassName = &svc.RuntimeClassName
+	}
+	if svc.HostNetwork {
+		podTemplateSpec.Spec.HostNetwork = true
+		podTemplateSpec.Spec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+	}
+	if svc.TolerateControlPlane {
+		podTemplateSpec.Spec.Tolerations = append(podTemplateSpec.Spec.Tolerations, controlPlaneToleration)
+	}
+	handleDNS(&svc.Base, &podTemplateSpec.Spec)
+
+	// Create the dedicated ServiceAccount the pods run as, which the workload waits on.
+	if sa := handleServiceAccount(&svc.Base, uniqueAppName, &podTemplateSpec.Spec); sa != nil {
+		sa.Namespace = request.Project
+		resourceID := module.KubernetesResourceID(sa.TypeMeta, sa.ObjectMeta)
+		resource, err := module.WrapK8sResourceToKusionResource(resourceID, sa)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *resource)
+		dependsOn = append(dependsOn, resourceID)
+	}
 
 	var k8sResource runtime.Object
 	typeMeta := metav1.TypeMeta{}
+	// The replicas are left to KEDA if the workload is scaled on schedule.
+	replicas := svc.Replicas
+	if svc.ScalingSchedule != nil {
+		replicas = nil
+	}
 
 	switch svc.Type {
 	case Deployment:
@@ -115,10 +265,15 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 			APIVersion: appsv1.SchemeGroupVersion.String(),
 			Kind:       string(Deployment),
 		}
+		strategy, err := deploymentStrategy(svc.UpdateStrategy)
+		if err != nil {
+			return nil, err
+		}
 		spec := appsv1.DeploymentSpec{
-			Replicas: svc.Replicas,
+			Replicas: replicas,
 			Selector: &metav1.LabelSelector{MatchLabels: selectors},
 			Template: podTemplateSpec,
+			Strategy: strategy,
 		}
 		k8sResource = &appsv1.Deployment{
 			TypeMeta:   typeMeta,
@@ -130,15 +285,47 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 			APIVersion: v1alpha1.GroupVersion.String(),
 			Kind:       string(Collaset),
 		}
+		strategy, err := collaSetUpdateStrategy(svc.UpdateStrategy)
+		if err != nil {
+			return nil, err
+		}
 		k8sResource = &v1alpha1.CollaSet{
 			TypeMeta:   typeMeta,
 			ObjectMeta: objectMeta,
 			Spec: v1alpha1.CollaSetSpec{
-				Replicas: svc.Replicas,
-				Selector: &metav1.LabelSelector{MatchLabels: selectors},
-				Template: podTemplateSpec,
+				Replicas:       replicas,
+				Selector:       &metav1.LabelSelector{MatchLabels: selectors},
+				Template:       podTemplateSpec,
+				UpdateStrategy: strategy,
+			},
+		}
+	case DaemonSet:
+		typeMeta = metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       string(DaemonSet),
+		}
+		strategy, err := daemonSetUpdateStrategy(svc.UpdateStrategy)
+		if err != nil {
+			return nil, err
+		}
+		k8sResource = &appsv1.DaemonSet{
+			TypeMeta:   typeMeta,
+			ObjectMeta: objectMeta,
+			Spec: appsv1.DaemonSetSpec{
+				Selector:       &metav1.LabelSelector{MatchLabels: selectors},
+				Template:       podTemplateSpec,
+				UpdateStrategy: strategy,
 			},
 		}
+	case KnativeService:
+		typeMeta = metav1.TypeMeta{
+			APIVersion: knativeAPIVersion,
+			Kind:       knativeServiceKind,
+		}
+		k8sResource, err = generateKnativeService(svc, objectMeta, podTemplateSpec)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// append the Deployment/Collaset resource to res.
@@ -147,8 +334,18 @@ func (svc *Service) Generate(ctx context.Context, request *module.GeneratorReque
 	if err != nil {
 		return nil, err
 	}
+	resource.DependsOn = dependsOn
 	res = append(res, *resource)
 
+	// Create the KEDA ScaledObject scaling the workload on the cron windows.
+	if svc.ScalingSchedule != nil {
+		scaledObject, err := generateScaledObject(svc, typeMeta, objectMeta, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *scaledObject)
+	}
+
 	// validate and complete service ports
 	if len(svc.Ports) != 0 {
 		if err = validate(selectors, svc.Ports); err != nil {
@@ -227,15 +424,143 @@ func completeServiceInput(service *Service, config kusionapiv1.GenericConfig) er
 	if platformServiceType == "" {
 		platformServiceType = Deployment
 	}
-	if platformServiceType != Deployment && platformServiceType != Collaset {
+	if !isSupportedServiceType(platformServiceType) {
 		return fmt.Errorf("unsupported Service type %s", platformServiceType)
 	}
 	if service.Type == "" {
 		service.Type = platformServiceType
 	}
+	if !isSupportedServiceType(service.Type) {
+		return fmt.Errorf("unsupported Service type %s", service.Type)
+	}
+	return completeUpdateStrategy(service, config)
+}
+
+// completeUpdateStrategy uses the update strategy from workspace for CollaSet if it is not declared
+// in the workload, which allows the platform running KusionStack operating to choose e.g. the
+// in-place update for all applications. The strategy is not applied to the other workload types,
+// whose update strategy types differ from CollaSet.
+func completeUpdateStrategy(service *Service, config kusionapiv1.GenericConfig) error {
+	value, ok := config[FieldUpdateStrategy]
+	if !ok || value == nil || service.UpdateStrategy != nil || service.Type != Collaset {
+		return nil
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	platform := &UpdateStrategy{}
+	if err = yaml.Unmarshal(out, platform); err != nil {
+		return fmt.Errorf("invalid updateStrategy config in workspace, %w", err)
+	}
+	service.UpdateStrategy = platform
 	return nil
 }
 
+func isSupportedServiceType(t ServiceType) bool {
+	return t == Deployment || t == Collaset || t == DaemonSet || t == KnativeService
+}
+
+// controlPlaneToleration tolerates the taint of the control-plane nodes.
+var controlPlaneToleration = corev1.Toleration{
+	Key:      "node-role.kubernetes.io/control-plane",
+	Operator: corev1.TolerationOpExists,
+	Effect:   corev1.TaintEffectNoSchedule,
+}
+
+// deploymentStrategy converts the update strategy into the Deployment strategy.
+func deploymentStrategy(in *UpdateStrategy) (appsv1.DeploymentStrategy, error) {
+	result := appsv1.DeploymentStrategy{}
+	if in == nil {
+		return result, nil
+	}
+
+	switch appsv1.DeploymentStrategyType(in.Type) {
+	case "", appsv1.RollingUpdateDeploymentStrategyType:
+		result.Type = appsv1.RollingUpdateDeploymentStrategyType
+		if in.MaxUnavailable != "" || in.MaxSurge != "" {
+			result.RollingUpdate = &appsv1.RollingUpdateDeployment{
+				MaxUnavailable: parseIntOrString(in.MaxUnavailable),
+				MaxSurge:       parseIntOrString(in.MaxSurge),
+			}
+		}
+	case appsv1.RecreateDeploymentStrategyType:
+		result.Type = appsv1.RecreateDeploymentStrategyType
+	default:
+		return result, fmt.Errorf("unsupported update strategy type %s for Deployment", in.Type)
+	}
+	return result, nil
+}
+
+// daemonSetUpdateStrategy converts the update strategy into the DaemonSet update strategy.
+func daemonSetUpdateStrategy(in *UpdateStrategy) (appsv1.DaemonSetUpdateStrategy, error) {
+	result := appsv1.DaemonSetUpdateStrategy{}
+	if in == nil {
+		return result, nil
+	}
+
+	switch appsv1.DaemonSetUpdateStrategyType(in.Type) {
+	case "", appsv1.RollingUpdateDaemonSetStrategyType:
+		result.Type = appsv1.RollingUpdateDaemonSetStrategyType
+		if in.MaxUnavailable != "" || in.MaxSurge != "" {
+			result.RollingUpdate = &appsv1.RollingUpdateDaemonSet{
+				MaxUnavailable: parseIntOrString(in.MaxUnavailable),
+				MaxSurge:       parseIntOrString(in.MaxSurge),
+			}
+		}
+	case appsv1.OnDeleteDaemonSetStrategyType:
+		result.Type = appsv1.OnDeleteDaemonSetStrategyType
+	default:
+		return result, fmt.Errorf("unsupported update strategy type %s for DaemonSet", in.Type)
+	}
+	return result, nil
+}
+
+// collaSetUpdateStrategy converts the update strategy into the CollaSet update strategy.
+func collaSetUpdateStrategy(in *UpdateStrategy) (v1alpha1.UpdateStrategy, error) {
+	result := v1alpha1.UpdateStrategy{}
+	if in == nil {
+		return result, nil
+	}
+
+	switch in.Type {
+	case "", ReplaceUpdateStrategy:
+		result.PodUpdatePolicy = v1alpha1.CollaSetReplacePodUpdateStrategyType
+	case InPlaceIfPossibleStrategy:
+		result.PodUpdatePolicy = v1alpha1.CollaSetInPlaceIfPossiblePodUpdateStrategyType
+	case InPlaceOnlyStrategy:
+		result.PodUpdatePolicy = v1alpha1.CollaSetInPlaceOnlyPodUpdateStrategyType
+	case RecreateStrategy:
+		result.PodUpdatePolicy = v1alpha1.CollaSetRecreatePodUpdateStrategyType
+	default:
+		return result, fmt.Errorf("unsupported update strategy type %s for CollaSet", in.Type)
+	}
+	if in.MaxUnavailable != "" || in.MaxSurge != "" {
+		return result, ErrCollaSetRollingUpdate
+	}
+	if in.Partition != nil {
+		result.RollingUpdate = &v1alpha1.RollingUpdateCollaSetStrategy{
+			ByPartition: &v1alpha1.ByPartition{Partition: in.Partition},
+		}
+	}
+	return result, nil
+}
+
+func parseIntOrString(value string) *intstr.IntOrString {
+	if value == "" {
+		return nil
+	}
+	result := intstr.Parse(value)
+	return &result
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Service{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
 	server.Start(&Service{})
 }
@@ -14,6 +14,7 @@ import (
 	"kusionstack.io/kube-api/apps/v1alpha1"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
 )
 
 func Test_workloadServiceGenerator_Generate(t *testing.T) {
@@ -369,6 +370,8 @@ status: {}
 
 func TestCompleteServiceInput(t *testing.T) {
 	r2 := int32(2)
+	gracePeriod30 := int64(30)
+	gracePeriod60 := int64(60)
 
 	testcases := []struct {
 		name             string
@@ -455,6 +458,61 @@ func TestCompleteServiceInput(t *testing.T) {
 				Type: "Deployment",
 			},
 		},
+		{
+			name: "use termination grace period in workspace config",
+			service: &Service{
+				Base: Base{
+					Containers: map[string]Container{
+						"nginx": {
+							Image: "nginx:v1",
+						},
+					},
+				},
+			},
+			config: kusionapiv1.GenericConfig{
+				"terminationGracePeriodSeconds": 30,
+			},
+			success: true,
+			completedService: &Service{
+				Base: Base{
+					Containers: map[string]Container{
+						"nginx": {
+							Image: "nginx:v1",
+						},
+					},
+					TerminationGracePeriodSeconds: &gracePeriod30,
+				},
+				Type: "Deployment",
+			},
+		},
+		{
+			name: "termination grace period in workload takes precedence",
+			service: &Service{
+				Base: Base{
+					Containers: map[string]Container{
+						"nginx": {
+							Image: "nginx:v1",
+						},
+					},
+					TerminationGracePeriodSeconds: &gracePeriod60,
+				},
+			},
+			config: kusionapiv1.GenericConfig{
+				"terminationGracePeriodSeconds": 30,
+			},
+			success: true,
+			completedService: &Service{
+				Base: Base{
+					Containers: map[string]Container{
+						"nginx": {
+							Image: "nginx:v1",
+						},
+					},
+					TerminationGracePeriodSeconds: &gracePeriod60,
+				},
+				Type: "Deployment",
+			},
+		},
 		{
 			name: "invalid field type",
 			service: &Service{
@@ -503,6 +561,22 @@ func TestCompleteServiceInput(t *testing.T) {
 			success:          false,
 			completedService: nil,
 		},
+		{
+			name: "unsupported type in workload",
+			service: &Service{
+				Base: Base{
+					Containers: map[string]Container{
+						"nginx": {
+							Image: "nginx:v1",
+						},
+					},
+				},
+				Type: "StatefulSet",
+			},
+			config:           nil,
+			success:          false,
+			completedService: nil,
+		},
 	}
 
 	for _, tc := range testcases {
@@ -515,3 +589,370 @@ func TestCompleteServiceInput(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateDaemonSet(t *testing.T) {
+	devConfig := kusionapiv1.Accessory{
+		"type":                 "DaemonSet",
+		"hostNetwork":          true,
+		"tolerateControlPlane": true,
+		"updateStrategy": map[string]interface{}{
+			"maxUnavailable": "10%",
+		},
+		"containers": map[string]interface{}{
+			"agent": map[string]interface{}{
+				"image": "agent:v1",
+				"ports": []interface{}{
+					map[string]interface{}{
+						"containerPort": 9100,
+						"hostPort":      9100,
+					},
+				},
+			},
+		},
+	}
+
+	svc := &Service{}
+	got, err := svc.Generate(context.Background(), &module.GeneratorRequest{
+		Project:   "default",
+		Stack:     "dev",
+		App:       "foo",
+		DevConfig: devConfig,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(got.Resources))
+	assert.Equal(t, "apps/v1:DaemonSet:default:default-dev-foo", got.Resources[0].ID)
+
+	ds := &appsv1.DaemonSet{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[0].Attributes, ds)
+	assert.NoError(t, err)
+	assert.True(t, ds.Spec.Template.Spec.HostNetwork)
+	assert.Equal(t, corev1.DNSClusterFirstWithHostNet, ds.Spec.Template.Spec.DNSPolicy)
+	assert.Equal(t, []corev1.Toleration{controlPlaneToleration}, ds.Spec.Template.Spec.Tolerations)
+	assert.Equal(t, appsv1.RollingUpdateDaemonSetStrategyType, ds.Spec.UpdateStrategy.Type)
+	assert.Equal(t, "10%", ds.Spec.UpdateStrategy.RollingUpdate.MaxUnavailable.String())
+	assert.Equal(t, []corev1.ContainerPort{
+		{ContainerPort: 9100, HostPort: 9100, Protocol: corev1.ProtocolTCP},
+	}, ds.Spec.Template.Spec.Containers[0].Ports)
+
+	// The host network and ports are denied by the baseline Pod Security Standards.
+	_, err = (&Service{}).Generate(context.Background(), &module.GeneratorRequest{
+		Project:        "default",
+		Stack:          "dev",
+		App:            "foo",
+		DevConfig:      devConfig,
+		PlatformConfig: kusionapiv1.GenericConfig{"podSecurity": moduleutil.PodSecurityBaseline},
+	})
+	assert.ErrorIs(t, err, moduleutil.ErrPodSecurityViolation)
+}
+
+func TestGenerateWindows(t *testing.T) {
+	devConfig := kusionapiv1.Accessory{
+		"os": "windows",
+		"containers": map[string]interface{}{
+			"web": map[string]interface{}{
+				"image": "mcr.microsoft.com/windows/servercore/iis",
+			},
+		},
+	}
+
+	svc := &Service{}
+	got, err := svc.Generate(context.Background(), &module.GeneratorRequest{
+		Project:   "default",
+		Stack:     "dev",
+		App:       "foo",
+		DevConfig: devConfig,
+	})
+	assert.NoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[0].Attributes, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, &corev1.PodOS{Name: corev1.Windows}, deployment.Spec.Template.Spec.OS)
+	assert.Nil(t, deployment.Spec.Template.Spec.RuntimeClassName)
+	assert.Equal(t, map[string]string{corev1.LabelOSStable: OSWindows}, deployment.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, []corev1.Toleration{
+		{Key: "os", Operator: corev1.TolerationOpEqual, Value: OSWindows, Effect: corev1.TaintEffectNoSchedule},
+	}, deployment.Spec.Template.Spec.Tolerations)
+
+	devConfig["securityContext"] = map[string]interface{}{"runAsUser": 1000}
+	_, err = (&Service{}).Generate(context.Background(), &module.GeneratorRequest{
+		Project:   "default",
+		Stack:     "dev",
+		App:       "foo",
+		DevConfig: devConfig,
+	})
+	assert.ErrorIs(t, err, ErrLinuxOnlyOption)
+}
+
+func TestGenerateUntrusted(t *testing.T) {
+	svc := &Service{}
+	got, err := svc.Generate(context.Background(), &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"untrusted": true,
+			"containers": map[string]interface{}{
+				"runner": map[string]interface{}{
+					"image": "runner:v1",
+				},
+			},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			"runtimeClass": map[string]interface{}{
+				"untrusted": "gvisor",
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[0].Attributes, deployment)
+	assert.NoError(t, err)
+	runtimeClassName := "gvisor"
+	assert.Equal(t, &runtimeClassName, deployment.Spec.Template.Spec.RuntimeClassName)
+}
+
+func TestUpdateStrategy(t *testing.T) {
+	deployment, err := deploymentStrategy(&UpdateStrategy{Type: "Recreate"})
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}, deployment)
+
+	deployment, err = deploymentStrategy(&UpdateStrategy{MaxSurge: "1", MaxUnavailable: "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, deployment.Type)
+	assert.Equal(t, "1", deployment.RollingUpdate.MaxSurge.String())
+	assert.Equal(t, "0", deployment.RollingUpdate.MaxUnavailable.String())
+
+	_, err = deploymentStrategy(&UpdateStrategy{Type: "OnDelete"})
+	assert.ErrorContains(t, err, "unsupported update strategy type OnDelete for Deployment")
+
+	daemonSet, err := daemonSetUpdateStrategy(&UpdateStrategy{Type: "OnDelete"})
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}, daemonSet)
+
+	_, err = daemonSetUpdateStrategy(&UpdateStrategy{Type: "Recreate"})
+	assert.ErrorContains(t, err, "unsupported update strategy type Recreate for DaemonSet")
+
+	partition := int32(2)
+	collaSet, err := collaSetUpdateStrategy(&UpdateStrategy{Type: "InPlaceIfPossible", Partition: &partition})
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.UpdateStrategy{
+		PodUpdatePolicy: v1alpha1.CollaSetInPlaceIfPossiblePodUpdateStrategyType,
+		RollingUpdate: &v1alpha1.RollingUpdateCollaSetStrategy{
+			ByPartition: &v1alpha1.ByPartition{Partition: &partition},
+		},
+	}, collaSet)
+
+	collaSet, err = collaSetUpdateStrategy(&UpdateStrategy{})
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.UpdateStrategy{PodUpdatePolicy: v1alpha1.CollaSetReplacePodUpdateStrategyType}, collaSet)
+
+	_, err = collaSetUpdateStrategy(&UpdateStrategy{Type: "OnDelete"})
+	assert.ErrorContains(t, err, "unsupported update strategy type OnDelete for CollaSet")
+
+	_, err = collaSetUpdateStrategy(&UpdateStrategy{MaxUnavailable: "10%"})
+	assert.ErrorIs(t, err, ErrCollaSetRollingUpdate)
+}
+
+func TestGenerateCollaSetUpdateStrategy(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"type": "CollaSet",
+		"updateStrategy": map[string]interface{}{
+			"type": "InPlaceOnly",
+		},
+	}
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"containers": map[string]interface{}{
+				"nginx": map[string]interface{}{
+					"image": "nginx:v1",
+				},
+			},
+		},
+		PlatformConfig: platformConfig,
+	}
+
+	got, err := (&Service{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	cs := &v1alpha1.CollaSet{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[0].Attributes, cs)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.CollaSetInPlaceOnlyPodUpdateStrategyType, cs.Spec.UpdateStrategy.PodUpdatePolicy)
+
+	// The update strategy declared in the workload takes precedence.
+	request.DevConfig["updateStrategy"] = map[string]interface{}{
+		"type": "ReplaceUpdate",
+	}
+	got, err = (&Service{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	cs = &v1alpha1.CollaSet{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[0].Attributes, cs)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.CollaSetReplacePodUpdateStrategyType, cs.Spec.UpdateStrategy.PodUpdatePolicy)
+
+	// The update strategy from workspace is only applied to CollaSet.
+	delete(request.DevConfig, "updateStrategy")
+	request.DevConfig["type"] = "Deployment"
+	got, err = (&Service{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, "apps/v1:Deployment:default:default-dev-foo", got.Resources[0].ID)
+}
+
+func TestGenerateInvalidConfig(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"containers": map[string]interface{}{
+				"nginx": map[string]interface{}{
+					"image":  "nginx:v1",
+					"comand": []interface{}{"nginx"},
+				},
+			},
+			"replicas": "2",
+		},
+	}
+	_, err := (&Service{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "containers.nginx.comand: unknown field")
+	assert.ErrorContains(t, err, "replicas: expected integer, got string")
+	var moduleErr *moduleutil.ModuleError
+	if assert.ErrorAs(t, err, &moduleErr) {
+		assert.Equal(t, "service", moduleErr.Module)
+		assert.Equal(t, moduleutil.PhaseValidate, moduleErr.Phase)
+		assert.Equal(t, "containers.nginx.comand", moduleErr.Path)
+	}
+
+	request.DevConfig = kusionapiv1.Accessory{
+		"containers": map[string]interface{}{
+			"nginx": map[string]interface{}{
+				"image": "nginx:v1",
+			},
+		},
+	}
+	request.PlatformConfig = kusionapiv1.GenericConfig{
+		"replica": 2,
+	}
+	_, err = (&Service{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, moduleutil.ErrInvalidConfig)
+	assert.ErrorContains(t, err, "validate Service platform config failed")
+	assert.ErrorContains(t, err, "replica: unknown field")
+}
+
+func TestGeneratePodMetadata(t *testing.T) {
+	devConfig := kusionapiv1.Accessory{
+		"labels":         map[string]interface{}{"team": "foo"},
+		"podLabels":      map[string]interface{}{"sidecar.istio.io/inject": "true", "app.kubernetes.io/name": "bar"},
+		"podAnnotations": map[string]interface{}{"prometheus.io/scrape": "true"},
+		"configChecksum": true,
+		"configMaps": map[string]interface{}{
+			"app": map[string]interface{}{
+				"data": map[string]interface{}{"LOG_LEVEL": "info"},
+			},
+		},
+		"containers": map[string]interface{}{
+			"nginx": map[string]interface{}{
+				"image": "nginx:v1",
+			},
+		},
+	}
+
+	svc := &Service{}
+	got, err := svc.Generate(context.Background(), &module.GeneratorRequest{
+		Project:   "default",
+		Stack:     "dev",
+		App:       "foo",
+		DevConfig: devConfig,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(got.Resources))
+
+	deployment := &appsv1.Deployment{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(got.Resources[1].Attributes, deployment)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/name":       "foo",
+		"app.kubernetes.io/part-of":    "default",
+		"app.kubernetes.io/managed-by": "kusion",
+		"kusionstack.io/project":       "default",
+		"kusionstack.io/stack":         "dev",
+		"team":                         "foo",
+	}, deployment.Labels)
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/name":    "foo",
+		"app.kubernetes.io/part-of": "default",
+		"team":                      "foo",
+		"sidecar.istio.io/inject":   "true",
+	}, deployment.Spec.Template.Labels)
+	assert.Equal(t, "true", deployment.Spec.Template.Annotations["prometheus.io/scrape"])
+	assert.Len(t, deployment.Spec.Template.Annotations[ConfigChecksumAnnotation], 64)
+	assert.Equal(t, map[string]string{moduleutil.AnnotationModule: "service"}, deployment.Annotations)
+}
+
+func TestGeneratePolicies(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"containers": map[string]interface{}{
+				"nginx": map[string]interface{}{
+					"image":           "nginx:v1",
+					"securityContext": map[string]interface{}{"privileged": true},
+				},
+			},
+		},
+		PlatformConfig: kusionapiv1.GenericConfig{
+			moduleutil.PoliciesKey: []interface{}{
+				map[string]interface{}{
+					"name":     "no-privileged",
+					"kinds":    []interface{}{"Deployment"},
+					"path":     "spec.template.spec.containers[*].securityContext.privileged",
+					"operator": "equals",
+					"value":    true,
+					"message":  "the containers must not be privileged",
+				},
+			},
+		},
+	}
+
+	_, err := (&Service{}).Generate(context.Background(), request)
+	assert.ErrorIs(t, err, moduleutil.ErrPolicyViolation)
+	assert.ErrorContains(t, err, "no-privileged: apps/v1:Deployment:default:default-dev-foo: the containers must not be privileged")
+
+	request.DevConfig["containers"] = map[string]interface{}{
+		"nginx": map[string]interface{}{"image": "nginx:v1"},
+	}
+	_, err = (&Service{}).Generate(context.Background(), request)
+	assert.NoError(t, err)
+}
+
+func TestGenerateWithModuleOutputs(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		DevConfig: kusionapiv1.Accessory{
+			"containers": map[string]interface{}{
+				"nginx": map[string]interface{}{
+					"image": "nginx:v1",
+					"env": map[string]interface{}{
+						"DB_SECRET": "${mysql.secretName}",
+					},
+				},
+			},
+		},
+	}
+
+	response, err := (&Service{}).Generate(context.Background(), request)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, response.Resources, 1)
+	assert.Equal(t, []string{"v1:Secret:default:default-dev-foo-mysql-mysql"}, response.Resources[0].DependsOn)
+}
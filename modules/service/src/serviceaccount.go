@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+var (
+	ErrInvalidServiceAccountName = errors.New("name of serviceAccount must be a valid DNS subdomain")
+	ErrEmptyTokenAudience        = errors.New("audience of serviceAccount token must not be empty")
+	ErrInvalidTokenExpiration    = errors.New("expirationSeconds of serviceAccount token must be between 600 and 4294967296")
+	ErrInvalidTokenMountPath     = errors.New("mountPath of serviceAccount token must be a unique absolute path")
+)
+
+const (
+	serviceAccountTokenVolumePrefix = "serviceaccount-token-"
+	serviceAccountTokenPath         = "token"
+
+	// The bounds of the expiration of the projected tokens accepted by the kube-apiserver.
+	minTokenExpirationSeconds = 600
+	maxTokenExpirationSeconds = 1 << 32
+
+	defaultTokenExpirationSeconds = 3600
+)
+
+// completeServiceAccount completes the ServiceAccount with the defaults from workspace, the fields
+// declared in the workload take precedence.
+func completeServiceAccount(base *Base, config kusionapiv1.GenericConfig) error {
+	sa := base.ServiceAccount
+	if sa == nil {
+		return nil
+	}
+	if value, ok := config[FieldServiceAccount]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		platform := &ServiceAccount{}
+		if err = yaml.Unmarshal(out, platform); err != nil {
+			return fmt.Errorf("invalid serviceAccount config in workspace, %w", err)
+		}
+		if sa.AutomountToken == nil {
+			sa.AutomountToken = platform.AutomountToken
+		}
+		if len(sa.Tokens) == 0 {
+			sa.Tokens = platform.Tokens
+		}
+	}
+	if sa.AutomountToken == nil {
+		automount := false
+		sa.AutomountToken = &automount
+	}
+	for i := range sa.Tokens {
+		if sa.Tokens[i].ExpirationSeconds == nil {
+			expiration := int64(defaultTokenExpirationSeconds)
+			sa.Tokens[i].ExpirationSeconds = &expiration
+		}
+	}
+	return validateServiceAccount(sa)
+}
+
+// validateServiceAccount validates the completed ServiceAccount.
+func validateServiceAccount(sa *ServiceAccount) error {
+	if sa.Name != "" {
+		if errs := validation.IsDNS1123Subdomain(sa.Name); len(errs) != 0 {
+			return fmt.Errorf("%w, got %s: %s", ErrInvalidServiceAccountName, sa.Name, strings.Join(errs, "; "))
+		}
+	}
+	mountPaths := make(map[string]bool, len(sa.Tokens))
+	for _, token := range sa.Tokens {
+		if token.Audience == "" {
+			return ErrEmptyTokenAudience
+		}
+		if e := *token.ExpirationSeconds; e < minTokenExpirationSeconds || e > maxTokenExpirationSeconds {
+			return fmt.Errorf("%w, got %d", ErrInvalidTokenExpiration, e)
+		}
+		mountPath := path.Clean(token.MountPath)
+		if !path.IsAbs(token.MountPath) || mountPaths[mountPath] {
+			return fmt.Errorf("%w, got %s", ErrInvalidTokenMountPath, token.MountPath)
+		}
+		mountPaths[mountPath] = true
+	}
+	return nil
+}
+
+// handleServiceAccount sets the ServiceAccount and the token automount of the pods, and returns
+// the dedicated ServiceAccount named after the workload if the existing one is not declared.
+func handleServiceAccount(base *Base, name string, spec *corev1.PodSpec) *corev1.ServiceAccount {
+	sa := base.ServiceAccount
+	if sa == nil {
+		return nil
+	}
+	spec.AutomountServiceAccountToken = sa.AutomountToken
+	if sa.Name != "" {
+		spec.ServiceAccountName = sa.Name
+		return nil
+	}
+	spec.ServiceAccountName = name
+	return &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		AutomountServiceAccountToken: sa.AutomountToken,
+	}
+}
+
+// mountServiceAccountTokens mounts the projected ServiceAccount tokens of the audiences into every
+// container, the tokens are rotated by the kubelet before expiry.
+func mountServiceAccountTokens(sa *ServiceAccount, containers []corev1.Container, volumes []corev1.Volume) ([]corev1.Container, []corev1.Volume) {
+	if sa == nil {
+		return containers, volumes
+	}
+
+	for i, token := range sa.Tokens {
+		name := fmt.Sprintf("%s%d", serviceAccountTokenVolumePrefix, i)
+		volumes = append(volumes, corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{
+							ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+								Audience:          token.Audience,
+								ExpirationSeconds: token.ExpirationSeconds,
+								Path:              serviceAccountTokenPath,
+							},
+						},
+					},
+				},
+			},
+		})
+		for j := range containers {
+			containers[j].VolumeMounts = append(containers[j].VolumeMounts, corev1.VolumeMount{
+				Name:      name,
+				MountPath: token.MountPath,
+				ReadOnly:  true,
+			})
+		}
+	}
+	return containers, volumes
+}
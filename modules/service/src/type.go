@@ -4,6 +4,7 @@ import (
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"moduleutil"
 )
 
 const (
@@ -12,6 +13,7 @@ const (
 	TypeHTTP            = BuiltinModulePrefix + ProbePrefix + "Http"
 	TypeExec            = BuiltinModulePrefix + ProbePrefix + "Exec"
 	TypeTCP             = BuiltinModulePrefix + ProbePrefix + "Tcp"
+	TypeGRPC            = BuiltinModulePrefix + ProbePrefix + "Grpc"
 )
 
 // LabelSelector is a label query over a set of resources.
@@ -57,6 +59,196 @@ type TopologySpreadConstraint struct {
 	MatchLabelKeys []string `yaml:"matchLabelKeys,omitempty" json:"matchLabelKeys,omitempty"`
 }
 
+// Scheduling describes the constraints used to assign the workload's pods to nodes.
+type Scheduling struct {
+	// NodeSelector is a selector which must match a node's labels for the pod to be scheduled on that node.
+	NodeSelector map[string]string `yaml:"nodeSelector,omitempty" json:"nodeSelector,omitempty"`
+	// Tolerations allow the pods to be scheduled onto nodes with matching taints.
+	Tolerations []Toleration `yaml:"tolerations,omitempty" json:"tolerations,omitempty"`
+	// Affinity describes node affinity, pod affinity and pod anti-affinity scheduling rules.
+	Affinity *Affinity `yaml:"affinity,omitempty" json:"affinity,omitempty"`
+	// TopologySpreadConstraints describes how a group of pods ought to spread across topology domains.
+	TopologySpreadConstraints map[string]TopologySpreadConstraint `yaml:"topologySpreadConstraints,omitempty" json:"topologySpreadConstraints,omitempty"`
+}
+
+// Toleration is attached to the pod to tolerate any taint that matches the triple <key,value,effect>.
+type Toleration struct {
+	// Key is the taint key that the toleration applies to. Empty means match all taint keys.
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+	// Operator represents a key's relationship to the value, Exists or Equal.
+	Operator corev1.TolerationOperator `yaml:"operator,omitempty" json:"operator,omitempty"`
+	// Value is the taint value the toleration matches to.
+	Value string `yaml:"value,omitempty" json:"value,omitempty"`
+	// Effect indicates the taint effect to match. Empty means match all taint effects.
+	Effect corev1.TaintEffect `yaml:"effect,omitempty" json:"effect,omitempty"`
+	// TolerationSeconds represents the period of time the toleration tolerates a NoExecute taint.
+	TolerationSeconds *int64 `yaml:"tolerationSeconds,omitempty" json:"tolerationSeconds,omitempty"`
+}
+
+// Affinity is a group of affinity scheduling rules.
+type Affinity struct {
+	// NodeAffinity describes node affinity scheduling rules for the pod.
+	NodeAffinity *NodeAffinity `yaml:"nodeAffinity,omitempty" json:"nodeAffinity,omitempty"`
+	// PodAffinity describes rules to co-locate the pod with other pods.
+	PodAffinity *PodAffinity `yaml:"podAffinity,omitempty" json:"podAffinity,omitempty"`
+	// PodAntiAffinity describes rules to avoid putting the pod together with other pods.
+	PodAntiAffinity *PodAffinity `yaml:"podAntiAffinity,omitempty" json:"podAntiAffinity,omitempty"`
+}
+
+// NodeAffinity is a group of node affinity scheduling rules.
+type NodeAffinity struct {
+	// Required node selector terms, any of which must be satisfied for the pod to be scheduled onto a node.
+	Required []NodeSelectorTerm `yaml:"required,omitempty" json:"required,omitempty"`
+	// Preferred node selector terms, the scheduler prefers nodes which satisfy more of them.
+	Preferred []PreferredSchedulingTerm `yaml:"preferred,omitempty" json:"preferred,omitempty"`
+}
+
+// NodeSelectorTerm represents the requirements to select nodes, which are ANDed.
+type NodeSelectorTerm struct {
+	// MatchExpressions is a list of node selector requirements by node's labels.
+	MatchExpressions []NodeSelectorRequirement `yaml:"matchExpressions,omitempty" json:"matchExpressions,omitempty"`
+}
+
+// NodeSelectorRequirement is a selector that contains values, a key, and an operator that relates the key and values.
+type NodeSelectorRequirement struct {
+	// Key is the label key that the selector applies to.
+	Key string `yaml:"key" json:"key"`
+	// Operator represents a key's relationship to a set of values.
+	// Valid operators are In, NotIn, Exists, DoesNotExist, Gt and Lt.
+	Operator corev1.NodeSelectorOperator `yaml:"operator" json:"operator"`
+	// Values is an array of string values.
+	Values []string `yaml:"values,omitempty" json:"values,omitempty"`
+}
+
+// PreferredSchedulingTerm is a node selector term with a weight.
+type PreferredSchedulingTerm struct {
+	// Weight associated with matching the corresponding term, in the range 1-100.
+	Weight int32 `yaml:"weight" json:"weight"`
+	// Preference is the node selector term associated with the weight.
+	Preference NodeSelectorTerm `yaml:"preference" json:"preference"`
+}
+
+// PodAffinity is a group of inter pod (anti-)affinity scheduling rules.
+type PodAffinity struct {
+	// Required pod affinity terms which must all be satisfied for the pod to be scheduled onto a node.
+	Required []PodAffinityTerm `yaml:"required,omitempty" json:"required,omitempty"`
+	// Preferred pod affinity terms, the scheduler prefers nodes which satisfy more of them.
+	Preferred []WeightedPodAffinityTerm `yaml:"preferred,omitempty" json:"preferred,omitempty"`
+}
+
+// PodAffinityTerm defines a set of pods that this pod should be co-located (affinity)
+// or not co-located (anti-affinity) with.
+type PodAffinityTerm struct {
+	// LabelSelector is a label query over a set of resources, in this case pods.
+	LabelSelector *LabelSelector `yaml:"labelSelector,omitempty" json:"labelSelector,omitempty"`
+	// Namespaces specifies a static list of namespace names that the term applies to.
+	Namespaces []string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+	// TopologyKey is the key of node labels used to determine co-location.
+	TopologyKey string `yaml:"topologyKey" json:"topologyKey"`
+}
+
+// WeightedPodAffinityTerm is a pod affinity term with a weight.
+type WeightedPodAffinityTerm struct {
+	// Weight associated with matching the corresponding term, in the range 1-100.
+	Weight int32 `yaml:"weight" json:"weight"`
+	// PodAffinityTerm is the pod affinity term associated with the weight.
+	PodAffinityTerm PodAffinityTerm `yaml:"podAffinityTerm" json:"podAffinityTerm"`
+}
+
+// PodSecurityContext holds pod-level security attributes and common container settings.
+type PodSecurityContext struct {
+	// RunAsNonRoot indicates that the containers must run as a non-root user.
+	RunAsNonRoot *bool `yaml:"runAsNonRoot,omitempty" json:"runAsNonRoot,omitempty"`
+	// RunAsUser is the UID to run the entrypoint of the container processes.
+	RunAsUser *int64 `yaml:"runAsUser,omitempty" json:"runAsUser,omitempty"`
+	// RunAsGroup is the GID to run the entrypoint of the container processes.
+	RunAsGroup *int64 `yaml:"runAsGroup,omitempty" json:"runAsGroup,omitempty"`
+	// FSGroup is a special supplemental group that applies to all containers in a pod.
+	FSGroup *int64 `yaml:"fsGroup,omitempty" json:"fsGroup,omitempty"`
+	// SeccompProfile is the seccomp options to use by the containers in this pod.
+	SeccompProfile *SeccompProfile `yaml:"seccompProfile,omitempty" json:"seccompProfile,omitempty"`
+}
+
+// SecurityContext holds container-level security attributes.
+type SecurityContext struct {
+	// RunAsNonRoot indicates that the container must run as a non-root user.
+	RunAsNonRoot *bool `yaml:"runAsNonRoot,omitempty" json:"runAsNonRoot,omitempty"`
+	// RunAsUser is the UID to run the entrypoint of the container process.
+	RunAsUser *int64 `yaml:"runAsUser,omitempty" json:"runAsUser,omitempty"`
+	// RunAsGroup is the GID to run the entrypoint of the container process.
+	RunAsGroup *int64 `yaml:"runAsGroup,omitempty" json:"runAsGroup,omitempty"`
+	// Privileged runs the container in privileged mode.
+	Privileged *bool `yaml:"privileged,omitempty" json:"privileged,omitempty"`
+	// AllowPrivilegeEscalation controls whether a process can gain more privileges than its parent process.
+	AllowPrivilegeEscalation *bool `yaml:"allowPrivilegeEscalation,omitempty" json:"allowPrivilegeEscalation,omitempty"`
+	// ReadOnlyRootFilesystem indicates whether the container has a read-only root filesystem.
+	ReadOnlyRootFilesystem *bool `yaml:"readOnlyRootFilesystem,omitempty" json:"readOnlyRootFilesystem,omitempty"`
+	// Capabilities to add or drop when running the container.
+	Capabilities *Capabilities `yaml:"capabilities,omitempty" json:"capabilities,omitempty"`
+	// SeccompProfile is the seccomp options to use by the container.
+	SeccompProfile *SeccompProfile `yaml:"seccompProfile,omitempty" json:"seccompProfile,omitempty"`
+}
+
+// Capabilities represent POSIX capabilities to add or drop from running containers.
+type Capabilities struct {
+	// Added capabilities.
+	Add []string `yaml:"add,omitempty" json:"add,omitempty"`
+	// Removed capabilities.
+	Drop []string `yaml:"drop,omitempty" json:"drop,omitempty"`
+}
+
+// SeccompProfile defines a pod/container's seccomp profile settings.
+type SeccompProfile struct {
+	// Type indicates which kind of seccomp profile will be applied, RuntimeDefault, Localhost or Unconfined.
+	Type corev1.SeccompProfileType `yaml:"type" json:"type"`
+	// LocalhostProfile indicates a profile defined in a file on the node should be used.
+	LocalhostProfile *string `yaml:"localhostProfile,omitempty" json:"localhostProfile,omitempty"`
+}
+
+// SecurityBaseline is the security settings configured in workspace, which are enforced on
+// every workload and override the ones declared by the application.
+type SecurityBaseline struct {
+	// Pod is the enforced pod-level security context.
+	Pod *PodSecurityContext `yaml:"pod,omitempty" json:"pod,omitempty"`
+	// Container is the enforced security context of every container.
+	Container *SecurityContext `yaml:"container,omitempty" json:"container,omitempty"`
+}
+
+// HostAlias holds the mapping between IP and hostnames that will be injected as an entry in the
+// pod's hosts file.
+type HostAlias struct {
+	// IP address of the host file entry.
+	IP string `yaml:"ip" json:"ip"`
+	// Hostnames for the above IP address.
+	Hostnames []string `yaml:"hostnames" json:"hostnames"`
+}
+
+// DNSConfig defines the DNS parameters of a pod in addition to those generated from DNSPolicy.
+type DNSConfig struct {
+	// Nameservers is a list of DNS name server IP addresses.
+	Nameservers []string `yaml:"nameservers,omitempty" json:"nameservers,omitempty"`
+	// Searches is a list of DNS search domains for host-name lookup.
+	Searches []string `yaml:"searches,omitempty" json:"searches,omitempty"`
+	// Options is a list of DNS resolver options, e.g. ndots.
+	Options map[string]string `yaml:"options,omitempty" json:"options,omitempty"`
+}
+
+// RegistryCredentials describes the credentials used to pull the images from private registries.
+type RegistryCredentials struct {
+	// ImagePullSecrets references the existing Secrets in the same namespace for pulling images.
+	ImagePullSecrets []string `yaml:"imagePullSecrets,omitempty" json:"imagePullSecrets,omitempty"`
+	// Registries are the servers of private registries whose credentials are configured in workspace,
+	// a Secret of type kubernetes.io/dockerconfigjson is generated with the credentials of them.
+	Registries []string `yaml:"registries,omitempty" json:"registries,omitempty"`
+}
+
+// RegistryCredential is the credential of a private registry configured in workspace.
+type RegistryCredential struct {
+	Username string `yaml:"username,omitempty" json:"username,omitempty"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+	Email    string `yaml:"email,omitempty" json:"email,omitempty"`
+}
+
 // Container describes how the App's tasks are expected to be run.
 type Container struct {
 	// Image to run for this container
@@ -86,6 +278,177 @@ type Container struct {
 	StartupProbe *Probe `yaml:"startupProbe,omitempty" json:"startupProbe,omitempty"`
 	// Actions that the management system should take in response to container lifecycle events.
 	Lifecycle *Lifecycle `yaml:"lifecycle,omitempty" json:"lifecycle,omitempty"`
+	// SecurityContext defines the security options the container should be run with.
+	SecurityContext *SecurityContext `yaml:"securityContext,omitempty" json:"securityContext,omitempty"`
+	// VolumeMounts mounts the volumes declared in the workload into the container's filesystem.
+	VolumeMounts []VolumeMount `yaml:"volumeMounts,omitempty" json:"volumeMounts,omitempty"`
+	// Ports to expose from the container.
+	Ports []ContainerPort `yaml:"ports,omitempty" json:"ports,omitempty"`
+	// EnvFrom populates environment variables from all the keys of ConfigMaps or Secrets.
+	EnvFrom []EnvFromSource `yaml:"envFrom,omitempty" json:"envFrom,omitempty"`
+	// GPU requests the GPUs of the GPU node pools for the container.
+	GPU *GPU `yaml:"gpu,omitempty" json:"gpu,omitempty"`
+}
+
+// GPU describes the NVIDIA GPUs requested by the container, which are advertised by the NVIDIA
+// device plugin and labeled by the GPU feature discovery on the nodes.
+type GPU struct {
+	// Count is the number of the GPUs, or the MIG instances of the profile.
+	Count int32 `yaml:"count" json:"count"`
+	// Product is the product name of the GPUs the pods are assigned to, e.g. NVIDIA-A100-SXM4-80GB.
+	Product string `yaml:"product,omitempty" json:"product,omitempty"`
+	// MIGProfile is the profile of the MIG instances partitioned from the GPUs, e.g. 1g.10gb, which
+	// requires the mixed strategy of MIG.
+	MIGProfile string `yaml:"migProfile,omitempty" json:"migProfile,omitempty"`
+	// Sharing is exclusive or shared, defaults to exclusive. The shared GPUs are time-sliced with
+	// the other pods and advertised as nvidia.com/gpu.shared.
+	Sharing string `yaml:"sharing,omitempty" json:"sharing,omitempty"`
+}
+
+// EnvFromSource represents the source of a set of ConfigMaps or Secrets.
+// One and only one of ConfigMap and Secret must be specified.
+type EnvFromSource struct {
+	// ConfigMap is the name of the ConfigMap to select from, which might be one declared in the
+	// configMaps of the workload.
+	ConfigMap string `yaml:"configMap,omitempty" json:"configMap,omitempty"`
+	// Secret is the name of the Secret to select from.
+	Secret string `yaml:"secret,omitempty" json:"secret,omitempty"`
+	// Prefix is an optional identifier to prepend to each key.
+	Prefix string `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	// Optional specifies whether the ConfigMap or Secret must be defined.
+	Optional *bool `yaml:"optional,omitempty" json:"optional,omitempty"`
+}
+
+// ConfigMap defines a ConfigMap to be generated along with the workload.
+type ConfigMap struct {
+	// Data contains the literal key/value pairs.
+	Data map[string]string `yaml:"data,omitempty" json:"data,omitempty"`
+	// Files contains the file name and content pairs, which are usually mounted as configuration files.
+	Files map[string]string `yaml:"files,omitempty" json:"files,omitempty"`
+	// Immutable ensures that data stored in the ConfigMap cannot be updated.
+	Immutable bool `yaml:"immutable,omitempty" json:"immutable,omitempty"`
+}
+
+// ContainerPort represents a network port in a single container.
+type ContainerPort struct {
+	// Name of the port, must be unique within the pod if specified.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// Number of port to expose on the pod's IP address.
+	ContainerPort int32 `yaml:"containerPort" json:"containerPort"`
+	// Number of port to expose on the host.
+	HostPort int32 `yaml:"hostPort,omitempty" json:"hostPort,omitempty"`
+	// Protocol for port, support TCP and UDP, defaults to TCP.
+	Protocol Protocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+}
+
+// VolumeMount describes a mounting of a workload volume within a container.
+type VolumeMount struct {
+	// Name of the volume declared in the workload.
+	Name string `yaml:"name" json:"name"`
+	// Path within the container at which the volume should be mounted.
+	MountPath string `yaml:"mountPath" json:"mountPath"`
+	// Path within the volume from which the container's volume should be mounted.
+	SubPath string `yaml:"subPath,omitempty" json:"subPath,omitempty"`
+	// Mounted read-only if true, read-write otherwise.
+	ReadOnly bool `yaml:"readOnly,omitempty" json:"readOnly,omitempty"`
+}
+
+// Volume represents a named volume which can be mounted by any container of the workload.
+// One and only one of the volume sources must be specified.
+type Volume struct {
+	// EmptyDir represents a temporary directory that shares a pod's lifetime.
+	EmptyDir *EmptyDirVolumeSource `yaml:"emptyDir,omitempty" json:"emptyDir,omitempty"`
+	// PVC represents a persistent volume claim, which is generated if the claim name is not specified.
+	PVC *PVCVolumeSource `yaml:"pvc,omitempty" json:"pvc,omitempty"`
+	// ConfigMap represents a configMap that should populate this volume.
+	ConfigMap *ConfigMapVolumeSource `yaml:"configMap,omitempty" json:"configMap,omitempty"`
+	// Secret represents a secret that should populate this volume.
+	Secret *SecretVolumeSource `yaml:"secret,omitempty" json:"secret,omitempty"`
+	// Projected represents a set of volume sources projected into the same directory.
+	Projected *ProjectedVolumeSource `yaml:"projected,omitempty" json:"projected,omitempty"`
+}
+
+// EmptyDirVolumeSource represents an empty directory for a pod.
+type EmptyDirVolumeSource struct {
+	// Medium represents what type of storage medium should back this directory, "" or "Memory".
+	Medium corev1.StorageMedium `yaml:"medium,omitempty" json:"medium,omitempty"`
+	// SizeLimit is the total amount of local storage required for this volume, e.g. 1Gi.
+	SizeLimit string `yaml:"sizeLimit,omitempty" json:"sizeLimit,omitempty"`
+}
+
+// PVCVolumeSource references an existing persistent volume claim, or describes the one to be created.
+type PVCVolumeSource struct {
+	// ClaimName is the name of an existing persistent volume claim.
+	ClaimName string `yaml:"claimName,omitempty" json:"claimName,omitempty"`
+	// StorageClass is the name of the storage class of the claim to be created.
+	StorageClass string `yaml:"storageClass,omitempty" json:"storageClass,omitempty"`
+	// Size is the storage size of the claim to be created, e.g. 10Gi.
+	Size string `yaml:"size,omitempty" json:"size,omitempty"`
+	// AccessModes of the claim to be created, defaults to ReadWriteOnce.
+	AccessModes []corev1.PersistentVolumeAccessMode `yaml:"accessModes,omitempty" json:"accessModes,omitempty"`
+	// ReadOnly will force the ReadOnly setting in VolumeMounts.
+	ReadOnly bool `yaml:"readOnly,omitempty" json:"readOnly,omitempty"`
+}
+
+// ConfigMapVolumeSource adapts a configMap into a volume.
+type ConfigMapVolumeSource struct {
+	// Name of the configMap.
+	Name string `yaml:"name" json:"name"`
+	// Items maps the keys of the configMap to the relative paths within the volume.
+	Items map[string]string `yaml:"items,omitempty" json:"items,omitempty"`
+	// Mode bits used to set permissions on created files by default.
+	DefaultMode string `yaml:"defaultMode,omitempty" json:"defaultMode,omitempty"`
+	// Optional specifies whether the configMap or its keys must be defined.
+	Optional *bool `yaml:"optional,omitempty" json:"optional,omitempty"`
+}
+
+// SecretVolumeSource adapts a secret into a volume.
+type SecretVolumeSource struct {
+	// Name of the secret.
+	Name string `yaml:"name" json:"name"`
+	// Items maps the keys of the secret to the relative paths within the volume.
+	Items map[string]string `yaml:"items,omitempty" json:"items,omitempty"`
+	// Mode bits used to set permissions on created files by default.
+	DefaultMode string `yaml:"defaultMode,omitempty" json:"defaultMode,omitempty"`
+	// Optional specifies whether the secret or its keys must be defined.
+	Optional *bool `yaml:"optional,omitempty" json:"optional,omitempty"`
+}
+
+// ProjectedVolumeSource represents a projected volume source.
+type ProjectedVolumeSource struct {
+	// Sources is the list of volume projections.
+	Sources []VolumeProjection `yaml:"sources" json:"sources"`
+	// Mode bits used to set permissions on created files by default.
+	DefaultMode string `yaml:"defaultMode,omitempty" json:"defaultMode,omitempty"`
+}
+
+// VolumeProjection is a projection that may be projected along with other supported volume types.
+// One and only one of the fields must be specified.
+type VolumeProjection struct {
+	// ConfigMap information about the configMap data to project.
+	ConfigMap *ObjectProjection `yaml:"configMap,omitempty" json:"configMap,omitempty"`
+	// Secret information about the secret data to project.
+	Secret *ObjectProjection `yaml:"secret,omitempty" json:"secret,omitempty"`
+	// ServiceAccountToken information about the service account token data to project.
+	ServiceAccountToken *ServiceAccountTokenProjection `yaml:"serviceAccountToken,omitempty" json:"serviceAccountToken,omitempty"`
+}
+
+// ObjectProjection adapts a configMap or secret into a projected volume.
+type ObjectProjection struct {
+	// Name of the configMap or secret.
+	Name string `yaml:"name" json:"name"`
+	// Items maps the keys of the object to the relative paths within the volume.
+	Items map[string]string `yaml:"items,omitempty" json:"items,omitempty"`
+}
+
+// ServiceAccountTokenProjection represents a projected service account token volume.
+type ServiceAccountTokenProjection struct {
+	// Audience is the intended audience of the token.
+	Audience string `yaml:"audience,omitempty" json:"audience,omitempty"`
+	// ExpirationSeconds is the requested duration of validity of the service account token.
+	ExpirationSeconds *int64 `yaml:"expirationSeconds,omitempty" json:"expirationSeconds,omitempty"`
+	// Path is the path relative to the mount point of the file to project the token into.
+	Path string `yaml:"path" json:"path"`
 }
 
 // FileSpec defines the target file in a Container
@@ -119,6 +482,8 @@ type Probe struct {
 	SuccessThreshold int32 `yaml:"successThreshold,omitempty" json:"successThreshold,omitempty"`
 	// Minimum consecutive failures for the probe to be considered failed after having succeeded.
 	FailureThreshold int32 `yaml:"failureThreshold,omitempty" json:"failureThreshold,omitempty"`
+	// Duration in seconds the pod needs to terminate gracefully upon probe failure.
+	TerminationGracePeriod *int64 `yaml:"terminationGracePeriod,omitempty" json:"terminationGracePeriod,omitempty"`
 }
 
 // ProbeHandler defines a specific action that should be taken in a probe.
@@ -135,6 +500,9 @@ type ProbeHandler struct {
 	// TCPSocket specifies an action involving a TCP port.
 	// +optional
 	*TCPSocketAction `yaml:",inline" json:",inline"`
+	// GRPC specifies an action involving a GRPC port.
+	// +optional
+	*GRPCAction `yaml:",inline" json:",inline"`
 }
 
 // ExecAction describes a "run in container" action.
@@ -159,6 +527,15 @@ type TCPSocketAction struct {
 	URL string `yaml:"url,omitempty" json:"url,omitempty"`
 }
 
+// GRPCAction describes an action based on the gRPC health checking protocol.
+type GRPCAction struct {
+	// Port number of the gRPC service.
+	Port int32 `yaml:"port" json:"port"`
+	// Service is the name of the service to place in the gRPC HealthCheckRequest.
+	// If this is not specified, the default behavior is defined by gRPC.
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+}
+
 // Lifecycle describes actions that the management system should take in response
 // to container lifecycle events.
 type Lifecycle struct {
@@ -200,6 +577,31 @@ type Port struct {
 	Protocol Protocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
 }
 
+// The types of Secret.
+const (
+	SecretTypeBasic       = "basic"
+	SecretTypeToken       = "token"
+	SecretTypeOpaque      = "opaque"
+	SecretTypeCertificate = "certificate"
+	SecretTypeExternal    = "external"
+)
+
+// The providers of the secret store.
+const (
+	SecretStoreAWS      = "aws"
+	SecretStoreAlicloud = "alicloud"
+	SecretStoreVault    = "vault"
+
+	// SecretStoreModeResolve resolves the external secrets at generate time into Secrets.
+	SecretStoreModeResolve = "resolve"
+	// SecretStoreModeCSI mounts the external secrets via the Secrets Store CSI driver, so that
+	// they are never materialized in etcd.
+	SecretStoreModeCSI = "csi"
+	// SecretStoreModeExternalSecret syncs the external secrets into Secrets by the ExternalSecrets
+	// of External Secrets Operator, so that they are never stored in the Kusion state.
+	SecretStoreModeExternalSecret = "externalsecret"
+)
+
 type Secret struct {
 	Type      string            `yaml:"type" json:"type"`
 	Params    map[string]string `yaml:"params,omitempty" json:"params,omitempty"`
@@ -207,12 +609,105 @@ type Secret struct {
 	Immutable bool              `yaml:"immutable,omitempty" json:"immutable,omitempty"`
 }
 
+// SecretStore describes the cloud secret manager configured in workspace, which the data of
+// external secrets are resolved from.
+type SecretStore struct {
+	// Provider of the secret store, aws, alicloud or vault.
+	Provider string `yaml:"provider" json:"provider"`
+	// Region of the secret manager of aws and alicloud.
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+	// Server is the address of vault, or the custom endpoint of the secret manager of aws and alicloud.
+	Server string `yaml:"server,omitempty" json:"server,omitempty"`
+	// Path is the mount path of the KV secrets engine of vault, defaults to secret.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+	// Version is the version of the KV secrets engine of vault, v1 or v2, defaults to v2.
+	Version string `yaml:"version,omitempty" json:"version,omitempty"`
+	// Mode of consuming the external secrets, resolve, csi or externalsecret, defaults to resolve.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+	// StoreRef is the name of the ClusterSecretStore of External Secrets Operator in externalsecret mode.
+	StoreRef string `yaml:"storeRef,omitempty" json:"storeRef,omitempty"`
+	// Role is the role of the Kubernetes auth method of vault used by the CSI driver.
+	Role string `yaml:"role,omitempty" json:"role,omitempty"`
+}
+
 const (
 	FieldLabels      = "labels"
 	FieldAnnotations = "annotations"
 	FieldReplicas    = "replicas"
+
+	FieldTerminationGracePeriodSeconds = "terminationGracePeriodSeconds"
+	FieldScheduling                    = "scheduling"
+	FieldSecurityContext               = "securityContext"
+	FieldRegistryCredentials           = "registryCredentials"
+	FieldUpdateStrategy                = "updateStrategy"
+	FieldSecretStore                   = "secretStore"
+	FieldWindows                       = "windows"
+	FieldGPU                           = "gpu"
+	FieldRuntimeClass                  = "runtimeClass"
+	FieldIdentity                      = "identity"
+	FieldServiceAccount                = "serviceAccount"
+	FieldConfigReload                  = "configReload"
+
+	// ConfigChecksumAnnotation is the pod annotation holding the checksum of the generated configuration.
+	ConfigChecksumAnnotation = "kusionstack.io/config-checksum"
 )
 
+// PlatformConfig describes the platform config of the service module in workspace.
+type PlatformConfig struct {
+	// Type is the default type of workload.Service.
+	Type ServiceType `yaml:"type,omitempty" json:"type,omitempty"`
+	// Labels and Annotations are merged into the ones of the workload.
+	Labels      map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	// Replicas is the default number of containers that should be run.
+	Replicas *int32 `yaml:"replicas,omitempty" json:"replicas,omitempty"`
+	// TerminationGracePeriodSeconds is the default duration in seconds the pod needs to terminate gracefully.
+	TerminationGracePeriodSeconds *int32 `yaml:"terminationGracePeriodSeconds,omitempty" json:"terminationGracePeriodSeconds,omitempty"`
+	// Scheduling is the default scheduling constraints of the pods.
+	Scheduling *Scheduling `yaml:"scheduling,omitempty" json:"scheduling,omitempty"`
+	// SecurityContext is the security baseline enforced on the pods and containers.
+	SecurityContext *SecurityBaseline `yaml:"securityContext,omitempty" json:"securityContext,omitempty"`
+	// RegistryCredentials are the credentials of the private registries keyed by the registry server.
+	RegistryCredentials map[string]RegistryCredential `yaml:"registryCredentials,omitempty" json:"registryCredentials,omitempty"`
+	// UpdateStrategy is the default update strategy of CollaSet.
+	UpdateStrategy *UpdateStrategy `yaml:"updateStrategy,omitempty" json:"updateStrategy,omitempty"`
+	// SecretStore is the cloud secret manager the external secrets are resolved from.
+	SecretStore *SecretStore `yaml:"secretStore,omitempty" json:"secretStore,omitempty"`
+	// Windows is the node selectors and tolerations of the Windows node pools.
+	Windows *Scheduling `yaml:"windows,omitempty" json:"windows,omitempty"`
+	// GPU is the node selectors and tolerations of the GPU node pools.
+	GPU *Scheduling `yaml:"gpu,omitempty" json:"gpu,omitempty"`
+	// RuntimeClass is the runtime classes of the pods enforced by the platform.
+	RuntimeClass *RuntimeClass `yaml:"runtimeClass,omitempty" json:"runtimeClass,omitempty"`
+	// Identity is the default issuance of the workload identity certificates.
+	Identity *WorkloadIdentity `yaml:"identity,omitempty" json:"identity,omitempty"`
+	// ServiceAccount is the default token automount and projected tokens of the ServiceAccounts.
+	ServiceAccount *ServiceAccount `yaml:"serviceAccount,omitempty" json:"serviceAccount,omitempty"`
+	// ConfigReload is the default image, resources and method of the config-reloader sidecars.
+	ConfigReload *ConfigReload `yaml:"configReload,omitempty" json:"configReload,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `yaml:"podSecurity,omitempty" json:"podSecurity,omitempty"`
+	// Policies are the policies checked against the generated resources.
+	Policies []moduleutil.Policy `yaml:"policies,omitempty" json:"policies,omitempty"`
+}
+
+// RuntimeClass describes the runtime classes of the pods enforced by the platform, e.g.
+//
+//	runtimeClass:
+//	  default: runc
+//	  untrusted: gvisor
+//	  allowed: [runc, gvisor, kata]
+type RuntimeClass struct {
+	// Default is the runtime class of the workloads which do not declare one.
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Untrusted is the sandboxed runtime class enforced on the untrusted workloads, e.g. gvisor
+	// or kata, which overrides the one declared in the workload.
+	Untrusted string `yaml:"untrusted,omitempty" json:"untrusted,omitempty"`
+	// Allowed is the runtime classes allowed to be declared in the workloads, any is allowed if empty.
+	Allowed []string `yaml:"allowed,omitempty" json:"allowed,omitempty"`
+}
+
 // Base defines set of attributes shared by different workload profile, e.g. Service and Job.
 type Base struct {
 	// The templates of containers to be run.
@@ -226,11 +721,128 @@ type Base struct {
 	// Labels and Annotations can be used to attach arbitrary metadata as key-value pairs to resources.
 	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// PodLabels and PodAnnotations are attached to the pod template only.
+	PodLabels      map[string]string `json:"podLabels,omitempty" yaml:"podLabels,omitempty"`
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty" yaml:"podAnnotations,omitempty"`
+	// ConfigChecksum injects the checksum of the generated ConfigMaps and Secrets into the pod
+	// annotations, so that the pods are restarted once the configuration is changed. The ConfigMaps
+	// watched by ConfigReload are left out, which are reloaded without restarting the pods.
+	ConfigChecksum bool `json:"configChecksum,omitempty" yaml:"configChecksum,omitempty"`
 	// TopologySpreadConstraints describes how a group of pods ought to spread across topology domains.
 	// Scheduler will schedule pods in a way which abides by the constraints. All topologySpreadConstraints are ANDed.
 	TopologySpreadConstraints map[string]TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty" yaml:"topologySpreadConstraints,omitempty"`
+	// TerminationGracePeriodSeconds is the duration in seconds the pod needs to terminate gracefully.
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty" yaml:"terminationGracePeriodSeconds,omitempty"`
+	// Scheduling describes the constraints used to assign the workload's pods to nodes.
+	Scheduling *Scheduling `json:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+	// SecurityContext holds pod-level security attributes and common container settings.
+	SecurityContext *PodSecurityContext `json:"securityContext,omitempty" yaml:"securityContext,omitempty"`
+	// Volumes declares the volumes that can be mounted by the containers of the workload.
+	Volumes map[string]Volume `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	// ConfigMaps declares the ConfigMaps to be generated along with the workload.
+	ConfigMaps map[string]ConfigMap `json:"configMaps,omitempty" yaml:"configMaps,omitempty"`
+	// HostAliases is a list of hosts and IPs that will be injected into the pod's hosts file.
+	HostAliases []HostAlias `json:"hostAliases,omitempty" yaml:"hostAliases,omitempty"`
+	// DNSPolicy sets the DNS policy of the pod, e.g. ClusterFirst, Default and None.
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty" yaml:"dnsPolicy,omitempty"`
+	// DNSConfig specifies the DNS parameters of the pod.
+	DNSConfig *DNSConfig `json:"dnsConfig,omitempty" yaml:"dnsConfig,omitempty"`
+	// RegistryCredentials describes the credentials used to pull the images from private registries.
+	RegistryCredentials *RegistryCredentials `json:"registryCredentials,omitempty" yaml:"registryCredentials,omitempty"`
+	// OS is the operating system of the nodes the pods run on, linux or windows, defaults to linux.
+	OS string `json:"os,omitempty" yaml:"os,omitempty"`
+	// RuntimeClassName is the RuntimeClass used to run the pods, e.g. gvisor or kata.
+	RuntimeClassName string `json:"runtimeClassName,omitempty" yaml:"runtimeClassName,omitempty"`
+	// Untrusted marks the workload running untrusted code, which runs in the sandboxed runtime
+	// class enforced by the platform.
+	Untrusted bool `json:"untrusted,omitempty" yaml:"untrusted,omitempty"`
+	// Identity issues the workload identity certificates to the pods for the mTLS between services.
+	Identity *WorkloadIdentity `json:"identity,omitempty" yaml:"identity,omitempty"`
+	// ServiceAccount runs the pods as the dedicated ServiceAccount with the projected tokens.
+	ServiceAccount *ServiceAccount `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+	// ConfigReload injects the config-reloader sidecar calling the reload endpoint of the
+	// application once the mounted ConfigMaps are updated, instead of restarting the pods.
+	ConfigReload *ConfigReload `json:"configReload,omitempty" yaml:"configReload,omitempty"`
+}
+
+// ConfigReload describes the config-reloader sidecar, which watches the ConfigMap volumes of the
+// pods and calls the reload endpoint of the application once they are updated, e.g.
+//
+//	configReload:
+//	  url: http://localhost:9090/-/reload
+//	  volumes: [rules]
+type ConfigReload struct {
+	// URL is the reload endpoint of the application, e.g. http://localhost:9090/-/reload.
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// Method is the HTTP method of the reload requests, GET, POST or PUT, defaults to POST.
+	Method string `yaml:"method,omitempty" json:"method,omitempty"`
+	// Volumes are the names of the ConfigMap volumes declared in the workload to watch. All the
+	// ConfigMap volumes of the pods are watched if empty, including the ones of the files.
+	Volumes []string `yaml:"volumes,omitempty" json:"volumes,omitempty"`
+	// Image of the config-reloader, defaults to ghcr.io/jimmidyson/configmap-reload:v0.14.0.
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+	// Resources of the config-reloader, e.g. cpu: 10m-100m.
+	Resources map[string]string `yaml:"resources,omitempty" json:"resources,omitempty"`
+}
+
+// ServiceAccount describes the ServiceAccount the pods run as, and the tokens of it projected into
+// the pods, which are bound to the audiences and rotated by the kubelet before expiry.
+type ServiceAccount struct {
+	// Name of the existing ServiceAccount. A dedicated ServiceAccount named after the workload is
+	// generated if empty.
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	// AutomountToken mounts the default token of the ServiceAccount into the pods, defaults to false.
+	AutomountToken *bool `yaml:"automountToken,omitempty" json:"automountToken,omitempty"`
+	// Tokens are the tokens projected into every container.
+	Tokens []ProjectedToken `yaml:"tokens,omitempty" json:"tokens,omitempty"`
 }
 
+// ProjectedToken describes the ServiceAccount token of the audience projected into the containers.
+type ProjectedToken struct {
+	// Audience is the intended audience of the token, e.g. sts.amazonaws.com or vault.
+	Audience string `yaml:"audience" json:"audience"`
+	// ExpirationSeconds is the requested duration of validity of the token, defaults to 3600.
+	ExpirationSeconds *int64 `yaml:"expirationSeconds,omitempty" json:"expirationSeconds,omitempty"`
+	// MountPath is the directory the token file named token is mounted to.
+	MountPath string `yaml:"mountPath" json:"mountPath"`
+}
+
+// WorkloadIdentity describes the identity certificates issued to the pods, which are mounted by the
+// CSI drivers of cert-manager and rotated by them before expiry.
+type WorkloadIdentity struct {
+	// Driver is the CSI driver issuing the certificates, cert-manager or spiffe, defaults to
+	// cert-manager. The certificates of spiffe are issued by the issuer configured in the driver.
+	Driver string `yaml:"driver,omitempty" json:"driver,omitempty"`
+	// Issuer is the cert-manager issuer signing the certificates of the cert-manager driver.
+	Issuer *CertificateIssuer `yaml:"issuer,omitempty" json:"issuer,omitempty"`
+	// MountPath is the directory the certificates are mounted to in every container, defaults to
+	// /var/run/secrets/workload-identity.
+	MountPath string `yaml:"mountPath,omitempty" json:"mountPath,omitempty"`
+	// DNSNames are the DNS names of the certificates of the cert-manager driver, which may contain
+	// the variables of the driver, e.g. ${POD_NAME}.
+	DNSNames []string `yaml:"dnsNames,omitempty" json:"dnsNames,omitempty"`
+	// Duration is the requested duration of the certificates of the cert-manager driver, e.g. 24h.
+	Duration string `yaml:"duration,omitempty" json:"duration,omitempty"`
+	// RenewBefore is how long before expiry the certificates of the cert-manager driver are renewed.
+	RenewBefore string `yaml:"renewBefore,omitempty" json:"renewBefore,omitempty"`
+}
+
+// CertificateIssuer references the issuer of cert-manager.
+type CertificateIssuer struct {
+	// Name of the issuer.
+	Name string `yaml:"name" json:"name"`
+	// Kind of the issuer, Issuer or ClusterIssuer, defaults to Issuer.
+	Kind string `yaml:"kind,omitempty" json:"kind,omitempty"`
+	// Group of the issuer, defaults to cert-manager.io.
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
+// The operating systems of the nodes the pods run on.
+const (
+	OSLinux   = "linux"
+	OSWindows = "windows"
+)
+
 type ServiceType string
 
 const (
@@ -238,15 +850,101 @@ const (
 	ModuleServiceType             = "type"
 	Deployment        ServiceType = "Deployment"
 	Collaset          ServiceType = "CollaSet"
+	DaemonSet         ServiceType = "DaemonSet"
+	KnativeService    ServiceType = "KnativeService"
+)
+
+// The update strategy types of CollaSet.
+const (
+	ReplaceUpdateStrategy     = "ReplaceUpdate"
+	InPlaceIfPossibleStrategy = "InPlaceIfPossible"
+	InPlaceOnlyStrategy       = "InPlaceOnly"
+	RecreateStrategy          = "Recreate"
 )
 
+// UpdateStrategy describes how to replace the existing pods with new ones.
+type UpdateStrategy struct {
+	// Type of the update strategy, RollingUpdate or Recreate for Deployment, RollingUpdate or OnDelete
+	// for DaemonSet, and ReplaceUpdate, InPlaceIfPossible, InPlaceOnly or Recreate for CollaSet.
+	Type string `yaml:"type,omitempty" json:"type,omitempty"`
+	// MaxUnavailable is the maximum number or percentage of pods that can be unavailable during the update.
+	MaxUnavailable string `yaml:"maxUnavailable,omitempty" json:"maxUnavailable,omitempty"`
+	// MaxSurge is the maximum number or percentage of pods that can be scheduled above the desired number.
+	MaxSurge string `yaml:"maxSurge,omitempty" json:"maxSurge,omitempty"`
+	// Partition is the number of pods of CollaSet to retain the old revision during the update.
+	Partition *int32 `yaml:"partition,omitempty" json:"partition,omitempty"`
+}
+
 // Service is a kind of workload profile that describes how to run your application code.
 // This is typically used for long-running web applications that should "never" go down, and handle short-lived latency-sensitive
 // web requests, or events.
 type Service struct {
 	Base `yaml:",inline" json:",inline"`
-	// Type represents the type of workload.Service, support Deployment and CollaSet.
+	// Type represents the type of workload.Service, support Deployment, CollaSet, DaemonSet and
+	// KnativeService.
 	Type ServiceType `yaml:"type" json:"type"`
 	// Ports describe the list of ports need getting exposed.
 	Ports []Port `yaml:"ports,omitempty" json:"ports,omitempty"`
+	// UpdateStrategy describes how to replace the existing pods with new ones.
+	UpdateStrategy *UpdateStrategy `yaml:"updateStrategy,omitempty" json:"updateStrategy,omitempty"`
+	// HostNetwork indicates the pods use the host's network namespace.
+	HostNetwork bool `yaml:"hostNetwork,omitempty" json:"hostNetwork,omitempty"`
+	// TolerateControlPlane allows the pods to be scheduled onto the control-plane nodes, which
+	// is typically used by the node agents running as DaemonSet.
+	TolerateControlPlane bool `yaml:"tolerateControlPlane,omitempty" json:"tolerateControlPlane,omitempty"`
+	// ScalingSchedule scales the workload on the cron windows, e.g. for the predictable traffic
+	// peaks during the business hours.
+	ScalingSchedule *ScalingSchedule `yaml:"scalingSchedule,omitempty" json:"scalingSchedule,omitempty"`
+	// Knative describes the autoscaling and the traffic routing of KnativeService.
+	Knative *Knative `yaml:"knative,omitempty" json:"knative,omitempty"`
+}
+
+// Knative describes the autoscaling and the traffic routing of the revisions of KnativeService.
+type Knative struct {
+	// MinScale is the minimum number of the pods of each revision, and the revisions are scaled
+	// to zero when idle if it is 0. Defaults to the one of Knative in the cluster.
+	MinScale *int32 `yaml:"minScale,omitempty" json:"minScale,omitempty"`
+	// MaxScale is the maximum number of the pods of each revision, unlimited if 0.
+	MaxScale int32 `yaml:"maxScale,omitempty" json:"maxScale,omitempty"`
+	// Target is the soft limit of the concurrent requests per pod the autoscaler aims at.
+	Target int32 `yaml:"target,omitempty" json:"target,omitempty"`
+	// ContainerConcurrency is the hard limit of the concurrent requests per pod, unlimited if 0.
+	ContainerConcurrency int64 `yaml:"containerConcurrency,omitempty" json:"containerConcurrency,omitempty"`
+	// Revision is the name of the revision created by the workload, which is prefixed with the
+	// name of the workload, e.g. v2. The revisions are named by Knative if empty.
+	Revision string `yaml:"revision,omitempty" json:"revision,omitempty"`
+	// Traffic splits the requests across the revisions, and all the requests are routed to the
+	// latest ready revision if empty.
+	Traffic []TrafficTarget `yaml:"traffic,omitempty" json:"traffic,omitempty"`
+}
+
+// TrafficTarget describes the percent of the requests routed to a revision.
+type TrafficTarget struct {
+	// Revision is the name of the revision without the prefix of the workload name, e.g. v1.
+	Revision string `yaml:"revision,omitempty" json:"revision,omitempty"`
+	// LatestRevision routes the requests to the latest ready revision instead.
+	LatestRevision bool `yaml:"latestRevision,omitempty" json:"latestRevision,omitempty"`
+	// Percent is the percent of the requests routed to the revision.
+	Percent int64 `yaml:"percent" json:"percent"`
+	// Tag exposes the revision on the dedicated URL, e.g. canary.
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
+}
+
+// ScalingSchedule describes the cron windows scaling the workload to the target replicas, which
+// is implemented with the cron triggers of KEDA.
+type ScalingSchedule struct {
+	// Timezone of the cron windows in the IANA time zone database, defaults to UTC.
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+	// Windows are the cron windows with the target replicas.
+	Windows []ScalingWindow `yaml:"windows" json:"windows"`
+}
+
+// ScalingWindow describes a cron window during which the workload is scaled to the replicas.
+type ScalingWindow struct {
+	// Start is the cron expression when the window starts, e.g. "0 8 * * 1-5".
+	Start string `yaml:"start" json:"start"`
+	// End is the cron expression when the window ends, e.g. "0 20 * * 1-5".
+	End string `yaml:"end" json:"end"`
+	// Replicas is the target number of replicas during the window.
+	Replicas int32 `yaml:"replicas" json:"replicas"`
 }
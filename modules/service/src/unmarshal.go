@@ -27,6 +27,10 @@ func (p *ProbeHandler) UnmarshalJSON(data []byte) error {
 		handler := &TCPSocketAction{}
 		err = json.Unmarshal(data, handler)
 		p.TCPSocketAction = handler
+	case TypeGRPC:
+		handler := &GRPCAction{}
+		err = json.Unmarshal(data, handler)
+		p.GRPCAction = handler
 	default:
 		return errors.New("unrecognized probe handler type")
 	}
@@ -56,6 +60,10 @@ func (p *ProbeHandler) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		handler := &TCPSocketAction{}
 		err = unmarshal(handler)
 		p.TCPSocketAction = handler
+	case TypeGRPC:
+		handler := &GRPCAction{}
+		err = unmarshal(handler)
+		p.GRPCAction = handler
 	default:
 		return errors.New("unrecognized probe handler type")
 	}
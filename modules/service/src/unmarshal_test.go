@@ -74,6 +74,23 @@ func TestContainerUnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		{
+			input: `{"image":"nginx:v1","livenessProbe":{"probeHandler":{"_type":"service.container.probe.Grpc","port":9090,"service":"health"},"failureThreshold":3,"terminationGracePeriod":30}}`,
+			result: Container{
+				Image: "nginx:v1",
+				LivenessProbe: &Probe{
+					ProbeHandler: &ProbeHandler{
+						TypeWrapper: TypeWrapper{Type: "service.container.probe.Grpc"},
+						GRPCAction: &GRPCAction{
+							Port:    9090,
+							Service: "health",
+						},
+					},
+					FailureThreshold:       3,
+					TerminationGracePeriod: &[]int64{30}[0],
+				},
+			},
+		},
 		{
 			input: `{"image":"nginx:v1","lifecycle":{"preStop":{"_type":"service.container.probe.Exec","command":["/bin/sh","-c","echo Hello from the postStart handler \u003e /usr/share/message"]},"postStart":{"_type":"service.container.probe.Exec","command":["/bin/sh","-c","nginx -s quit; while killall -0 nginx; do sleep 1; done"]}}}`,
 			result: Container{
@@ -286,6 +303,29 @@ readinessProbe:
 		},
 		{
 			input: `image: nginx:v1
+livenessProbe:
+  probeHandler:
+    _type: service.container.probe.Grpc
+    port: 9090
+  failureThreshold: 3
+  terminationGracePeriod: 30
+`,
+			result: Container{
+				Image: "nginx:v1",
+				LivenessProbe: &Probe{
+					ProbeHandler: &ProbeHandler{
+						TypeWrapper: TypeWrapper{Type: "service.container.probe.Grpc"},
+						GRPCAction: &GRPCAction{
+							Port: 9090,
+						},
+					},
+					FailureThreshold:       3,
+					TerminationGracePeriod: &[]int64{30}[0],
+				},
+			},
+		},
+		{
+			input: `image: nginx:v1
 command:
 - /bin/sh
 - -c
@@ -1,17 +1,28 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/imdario/mergo"
 	"golang.org/x/exp/maps"
+	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
 	"kusionstack.io/kusion-module-framework/pkg/module"
@@ -19,10 +30,31 @@ import (
 	"kusionstack.io/kusion/pkg/util/net"
 )
 
+var (
+	ErrInvalidVolumeSource = errors.New("one and only one volume source must be specified")
+	ErrEmptyPVCSize        = errors.New("size must be specified if the claim name of pvc is empty")
+	ErrEmptyContainers     = errors.New("at least one container must be specified")
+	ErrInvalidEnvFrom      = errors.New("one and only one of configMap and secret must be specified in envFrom")
+	ErrUnsupportedOS       = errors.New("os must be linux or windows")
+	ErrLinuxOnlyOption     = errors.New("linux-only options are not supported by windows pods")
+
+	ErrUnsupportedResource   = errors.New("resource must be cpu, memory, ephemeral-storage, hugepages-<size> or an extended resource named <domain>/<name>")
+	ErrOvercommittedResource = errors.New("request and limit of hugepages and extended resources must be equal")
+	ErrFractionalResource    = errors.New("extended resources must be whole numbers")
+	ErrHugePagesOnly         = errors.New("hugepages require cpu or memory to be specified")
+
+	ErrUnsupportedRuntimeClass = errors.New("runtimeClassName is not allowed by the platform")
+	ErrEmptySandboxRuntime     = errors.New("untrusted workloads must specify a sandboxed runtimeClassName if not enforced by the platform")
+)
+
 func toOrderedContainers(
 	appContainers map[string]Container,
 	uniqueAppName string,
 ) ([]corev1.Container, []corev1.Volume, []corev1.ConfigMap, error) {
+	if err := validateContainers(appContainers); err != nil {
+		return nil, nil, nil, err
+	}
+
 	// Create a slice of containers based on the App's containers.
 	var containers []corev1.Container
 
@@ -73,6 +105,34 @@ func toOrderedContainers(
 		volumes = append(volumes, otherVolumes...)
 		ctn.VolumeMounts = append(ctn.VolumeMounts, otherVolumeMounts...)
 
+		for _, p := range c.Ports {
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = TCP
+			}
+			ctn.Ports = append(ctn.Ports, corev1.ContainerPort{
+				Name:          p.Name,
+				ContainerPort: p.ContainerPort,
+				HostPort:      p.HostPort,
+				Protocol:      corev1.Protocol(protocol),
+			})
+		}
+
+		envFrom, err := handleEnvFrom(c.EnvFrom)
+		if err != nil {
+			return fmt.Errorf("invalid envFrom of container %s, %w", containerName, err)
+		}
+		ctn.EnvFrom = envFrom
+
+		for _, m := range c.VolumeMounts {
+			ctn.VolumeMounts = append(ctn.VolumeMounts, corev1.VolumeMount{
+				Name:      m.Name,
+				MountPath: m.MountPath,
+				SubPath:   m.SubPath,
+				ReadOnly:  m.ReadOnly,
+			})
+		}
+
 		// Append the container object to the containers slice.
 		containers = append(containers, ctn)
 		return nil
@@ -82,6 +142,39 @@ func toOrderedContainers(
 	return containers, volumes, configMaps, nil
 }
 
+// validateContainers validates the containers running in the same pod. As the containers
+// share the network namespace of the pod, the port names and the port-protocol pairs must
+// be unique across all the containers.
+func validateContainers(appContainers map[string]Container) error {
+	if len(appContainers) == 0 {
+		return ErrEmptyContainers
+	}
+
+	portNames := make(map[string]string)
+	portProtocols := make(map[string]string)
+	return module.ForeachOrdered(appContainers, func(containerName string, c Container) error {
+		for _, p := range c.Ports {
+			if p.Name != "" {
+				if other, ok := portNames[p.Name]; ok {
+					return fmt.Errorf("port name %s of container %s is duplicate with container %s", p.Name, containerName, other)
+				}
+				portNames[p.Name] = containerName
+			}
+
+			protocol := p.Protocol
+			if protocol == "" {
+				protocol = TCP
+			}
+			portProtocol := fmt.Sprintf("%d-%s", p.ContainerPort, protocol)
+			if other, ok := portProtocols[portProtocol]; ok {
+				return fmt.Errorf("port %d/%s of container %s is duplicate with container %s", p.ContainerPort, protocol, containerName, other)
+			}
+			portProtocols[portProtocol] = containerName
+		}
+		return nil
+	})
+}
+
 // updateContainer updates corev1.Container with passed parameters.
 func updateContainer(in *Container, out *corev1.Container) error {
 	if in.ReadinessProbe != nil {
@@ -116,6 +209,8 @@ func updateContainer(in *Container, out *corev1.Container) error {
 		out.Lifecycle = lifecycle
 	}
 
+	out.SecurityContext = toV1SecurityContext(in.SecurityContext)
+
 	return nil
 }
 
@@ -141,7 +236,57 @@ func handleResourceRequirementsV1(resources map[string]string) (corev1.ResourceR
 		}
 		maps.Copy(result.Limits, limits)
 	}
-	return result, nil
+	return result, validateResourceRequirements(resources, result)
+}
+
+// extendedResourcePattern matches the names of the extended resources advertised by the device
+// plugins, e.g. nvidia.com/gpu and amd.com/gpu.
+var extendedResourcePattern = regexp.MustCompile(
+	`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+
+// validateResourceRequirements validates the names and values of the container resources. Besides
+// cpu, memory and ephemeral-storage, the hugepages and the extended resources are supported, which
+// can not be overcommitted, and the extended resources must be whole numbers.
+func validateResourceRequirements(resources map[string]string, result corev1.ResourceRequirements) error {
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hugePages := false
+	for _, key := range names {
+		name := corev1.ResourceName(key)
+		switch {
+		case name == corev1.ResourceCPU || name == corev1.ResourceMemory || name == corev1.ResourceEphemeralStorage:
+			continue
+		case strings.HasPrefix(key, corev1.ResourceHugePagesPrefix):
+			if _, err := resource.ParseQuantity(strings.TrimPrefix(key, corev1.ResourceHugePagesPrefix)); err != nil {
+				return fmt.Errorf("%w, got %s", ErrUnsupportedResource, key)
+			}
+			hugePages = true
+		case extendedResourcePattern.MatchString(key) && !strings.Contains(key, "kubernetes.io/"):
+			if quantity, ok := result.Limits[name]; ok && quantity.MilliValue()%1000 != 0 {
+				return fmt.Errorf("%w, got %s=%s", ErrFractionalResource, key, resources[key])
+			}
+		default:
+			return fmt.Errorf("%w, got %s", ErrUnsupportedResource, key)
+		}
+
+		request, ok := result.Requests[name]
+		if limit := result.Limits[name]; ok && !request.Equal(limit) {
+			return fmt.Errorf("%w, got %s=%s", ErrOvercommittedResource, key, resources[key])
+		}
+	}
+
+	if hugePages {
+		_, cpu := result.Limits[corev1.ResourceCPU]
+		_, memory := result.Limits[corev1.ResourceMemory]
+		if !cpu && !memory {
+			return ErrHugePagesOnly
+		}
+	}
+	return nil
 }
 
 // populateResourceLists takes strings of form <resourceName>=[<minValue>-]<maxValue> and
@@ -176,13 +321,17 @@ func populateResourceLists(name corev1.ResourceName, spec string) (corev1.Resour
 // convertKusionProbeToV1Probe converts Kusion Probe to Kubernetes Probe types.
 func convertKusionProbeToV1Probe(p *Probe) (*corev1.Probe, error) {
 	result := &corev1.Probe{
-		InitialDelaySeconds: p.InitialDelaySeconds,
-		TimeoutSeconds:      p.TimeoutSeconds,
-		PeriodSeconds:       p.PeriodSeconds,
-		SuccessThreshold:    p.SuccessThreshold,
-		FailureThreshold:    p.FailureThreshold,
+		InitialDelaySeconds:           p.InitialDelaySeconds,
+		TimeoutSeconds:                p.TimeoutSeconds,
+		PeriodSeconds:                 p.PeriodSeconds,
+		SuccessThreshold:              p.SuccessThreshold,
+		FailureThreshold:              p.FailureThreshold,
+		TerminationGracePeriodSeconds: p.TerminationGracePeriod,
 	}
 	probeHandler := p.ProbeHandler
+	if probeHandler == nil {
+		return nil, errors.New("probe handler must be specified")
+	}
 	switch probeHandler.Type {
 	case TypeHTTP:
 		action, err := httpGetAction(probeHandler.HTTPGetAction.URL, probeHandler.Headers)
@@ -198,6 +347,10 @@ func convertKusionProbeToV1Probe(p *Probe) (*corev1.Probe, error) {
 			return nil, err
 		}
 		result.TCPSocket = action
+	case TypeGRPC:
+		result.GRPC = grpcAction(probeHandler.GRPCAction)
+	default:
+		return nil, fmt.Errorf("unsupported probe handler type: %s", probeHandler.Type)
 	}
 	return result, nil
 }
@@ -277,6 +430,14 @@ func tcpSocketAction(urlstr string) (*corev1.TCPSocketAction, error) {
 	}, nil
 }
 
+func grpcAction(in *GRPCAction) *corev1.GRPCAction {
+	result := &corev1.GRPCAction{Port: in.Port}
+	if in.Service != "" {
+		result.Service = &in.Service
+	}
+	return result
+}
+
 // handleFileCreation handles the creation of the files declared in container.Files
 // and returns the generated ConfigMap, Volume and VolumeMount.
 func handleFileCreation(c Container, uniqueAppName, containerName string) (
@@ -390,6 +551,518 @@ func handleDirCreation(c Container) (volumes []corev1.Volume, volumeMounts []cor
 	return
 }
 
+func handleEnvFrom(sources []EnvFromSource) ([]corev1.EnvFromSource, error) {
+	var result []corev1.EnvFromSource
+	for _, source := range sources {
+		envFrom := corev1.EnvFromSource{Prefix: source.Prefix}
+		switch {
+		case source.ConfigMap != "" && source.Secret == "":
+			envFrom.ConfigMapRef = &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.ConfigMap},
+				Optional:             source.Optional,
+			}
+		case source.Secret != "" && source.ConfigMap == "":
+			envFrom.SecretRef = &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: source.Secret},
+				Optional:             source.Optional,
+			}
+		default:
+			return nil, ErrInvalidEnvFrom
+		}
+		result = append(result, envFrom)
+	}
+	return result, nil
+}
+
+// handleConfigMaps generates the ConfigMaps declared in the workload, which are named with the
+// unique app name as prefix. The references to the declared ConfigMaps in envFrom and volumes
+// are replaced with the generated names.
+func handleConfigMaps(base *Base, uniqueAppName string) []corev1.ConfigMap {
+	if len(base.ConfigMaps) == 0 {
+		return nil
+	}
+
+	var configMaps []corev1.ConfigMap
+	_ = module.ForeachOrdered(base.ConfigMaps, func(name string, cm ConfigMap) error {
+		data := make(map[string]string, len(cm.Data)+len(cm.Files))
+		maps.Copy(data, cm.Data)
+		maps.Copy(data, cm.Files)
+
+		configMap := corev1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "ConfigMap",
+				APIVersion: corev1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: generatedConfigMapName(uniqueAppName, name),
+			},
+			Data: data,
+		}
+		if cm.Immutable {
+			configMap.Immutable = &cm.Immutable
+		}
+		configMaps = append(configMaps, configMap)
+		return nil
+	})
+
+	for containerName, c := range base.Containers {
+		for i := range c.EnvFrom {
+			if _, ok := base.ConfigMaps[c.EnvFrom[i].ConfigMap]; ok {
+				c.EnvFrom[i].ConfigMap = generatedConfigMapName(uniqueAppName, c.EnvFrom[i].ConfigMap)
+			}
+		}
+		base.Containers[containerName] = c
+	}
+	for _, v := range base.Volumes {
+		if v.ConfigMap != nil {
+			if _, ok := base.ConfigMaps[v.ConfigMap.Name]; ok {
+				v.ConfigMap.Name = generatedConfigMapName(uniqueAppName, v.ConfigMap.Name)
+			}
+		}
+		if v.Projected != nil {
+			for _, source := range v.Projected.Sources {
+				if source.ConfigMap == nil {
+					continue
+				}
+				if _, ok := base.ConfigMaps[source.ConfigMap.Name]; ok {
+					source.ConfigMap.Name = generatedConfigMapName(uniqueAppName, source.ConfigMap.Name)
+				}
+			}
+		}
+	}
+	return configMaps
+}
+
+func generatedConfigMapName(uniqueAppName, name string) string {
+	return uniqueAppName + "-" + name
+}
+
+// handleDNS sets the host aliases and DNS settings of the workload into the pod spec.
+func handleDNS(base *Base, spec *corev1.PodSpec) {
+	for _, alias := range base.HostAliases {
+		spec.HostAliases = append(spec.HostAliases, corev1.HostAlias{
+			IP:        alias.IP,
+			Hostnames: alias.Hostnames,
+		})
+	}
+	if base.DNSPolicy != "" {
+		spec.DNSPolicy = base.DNSPolicy
+	}
+	if base.DNSConfig != nil {
+		dnsConfig := &corev1.PodDNSConfig{
+			Nameservers: base.DNSConfig.Nameservers,
+			Searches:    base.DNSConfig.Searches,
+		}
+		_ = module.ForeachOrdered(base.DNSConfig.Options, func(name string, value string) error {
+			option := corev1.PodDNSConfigOption{Name: name}
+			if value != "" {
+				option.Value = &value
+			}
+			dnsConfig.Options = append(dnsConfig.Options, option)
+			return nil
+		})
+		spec.DNSConfig = dnsConfig
+	}
+}
+
+// handleRegistryCredentials returns the image pull secrets of the workload. The credentials of the
+// registries referenced by the workload are read from workspace, and a Secret of type
+// kubernetes.io/dockerconfigjson is generated with them.
+func handleRegistryCredentials(
+	base *Base,
+	config kusionapiv1.GenericConfig,
+	uniqueAppName string,
+) ([]corev1.LocalObjectReference, *corev1.Secret, error) {
+	if base.RegistryCredentials == nil {
+		return nil, nil, nil
+	}
+
+	var pullSecrets []corev1.LocalObjectReference
+	for _, name := range base.RegistryCredentials.ImagePullSecrets {
+		pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	if len(base.RegistryCredentials.Registries) == 0 {
+		return pullSecrets, nil, nil
+	}
+
+	credentials := make(map[string]RegistryCredential)
+	if value, ok := config[FieldRegistryCredentials]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = yaml.Unmarshal(out, &credentials); err != nil {
+			return nil, nil, fmt.Errorf("invalid registryCredentials config in workspace, %w", err)
+		}
+	}
+
+	auths := make(map[string]map[string]string)
+	for _, registry := range base.RegistryCredentials.Registries {
+		credential, ok := credentials[registry]
+		if !ok {
+			return nil, nil, fmt.Errorf("credential of registry %s is not found in workspace", registry)
+		}
+		auth := map[string]string{
+			"username": credential.Username,
+			"password": credential.Password,
+			"auth":     base64.StdEncoding.EncodeToString([]byte(credential.Username + ":" + credential.Password)),
+		}
+		if credential.Email != "" {
+			auth["email"] = credential.Email
+		}
+		auths[registry] = auth
+	}
+	dockerConfig, err := json.Marshal(map[string]any{"auths": auths})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	secret := &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Secret",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: uniqueAppName + "-registry-credentials",
+		},
+		Type: corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: dockerConfig,
+		},
+	}
+	pullSecrets = append(pullSecrets, corev1.LocalObjectReference{Name: secret.Name})
+	return pullSecrets, secret, nil
+}
+
+// secretTypes maps the types of the secrets declared in the workload to the Secret types.
+var secretTypes = map[string]corev1.SecretType{
+	SecretTypeBasic:       corev1.SecretTypeBasicAuth,
+	SecretTypeToken:       corev1.SecretTypeOpaque,
+	SecretTypeOpaque:      corev1.SecretTypeOpaque,
+	SecretTypeCertificate: corev1.SecretTypeTLS,
+	SecretTypeExternal:    corev1.SecretTypeOpaque,
+}
+
+// handleSecrets generates the Secrets declared in the workload, named after their keys. The data of
+// the external secrets are references in the format of ref://name/property?version=version, which
+// are resolved at generate time from the secret store configured in workspace, are mounted via the
+// Secrets Store CSI driver with the generated SecretProviderClasses in csi mode, or are synced by
+// the generated ExternalSecrets in externalsecret mode.
+func handleSecrets(
+	ctx context.Context,
+	base *Base,
+	config kusionapiv1.GenericConfig,
+) ([]corev1.Secret, []unstructured.Unstructured, error) {
+	var spec *SecretStore
+	var store secretStore
+	var secrets []corev1.Secret
+	var secretResources []unstructured.Unstructured
+	err := module.ForeachOrdered(base.Secrets, func(name string, secret Secret) error {
+		secretType, ok := secretTypes[secret.Type]
+		if !ok {
+			return fmt.Errorf("unsupported type %s of secret %s", secret.Type, name)
+		}
+
+		data := make(map[string][]byte, len(secret.Data))
+		if secret.Type != SecretTypeExternal {
+			for k, v := range secret.Data {
+				data[k] = []byte(v)
+			}
+		} else {
+			if spec == nil {
+				var err error
+				if spec, err = getSecretStore(config); err != nil {
+					return err
+				}
+			}
+			switch spec.Mode {
+			case SecretStoreModeCSI:
+				providerClass, err := secretProviderClass(spec, name, secret)
+				if err != nil {
+					return err
+				}
+				secretResources = append(secretResources, *providerClass)
+				return nil
+			case SecretStoreModeExternalSecret:
+				externalSecret, err := externalSecret(spec, name, secret)
+				if err != nil {
+					return err
+				}
+				secretResources = append(secretResources, *externalSecret)
+				return nil
+			}
+			if store == nil {
+				var err error
+				if store, err = newSecretStore(spec); err != nil {
+					return err
+				}
+			}
+			err := module.ForeachOrdered(secret.Data, func(k string, v string) error {
+				ref, err := parseExternalSecretRef(v)
+				if err != nil {
+					return fmt.Errorf("invalid data %s of secret %s, %w", k, name, err)
+				}
+				value, err := store.GetSecret(ctx, ref)
+				if err != nil {
+					return err
+				}
+				data[k] = value
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		generated := corev1.Secret{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Secret",
+				APIVersion: corev1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name,
+			},
+			Type: secretType,
+			Data: data,
+		}
+		if secret.Immutable {
+			generated.Immutable = &secret.Immutable
+		}
+		secrets = append(secrets, generated)
+		return nil
+	})
+	return secrets, secretResources, err
+}
+
+// getSecretStore returns the secretStore block in workspace.
+func getSecretStore(config kusionapiv1.GenericConfig) (*SecretStore, error) {
+	value, ok := config[FieldSecretStore]
+	if !ok || value == nil {
+		return nil, ErrEmptySecretStore
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	spec := &SecretStore{}
+	if err = yaml.Unmarshal(out, spec); err != nil {
+		return nil, fmt.Errorf("invalid secretStore config in workspace, %w", err)
+	}
+	switch spec.Mode {
+	case "", SecretStoreModeResolve, SecretStoreModeCSI, SecretStoreModeExternalSecret:
+	default:
+		return nil, fmt.Errorf("unsupported secretStore mode %s, must be resolve, csi or externalsecret", spec.Mode)
+	}
+	return spec, nil
+}
+
+// configChecksum calculates the sha256 checksum of the data of the given ConfigMaps and Secrets.
+func configChecksum(configMaps []corev1.ConfigMap, secrets []corev1.Secret) (string, error) {
+	hash := sha256.New()
+	for _, cm := range configMaps {
+		data, err := json.Marshal([]any{cm.Name, cm.Data, cm.BinaryData})
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+	for _, secret := range secrets {
+		data, err := json.Marshal([]any{secret.Name, secret.Data, secret.StringData})
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// handleVolumes converts the volumes declared in the workload into Volumes, and returns the
+// PersistentVolumeClaims to be created for the pvc volumes without a claim name.
+func handleVolumes(base *Base, uniqueAppName string) (
+	volumes []corev1.Volume,
+	pvcs []corev1.PersistentVolumeClaim,
+	err error,
+) {
+	// The volumes mounted by the containers must be declared in the workload.
+	if err = module.ForeachOrdered(base.Containers, func(containerName string, c Container) error {
+		for _, m := range c.VolumeMounts {
+			if _, ok := base.Volumes[m.Name]; !ok {
+				return fmt.Errorf("volume %s mounted by container %s is not declared", m.Name, containerName)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	err = module.ForeachOrdered(base.Volumes, func(name string, v Volume) error {
+		volume := corev1.Volume{Name: name}
+		sources := 0
+		if v.EmptyDir != nil {
+			sources++
+			emptyDir := &corev1.EmptyDirVolumeSource{Medium: v.EmptyDir.Medium}
+			if v.EmptyDir.SizeLimit != "" {
+				sizeLimit, err := resource.ParseQuantity(v.EmptyDir.SizeLimit)
+				if err != nil {
+					return fmt.Errorf("invalid sizeLimit of volume %s, %w", name, err)
+				}
+				emptyDir.SizeLimit = &sizeLimit
+			}
+			volume.EmptyDir = emptyDir
+		}
+		if v.PVC != nil {
+			sources++
+			claimName := v.PVC.ClaimName
+			if claimName == "" {
+				claimName = uniqueAppName + "-" + name
+				pvc, err := persistentVolumeClaim(claimName, v.PVC)
+				if err != nil {
+					return fmt.Errorf("invalid pvc of volume %s, %w", name, err)
+				}
+				pvcs = append(pvcs, *pvc)
+			}
+			volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: claimName,
+				ReadOnly:  v.PVC.ReadOnly,
+			}
+		}
+		if v.ConfigMap != nil {
+			sources++
+			defaultMode, err := parseMode(v.ConfigMap.DefaultMode)
+			if err != nil {
+				return err
+			}
+			volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: v.ConfigMap.Name},
+				Items:                toKeyToPaths(v.ConfigMap.Items),
+				DefaultMode:          defaultMode,
+				Optional:             v.ConfigMap.Optional,
+			}
+		}
+		if v.Secret != nil {
+			sources++
+			defaultMode, err := parseMode(v.Secret.DefaultMode)
+			if err != nil {
+				return err
+			}
+			volume.Secret = &corev1.SecretVolumeSource{
+				SecretName:  v.Secret.Name,
+				Items:       toKeyToPaths(v.Secret.Items),
+				DefaultMode: defaultMode,
+				Optional:    v.Secret.Optional,
+			}
+		}
+		if v.Projected != nil {
+			sources++
+			defaultMode, err := parseMode(v.Projected.DefaultMode)
+			if err != nil {
+				return err
+			}
+			volume.Projected = &corev1.ProjectedVolumeSource{
+				Sources:     toVolumeProjections(v.Projected.Sources),
+				DefaultMode: defaultMode,
+			}
+		}
+		if sources != 1 {
+			return fmt.Errorf("invalid volume %s, %w", name, ErrInvalidVolumeSource)
+		}
+
+		volumes = append(volumes, volume)
+		return nil
+	})
+	return
+}
+
+func persistentVolumeClaim(name string, in *PVCVolumeSource) (*corev1.PersistentVolumeClaim, error) {
+	if in.Size == "" {
+		return nil, ErrEmptyPVCSize
+	}
+	size, err := resource.ParseQuantity(in.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	accessModes := in.AccessModes
+	if len(accessModes) == 0 {
+		accessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PersistentVolumeClaim",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessModes,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+	if in.StorageClass != "" {
+		pvc.Spec.StorageClassName = &in.StorageClass
+	}
+	return pvc, nil
+}
+
+func toVolumeProjections(sources []VolumeProjection) []corev1.VolumeProjection {
+	result := make([]corev1.VolumeProjection, 0, len(sources))
+	for _, s := range sources {
+		projection := corev1.VolumeProjection{}
+		if s.ConfigMap != nil {
+			projection.ConfigMap = &corev1.ConfigMapProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: s.ConfigMap.Name},
+				Items:                toKeyToPaths(s.ConfigMap.Items),
+			}
+		}
+		if s.Secret != nil {
+			projection.Secret = &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: s.Secret.Name},
+				Items:                toKeyToPaths(s.Secret.Items),
+			}
+		}
+		if s.ServiceAccountToken != nil {
+			projection.ServiceAccountToken = &corev1.ServiceAccountTokenProjection{
+				Audience:          s.ServiceAccountToken.Audience,
+				ExpirationSeconds: s.ServiceAccountToken.ExpirationSeconds,
+				Path:              s.ServiceAccountToken.Path,
+			}
+		}
+		result = append(result, projection)
+	}
+	return result
+}
+
+func toKeyToPaths(items map[string]string) []corev1.KeyToPath {
+	if len(items) == 0 {
+		return nil
+	}
+
+	result := make([]corev1.KeyToPath, 0, len(items))
+	_ = module.ForeachOrdered(items, func(key string, path string) error {
+		result = append(result, corev1.KeyToPath{Key: key, Path: path})
+		return nil
+	})
+	return result
+}
+
+// parseMode parses the mode bits string, e.g. "0644", and returns nil if it is empty.
+func parseMode(mode string) (*int32, error) {
+	if mode == "" {
+		return nil, nil
+	}
+	modeInt64, err := strconv.ParseInt(mode, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mode %s, %w", mode, err)
+	}
+	modeInt32 := int32(modeInt64)
+	return &modeInt32, nil
+}
+
 // completeBaseWorkload uses config from workspace to complete the Workload base config.
 func completeBaseWorkload(base *Base, config kusionapiv1.GenericConfig) error {
 	replicas, err := workspace.GetInt32PointerFromGenericConfig(config, FieldReplicas)
@@ -419,7 +1092,37 @@ func completeBaseWorkload(base *Base, config kusionapiv1.GenericConfig) error {
 			return err
 		}
 	}
-	return nil
+	gracePeriod, err := workspace.GetInt32PointerFromGenericConfig(config, FieldTerminationGracePeriodSeconds)
+	if err != nil {
+		return err
+	}
+	// use the grace period from workspace as default if it is not set in the workload
+	if base.TerminationGracePeriodSeconds == nil && gracePeriod != nil {
+		seconds := int64(*gracePeriod)
+		base.TerminationGracePeriodSeconds = &seconds
+	}
+	if err = completeScheduling(base, config); err != nil {
+		return err
+	}
+	if err = completeWindowsScheduling(base, config); err != nil {
+		return err
+	}
+	if err = completeGPU(base, config); err != nil {
+		return err
+	}
+	if err = completeRuntimeClass(base, config); err != nil {
+		return err
+	}
+	if err = completeIdentity(base, config); err != nil {
+		return err
+	}
+	if err = completeServiceAccount(base, config); err != nil {
+		return err
+	}
+	if err = completeConfigReload(base, config); err != nil {
+		return err
+	}
+	return enforceSecurityBaseline(base, config)
 }
 
 type secretReference struct {
@@ -456,32 +1159,467 @@ func handleTopologySpreadConstraints(tps map[string]TopologySpreadConstraint) []
 		return nil
 	}
 
-	for _, v := range tps {
-		tp := corev1.TopologySpreadConstraint{
+	_ = module.ForeachOrdered(tps, func(_ string, v TopologySpreadConstraint) error {
+		topologySpreadConstraints = append(topologySpreadConstraints, corev1.TopologySpreadConstraint{
 			MaxSkew:            v.MaxSkew,
 			TopologyKey:        v.TopologyKey,
 			WhenUnsatisfiable:  v.WhenUnsatisfiable,
+			LabelSelector:      toV1LabelSelector(v.LabelSelector),
 			MinDomains:         v.MinDomains,
 			NodeAffinityPolicy: v.NodeAffinityPolicy,
 			NodeTaintsPolicy:   v.NodeTaintsPolicy,
 			MatchLabelKeys:     v.MatchLabelKeys,
-		}
+		})
+		return nil
+	})
+	return topologySpreadConstraints
+}
+
+func toV1LabelSelector(in *LabelSelector) *metav1.LabelSelector {
+	if in == nil {
+		return nil
+	}
 
-		if v.LabelSelector != nil {
-			var matchExpressions []metav1.LabelSelectorRequirement
-			for _, m := range v.LabelSelector.MatchExpressions {
-				matchExpressions = append(matchExpressions, metav1.LabelSelectorRequirement{
-					Key:      m.Key,
-					Operator: m.Operator,
-					Values:   m.Values,
+	var matchExpressions []metav1.LabelSelectorRequirement
+	for _, m := range in.MatchExpressions {
+		matchExpressions = append(matchExpressions, metav1.LabelSelectorRequirement{
+			Key:      m.Key,
+			Operator: m.Operator,
+			Values:   m.Values,
+		})
+	}
+	return &metav1.LabelSelector{
+		MatchLabels:      in.MatchLabels,
+		MatchExpressions: matchExpressions,
+	}
+}
+
+// handleScheduling sets the scheduling constraints of the workload, including the
+// legacy top-level topologySpreadConstraints, into the pod spec.
+func handleScheduling(base *Base, spec *corev1.PodSpec) {
+	tps := base.TopologySpreadConstraints
+	if base.Scheduling != nil && len(base.Scheduling.TopologySpreadConstraints) != 0 {
+		tps = make(map[string]TopologySpreadConstraint)
+		maps.Copy(tps, base.Scheduling.TopologySpreadConstraints)
+		// the top-level constraints take precedence over the ones in scheduling block
+		maps.Copy(tps, base.TopologySpreadConstraints)
+	}
+	spec.TopologySpreadConstraints = handleTopologySpreadConstraints(tps)
+
+	if base.Scheduling == nil {
+		return
+	}
+	spec.NodeSelector = base.Scheduling.NodeSelector
+	spec.Tolerations = handleTolerations(base.Scheduling.Tolerations)
+	spec.Affinity = handleAffinity(base.Scheduling.Affinity)
+}
+
+func handleTolerations(tolerations []Toleration) []corev1.Toleration {
+	if len(tolerations) == 0 {
+		return nil
+	}
+
+	result := make([]corev1.Toleration, 0, len(tolerations))
+	for _, t := range tolerations {
+		result = append(result, corev1.Toleration{
+			Key:               t.Key,
+			Operator:          t.Operator,
+			Value:             t.Value,
+			Effect:            t.Effect,
+			TolerationSeconds: t.TolerationSeconds,
+		})
+	}
+	return result
+}
+
+func handleAffinity(affinity *Affinity) *corev1.Affinity {
+	if affinity == nil {
+		return nil
+	}
+
+	result := &corev1.Affinity{}
+	if na := affinity.NodeAffinity; na != nil {
+		result.NodeAffinity = &corev1.NodeAffinity{}
+		if len(na.Required) != 0 {
+			result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+			for _, term := range na.Required {
+				result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = append(
+					result.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, toV1NodeSelectorTerm(term))
+			}
+		}
+		for _, term := range na.Preferred {
+			result.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+				result.NodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution, corev1.PreferredSchedulingTerm{
+					Weight:     term.Weight,
+					Preference: toV1NodeSelectorTerm(term.Preference),
 				})
+		}
+	}
+	if pa := affinity.PodAffinity; pa != nil {
+		required, preferred := toV1PodAffinityTerms(pa)
+		result.PodAffinity = &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution:  required,
+			PreferredDuringSchedulingIgnoredDuringExecution: preferred,
+		}
+	}
+	if paa := affinity.PodAntiAffinity; paa != nil {
+		required, preferred := toV1PodAffinityTerms(paa)
+		result.PodAntiAffinity = &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution:  required,
+			PreferredDuringSchedulingIgnoredDuringExecution: preferred,
+		}
+	}
+	return result
+}
+
+func toV1NodeSelectorTerm(term NodeSelectorTerm) corev1.NodeSelectorTerm {
+	var result corev1.NodeSelectorTerm
+	for _, m := range term.MatchExpressions {
+		result.MatchExpressions = append(result.MatchExpressions, corev1.NodeSelectorRequirement{
+			Key:      m.Key,
+			Operator: m.Operator,
+			Values:   m.Values,
+		})
+	}
+	return result
+}
+
+func toV1PodAffinityTerms(pa *PodAffinity) ([]corev1.PodAffinityTerm, []corev1.WeightedPodAffinityTerm) {
+	var required []corev1.PodAffinityTerm
+	for _, term := range pa.Required {
+		required = append(required, toV1PodAffinityTerm(term))
+	}
+	var preferred []corev1.WeightedPodAffinityTerm
+	for _, term := range pa.Preferred {
+		preferred = append(preferred, corev1.WeightedPodAffinityTerm{
+			Weight:          term.Weight,
+			PodAffinityTerm: toV1PodAffinityTerm(term.PodAffinityTerm),
+		})
+	}
+	return required, preferred
+}
+
+func toV1PodAffinityTerm(term PodAffinityTerm) corev1.PodAffinityTerm {
+	return corev1.PodAffinityTerm{
+		LabelSelector: toV1LabelSelector(term.LabelSelector),
+		Namespaces:    term.Namespaces,
+		TopologyKey:   term.TopologyKey,
+	}
+}
+
+// completeScheduling uses the scheduling block from workspace as the default of the workload's
+// scheduling constraints. The constraints declared in the workload take precedence.
+func completeScheduling(base *Base, config kusionapiv1.GenericConfig) error {
+	value, ok := config[FieldScheduling]
+	if !ok || value == nil {
+		return nil
+	}
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	platform := &Scheduling{}
+	if err = yaml.Unmarshal(out, platform); err != nil {
+		return fmt.Errorf("invalid scheduling config in workspace, %w", err)
+	}
+
+	if base.Scheduling == nil {
+		base.Scheduling = platform
+		return nil
+	}
+	if err = mergo.Merge(&base.Scheduling.NodeSelector, platform.NodeSelector); err != nil {
+		return err
+	}
+	if len(base.Scheduling.Tolerations) == 0 {
+		base.Scheduling.Tolerations = platform.Tolerations
+	}
+	if base.Scheduling.Affinity == nil {
+		base.Scheduling.Affinity = platform.Affinity
+	}
+	return mergo.Merge(&base.Scheduling.TopologySpreadConstraints, platform.TopologySpreadConstraints)
+}
+
+// defaultWindowsScheduling is used to assign the Windows pods to the nodes if the Windows node pools
+// are not configured in workspace, which tolerates the common taint of the Windows nodes.
+var defaultWindowsScheduling = Scheduling{
+	Tolerations: []Toleration{
+		{
+			Key:      "os",
+			Operator: corev1.TolerationOpEqual,
+			Value:    OSWindows,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	},
+}
+
+// completeWindowsScheduling assigns the Windows pods to the Windows node pools configured in
+// workspace. The node selectors of the pools override the ones declared in the workload, so that
+// the pods never land on the Linux nodes, and the tolerations of the pools are appended.
+func completeWindowsScheduling(base *Base, config kusionapiv1.GenericConfig) error {
+	if base.OS != OSWindows {
+		return nil
+	}
+	platform := defaultWindowsScheduling
+	if value, ok := config[FieldWindows]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		platform = Scheduling{}
+		if err = yaml.Unmarshal(out, &platform); err != nil {
+			return fmt.Errorf("invalid windows config in workspace, %w", err)
+		}
+	}
+
+	if base.Scheduling == nil {
+		base.Scheduling = &Scheduling{}
+	}
+	if base.Scheduling.NodeSelector == nil {
+		base.Scheduling.NodeSelector = make(map[string]string)
+	}
+	maps.Copy(base.Scheduling.NodeSelector, platform.NodeSelector)
+	base.Scheduling.NodeSelector[corev1.LabelOSStable] = OSWindows
+	base.Scheduling.Tolerations = appendTolerations(base.Scheduling.Tolerations, platform.Tolerations)
+	if base.Scheduling.Affinity == nil {
+		base.Scheduling.Affinity = platform.Affinity
+	}
+	return nil
+}
+
+// appendTolerations appends the tolerations of the node pools absent from the existing ones.
+func appendTolerations(existing, tolerations []Toleration) []Toleration {
+	for _, t := range tolerations {
+		if !slices.ContainsFunc(existing, func(e Toleration) bool {
+			return e.Key == t.Key && e.Operator == t.Operator && e.Value == t.Value && e.Effect == t.Effect
+		}) {
+			existing = append(existing, t)
+		}
+	}
+	return existing
+}
+
+// completeRuntimeClass completes the runtime class of the workload with the one from workspace.
+// The sandboxed runtime class is enforced on the untrusted workloads, and the other workloads use
+// the default one if not declared, which must be one of the allowed runtime classes.
+func completeRuntimeClass(base *Base, config kusionapiv1.GenericConfig) error {
+	platform := &RuntimeClass{}
+	if value, ok := config[FieldRuntimeClass]; ok && value != nil {
+		out, err := yaml.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if err = yaml.Unmarshal(out, platform); err != nil {
+			return fmt.Errorf("invalid runtimeClass config in workspace, %w", err)
+		}
+	}
+
+	if base.Untrusted {
+		if platform.Untrusted != "" {
+			base.RuntimeClassName = platform.Untrusted
+			return nil
+		}
+		if base.RuntimeClassName == "" {
+			return ErrEmptySandboxRuntime
+		}
+	}
+	if base.RuntimeClassName == "" {
+		base.RuntimeClassName = platform.Default
+	}
+	if base.RuntimeClassName != "" && len(platform.Allowed) != 0 &&
+		!slices.Contains(platform.Allowed, base.RuntimeClassName) {
+		return fmt.Errorf("%w, got %s, allowed %v", ErrUnsupportedRuntimeClass, base.RuntimeClassName, platform.Allowed)
+	}
+	return nil
+}
+
+// validateOS validates the operating system of the workload, where the Linux-only options of the
+// security contexts are rejected for the Windows pods.
+func validateOS(base *Base) error {
+	switch base.OS {
+	case "", OSLinux:
+		return nil
+	case OSWindows:
+	default:
+		return fmt.Errorf("%w, got %q", ErrUnsupportedOS, base.OS)
+	}
+
+	var options []string
+	if sc := base.SecurityContext; sc != nil {
+		options = append(options, linuxOnlyOptions("securityContext", []linuxOnlyOption{
+			{"runAsUser", sc.RunAsUser != nil},
+			{"runAsGroup", sc.RunAsGroup != nil},
+			{"fsGroup", sc.FSGroup != nil},
+			{"seccompProfile", sc.SeccompProfile != nil},
+		})...)
+	}
+	names := make([]string, 0, len(base.Containers))
+	for name := range base.Containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sc := base.Containers[name].SecurityContext
+		if sc == nil {
+			continue
+		}
+		options = append(options, linuxOnlyOptions("containers."+name+".securityContext", []linuxOnlyOption{
+			{"runAsUser", sc.RunAsUser != nil},
+			{"runAsGroup", sc.RunAsGroup != nil},
+			{"privileged", sc.Privileged != nil},
+			{"allowPrivilegeEscalation", sc.AllowPrivilegeEscalation != nil},
+			{"readOnlyRootFilesystem", sc.ReadOnlyRootFilesystem != nil},
+			{"capabilities", sc.Capabilities != nil},
+			{"seccompProfile", sc.SeccompProfile != nil},
+		})...)
+	}
+	if len(options) != 0 {
+		return fmt.Errorf("%w: %s", ErrLinuxOnlyOption, strings.Join(options, ", "))
+	}
+	return nil
+}
+
+// linuxOnlyOption is a Linux-only field of the security contexts and whether it is set.
+type linuxOnlyOption struct {
+	field string
+	set   bool
+}
+
+// linuxOnlyOptions returns the paths of the Linux-only options set under the prefix.
+func linuxOnlyOptions(prefix string, fields []linuxOnlyOption) []string {
+	var options []string
+	for _, f := range fields {
+		if f.set {
+			options = append(options, prefix+"."+f.field)
+		}
+	}
+	return options
+}
+
+// enforceSecurityBaseline applies the security baseline from workspace to the workload. The fields
+// set in the baseline override the ones declared in the workload, and the dropped capabilities are
+// unioned, so that the platform is able to enforce a hardened baseline across all applications.
+// Only runAsNonRoot is enforced on the Windows pods, which reject the other Linux-only fields.
+func enforceSecurityBaseline(base *Base, config kusionapiv1.GenericConfig) error {
+	value, ok := config[FieldSecurityContext]
+	if !ok || value == nil {
+		return nil
+	}
+	windows := base.OS == OSWindows
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	baseline := &SecurityBaseline{}
+	if err = yaml.Unmarshal(out, baseline); err != nil {
+		return fmt.Errorf("invalid securityContext config in workspace, %w", err)
+	}
+
+	if baseline.Pod != nil {
+		if base.SecurityContext == nil {
+			base.SecurityContext = &PodSecurityContext{}
+		}
+		sc, enforced := base.SecurityContext, baseline.Pod
+		overridePointer(&sc.RunAsNonRoot, enforced.RunAsNonRoot)
+		if !windows {
+			overridePointer(&sc.RunAsUser, enforced.RunAsUser)
+			overridePointer(&sc.RunAsGroup, enforced.RunAsGroup)
+			overridePointer(&sc.FSGroup, enforced.FSGroup)
+			overridePointer(&sc.SeccompProfile, enforced.SeccompProfile)
+		}
+	}
+
+	if baseline.Container != nil {
+		for name, c := range base.Containers {
+			if c.SecurityContext == nil {
+				c.SecurityContext = &SecurityContext{}
 			}
-			tp.LabelSelector = &metav1.LabelSelector{
-				MatchLabels:      v.LabelSelector.MatchLabels,
-				MatchExpressions: matchExpressions,
+			sc, enforced := c.SecurityContext, baseline.Container
+			overridePointer(&sc.RunAsNonRoot, enforced.RunAsNonRoot)
+			if windows {
+				base.Containers[name] = c
+				continue
 			}
+			overridePointer(&sc.RunAsUser, enforced.RunAsUser)
+			overridePointer(&sc.RunAsGroup, enforced.RunAsGroup)
+			overridePointer(&sc.Privileged, enforced.Privileged)
+			overridePointer(&sc.AllowPrivilegeEscalation, enforced.AllowPrivilegeEscalation)
+			overridePointer(&sc.ReadOnlyRootFilesystem, enforced.ReadOnlyRootFilesystem)
+			overridePointer(&sc.SeccompProfile, enforced.SeccompProfile)
+			if enforced.Capabilities != nil {
+				if sc.Capabilities == nil {
+					sc.Capabilities = &Capabilities{}
+				}
+				sc.Capabilities.Drop = unionStrings(sc.Capabilities.Drop, enforced.Capabilities.Drop)
+			}
+			base.Containers[name] = c
 		}
-		topologySpreadConstraints = append(topologySpreadConstraints, tp)
 	}
-	return topologySpreadConstraints
+	return nil
+}
+
+func overridePointer[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
+}
+
+func unionStrings(a, b []string) []string {
+	result := append([]string{}, a...)
+	for _, s := range b {
+		if !slices.Contains(result, s) {
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+func toV1PodSecurityContext(in *PodSecurityContext) *corev1.PodSecurityContext {
+	if in == nil {
+		return nil
+	}
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot:   in.RunAsNonRoot,
+		RunAsUser:      in.RunAsUser,
+		RunAsGroup:     in.RunAsGroup,
+		FSGroup:        in.FSGroup,
+		SeccompProfile: toV1SeccompProfile(in.SeccompProfile),
+	}
+}
+
+func toV1SecurityContext(in *SecurityContext) *corev1.SecurityContext {
+	if in == nil {
+		return nil
+	}
+	result := &corev1.SecurityContext{
+		RunAsNonRoot:             in.RunAsNonRoot,
+		RunAsUser:                in.RunAsUser,
+		RunAsGroup:               in.RunAsGroup,
+		Privileged:               in.Privileged,
+		AllowPrivilegeEscalation: in.AllowPrivilegeEscalation,
+		ReadOnlyRootFilesystem:   in.ReadOnlyRootFilesystem,
+		SeccompProfile:           toV1SeccompProfile(in.SeccompProfile),
+	}
+	if in.Capabilities != nil {
+		result.Capabilities = &corev1.Capabilities{}
+		for _, c := range in.Capabilities.Add {
+			result.Capabilities.Add = append(result.Capabilities.Add, corev1.Capability(c))
+		}
+		for _, c := range in.Capabilities.Drop {
+			result.Capabilities.Drop = append(result.Capabilities.Drop, corev1.Capability(c))
+		}
+	}
+	return result
+}
+
+func toV1SeccompProfile(in *SeccompProfile) *corev1.SeccompProfile {
+	if in == nil {
+		return nil
+	}
+	return &corev1.SeccompProfile{
+		Type:             in.Type,
+		LocalhostProfile: in.LocalhostProfile,
+	}
 }
@@ -0,0 +1,1135 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestConvertKusionProbeToV1Probe(t *testing.T) {
+	gracePeriod := int64(30)
+	service := "health"
+
+	tests := []struct {
+		name    string
+		probe   *Probe
+		want    *corev1.Probe
+		wantErr string
+	}{
+		{
+			name: "http probe with thresholds",
+			probe: &Probe{
+				ProbeHandler: &ProbeHandler{
+					TypeWrapper: TypeWrapper{Type: TypeHTTP},
+					HTTPGetAction: &HTTPGetAction{
+						URL: "http://localhost:8080/healthz",
+					},
+				},
+				InitialDelaySeconds: 5,
+				TimeoutSeconds:      2,
+				PeriodSeconds:       10,
+				SuccessThreshold:    1,
+				FailureThreshold:    3,
+			},
+			want: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					HTTPGet: &corev1.HTTPGetAction{
+						Path:        "/healthz",
+						Port:        intstr.FromInt32(8080),
+						Scheme:      corev1.URISchemeHTTP,
+						HTTPHeaders: []corev1.HTTPHeader{},
+					},
+				},
+				InitialDelaySeconds: 5,
+				TimeoutSeconds:      2,
+				PeriodSeconds:       10,
+				SuccessThreshold:    1,
+				FailureThreshold:    3,
+			},
+		},
+		{
+			name: "exec probe",
+			probe: &Probe{
+				ProbeHandler: &ProbeHandler{
+					TypeWrapper: TypeWrapper{Type: TypeExec},
+					ExecAction: &ExecAction{
+						Command: []string{"cat", "/tmp/healthy"},
+					},
+				},
+			},
+			want: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					Exec: &corev1.ExecAction{Command: []string{"cat", "/tmp/healthy"}},
+				},
+			},
+		},
+		{
+			name: "tcp probe",
+			probe: &Probe{
+				ProbeHandler: &ProbeHandler{
+					TypeWrapper: TypeWrapper{Type: TypeTCP},
+					TCPSocketAction: &TCPSocketAction{
+						URL: "127.0.0.1:8080",
+					},
+				},
+			},
+			want: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					TCPSocket: &corev1.TCPSocketAction{
+						Port: intstr.FromInt32(8080),
+						Host: "127.0.0.1",
+					},
+				},
+			},
+		},
+		{
+			name: "grpc probe with termination grace period",
+			probe: &Probe{
+				ProbeHandler: &ProbeHandler{
+					TypeWrapper: TypeWrapper{Type: TypeGRPC},
+					GRPCAction: &GRPCAction{
+						Port:    9090,
+						Service: service,
+					},
+				},
+				FailureThreshold:       3,
+				TerminationGracePeriod: &gracePeriod,
+			},
+			want: &corev1.Probe{
+				ProbeHandler: corev1.ProbeHandler{
+					GRPC: &corev1.GRPCAction{
+						Port:    9090,
+						Service: &service,
+					},
+				},
+				FailureThreshold:              3,
+				TerminationGracePeriodSeconds: &gracePeriod,
+			},
+		},
+		{
+			name:    "missing probe handler",
+			probe:   &Probe{},
+			wantErr: "probe handler must be specified",
+		},
+		{
+			name: "unsupported probe handler type",
+			probe: &Probe{
+				ProbeHandler: &ProbeHandler{
+					TypeWrapper: TypeWrapper{Type: "service.container.probe.Unknown"},
+				},
+			},
+			wantErr: "unsupported probe handler type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertKusionProbeToV1Probe(tt.probe)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandleResourceRequirementsV1(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources map[string]string
+		want      corev1.ResourceRequirements
+		wantErr   error
+	}{
+		{
+			name: "ephemeral storage, hugepages and extended resources",
+			resources: map[string]string{
+				"cpu":               "500m-1",
+				"memory":            "1Gi",
+				"ephemeral-storage": "1Gi-10Gi",
+				"hugepages-2Mi":     "100Mi-100Mi",
+				"nvidia.com/gpu":    "2",
+			},
+			want: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:              resource.MustParse("500m"),
+					corev1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+					"hugepages-2Mi":                 resource.MustParse("100Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceCPU:              resource.MustParse("1"),
+					corev1.ResourceMemory:           resource.MustParse("1Gi"),
+					corev1.ResourceEphemeralStorage: resource.MustParse("10Gi"),
+					"hugepages-2Mi":                 resource.MustParse("100Mi"),
+					"nvidia.com/gpu":                resource.MustParse("2"),
+				},
+			},
+		},
+		{
+			name:      "unsupported resource",
+			resources: map[string]string{"gpu": "1"},
+			wantErr:   ErrUnsupportedResource,
+		},
+		{
+			name:      "invalid hugepages size",
+			resources: map[string]string{"memory": "1Gi", "hugepages-large": "1Gi"},
+			wantErr:   ErrUnsupportedResource,
+		},
+		{
+			name:      "kubernetes.io resource",
+			resources: map[string]string{"kubernetes.io/gpu": "1"},
+			wantErr:   ErrUnsupportedResource,
+		},
+		{
+			name:      "overcommitted extended resource",
+			resources: map[string]string{"amd.com/gpu": "1-2"},
+			wantErr:   ErrOvercommittedResource,
+		},
+		{
+			name:      "fractional extended resource",
+			resources: map[string]string{"nvidia.com/gpu": "500m"},
+			wantErr:   ErrFractionalResource,
+		},
+		{
+			name:      "hugepages only",
+			resources: map[string]string{"hugepages-1Gi": "2Gi"},
+			wantErr:   ErrHugePagesOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := handleResourceRequirementsV1(tt.resources)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHandleScheduling(t *testing.T) {
+	tolerationSeconds := int64(300)
+
+	base := &Base{
+		TopologySpreadConstraints: map[string]TopologySpreadConstraint{
+			"zone": {
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.DoNotSchedule,
+			},
+		},
+		Scheduling: &Scheduling{
+			NodeSelector: map[string]string{"disktype": "ssd"},
+			Tolerations: []Toleration{
+				{
+					Key:               "dedicated",
+					Operator:          corev1.TolerationOpEqual,
+					Value:             "web",
+					Effect:            corev1.TaintEffectNoExecute,
+					TolerationSeconds: &tolerationSeconds,
+				},
+			},
+			Affinity: &Affinity{
+				NodeAffinity: &NodeAffinity{
+					Required: []NodeSelectorTerm{
+						{
+							MatchExpressions: []NodeSelectorRequirement{
+								{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}},
+							},
+						},
+					},
+				},
+				PodAntiAffinity: &PodAffinity{
+					Preferred: []WeightedPodAffinityTerm{
+						{
+							Weight: 100,
+							PodAffinityTerm: PodAffinityTerm{
+								LabelSelector: &LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "foo"}},
+								TopologyKey:   "kubernetes.io/hostname",
+							},
+						},
+					},
+				},
+			},
+			TopologySpreadConstraints: map[string]TopologySpreadConstraint{
+				"host": {
+					MaxSkew:           2,
+					TopologyKey:       "kubernetes.io/hostname",
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+				},
+				"zone": {
+					MaxSkew:           3,
+					TopologyKey:       "topology.kubernetes.io/zone",
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+				},
+			},
+		},
+	}
+
+	spec := &corev1.PodSpec{}
+	handleScheduling(base, spec)
+
+	assert.Equal(t, map[string]string{"disktype": "ssd"}, spec.NodeSelector)
+	assert.Equal(t, []corev1.Toleration{
+		{
+			Key:               "dedicated",
+			Operator:          corev1.TolerationOpEqual,
+			Value:             "web",
+			Effect:            corev1.TaintEffectNoExecute,
+			TolerationSeconds: &tolerationSeconds,
+		},
+	}, spec.Tolerations)
+	assert.Equal(t, &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{Key: "kubernetes.io/arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"amd64"}},
+						},
+					},
+				},
+			},
+		},
+		PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+				{
+					Weight: 100,
+					PodAffinityTerm: corev1.PodAffinityTerm{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "foo"}},
+						TopologyKey:   "kubernetes.io/hostname",
+					},
+				},
+			},
+		},
+	}, spec.Affinity)
+	// the top-level topology spread constraints take precedence.
+	assert.Equal(t, []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           2,
+			TopologyKey:       "kubernetes.io/hostname",
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+		},
+		{
+			MaxSkew:           1,
+			TopologyKey:       "topology.kubernetes.io/zone",
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+		},
+	}, spec.TopologySpreadConstraints)
+}
+
+func TestCompleteScheduling(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"scheduling": map[string]any{
+			"nodeSelector": map[string]any{
+				"disktype": "hdd",
+				"pool":     "default",
+			},
+			"tolerations": []any{
+				map[string]any{
+					"key":      "dedicated",
+					"operator": "Exists",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		base   *Base
+		config kusionapiv1.GenericConfig
+		want   *Scheduling
+	}{
+		{
+			name:   "no scheduling in workspace",
+			base:   &Base{},
+			config: kusionapiv1.GenericConfig{},
+			want:   nil,
+		},
+		{
+			name:   "use scheduling in workspace",
+			base:   &Base{},
+			config: platformConfig,
+			want: &Scheduling{
+				NodeSelector: map[string]string{"disktype": "hdd", "pool": "default"},
+				Tolerations:  []Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+			},
+		},
+		{
+			name: "scheduling in workload takes precedence",
+			base: &Base{
+				Scheduling: &Scheduling{
+					NodeSelector: map[string]string{"disktype": "ssd"},
+					Tolerations:  []Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+				},
+			},
+			config: platformConfig,
+			want: &Scheduling{
+				NodeSelector: map[string]string{"disktype": "ssd", "pool": "default"},
+				Tolerations:  []Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeScheduling(tt.base, tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.Scheduling)
+		})
+	}
+}
+
+func TestCompleteWindowsScheduling(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   *Base
+		config kusionapiv1.GenericConfig
+		want   *Scheduling
+	}{
+		{
+			name:   "linux workload",
+			base:   &Base{},
+			config: kusionapiv1.GenericConfig{},
+			want:   nil,
+		},
+		{
+			name:   "default windows scheduling",
+			base:   &Base{OS: OSWindows},
+			config: kusionapiv1.GenericConfig{},
+			want: &Scheduling{
+				NodeSelector: map[string]string{corev1.LabelOSStable: OSWindows},
+				Tolerations:  defaultWindowsScheduling.Tolerations,
+			},
+		},
+		{
+			name: "windows node pools in workspace",
+			base: &Base{
+				OS: OSWindows,
+				Scheduling: &Scheduling{
+					NodeSelector: map[string]string{corev1.LabelOSStable: OSLinux, "disktype": "ssd"},
+					Tolerations:  []Toleration{{Key: "gpu", Operator: corev1.TolerationOpExists}},
+				},
+			},
+			config: kusionapiv1.GenericConfig{
+				"windows": map[string]any{
+					"nodeSelector": map[string]any{"pool": "windows"},
+					"tolerations": []any{
+						map[string]any{
+							"key":      "pool",
+							"operator": "Equal",
+							"value":    "windows",
+							"effect":   "NoSchedule",
+						},
+					},
+				},
+			},
+			want: &Scheduling{
+				NodeSelector: map[string]string{corev1.LabelOSStable: OSWindows, "disktype": "ssd", "pool": "windows"},
+				Tolerations: []Toleration{
+					{Key: "gpu", Operator: corev1.TolerationOpExists},
+					{Key: "pool", Operator: corev1.TolerationOpEqual, Value: "windows", Effect: corev1.TaintEffectNoSchedule},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeWindowsScheduling(tt.base, tt.config)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.Scheduling)
+		})
+	}
+}
+
+func TestCompleteRuntimeClass(t *testing.T) {
+	platformConfig := kusionapiv1.GenericConfig{
+		"runtimeClass": map[string]any{
+			"default":   "runc",
+			"untrusted": "gvisor",
+			"allowed":   []any{"runc", "gvisor", "kata"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		base    *Base
+		config  kusionapiv1.GenericConfig
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "no runtime class",
+			base:   &Base{},
+			config: kusionapiv1.GenericConfig{},
+		},
+		{
+			name:   "default runtime class",
+			base:   &Base{},
+			config: platformConfig,
+			want:   "runc",
+		},
+		{
+			name:   "declared runtime class",
+			base:   &Base{RuntimeClassName: "kata"},
+			config: platformConfig,
+			want:   "kata",
+		},
+		{
+			name:    "runtime class not allowed",
+			base:    &Base{RuntimeClassName: "youki"},
+			config:  platformConfig,
+			wantErr: ErrUnsupportedRuntimeClass,
+		},
+		{
+			name:   "sandboxed runtime class enforced on untrusted workload",
+			base:   &Base{RuntimeClassName: "runc", Untrusted: true},
+			config: platformConfig,
+			want:   "gvisor",
+		},
+		{
+			name:   "untrusted workload with declared runtime class",
+			base:   &Base{RuntimeClassName: "kata", Untrusted: true},
+			config: kusionapiv1.GenericConfig{},
+			want:   "kata",
+		},
+		{
+			name:    "untrusted workload without runtime class",
+			base:    &Base{Untrusted: true},
+			config:  kusionapiv1.GenericConfig{},
+			wantErr: ErrEmptySandboxRuntime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := completeRuntimeClass(tt.base, tt.config)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, tt.base.RuntimeClassName)
+		})
+	}
+}
+
+func TestValidateOS(t *testing.T) {
+	runAsUser, privileged, runAsNonRoot := int64(1000), true, true
+
+	tests := []struct {
+		name    string
+		base    *Base
+		wantErr error
+		errMsg  string
+	}{
+		{
+			name: "linux workload with linux-only options",
+			base: &Base{SecurityContext: &PodSecurityContext{RunAsUser: &runAsUser}},
+		},
+		{
+			name:    "unsupported os",
+			base:    &Base{OS: "darwin"},
+			wantErr: ErrUnsupportedOS,
+		},
+		{
+			name: "windows workload",
+			base: &Base{
+				OS:              OSWindows,
+				SecurityContext: &PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+			},
+		},
+		{
+			name: "windows workload with linux-only options",
+			base: &Base{
+				OS: OSWindows,
+				SecurityContext: &PodSecurityContext{
+					RunAsUser:      &runAsUser,
+					SeccompProfile: &SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				},
+				Containers: map[string]Container{
+					"web": {
+						Image: "mcr.microsoft.com/windows/servercore/iis",
+						SecurityContext: &SecurityContext{
+							RunAsNonRoot: &runAsNonRoot,
+							Privileged:   &privileged,
+							Capabilities: &Capabilities{Drop: []string{"ALL"}},
+						},
+					},
+				},
+			},
+			wantErr: ErrLinuxOnlyOption,
+			errMsg: "securityContext.runAsUser, securityContext.seccompProfile, " +
+				"containers.web.securityContext.privileged, containers.web.securityContext.capabilities",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOS(tt.base)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestEnforceSecurityBaselineWindows(t *testing.T) {
+	base := &Base{
+		OS: OSWindows,
+		Containers: map[string]Container{
+			"web": {Image: "mcr.microsoft.com/windows/servercore/iis"},
+		},
+	}
+	config := kusionapiv1.GenericConfig{
+		"securityContext": map[string]any{
+			"pod": map[string]any{
+				"runAsNonRoot": true,
+				"fsGroup":      2000,
+			},
+			"container": map[string]any{
+				"runAsNonRoot":           true,
+				"readOnlyRootFilesystem": true,
+				"capabilities": map[string]any{
+					"drop": []any{"ALL"},
+				},
+			},
+		},
+	}
+
+	err := enforceSecurityBaseline(base, config)
+	assert.NoError(t, err)
+
+	runAsNonRoot := true
+	assert.Equal(t, &PodSecurityContext{RunAsNonRoot: &runAsNonRoot}, base.SecurityContext)
+	assert.Equal(t, &SecurityContext{RunAsNonRoot: &runAsNonRoot}, base.Containers["web"].SecurityContext)
+	assert.NoError(t, validateOS(base))
+}
+
+func TestEnforceSecurityBaseline(t *testing.T) {
+	runAsUser := int64(1000)
+	runAsNonRoot := true
+	readOnly := false
+
+	base := &Base{
+		Containers: map[string]Container{
+			"nginx": {
+				Image: "nginx:v1",
+				SecurityContext: &SecurityContext{
+					RunAsUser:              &runAsUser,
+					ReadOnlyRootFilesystem: &readOnly,
+					Capabilities: &Capabilities{
+						Add:  []string{"NET_BIND_SERVICE"},
+						Drop: []string{"NET_RAW"},
+					},
+				},
+			},
+			"sidecar": {
+				Image: "sidecar:v1",
+			},
+		},
+	}
+	config := kusionapiv1.GenericConfig{
+		"securityContext": map[string]any{
+			"pod": map[string]any{
+				"runAsNonRoot": true,
+				"fsGroup":      2000,
+				"seccompProfile": map[string]any{
+					"type": "RuntimeDefault",
+				},
+			},
+			"container": map[string]any{
+				"readOnlyRootFilesystem":   true,
+				"allowPrivilegeEscalation": false,
+				"capabilities": map[string]any{
+					"drop": []any{"ALL"},
+				},
+			},
+		},
+	}
+
+	err := enforceSecurityBaseline(base, config)
+	assert.NoError(t, err)
+
+	fsGroup := int64(2000)
+	assert.Equal(t, &PodSecurityContext{
+		RunAsNonRoot:   &runAsNonRoot,
+		FSGroup:        &fsGroup,
+		SeccompProfile: &SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}, base.SecurityContext)
+
+	readOnlyEnforced, allowEscalation := true, false
+	assert.Equal(t, &SecurityContext{
+		RunAsUser:                &runAsUser,
+		AllowPrivilegeEscalation: &allowEscalation,
+		ReadOnlyRootFilesystem:   &readOnlyEnforced,
+		Capabilities: &Capabilities{
+			Add:  []string{"NET_BIND_SERVICE"},
+			Drop: []string{"NET_RAW", "ALL"},
+		},
+	}, base.Containers["nginx"].SecurityContext)
+	assert.Equal(t, &SecurityContext{
+		AllowPrivilegeEscalation: &allowEscalation,
+		ReadOnlyRootFilesystem:   &readOnlyEnforced,
+		Capabilities: &Capabilities{
+			Drop: []string{"ALL"},
+		},
+	}, base.Containers["sidecar"].SecurityContext)
+
+	v1SecurityContext := toV1SecurityContext(base.Containers["nginx"].SecurityContext)
+	assert.Equal(t, []corev1.Capability{"NET_RAW", "ALL"}, v1SecurityContext.Capabilities.Drop)
+	assert.Equal(t, &readOnlyEnforced, v1SecurityContext.ReadOnlyRootFilesystem)
+}
+
+func TestHandleVolumes(t *testing.T) {
+	mode := int32(0o644)
+	storageClass := "standard"
+
+	tests := []struct {
+		name        string
+		base        *Base
+		wantVolumes []corev1.Volume
+		wantPVCs    []corev1.PersistentVolumeClaim
+		wantErr     string
+	}{
+		{
+			name: "volumes of all sources",
+			base: &Base{
+				Containers: map[string]Container{
+					"nginx": {
+						Image: "nginx:v1",
+						VolumeMounts: []VolumeMount{
+							{Name: "cache", MountPath: "/cache"},
+							{Name: "data", MountPath: "/data"},
+						},
+					},
+				},
+				Volumes: map[string]Volume{
+					"cache": {EmptyDir: &EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+					"data":  {PVC: &PVCVolumeSource{StorageClass: storageClass, Size: "10Gi"}},
+					"shared": {PVC: &PVCVolumeSource{
+						ClaimName: "shared-claim",
+						ReadOnly:  true,
+					}},
+					"config": {ConfigMap: &ConfigMapVolumeSource{
+						Name:        "nginx-conf",
+						Items:       map[string]string{"nginx.conf": "nginx.conf"},
+						DefaultMode: "0644",
+					}},
+					"certs": {Secret: &SecretVolumeSource{Name: "tls"}},
+					"projected": {Projected: &ProjectedVolumeSource{
+						Sources: []VolumeProjection{
+							{ConfigMap: &ObjectProjection{Name: "nginx-conf"}},
+							{ServiceAccountToken: &ServiceAccountTokenProjection{Path: "token"}},
+						},
+					}},
+				},
+			},
+			wantVolumes: []corev1.Volume{
+				{Name: "cache", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}}},
+				{Name: "certs", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+				{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "nginx-conf"},
+					Items:                []corev1.KeyToPath{{Key: "nginx.conf", Path: "nginx.conf"}},
+					DefaultMode:          &mode,
+				}}},
+				{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "default-dev-foo-data",
+				}}},
+				{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					Sources: []corev1.VolumeProjection{
+						{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "nginx-conf"}}},
+						{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token"}},
+					},
+				}}},
+				{Name: "shared", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "shared-claim",
+					ReadOnly:  true,
+				}}},
+			},
+			wantPVCs: []corev1.PersistentVolumeClaim{
+				{
+					TypeMeta:   metav1.TypeMeta{Kind: "PersistentVolumeClaim", APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-data"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: &storageClass,
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "mount undeclared volume",
+			base: &Base{
+				Containers: map[string]Container{
+					"nginx": {
+						Image:        "nginx:v1",
+						VolumeMounts: []VolumeMount{{Name: "cache", MountPath: "/cache"}},
+					},
+				},
+			},
+			wantErr: "volume cache mounted by container nginx is not declared",
+		},
+		{
+			name: "multiple volume sources",
+			base: &Base{
+				Volumes: map[string]Volume{
+					"cache": {
+						EmptyDir: &EmptyDirVolumeSource{},
+						Secret:   &SecretVolumeSource{Name: "tls"},
+					},
+				},
+			},
+			wantErr: ErrInvalidVolumeSource.Error(),
+		},
+		{
+			name: "pvc without size",
+			base: &Base{
+				Volumes: map[string]Volume{
+					"data": {PVC: &PVCVolumeSource{StorageClass: storageClass}},
+				},
+			},
+			wantErr: ErrEmptyPVCSize.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumes, pvcs, err := handleVolumes(tt.base, "default-dev-foo")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVolumes, volumes)
+			assert.Equal(t, tt.wantPVCs, pvcs)
+		})
+	}
+}
+
+func TestValidateContainers(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers map[string]Container
+		wantErr    string
+	}{
+		{
+			name: "containers with distinct ports",
+			containers: map[string]Container{
+				"app": {
+					Image: "app:v1",
+					Ports: []ContainerPort{{Name: "http", ContainerPort: 8080}},
+				},
+				"proxy": {
+					Image: "envoy:v1",
+					Ports: []ContainerPort{
+						{Name: "proxy", ContainerPort: 15001},
+						{Name: "dns", ContainerPort: 15001, Protocol: UDP},
+					},
+				},
+			},
+		},
+		{
+			name:    "no container",
+			wantErr: ErrEmptyContainers.Error(),
+		},
+		{
+			name: "duplicate port names",
+			containers: map[string]Container{
+				"app":   {Image: "app:v1", Ports: []ContainerPort{{Name: "http", ContainerPort: 8080}}},
+				"proxy": {Image: "envoy:v1", Ports: []ContainerPort{{Name: "http", ContainerPort: 15001}}},
+			},
+			wantErr: "port name http of container proxy is duplicate with container app",
+		},
+		{
+			name: "duplicate ports",
+			containers: map[string]Container{
+				"app":   {Image: "app:v1", Ports: []ContainerPort{{ContainerPort: 8080}}},
+				"proxy": {Image: "envoy:v1", Ports: []ContainerPort{{ContainerPort: 8080, Protocol: TCP}}},
+			},
+			wantErr: "port 8080/TCP of container proxy is duplicate with container app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateContainers(tt.containers)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHandleConfigMaps(t *testing.T) {
+	immutable := true
+	base := &Base{
+		Containers: map[string]Container{
+			"nginx": {
+				Image: "nginx:v1",
+				EnvFrom: []EnvFromSource{
+					{ConfigMap: "env"},
+					{ConfigMap: "external-env", Prefix: "EXT_"},
+				},
+			},
+		},
+		Volumes: map[string]Volume{
+			"conf": {ConfigMap: &ConfigMapVolumeSource{Name: "conf"}},
+		},
+		ConfigMaps: map[string]ConfigMap{
+			"env": {
+				Data: map[string]string{"LOG_LEVEL": "info"},
+			},
+			"conf": {
+				Files:     map[string]string{"nginx.conf": "worker_processes 1;"},
+				Immutable: true,
+			},
+		},
+	}
+
+	configMaps := handleConfigMaps(base, "default-dev-foo")
+	assert.Equal(t, []corev1.ConfigMap{
+		{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-conf"},
+			Data:       map[string]string{"nginx.conf": "worker_processes 1;"},
+			Immutable:  &immutable,
+		},
+		{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-env"},
+			Data:       map[string]string{"LOG_LEVEL": "info"},
+		},
+	}, configMaps)
+	assert.Equal(t, "default-dev-foo-conf", base.Volumes["conf"].ConfigMap.Name)
+
+	envFrom, err := handleEnvFrom(base.Containers["nginx"].EnvFrom)
+	assert.NoError(t, err)
+	assert.Equal(t, []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "default-dev-foo-env"}}},
+		{Prefix: "EXT_", ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "external-env"}}},
+	}, envFrom)
+
+	_, err = handleEnvFrom([]EnvFromSource{{ConfigMap: "env", Secret: "token"}})
+	assert.ErrorIs(t, err, ErrInvalidEnvFrom)
+}
+
+func TestHandleDNS(t *testing.T) {
+	base := &Base{
+		HostAliases: []HostAlias{
+			{IP: "10.0.0.1", Hostnames: []string{"foo.internal", "bar.internal"}},
+		},
+		DNSPolicy: corev1.DNSNone,
+		DNSConfig: &DNSConfig{
+			Nameservers: []string{"10.0.0.10"},
+			Searches:    []string{"svc.cluster.local"},
+			Options: map[string]string{
+				"ndots":  "2",
+				"rotate": "",
+			},
+		},
+	}
+	spec := &corev1.PodSpec{DNSPolicy: corev1.DNSClusterFirstWithHostNet}
+	handleDNS(base, spec)
+
+	ndots := "2"
+	assert.Equal(t, []corev1.HostAlias{
+		{IP: "10.0.0.1", Hostnames: []string{"foo.internal", "bar.internal"}},
+	}, spec.HostAliases)
+	assert.Equal(t, corev1.DNSNone, spec.DNSPolicy)
+	assert.Equal(t, &corev1.PodDNSConfig{
+		Nameservers: []string{"10.0.0.10"},
+		Searches:    []string{"svc.cluster.local"},
+		Options: []corev1.PodDNSConfigOption{
+			{Name: "ndots", Value: &ndots},
+			{Name: "rotate"},
+		},
+	}, spec.DNSConfig)
+}
+
+func TestHandleRegistryCredentials(t *testing.T) {
+	config := kusionapiv1.GenericConfig{
+		FieldRegistryCredentials: map[string]any{
+			"registry.example.com": map[string]any{
+				"username": "admin",
+				"password": "passwd",
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		credentials     *RegistryCredentials
+		wantPullSecrets []corev1.LocalObjectReference
+		wantDockerCfg   string
+		wantErr         string
+	}{
+		{
+			name:        "no registry credentials",
+			credentials: nil,
+		},
+		{
+			name:            "existing pull secrets",
+			credentials:     &RegistryCredentials{ImagePullSecrets: []string{"my-pull-secret"}},
+			wantPullSecrets: []corev1.LocalObjectReference{{Name: "my-pull-secret"}},
+		},
+		{
+			name: "generated pull secret",
+			credentials: &RegistryCredentials{
+				ImagePullSecrets: []string{"my-pull-secret"},
+				Registries:       []string{"registry.example.com"},
+			},
+			wantPullSecrets: []corev1.LocalObjectReference{
+				{Name: "my-pull-secret"},
+				{Name: "default-dev-foo-registry-credentials"},
+			},
+			wantDockerCfg: `{"auths":{"registry.example.com":{"auth":"YWRtaW46cGFzc3dk","password":"passwd","username":"admin"}}}`,
+		},
+		{
+			name:        "unknown registry",
+			credentials: &RegistryCredentials{Registries: []string{"docker.io"}},
+			wantErr:     "credential of registry docker.io is not found in workspace",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pullSecrets, secret, err := handleRegistryCredentials(&Base{RegistryCredentials: tt.credentials}, config, "default-dev-foo")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPullSecrets, pullSecrets)
+			if tt.wantDockerCfg == "" {
+				assert.Nil(t, secret)
+				return
+			}
+			assert.Equal(t, corev1.SecretTypeDockerConfigJson, secret.Type)
+			assert.Equal(t, tt.wantDockerCfg, string(secret.Data[corev1.DockerConfigJsonKey]))
+		})
+	}
+}
+
+func TestConfigChecksum(t *testing.T) {
+	configMaps := []corev1.ConfigMap{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-app"}, Data: map[string]string{"LOG_LEVEL": "info"}},
+	}
+	secrets := []corev1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default-dev-foo-registry-credentials"}, Data: map[string][]byte{"key": []byte("value")}},
+	}
+
+	checksum, err := configChecksum(configMaps, secrets)
+	assert.NoError(t, err)
+	assert.Len(t, checksum, 64)
+
+	// The checksum is stable for the same configuration.
+	again, err := configChecksum(configMaps, secrets)
+	assert.NoError(t, err)
+	assert.Equal(t, checksum, again)
+
+	// The checksum changes once the configuration is changed.
+	configMaps[0].Data["LOG_LEVEL"] = "debug"
+	changed, err := configChecksum(configMaps, secrets)
+	assert.NoError(t, err)
+	assert.NotEqual(t, checksum, changed)
+}
+
+func TestHandleSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"data":{"password":"secret"}}}`))
+	}))
+	defer server.Close()
+
+	base := &Base{
+		Secrets: map[string]Secret{
+			"basic-auth": {
+				Type:      SecretTypeBasic,
+				Data:      map[string]string{"username": "admin", "password": "admin"},
+				Immutable: true,
+			},
+			"db-credentials": {
+				Type: SecretTypeExternal,
+				Data: map[string]string{"password": "ref://db/password"},
+			},
+		},
+	}
+
+	_, _, err := handleSecrets(context.Background(), base, nil)
+	assert.ErrorIs(t, err, ErrEmptySecretStore)
+
+	config := kusionapiv1.GenericConfig{
+		FieldSecretStore: map[string]interface{}{
+			"provider": SecretStoreVault,
+			"server":   server.URL,
+		},
+	}
+	_, _, err = handleSecrets(context.Background(), base, config)
+	assert.ErrorIs(t, err, ErrEmptySecretStoreCreds)
+
+	t.Setenv("VAULT_TOKEN", "vault-token")
+	secrets, providerClasses, err := handleSecrets(context.Background(), base, config)
+	assert.NoError(t, err)
+	immutable := true
+	assert.Equal(t, []corev1.Secret{
+		{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "basic-auth"},
+			Type:       corev1.SecretTypeBasicAuth,
+			Data:       map[string][]byte{"username": []byte("admin"), "password": []byte("admin")},
+			Immutable:  &immutable,
+		},
+		{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "db-credentials"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"password": []byte("secret")},
+		},
+	}, secrets)
+	assert.Empty(t, providerClasses)
+
+	// The external secrets are mounted via csi instead of being materialized as Secrets.
+	config[FieldSecretStore] = map[string]interface{}{
+		"provider": SecretStoreAWS,
+		"region":   "us-east-1",
+		"mode":     SecretStoreModeCSI,
+	}
+	secrets, providerClasses, err = handleSecrets(context.Background(), base, config)
+	assert.NoError(t, err)
+	assert.Len(t, secrets, 1)
+	assert.Len(t, providerClasses, 1)
+	assert.Equal(t, "db-credentials", providerClasses[0].GetName())
+
+	// The external secrets are synced by the ExternalSecrets in externalsecret mode.
+	config[FieldSecretStore] = map[string]interface{}{
+		"provider": SecretStoreAWS,
+		"mode":     SecretStoreModeExternalSecret,
+		"storeRef": "aws-secrets-manager",
+	}
+	secrets, externalSecrets, err := handleSecrets(context.Background(), base, config)
+	assert.NoError(t, err)
+	assert.Len(t, secrets, 1)
+	assert.Len(t, externalSecrets, 1)
+	assert.Equal(t, "ExternalSecret", externalSecrets[0].GetKind())
+}
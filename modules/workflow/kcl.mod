@@ -0,0 +1,3 @@
+[package]
+name = "workflow"
+version = "0.1.0"
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	temporalCloudNamespace = "temporalcloud_namespace"
+	temporalCloudPort      = 7233
+
+	certificateSuffix     = "temporal-tls"
+	certificateAPIVersion = "cert-manager.io/v1"
+	certificateKind       = "Certificate"
+	certManagerGroup      = "cert-manager.io"
+	certManagerIssuerKind = "Issuer"
+)
+
+// defaultTemporalCloudProviderCfg is the provider config of the Temporal Cloud Terraform provider,
+// whose API key is configured in the workspace, or by TEMPORAL_CLOUD_API_KEY.
+var defaultTemporalCloudProviderCfg = module.ProviderConfig{
+	Source:  "temporalio/temporalcloud",
+	Version: "0.0.16",
+}
+
+// generateCloudResources generates the namespace provisioned by Temporal Cloud and the client
+// certificate issued by cert-manager, and returns them along with the gRPC endpoint of the
+// namespace and its ID.
+func (workflow *Workflow) generateCloudResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, string, string, error) {
+	name := workflow.namespaceName(request)
+	id, err := module.TerraformResourceID(defaultTemporalCloudProviderCfg, temporalCloudNamespace, name)
+	if err != nil {
+		return nil, "", "", err
+	}
+	namespace, err := module.WrapTFResourceToKusionResource(defaultTemporalCloudProviderCfg, temporalCloudNamespace, id, map[string]interface{}{
+		"name":               name,
+		"regions":            []string{workflow.platform.Region},
+		"accepted_client_ca": base64.StdEncoding.EncodeToString([]byte(workflow.platform.CACertificate)),
+		"retention_days":     workflow.RetentionDays,
+	}, nil)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	certificate, err := workflow.generateCertificate(request, name)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// The namespaces of Temporal Cloud are addressed by <namespace>.<account>.tmprl.cloud.
+	endpoint := fmt.Sprintf("%s.%s.tmprl.cloud:%d", name, workflow.platform.AccountID, temporalCloudPort)
+	return []kusionapiv1.Resource{*namespace, *certificate}, endpoint, module.KusionPathDependency(namespace.ID, "id"), nil
+}
+
+// generateCertificate generates the cert-manager Certificate of the client certificate of the
+// namespace, whose Secret holds the certificate and the private key injected into the workload.
+func (workflow *Workflow) generateCertificate(request *module.GeneratorRequest, namespace string) (*kusionapiv1.Resource, error) {
+	issuer := workflow.platform.Issuer
+	kind, group := issuer.Kind, issuer.Group
+	if kind == "" {
+		kind = certManagerIssuerKind
+	}
+	if group == "" {
+		group = certManagerGroup
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      moduleutil.ResourceName(request, certificateSuffix, moduleutil.KubernetesNamingRule),
+		Namespace: request.Project,
+	}
+	// Round trip the object through JSON, so that it only holds the JSON values accepted by the
+	// unstructured object.
+	data, err := json.Marshal(map[string]interface{}{
+		"apiVersion": certificateAPIVersion,
+		"kind":       certificateKind,
+		"metadata":   map[string]interface{}{"name": objectMeta.Name, "namespace": objectMeta.Namespace},
+		"spec": map[string]interface{}{
+			"secretName": objectMeta.Name,
+			"commonName": namespace,
+			"usages":     []string{"client auth"},
+			"privateKey": map[string]interface{}{"algorithm": "ECDSA", "size": 256},
+			"issuerRef":  map[string]interface{}{"name": issuer.Name, "kind": kind, "group": group},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{}
+	if err = json.Unmarshal(data, &obj.Object); err != nil {
+		return nil, err
+	}
+
+	resourceID := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: certificateAPIVersion, Kind: certificateKind}, objectMeta)
+	return module.WrapK8sResourceToKusionResource(resourceID, obj)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"testutil"
+)
+
+func TestWorkflow_GenerateCloudResources(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	workflow := &Workflow{
+		RetentionDays: 14,
+		platform: PlatformConfig{
+			AccountID:     "a1b2c",
+			Region:        "aws-us-east-1",
+			CACertificate: testCACertificate,
+			Issuer:        &CertificateIssuer{Name: "temporal-ca", Kind: "ClusterIssuer"},
+		},
+	}
+
+	resources, endpoint, namespace, err := workflow.generateCloudResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 2) {
+		return
+	}
+	assert.Equal(t, "default-dev-foo.a1b2c.tmprl.cloud:7233", endpoint)
+	assert.Equal(t, module.KusionPathDependency(resources[0].ID, "id"), namespace)
+	assert.Equal(t, map[string]interface{}{
+		"name":               "default-dev-foo",
+		"regions":            []string{"aws-us-east-1"},
+		"accepted_client_ca": base64.StdEncoding.EncodeToString([]byte(testCACertificate)),
+		"retention_days":     14,
+	}, resources[0].Attributes)
+
+	spec := resources[1].Attributes["spec"].(map[string]interface{})
+	assert.Equal(t, "default-dev-foo-temporal-tls", spec["secretName"])
+	assert.Equal(t, "default-dev-foo", spec["commonName"])
+	assert.Equal(t, map[string]interface{}{
+		"name":  "temporal-ca",
+		"kind":  "ClusterIssuer",
+		"group": certManagerGroup,
+	}, spec["issuerRef"])
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"moduleutil"
+)
+
+const (
+	localSuffix = "temporal"
+
+	temporalPort = 7233
+	databasePort = 5432
+	databaseUser = "temporal"
+
+	// databasePasswordKey is the key of the database password in the Secret of the on-cluster
+	// Temporal server.
+	databasePasswordKey = "databasePassword"
+)
+
+// generateLocalResources generates the Temporal server deployed on-cluster for the App along with
+// its PostgreSQL database, and returns them along with the gRPC endpoint of the server and the
+// namespace registered on its start.
+func (workflow *Workflow) generateLocalResources(request *module.GeneratorRequest) ([]kusionapiv1.Resource, string, string, error) {
+	name := moduleutil.ResourceName(request, localSuffix, moduleutil.KubernetesNamingRule)
+
+	secret := &v1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+		},
+		Type:       v1.SecretTypeOpaque,
+		StringData: map[string]string{databasePasswordKey: localSecret(request, databasePasswordKey)},
+	}
+
+	pvc := &v1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    localLabels(name),
+		},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+			Resources: v1.VolumeResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceStorage: resource.MustParse(workflow.platform.StorageSize),
+				},
+			},
+		},
+	}
+
+	service := &v1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    localLabels(name),
+		},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "grpc", Port: temporalPort, TargetPort: intstr.FromString("grpc")},
+			},
+			Selector: localLabels(name),
+		},
+	}
+
+	namespace := workflow.namespaceName(request)
+	var resources []kusionapiv1.Resource
+	for _, obj := range []moduleutil.K8sObject{secret, pvc, workflow.generateLocalDeployment(request, name, namespace), service} {
+		res, err := moduleutil.WrapK8sResource(obj)
+		if err != nil {
+			return nil, "", "", err
+		}
+		resources = append(resources, *res)
+	}
+
+	endpoint := fmt.Sprintf("%s.%s.svc:%d", name, request.Project, temporalPort)
+	return resources, endpoint, namespace, nil
+}
+
+// generateLocalDeployment generates the Deployment of the Temporal server, which creates its
+// schema in the PostgreSQL database running in the same Pod and registers the namespace on start.
+func (workflow *Workflow) generateLocalDeployment(request *module.GeneratorRequest, name, namespace string) *appsv1.Deployment {
+	passwordEnv := func(env string) v1.EnvVar {
+		return v1.EnvVar{
+			Name: env,
+			ValueFrom: &v1.EnvVarSource{
+				SecretKeyRef: &v1.SecretKeySelector{
+					LocalObjectReference: v1.LocalObjectReference{Name: name},
+					Key:                  databasePasswordKey,
+				},
+			},
+		}
+	}
+	replicas := int32(1)
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "Deployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    localLabels(name),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: localLabels(name)},
+			// The volume of the database is mounted by one Pod at a time.
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: localLabels(name)},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "temporal",
+							Image: workflow.platform.Image,
+							Env: []v1.EnvVar{
+								{Name: "DB", Value: "postgres12"},
+								{Name: "DB_PORT", Value: fmt.Sprint(databasePort)},
+								{Name: "POSTGRES_SEEDS", Value: "localhost"},
+								{Name: "POSTGRES_USER", Value: databaseUser},
+								passwordEnv("POSTGRES_PWD"),
+								{Name: "DEFAULT_NAMESPACE", Value: namespace},
+								{Name: "DEFAULT_NAMESPACE_RETENTION", Value: fmt.Sprintf("%dh", workflow.RetentionDays*24)},
+							},
+							Ports: []v1.ContainerPort{{Name: "grpc", ContainerPort: temporalPort}},
+							ReadinessProbe: &v1.Probe{
+								ProbeHandler: v1.ProbeHandler{
+									TCPSocket: &v1.TCPSocketAction{Port: intstr.FromString("grpc")},
+								},
+							},
+						},
+						{
+							Name:  "postgres",
+							Image: workflow.platform.DatabaseImage,
+							Env: []v1.EnvVar{
+								{Name: "POSTGRES_USER", Value: databaseUser},
+								passwordEnv("POSTGRES_PASSWORD"),
+								// The data lives in the subdirectory since the root of the volume
+								// may hold lost+found.
+								{Name: "PGDATA", Value: "/var/lib/postgresql/data/pgdata"},
+							},
+							Ports:        []v1.ContainerPort{{Name: "postgres", ContainerPort: databasePort}},
+							VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/var/lib/postgresql/data"}},
+						},
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: "data",
+							VolumeSource: v1.VolumeSource{
+								PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: name},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// localLabels returns the labels selecting the Pods of the on-cluster Temporal server.
+func localLabels(name string) map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name":     "temporal",
+		"app.kubernetes.io/instance": name,
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"testutil"
+)
+
+func TestWorkflow_GenerateLocalResources(t *testing.T) {
+	request := testutil.NewRequest().Build()
+	workflow := &Workflow{
+		Namespace:     "orders",
+		RetentionDays: 3,
+		platform: PlatformConfig{
+			Image:         defaultImage,
+			DatabaseImage: defaultDatabaseImage,
+			StorageSize:   defaultStorageSize,
+		},
+	}
+
+	resources, endpoint, namespace, err := workflow.generateLocalResources(request)
+	assert.NoError(t, err)
+	if !assert.Len(t, resources, 4) {
+		return
+	}
+	assert.Equal(t, "default-dev-foo-temporal.default.svc:7233", endpoint)
+	assert.Equal(t, "orders", namespace)
+
+	spec := resources[2].Attributes["spec"].(map[string]interface{})
+	template := spec["template"].(map[string]interface{})["spec"].(map[string]interface{})
+	temporal := template["containers"].([]interface{})[0].(map[string]interface{})
+	assert.Contains(t, temporal["env"], map[string]interface{}{"name": "DEFAULT_NAMESPACE", "value": "orders"})
+	assert.Contains(t, temporal["env"], map[string]interface{}{"name": "DEFAULT_NAMESPACE_RETENTION", "value": "72h"})
+}
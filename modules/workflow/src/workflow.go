@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+	"kusionstack.io/kusion-module-framework/pkg/server"
+	"moduleutil"
+)
+
+const (
+	defaultRetentionDays = 7
+	maxRetentionDays     = 90
+	defaultImage         = "temporalio/auto-setup:1.24.2"
+	defaultDatabaseImage = "postgres:15"
+	defaultStorageSize   = "10Gi"
+
+	// The env vars injected into the workload, which are read by the Temporal CLI and SDK
+	// samples.
+	addressEnv     = "TEMPORAL_ADDRESS"
+	namespaceEnv   = "TEMPORAL_NAMESPACE"
+	tlsCertDataEnv = "TEMPORAL_TLS_CERT_DATA"
+	tlsKeyDataEnv  = "TEMPORAL_TLS_KEY_DATA"
+)
+
+// namespacePattern matches the names of the Temporal namespaces.
+var namespacePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,37}[a-z0-9])?$`)
+
+// TemporalNamingRule is the rule of the names of the Temporal namespaces.
+var TemporalNamingRule = moduleutil.NamingRule{MaxLength: 39}
+
+var (
+	ErrInvalidNamespace     = errors.New("namespace must consist of at most 39 lowercase letters, digits and hyphens, and start and end with a letter or digit")
+	ErrInvalidRetentionDays = errors.New("retentionDays must be between 1 and 90")
+	ErrEmptyCloudRegion     = errors.New("region must be specified for Temporal Cloud, e.g. aws-us-east-1")
+	ErrInvalidCACertificate = errors.New("caCertificate must be the PEM encoded CA certificate accepted by Temporal Cloud")
+	ErrEmptyIssuer          = errors.New("issuer signing the client certificates must be specified for Temporal Cloud")
+	ErrCloudLocalOptions    = errors.New("image, databaseImage and storageSize are only supported by the on-cluster Temporal server")
+	ErrInvalidStorageSize   = errors.New("storageSize must be a valid quantity, e.g. 10Gi")
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == moduleutil.SchemaCommand {
+		if err := moduleutil.PrintConfigSchemas(os.Stdout, Workflow{}, PlatformConfig{}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	server.Start(&Workflow{})
+}
+
+// Workflow describes the Temporal namespace the workload runs its durable workflows in, which is
+// served by the Temporal server deployed on-cluster, or provisioned by Temporal Cloud and accessed
+// with the client certificate issued by cert-manager.
+type Workflow struct {
+	// Namespace is the name of the Temporal namespace, which defaults to the name of the App.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// RetentionDays is the days the closed workflows are retained for, which defaults to 7.
+	RetentionDays int `json:"retentionDays,omitempty" yaml:"retentionDays,omitempty"`
+
+	// The platform config of the workflow module.
+	platform PlatformConfig
+}
+
+// PlatformConfig describes the platform config of the workflow module in workspace.
+type PlatformConfig struct {
+	// AccountID is the account of Temporal Cloud, whose API key configures the Temporal Cloud
+	// Terraform provider. The Temporal server is deployed on-cluster for each App if not set.
+	AccountID string `json:"accountID,omitempty" yaml:"accountID,omitempty"`
+	// Region is the region of the namespaces of Temporal Cloud, e.g. aws-us-east-1.
+	Region string `json:"region,omitempty" yaml:"region,omitempty"`
+	// CACertificate is the PEM encoded CA certificate accepted by the namespaces of Temporal Cloud,
+	// which signs the client certificates issued by the issuer.
+	CACertificate string `json:"caCertificate,omitempty" yaml:"caCertificate,omitempty"`
+	// Issuer is the cert-manager issuer of the client certificates of Temporal Cloud.
+	Issuer *CertificateIssuer `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// Image is the image of the on-cluster Temporal server.
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+	// DatabaseImage is the image of the PostgreSQL database of the on-cluster Temporal server.
+	DatabaseImage string `json:"databaseImage,omitempty" yaml:"databaseImage,omitempty"`
+	// StorageSize is the size of the volume of the PostgreSQL database, e.g. 10Gi.
+	StorageSize string `json:"storageSize,omitempty" yaml:"storageSize,omitempty"`
+	// The default dev config, which is merged with the one declared by the application.
+	Defaults *Workflow `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// The policies checked against the generated resources.
+	Policies []moduleutil.Policy `json:"policies,omitempty" yaml:"policies,omitempty"`
+	// PodSecurity is the Pod Security Standards level the pod specs are checked against, i.e.
+	// privileged, baseline or restricted.
+	PodSecurity string `json:"podSecurity,omitempty" yaml:"podSecurity,omitempty"`
+}
+
+// CertificateIssuer references the issuer of cert-manager.
+type CertificateIssuer struct {
+	// Name of the issuer.
+	Name string `json:"name" yaml:"name"`
+	// Kind of the issuer, Issuer or ClusterIssuer, defaults to Issuer.
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Group of the issuer, defaults to cert-manager.io.
+	Group string `json:"group,omitempty" yaml:"group,omitempty"`
+}
+
+// Generate implements the generation logic of the workflow module.
+func (workflow *Workflow) Generate(ctx context.Context, request *module.GeneratorRequest) (response *module.GeneratorResponse, err error) {
+	// Get the module logger with the generator context.
+	logger := log.GetModuleLogger(ctx)
+	logger.Info("Generating resources...")
+
+	// Recover from the panic and wrap the returned error into the structured module error.
+	defer moduleutil.Recover(ctx, "workflow", &response, &err)
+
+	// Attach the endpoint and the namespace of Temporal as the connection info, and finalize the
+	// generated resources with the standard metadata, the policy and Pod Security Standards checks,
+	// and the preview summary if enabled in the workspace context.
+	var endpoint, namespace string
+	defer func() {
+		if err == nil {
+			name := moduleutil.AppName(request) + "-workflow-connection-info"
+			info := map[string]string{"endpoint": endpoint, "namespace": namespace}
+			if err = moduleutil.AttachConnectionInfo("workflow", name, request, response, info); err != nil {
+				response = nil
+				return
+			}
+			if err = moduleutil.Finalize("workflow", request, response); err != nil {
+				response = nil
+			}
+		}
+	}()
+
+	// Workflow does not exist in AppConfiguration configs.
+	if request.DevConfig == nil {
+		logger.Info("Workflow does not exist in AppConfig config")
+		return nil, nil
+	}
+
+	// Get the complete configs of the workflow module.
+	if err := workflow.GetCompleteConfig(request.DevConfig, request.PlatformConfig); err != nil {
+		return nil, moduleutil.NewModuleError("workflow", moduleutil.PhaseComplete, err)
+	}
+
+	// Provision the namespace by Temporal Cloud along with the client certificate, or deploy the
+	// Temporal server on-cluster with the namespace registered on its start.
+	var resources []kusionapiv1.Resource
+	if workflow.cloud() {
+		resources, endpoint, namespace, err = workflow.generateCloudResources(request)
+	} else {
+		resources, endpoint, namespace, err = workflow.generateLocalResources(request)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &module.GeneratorResponse{
+		Resources: resources,
+		Patcher:   workflow.generatePatcher(request, endpoint, namespace),
+	}, nil
+}
+
+// GetCompleteConfig combines the configs in devModuleConfig and platformModuleConfig to form a complete
+// configuration for the workflow module.
+func (workflow *Workflow) GetCompleteConfig(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) error {
+	// Reject the unknown fields and mismatched value types instead of ignoring them silently.
+	if err := moduleutil.ValidateConfig(devConfig, Workflow{}); err != nil {
+		return moduleutil.NewModuleError("workflow", moduleutil.PhaseValidate, fmt.Errorf("validate workflow dev config failed, %w", err))
+	}
+	if err := moduleutil.ValidateConfig(platformConfig, PlatformConfig{}); err != nil {
+		return moduleutil.NewModuleError("workflow", moduleutil.PhaseValidate, fmt.Errorf("validate workflow platform config failed, %w", err))
+	}
+
+	// Fill the unset configs in devConfig with the defaults in platformConfig.
+	devConfig, err := moduleutil.MergeDefaults(devConfig, platformConfig)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(devConfig)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(out, workflow); err != nil {
+		return err
+	}
+
+	if platformConfig != nil {
+		out, err = json.Marshal(platformConfig)
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(out, &workflow.platform); err != nil {
+			return err
+		}
+	}
+
+	if workflow.RetentionDays == 0 {
+		workflow.RetentionDays = defaultRetentionDays
+	}
+	if !workflow.cloud() {
+		if workflow.platform.Image == "" {
+			workflow.platform.Image = defaultImage
+		}
+		if workflow.platform.DatabaseImage == "" {
+			workflow.platform.DatabaseImage = defaultDatabaseImage
+		}
+		if workflow.platform.StorageSize == "" {
+			workflow.platform.StorageSize = defaultStorageSize
+		}
+	}
+
+	return workflow.Validate()
+}
+
+// Validate validates whether the configs of the workflow module are valid.
+func (workflow *Workflow) Validate() error {
+	if workflow.Namespace != "" && !namespacePattern.MatchString(workflow.Namespace) {
+		return fmt.Errorf("%w, got %q", ErrInvalidNamespace, workflow.Namespace)
+	}
+	if workflow.RetentionDays < 1 || workflow.RetentionDays > maxRetentionDays {
+		return fmt.Errorf("%w, got %d", ErrInvalidRetentionDays, workflow.RetentionDays)
+	}
+
+	platform := workflow.platform
+	if workflow.cloud() {
+		if platform.Region == "" {
+			return ErrEmptyCloudRegion
+		}
+		if block, _ := pem.Decode([]byte(platform.CACertificate)); block == nil || block.Type != "CERTIFICATE" {
+			return ErrInvalidCACertificate
+		}
+		if platform.Issuer == nil || platform.Issuer.Name == "" {
+			return ErrEmptyIssuer
+		}
+		if platform.Image != "" || platform.DatabaseImage != "" || platform.StorageSize != "" {
+			return ErrCloudLocalOptions
+		}
+		return nil
+	}
+
+	if _, err := resource.ParseQuantity(platform.StorageSize); err != nil {
+		return fmt.Errorf("%w, got %q", ErrInvalidStorageSize, platform.StorageSize)
+	}
+
+	return nil
+}
+
+// namespaceName returns the name of the Temporal namespace, which defaults to the name of the App.
+func (workflow *Workflow) namespaceName(request *module.GeneratorRequest) string {
+	if workflow.Namespace != "" {
+		return workflow.Namespace
+	}
+	return moduleutil.ResourceName(request, "", TemporalNamingRule)
+}
+
+// cloud returns whether the namespace is provisioned by Temporal Cloud.
+func (workflow *Workflow) cloud() bool {
+	return workflow.platform.AccountID != ""
+}
+
+// generatePatcher generates the patcher injecting the endpoint and the namespace of Temporal into
+// the containers of the workload as the env vars, along with the client certificate of Temporal
+// Cloud.
+func (workflow *Workflow) generatePatcher(request *module.GeneratorRequest, endpoint, namespace string) *kusionapiv1.Patcher {
+	envs := []v1.EnvVar{
+		{Name: addressEnv, Value: endpoint},
+		{Name: namespaceEnv, Value: namespace},
+	}
+	if workflow.cloud() {
+		secretEnv := func(env, key string) v1.EnvVar {
+			return v1.EnvVar{
+				Name: env,
+				ValueFrom: &v1.EnvVarSource{
+					SecretKeyRef: &v1.SecretKeySelector{
+						LocalObjectReference: v1.LocalObjectReference{Name: moduleutil.ResourceName(request, certificateSuffix, moduleutil.KubernetesNamingRule)},
+						Key:                  key,
+					},
+				},
+			}
+		}
+		envs = append(envs, secretEnv(tlsCertDataEnv, v1.TLSCertKey), secretEnv(tlsKeyDataEnv, v1.TLSPrivateKeyKey))
+	}
+	return &kusionapiv1.Patcher{Environments: envs}
+}
+
+// localSecret returns the fixed secret of the name in the App of the request for the on-cluster
+// Temporal server, which stays the same across the generations without any state.
+func localSecret(request *module.GeneratorRequest, name string) string {
+	hash := md5.Sum([]byte(request.Project + request.Stack + request.App + name))
+	return hex.EncodeToString(hash[:])
+}
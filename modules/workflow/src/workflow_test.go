@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"moduleutil"
+	"testutil"
+)
+
+// testCACertificate is the PEM block of the CA certificate accepted by Temporal Cloud in tests.
+const testCACertificate = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+
+func TestWorkflow_Generate(t *testing.T) {
+	cloudConfig := kusionapiv1.GenericConfig{
+		"accountID":     "a1b2c",
+		"region":        "aws-us-east-1",
+		"caCertificate": testCACertificate,
+		"issuer":        map[string]interface{}{"name": "temporal-ca", "kind": "ClusterIssuer"},
+	}
+
+	tests := []struct {
+		name             string
+		devConfig        kusionapiv1.Accessory
+		platformConfig   kusionapiv1.GenericConfig
+		expectedPhase    moduleutil.Phase
+		expectedErr      error
+		expectedKinds    []string
+		expectedEndpoint string
+		expectedEnvs     []string
+	}{
+		{
+			name:             "local",
+			devConfig:        kusionapiv1.Accessory{},
+			expectedKinds:    []string{"Secret", "PersistentVolumeClaim", "Deployment", "Service"},
+			expectedEndpoint: "default-dev-foo-temporal.default.svc:7233",
+			expectedEnvs:     []string{addressEnv, namespaceEnv},
+		},
+		{
+			name:             "cloud",
+			devConfig:        kusionapiv1.Accessory{"namespace": "orders", "retentionDays": 30},
+			platformConfig:   cloudConfig,
+			expectedKinds:    []string{temporalCloudNamespace, certificateKind},
+			expectedEndpoint: "orders.a1b2c.tmprl.cloud:7233",
+			expectedEnvs:     []string{addressEnv, namespaceEnv, tlsCertDataEnv, tlsKeyDataEnv},
+		},
+		{
+			name:           "cloud without issuer",
+			devConfig:      kusionapiv1.Accessory{},
+			platformConfig: kusionapiv1.GenericConfig{"accountID": "a1b2c", "region": "aws-us-east-1", "caCertificate": testCACertificate},
+			expectedPhase:  moduleutil.PhaseComplete,
+			expectedErr:    ErrEmptyIssuer,
+		},
+		{
+			name:          "invalid retention days",
+			devConfig:     kusionapiv1.Accessory{"retentionDays": 365},
+			expectedPhase: moduleutil.PhaseComplete,
+			expectedErr:   ErrInvalidRetentionDays,
+		},
+		{
+			name:          "unknown field",
+			devConfig:     kusionapiv1.Accessory{"unknown": "foo"},
+			expectedPhase: moduleutil.PhaseValidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testutil.NewRequest().
+				WithServiceWorkload("Deployment").
+				WithDevConfig(tt.devConfig).
+				WithPlatformConfig(tt.platformConfig).
+				Build()
+
+			response, err := (&Workflow{}).Generate(context.Background(), request)
+			if tt.expectedPhase != "" {
+				var moduleErr *moduleutil.ModuleError
+				if assert.ErrorAs(t, err, &moduleErr) {
+					assert.Equal(t, tt.expectedPhase, moduleErr.Phase)
+				}
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			if !assert.Len(t, response.Resources, len(tt.expectedKinds)) {
+				return
+			}
+			for i, kind := range tt.expectedKinds {
+				if resourceType, ok := response.Resources[i].Extensions["resourceType"]; ok {
+					assert.Equal(t, kind, resourceType)
+					continue
+				}
+				assert.Equal(t, kind, response.Resources[i].Attributes["kind"])
+			}
+			var envs []string
+			for _, env := range response.Patcher.Environments {
+				envs = append(envs, env.Name)
+			}
+			assert.Equal(t, tt.expectedEnvs, envs)
+			assert.Equal(t, tt.expectedEndpoint, response.Patcher.Environments[0].Value)
+		})
+	}
+}
+
+func TestWorkflow_Validate(t *testing.T) {
+	local := PlatformConfig{StorageSize: "10Gi"}
+	cloud := PlatformConfig{
+		AccountID:     "a1b2c",
+		Region:        "aws-us-east-1",
+		CACertificate: testCACertificate,
+		Issuer:        &CertificateIssuer{Name: "temporal-ca"},
+	}
+
+	tests := []struct {
+		name        string
+		workflow    Workflow
+		expectedErr error
+	}{
+		{
+			name:     "valid local",
+			workflow: Workflow{RetentionDays: 7, platform: local},
+		},
+		{
+			name:     "valid cloud",
+			workflow: Workflow{Namespace: "orders", RetentionDays: 90, platform: cloud},
+		},
+		{
+			name:        "invalid namespace",
+			workflow:    Workflow{Namespace: "Orders", RetentionDays: 7, platform: local},
+			expectedErr: ErrInvalidNamespace,
+		},
+		{
+			name:        "empty region",
+			workflow:    Workflow{RetentionDays: 7, platform: PlatformConfig{AccountID: "a1b2c"}},
+			expectedErr: ErrEmptyCloudRegion,
+		},
+		{
+			name: "invalid ca certificate",
+			workflow: Workflow{RetentionDays: 7, platform: PlatformConfig{
+				AccountID: "a1b2c", Region: "aws-us-east-1", CACertificate: "MIIB",
+			}},
+			expectedErr: ErrInvalidCACertificate,
+		},
+		{
+			name: "local options of cloud",
+			workflow: Workflow{RetentionDays: 7, platform: PlatformConfig{
+				AccountID: "a1b2c", Region: "aws-us-east-1", CACertificate: testCACertificate,
+				Issuer: &CertificateIssuer{Name: "temporal-ca"}, StorageSize: "1Gi",
+			}},
+			expectedErr: ErrCloudLocalOptions,
+		},
+		{
+			name:        "invalid storage size",
+			workflow:    Workflow{RetentionDays: 7, platform: PlatformConfig{StorageSize: "10G1"}},
+			expectedErr: ErrInvalidStorageSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.workflow.Validate()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package moduleutil
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+const (
+	// ConnectionInfoKey is the key of the workspace context enabling the connection info ConfigMaps.
+	ConnectionInfoKey = "connectionInfo"
+	// ConnectionInfoLabel is the label of the connection info ConfigMaps whose value is the name of
+	// the App, so that the connection details of all the accessories of the App are listed by
+	// `kubectl get configmap -l kusionstack.io/connection-info=<app>`.
+	ConnectionInfoLabel = "kusionstack.io/connection-info"
+)
+
+// connectionInfoEnabled returns whether the connection info is enabled in the workspace context.
+func connectionInfoEnabled(request *module.GeneratorRequest) bool {
+	if request == nil {
+		return false
+	}
+	enabled, _ := request.Context[ConnectionInfoKey].(bool)
+	return enabled
+}
+
+// AttachConnectionInfo appends the informational ConfigMap of the connection info to the response
+// if enabled in the workspace context, whose keys are prefixed with the module name. The values
+// may be the Kusion path references resolved at apply time, and must never be the credentials.
+func AttachConnectionInfo(moduleName, name string, request *module.GeneratorRequest, response *module.GeneratorResponse, info map[string]string) error {
+	if !connectionInfoEnabled(request) || response == nil || len(info) == 0 {
+		return nil
+	}
+	data := make(map[string]string, len(info))
+	for k, v := range info {
+		data[moduleName+"."+k] = v
+	}
+	cm := &v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: request.Project,
+			Labels:    map[string]string{ConnectionInfoLabel: AppName(request)},
+		},
+		Data: data,
+	}
+	resource, err := module.WrapK8sResourceToKusionResource(module.KubernetesResourceID(cm.TypeMeta, cm.ObjectMeta), cm)
+	if err != nil {
+		return err
+	}
+	response.Resources = append(response.Resources, *resource)
+	return nil
+}
+
+// ConnectionInfoData returns the data of the connection info ConfigMap, or nil if the resource is
+// not a connection info ConfigMap.
+func ConnectionInfoData(res kusionapiv1.Resource) map[string]string {
+	metadata, _ := res.Attributes["metadata"].(map[string]interface{})
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if _, ok := labels[ConnectionInfoLabel]; !ok || res.Type != kusionapiv1.Kubernetes {
+		return nil
+	}
+	data, _ := res.Attributes["data"].(map[string]interface{})
+	info := make(map[string]string, len(data))
+	for k, v := range data {
+		info[k] = fmt.Sprint(v)
+	}
+	return info
+}
@@ -0,0 +1,52 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestAttachConnectionInfo(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		Context: kusionapiv1.GenericConfig{ConnectionInfoKey: true},
+	}
+	info := map[string]string{"host": "foo.default.svc"}
+
+	response := &module.GeneratorResponse{}
+	assert.NoError(t, AttachConnectionInfo("foo", "default-dev-foo-connection", request, response, info))
+	if assert.Len(t, response.Resources, 1) {
+		res := response.Resources[0]
+		assert.Equal(t, "v1:ConfigMap:default:default-dev-foo-connection", res.ID)
+		assert.Equal(t, map[string]string{"foo.host": "foo.default.svc"}, ConnectionInfoData(res))
+	}
+
+	// The connection info is attached only if enabled in the workspace context.
+	request.Context = nil
+	response = &module.GeneratorResponse{}
+	assert.NoError(t, AttachConnectionInfo("foo", "default-dev-foo-connection", request, response, info))
+	assert.Empty(t, response.Resources)
+}
+
+func TestConnectionInfoData(t *testing.T) {
+	assert.Nil(t, ConnectionInfoData(kusionapiv1.Resource{
+		Type: kusionapiv1.Kubernetes,
+		Attributes: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "foo"},
+			"data":     map[string]interface{}{"foo.host": "foo.default.svc"},
+		},
+	}))
+	assert.Equal(t, map[string]string{"foo.port": "5432"}, ConnectionInfoData(kusionapiv1.Resource{
+		Type: kusionapiv1.Kubernetes,
+		Attributes: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{ConnectionInfoLabel: "default-dev-foo"},
+			},
+			"data": map[string]interface{}{"foo.port": 5432},
+		},
+	}))
+}
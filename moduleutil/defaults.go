@@ -0,0 +1,56 @@
+package moduleutil
+
+import (
+	"fmt"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+// DefaultsKey is the key of the section in the platform config holding the default values of the
+// dev config, e.g.
+//
+//	defaults:
+//	  type: aws
+//	  labels:
+//	    team: payments
+const DefaultsKey = "defaults"
+
+// MergeDefaults returns the dev config deep-merged over the defaults section of the platform
+// config. The precedence rules are:
+//
+//  1. The values declared in the dev config always win.
+//  2. Nested maps are merged recursively, so that the unset keys of a map in the dev config are
+//     filled from the defaults.
+//  3. Lists and scalar values are never merged, the one in the dev config replaces the default
+//     as a whole.
+//
+// Neither of the given configs is modified.
+func MergeDefaults(devConfig kusionapiv1.Accessory, platformConfig kusionapiv1.GenericConfig) (kusionapiv1.Accessory, error) {
+	value, ok := platformConfig[DefaultsKey]
+	if !ok || value == nil {
+		return devConfig, nil
+	}
+	defaults, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the %s in platform config must be a map, got %T", DefaultsKey, value)
+	}
+	return deepMerge(defaults, devConfig), nil
+}
+
+// deepMerge returns a new map with the values in override merged over the ones in base.
+func deepMerge(base, override map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range override {
+		baseMap, baseIsMap := result[k].(map[string]interface{})
+		overrideMap, overrideIsMap := v.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			result[k] = deepMerge(baseMap, overrideMap)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
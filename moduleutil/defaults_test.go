@@ -0,0 +1,81 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestMergeDefaults(t *testing.T) {
+	tests := []struct {
+		name           string
+		devConfig      kusionapiv1.Accessory
+		platformConfig kusionapiv1.GenericConfig
+		want           kusionapiv1.Accessory
+		wantErr        bool
+	}{
+		{
+			name:      "no defaults",
+			devConfig: kusionapiv1.Accessory{"type": "aws"},
+			want:      kusionapiv1.Accessory{"type": "aws"},
+		},
+		{
+			name: "dev config takes precedence",
+			devConfig: kusionapiv1.Accessory{
+				"type": "local",
+				"labels": map[string]interface{}{
+					"team": "dev",
+				},
+				"list": []interface{}{"dev"},
+			},
+			platformConfig: kusionapiv1.GenericConfig{
+				"size": 20,
+				DefaultsKey: map[string]interface{}{
+					"type":    "aws",
+					"version": "8.0",
+					"labels": map[string]interface{}{
+						"team": "platform",
+						"tier": "backend",
+					},
+					"list": []interface{}{"default-1", "default-2"},
+				},
+			},
+			want: kusionapiv1.Accessory{
+				"type":    "local",
+				"version": "8.0",
+				"labels": map[string]interface{}{
+					"team": "dev",
+					"tier": "backend",
+				},
+				"list": []interface{}{"dev"},
+			},
+		},
+		{
+			name:      "nil dev config",
+			devConfig: nil,
+			platformConfig: kusionapiv1.GenericConfig{
+				DefaultsKey: map[string]interface{}{"type": "aws"},
+			},
+			want: kusionapiv1.Accessory{"type": "aws"},
+		},
+		{
+			name: "illegal defaults",
+			platformConfig: kusionapiv1.GenericConfig{
+				DefaultsKey: "aws",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeDefaults(tt.devConfig, tt.platformConfig)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
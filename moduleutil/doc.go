@@ -0,0 +1,26 @@
+// Package moduleutil provides the helpers shared by all the Kusion modules in the catalog:
+//
+//   - the structured ModuleError returned by the generators, so that the callers match the errors
+//     of every module with the same type;
+//   - Recover and Finalize, which recover the generators from the panics and finalize the
+//     generated resources;
+//   - the JSON Schemas of the module configs with the validation against them, and the merge of
+//     the defaults section of the platform config under the dev config;
+//   - the names of the generated resources rendered from the naming template;
+//   - EnvironmentClass, the class of the workspace keying the guardrails;
+//   - the wrapping of the generated objects into the Kusion resources, and ApplyTerraformHints
+//     setting the provider aliases and state groups of the Terraform resources;
+//   - the type and the pod spec of the workload patched by the modules;
+//   - the standard labels and tags of the generated resources, and the policies and the Pod
+//     Security Standards checked against them;
+//   - the Secret with the connection info of the module exported to the workload;
+//   - ResolveOutputRefs, PublishOutputs, DatabaseConnectionInfo and OutputSecretName, which
+//     resolve and publish the outputs of the modules;
+//   - the summary of the generated resources shown by the preview.
+//
+// Each module imports the package by a local replace directive in its go.mod:
+//
+//	require moduleutil v0.0.0
+//
+//	replace moduleutil => ../../../moduleutil
+package moduleutil
@@ -0,0 +1,66 @@
+package moduleutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// EnvironmentKey is the key of the environment class in the platform config and the workspace
+// context, where the one in the platform config takes precedence.
+const EnvironmentKey = "environment"
+
+// Environment is the class of the environment deployed by the workspace, which the guardrails of
+// the modules are keyed on.
+type Environment string
+
+const (
+	EnvironmentDev     Environment = "dev"
+	EnvironmentStaging Environment = "staging"
+	EnvironmentProd    Environment = "prod"
+)
+
+// ErrInvalidEnvironment is returned when the environment class is not dev, staging or prod.
+var ErrInvalidEnvironment = errors.New("invalid environment, must be dev, staging or prod")
+
+// environmentAliases are the workspace name segments classified into the environment classes.
+var environmentAliases = map[string]Environment{
+	"dev":         EnvironmentDev,
+	"development": EnvironmentDev,
+	"test":        EnvironmentDev,
+	"staging":     EnvironmentStaging,
+	"stage":       EnvironmentStaging,
+	"stg":         EnvironmentStaging,
+	"pre":         EnvironmentStaging,
+	"prod":        EnvironmentProd,
+	"production":  EnvironmentProd,
+	"prd":         EnvironmentProd,
+}
+
+// EnvironmentClass returns the environment class of the request, which is the one set in the
+// platform config or the workspace context if any. Otherwise, it is classified by the segments of
+// the workspace name, e.g. "prod-us-east" is prod, and defaults to dev.
+func EnvironmentClass(request *module.GeneratorRequest) (Environment, error) {
+	for _, config := range []map[string]interface{}{request.PlatformConfig, request.Context} {
+		if value, ok := config[EnvironmentKey].(string); ok && value != "" {
+			env := Environment(strings.ToLower(value))
+			if env != EnvironmentDev && env != EnvironmentStaging && env != EnvironmentProd {
+				return "", fmt.Errorf("%w, got %q", ErrInvalidEnvironment, value)
+			}
+			return env, nil
+		}
+	}
+
+	workspace, _ := request.Context[WorkspaceKey].(string)
+	segments := strings.FieldsFunc(strings.ToLower(workspace), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	for _, segment := range segments {
+		if env, ok := environmentAliases[segment]; ok {
+			return env, nil
+		}
+	}
+	return EnvironmentDev, nil
+}
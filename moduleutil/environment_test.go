@@ -0,0 +1,65 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestEnvironmentClass(t *testing.T) {
+	tests := []struct {
+		name           string
+		platformConfig kusionapiv1.GenericConfig
+		context        kusionapiv1.GenericConfig
+		expected       Environment
+		expectedErr    error
+	}{
+		{
+			name:     "default",
+			expected: EnvironmentDev,
+		},
+		{
+			name:           "platform config",
+			platformConfig: kusionapiv1.GenericConfig{EnvironmentKey: "Prod"},
+			context:        kusionapiv1.GenericConfig{EnvironmentKey: "staging"},
+			expected:       EnvironmentProd,
+		},
+		{
+			name:     "workspace context",
+			context:  kusionapiv1.GenericConfig{EnvironmentKey: "staging", WorkspaceKey: "prod"},
+			expected: EnvironmentStaging,
+		},
+		{
+			name:     "workspace name",
+			context:  kusionapiv1.GenericConfig{WorkspaceKey: "us-east-1_production"},
+			expected: EnvironmentProd,
+		},
+		{
+			name:     "unclassified workspace name",
+			context:  kusionapiv1.GenericConfig{WorkspaceKey: "product"},
+			expected: EnvironmentDev,
+		},
+		{
+			name:           "invalid environment",
+			platformConfig: kusionapiv1.GenericConfig{EnvironmentKey: "qa"},
+			expectedErr:    ErrInvalidEnvironment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := EnvironmentClass(&module.GeneratorRequest{
+				PlatformConfig: tt.platformConfig,
+				Context:        tt.context,
+			})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, env)
+		})
+	}
+}
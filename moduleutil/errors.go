@@ -0,0 +1,79 @@
+package moduleutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Phase is the phase of the module generation where the error occurs.
+type Phase string
+
+const (
+	// PhaseValidate validates the dev and platform config against the JSON Schema of the module.
+	PhaseValidate Phase = "validate"
+	// PhaseComplete completes the module config with the dev and platform config.
+	PhaseComplete Phase = "complete"
+	// PhaseGenerate generates the resources and patcher of the module.
+	PhaseGenerate Phase = "generate"
+)
+
+// ErrPanic is the cause of the ModuleError recovered from a panic of the generator.
+var ErrPanic = errors.New("generator panicked")
+
+// ModuleError is the structured error returned by the module generator, which records the module
+// name, the phase and the config path where the error occurs along with the wrapped cause.
+type ModuleError struct {
+	Module string
+	Phase  Phase
+	// Path is the path of the config field causing the error, e.g. "ports[0].port", and empty if
+	// the error is not caused by a specific field.
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *ModuleError) Error() string {
+	msg := fmt.Sprintf("%s module %s failed", e.Module, e.Phase)
+	if e.Path != "" {
+		msg += " at " + e.Path
+	}
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the error.
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigFieldError is the error of a config field, e.g. an unknown field or a mismatched value type.
+type ConfigFieldError struct {
+	Path   string
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *ConfigFieldError) Error() string {
+	return e.Path + ": " + e.Reason
+}
+
+// NewModuleError returns the ModuleError of the module in the phase caused by err, whose path is
+// the path of the first ConfigFieldError in err. The error is returned as is if it is nil or
+// already a ModuleError.
+func NewModuleError(moduleName string, phase Phase, err error) error {
+	var moduleErr *ModuleError
+	if err == nil || errors.As(err, &moduleErr) {
+		return err
+	}
+	moduleErr = &ModuleError{Module: moduleName, Phase: phase, Err: err}
+	var fieldErr *ConfigFieldError
+	if errors.As(err, &fieldErr) {
+		moduleErr.Path = fieldErr.Path
+	}
+	return moduleErr
+}
+
+// RecoveredError returns the ModuleError of the panic recovered from the generator. It carries
+// neither the stack nor the raw request, which may contain the secrets in the configs.
+func RecoveredError(moduleName string, r interface{}) error {
+	return &ModuleError{Module: moduleName, Phase: PhaseGenerate, Err: fmt.Errorf("%w: %v", ErrPanic, r)}
+}
@@ -0,0 +1,37 @@
+package moduleutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewModuleError(t *testing.T) {
+	assert.NoError(t, NewModuleError("foo", PhaseGenerate, nil))
+
+	cause := errors.New("boom")
+	err := NewModuleError("foo", PhaseGenerate, cause)
+	assert.ErrorIs(t, err, cause)
+	assert.EqualError(t, err, "foo module generate failed: boom")
+
+	// The inner ModuleError is kept as is.
+	assert.Equal(t, err, NewModuleError("foo", PhaseComplete, err))
+
+	err = NewModuleError("foo", PhaseValidate, fmt.Errorf("validate foo config failed, %w",
+		errors.Join(&ConfigFieldError{Path: "ports[0].port", Reason: "unknown field"})))
+	var moduleErr *ModuleError
+	if assert.ErrorAs(t, err, &moduleErr) {
+		assert.Equal(t, "foo", moduleErr.Module)
+		assert.Equal(t, PhaseValidate, moduleErr.Phase)
+		assert.Equal(t, "ports[0].port", moduleErr.Path)
+	}
+	assert.EqualError(t, err, "foo module validate failed at ports[0].port: validate foo config failed, ports[0].port: unknown field")
+}
+
+func TestRecoveredError(t *testing.T) {
+	err := RecoveredError("foo", "interface conversion")
+	assert.ErrorIs(t, err, ErrPanic)
+	assert.EqualError(t, err, "foo module generate failed: generator panicked: interface conversion")
+}
@@ -0,0 +1,38 @@
+package moduleutil
+
+import (
+	"context"
+	"runtime/debug"
+
+	"kusionstack.io/kusion-module-framework/pkg/log"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// Recover recovers from the panic of the generator and wraps the returned error into the
+// structured module error, which leaves the stack to the logs and never embeds the raw request
+// carrying the secrets. It must be deferred directly by Generate with its named results:
+//
+//	defer moduleutil.Recover(ctx, "foo", &response, &err)
+func Recover(ctx context.Context, moduleName string, response **module.GeneratorResponse, err *error) {
+	if r := recover(); r != nil {
+		log.GetModuleLogger(ctx).Debug("failed to generate %s module: %v\n%s", moduleName, r, debug.Stack())
+		*response = nil
+		*err = RecoveredError(moduleName, r)
+	}
+	*err = NewModuleError(moduleName, PhaseGenerate, *err)
+}
+
+// Finalize post-processes the resources generated by the module. It labels and tags them with the
+// standard metadata, checks them against the policies and the Pod Security Standards level in the
+// platform config, and attaches the preview summary of them if enabled in the workspace context.
+func Finalize(moduleName string, request *module.GeneratorRequest, response *module.GeneratorResponse) error {
+	ApplyMetadata(moduleName, request, response)
+	if err := CheckPolicies(request, response); err != nil {
+		return err
+	}
+	if err := CheckPodSecurity(request, response); err != nil {
+		return err
+	}
+	AttachSummary(moduleName, request, response)
+	return nil
+}
@@ -0,0 +1,70 @@
+package moduleutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestRecover(t *testing.T) {
+	generate := func(cause error, panics bool) (response *module.GeneratorResponse, err error) {
+		defer Recover(context.Background(), "foo", &response, &err)
+		response = &module.GeneratorResponse{}
+		if panics {
+			panic("interface conversion")
+		}
+		return response, cause
+	}
+
+	response, err := generate(nil, false)
+	assert.NoError(t, err)
+	assert.NotNil(t, response)
+
+	cause := errors.New("boom")
+	_, err = generate(cause, false)
+	assert.ErrorIs(t, err, cause)
+	assert.EqualError(t, err, "foo module generate failed: boom")
+
+	response, err = generate(nil, true)
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, ErrPanic)
+	assert.EqualError(t, err, "foo module generate failed: generator panicked: interface conversion")
+}
+
+func TestFinalize(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+		Context: kusionapiv1.GenericConfig{PreviewSummaryKey: true},
+	}
+	response := &module.GeneratorResponse{Resources: policyTestResources()}
+	response.Resources[0].Attributes["kind"] = "Deployment"
+	response.Resources[0].Attributes["metadata"] = map[string]interface{}{"name": "foo"}
+
+	assert.NoError(t, Finalize("foo", request, response))
+	metadata := response.Resources[0].Attributes["metadata"].(map[string]interface{})
+	assert.Equal(t, "foo", metadata["labels"].(map[string]interface{})[LabelAppName])
+	assert.Equal(t, "default", response.Resources[1].Attributes["tags"].(map[string]interface{})[LabelProject])
+	assert.Contains(t, response.Resources[0].Extensions, SummaryExtensionKey)
+
+	request.PlatformConfig = kusionapiv1.GenericConfig{
+		PoliciesKey: []interface{}{
+			map[string]interface{}{
+				"name":     "no-privileged",
+				"kinds":    []interface{}{"Deployment"},
+				"path":     "spec.template.spec.containers[*].securityContext.privileged",
+				"operator": "equals",
+				"value":    true,
+			},
+		},
+	}
+	assert.ErrorIs(t, Finalize("foo", request, response), ErrPolicyViolation)
+
+	request.PlatformConfig = kusionapiv1.GenericConfig{PodSecurityKey: PodSecurityBaseline}
+	assert.ErrorIs(t, Finalize("foo", request, response), ErrPodSecurityViolation)
+}
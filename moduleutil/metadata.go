@@ -0,0 +1,103 @@
+package moduleutil
+
+import (
+	"slices"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// The standard labels of the generated Kubernetes resources, which are also the tags of the
+// generated cloud resources.
+const (
+	LabelAppName   = "app.kubernetes.io/name"
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelProject   = "kusionstack.io/project"
+	LabelStack     = "kusionstack.io/stack"
+	LabelWorkspace = "kusionstack.io/workspace"
+	// AnnotationModule is the annotation of the module generating the Kubernetes resource.
+	AnnotationModule = "kusionstack.io/module"
+)
+
+const (
+	// WorkspaceKey is the key of the workspace context carrying the workspace name.
+	WorkspaceKey = "workspace"
+	// ManagedByKusion is the value of the managed-by label.
+	ManagedByKusion = "kusion"
+)
+
+// taggedCloudResources are the types of the Terraform resources supporting the tags.
+var taggedCloudResources = []string{
+	"aws_db_instance",
+	"aws_security_group",
+	"alicloud_db_instance",
+	"aws_apigatewayv2_api",
+	"aws_apigatewayv2_stage",
+	"aws_apigatewayv2_domain_name",
+	"aws_sns_topic",
+	"aws_iam_user",
+}
+
+// StandardLabels returns the standard labels of the resources generated for the application,
+// where the workspace label is only set if the workspace name is in the workspace context.
+func StandardLabels(request *module.GeneratorRequest) map[string]string {
+	labels := map[string]string{
+		LabelAppName:   request.App,
+		LabelManagedBy: ManagedByKusion,
+		LabelProject:   request.Project,
+		LabelStack:     request.Stack,
+	}
+	if workspace, ok := request.Context[WorkspaceKey].(string); ok && workspace != "" {
+		labels[LabelWorkspace] = workspace
+	}
+	return labels
+}
+
+// ApplyMetadata sets the standard labels and the module annotation of the generated Kubernetes
+// resources, and the standard tags of the generated cloud resources supporting the tags. The
+// labels, annotations and tags already set by the module are kept.
+func ApplyMetadata(moduleName string, request *module.GeneratorRequest, response *module.GeneratorResponse) {
+	if request == nil || response == nil {
+		return
+	}
+	labels := StandardLabels(request)
+	for i := range response.Resources {
+		res := &response.Resources[i]
+		switch {
+		case res.Type == kusionapiv1.Kubernetes:
+			metadata, ok := res.Attributes["metadata"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			metadata["labels"] = MergeMetadata(metadata["labels"], labels)
+			metadata["annotations"] = MergeMetadata(metadata["annotations"], map[string]string{
+				AnnotationModule: moduleName,
+			})
+		case res.Type == kusionapiv1.Terraform && slices.Contains(taggedCloudResources, ResourceKind(*res)):
+			if res.Attributes == nil {
+				continue
+			}
+			res.Attributes["tags"] = MergeMetadata(res.Attributes["tags"], labels)
+		}
+	}
+}
+
+// MergeMetadata merges the metadata into the existing labels, annotations or tags without
+// overriding them.
+func MergeMetadata(existing interface{}, metadata map[string]string) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for k, v := range metadata {
+		merged[k] = v
+	}
+	switch existing := existing.(type) {
+	case map[string]interface{}:
+		for k, v := range existing {
+			merged[k] = v
+		}
+	case map[string]string:
+		for k, v := range existing {
+			merged[k] = v
+		}
+	}
+	return merged
+}
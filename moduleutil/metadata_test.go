@@ -0,0 +1,75 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestStandardLabels(t *testing.T) {
+	request := &module.GeneratorRequest{Project: "default", Stack: "dev", App: "foo"}
+	assert.Equal(t, map[string]string{
+		LabelAppName:   "foo",
+		LabelManagedBy: ManagedByKusion,
+		LabelProject:   "default",
+		LabelStack:     "dev",
+	}, StandardLabels(request))
+
+	request.Context = kusionapiv1.GenericConfig{WorkspaceKey: "prod"}
+	assert.Equal(t, "prod", StandardLabels(request)[LabelWorkspace])
+}
+
+func TestApplyMetadata(t *testing.T) {
+	request := &module.GeneratorRequest{Project: "default", Stack: "dev", App: "foo"}
+	response := &module.GeneratorResponse{
+		Resources: []kusionapiv1.Resource{
+			{
+				ID:   "v1:Secret:default:foo",
+				Type: kusionapiv1.Kubernetes,
+				Attributes: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":   "foo",
+						"labels": map[string]interface{}{LabelAppName: "bar"},
+					},
+				},
+			},
+			{
+				ID:         "hashicorp:aws:aws_db_instance:foo",
+				Type:       kusionapiv1.Terraform,
+				Attributes: map[string]interface{}{"tags": map[string]string{"team": "db"}},
+			},
+			{
+				ID:         "hashicorp:random:random_password:foo",
+				Type:       kusionapiv1.Terraform,
+				Attributes: map[string]interface{}{"length": 16},
+			},
+			{
+				ID:         "hashicorp:aws:aws_sns_topic:foo",
+				Type:       kusionapiv1.Terraform,
+				Attributes: map[string]interface{}{"name": "foo"},
+			},
+		},
+	}
+
+	ApplyMetadata("network", request, response)
+
+	metadata := response.Resources[0].Attributes["metadata"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		LabelAppName:   "bar",
+		LabelManagedBy: ManagedByKusion,
+		LabelProject:   "default",
+		LabelStack:     "dev",
+	}, metadata["labels"])
+	assert.Equal(t, map[string]interface{}{AnnotationModule: "network"}, metadata["annotations"])
+	assert.Equal(t, map[string]interface{}{
+		"team":         "db",
+		LabelAppName:   "foo",
+		LabelManagedBy: ManagedByKusion,
+		LabelProject:   "default",
+		LabelStack:     "dev",
+	}, response.Resources[1].Attributes["tags"])
+	assert.NotContains(t, response.Resources[2].Attributes, "tags")
+	assert.Contains(t, response.Resources[3].Attributes, "tags")
+}
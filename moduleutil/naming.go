@@ -0,0 +1,87 @@
+package moduleutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+const (
+	// NamingTemplateKey is the key of the workspace context carrying the naming template of the
+	// generated resources.
+	NamingTemplateKey = "namingTemplate"
+	// DefaultNamingTemplate is the default naming template, where the empty placeholders are
+	// dropped along with their separators, e.g. the app name is "{project}-{stack}-{app}".
+	DefaultNamingTemplate = "{project}-{stack}-{app}-{resource}"
+)
+
+// NamingRule is the naming rule of the resources of a provider, which the names rendered from
+// the naming template are sanitized and truncated by.
+type NamingRule struct {
+	// The max length of the names, the longer names are truncated with a hash suffix.
+	MaxLength int
+	// The prefix of the names not starting with a letter, empty if they are allowed.
+	LetterPrefix string
+}
+
+var (
+	// KubernetesNamingRule is the rule of the DNS label names of the Kubernetes resources.
+	KubernetesNamingRule = NamingRule{MaxLength: 63}
+	// AWSNamingRule is the rule of the identifiers of the AWS resources, e.g. RDS instances.
+	AWSNamingRule = NamingRule{MaxLength: 63, LetterPrefix: "kusion-"}
+	// AlicloudNamingRule is the rule of the names of the Alicloud resources, e.g. RDS instances.
+	AlicloudNamingRule = NamingRule{MaxLength: 64, LetterPrefix: "kusion-"}
+)
+
+var (
+	placeholderPattern  = regexp.MustCompile(`\{[a-z]+\}`)
+	invalidNamePattern  = regexp.MustCompile(`[^a-z0-9]+`)
+	namingHashLength    = 8
+	namingHashSeparator = "-"
+)
+
+// ResourceName renders the name of the resource by the naming template in the workspace context,
+// or DefaultNamingTemplate if not set. The placeholders are {project}, {stack}, {app} and
+// {resource}, and the unknown ones are dropped. The name is lowercased, the characters other
+// than letters and digits are replaced with hyphens, and it is truncated by the rule.
+func ResourceName(request *module.GeneratorRequest, resource string, rule NamingRule) string {
+	template, _ := request.Context[NamingTemplateKey].(string)
+	if template == "" {
+		template = DefaultNamingTemplate
+	}
+	values := map[string]string{
+		"{project}":  request.Project,
+		"{stack}":    request.Stack,
+		"{app}":      request.App,
+		"{resource}": resource,
+	}
+	name := placeholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return values[placeholder]
+	})
+	return SanitizeName(name, rule)
+}
+
+// AppName returns the name of the application, which is the name of the workload and the prefix
+// of the names of the resources generated for it.
+func AppName(request *module.GeneratorRequest) string {
+	return ResourceName(request, "", KubernetesNamingRule)
+}
+
+// SanitizeName sanitizes the name by the rule, and truncates the name longer than the max length
+// with the hash of the full name to keep it unique.
+func SanitizeName(name string, rule NamingRule) string {
+	name = strings.Trim(invalidNamePattern.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if rule.LetterPrefix != "" && (name == "" || name[0] < 'a' || name[0] > 'z') {
+		name = rule.LetterPrefix + name
+	}
+	if rule.MaxLength > 0 && len(name) > rule.MaxLength {
+		sum := sha256.Sum256([]byte(name))
+		hash := hex.EncodeToString(sum[:])[:namingHashLength]
+		prefix := strings.TrimRight(name[:rule.MaxLength-namingHashLength-len(namingHashSeparator)], "-")
+		name = prefix + namingHashSeparator + hash
+	}
+	return name
+}
@@ -0,0 +1,77 @@
+package moduleutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		resource string
+		rule     NamingRule
+		expected string
+	}{
+		{
+			name:     "default template",
+			resource: "postgres",
+			rule:     KubernetesNamingRule,
+			expected: "default-dev-foo-postgres",
+		},
+		{
+			name:     "default template without resource",
+			rule:     KubernetesNamingRule,
+			expected: "default-dev-foo",
+		},
+		{
+			name:     "custom template",
+			template: "{app}-{resource}-{stack}",
+			resource: "postgres",
+			rule:     KubernetesNamingRule,
+			expected: "foo-postgres-dev",
+		},
+		{
+			name:     "unknown placeholder and invalid characters",
+			template: "{team}_{App}.{app}",
+			rule:     KubernetesNamingRule,
+			expected: "app-foo",
+		},
+		{
+			name:     "letter prefix",
+			template: "{resource}-{app}",
+			resource: "1st",
+			rule:     AWSNamingRule,
+			expected: "kusion-1st-foo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &module.GeneratorRequest{Project: "default", Stack: "dev", App: "foo"}
+			if tt.template != "" {
+				request.Context = kusionapiv1.GenericConfig{NamingTemplateKey: tt.template}
+			}
+			assert.Equal(t, tt.expected, ResourceName(request, tt.resource, tt.rule))
+		})
+	}
+}
+
+func TestResourceNameTruncate(t *testing.T) {
+	request := &module.GeneratorRequest{Project: "default", Stack: "dev", App: strings.Repeat("a", 80)}
+
+	name := ResourceName(request, "postgres", KubernetesNamingRule)
+	assert.Len(t, name, KubernetesNamingRule.MaxLength)
+	assert.True(t, strings.HasPrefix(name, "default-dev-aaa"))
+	assert.NotEqual(t, name, ResourceName(request, "mysql", KubernetesNamingRule))
+	assert.Equal(t, name, ResourceName(request, "postgres", KubernetesNamingRule))
+}
+
+func TestAppName(t *testing.T) {
+	request := &module.GeneratorRequest{Project: "default", Stack: "dev", App: "foo"}
+	assert.Equal(t, module.UniqueAppName(request.Project, request.Stack, request.App), AppName(request))
+}
@@ -0,0 +1,169 @@
+package moduleutil
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// OutputsExtensionKey is the extension key of the Secret resource listing the outputs published by
+// the module, which the other modules of the App reference as "${<module>.<output>}".
+const OutputsExtensionKey = "outputs"
+
+var (
+	ErrUnknownOutput  = errors.New("unknown module output")
+	ErrEmbeddedOutput = errors.New("module output reference must be the whole value")
+)
+
+// outputRefPattern matches the references to the module outputs, e.g. "${postgres.host}".
+var outputRefPattern = regexp.MustCompile(`\$\{([a-z][a-z0-9_]*)\.([A-Za-z][A-Za-z0-9]*)\}`)
+
+// databaseSecretKeys are the keys of the database Secret storing the outputs of the database modules.
+var databaseSecretKeys = map[string]string{
+	"host":       "hostAddress",
+	"port":       "port",
+	"username":   "username",
+	"password":   "password",
+	"secretName": "",
+}
+
+// publishedOutputs are the outputs published by the modules of the App, keyed by the module name
+// and then the output name. The outputs are stored in the Secret generated by the module, and the
+// values are the keys of the Secret data, where the empty key stands for the name of the Secret.
+var publishedOutputs = map[string]map[string]string{
+	"postgres": databaseSecretKeys,
+	"mysql":    databaseSecretKeys,
+}
+
+// OutputRef is the reference to the output of the other module of the App, resolved to the Secret
+// storing the output.
+type OutputRef struct {
+	// The ID of the Secret, which the resource referencing the output depends on.
+	SecretID string
+	// The name of the Secret by the Kusion path of its ID.
+	SecretName string
+	// The key of the output in the Secret data, empty for the name of the Secret.
+	Key string
+}
+
+// ParseOutputRef parses the value referencing the output of the other module, e.g.
+// "${postgres.host}", and returns nil if the value references none. The references to unknown
+// modules are kept as they are, e.g. the shell variables expanded by the container.
+func ParseOutputRef(request *module.GeneratorRequest, value string) (*OutputRef, error) {
+	matches := outputRefPattern.FindAllStringSubmatch(value, -1)
+	for _, match := range matches {
+		outputs, ok := publishedOutputs[match[1]]
+		if !ok {
+			continue
+		}
+		if len(matches) > 1 || match[0] != value {
+			return nil, ErrEmbeddedOutput
+		}
+		key, ok := outputs[match[2]]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s.%s", ErrUnknownOutput, match[1], match[2])
+		}
+
+		id := OutputSecretID(request, match[1])
+		return &OutputRef{
+			SecretID:   id,
+			SecretName: module.KusionPathDependency(id, "metadata.name"),
+			Key:        key,
+		}, nil
+	}
+	return nil, nil
+}
+
+// OutputSecretName returns the name of the Secret storing the outputs of the module, which is
+// rendered from the naming template in the workspace context by the Kubernetes naming rule and
+// suffixed with the module name, e.g. "proj-dev-app-postgres-postgres". It only depends on the
+// request, so that the other modules of the App resolve the same name regardless of the database
+// name overridden in the platform config of the module.
+func OutputSecretName(request *module.GeneratorRequest, moduleName string) string {
+	return ResourceName(request, moduleName, KubernetesNamingRule) + "-" + moduleName
+}
+
+// OutputSecretID returns the ID of the Secret storing the outputs of the module, e.g.
+// "v1:Secret:proj:proj-dev-app-postgres-postgres".
+func OutputSecretID(request *module.GeneratorRequest, moduleName string) string {
+	return module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Secret"},
+		metav1.ObjectMeta{Namespace: request.Project, Name: OutputSecretName(request, moduleName)},
+	)
+}
+
+// ResolveOutputRefs resolves the env values referencing the outputs of the other modules, e.g.
+// "${postgres.host}", in place. The outputs stored in the Secret data are injected by secretKeyRef
+// and the Secret name by its value, both of which refer to the Secret by the Kusion path, so that
+// the workload waits on the Secret. It returns the IDs of the referenced Secrets.
+func ResolveOutputRefs(request *module.GeneratorRequest, containers []corev1.Container) ([]string, error) {
+	var dependsOn []string
+	for i := range containers {
+		for j := range containers[i].Env {
+			env := &containers[i].Env[j]
+			ref, err := ParseOutputRef(request, env.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w, env %s of container %s", err, env.Name, containers[i].Name)
+			}
+			if ref == nil {
+				continue
+			}
+
+			if ref.Key == "" {
+				env.Value = ref.SecretName
+			} else {
+				env.Value = ""
+				env.ValueFrom = &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: ref.SecretName},
+						Key:                  ref.Key,
+					},
+				}
+			}
+			if !slices.Contains(dependsOn, ref.SecretID) {
+				dependsOn = append(dependsOn, ref.SecretID)
+			}
+		}
+	}
+	return dependsOn, nil
+}
+
+// PublishOutputs lists the outputs published by the module in the extensions of the Secret
+// resource storing them.
+func PublishOutputs(moduleName string, resource *kusionapiv1.Resource) {
+	outputs := make(map[string]interface{}, len(publishedOutputs[moduleName]))
+	for name, key := range publishedOutputs[moduleName] {
+		outputs[name] = key
+	}
+	if resource.Extensions == nil {
+		resource.Extensions = map[string]interface{}{}
+	}
+	resource.Extensions[OutputsExtensionKey] = outputs
+}
+
+// DatabaseConnectionInfo returns the host, port and Secret name of the database from the database
+// Secret publishing the outputs in the response, leaving out the credentials stored in the Secret.
+func DatabaseConnectionInfo(response *module.GeneratorResponse) map[string]string {
+	if response == nil {
+		return nil
+	}
+	for _, res := range response.Resources {
+		if _, ok := res.Extensions[OutputsExtensionKey]; !ok {
+			continue
+		}
+		metadata, _ := res.Attributes["metadata"].(map[string]interface{})
+		data, _ := res.Attributes["stringData"].(map[string]interface{})
+		return map[string]string{
+			"host":       fmt.Sprint(data["hostAddress"]),
+			"port":       fmt.Sprint(data["port"]),
+			"secretName": fmt.Sprint(metadata["name"]),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package moduleutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestParseOutputRef(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+	}
+	postgresID := "v1:Secret:default:default-dev-foo-postgres-postgres"
+
+	tests := []struct {
+		name          string
+		value         string
+		expected      *OutputRef
+		expectedError error
+	}{
+		{
+			name:  "output in the Secret data",
+			value: "${postgres.password}",
+			expected: &OutputRef{
+				SecretID:   postgresID,
+				SecretName: "$kusion_path." + postgresID + ".metadata.name",
+				Key:        "password",
+			},
+		},
+		{
+			name:  "name of the Secret",
+			value: "${mysql.secretName}",
+			expected: &OutputRef{
+				SecretID:   "v1:Secret:default:default-dev-foo-mysql-mysql",
+				SecretName: "$kusion_path.v1:Secret:default:default-dev-foo-mysql-mysql.metadata.name",
+			},
+		},
+		{
+			name:  "not a module output",
+			value: "${env.path}:/bin",
+		},
+		{
+			name:  "plain value",
+			value: "bar",
+		},
+		{
+			name:          "unknown output",
+			value:         "${postgres.hostname}",
+			expectedError: ErrUnknownOutput,
+		},
+		{
+			name:          "embedded output",
+			value:         "postgres://${postgres.host}:5432",
+			expectedError: ErrEmbeddedOutput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := ParseOutputRef(request, tt.value)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, ref)
+		})
+	}
+}
+
+func TestOutputSecretID(t *testing.T) {
+	tests := []struct {
+		name     string
+		app      string
+		context  kusionapiv1.GenericConfig
+		expected string
+	}{
+		{
+			name:     "default naming template",
+			app:      "foo",
+			expected: "v1:Secret:default:default-dev-foo-postgres-postgres",
+		},
+		{
+			name:     "naming template in the workspace context",
+			app:      "foo",
+			context:  kusionapiv1.GenericConfig{NamingTemplateKey: "{app}-{resource}"},
+			expected: "v1:Secret:default:foo-postgres-postgres",
+		},
+		{
+			name:     "uppercase app name",
+			app:      "Foo_Bar",
+			expected: "v1:Secret:default:default-dev-foo-bar-postgres-postgres",
+		},
+		{
+			name:     "overlong app name",
+			app:      strings.Repeat("a", 70),
+			expected: "v1:Secret:default:" + SanitizeName("default-dev-"+strings.Repeat("a", 70)+"-postgres", KubernetesNamingRule) + "-postgres",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &module.GeneratorRequest{Project: "default", Stack: "dev", App: tt.app, Context: tt.context}
+			id := OutputSecretID(request, "postgres")
+			assert.Equal(t, tt.expected, id)
+			assert.LessOrEqual(t, len(OutputSecretName(request, "postgres")), KubernetesNamingRule.MaxLength+len("-postgres"))
+		})
+	}
+}
+
+func TestResolveOutputRefs(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Project: "default",
+		Stack:   "dev",
+		App:     "foo",
+	}
+	postgresID := "v1:Secret:default:default-dev-foo-postgres-postgres"
+	mysqlID := "v1:Secret:default:default-dev-foo-mysql-mysql"
+
+	tests := []struct {
+		name          string
+		env           []corev1.EnvVar
+		expectedEnv   []corev1.EnvVar
+		expectedDeps  []string
+		expectedError error
+	}{
+		{
+			name: "resolve outputs",
+			env: []corev1.EnvVar{
+				{Name: "DB_HOST", Value: "${postgres.host}"},
+				{Name: "DB_PASSWORD", Value: "${postgres.password}"},
+				{Name: "DB_SECRET", Value: "${mysql.secretName}"},
+				{Name: "PATH", Value: "${env.path}:/bin"},
+				{Name: "FOO", Value: "bar"},
+			},
+			expectedEnv: []corev1.EnvVar{
+				{Name: "DB_HOST", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "$kusion_path." + postgresID + ".metadata.name"},
+						Key:                  "hostAddress",
+					},
+				}},
+				{Name: "DB_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "$kusion_path." + postgresID + ".metadata.name"},
+						Key:                  "password",
+					},
+				}},
+				{Name: "DB_SECRET", Value: "$kusion_path." + mysqlID + ".metadata.name"},
+				{Name: "PATH", Value: "${env.path}:/bin"},
+				{Name: "FOO", Value: "bar"},
+			},
+			expectedDeps: []string{postgresID, mysqlID},
+		},
+		{
+			name: "unknown output",
+			env: []corev1.EnvVar{
+				{Name: "DB_HOST", Value: "${postgres.hostname}"},
+			},
+			expectedError: ErrUnknownOutput,
+		},
+		{
+			name: "embedded output",
+			env: []corev1.EnvVar{
+				{Name: "DB_URL", Value: "postgres://${postgres.host}:5432"},
+			},
+			expectedError: ErrEmbeddedOutput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			containers := []corev1.Container{{Name: "app", Env: tt.env}}
+			dependsOn, err := ResolveOutputRefs(request, containers)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedEnv, containers[0].Env)
+			assert.Equal(t, tt.expectedDeps, dependsOn)
+		})
+	}
+}
+
+func TestPublishOutputs(t *testing.T) {
+	secret := kusionapiv1.Resource{
+		ID: "v1:Secret:default:foo-postgres",
+		Attributes: map[string]interface{}{
+			"metadata":   map[string]interface{}{"name": "foo-postgres"},
+			"stringData": map[string]interface{}{"hostAddress": "foo.rds.aliyuncs.com", "port": "5432", "password": "secret"},
+		},
+	}
+	PublishOutputs("postgres", &secret)
+	assert.Equal(t, map[string]interface{}{
+		"host":       "hostAddress",
+		"port":       "port",
+		"username":   "username",
+		"password":   "password",
+		"secretName": "",
+	}, secret.Extensions[OutputsExtensionKey])
+
+	response := &module.GeneratorResponse{Resources: []kusionapiv1.Resource{{ID: "v1:Service:default:foo"}, secret}}
+	assert.Equal(t, map[string]string{
+		"host":       "foo.rds.aliyuncs.com",
+		"port":       "5432",
+		"secretName": "foo-postgres",
+	}, DatabaseConnectionInfo(response))
+	assert.Nil(t, DatabaseConnectionInfo(&module.GeneratorResponse{Resources: response.Resources[:1]}))
+	assert.Nil(t, DatabaseConnectionInfo(nil))
+}
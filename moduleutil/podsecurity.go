@@ -0,0 +1,447 @@
+package moduleutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// PodSecurityKey is the key of the platform config holding the Pod Security Standards level the
+// generated pod specs are checked against, i.e. privileged, baseline or restricted, e.g.
+//
+//	podSecurity: restricted
+//
+// The pod specs are not checked if empty or privileged.
+const PodSecurityKey = "podSecurity"
+
+// The levels of the Pod Security Standards.
+const (
+	PodSecurityPrivileged = "privileged"
+	PodSecurityBaseline   = "baseline"
+	PodSecurityRestricted = "restricted"
+)
+
+var (
+	ErrPodSecurityViolation = errors.New("pod security violation")
+	ErrInvalidPodSecurity   = errors.New("podSecurity must be privileged, baseline or restricted")
+)
+
+var (
+	// baselineCapabilities are the capabilities allowed to be added by the baseline level.
+	baselineCapabilities = []corev1.Capability{
+		"AUDIT_WRITE", "CHOWN", "DAC_OVERRIDE", "FOWNER", "FSETID", "KILL", "MKNOD", "NET_BIND_SERVICE",
+		"SETFCAP", "SETGID", "SETPCAP", "SETUID", "SYS_CHROOT",
+	}
+	// baselineSysctls are the safe sysctls allowed by the baseline level.
+	baselineSysctls = []string{
+		"kernel.shm_rmid_forced", "net.ipv4.ip_local_port_range", "net.ipv4.ip_unprivileged_port_start",
+		"net.ipv4.tcp_syncookies", "net.ipv4.ping_group_range", "net.ipv4.ip_local_reserved_ports",
+		"net.ipv4.tcp_keepalive_time", "net.ipv4.tcp_fin_timeout", "net.ipv4.tcp_keepalive_intvl",
+		"net.ipv4.tcp_keepalive_probes",
+	}
+	// baselineSELinuxTypes are the SELinux types allowed by the baseline level besides the empty one.
+	baselineSELinuxTypes = []string{"container_t", "container_init_t", "container_kvm_t", "container_engine_t"}
+)
+
+// podTemplatePaths are the paths to the pod templates in the attributes of the workloads by kind.
+var podTemplatePaths = map[string][][]string{
+	"Pod":         {{}},
+	"Deployment":  {{"spec", "template"}},
+	"StatefulSet": {{"spec", "template"}},
+	"DaemonSet":   {{"spec", "template"}},
+	"ReplicaSet":  {{"spec", "template"}},
+	"CollaSet":    {{"spec", "template"}},
+	"Job":         {{"spec", "template"}},
+	"CronJob":     {{"spec", "jobTemplate", "spec", "template"}},
+	// The Knative Service, the core Services without the template are skipped.
+	"Service": {{"spec", "template"}},
+	// The pod templates of the Flink job and task managers are merged with the common one.
+	"FlinkDeployment": {
+		{"spec", "podTemplate"},
+		{"spec", "jobManager", "podTemplate"},
+		{"spec", "taskManager", "podTemplate"},
+	},
+}
+
+// CheckPodSecurity checks the pod specs of the generated workloads against the Pod Security
+// Standards level in the platform config, and returns ErrPodSecurityViolation carrying all the
+// violations if any, so that they are caught before rejected by the admission.
+//
+// The containers, volumes and pod fields added by the JSON patches of the module to the workload
+// of the application, e.g. the sidecars, are checked as well. They are checked on their own, so
+// the sidecars set the container security context required by the level themselves rather than
+// relying on the pod one of the workload.
+func CheckPodSecurity(request *module.GeneratorRequest, response *module.GeneratorResponse) error {
+	if request == nil || response == nil {
+		return nil
+	}
+	level, _ := request.PlatformConfig[PodSecurityKey].(string)
+	switch level {
+	case "", PodSecurityPrivileged:
+		return nil
+	case PodSecurityBaseline, PodSecurityRestricted:
+	default:
+		return fmt.Errorf("%w, got %s", ErrInvalidPodSecurity, level)
+	}
+
+	var violations []string
+	for _, res := range response.Resources {
+		templates, err := podTemplates(res)
+		if err != nil {
+			return err
+		}
+		for _, template := range templates {
+			for _, v := range podSecurityViolations(level, template) {
+				violations = append(violations, fmt.Sprintf("%s: %s", res.ID, v))
+			}
+		}
+	}
+	patched, err := patchedPodTemplates(request, response.Patcher)
+	if err != nil {
+		return err
+	}
+	for _, id := range slices.Sorted(maps.Keys(patched)) {
+		for _, v := range podSecurityViolations(level, patched[id]) {
+			violations = append(violations, fmt.Sprintf("patch of %s: %s", id, v))
+		}
+	}
+	if len(violations) != 0 {
+		return fmt.Errorf("%w of %s level, %s", ErrPodSecurityViolation, level, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// podTemplates returns the pod templates of the Kubernetes workload, or nil if the resource is not
+// a workload.
+func podTemplates(res kusionapiv1.Resource) ([]*corev1.PodTemplateSpec, error) {
+	if res.Type != kusionapiv1.Kubernetes {
+		return nil, nil
+	}
+	kind, _ := res.Attributes["kind"].(string)
+	if kind == "SparkApplication" {
+		return sparkPodTemplates(res)
+	}
+	var templates []*corev1.PodTemplateSpec
+	for _, path := range podTemplatePaths[kind] {
+		value, ok := attributeAt(res.Attributes, path)
+		if !ok {
+			continue
+		}
+		template := &corev1.PodTemplateSpec{}
+		if err := convertValue(value, template); err != nil {
+			return nil, fmt.Errorf("invalid pod template of %s: %v", res.ID, err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// sparkPod is the driver or executor of the SparkApplication, whose fields are part of the pod
+// spec of the Spark pods.
+type sparkPod struct {
+	Annotations        map[string]string          `json:"annotations,omitempty"`
+	HostNetwork        *bool                      `json:"hostNetwork,omitempty"`
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+	SecurityContext    *corev1.SecurityContext    `json:"securityContext,omitempty"`
+	Sidecars           []corev1.Container         `json:"sidecars,omitempty"`
+	InitContainers     []corev1.Container         `json:"initContainers,omitempty"`
+}
+
+// sparkPodTemplates returns the pod templates of the driver and the executors of the
+// SparkApplication, which share the volumes of the application.
+func sparkPodTemplates(res kusionapiv1.Resource) ([]*corev1.PodTemplateSpec, error) {
+	spec, ok := res.Attributes["spec"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	var volumes []corev1.Volume
+	if err := convertValue(spec["volumes"], &volumes); err != nil {
+		return nil, fmt.Errorf("invalid volumes of %s: %v", res.ID, err)
+	}
+	var templates []*corev1.PodTemplateSpec
+	for _, role := range []string{"driver", "executor"} {
+		if spec[role] == nil {
+			continue
+		}
+		pod := &sparkPod{}
+		if err := convertValue(spec[role], pod); err != nil {
+			return nil, fmt.Errorf("invalid %s of %s: %v", role, res.ID, err)
+		}
+		template := &corev1.PodTemplateSpec{}
+		template.Annotations = pod.Annotations
+		template.Spec.HostNetwork = pod.HostNetwork != nil && *pod.HostNetwork
+		template.Spec.SecurityContext = pod.PodSecurityContext
+		template.Spec.Volumes = volumes
+		template.Spec.InitContainers = pod.InitContainers
+		template.Spec.Containers = append([]corev1.Container{{Name: role, SecurityContext: pod.SecurityContext}}, pod.Sidecars...)
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// patchedPodTemplates returns the pod templates made of the containers, volumes and pod fields
+// added by the JSON patches of the patcher to the workload of the application, keyed by the ID of
+// the workload. The patches of the other resources are skipped.
+func patchedPodTemplates(request *module.GeneratorRequest, patcher *kusionapiv1.Patcher) (map[string]*corev1.PodTemplateSpec, error) {
+	if patcher == nil || len(patcher.JSONPatchers) == 0 || request.Workload == nil {
+		return nil, nil
+	}
+	_, podSpecPath, err := WorkloadTypeMeta(request.Workload)
+	if err != nil {
+		return nil, nil
+	}
+
+	templates := make(map[string]*corev1.PodTemplateSpec)
+	for id, jsonPatcher := range patcher.JSONPatchers {
+		if jsonPatcher.Type != kusionapiv1.JSONPatch {
+			continue
+		}
+		var operations []struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}
+		if err = json.Unmarshal(jsonPatcher.Payload, &operations); err != nil {
+			return nil, fmt.Errorf("invalid JSON patch of %s: %v", id, err)
+		}
+		spec := map[string]interface{}{}
+		for _, op := range operations {
+			field, ok := strings.CutPrefix(op.Path, podSpecPath+"/")
+			if !ok || (op.Op != "add" && op.Op != "replace") {
+				continue
+			}
+			setPatchValue(spec, strings.Split(field, "/"), op.Value)
+		}
+		if len(spec) == 0 {
+			continue
+		}
+		template := &corev1.PodTemplateSpec{}
+		if err = convertValue(map[string]interface{}{"spec": spec}, template); err != nil {
+			return nil, fmt.Errorf("invalid JSON patch of %s: %v", id, err)
+		}
+		templates[id] = template
+	}
+	return templates, nil
+}
+
+// setPatchValue sets the value of the JSON patch operation at the path segments of the pod spec,
+// where "-" appends to a list and the items missing before an index are added as the empty ones
+// named after their positions, e.g. the container at "containers/1/ports".
+func setPatchValue(object map[string]interface{}, segments []string, value interface{}) {
+	field := strings.NewReplacer("~1", "/", "~0", "~").Replace(segments[0])
+	switch {
+	case len(segments) == 1:
+		object[field] = value
+	case segments[1] == "-":
+		list, _ := object[field].([]interface{})
+		object[field] = append(list, value)
+	default:
+		index, err := strconv.Atoi(segments[1])
+		if err != nil {
+			child, ok := object[field].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				object[field] = child
+			}
+			setPatchValue(child, segments[1:], value)
+			return
+		}
+		list, _ := object[field].([]interface{})
+		for len(list) <= index {
+			list = append(list, map[string]interface{}{"name": fmt.Sprintf("%s[%d]", field, len(list))})
+		}
+		if len(segments) == 2 {
+			list[index] = value
+		} else if item, ok := list[index].(map[string]interface{}); ok {
+			setPatchValue(item, segments[2:], value)
+		}
+		object[field] = list
+	}
+}
+
+// attributeAt returns the attribute at the path of the fields.
+func attributeAt(attributes map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = attributes
+	for _, field := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value = m[field]
+	}
+	return value, value != nil
+}
+
+// convertValue converts the unstructured value into the typed one by JSON.
+func convertValue(value interface{}, out interface{}) error {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// podSecurityViolations returns the violations of the pod template against the level.
+func podSecurityViolations(level string, template *corev1.PodTemplateSpec) []string {
+	spec := &template.Spec
+	var violations []string
+	violate := func(format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf(format, args...))
+	}
+	restricted := level == PodSecurityRestricted
+
+	// The baseline level.
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		violate("host namespaces must not be shared")
+	}
+	for _, volume := range spec.Volumes {
+		if volume.HostPath != nil {
+			violate("volume %s must not be hostPath", volume.Name)
+		}
+	}
+	podSC := spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+	for _, sysctl := range podSC.Sysctls {
+		if !slices.Contains(baselineSysctls, sysctl.Name) {
+			violate("sysctl %s is not allowed", sysctl.Name)
+		}
+	}
+	if violation := seLinuxViolation(podSC.SELinuxOptions); violation != "" {
+		violate("pod %s", violation)
+	}
+	if podSC.SeccompProfile != nil && podSC.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		violate("pod seccompProfile must not be Unconfined")
+	}
+	if podSC.AppArmorProfile != nil && podSC.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+		violate("pod appArmorProfile must not be Unconfined")
+	}
+	if podSC.WindowsOptions != nil && podSC.WindowsOptions.HostProcess != nil && *podSC.WindowsOptions.HostProcess {
+		violate("pod must not be hostProcess")
+	}
+	for _, key := range slices.Sorted(maps.Keys(template.Annotations)) {
+		name, ok := strings.CutPrefix(key, corev1.DeprecatedAppArmorBetaContainerAnnotationKeyPrefix)
+		profile := template.Annotations[key]
+		if ok && profile != "" && profile != corev1.DeprecatedAppArmorBetaProfileRuntimeDefault &&
+			!strings.HasPrefix(profile, corev1.DeprecatedAppArmorBetaProfileNamePrefix) {
+			violate("container %s AppArmor profile %s is not allowed", name, profile)
+		}
+	}
+
+	containers := make([]corev1.Container, 0, len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers))
+	containers = append(containers, spec.InitContainers...)
+	containers = append(containers, spec.Containers...)
+	for _, c := range spec.EphemeralContainers {
+		containers = append(containers, corev1.Container(c.EphemeralContainerCommon))
+	}
+	for _, c := range containers {
+		sc := c.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			violate("container %s must not be privileged", c.Name)
+		}
+		for _, port := range c.Ports {
+			if port.HostPort != 0 {
+				violate("container %s must not use hostPort %d", c.Name, port.HostPort)
+			}
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			violate("container %s procMount must be Default", c.Name)
+		}
+		if violation := seLinuxViolation(sc.SELinuxOptions); violation != "" {
+			violate("container %s %s", c.Name, violation)
+		}
+		if sc.SeccompProfile != nil && sc.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+			violate("container %s seccompProfile must not be Unconfined", c.Name)
+		}
+		if sc.AppArmorProfile != nil && sc.AppArmorProfile.Type == corev1.AppArmorProfileTypeUnconfined {
+			violate("container %s appArmorProfile must not be Unconfined", c.Name)
+		}
+		if sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+			violate("container %s must not be hostProcess", c.Name)
+		}
+		allowed := baselineCapabilities
+		if restricted {
+			allowed = []corev1.Capability{"NET_BIND_SERVICE"}
+		}
+		if sc.Capabilities != nil {
+			for _, capability := range sc.Capabilities.Add {
+				if !slices.Contains(allowed, capability) {
+					violate("container %s must not add capability %s", c.Name, capability)
+				}
+			}
+		}
+
+		// The restricted level.
+		if !restricted {
+			continue
+		}
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			violate("container %s allowPrivilegeEscalation must be false", c.Name)
+		}
+		if sc.Capabilities == nil || !slices.Contains(sc.Capabilities.Drop, "ALL") {
+			violate("container %s must drop ALL capabilities", c.Name)
+		}
+		runAsNonRoot := podSC.RunAsNonRoot
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot {
+			violate("container %s runAsNonRoot must be true", c.Name)
+		}
+		if (sc.RunAsUser != nil && *sc.RunAsUser == 0) || (sc.RunAsUser == nil && podSC.RunAsUser != nil && *podSC.RunAsUser == 0) {
+			violate("container %s must not run as user 0", c.Name)
+		}
+		seccompProfile := podSC.SeccompProfile
+		if sc.SeccompProfile != nil {
+			seccompProfile = sc.SeccompProfile
+		}
+		if seccompProfile == nil || (seccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault && seccompProfile.Type != corev1.SeccompProfileTypeLocalhost) {
+			violate("container %s seccompProfile must be RuntimeDefault or Localhost", c.Name)
+		}
+	}
+
+	if restricted {
+		for _, volume := range spec.Volumes {
+			if volume.HostPath == nil && !restrictedVolume(volume.VolumeSource) {
+				violate("volume %s must be one of configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected and secret", volume.Name)
+			}
+		}
+	}
+	return violations
+}
+
+// seLinuxViolation returns the violation of the SELinux options against the baseline level, or
+// empty if none.
+func seLinuxViolation(options *corev1.SELinuxOptions) string {
+	if options == nil {
+		return ""
+	}
+	if options.Type != "" && !slices.Contains(baselineSELinuxTypes, options.Type) {
+		return fmt.Sprintf("seLinuxOptions type %s is not allowed", options.Type)
+	}
+	if options.User != "" || options.Role != "" {
+		return "seLinuxOptions must not set the user or role"
+	}
+	return ""
+}
+
+// restrictedVolume returns whether the volume type is allowed by the restricted level.
+func restrictedVolume(source corev1.VolumeSource) bool {
+	return source.ConfigMap != nil || source.CSI != nil || source.DownwardAPI != nil || source.EmptyDir != nil ||
+		source.Ephemeral != nil || source.PersistentVolumeClaim != nil || source.Projected != nil || source.Secret != nil
+}
@@ -0,0 +1,277 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestCheckPodSecurity(t *testing.T) {
+	newResponse := func(kind string, podSpec map[string]interface{}) *module.GeneratorResponse {
+		attributes := map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       kind,
+			"spec":       map[string]interface{}{"template": map[string]interface{}{"spec": podSpec}},
+		}
+		if kind == "CronJob" {
+			attributes["apiVersion"] = "batch/v1"
+			attributes["spec"] = map[string]interface{}{
+				"jobTemplate": map[string]interface{}{"spec": attributes["spec"]},
+			}
+		}
+		return &module.GeneratorResponse{
+			Resources: []kusionapiv1.Resource{
+				{ID: "foo", Type: kusionapiv1.Kubernetes, Attributes: attributes},
+				{ID: "bar", Type: kusionapiv1.Kubernetes, Attributes: map[string]interface{}{"kind": "ConfigMap"}},
+			},
+		}
+	}
+	restrictedContainer := map[string]interface{}{
+		"name":  "app",
+		"image": "app:v1",
+		"securityContext": map[string]interface{}{
+			"allowPrivilegeEscalation": false,
+			"runAsNonRoot":             true,
+			"capabilities":             map[string]interface{}{"drop": []interface{}{"ALL"}, "add": []interface{}{"NET_BIND_SERVICE"}},
+			"seccompProfile":           map[string]interface{}{"type": "RuntimeDefault"},
+		},
+	}
+	baselineContainer := map[string]interface{}{
+		"name":            "app",
+		"image":           "app:v1",
+		"securityContext": map[string]interface{}{"capabilities": map[string]interface{}{"add": []interface{}{"CHOWN"}}},
+	}
+
+	tests := []struct {
+		name       string
+		level      interface{}
+		kind       string
+		podSpec    map[string]interface{}
+		violations []string
+		wantErr    error
+	}{
+		{
+			name:    "not configured",
+			kind:    "Deployment",
+			podSpec: map[string]interface{}{"hostNetwork": true},
+		},
+		{
+			name:    "privileged",
+			level:   PodSecurityPrivileged,
+			kind:    "Deployment",
+			podSpec: map[string]interface{}{"hostNetwork": true},
+		},
+		{
+			name:    "baseline",
+			level:   PodSecurityBaseline,
+			kind:    "Deployment",
+			podSpec: map[string]interface{}{"containers": []interface{}{baselineContainer}},
+		},
+		{
+			name:  "baseline violations",
+			level: PodSecurityBaseline,
+			kind:  "Deployment",
+			podSpec: map[string]interface{}{
+				"hostPID": true,
+				"volumes": []interface{}{map[string]interface{}{"name": "logs", "hostPath": map[string]interface{}{"path": "/var/log"}}},
+				"containers": []interface{}{map[string]interface{}{
+					"name":            "app",
+					"ports":           []interface{}{map[string]interface{}{"containerPort": 80, "hostPort": 80}},
+					"securityContext": map[string]interface{}{"privileged": true, "capabilities": map[string]interface{}{"add": []interface{}{"SYS_ADMIN"}}},
+				}},
+			},
+			violations: []string{
+				"foo: host namespaces must not be shared",
+				"foo: volume logs must not be hostPath",
+				"foo: container app must not be privileged",
+				"foo: container app must not use hostPort 80",
+				"foo: container app must not add capability SYS_ADMIN",
+			},
+			wantErr: ErrPodSecurityViolation,
+		},
+		{
+			name:  "baseline windows and AppArmor violations",
+			level: PodSecurityBaseline,
+			kind:  "Deployment",
+			podSpec: map[string]interface{}{
+				"securityContext": map[string]interface{}{
+					"windowsOptions":  map[string]interface{}{"hostProcess": true},
+					"appArmorProfile": map[string]interface{}{"type": "Unconfined"},
+				},
+				"containers": []interface{}{map[string]interface{}{
+					"name": "app",
+					"securityContext": map[string]interface{}{
+						"windowsOptions":  map[string]interface{}{"hostProcess": true},
+						"appArmorProfile": map[string]interface{}{"type": "Unconfined"},
+					},
+				}},
+			},
+			violations: []string{
+				"foo: pod appArmorProfile must not be Unconfined",
+				"foo: pod must not be hostProcess",
+				"foo: container app appArmorProfile must not be Unconfined",
+				"foo: container app must not be hostProcess",
+			},
+			wantErr: ErrPodSecurityViolation,
+		},
+		{
+			name:    "restricted",
+			level:   PodSecurityRestricted,
+			kind:    "CronJob",
+			podSpec: map[string]interface{}{"containers": []interface{}{restrictedContainer}},
+		},
+		{
+			name:  "restricted violations",
+			level: PodSecurityRestricted,
+			kind:  "CronJob",
+			podSpec: map[string]interface{}{
+				"securityContext": map[string]interface{}{"runAsUser": 0},
+				"volumes":         []interface{}{map[string]interface{}{"name": "data", "nfs": map[string]interface{}{"server": "nfs", "path": "/"}}},
+				"containers":      []interface{}{baselineContainer},
+			},
+			violations: []string{
+				"foo: container app must not add capability CHOWN",
+				"foo: container app allowPrivilegeEscalation must be false",
+				"foo: container app must drop ALL capabilities",
+				"foo: container app runAsNonRoot must be true",
+				"foo: container app must not run as user 0",
+				"foo: container app seccompProfile must be RuntimeDefault or Localhost",
+				"foo: volume data must be one of configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim, projected and secret",
+			},
+			wantErr: ErrPodSecurityViolation,
+		},
+		{
+			name:    "invalid level",
+			level:   "strict",
+			kind:    "Deployment",
+			podSpec: map[string]interface{}{},
+			wantErr: ErrInvalidPodSecurity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &module.GeneratorRequest{PlatformConfig: kusionapiv1.GenericConfig{}}
+			if tt.level != nil {
+				request.PlatformConfig[PodSecurityKey] = tt.level
+			}
+			err := CheckPodSecurity(request, newResponse(tt.kind, tt.podSpec))
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+			for _, v := range tt.violations {
+				assert.Contains(t, err.Error(), v)
+			}
+		})
+	}
+}
+
+func TestCheckPodSecurity_PodTemplates(t *testing.T) {
+	request := &module.GeneratorRequest{PlatformConfig: kusionapiv1.GenericConfig{PodSecurityKey: PodSecurityBaseline}}
+	privileged := map[string]interface{}{"privileged": true}
+	response := &module.GeneratorResponse{
+		Resources: []kusionapiv1.Resource{
+			{
+				ID:   "apps/v1:Deployment:default:foo",
+				Type: kusionapiv1.Kubernetes,
+				Attributes: map[string]interface{}{
+					"kind": "Deployment",
+					"spec": map[string]interface{}{
+						"template": map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"annotations": map[string]interface{}{
+									"container.apparmor.security.beta.kubernetes.io/app":     "unconfined",
+									"container.apparmor.security.beta.kubernetes.io/sidecar": "runtime/default",
+								},
+							},
+							"spec": map[string]interface{}{"containers": []interface{}{map[string]interface{}{"name": "app"}}},
+						},
+					},
+				},
+			},
+			{
+				ID:   "flink.apache.org/v1beta1:FlinkDeployment:default:foo",
+				Type: kusionapiv1.Kubernetes,
+				Attributes: map[string]interface{}{
+					"kind": "FlinkDeployment",
+					"spec": map[string]interface{}{
+						"taskManager": map[string]interface{}{
+							"podTemplate": map[string]interface{}{
+								"spec": map[string]interface{}{"hostNetwork": true},
+							},
+						},
+					},
+				},
+			},
+			{
+				ID:   "sparkoperator.k8s.io/v1beta2:SparkApplication:default:foo",
+				Type: kusionapiv1.Kubernetes,
+				Attributes: map[string]interface{}{
+					"kind": "SparkApplication",
+					"spec": map[string]interface{}{
+						"volumes": []interface{}{map[string]interface{}{"name": "data", "hostPath": map[string]interface{}{"path": "/data"}}},
+						"driver":  map[string]interface{}{"securityContext": privileged},
+						"executor": map[string]interface{}{
+							"sidecars": []interface{}{map[string]interface{}{"name": "proxy", "securityContext": privileged}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := CheckPodSecurity(request, response)
+	assert.ErrorIs(t, err, ErrPodSecurityViolation)
+	for _, v := range []string{
+		"apps/v1:Deployment:default:foo: container app AppArmor profile unconfined is not allowed",
+		"flink.apache.org/v1beta1:FlinkDeployment:default:foo: host namespaces must not be shared",
+		"sparkoperator.k8s.io/v1beta2:SparkApplication:default:foo: volume data must not be hostPath",
+		"sparkoperator.k8s.io/v1beta2:SparkApplication:default:foo: container driver must not be privileged",
+		"sparkoperator.k8s.io/v1beta2:SparkApplication:default:foo: container proxy must not be privileged",
+	} {
+		assert.Contains(t, err.Error(), v)
+	}
+	assert.NotContains(t, err.Error(), "container sidecar")
+}
+
+func TestCheckPodSecurity_Patcher(t *testing.T) {
+	request := &module.GeneratorRequest{
+		Workload:       kusionapiv1.Accessory{"_type": "service.Service", "type": "Deployment"},
+		PlatformConfig: kusionapiv1.GenericConfig{PodSecurityKey: PodSecurityBaseline},
+	}
+	payload := []byte(`[
+		{"op": "add", "path": "/spec/template/spec/shareProcessNamespace", "value": true},
+		{"op": "add", "path": "/spec/template/spec/containers/-", "value": {"name": "alloy", "securityContext": {"privileged": true}}},
+		{"op": "add", "path": "/spec/template/spec/containers/1/ports", "value": [{"containerPort": 80, "hostPort": 80}]},
+		{"op": "add", "path": "/spec/template/spec/volumes", "value": [{"name": "logs", "hostPath": {"path": "/var/log"}}]},
+		{"op": "add", "path": "/metadata/labels/foo", "value": "bar"}
+	]`)
+	response := &module.GeneratorResponse{
+		Patcher: &kusionapiv1.Patcher{
+			JSONPatchers: map[string]kusionapiv1.JSONPatcher{
+				"apps/v1:Deployment:default:foo": {Type: kusionapiv1.JSONPatch, Payload: payload},
+			},
+		},
+	}
+
+	err := CheckPodSecurity(request, response)
+	assert.ErrorIs(t, err, ErrPodSecurityViolation)
+	for _, v := range []string{
+		"patch of apps/v1:Deployment:default:foo: volume logs must not be hostPath",
+		"patch of apps/v1:Deployment:default:foo: container alloy must not be privileged",
+		"patch of apps/v1:Deployment:default:foo: container containers[1] must not use hostPort 80",
+	} {
+		assert.Contains(t, err.Error(), v)
+	}
+
+	request.PlatformConfig[PodSecurityKey] = PodSecurityPrivileged
+	assert.NoError(t, CheckPodSecurity(request, response))
+
+	response.Patcher.JSONPatchers["apps/v1:Deployment:default:foo"] = kusionapiv1.JSONPatcher{Type: kusionapiv1.JSONPatch, Payload: []byte("{")}
+	request.PlatformConfig[PodSecurityKey] = PodSecurityBaseline
+	assert.ErrorContains(t, CheckPodSecurity(request, response), "invalid JSON patch of apps/v1:Deployment:default:foo")
+}
@@ -0,0 +1,289 @@
+package moduleutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// PoliciesKey is the key of the section in the platform config holding the policies checked
+// against the generated resources, e.g.
+//
+//	policies:
+//	  - name: no-public-db
+//	    kinds: [aws_db_instance]
+//	    path: publicly_accessible
+//	    operator: equals
+//	    value: true
+//	    message: the database instances must not be publicly accessible
+const PoliciesKey = "policies"
+
+// The operators of the policies, which deny the resources whose attribute at the path matches.
+const (
+	PolicyEquals    = "equals"
+	PolicyNotEquals = "notEquals"
+	PolicyContains  = "contains"
+	PolicyExists    = "exists"
+	PolicyAbsent    = "absent"
+)
+
+var (
+	ErrPolicyViolation = errors.New("policy violation")
+	ErrInvalidPolicy   = errors.New("invalid policy")
+)
+
+// PolicyViolation is the violation of a policy by a generated resource.
+type PolicyViolation struct {
+	Policy     string
+	ResourceID string
+	Message    string
+}
+
+// String returns the readable description of the violation.
+func (v PolicyViolation) String() string {
+	return fmt.Sprintf("%s: %s: %s", v.Policy, v.ResourceID, v.Message)
+}
+
+var (
+	// policyFieldPattern matches the field of a policy path with the optional list indexes.
+	policyFieldPattern = regexp.MustCompile(`^([^\[\]]*)((?:\[(?:\*|[0-9]+)\])*)$`)
+	policyIndexPattern = regexp.MustCompile(`\[(?:\*|[0-9]+)\]`)
+)
+
+// Policy is the declarative policy in the platform config, which denies the resources of the
+// kinds whose attributes at the path match the operator and value. The path is dot-separated,
+// where "[*]" matches all the items of a list and "[n]" the n-th one, e.g.
+// "spec.template.spec.containers[*].securityContext.privileged".
+type Policy struct {
+	// The name of the policy.
+	Name string `yaml:"name" json:"name"`
+	// The kinds of the resources checked, e.g. Deployment or aws_db_instance, and all if empty.
+	Kinds []string `yaml:"kinds,omitempty" json:"kinds,omitempty"`
+	// The path of the checked attribute.
+	Path string `yaml:"path" json:"path"`
+	// The operator matching the attribute, one of equals, notEquals, contains, exists and absent.
+	Operator string `yaml:"operator" json:"operator"`
+	// The value compared with the attribute, not required by exists and absent.
+	Value interface{} `yaml:"value,omitempty" json:"value,omitempty"`
+	// The message of the violations.
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// Policies are the policies declared in the platform config.
+type Policies []Policy
+
+// Evaluate checks the resources against the policies and returns the violations of them.
+func (policies Policies) Evaluate(resources []kusionapiv1.Resource) ([]PolicyViolation, error) {
+	var violations []PolicyViolation
+	for _, policy := range policies {
+		segments, err := policy.validate()
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range resources {
+			if len(policy.Kinds) != 0 && !slices.Contains(policy.Kinds, ResourceKind(res)) {
+				continue
+			}
+			if policy.matches(segments, res.Attributes) {
+				violations = append(violations, PolicyViolation{
+					Policy:     policy.Name,
+					ResourceID: res.ID,
+					Message:    policy.message(),
+				})
+			}
+		}
+	}
+	return violations, nil
+}
+
+// parsePolicies returns the policies in the platform config.
+func parsePolicies(platformConfig kusionapiv1.GenericConfig) (Policies, error) {
+	value, ok := platformConfig[PoliciesKey]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	var policies Policies
+	if err = json.Unmarshal(data, &policies); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	return policies, nil
+}
+
+// CheckPolicies checks the generated resources against the policies in the platform config, and
+// returns ErrPolicyViolation carrying all the violations if any.
+func CheckPolicies(request *module.GeneratorRequest, response *module.GeneratorResponse) error {
+	if request == nil || response == nil || len(response.Resources) == 0 {
+		return nil
+	}
+	policies, err := parsePolicies(request.PlatformConfig)
+	if err != nil {
+		return err
+	}
+
+	found, err := policies.Evaluate(response.Resources)
+	if err != nil {
+		return err
+	}
+	var violations []string
+	for _, v := range found {
+		violations = append(violations, v.String())
+	}
+	if len(violations) != 0 {
+		return fmt.Errorf("%w, %s", ErrPolicyViolation, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// validate validates the policy and returns the segments of its path.
+func (p Policy) validate() ([]string, error) {
+	if p.Name == "" {
+		return nil, fmt.Errorf("%w: empty name", ErrInvalidPolicy)
+	}
+	if p.Path == "" {
+		return nil, fmt.Errorf("%w %s: empty path", ErrInvalidPolicy, p.Name)
+	}
+	switch p.Operator {
+	case PolicyEquals, PolicyNotEquals, PolicyContains:
+		if p.Value == nil {
+			return nil, fmt.Errorf("%w %s: empty value of %s", ErrInvalidPolicy, p.Name, p.Operator)
+		}
+	case PolicyExists, PolicyAbsent:
+	default:
+		return nil, fmt.Errorf("%w %s: unsupported operator %q", ErrInvalidPolicy, p.Name, p.Operator)
+	}
+
+	var segments []string
+	for _, field := range strings.Split(p.Path, ".") {
+		match := policyFieldPattern.FindStringSubmatch(field)
+		if match == nil || field == "" {
+			return nil, fmt.Errorf("%w %s: invalid path %q", ErrInvalidPolicy, p.Name, p.Path)
+		}
+		if match[1] != "" {
+			segments = append(segments, match[1])
+		}
+		segments = append(segments, policyIndexPattern.FindAllString(match[2], -1)...)
+	}
+	return segments, nil
+}
+
+// matches returns whether the attributes at the path segments match the policy.
+func (p Policy) matches(segments []string, attributes map[string]interface{}) bool {
+	values, missing := lookupPath(reflect.ValueOf(attributes), segments)
+	switch p.Operator {
+	case PolicyExists:
+		return len(values) != 0
+	case PolicyAbsent:
+		return missing
+	}
+	for _, value := range values {
+		switch p.Operator {
+		case PolicyEquals:
+			if equalValues(value, p.Value) {
+				return true
+			}
+		case PolicyNotEquals:
+			if !equalValues(value, p.Value) {
+				return true
+			}
+		case PolicyContains:
+			if containsValue(value, p.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// message returns the message of the violations of the policy.
+func (p Policy) message() string {
+	if p.Message != "" {
+		return p.Message
+	}
+	if p.Value == nil {
+		return fmt.Sprintf("%s %s", p.Path, p.Operator)
+	}
+	return fmt.Sprintf("%s %s %v", p.Path, p.Operator, p.Value)
+}
+
+// lookupPath returns the values at the path segments, and whether the path is missing in any of
+// the matched items.
+func lookupPath(value reflect.Value, segments []string) ([]reflect.Value, bool) {
+	for value.IsValid() && (value.Kind() == reflect.Interface || value.Kind() == reflect.Ptr) {
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil, true
+	}
+	if len(segments) == 0 {
+		return []reflect.Value{value}, false
+	}
+
+	segment, rest := segments[0], segments[1:]
+	switch {
+	case segment == "[*]":
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			return nil, true
+		}
+		var values []reflect.Value
+		missing := false
+		for i := 0; i < value.Len(); i++ {
+			found, m := lookupPath(value.Index(i), rest)
+			values = append(values, found...)
+			missing = missing || m
+		}
+		return values, missing
+	case strings.HasPrefix(segment, "["):
+		index, _ := strconv.Atoi(segment[1 : len(segment)-1])
+		if (value.Kind() != reflect.Slice && value.Kind() != reflect.Array) || index >= value.Len() {
+			return nil, true
+		}
+		return lookupPath(value.Index(index), rest)
+	case value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+		return lookupPath(value.MapIndex(reflect.ValueOf(segment).Convert(value.Type().Key())), rest)
+	default:
+		return nil, true
+	}
+}
+
+// equalValues returns whether the attribute equals the policy value, comparing the scalars by
+// their string forms so that e.g. the integers decoded as float64 equal the integers.
+func equalValues(value reflect.Value, expected interface{}) bool {
+	switch value.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return reflect.DeepEqual(value.Interface(), expected)
+	default:
+		return fmt.Sprint(value.Interface()) == fmt.Sprint(expected)
+	}
+}
+
+// containsValue returns whether the list attribute has an item equal to the policy value, or the
+// string attribute has the policy value as a substring.
+func containsValue(value reflect.Value, expected interface{}) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			item := value.Index(i)
+			for item.Kind() == reflect.Interface {
+				item = item.Elem()
+			}
+			if item.IsValid() && equalValues(item, expected) {
+				return true
+			}
+		}
+	case reflect.String:
+		return strings.Contains(value.String(), fmt.Sprint(expected))
+	}
+	return false
+}
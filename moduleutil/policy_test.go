@@ -0,0 +1,139 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func policyTestResources() []kusionapiv1.Resource {
+	return []kusionapiv1.Resource{
+		{
+			ID:   "apps/v1:Deployment:default:foo",
+			Type: kusionapiv1.Kubernetes,
+			Attributes: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"template": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":            "foo",
+									"securityContext": map[string]interface{}{"privileged": true},
+									"resources": map[string]interface{}{
+										"limits": map[string]interface{}{"cpu": "1"},
+									},
+								},
+								map[string]interface{}{"name": "sidecar"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			ID:         "hashicorp:alicloud:alicloud_db_instance:foo",
+			Type:       kusionapiv1.Terraform,
+			Attributes: map[string]interface{}{"security_ips": []string{"0.0.0.0/0"}, "instance_storage": 20},
+			Extensions: map[string]interface{}{"resourceType": "alicloud_db_instance"},
+		},
+	}
+}
+
+func TestPolicies_Evaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		expectedIDs []string
+		expectedErr error
+	}{
+		{
+			name:        "privileged containers",
+			policy:      Policy{Name: "no-privileged", Path: "spec.template.spec.containers[*].securityContext.privileged", Operator: PolicyEquals, Value: true},
+			expectedIDs: []string{"apps/v1:Deployment:default:foo"},
+		},
+		{
+			name:        "missing resource limits",
+			policy:      Policy{Name: "limits", Kinds: []string{"Deployment"}, Path: "spec.template.spec.containers[*].resources.limits", Operator: PolicyAbsent},
+			expectedIDs: []string{"apps/v1:Deployment:default:foo"},
+		},
+		{
+			name:   "indexed container with limits",
+			policy: Policy{Name: "limits", Kinds: []string{"Deployment"}, Path: "spec.template.spec.containers[0].resources.limits", Operator: PolicyAbsent},
+		},
+		{
+			name:        "public database",
+			policy:      Policy{Name: "no-public-db", Kinds: []string{"alicloud_db_instance"}, Path: "security_ips", Operator: PolicyContains, Value: "0.0.0.0/0"},
+			expectedIDs: []string{"hashicorp:alicloud:alicloud_db_instance:foo"},
+		},
+		{
+			name:        "number values",
+			policy:      Policy{Name: "storage", Path: "instance_storage", Operator: PolicyNotEquals, Value: 20.0},
+			expectedIDs: nil,
+		},
+		{
+			name:        "existing attribute",
+			policy:      Policy{Name: "storage", Path: "instance_storage", Operator: PolicyExists},
+			expectedIDs: []string{"hashicorp:alicloud:alicloud_db_instance:foo"},
+		},
+		{
+			name:        "unsupported operator",
+			policy:      Policy{Name: "foo", Path: "spec", Operator: "matches"},
+			expectedErr: ErrInvalidPolicy,
+		},
+		{
+			name:        "missing value",
+			policy:      Policy{Name: "foo", Path: "spec", Operator: PolicyEquals},
+			expectedErr: ErrInvalidPolicy,
+		},
+		{
+			name:        "invalid path",
+			policy:      Policy{Name: "foo", Path: "spec..containers[x]", Operator: PolicyExists},
+			expectedErr: ErrInvalidPolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := Policies{tt.policy}.Evaluate(policyTestResources())
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			var ids []string
+			for _, v := range violations {
+				ids = append(ids, v.ResourceID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestCheckPolicies(t *testing.T) {
+	request := &module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{
+			PoliciesKey: []interface{}{
+				map[string]interface{}{
+					"name":     "no-public-db",
+					"kinds":    []interface{}{"alicloud_db_instance"},
+					"path":     "security_ips",
+					"operator": "contains",
+					"value":    "0.0.0.0/0",
+					"message":  "the database must not be public",
+				},
+			},
+		},
+	}
+	response := &module.GeneratorResponse{Resources: policyTestResources()}
+
+	err := CheckPolicies(request, response)
+	assert.ErrorIs(t, err, ErrPolicyViolation)
+	assert.ErrorContains(t, err, "no-public-db: hashicorp:alicloud:alicloud_db_instance:foo: the database must not be public")
+
+	assert.NoError(t, CheckPolicies(&module.GeneratorRequest{}, response))
+
+	request.PlatformConfig = kusionapiv1.GenericConfig{PoliciesKey: "foo"}
+	assert.ErrorIs(t, CheckPolicies(request, response), ErrInvalidPolicy)
+}
@@ -0,0 +1,35 @@
+package moduleutil
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// K8sObject is the Kubernetes object with the type and object metadata.
+type K8sObject interface {
+	runtime.Object
+	metav1.Object
+}
+
+// WrapK8sResource wraps the Kubernetes object into the Kusion resource, whose ID is derived from
+// the type and object metadata of the object.
+func WrapK8sResource(obj K8sObject) (*kusionapiv1.Resource, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	id := module.KubernetesResourceID(
+		metav1.TypeMeta{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind},
+		metav1.ObjectMeta{Name: obj.GetName(), Namespace: obj.GetNamespace()},
+	)
+	return module.WrapK8sResourceToKusionResource(id, obj)
+}
+
+// WrapTFResource wraps the Terraform resource of the provider into the Kusion resource, whose ID is
+// derived from the provider, the resource type and the name.
+func WrapTFResource(providerCfg module.ProviderConfig, resourceType, name string, attrs map[string]interface{}, dependsOn ...string) (*kusionapiv1.Resource, error) {
+	id, err := module.TerraformResourceID(providerCfg, resourceType, name)
+	if err != nil {
+		return nil, err
+	}
+	return module.WrapTFResourceToKusionResource(providerCfg, resourceType, id, attrs, dependsOn)
+}
@@ -0,0 +1,37 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestWrapK8sResource(t *testing.T) {
+	sa := &v1.ServiceAccount{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ServiceAccount"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"},
+	}
+
+	res, err := WrapK8sResource(sa)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1:ServiceAccount:default:foo", res.ID)
+	assert.Equal(t, kusionapiv1.Kubernetes, res.Type)
+	assert.Equal(t, "ServiceAccount", res.Attributes["kind"])
+}
+
+func TestWrapTFResource(t *testing.T) {
+	providerCfg := module.ProviderConfig{Source: "hashicorp/aws", Version: "5.0.0"}
+
+	res, err := WrapTFResource(providerCfg, "aws_sns_topic", "foo", map[string]interface{}{"name": "foo"}, "bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "hashicorp:aws:aws_sns_topic:foo", res.ID)
+	assert.Equal(t, kusionapiv1.Terraform, res.Type)
+	assert.Equal(t, []string{"bar"}, res.DependsOn)
+
+	_, err = WrapTFResource(module.ProviderConfig{Source: "aws"}, "aws_sns_topic", "foo", nil)
+	assert.Error(t, err)
+}
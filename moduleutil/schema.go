@@ -0,0 +1,268 @@
+package moduleutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaCommand is the command line argument to print the JSON Schemas of the module config.
+const SchemaCommand = "schema"
+
+// JSONSchemaDraft is the JSON Schema dialect of the generated schemas.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// ErrInvalidConfig is returned when the config does not conform to the JSON Schema of the module.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// schemaProvider is implemented by the types describing their JSON Schema on their own, e.g. the
+// values of either int or string.
+type schemaProvider interface {
+	JSONSchema() map[string]interface{}
+}
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	schemaProviderType  = reflect.TypeOf((*schemaProvider)(nil)).Elem()
+)
+
+// JSONSchema generates the JSON Schema of the config decoded into v, following the json tags of
+// the struct fields. The fields whose types implement json.Unmarshaler decode the config on their
+// own and accept any value, unless the types describe their schema by schemaProvider.
+func JSONSchema(v interface{}) map[string]interface{} {
+	return typeSchema(reflect.TypeOf(v))
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Implements(schemaProviderType) {
+		return reflect.Zero(t).Interface().(schemaProvider).JSONSchema()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		structProperties(t, properties)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		// yaml.MapSlice is the ordered map decoded from a mapping.
+		if t.Elem().Name() == "MapItem" && strings.HasPrefix(t.Elem().PkgPath(), "gopkg.in/yaml") {
+			return map[string]interface{}{"type": "object"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem()),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structProperties collects the properties of the struct fields, where the embedded and inline
+// structs are flattened into the properties of the parent.
+func structProperties(t reflect.Type, properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if name == "" && (field.Anonymous || opts == "inline") && ft.Kind() == reflect.Struct &&
+			!ft.Implements(schemaProviderType) && !reflect.PointerTo(ft).Implements(jsonUnmarshalerType) {
+			structProperties(ft, properties)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type)
+	}
+}
+
+// ValidateConfig validates the config against the JSON Schema generated from v, and returns the
+// errors of the unknown fields and the mismatched value types along with their field paths, e.g.
+// "ports[0].protocl: unknown field". The fields prefixed with an underscore are the hidden
+// attributes of KCL and skipped.
+func ValidateConfig(config map[string]interface{}, v interface{}) error {
+	if config == nil {
+		return nil
+	}
+	var errs []error
+	validateValue("", config, JSONSchema(v), &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w, %w", ErrInvalidConfig, errors.Join(errs...))
+}
+
+func validateValue(path string, value interface{}, schema map[string]interface{}, errs *[]error) {
+	if value == nil {
+		return
+	}
+	types := schemaTypes(schema)
+	if len(types) == 0 {
+		return
+	}
+	rv := reflect.ValueOf(value)
+	typ := ""
+	for _, t := range types {
+		if matchesType(rv, t) {
+			typ = t
+			break
+		}
+	}
+	if typ == "" {
+		*errs = append(*errs, &ConfigFieldError{
+			Path:   fieldPath(path),
+			Reason: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), valueType(rv)),
+		})
+		return
+	}
+
+	switch typ {
+	case "object":
+		properties, _ := schema["properties"].(map[string]interface{})
+		keys := make([]string, 0, rv.Len())
+		values := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			key := fmt.Sprint(iter.Key().Interface())
+			keys = append(keys, key)
+			values[key] = iter.Value().Interface()
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := key
+			if path != "" {
+				keyPath = path + "." + key
+			}
+			if property, ok := properties[key].(map[string]interface{}); ok {
+				validateValue(keyPath, values[key], property, errs)
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case map[string]interface{}:
+				validateValue(keyPath, values[key], additional, errs)
+			case bool:
+				if !additional && !strings.HasPrefix(key, "_") {
+					*errs = append(*errs, &ConfigFieldError{Path: keyPath, Reason: "unknown field"})
+				}
+			}
+		}
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		for i := 0; i < rv.Len(); i++ {
+			validateValue(fmt.Sprintf("%s[%d]", path, i), rv.Index(i).Interface(), items, errs)
+		}
+	}
+}
+
+// schemaTypes returns the types of the schema, which is either a single type or a list of types.
+func schemaTypes(schema map[string]interface{}) []string {
+	switch typ := schema["type"].(type) {
+	case string:
+		return []string{typ}
+	case []string:
+		return typ
+	}
+	return nil
+}
+
+func matchesType(rv reflect.Value, typ string) bool {
+	switch typ {
+	case "object":
+		return rv.Kind() == reflect.Map
+	case "array":
+		return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+	case "string":
+		return rv.Kind() == reflect.String
+	case "boolean":
+		return rv.Kind() == reflect.Bool
+	case "integer":
+		if rv.CanFloat() {
+			// Numbers decoded from JSON are float64.
+			return rv.Float() == float64(int64(rv.Float()))
+		}
+		return rv.CanInt() || rv.CanUint()
+	case "number":
+		return rv.CanFloat() || rv.CanInt() || rv.CanUint()
+	}
+	return true
+}
+
+func valueType(rv reflect.Value) string {
+	switch {
+	case rv.Kind() == reflect.Map:
+		return "object"
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		return "array"
+	case rv.Kind() == reflect.String:
+		return "string"
+	case rv.Kind() == reflect.Bool:
+		return "boolean"
+	case rv.CanInt() || rv.CanUint():
+		return "integer"
+	case rv.CanFloat():
+		return "number"
+	}
+	return rv.Type().String()
+}
+
+func fieldPath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// PrintConfigSchemas writes the JSON Schemas of the dev config decoded into dev and the platform
+// config decoded into platform.
+func PrintConfigSchemas(w io.Writer, dev, platform interface{}) error {
+	schemas := map[string]interface{}{}
+	for name, v := range map[string]interface{}{"devConfig": dev, "platformConfig": platform} {
+		schema := JSONSchema(v)
+		schema["$schema"] = JSONSchemaDraft
+		schemas[name] = schema
+	}
+	out, err := json.MarshalIndent(schemas, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(out))
+	return err
+}
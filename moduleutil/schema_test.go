@@ -0,0 +1,171 @@
+package moduleutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaTestInline struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type schemaTestPort struct {
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+type schemaTestIntOrString struct{}
+
+func (schemaTestIntOrString) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": []string{"integer", "string"}}
+}
+
+type schemaTestConfig struct {
+	schemaTestInline `json:",inline"`
+	Name             string                `json:"name"`
+	Replicas         *int32                `json:"replicas,omitempty"`
+	Ratio            float64               `json:"ratio,omitempty"`
+	Enabled          bool                  `json:"enabled,omitempty"`
+	Ports            []schemaTestPort      `json:"ports,omitempty"`
+	Data             []byte                `json:"data,omitempty"`
+	MaxSurge         schemaTestIntOrString `json:"maxSurge,omitempty"`
+	Ignored          string                `json:"-"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	expected := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"labels": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"name":     map[string]interface{}{"type": "string"},
+			"replicas": map[string]interface{}{"type": "integer"},
+			"ratio":    map[string]interface{}{"type": "number"},
+			"enabled":  map[string]interface{}{"type": "boolean"},
+			"ports": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"port":     map[string]interface{}{"type": "integer"},
+						"protocol": map[string]interface{}{"type": "string"},
+					},
+					"additionalProperties": false,
+				},
+			},
+			"data":     map[string]interface{}{"type": "string"},
+			"maxSurge": map[string]interface{}{"type": []string{"integer", "string"}},
+		},
+		"additionalProperties": false,
+	}
+	assert.Equal(t, expected, JSONSchema(schemaTestConfig{}))
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr []string
+	}{
+		{
+			name: "valid config",
+			config: map[string]interface{}{
+				"name":     "foo",
+				"replicas": 2,
+				"ratio":    1,
+				"labels":   map[string]interface{}{"app": "foo"},
+				"ports": []interface{}{
+					map[string]interface{}{"port": float64(80), "protocol": "TCP"},
+				},
+				"maxSurge": "10%",
+				"_type":    "foo.Foo",
+			},
+		},
+		{
+			name:   "nil config",
+			config: nil,
+		},
+		{
+			name: "null value is unset",
+			config: map[string]interface{}{
+				"replicas": nil,
+			},
+		},
+		{
+			name: "unknown fields",
+			config: map[string]interface{}{
+				"nmae": "foo",
+				"ports": []interface{}{
+					map[string]interface{}{"port": 80, "protocl": "TCP"},
+				},
+			},
+			wantErr: []string{
+				"nmae: unknown field",
+				"ports[0].protocl: unknown field",
+			},
+		},
+		{
+			name: "mismatched types",
+			config: map[string]interface{}{
+				"name":     1,
+				"replicas": "2",
+				"enabled":  "true",
+				"maxSurge": true,
+				"labels":   map[string]interface{}{"app": true},
+				"ports": []interface{}{
+					map[string]interface{}{"port": 80.5},
+				},
+			},
+			wantErr: []string{
+				"enabled: expected boolean, got string",
+				"labels.app: expected string, got boolean",
+				"maxSurge: expected integer or string, got boolean",
+				"name: expected string, got integer",
+				"ports[0].port: expected integer, got number",
+				"replicas: expected integer, got string",
+			},
+		},
+		{
+			name: "object instead of array",
+			config: map[string]interface{}{
+				"ports": map[string]interface{}{"port": 80},
+			},
+			wantErr: []string{
+				"ports: expected array, got object",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConfig(tt.config, schemaTestConfig{})
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			if !assert.ErrorIs(t, err, ErrInvalidConfig) {
+				return
+			}
+			for _, msg := range tt.wantErr {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestPrintConfigSchemas(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, PrintConfigSchemas(buf, schemaTestConfig{}, schemaTestPort{}))
+
+	schemas := map[string]map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &schemas))
+	assert.Equal(t, JSONSchemaDraft, schemas["devConfig"]["$schema"])
+	assert.Contains(t, schemas["devConfig"]["properties"], "ports")
+	assert.Equal(t, JSONSchemaDraft, schemas["platformConfig"]["$schema"])
+	assert.Contains(t, schemas["platformConfig"]["properties"], "protocol")
+}
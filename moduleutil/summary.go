@@ -0,0 +1,119 @@
+package moduleutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+const (
+	// PreviewSummaryKey is the key of the workspace context enabling the preview summary of the
+	// generated resources.
+	PreviewSummaryKey = "previewSummary"
+	// SummaryExtensionKey is the extension key of the resource carrying the preview summary.
+	SummaryExtensionKey = "summary"
+	// UnknownCost is the placeholder of the estimated monthly cost of the cloud resources.
+	UnknownCost = "unknown"
+)
+
+// Summary is the human-readable summary of the resources generated by the module, which is shown
+// by `kusion preview` to tell what the accessory will create.
+type Summary struct {
+	Module         string            `json:"module" yaml:"module"`
+	Resources      map[string]int    `json:"resources" yaml:"resources"`
+	CloudResources []CloudResource   `json:"cloudResources,omitempty" yaml:"cloudResources,omitempty"`
+	ConnectionInfo map[string]string `json:"connectionInfo,omitempty" yaml:"connectionInfo,omitempty"`
+	Description    string            `json:"description" yaml:"description"`
+}
+
+// CloudResource is a cloud resource created by the module along with its estimated monthly cost.
+type CloudResource struct {
+	ID                   string `json:"id" yaml:"id"`
+	Type                 string `json:"type" yaml:"type"`
+	EstimatedMonthlyCost string `json:"estimatedMonthlyCost" yaml:"estimatedMonthlyCost"`
+}
+
+// previewSummaryEnabled returns whether the preview summary is enabled in the workspace context.
+func previewSummaryEnabled(request *module.GeneratorRequest) bool {
+	if request == nil {
+		return false
+	}
+	enabled, _ := request.Context[PreviewSummaryKey].(bool)
+	return enabled
+}
+
+// AttachSummary attaches the summary of the generated resources to the extensions of the first
+// resource in the response, if the preview summary is enabled.
+func AttachSummary(moduleName string, request *module.GeneratorRequest, response *module.GeneratorResponse) {
+	if !previewSummaryEnabled(request) || response == nil || len(response.Resources) == 0 {
+		return
+	}
+	summary := Summarize(moduleName, response.Resources)
+	if response.Resources[0].Extensions == nil {
+		response.Resources[0].Extensions = map[string]interface{}{}
+	}
+	response.Resources[0].Extensions[SummaryExtensionKey] = summary
+}
+
+// Summarize counts the resources by their kinds, where the Kubernetes resources are counted by
+// the kinds and the Terraform resources by the resource types, and lists the cloud resources along
+// with the connection info.
+func Summarize(moduleName string, resources []kusionapiv1.Resource) Summary {
+	summary := Summary{
+		Module:    moduleName,
+		Resources: map[string]int{},
+	}
+	for _, res := range resources {
+		kind := ResourceKind(res)
+		summary.Resources[kind]++
+		if res.Type == kusionapiv1.Terraform {
+			summary.CloudResources = append(summary.CloudResources, CloudResource{
+				ID:                   res.ID,
+				Type:                 kind,
+				EstimatedMonthlyCost: UnknownCost,
+			})
+		}
+		for k, v := range ConnectionInfoData(res) {
+			if summary.ConnectionInfo == nil {
+				summary.ConnectionInfo = map[string]string{}
+			}
+			summary.ConnectionInfo[k] = v
+		}
+	}
+
+	kinds := make([]string, 0, len(summary.Resources))
+	for kind := range summary.Resources {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	counts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		counts = append(counts, fmt.Sprintf("%d %s", summary.Resources[kind], kind))
+	}
+	summary.Description = fmt.Sprintf("%s creates %d resource(s): %s", moduleName, len(resources), strings.Join(counts, ", "))
+	if len(summary.CloudResources) > 0 {
+		summary.Description += fmt.Sprintf("; %d cloud resource(s) with estimated monthly cost %s", len(summary.CloudResources), UnknownCost)
+	}
+	return summary
+}
+
+// ResourceKind returns the kind of the Kubernetes resource from its ID in the form of
+// "apiVersion:kind:namespace:name", or the resource type of the Terraform resource.
+func ResourceKind(res kusionapiv1.Resource) string {
+	if res.Type == kusionapiv1.Terraform {
+		if resType, ok := res.Extensions["resourceType"].(string); ok {
+			return resType
+		}
+	}
+	parts := strings.Split(res.ID, ":")
+	if res.Type == kusionapiv1.Kubernetes && len(parts) >= 3 {
+		return parts[1]
+	}
+	if res.Type == kusionapiv1.Terraform && len(parts) >= 4 {
+		return parts[2]
+	}
+	return string(res.Type)
+}
@@ -0,0 +1,59 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestSummarize(t *testing.T) {
+	resources := []kusionapiv1.Resource{
+		{ID: "v1:Secret:default:foo", Type: kusionapiv1.Kubernetes},
+		{ID: "apps/v1:Deployment:default:foo", Type: kusionapiv1.Kubernetes},
+		{ID: "v1:Secret:default:bar", Type: kusionapiv1.Kubernetes},
+		{
+			ID:         "hashicorp:aws:aws_db_instance:foo",
+			Type:       kusionapiv1.Terraform,
+			Extensions: map[string]interface{}{"resourceType": "aws_db_instance"},
+		},
+	}
+
+	expected := Summary{
+		Module: "foo",
+		Resources: map[string]int{
+			"Secret":          2,
+			"Deployment":      1,
+			"aws_db_instance": 1,
+		},
+		CloudResources: []CloudResource{
+			{ID: "hashicorp:aws:aws_db_instance:foo", Type: "aws_db_instance", EstimatedMonthlyCost: UnknownCost},
+		},
+		Description: "foo creates 4 resource(s): 1 Deployment, 2 Secret, 1 aws_db_instance; " +
+			"1 cloud resource(s) with estimated monthly cost unknown",
+	}
+	assert.Equal(t, expected, Summarize("foo", resources))
+}
+
+func TestAttachSummary(t *testing.T) {
+	newResponse := func() *module.GeneratorResponse {
+		return &module.GeneratorResponse{
+			Resources: []kusionapiv1.Resource{
+				{ID: "v1:ConfigMap:default:foo", Type: kusionapiv1.Kubernetes},
+			},
+		}
+	}
+
+	response := newResponse()
+	AttachSummary("foo", &module.GeneratorRequest{}, response)
+	assert.NotContains(t, response.Resources[0].Extensions, SummaryExtensionKey)
+
+	response = newResponse()
+	request := &module.GeneratorRequest{Context: kusionapiv1.GenericConfig{PreviewSummaryKey: true}}
+	AttachSummary("foo", request, response)
+	assert.Equal(t, Summarize("foo", response.Resources), response.Resources[0].Extensions[SummaryExtensionKey])
+
+	AttachSummary("foo", request, nil)
+	AttachSummary("foo", request, &module.GeneratorResponse{})
+}
@@ -0,0 +1,107 @@
+package moduleutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+// TerraformKey is the key of the section in the platform config holding the hints of the
+// generated Terraform resources, e.g.
+//
+//	terraform:
+//	  providerAliases:
+//	    aws: us_west_2
+//	  stateGroup: payments-us-west-2
+const TerraformKey = "terraform"
+
+// The extensions of the generated Terraform resources carrying the hints.
+const (
+	ProviderAliasExtension = "providerAlias"
+	StateGroupExtension    = "stateGroup"
+)
+
+// ErrInvalidTerraformHints is returned when the Terraform hints in the platform config are invalid.
+var ErrInvalidTerraformHints = errors.New("invalid terraform hints")
+
+// providerAliasPattern matches the valid aliases of the Terraform provider configurations.
+var providerAliasPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// TerraformHints are the hints mapping the generated Terraform resources to the provider aliases
+// and the isolated states in the multi-region or multi-account setups.
+type TerraformHints struct {
+	// The aliases of the provider configurations managing the resources by the provider names,
+	// e.g. aws or alicloud.
+	ProviderAliases map[string]string `json:"providerAliases,omitempty" yaml:"providerAliases,omitempty"`
+	// The group of the resources sharing an isolated state, e.g. the account or the region.
+	StateGroup string `json:"stateGroup,omitempty" yaml:"stateGroup,omitempty"`
+}
+
+// parseTerraformHints returns the Terraform hints in the platform config.
+func parseTerraformHints(platformConfig kusionapiv1.GenericConfig) (*TerraformHints, error) {
+	value, ok := platformConfig[TerraformKey]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTerraformHints, err)
+	}
+	hints := &TerraformHints{}
+	if err = json.Unmarshal(data, hints); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTerraformHints, err)
+	}
+	for provider, alias := range hints.ProviderAliases {
+		if !providerAliasPattern.MatchString(alias) {
+			return nil, fmt.Errorf("%w: invalid alias %q of provider %s", ErrInvalidTerraformHints, alias, provider)
+		}
+	}
+	return hints, nil
+}
+
+// ApplyTerraformHints sets the provider alias and the state group in the extensions of the
+// generated Terraform resources, where the aliases are only set for the resources of the providers
+// in the hints.
+func ApplyTerraformHints(request *module.GeneratorRequest, response *module.GeneratorResponse) error {
+	if request == nil || response == nil {
+		return nil
+	}
+	hints, err := parseTerraformHints(request.PlatformConfig)
+	if err != nil || hints == nil {
+		return err
+	}
+
+	for i := range response.Resources {
+		res := &response.Resources[i]
+		if res.Type != kusionapiv1.Terraform {
+			continue
+		}
+		if res.Extensions == nil {
+			res.Extensions = map[string]interface{}{}
+		}
+		if alias, ok := hints.ProviderAliases[providerName(*res)]; ok {
+			res.Extensions[ProviderAliasExtension] = alias
+		}
+		if hints.StateGroup != "" {
+			res.Extensions[StateGroupExtension] = hints.StateGroup
+		}
+	}
+	return nil
+}
+
+// providerName returns the name of the provider of the Terraform resource, e.g. aws of the
+// provider source hashicorp/aws.
+func providerName(res kusionapiv1.Resource) string {
+	if source, ok := res.Extensions["provider"].(string); ok && source != "" {
+		return source[strings.LastIndex(source, "/")+1:]
+	}
+	if parts := strings.Split(res.ID, ":"); len(parts) >= 4 {
+		return parts[1]
+	}
+	return ""
+}
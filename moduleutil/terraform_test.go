@@ -0,0 +1,58 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+func TestApplyTerraformHints(t *testing.T) {
+	newResponse := func() *module.GeneratorResponse {
+		return &module.GeneratorResponse{
+			Resources: []kusionapiv1.Resource{
+				{
+					ID:         "hashicorp:aws:aws_db_instance:foo",
+					Type:       kusionapiv1.Terraform,
+					Extensions: map[string]interface{}{"provider": "registry.terraform.io/hashicorp/aws"},
+				},
+				{
+					ID:   "hashicorp:random:random_password:foo",
+					Type: kusionapiv1.Terraform,
+				},
+				{
+					ID:   "v1:Secret:default:foo",
+					Type: kusionapiv1.Kubernetes,
+				},
+			},
+		}
+	}
+
+	request := &module.GeneratorRequest{
+		PlatformConfig: kusionapiv1.GenericConfig{
+			TerraformKey: map[string]interface{}{
+				"providerAliases": map[string]interface{}{"aws": "us_west_2"},
+				"stateGroup":      "payments-us-west-2",
+			},
+		},
+	}
+	response := newResponse()
+	assert.NoError(t, ApplyTerraformHints(request, response))
+	assert.Equal(t, map[string]interface{}{
+		"provider":             "registry.terraform.io/hashicorp/aws",
+		ProviderAliasExtension: "us_west_2",
+		StateGroupExtension:    "payments-us-west-2",
+	}, response.Resources[0].Extensions)
+	assert.Equal(t, map[string]interface{}{StateGroupExtension: "payments-us-west-2"}, response.Resources[1].Extensions)
+	assert.Nil(t, response.Resources[2].Extensions)
+
+	response = newResponse()
+	assert.NoError(t, ApplyTerraformHints(&module.GeneratorRequest{}, response))
+	assert.Equal(t, newResponse(), response)
+
+	request.PlatformConfig[TerraformKey] = map[string]interface{}{
+		"providerAliases": map[string]interface{}{"aws": "us.west"},
+	}
+	assert.ErrorIs(t, ApplyTerraformHints(request, newResponse()), ErrInvalidTerraformHints)
+}
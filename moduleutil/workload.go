@@ -0,0 +1,89 @@
+package moduleutil
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+var ErrUnsupportedWorkloadType = errors.New("unsupported workload type, must be Deployment, DaemonSet, CollaSet, KnativeService, Job or CronJob")
+
+// The API versions of the workloads of the custom resources generated by the service module.
+const (
+	apiVersionCollaSet       = "apps.kusionstack.io/v1alpha1"
+	apiVersionKnativeService = "serving.knative.dev/v1"
+)
+
+// WorkloadTypeMeta returns the TypeMeta of the workload generated by the service or job module,
+// and the JSON pointer to its pod spec, e.g. for patching the workload by the JSON patches.
+func WorkloadTypeMeta(workload kusionapiv1.Accessory) (metav1.TypeMeta, string, error) {
+	if kind, _ := workload["_type"].(string); strings.Contains(kind, ".Job") {
+		if schedule, _ := workload["schedule"].(string); schedule != "" {
+			return metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "CronJob"},
+				"/spec/jobTemplate/spec/template/spec", nil
+		}
+		return metav1.TypeMeta{APIVersion: batchv1.SchemeGroupVersion.String(), Kind: "Job"}, "/spec/template/spec", nil
+	}
+	workloadType, _ := workload["type"].(string)
+	switch strings.ToLower(workloadType) {
+	case "", "deployment":
+		return metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"}, "/spec/template/spec", nil
+	case "daemonset":
+		return metav1.TypeMeta{APIVersion: "apps/v1", Kind: "DaemonSet"}, "/spec/template/spec", nil
+	case "collaset":
+		return metav1.TypeMeta{APIVersion: apiVersionCollaSet, Kind: "CollaSet"}, "/spec/template/spec", nil
+	case "knativeservice":
+		return metav1.TypeMeta{APIVersion: apiVersionKnativeService, Kind: "Service"}, "/spec/template/spec", nil
+	default:
+		return metav1.TypeMeta{}, "", fmt.Errorf("%w, got %s", ErrUnsupportedWorkloadType, workloadType)
+	}
+}
+
+// workloadContainers returns the containers of the workload in the order of the generated pod spec,
+// which is sorted by the container names.
+func workloadContainers(workload kusionapiv1.Accessory) []map[string]interface{} {
+	containers, _ := workload["containers"].(map[string]interface{})
+	names := make([]string, 0, len(containers))
+	for name := range containers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		container, _ := containers[name].(map[string]interface{})
+		result = append(result, container)
+	}
+	return result
+}
+
+// WorkloadContainerMounts returns whether each container of the workload has the volume mounts,
+// which are generated from the files and dirs of the container.
+func WorkloadContainerMounts(workload kusionapiv1.Accessory) []bool {
+	containers := workloadContainers(workload)
+	mounts := make([]bool, 0, len(containers))
+	for _, container := range containers {
+		files, _ := container["files"].(map[string]interface{})
+		dirs, _ := container["dirs"].(map[string]interface{})
+		mounts = append(mounts, len(files) != 0 || len(dirs) != 0)
+	}
+	return mounts
+}
+
+// WorkloadDeclaresVolumes returns whether the pod spec of the workload has the volumes, which are
+// generated from the volumes of the workload and the files and dirs of its containers.
+func WorkloadDeclaresVolumes(workload kusionapiv1.Accessory) bool {
+	if volumes, _ := workload["volumes"].(map[string]interface{}); len(volumes) != 0 {
+		return true
+	}
+	for _, mounts := range WorkloadContainerMounts(workload) {
+		if mounts {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package moduleutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+)
+
+func TestWorkloadDeclaresVolumes(t *testing.T) {
+	tests := []struct {
+		name           string
+		workload       kusionapiv1.Accessory
+		expectedMounts []bool
+		expected       bool
+	}{
+		{
+			name:           "no volumes",
+			workload:       kusionapiv1.Accessory{"containers": map[string]interface{}{"app": map[string]interface{}{}}},
+			expectedMounts: []bool{false},
+		},
+		{
+			name:     "workload volumes",
+			workload: kusionapiv1.Accessory{"volumes": map[string]interface{}{"data": map[string]interface{}{}}},
+			expected: true,
+		},
+		{
+			name: "container files",
+			workload: kusionapiv1.Accessory{"containers": map[string]interface{}{
+				"sidecar": map[string]interface{}{},
+				"app":     map[string]interface{}{"files": map[string]interface{}{"/etc/app.yaml": map[string]interface{}{}}},
+			}},
+			expectedMounts: []bool{true, false},
+			expected:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WorkloadDeclaresVolumes(tt.workload))
+			if tt.expectedMounts != nil {
+				assert.Equal(t, tt.expectedMounts, WorkloadContainerMounts(tt.workload))
+			}
+		})
+	}
+}
+
+func TestWorkloadTypeMeta(t *testing.T) {
+	tests := []struct {
+		name         string
+		workload     kusionapiv1.Accessory
+		expected     metav1.TypeMeta
+		expectedPath string
+		expectedErr  error
+	}{
+		{
+			name:         "default deployment",
+			workload:     kusionapiv1.Accessory{"_type": "service.Service"},
+			expected:     metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+			expectedPath: "/spec/template/spec",
+		},
+		{
+			name:         "knative service",
+			workload:     kusionapiv1.Accessory{"_type": "service.Service", "type": "KnativeService"},
+			expected:     metav1.TypeMeta{APIVersion: "serving.knative.dev/v1", Kind: "Service"},
+			expectedPath: "/spec/template/spec",
+		},
+		{
+			name:         "cron job",
+			workload:     kusionapiv1.Accessory{"_type": "job.Job", "schedule": "0 * * * *"},
+			expected:     metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJob"},
+			expectedPath: "/spec/jobTemplate/spec/template/spec",
+		},
+		{
+			name:        "unsupported type",
+			workload:    kusionapiv1.Accessory{"_type": "service.Service", "type": "StatefulSet"},
+			expectedErr: ErrUnsupportedWorkloadType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typeMeta, path, err := WorkloadTypeMeta(tt.workload)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, typeMeta)
+			assert.Equal(t, tt.expectedPath, path)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Command scaffold stamps out the skeleton of a new Kusion module in the catalog, so that the
+// modules share the same layout, build files and helpers:
+//
+//	modules/<name>
+//	├── <name>.k              the KCL schema of the dev config
+//	├── kcl.mod
+//	├── example               the example workspace and AppConfiguration
+//	└── src                   the generator in Go
+//	    ├── <name>.go         the generator stub
+//	    ├── <name>_test.go
+//	    ├── go.mod, go.sum    derived from the reference module
+//	    └── Makefile
+//
+// The go.mod and go.sum are copied from the src directory of the reference module, network by
+// default, where the module name is replaced and the moduleutil module of the helpers shared by the
+// generators is required and replaced if missing. Run it from this directory:
+//
+//	go run . -name redis
+package main
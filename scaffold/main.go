@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	opts := Options{}
+	flag.StringVar(&opts.Name, "name", "", "the name of the module, e.g. redis")
+	flag.StringVar(&opts.Version, "version", defaultVersion, "the version of the module")
+	flag.StringVar(&opts.ModulesDir, "modules", "../modules", "the modules directory of the catalog")
+	flag.StringVar(&opts.Reference, "reference", defaultReference, "the module to copy the go.mod and go.sum from")
+	flag.Parse()
+
+	moduleDir, err := Scaffold(opts)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created module %s in %s\n", opts.Name, moduleDir)
+}
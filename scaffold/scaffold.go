@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// templates are the files of the module skeleton, where the "__name__" in the paths is replaced
+// with the module name and the ".tmpl" suffix is trimmed.
+//
+//go:embed all:templates
+var templates embed.FS
+
+const (
+	templatesDir     = "templates"
+	templateSuffix   = ".tmpl"
+	namePlaceholder  = "__name__"
+	defaultReference = "network"
+	defaultVersion   = "0.1.0"
+)
+
+var (
+	ErrInvalidName      = errors.New("module name must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+	ErrModuleExists     = errors.New("module already exists")
+	ErrInvalidReference = errors.New("invalid reference module")
+)
+
+// reservedNames are the Go keywords and the packages imported by the generator stub, which can't
+// be the name of the receiver.
+var reservedNames = []string{
+	"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for",
+	"func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return",
+	"select", "struct", "switch", "type", "var",
+	"context", "debug", "fmt", "json", "kusionapiv1", "log", "module", "moduleutil", "os", "server",
+}
+
+// The require and replace directives of the moduleutil module holding the helpers shared by the
+// generators, which are added to the go.mod of the module if missing in the reference module.
+const (
+	moduleutilRequire = "moduleutil v0.0.0"
+	moduleutilReplace = "replace moduleutil => ../../../moduleutil"
+)
+
+var (
+	namePattern         = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	moduleLinePattern   = regexp.MustCompile(`(?m)^module \S+$`)
+	requireBlockPattern = regexp.MustCompile(`(?ms)^require \(\n.*?^\)`)
+)
+
+// Options are the options of the module skeleton.
+type Options struct {
+	// The name of the module, e.g. redis.
+	Name string
+	// The version of the module in kcl.mod and Makefile.
+	Version string
+	// The modules directory of the catalog.
+	ModulesDir string
+	// The name of the reference module to copy the go.mod and go.sum from.
+	Reference string
+}
+
+// templateData is the data to render the templates.
+type templateData struct {
+	Name     string
+	Type     string
+	Receiver string
+	Version  string
+}
+
+// Scaffold creates the skeleton of the module in the modules directory, and returns the directory
+// of the module.
+func Scaffold(opts Options) (string, error) {
+	if !namePattern.MatchString(opts.Name) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, opts.Name)
+	}
+	if opts.Version == "" {
+		opts.Version = defaultVersion
+	}
+	if opts.Reference == "" {
+		opts.Reference = defaultReference
+	}
+
+	moduleDir := filepath.Join(opts.ModulesDir, opts.Name)
+	if _, err := os.Stat(moduleDir); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrModuleExists, moduleDir)
+	}
+	referenceSrc := filepath.Join(opts.ModulesDir, opts.Reference, "src")
+	goMod, err := os.ReadFile(filepath.Join(referenceSrc, "go.mod"))
+	if err != nil {
+		return "", fmt.Errorf("%w %s: %v", ErrInvalidReference, opts.Reference, err)
+	}
+
+	data := templateData{
+		Name:     opts.Name,
+		Type:     typeName(opts.Name),
+		Receiver: receiverName(opts.Name),
+		Version:  opts.Version,
+	}
+	if err = renderTemplates(moduleDir, data); err != nil {
+		return "", err
+	}
+
+	srcDir := filepath.Join(moduleDir, "src")
+	goMod = requireModuleutil(moduleLinePattern.ReplaceAll(goMod, []byte("module "+opts.Name)))
+	if err = os.WriteFile(filepath.Join(srcDir, "go.mod"), goMod, 0o644); err != nil {
+		return "", err
+	}
+	goSum, err := os.ReadFile(filepath.Join(referenceSrc, "go.sum"))
+	if err != nil {
+		return "", fmt.Errorf("%w %s: %v", ErrInvalidReference, opts.Reference, err)
+	}
+	if err = os.WriteFile(filepath.Join(srcDir, "go.sum"), goSum, 0o644); err != nil {
+		return "", err
+	}
+
+	return moduleDir, nil
+}
+
+// requireModuleutil adds the require and replace directives of the moduleutil module to the go.mod
+// if missing, where the require is appended to the first require block.
+func requireModuleutil(goMod []byte) []byte {
+	content := string(goMod)
+	if !strings.Contains(content, "\t"+moduleutilRequire+"\n") && !strings.Contains(content, "require "+moduleutilRequire+"\n") {
+		if loc := requireBlockPattern.FindStringIndex(content); loc != nil {
+			content = content[:loc[1]-1] + "\t" + moduleutilRequire + "\n" + content[loc[1]-1:]
+		} else {
+			content = strings.TrimRight(content, "\n") + "\n\nrequire " + moduleutilRequire + "\n"
+		}
+	}
+	if !strings.Contains(content, moduleutilReplace+"\n") {
+		content = strings.TrimRight(content, "\n") + "\n\n" + moduleutilReplace + "\n"
+	}
+	return []byte(content)
+}
+
+// renderTemplates renders the embedded templates into the module directory.
+func renderTemplates(moduleDir string, data templateData) error {
+	return fs.WalkDir(templates, templatesDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := templates.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		rel := strings.TrimPrefix(p, templatesDir+"/")
+		rel = strings.ReplaceAll(strings.TrimSuffix(rel, templateSuffix), namePlaceholder, data.Name)
+		if strings.HasSuffix(p, templateSuffix) {
+			tmpl, err := template.New(path.Base(p)).Parse(string(content))
+			if err != nil {
+				return err
+			}
+			buf := &bytes.Buffer{}
+			if err = tmpl.Execute(buf, data); err != nil {
+				return fmt.Errorf("render template %s failed, %w", p, err)
+			}
+			content = buf.Bytes()
+		}
+
+		target := filepath.Join(moduleDir, filepath.FromSlash(rel))
+		if err = os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, 0o644)
+	})
+}
+
+// typeName converts the module name into the name of the Go type and KCL schema, e.g. k8s_manifest
+// to K8sManifest.
+func typeName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+// receiverName converts the module name into the name of the receiver of the generator, e.g.
+// k8s_manifest to k8sManifest, which falls back to m for the reserved names.
+func receiverName(name string) string {
+	typ := typeName(name)
+	receiver := strings.ToLower(typ[:1]) + typ[1:]
+	if slices.Contains(reservedNames, receiver) {
+		return "m"
+	}
+	return receiver
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newModulesDir creates a modules directory with the reference module of the go.mod and go.sum.
+func newModulesDir(t *testing.T) string {
+	t.Helper()
+
+	modulesDir := t.TempDir()
+	referenceSrc := filepath.Join(modulesDir, defaultReference, "src")
+	if err := os.MkdirAll(referenceSrc, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"go.mod": "module network\n\ngo 1.23.1\n\nrequire (\n\ttestutil v0.0.0\n)\n\nreplace testutil => ../../../testutil\n",
+		"go.sum": "// go.sum\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(referenceSrc, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return modulesDir
+}
+
+func TestScaffold(t *testing.T) {
+	modulesDir := newModulesDir(t)
+
+	moduleDir, err := Scaffold(Options{Name: "k8s_redis", ModulesDir: modulesDir})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{
+		"kcl.mod",
+		"k8s_redis.k",
+		"example/project.yaml",
+		"example/dev/kcl.mod",
+		"example/dev/main.k",
+		"example/dev/stack.yaml",
+		"example/dev/example_workspace.yaml",
+		"src/Makefile",
+		"src/go.mod",
+		"src/go.sum",
+		"src/k8s_redis.go",
+		"src/k8s_redis_test.go",
+	} {
+		if _, err := os.Stat(filepath.Join(moduleDir, name)); err != nil {
+			t.Errorf("missing file %s: %v", name, err)
+		}
+	}
+
+	goMod, _ := os.ReadFile(filepath.Join(moduleDir, "src", "go.mod"))
+	expectedGoMod := "module k8s_redis\n\ngo 1.23.1\n\nrequire (\n\ttestutil v0.0.0\n\tmoduleutil v0.0.0\n)\n\n" +
+		"replace testutil => ../../../testutil\n\nreplace moduleutil => ../../../moduleutil\n"
+	if string(goMod) != expectedGoMod {
+		t.Errorf("unexpected go.mod:\n%s", goMod)
+	}
+	kclMod, _ := os.ReadFile(filepath.Join(moduleDir, "kcl.mod"))
+	if !strings.Contains(string(kclMod), `version = "0.1.0"`) {
+		t.Errorf("unexpected kcl.mod:\n%s", kclMod)
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range []string{"k8s_redis.go", "k8s_redis_test.go"} {
+		file, err := parser.ParseFile(fset, filepath.Join(moduleDir, "src", name), nil, parser.AllErrors)
+		if err != nil {
+			t.Errorf("invalid generated Go file %s: %v", name, err)
+			continue
+		}
+		if file.Name.Name != "main" {
+			t.Errorf("unexpected package of %s: %s", name, file.Name.Name)
+		}
+	}
+	generator, _ := os.ReadFile(filepath.Join(moduleDir, "src", "k8s_redis.go"))
+	if !strings.Contains(string(generator), "func (k8sRedis *K8sRedis) Generate(") {
+		t.Errorf("unexpected generator stub:\n%s", generator)
+	}
+}
+
+func TestScaffoldErrors(t *testing.T) {
+	modulesDir := newModulesDir(t)
+
+	tests := []struct {
+		name        string
+		opts        Options
+		expectedErr error
+	}{
+		{
+			name:        "invalid name",
+			opts:        Options{Name: "Redis-Cluster", ModulesDir: modulesDir},
+			expectedErr: ErrInvalidName,
+		},
+		{
+			name:        "existing module",
+			opts:        Options{Name: defaultReference, ModulesDir: modulesDir},
+			expectedErr: ErrModuleExists,
+		},
+		{
+			name:        "missing reference",
+			opts:        Options{Name: "redis", ModulesDir: modulesDir, Reference: "unknown"},
+			expectedErr: ErrInvalidReference,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Scaffold(tt.opts); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("expected error %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestRequireModuleutil(t *testing.T) {
+	tests := map[string]string{
+		"module redis\n": "module redis\n\nrequire moduleutil v0.0.0\n\nreplace moduleutil => ../../../moduleutil\n",
+		"module redis\n\nrequire (\n\tfoo v1.0.0\n)\n":                                                     "module redis\n\nrequire (\n\tfoo v1.0.0\n\tmoduleutil v0.0.0\n)\n\nreplace moduleutil => ../../../moduleutil\n",
+		"module redis\n\nrequire (\n\tmoduleutil v0.0.0\n)\n\nreplace moduleutil => ../../../moduleutil\n": "module redis\n\nrequire (\n\tmoduleutil v0.0.0\n)\n\nreplace moduleutil => ../../../moduleutil\n",
+	}
+	for goMod, expected := range tests {
+		if got := string(requireModuleutil([]byte(goMod))); got != expected {
+			t.Errorf("requireModuleutil(%q) = %q, expected %q", goMod, got, expected)
+		}
+	}
+}
+
+func TestReceiverName(t *testing.T) {
+	tests := map[string]string{
+		"redis":        "redis",
+		"k8s_manifest": "k8sManifest",
+		"log":          "m",
+		"module":       "m",
+	}
+	for name, expected := range tests {
+		if got := receiverName(name); got != expected {
+			t.Errorf("receiverName(%q) = %q, expected %q", name, got, expected)
+		}
+	}
+}
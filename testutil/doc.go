@@ -0,0 +1,10 @@
+// Package testutil provides the scaffolding of the table-driven generator tests shared by the
+// Kusion modules in the catalog, including the golden-file comparison of the GeneratorResponse,
+// the fake GeneratorRequest builders and the env-var isolation helpers.
+//
+// Each module imports the package by a local replace directive in its go.mod:
+//
+//	require testutil v0.0.0
+//
+//	replace testutil => ../../../testutil
+package testutil
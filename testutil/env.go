@@ -0,0 +1,64 @@
+package testutil
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// UnsetEnv unsets the env vars during the test and restores them on cleanup, which shields the
+// test from the env of the host, e.g. the cloud credentials.
+func UnsetEnv(t testing.TB, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatalf("failed to unset env %s: %v", key, err)
+		}
+		t.Cleanup(func() {
+			_ = os.Setenv(key, value)
+		})
+	}
+}
+
+// IsolateEnv unsets the env vars with any of the prefixes, e.g. "AWS_" and "ALICLOUD_", during
+// the test and restores them on cleanup.
+func IsolateEnv(t testing.TB, prefixes ...string) {
+	t.Helper()
+
+	var keys []string
+	for _, env := range os.Environ() {
+		key, _, _ := strings.Cut(env, "=")
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	UnsetEnv(t, keys...)
+}
+
+// SetEnv sets the env vars during the test and restores them on cleanup. Unlike testing.T.Setenv,
+// it sets a batch of env vars at once, e.g. the fake cloud credentials.
+func SetEnv(t testing.TB, envs map[string]string) {
+	t.Helper()
+
+	for key, value := range envs {
+		prev, ok := os.LookupEnv(key)
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatalf("failed to set env %s: %v", key, err)
+		}
+		t.Cleanup(func() {
+			if ok {
+				_ = os.Setenv(key, prev)
+			} else {
+				_ = os.Unsetenv(key)
+			}
+		})
+	}
+}
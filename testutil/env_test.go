@@ -0,0 +1,29 @@
+package testutil
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsolateEnv(t *testing.T) {
+	t.Setenv("TESTUTIL_FOO", "foo")
+	t.Setenv("TESTUTIL_BAR", "bar")
+
+	t.Run("isolate", func(t *testing.T) {
+		IsolateEnv(t, "TESTUTIL_")
+		if _, ok := os.LookupEnv("TESTUTIL_FOO"); ok {
+			t.Error("env TESTUTIL_FOO is not unset")
+		}
+		SetEnv(t, map[string]string{"TESTUTIL_BAR": "baz"})
+		if got := os.Getenv("TESTUTIL_BAR"); got != "baz" {
+			t.Errorf("unexpected env TESTUTIL_BAR: %s", got)
+		}
+	})
+
+	if got := os.Getenv("TESTUTIL_FOO"); got != "foo" {
+		t.Errorf("env TESTUTIL_FOO is not restored: %s", got)
+	}
+	if got := os.Getenv("TESTUTIL_BAR"); got != "bar" {
+		t.Errorf("env TESTUTIL_BAR is not restored: %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateGoldenEnv is the env var to rewrite the golden files with the actual values instead of
+// comparing them, e.g. `UPDATE_GOLDEN=1 go test ./...`.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// AssertGolden compares the actual value, e.g. a GeneratorResponse, encoded as the indented JSON
+// with the golden file at path, which is usually under the testdata directory of the module. The
+// golden file is rewritten instead if UpdateGoldenEnv is set.
+func AssertGolden(t testing.TB, path string, actual interface{}) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode the actual value of golden file %s: %v", path, err)
+	}
+	got = append(got, '\n')
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create the directory of golden file %s: %v", path, err)
+		}
+		if err = os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s, run the test with %s=1 to create it: %v", path, UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(normalizeJSON(t, want), normalizeJSON(t, got)) {
+		t.Errorf("mismatched golden file %s, run the test with %s=1 to update it\nwant:\n%s\ngot:\n%s",
+			path, UpdateGoldenEnv, want, got)
+	}
+}
+
+// normalizeJSON re-encodes the JSON document so that the golden files are compared regardless of
+// the key order and indentation.
+func normalizeJSON(t testing.TB, data []byte) []byte {
+	t.Helper()
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("failed to decode the golden JSON: %v", err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to encode the golden JSON: %v", err)
+	}
+	return out
+}
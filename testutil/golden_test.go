@@ -0,0 +1,27 @@
+package testutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssertGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "response.golden.json")
+	actual := map[string]interface{}{"resources": []string{"v1:Secret:default:foo"}}
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	AssertGolden(t, path, actual)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("golden file is not created: %v", err)
+	}
+
+	t.Setenv(UpdateGoldenEnv, "")
+	AssertGolden(t, path, actual)
+
+	// The golden files are compared regardless of the indentation.
+	if err := os.WriteFile(path, []byte(`{"resources":["v1:Secret:default:foo"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	AssertGolden(t, path, actual)
+}
@@ -0,0 +1,89 @@
+package testutil
+
+import (
+	kusionapiv1 "kusionstack.io/kusion-api-go/api.kusion.io/v1"
+	"kusionstack.io/kusion-module-framework/pkg/module"
+)
+
+const (
+	// DefaultProject is the project name of the fake GeneratorRequest.
+	DefaultProject = "default"
+	// DefaultStack is the stack name of the fake GeneratorRequest.
+	DefaultStack = "dev"
+	// DefaultApp is the App name of the fake GeneratorRequest.
+	DefaultApp = "foo"
+)
+
+// RequestBuilder builds the fake GeneratorRequest of the module tests.
+type RequestBuilder struct {
+	request module.GeneratorRequest
+}
+
+// NewRequest returns the builder of the GeneratorRequest of DefaultApp in DefaultProject and
+// DefaultStack, without any workload or module config.
+func NewRequest() *RequestBuilder {
+	return &RequestBuilder{
+		request: module.GeneratorRequest{
+			Project: DefaultProject,
+			Stack:   DefaultStack,
+			App:     DefaultApp,
+		},
+	}
+}
+
+// WithApp sets the project, stack and App names of the request.
+func (b *RequestBuilder) WithApp(project, stack, app string) *RequestBuilder {
+	b.request.Project = project
+	b.request.Stack = stack
+	b.request.App = app
+	return b
+}
+
+// WithWorkload sets the workload config of the request.
+func (b *RequestBuilder) WithWorkload(workload kusionapiv1.Accessory) *RequestBuilder {
+	b.request.Workload = workload
+	return b
+}
+
+// WithServiceWorkload sets the workload of the request to a service of the given type, e.g.
+// "Deployment" or "CollaSet".
+func (b *RequestBuilder) WithServiceWorkload(serviceType string) *RequestBuilder {
+	return b.WithWorkload(kusionapiv1.Accessory{
+		"_type": "service.Service",
+		"type":  serviceType,
+	})
+}
+
+// WithJobWorkload sets the workload of the request to a job.
+func (b *RequestBuilder) WithJobWorkload() *RequestBuilder {
+	return b.WithWorkload(kusionapiv1.Accessory{
+		"_type": "job.Job",
+	})
+}
+
+// WithDevConfig sets the dev config of the module in the request.
+func (b *RequestBuilder) WithDevConfig(devConfig kusionapiv1.Accessory) *RequestBuilder {
+	b.request.DevConfig = devConfig
+	return b
+}
+
+// WithPlatformConfig sets the platform config of the module in the request.
+func (b *RequestBuilder) WithPlatformConfig(platformConfig kusionapiv1.GenericConfig) *RequestBuilder {
+	b.request.PlatformConfig = platformConfig
+	return b
+}
+
+// WithContext sets the value of the key in the workspace context of the request.
+func (b *RequestBuilder) WithContext(key string, value interface{}) *RequestBuilder {
+	if b.request.Context == nil {
+		b.request.Context = kusionapiv1.GenericConfig{}
+	}
+	b.request.Context[key] = value
+	return b
+}
+
+// Build returns a copy of the built request, so that the builder can be reused by the test cases.
+func (b *RequestBuilder) Build() *module.GeneratorRequest {
+	request := b.request
+	return &request
+}
@@ -0,0 +1,25 @@
+package testutil
+
+import (
+	"testing"
+)
+
+func TestRequestBuilder(t *testing.T) {
+	builder := NewRequest().WithServiceWorkload("Deployment").WithContext("workspace", "dev")
+	request := builder.Build()
+	if request.Project != DefaultProject || request.Stack != DefaultStack || request.App != DefaultApp {
+		t.Errorf("unexpected app of the request: %s/%s/%s", request.Project, request.Stack, request.App)
+	}
+	if request.Workload["type"] != "Deployment" {
+		t.Errorf("unexpected workload of the request: %v", request.Workload)
+	}
+	if request.Context["workspace"] != "dev" {
+		t.Errorf("unexpected context of the request: %v", request.Context)
+	}
+
+	// The built requests are not affected by the later changes of the builder.
+	other := builder.WithApp("bar", "prod", "baz").Build()
+	if request.App != DefaultApp || other.App != "baz" {
+		t.Errorf("unexpected apps of the requests: %s, %s", request.App, other.App)
+	}
+}